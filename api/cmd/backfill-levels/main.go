@@ -0,0 +1,79 @@
+// Команда backfill-levels выдаёт предметы и звания таблицы уровней детям, которые
+// прошли уровни раньше, чем уровень стал записываться. Повторный запуск ничего не меняет.
+//
+//	backfill-levels [-file path]  таблица уровней (по умолчанию из CATALOG_DIR или встроенная)
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"child-bot/api/internal/level"
+	"child-bot/api/internal/store"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	file := flag.String("file", "", "path to level table JSON (default: $CATALOG_DIR/levels.json or embedded table)")
+	flag.Parse()
+
+	if err := run(*file); err != nil {
+		log.Fatalf("backfill failed: %v", err)
+	}
+}
+
+func run(path string) error {
+	if dir := os.Getenv("CATALOG_DIR"); path == "" && dir != "" {
+		path = filepath.Join(dir, level.FileName)
+	}
+	levels, err := level.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := levels.Validate(); err != nil {
+		return fmt.Errorf("level table v%d is invalid: %w", levels.Version, err)
+	}
+
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		return fmt.Errorf("missing required environment variable: DATABASE_URL")
+	}
+
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database ping failed: %w", err)
+	}
+
+	st := store.NewStore(db)
+	missing, err := st.MissingShopItems(ctx, levels.ItemIDs())
+	if err != nil {
+		return fmt.Errorf("failed to check level reward items: %w", err)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("level table v%d references unknown shop items: %v", levels.Version, missing)
+	}
+
+	result, err := st.BackfillLevelRewards(ctx, levels)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✓ Level table v%d: backfilled %d levels for %d children",
+		levels.Version, result.Levels, result.Children)
+	return nil
+}
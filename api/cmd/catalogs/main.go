@@ -0,0 +1,77 @@
+// Команда catalogs проверяет каталоги контента сервера и синхронизирует их с БД.
+// Каталоги берутся так же, как на сервере: из CATALOG_DIR или встроенные.
+//
+//	catalogs validate [-dir path]  проверить все каталоги
+//	catalogs sync [-dir path]      записать каталоги в БД (нужен DATABASE_URL)
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"child-bot/api/internal/content"
+	"child-bot/api/internal/store"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	dir := fs.String("dir", os.Getenv("CATALOG_DIR"), "catalog directory (default: $CATALOG_DIR or embedded catalogs)")
+	_ = fs.Parse(os.Args[2:])
+
+	catalogs, err := content.Load(*dir)
+	if err != nil {
+		log.Fatalf("load catalogs: %v", err)
+	}
+
+	switch os.Args[1] {
+	case "validate":
+		if err := catalogs.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "catalogs are invalid:\n%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("catalogs are valid: %d achievements, %d missions, %d events, %d family quests, %d levels\n",
+			len(catalogs.Achievements.Achievements), len(catalogs.Missions.Missions), len(catalogs.Events.Events),
+			len(catalogs.Quests.Templates), len(catalogs.Levels.Levels))
+	case "sync":
+		if err := sync(catalogs); err != nil {
+			log.Fatalf("sync failed: %v", err)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: catalogs validate|sync [-dir path]")
+}
+
+func sync(catalogs *content.Catalogs) error {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		return fmt.Errorf("missing required environment variable: DATABASE_URL")
+	}
+
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	return catalogs.Sync(ctx, store.NewStore(db))
+}
@@ -0,0 +1,66 @@
+// Команда reconcile сверяет журнал монет и XP (wallet_transactions)
+// с материализованными балансами в child_profiles.
+// Завершается с кодом 1, если найдены расхождения.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"child-bot/api/internal/store"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	mismatches, err := run()
+	if err != nil {
+		log.Fatalf("reconcile failed: %v", err)
+	}
+	if mismatches > 0 {
+		os.Exit(1)
+	}
+}
+
+func run() (int, error) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		return 0, fmt.Errorf("missing required environment variable: DATABASE_URL")
+	}
+
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return 0, fmt.Errorf("database ping failed: %w", err)
+	}
+
+	st := store.NewStore(db)
+	mismatches, err := st.ReconcileWallets(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(mismatches) == 0 {
+		log.Println("✓ Wallet ledger matches child_profiles balances")
+		return 0, nil
+	}
+
+	for _, m := range mismatches {
+		fmt.Printf("%s\t%s\tledger=%d\tprofile=%d\tdiff=%d\n",
+			m.ChildProfileID, m.Currency, m.LedgerTotal, m.ProfileBalance, m.ProfileBalance-m.LedgerTotal)
+	}
+	log.Printf("✗ Found %d wallet mismatches", len(mismatches))
+
+	return len(mismatches), nil
+}
@@ -14,6 +14,7 @@ import (
 
 	"child-bot/api/internal/api/router"
 	"child-bot/api/internal/config"
+	"child-bot/api/internal/content"
 	"child-bot/api/internal/llm"
 	"child-bot/api/internal/store"
 
@@ -47,14 +48,33 @@ func run() error {
 
 	// Инициализация зависимостей
 	st := store.NewStore(db)
+
+	// Каталоги контента: встроенные или из CATALOG_DIR; проверяются и синхронизируются в БД
+	catalogs, err := content.Load(cfg.CatalogDir)
+	if err != nil {
+		return err
+	}
+	if err := catalogs.Sync(ctx, st); err != nil {
+		return err
+	}
+	// Проверенная таблица уровней; награды за пройденные раньше уровни выдаёт cmd/backfill-levels
+	st.XP = store.XPConfig{Levels: catalogs.Levels}
+
 	llmClient := llm.NewClient(cfg.LLMServerURL)
 
+	// Фоновые задачи роутера останавливаются при выходе из run
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
 	// Создание роутера
 	r := router.New(&router.Dependencies{
-		Store:      st,
-		LLMClient:  llmClient,
-		Config:     cfg,
-		DefaultLLM: cfg.DefaultLLM,
+		Store:        st,
+		LLMClient:    llmClient,
+		Config:       cfg,
+		DefaultLLM:   cfg.DefaultLLM,
+		Missions:     catalogs.Missions,
+		FamilyQuests: catalogs.Quests,
+		Context:      backgroundCtx,
 	})
 
 	// HTTP сервер
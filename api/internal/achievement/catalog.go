@@ -0,0 +1,256 @@
+// Package achievement описывает каталог достижений и серий.
+// Каталог хранится в JSON, проверяется валидатором и идемпотентно
+// синхронизируется в таблицу achievements при старте сервера.
+package achievement
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"child-bot/api/internal/catalogfile"
+	"child-bot/api/internal/store"
+)
+
+// Встроенный каталог: новая серия добавляется правкой JSON, без миграции
+//
+//go:embed catalog/achievements.json
+var catalogFS embed.FS
+
+// FileName имя файла каталога: встроенного и в каталоге контента на диске
+const FileName = "achievements.json"
+
+// Catalog версионированный каталог достижений
+type Catalog struct {
+	Version       int          `json:"version"`
+	DefaultLocale string       `json:"default_locale"`
+	Achievements  []Definition `json:"achievements"`
+}
+
+// Definition описание одного достижения (уровня серии)
+type Definition struct {
+	ID          string           `json:"id"`
+	Series      string           `json:"series"`
+	Level       int              `json:"level"`    // уровень внутри серии, с 1
+	Category    string           `json:"category"` // achievements.type: streak, tasks, villain, social, mastery
+	Icon        string           `json:"icon"`
+	ArtworkKey  string           `json:"artwork_key"`
+	Requirement Requirement      `json:"requirement"`
+	Reward      Reward           `json:"reward"`
+	Priority    int              `json:"priority"`
+	Texts       map[string]Texts `json:"texts"` // локаль -> тексты
+}
+
+// Requirement условие разблокировки
+type Requirement struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+// Reward награда за достижение
+type Reward struct {
+	Type   string `json:"type"`             // coins, xp, sticker, avatar, badge
+	ID     string `json:"id,omitempty"`     // для avatar — id аватара
+	Amount int    `json:"amount,omitempty"` // для coins и xp
+}
+
+// Texts локализованные тексты
+type Texts struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	RewardName  string `json:"reward_name"`
+}
+
+var idPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Известные типы требований (их считает движок достижений)
+var requirementTypes = map[string]bool{
+	store.RequirementTasksCorrect:      true,
+	store.RequirementTasksNoHints:      true,
+	store.RequirementErrorsFound:       true,
+	store.RequirementHintsUsed:         true,
+	store.RequirementVillainsDefeated:  true,
+	store.RequirementStreakDays:        true,
+	store.RequirementFriendsInvited:    true,
+	store.RequirementStickersCollected: true,
+}
+
+var rewardTypes = map[string]bool{
+	"coins": true, "xp": true, "sticker": true, "avatar": true, "badge": true, "streak_freeze": true,
+}
+
+// Default возвращает встроенный каталог
+func Default() (*Catalog, error) {
+	return Load("")
+}
+
+// Load читает каталог из файла path, а при пустом пути — встроенный
+func Load(path string) (*Catalog, error) {
+	return catalogfile.Load[Catalog](catalogFS, "catalog/"+FileName, path)
+}
+
+// Validate проверяет каталог и возвращает все найденные ошибки разом
+func (c *Catalog) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Version <= 0 {
+		add("version must be positive")
+	}
+	if c.DefaultLocale == "" {
+		add("default_locale is required")
+	}
+
+	ids := make(map[string]bool)
+	series := make(map[string][]Definition)
+	for i, d := range c.Achievements {
+		where := fmt.Sprintf("achievements[%d] %q", i, d.ID)
+
+		switch {
+		case d.ID == "":
+			add("achievements[%d]: id is required", i)
+		case len(d.ID) > 100 || !idPattern.MatchString(d.ID):
+			add("%s: id must match [a-z0-9_]+ and be at most 100 chars", where)
+		case ids[d.ID]:
+			add("%s: duplicate id", where)
+		}
+		ids[d.ID] = true
+
+		if d.Series == "" || !idPattern.MatchString(d.Series) {
+			add("%s: series must match [a-z0-9_]+", where)
+		}
+		if d.Level < 1 {
+			add("%s: level must be >= 1", where)
+		}
+		if d.Category == "" {
+			add("%s: category is required", where)
+		}
+		if d.Icon == "" {
+			add("%s: icon is required", where)
+		}
+		if d.ArtworkKey == "" {
+			add("%s: artwork_key is required", where)
+		}
+		if !requirementTypes[d.Requirement.Type] {
+			add("%s: unknown requirement type %q", where, d.Requirement.Type)
+		}
+		if d.Requirement.Value <= 0 {
+			add("%s: requirement value must be positive", where)
+		}
+
+		switch {
+		case !rewardTypes[d.Reward.Type]:
+			add("%s: unknown reward type %q", where, d.Reward.Type)
+		case (d.Reward.Type == "coins" || d.Reward.Type == "xp" || d.Reward.Type == "streak_freeze") && d.Reward.Amount <= 0:
+			add("%s: %s reward needs a positive amount", where, d.Reward.Type)
+		case d.Reward.Type == "avatar" && d.Reward.ID == "":
+			add("%s: avatar reward needs an id", where)
+		}
+
+		texts, ok := d.Texts[c.DefaultLocale]
+		if !ok {
+			add("%s: texts for default locale %q are required", where, c.DefaultLocale)
+		}
+		for locale, t := range d.Texts {
+			if t.Title == "" || t.Description == "" {
+				add("%s: %s title and description are required", where, locale)
+			}
+		}
+		if ok && texts.RewardName == "" {
+			add("%s: %s reward_name is required", where, c.DefaultLocale)
+		}
+
+		series[d.Series] = append(series[d.Series], d)
+	}
+
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = append(errs, c.validateSeries(name, series[name])...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateSeries проверяет согласованность уровней серии: общее условие и награда,
+// уровни 1..N без пропусков, значения растут вместе с уровнем
+func (c *Catalog) validateSeries(name string, levels []Definition) []error {
+	var errs []error
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Level < levels[j].Level })
+
+	first := levels[0]
+	for i, d := range levels {
+		if d.Level != i+1 {
+			errs = append(errs, fmt.Errorf("series %q: expected level %d, got %d (%s)", name, i+1, d.Level, d.ID))
+		}
+		if d.Requirement.Type != first.Requirement.Type {
+			errs = append(errs, fmt.Errorf("series %q: %s has requirement %q, series uses %q", name, d.ID, d.Requirement.Type, first.Requirement.Type))
+		}
+		if d.Reward.Type != first.Reward.Type {
+			errs = append(errs, fmt.Errorf("series %q: %s has reward %q, series uses %q", name, d.ID, d.Reward.Type, first.Reward.Type))
+		}
+		// По reward_name экран достижений собирает уровни серии
+		if d.Texts[c.DefaultLocale].RewardName != first.Texts[c.DefaultLocale].RewardName {
+			errs = append(errs, fmt.Errorf("series %q: %s has a different reward_name", name, d.ID))
+		}
+		if i > 0 && d.Requirement.Value <= levels[i-1].Requirement.Value {
+			errs = append(errs, fmt.Errorf("series %q: requirement value of %s must be greater than level %d", name, d.ID, levels[i-1].Level))
+		}
+	}
+	return errs
+}
+
+// Definitions переводит каталог в строки для синхронизации с БД
+func (c *Catalog) Definitions() ([]store.AchievementDefinition, error) {
+	defs := make([]store.AchievementDefinition, 0, len(c.Achievements))
+	for _, d := range c.Achievements {
+		texts := d.Texts[c.DefaultLocale]
+		localized, err := json.Marshal(d.Texts)
+		if err != nil {
+			return nil, fmt.Errorf("marshal texts for %s: %w", d.ID, err)
+		}
+		defs = append(defs, store.AchievementDefinition{
+			ID:               d.ID,
+			Category:         d.Category,
+			Series:           d.Series,
+			SeriesLevel:      d.Level,
+			Title:            texts.Title,
+			Description:      texts.Description,
+			Icon:             d.Icon,
+			ArtworkKey:       d.ArtworkKey,
+			RequirementType:  d.Requirement.Type,
+			RequirementValue: d.Requirement.Value,
+			RewardType:       d.Reward.Type,
+			RewardID:         d.Reward.ID,
+			RewardName:       texts.RewardName,
+			RewardAmount:     d.Reward.Amount,
+			Priority:         d.Priority,
+			Texts:            localized,
+			CatalogVersion:   c.Version,
+		})
+	}
+	return defs, nil
+}
+
+// Sync проверяет каталог и идемпотентно записывает его в БД
+func (c *Catalog) Sync(ctx context.Context, st *store.Store) (*store.AchievementSyncResult, error) {
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("catalog v%d is invalid: %w", c.Version, err)
+	}
+
+	defs, err := c.Definitions()
+	if err != nil {
+		return nil, err
+	}
+
+	return st.SyncAchievementCatalog(ctx, defs)
+}
@@ -0,0 +1,141 @@
+package achievement
+
+import (
+	"strings"
+	"testing"
+
+	"child-bot/api/internal/catalogfile"
+)
+
+func TestDefaultCatalogValid(t *testing.T) {
+	c, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("embedded catalog is invalid:\n%v", err)
+	}
+
+	defs, err := c.Definitions()
+	if err != nil {
+		t.Fatalf("Definitions() error = %v", err)
+	}
+	if len(defs) != len(c.Achievements) {
+		t.Errorf("Definitions() = %d, want %d", len(defs), len(c.Achievements))
+	}
+	for _, d := range defs {
+		if d.Title == "" || d.RewardName == "" || len(d.Texts) == 0 {
+			t.Errorf("definition %s has empty texts: %+v", d.ID, d)
+		}
+	}
+}
+
+func validDefinition(id, series string, level, value int) Definition {
+	return Definition{
+		ID:          id,
+		Series:      series,
+		Level:       level,
+		Category:    "tasks",
+		Icon:        "✅",
+		ArtworkKey:  "sticker_" + series,
+		Requirement: Requirement{Type: "tasks_correct", Value: value},
+		Reward:      Reward{Type: "sticker"},
+		Texts:       map[string]Texts{"ru": {Title: "Проверки", Description: "Описание", RewardName: "Проверки"}},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Catalog)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *Catalog) {},
+		},
+		{
+			name: "duplicate id",
+			modify: func(c *Catalog) {
+				c.Achievements[1].ID = c.Achievements[0].ID
+			},
+			wantErr: "duplicate id",
+		},
+		{
+			name: "level gap",
+			modify: func(c *Catalog) {
+				c.Achievements[1].Level = 3
+			},
+			wantErr: "expected level 2",
+		},
+		{
+			name: "value not increasing",
+			modify: func(c *Catalog) {
+				c.Achievements[1].Requirement.Value = 1
+			},
+			wantErr: "must be greater than level 1",
+		},
+		{
+			name: "unknown requirement",
+			modify: func(c *Catalog) {
+				c.Achievements[0].Requirement.Type = "tasks_count"
+			},
+			wantErr: `unknown requirement type "tasks_count"`,
+		},
+		{
+			name: "coins without amount",
+			modify: func(c *Catalog) {
+				c.Achievements[0].Reward.Type = "coins"
+				c.Achievements[1].Reward.Type = "coins"
+				c.Achievements[1].Reward.Amount = 10
+			},
+			wantErr: "coins reward needs a positive amount",
+		},
+		{
+			name: "missing default locale",
+			modify: func(c *Catalog) {
+				c.Achievements[0].Texts = map[string]Texts{"en": {Title: "Checks", Description: "Desc"}}
+			},
+			wantErr: `texts for default locale "ru" are required`,
+		},
+		{
+			name: "different reward name in series",
+			modify: func(c *Catalog) {
+				c.Achievements[1].Texts = map[string]Texts{"ru": {Title: "Проверки", Description: "Описание", RewardName: "Другое"}}
+			},
+			wantErr: "different reward_name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Catalog{
+				Version:       1,
+				DefaultLocale: "ru",
+				Achievements: []Definition{
+					validDefinition("checks_1", "checks", 1, 1),
+					validDefinition("checks_10", "checks", 2, 10),
+				},
+			}
+			tt.modify(c)
+
+			err := c.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	_, err := catalogfile.Parse[Catalog]([]byte(`{"version": 1, "default_locale": "ru", "achievements": [{"id": "x", "requirment": {}}]}`))
+	if err == nil || !strings.Contains(err.Error(), "requirment") {
+		t.Errorf("Parse() error = %v, want unknown field error", err)
+	}
+}
@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 
 	"child-bot/api/internal/api/middleware"
 	"child-bot/api/internal/api/response"
 	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/domain"
 	"child-bot/api/internal/store"
 )
 
@@ -95,6 +98,21 @@ type AchievementReward struct {
 	Amount   int    `json:"amount,omitempty"` // для coins
 }
 
+// ClaimRewardResponse ответ на получение награды за достижение
+type ClaimRewardResponse struct {
+	Claimed        bool   `json:"claimed"`
+	AlreadyClaimed bool   `json:"already_claimed"`
+	RewardType     string `json:"reward_type"`
+	RewardID       string `json:"reward_id,omitempty"`
+	RewardName     string `json:"reward_name,omitempty"`
+	Amount         int    `json:"amount,omitempty"`
+	Message        string `json:"message"`
+	CoinsBalance   int    `json:"coins_balance"`
+	XPTotal        int    `json:"xp_total"`
+	Level          int    `json:"level"`
+	LeveledUp      bool   `json:"leveled_up,omitempty"`
+}
+
 type AchievementsStats struct {
 	UnlockedCount   int     `json:"unlocked_count"`
 	TotalCount      int     `json:"total_count"`
@@ -463,18 +481,54 @@ func (h *AchievementHandler) Claim(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Phase 4 - забрать награду через service layer
-	// reward, err := h.service.ClaimAchievement(r.Context(), childProfileID, achievementID)
-
-	// Placeholder
-	reward := map[string]interface{}{
-		"claimed":     true,
-		"reward_type": "coins",
-		"amount":      50,
-		"message":     "Получено 50 монет!",
+	claim, err := h.store.ClaimAchievementReward(r.Context(), childProfileID, achievementID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Achievement not found")
+		case errors.Is(err, domain.ErrAchievementLocked):
+			response.Conflict(w, "Achievement is not unlocked yet")
+		default:
+			log.Printf("[AchievementHandler] Failed to claim achievement %s for %s: %v", achievementID, childProfileID, err)
+			response.InternalError(w, "Failed to claim reward")
+		}
+		return
 	}
 
-	response.OK(w, reward)
+	response.OK(w, ClaimRewardResponse{
+		Claimed:        true,
+		AlreadyClaimed: claim.AlreadyClaimed,
+		RewardType:     claim.RewardType,
+		RewardID:       claim.RewardID,
+		RewardName:     claim.RewardName,
+		Amount:         claim.RewardAmount,
+		Message:        claimMessage(claim),
+		CoinsBalance:   claim.CoinsBalance,
+		XPTotal:        claim.XPTotal,
+		Level:          claim.Level,
+		LeveledUp:      claim.LeveledUp,
+	})
+}
+
+// claimMessage формирует сообщение для ребёнка о полученной награде
+func claimMessage(claim *store.AchievementClaim) string {
+	if claim.AlreadyClaimed {
+		return "Награда уже получена"
+	}
+	switch claim.RewardType {
+	case "coins":
+		return fmt.Sprintf("Получено %d монет!", claim.RewardAmount)
+	case "xp":
+		return fmt.Sprintf("Получено %d XP!", claim.RewardAmount)
+	case "sticker":
+		return "Новый стикер в коллекции!"
+	case "avatar":
+		return "Новый аватар открыт!"
+	case "badge":
+		return "Новый значок получен!"
+	default:
+		return "Награда получена!"
+	}
 }
 
 // GetStats получает статистику достижений
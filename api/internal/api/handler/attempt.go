@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
 	"child-bot/api/internal/api/middleware"
 	"child-bot/api/internal/api/response"
@@ -13,6 +14,7 @@ import (
 	"child-bot/api/internal/domain"
 	"child-bot/api/internal/llm/types"
 	"child-bot/api/internal/service"
+	"child-bot/api/internal/store"
 )
 
 // AttemptServiceInterface определяет интерфейс для AttemptService
@@ -20,12 +22,19 @@ type AttemptServiceInterface interface {
 	CreateAttempt(ctx context.Context, childProfileID, attemptType string) (string, error)
 	UploadImage(ctx context.Context, attemptID, imageType, imageData string) (string, error)
 	ProcessHelp(ctx context.Context, attemptID, imageBase64 string) error
-	ProcessCheck(ctx context.Context, attemptID, childProfileID, taskImageBase64, answerImageBase64 string) error
+	ProcessCheck(ctx context.Context, attemptID, childProfileID, taskImageBase64, answerImageBase64, powerUpID string) error
+	StartPageTaskCheck(ctx context.Context, attemptID string) error
 	GetAttemptResult(ctx context.Context, attemptID string) (*service.AttemptData, error)
 	GetNextHint(ctx context.Context, attemptID string) (*domain.HelpResult, error)
 	DeleteAttempt(ctx context.Context, attemptID string) error
 	GetUnfinishedAttempt(ctx context.Context, childProfileID string) (*service.AttemptData, error)
 	GetRecentAttempts(ctx context.Context, childProfileID string, limit int) ([]service.AttemptData, error)
+	ExplainMistakes(ctx context.Context, attemptID, childProfileID string) (*domain.MistakeStep, error)
+	NextMistake(ctx context.Context, attemptID, childProfileID string) (*domain.MistakeStep, error)
+	ProcessPage(ctx context.Context, attemptID, imageBase64 string) error
+	GetPageProgress(ctx context.Context, attemptID, childProfileID string) (*domain.PageProgress, error)
+	RateHint(ctx context.Context, attemptID, childProfileID string, hintIndex int, rating, comment string) error
+	RegenerateHint(ctx context.Context, attemptID, childProfileID string, hintIndex int, rating, comment string) (*domain.RegeneratedHint, error)
 }
 
 // AttemptHandler обрабатывает запросы, связанные с попытками
@@ -150,6 +159,17 @@ func (h *AttemptHandler) Process(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Тело необязательно: усилитель ребёнок выбирает сам перед проверкой
+	var req ProcessAttemptRequest
+	if err := validation.DecodeJSON(r, &req); err != nil && !errors.Is(err, validation.ErrEmptyBody) {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if req.PowerUpID != "" && req.PowerUpID != store.PowerUpDoubleHit {
+		response.BadRequest(w, "unknown power_up_id")
+		return
+	}
+
 	// Получаем данные попытки
 	attemptData, err := h.service.GetAttemptResult(r.Context(), attemptID)
 	if err != nil {
@@ -171,7 +191,33 @@ func (h *AttemptHandler) Process(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Обрабатываем в зависимости от типа
-	if attemptData.Type == "help" {
+	if attemptData.Type == "help" && attemptData.ParentAttemptID != "" && attemptData.AnswerImageData != "" {
+		// Задача со страницы с загруженным решением — проверяем её (урон злодею за каждую решённую задачу)
+		if err := h.service.StartPageTaskCheck(r.Context(), attemptID); err != nil {
+			if errors.Is(err, domain.ErrConflict) {
+				response.Conflict(w, "Task is already solved or is being checked")
+				return
+			}
+			log.Printf("[AttemptHandler] Failed to start check for page task %s: %v", attemptID, err)
+			response.InternalError(w, "Failed to start check")
+			return
+		}
+
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("[AttemptHandler] PANIC in ProcessCheck goroutine for page task %s: %v", attemptID, r)
+				}
+			}()
+
+			ctx := context.Background()
+			err := h.service.ProcessCheck(ctx, attemptID, childProfileID,
+				attemptData.TaskImageData, attemptData.AnswerImageData, req.PowerUpID)
+			if err != nil {
+				log.Printf("[AttemptHandler] ProcessCheck failed for page task %s: %v", attemptID, err)
+			}
+		}()
+	} else if attemptData.Type == "help" {
 		// Запускаем обработку в goroutine (не блокируем запрос)
 		go func() {
 			// Восстановление после паники
@@ -197,12 +243,27 @@ func (h *AttemptHandler) Process(w http.ResponseWriter, r *http.Request) {
 		go func() {
 			ctx := context.Background()
 			err := h.service.ProcessCheck(ctx, attemptID, childProfileID,
-				attemptData.TaskImageData, attemptData.AnswerImageData)
+				attemptData.TaskImageData, attemptData.AnswerImageData, req.PowerUpID)
 			if err != nil {
 				log.Printf("[AttemptHandler] ProcessCheck failed for attempt %s: %v", attemptID, err)
 				// Note: ProcessCheck service already updates status to "failed" on errors
 			}
 		}()
+	} else if attemptData.Type == "page" {
+		// Разбиваем страницу на задачи в goroutine
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("[AttemptHandler] PANIC in ProcessPage goroutine for attempt %s: %v", attemptID, r)
+				}
+			}()
+
+			ctx := context.Background()
+			err := h.service.ProcessPage(ctx, attemptID, attemptData.TaskImageData)
+			if err != nil {
+				log.Printf("[AttemptHandler] ProcessPage failed for attempt %s: %v", attemptID, err)
+			}
+		}()
 	}
 
 	response.OK(w, ProcessAttemptResponse{
@@ -260,6 +321,11 @@ func (h *AttemptHandler) GetResult(w http.ResponseWriter, r *http.Request) {
 		}
 		resultData["hints"] = hintsArray
 
+		// Политика подсказок, подобранная по истории ребёнка
+		if attemptData.HintPolicy != nil {
+			resultData["hint_policy"] = attemptData.HintPolicy
+		}
+
 		// Добавляем изображение задания для передачи на страницу проверки
 		if attemptData.TaskImageData != "" {
 			resultData["task_image"] = attemptData.TaskImageData
@@ -367,6 +433,217 @@ func (h *AttemptHandler) NextHint(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetPage возвращает задачи страницы домашки и прогресс её решения
+// GET /attempts/{id}/page
+func (h *AttemptHandler) GetPage(w http.ResponseWriter, r *http.Request) {
+	attemptID := r.PathValue("id")
+	if err := validation.ValidateUUID(attemptID); err != nil {
+		response.BadRequest(w, "invalid attempt_id: "+err.Error())
+		return
+	}
+
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	progress, err := h.service.GetPageProgress(r.Context(), attemptID, childProfileID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrForbidden):
+			response.Forbidden(w, "Attempt belongs to another user")
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.BadRequest(w, "Attempt is not a homework page")
+		default:
+			log.Printf("[AttemptHandler] Failed to get page progress for %s: %v", attemptID, err)
+			response.InternalError(w, "Failed to get page progress")
+		}
+		return
+	}
+
+	response.OK(w, progress)
+}
+
+// RateHint сохраняет оценку показанной подсказки
+// POST /attempts/{id}/hints/{index}/rating
+func (h *AttemptHandler) RateHint(w http.ResponseWriter, r *http.Request) {
+	attemptID, childProfileID, hintIndex, req, ok := h.decodeHintFeedback(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RateHint(r.Context(), attemptID, childProfileID, hintIndex, req.Rating, req.Comment); err != nil {
+		h.writeHintFeedbackError(w, attemptID, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// RegenerateHint перегенерирует показанную подсказку на том же уровне с учётом оценки
+// POST /attempts/{id}/hints/{index}/regenerate
+func (h *AttemptHandler) RegenerateHint(w http.ResponseWriter, r *http.Request) {
+	attemptID, childProfileID, hintIndex, req, ok := h.decodeHintFeedback(w, r)
+	if !ok {
+		return
+	}
+
+	hint, err := h.service.RegenerateHint(r.Context(), attemptID, childProfileID, hintIndex, req.Rating, req.Comment)
+	if err != nil {
+		h.writeHintFeedbackError(w, attemptID, err)
+		return
+	}
+
+	response.OK(w, RegenerateHintResponse{
+		Hint:              hint.Hint,
+		HintIndex:         hint.HintIndex,
+		TotalHints:        hint.TotalHints,
+		RegenerationsLeft: hint.RegenerationsLeft,
+	})
+}
+
+// decodeHintFeedback валидирует параметры запроса оценки подсказки
+func (h *AttemptHandler) decodeHintFeedback(w http.ResponseWriter, r *http.Request) (string, string, int, HintFeedbackRequest, bool) {
+	var req HintFeedbackRequest
+
+	attemptID := r.PathValue("id")
+	if err := validation.ValidateUUID(attemptID); err != nil {
+		response.BadRequest(w, "invalid attempt_id: "+err.Error())
+		return "", "", 0, req, false
+	}
+
+	hintIndex, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || hintIndex < 0 {
+		response.BadRequest(w, "invalid hint index")
+		return "", "", 0, req, false
+	}
+
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return "", "", 0, req, false
+	}
+
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		response.BadRequest(w, err.Error())
+		return "", "", 0, req, false
+	}
+
+	if err := validation.ValidateMaxLength(req.Comment, "comment", service.MaxHintCommentLength); err != nil {
+		response.BadRequest(w, err.Error())
+		return "", "", 0, req, false
+	}
+
+	return attemptID, childProfileID, hintIndex, req, true
+}
+
+// writeHintFeedbackError преобразует ошибки оценки подсказки в HTTP ответ
+func (h *AttemptHandler) writeHintFeedbackError(w http.ResponseWriter, attemptID string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrForbidden):
+		response.Forbidden(w, "Attempt belongs to another user")
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.BadRequest(w, "Invalid rating or hint has not been shown yet")
+	case errors.Is(err, domain.ErrRegenerationLimitReached):
+		response.ErrorWithCode(w, http.StatusConflict, "Hint regeneration limit reached", "regeneration_limit_reached")
+	default:
+		log.Printf("[AttemptHandler] Failed to process hint feedback for attempt %s: %v", attemptID, err)
+		response.InternalError(w, "Failed to process hint feedback")
+	}
+}
+
+// ExplainMistakes возвращает разбор текущей ошибки неверно решённой check попытки
+// POST /attempts/{id}/explain-mistakes
+func (h *AttemptHandler) ExplainMistakes(w http.ResponseWriter, r *http.Request) {
+	attemptID := r.PathValue("id")
+	if err := validation.ValidateUUID(attemptID); err != nil {
+		response.BadRequest(w, "invalid attempt_id: "+err.Error())
+		return
+	}
+
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	step, err := h.service.ExplainMistakes(r.Context(), attemptID, childProfileID)
+	if err != nil {
+		h.writeMistakeError(w, attemptID, err)
+		return
+	}
+
+	response.OK(w, newMistakeStepResponse(step))
+}
+
+// NextMistake переходит к следующей ошибке в разборе
+// POST /attempts/{id}/next-mistake
+func (h *AttemptHandler) NextMistake(w http.ResponseWriter, r *http.Request) {
+	attemptID := r.PathValue("id")
+	if err := validation.ValidateUUID(attemptID); err != nil {
+		response.BadRequest(w, "invalid attempt_id: "+err.Error())
+		return
+	}
+
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	step, err := h.service.NextMistake(r.Context(), attemptID, childProfileID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoMistakesLeft) {
+			response.OK(w, MistakeStepResponse{
+				Hints:        []string{},
+				MistakeIndex: -1,
+				Completed:    true,
+			})
+			return
+		}
+		h.writeMistakeError(w, attemptID, err)
+		return
+	}
+
+	response.OK(w, newMistakeStepResponse(step))
+}
+
+// writeMistakeError преобразует ошибки разбора в HTTP ответ
+func (h *AttemptHandler) writeMistakeError(w http.ResponseWriter, attemptID string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrForbidden):
+		response.Forbidden(w, "Attempt belongs to another user")
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.BadRequest(w, "Attempt has no mistakes to explain")
+	case errors.Is(err, domain.ErrNotFound):
+		response.NotFound(w, "Mistakes explanation not started")
+	default:
+		log.Printf("[AttemptHandler] Failed to explain mistakes for attempt %s: %v", attemptID, err)
+		response.InternalError(w, "Failed to explain mistakes")
+	}
+}
+
+// newMistakeStepResponse формирует ответ из шага разбора
+func newMistakeStepResponse(step *domain.MistakeStep) MistakeStepResponse {
+	hints := step.Mistake.Hints
+	if hints == nil {
+		hints = []string{}
+	}
+	return MistakeStepResponse{
+		Label:          step.Mistake.Label,
+		Title:          translateErrorToRussian(step.Mistake.Label),
+		Fragment:       step.Mistake.Fragment,
+		Explanation:    step.Mistake.Explanation,
+		CommonMistake:  step.Mistake.CommonMistake,
+		Hints:          hints,
+		MistakeIndex:   step.MistakeIndex,
+		TotalMistakes:  step.TotalMistakes,
+		HasMoreMistake: step.MistakeIndex < step.TotalMistakes-1,
+		Completed:      false,
+	}
+}
+
 // Delete удаляет попытку
 // DELETE /attempts/{id}
 func (h *AttemptHandler) Delete(w http.ResponseWriter, r *http.Request) {
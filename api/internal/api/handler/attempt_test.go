@@ -5,11 +5,19 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"child-bot/api/internal/domain"
 	"child-bot/api/internal/service"
 )
 
+// Attempt handlers validate attempt_id as a UUID
+const (
+	testAttemptID      = "3f6c2a1e-8b4d-4c7a-9e2f-1a5b7c9d0e12"
+	missingAttemptID   = "9d8c7b6a-5f4e-4d3c-8b2a-1f0e9d8c7b6a"
+	testChildProfileID = "5a4b3c2d-1e0f-4a9b-8c7d-6e5f4a3b2c1d"
+)
+
 func TestAttemptHandler_Create(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -31,14 +39,14 @@ func TestAttemptHandler_Create(t *testing.T) {
 				if attemptType != "help" {
 					t.Errorf("unexpected attemptType: %s", attemptType)
 				}
-				return "attempt-123", nil
+				return testAttemptID, nil
 			},
 			expectedStatus: http.StatusCreated,
 			checkResponse: func(t *testing.T, w *mockResponseWriter) {
 				var resp CreateAttemptResponse
 				decodeResponse(t, w, &resp)
-				if resp.AttemptID != "attempt-123" {
-					t.Errorf("expected attempt_id 'attempt-123', got %s", resp.AttemptID)
+				if resp.AttemptID != testAttemptID {
+					t.Errorf("expected attempt_id %s, got %s", testAttemptID, resp.AttemptID)
 				}
 				if resp.Status != "created" {
 					t.Errorf("expected status 'created', got %s", resp.Status)
@@ -147,13 +155,13 @@ func TestAttemptHandler_UploadImage(t *testing.T) {
 	}{
 		{
 			name:      "success - task image",
-			attemptID: "attempt-123",
+			attemptID: testAttemptID,
 			requestBody: map[string]string{
 				"image_type": "task",
-				"image_data": "iVBORw0KGgoAAAANSUhEUg...",
+				"image_data": "data:image/png;base64,iVBORw0KGgoAAAANSUhEUg...",
 			},
 			mockUpload: func(ctx context.Context, attemptID, imageType, imageData string) (string, error) {
-				if attemptID != "attempt-123" {
+				if attemptID != testAttemptID {
 					t.Errorf("unexpected attemptID: %s", attemptID)
 				}
 				if imageType != "task" {
@@ -165,10 +173,10 @@ func TestAttemptHandler_UploadImage(t *testing.T) {
 		},
 		{
 			name:      "success - answer image",
-			attemptID: "attempt-123",
+			attemptID: testAttemptID,
 			requestBody: map[string]string{
 				"image_type": "answer",
-				"image_data": "iVBORw0KGgoAAAANSUhEUg...",
+				"image_data": "data:image/png;base64,iVBORw0KGgoAAAANSUhEUg...",
 			},
 			mockUpload: func(ctx context.Context, attemptID, imageType, imageData string) (string, error) {
 				return "https://storage.example.com/answer123.jpg", nil
@@ -177,16 +185,16 @@ func TestAttemptHandler_UploadImage(t *testing.T) {
 		},
 		{
 			name:      "validation error - invalid image_type",
-			attemptID: "attempt-123",
+			attemptID: testAttemptID,
 			requestBody: map[string]string{
 				"image_type": "invalid",
-				"image_data": "iVBORw0KGgoAAAANSUhEUg...",
+				"image_data": "data:image/png;base64,iVBORw0KGgoAAAANSUhEUg...",
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			name:      "validation error - missing image_data",
-			attemptID: "attempt-123",
+			attemptID: testAttemptID,
 			requestBody: map[string]string{
 				"image_type": "task",
 			},
@@ -194,10 +202,10 @@ func TestAttemptHandler_UploadImage(t *testing.T) {
 		},
 		{
 			name:      "service error",
-			attemptID: "attempt-123",
+			attemptID: testAttemptID,
 			requestBody: map[string]string{
 				"image_type": "task",
-				"image_data": "iVBORw0KGgoAAAANSUhEUg...",
+				"image_data": "data:image/png;base64,iVBORw0KGgoAAAANSUhEUg...",
 			},
 			mockUpload: func(ctx context.Context, attemptID, imageType, imageData string) (string, error) {
 				return "", errors.New("upload failed")
@@ -228,34 +236,39 @@ func TestAttemptHandler_Process(t *testing.T) {
 	tests := []struct {
 		name           string
 		attemptID      string
-		mockProcess    func(ctx context.Context, attemptID, imageBase64 string) error
+		processErr     error
 		expectedStatus int
 	}{
 		{
-			name:      "success - help attempt",
-			attemptID: "attempt-123",
-			mockProcess: func(ctx context.Context, attemptID, imageBase64 string) error {
-				if attemptID != "attempt-123" {
-					t.Errorf("unexpected attemptID: %s", attemptID)
-				}
-				return nil
-			},
+			name:           "success - help attempt",
+			attemptID:      testAttemptID,
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:      "service error",
-			attemptID: "attempt-123",
-			mockProcess: func(ctx context.Context, attemptID, imageBase64 string) error {
-				return errors.New("processing failed")
-			},
+			name:           "service error",
+			attemptID:      testAttemptID,
+			processErr:     errors.New("processing failed"),
 			expectedStatus: http.StatusOK,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			processed := make(chan string, 1)
 			mockService := &mockAttemptService{
-				processHelpFunc: tt.mockProcess,
+				getAttemptResultFunc: func(ctx context.Context, attemptID string) (*service.AttemptData, error) {
+					return &service.AttemptData{
+						ID:             attemptID,
+						ChildProfileID: testChildProfileID,
+						Type:           "help",
+						TaskImageData:  "data:image/png;base64,iVBORw0KGgo",
+					}, nil
+				},
+				// Processing runs in the background: the response does not wait for it
+				processHelpFunc: func(ctx context.Context, attemptID, imageBase64 string) error {
+					processed <- attemptID
+					return tt.processErr
+				},
 			}
 
 			handler := NewAttemptHandler(mockService)
@@ -263,9 +276,17 @@ func TestAttemptHandler_Process(t *testing.T) {
 			req := makeRequest(t, http.MethodPost, "/attempts/"+tt.attemptID+"/process", nil)
 			w := newMockResponseWriter()
 
-			handler.Process(w, req)
+			handler.Process(w, withChildProfile(req, testChildProfileID))
 
 			assertStatus(t, w, tt.expectedStatus)
+			select {
+			case attemptID := <-processed:
+				if attemptID != tt.attemptID {
+					t.Errorf("unexpected attemptID: %s", attemptID)
+				}
+			case <-time.After(time.Second):
+				t.Error("ProcessHelp was not called")
+			}
 		})
 	}
 }
@@ -280,7 +301,7 @@ func TestAttemptHandler_GetResult(t *testing.T) {
 	}{
 		{
 			name:      "success - result available",
-			attemptID: "attempt-123",
+			attemptID: testAttemptID,
 			mockGetResult: func(ctx context.Context, attemptID string) (*service.AttemptData, error) {
 				return &service.AttemptData{
 					ID:     attemptID,
@@ -292,18 +313,18 @@ func TestAttemptHandler_GetResult(t *testing.T) {
 			checkResponse: func(t *testing.T, w *mockResponseWriter) {
 				var resp map[string]interface{}
 				decodeResponse(t, w, &resp)
-				if resp["attempt_id"] != "attempt-123" {
+				if resp["attempt_id"] != testAttemptID {
 					t.Errorf("unexpected attempt_id: %v", resp["attempt_id"])
 				}
 			},
 		},
 		{
 			name:      "not found",
-			attemptID: "attempt-999",
+			attemptID: missingAttemptID,
 			mockGetResult: func(ctx context.Context, attemptID string) (*service.AttemptData, error) {
 				return nil, errors.New("not found")
 			},
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusInternalServerError,
 		},
 	}
 
@@ -338,7 +359,7 @@ func TestAttemptHandler_NextHint(t *testing.T) {
 	}{
 		{
 			name:      "success - hint available",
-			attemptID: "attempt-123",
+			attemptID: testAttemptID,
 			mockNextHint: func(ctx context.Context, attemptID string) (*domain.HelpResult, error) {
 				return &domain.HelpResult{
 					Subject:     "Math",
@@ -360,11 +381,11 @@ func TestAttemptHandler_NextHint(t *testing.T) {
 		},
 		{
 			name:      "service error",
-			attemptID: "attempt-123",
+			attemptID: testAttemptID,
 			mockNextHint: func(ctx context.Context, attemptID string) (*domain.HelpResult, error) {
 				return nil, errors.New("hint generation failed")
 			},
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusInternalServerError,
 		},
 	}
 
@@ -398,7 +419,7 @@ func TestAttemptHandler_Delete(t *testing.T) {
 	}{
 		{
 			name:      "success",
-			attemptID: "attempt-123",
+			attemptID: testAttemptID,
 			mockDelete: func(ctx context.Context, attemptID string) error {
 				return nil
 			},
@@ -406,7 +427,7 @@ func TestAttemptHandler_Delete(t *testing.T) {
 		},
 		{
 			name:      "service error",
-			attemptID: "attempt-123",
+			attemptID: testAttemptID,
 			mockDelete: func(ctx context.Context, attemptID string) error {
 				return errors.New("delete failed")
 			},
@@ -1,17 +1,23 @@
 package handler
 
 import (
+	"log"
 	"net/http"
 
+	"child-bot/api/internal/api/middleware"
 	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/service"
+	"child-bot/api/internal/store"
 )
 
 // AvatarHandler обрабатывает запросы аватаров
-type AvatarHandler struct{}
+type AvatarHandler struct {
+	shop *service.ShopService
+}
 
 // NewAvatarHandler создает новый AvatarHandler
-func NewAvatarHandler() *AvatarHandler {
-	return &AvatarHandler{}
+func NewAvatarHandler(shop *service.ShopService) *AvatarHandler {
+	return &AvatarHandler{shop: shop}
 }
 
 // Avatar представляет доступный аватар
@@ -20,23 +26,35 @@ type Avatar struct {
 	Name      string `json:"name"`
 	ImageURL  string `json:"imageUrl"`
 	IsPremium bool   `json:"isPremium"`
+	Price     int    `json:"price"`
+	MinLevel  int    `json:"minLevel"`
+	Owned     bool   `json:"owned"` // без профиля — только бесплатные
 }
 
-// GetAll возвращает список доступных аватаров
+// GetAll возвращает список аватаров из каталога магазина
 // GET /avatars
 func (h *AvatarHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	// Список доступных аватаров (пока hardcoded)
-	avatars := []Avatar{
-		{ID: "cat", Name: "Кот", ImageURL: "🐱", IsPremium: false},
-		{ID: "dog", Name: "Пёс", ImageURL: "🐶", IsPremium: false},
-		{ID: "panda", Name: "Панда", ImageURL: "🐼", IsPremium: false},
-		{ID: "fox", Name: "Лиса", ImageURL: "🦊", IsPremium: false},
-		{ID: "bear", Name: "Медведь", ImageURL: "🐻", IsPremium: false},
-		{ID: "lion", Name: "Лев", ImageURL: "🦁", IsPremium: false},
-		{ID: "tiger", Name: "Тигр", ImageURL: "🐯", IsPremium: true},
-		{ID: "unicorn", Name: "Единорог", ImageURL: "🦄", IsPremium: true},
-		{ID: "robot", Name: "Робот", ImageURL: "🤖", IsPremium: false},
-		{ID: "alien", Name: "Пришелец", ImageURL: "👽", IsPremium: true},
+	// Профиля может ещё не быть (выбор аватара при регистрации)
+	childProfileID := middleware.GetChildProfileID(r.Context())
+
+	catalog, err := h.shop.GetCatalog(r.Context(), childProfileID, store.ShopCategoryAvatar)
+	if err != nil {
+		log.Printf("[AvatarHandler] Failed to load avatars: %v", err)
+		response.InternalError(w, "Failed to load avatars")
+		return
+	}
+
+	avatars := make([]Avatar, 0, len(catalog.Items))
+	for _, item := range catalog.Items {
+		avatars = append(avatars, Avatar{
+			ID:        item.ID,
+			Name:      item.Name,
+			ImageURL:  item.Icon,
+			IsPremium: item.IsPremium,
+			Price:     item.Price,
+			MinLevel:  item.MinLevel,
+			Owned:     item.Owned,
+		})
 	}
 
 	response.OK(w, avatars)
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/service"
+)
+
+// BossServiceInterface интерфейс для BossService
+type BossServiceInterface interface {
+	GetWeeklyBoss(ctx context.Context, childProfileID string) (*service.BossFight, error)
+}
+
+// BossHandler обрабатывает запросы недельного босса
+type BossHandler struct {
+	service BossServiceInterface
+}
+
+// NewBossHandler создает новый BossHandler
+func NewBossHandler(bossService BossServiceInterface) *BossHandler {
+	return &BossHandler{service: bossService}
+}
+
+// BossRequirement что нужно решать, чтобы ранить босса
+type BossRequirement struct {
+	Subject      string `json:"subject,omitempty"`
+	SubjectName  string `json:"subject_name,omitempty"`
+	TaskType     string `json:"task_type,omitempty"`
+	TaskTypeName string `json:"task_type_name,omitempty"`
+}
+
+// BossPhase фаза босса
+type BossPhase struct {
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	HP          int             `json:"hp"`
+	MaxHP       int             `json:"max_hp"`
+	Status      string          `json:"status"` // completed, current, locked
+	Requirement BossRequirement `json:"requirement"`
+}
+
+// BossReward награда за победу над боссом
+type BossReward struct {
+	Type     string `json:"type"` // coins, xp, item
+	ItemID   string `json:"item_id,omitempty"`
+	ItemName string `json:"item_name,omitempty"`
+	Amount   int    `json:"amount"`
+}
+
+// BossDamageEvent удар по боссу
+type BossDamageEvent struct {
+	Phase      int           `json:"phase"`
+	Damage     int           `json:"damage"`
+	IsCritical bool          `json:"is_critical"`
+	Bonuses    []DamageBonus `json:"bonuses"`
+	Subject    string        `json:"subject,omitempty"`
+	CreatedAt  string        `json:"created_at"`
+}
+
+// BossFightResponse экран битвы с недельным боссом
+type BossFightResponse struct {
+	BattleID      int64             `json:"battle_id"`
+	VillainID     string            `json:"villain_id"`
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	ImageURL      string            `json:"image_url"`
+	Level         int               `json:"level"`
+	Status        string            `json:"status"` // active, defeated, expired
+	WeekStart     string            `json:"week_start"`
+	EndsOn        string            `json:"ends_on"`
+	Today         string            `json:"today"`
+	DaysLeft      int               `json:"days_left"`
+	HP            int               `json:"hp"`
+	MaxHP         int               `json:"max_hp"`
+	CurrentPhase  int               `json:"current_phase"`
+	Phases        []BossPhase       `json:"phases"`
+	Next          *BossRequirement  `json:"next,omitempty"`
+	RegenHPPerDay int               `json:"regen_hp_per_day"`
+	HealedNow     int               `json:"healed_now"`
+	RegenTotal    int               `json:"regen_total"`
+	TotalDamage   int               `json:"total_damage"`
+	TasksCount    int               `json:"tasks_count"`
+	Rewards       []BossReward      `json:"rewards"`
+	RecentDamage  []BossDamageEvent `json:"recent_damage"`
+	DefeatedAt    string            `json:"defeated_at,omitempty"`
+}
+
+// GetWeekly возвращает босса текущей недели и состояние битвы
+// GET /boss
+func (h *BossHandler) GetWeekly(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	fight, err := h.service.GetWeeklyBoss(r.Context(), childProfileID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.NotFound(w, "Weekly boss not found")
+			return
+		}
+		log.Printf("[BossHandler] Failed to get weekly boss for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get weekly boss")
+		return
+	}
+
+	phases := make([]BossPhase, 0, len(fight.Phases))
+	for _, p := range fight.Phases {
+		phases = append(phases, BossPhase{
+			Number:      p.Number,
+			Title:       p.Title,
+			HP:          p.HP,
+			MaxHP:       p.MaxHP,
+			Status:      p.Status,
+			Requirement: toBossRequirement(p.Requirement),
+		})
+	}
+
+	rewards := make([]BossReward, 0, len(fight.Rewards))
+	for _, rw := range fight.Rewards {
+		rewards = append(rewards, BossReward{
+			Type:     rw.Type,
+			ItemID:   rw.ItemID,
+			ItemName: rw.ItemName,
+			Amount:   rw.Amount,
+		})
+	}
+
+	recentDamage := make([]BossDamageEvent, 0, len(fight.RecentDamage))
+	for _, event := range fight.RecentDamage {
+		bonuses := make([]DamageBonus, 0, len(event.Bonuses))
+		for _, bonus := range event.Bonuses {
+			bonuses = append(bonuses, DamageBonus{
+				Code:   bonus.Code,
+				Label:  bonus.Label,
+				Damage: bonus.Damage,
+			})
+		}
+		recentDamage = append(recentDamage, BossDamageEvent{
+			Phase:      event.Phase,
+			Damage:     event.Damage,
+			IsCritical: event.IsCritical,
+			Bonuses:    bonuses,
+			Subject:    event.Subject,
+			CreatedAt:  event.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	resp := BossFightResponse{
+		BattleID:      fight.BattleID,
+		VillainID:     fight.VillainID,
+		Name:          fight.Name,
+		Description:   fight.Description,
+		ImageURL:      fight.ImageURL,
+		Level:         fight.Level,
+		Status:        fight.Status,
+		WeekStart:     fight.WeekStart,
+		EndsOn:        fight.EndsOn,
+		Today:         fight.Today,
+		DaysLeft:      fight.DaysLeft,
+		HP:            fight.HP,
+		MaxHP:         fight.MaxHP,
+		CurrentPhase:  fight.CurrentPhase,
+		Phases:        phases,
+		RegenHPPerDay: fight.RegenHPPerDay,
+		HealedNow:     fight.HealedNow,
+		RegenTotal:    fight.RegenTotal,
+		TotalDamage:   fight.TotalDamage,
+		TasksCount:    fight.TasksCount,
+		Rewards:       rewards,
+		RecentDamage:  recentDamage,
+	}
+	if fight.Next != nil {
+		next := toBossRequirement(*fight.Next)
+		resp.Next = &next
+	}
+	if fight.DefeatedAt != nil {
+		resp.DefeatedAt = fight.DefeatedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	response.OK(w, resp)
+}
+
+func toBossRequirement(r service.BossRequirement) BossRequirement {
+	return BossRequirement{
+		Subject:      r.Subject,
+		SubjectName:  r.SubjectName,
+		TaskType:     r.TaskType,
+		TaskTypeName: r.TaskTypeName,
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/service"
+)
+
+// DialogHandler обрабатывает диалог ребёнка с наставником по попытке
+type DialogHandler struct {
+	service *service.DialogService
+}
+
+// NewDialogHandler создает новый DialogHandler
+func NewDialogHandler(service *service.DialogService) *DialogHandler {
+	return &DialogHandler{service: service}
+}
+
+// SendMessageRequest вопрос ребёнка по задаче
+type SendMessageRequest struct {
+	Text string `json:"text"`
+}
+
+// DialogMessageResponse сообщение диалога
+type DialogMessageResponse struct {
+	Role      string `json:"role"` // child или tutor
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+}
+
+// SendMessageResponse ответ наставника
+type SendMessageResponse struct {
+	Reply         DialogMessageResponse `json:"reply"`
+	QuestionsLeft int                   `json:"questions_left"`
+}
+
+// SendMessage принимает вопрос ребёнка и возвращает ответ наставника
+// POST /attempts/{id}/messages
+func (h *DialogHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	attemptID := r.PathValue("id")
+	if err := validation.ValidateUUID(attemptID); err != nil {
+		response.BadRequest(w, "invalid attempt_id: "+err.Error())
+		return
+	}
+
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	var req SendMessageRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := validation.ValidateRequired(req.Text, "text"); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := validation.ValidateMaxLength(req.Text, "text", service.MaxDialogMessageLength); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	reply, err := h.service.SendAttemptMessage(r.Context(), attemptID, childProfileID, req.Text)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrForbidden):
+			response.Forbidden(w, "Attempt belongs to another user")
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.BadRequest(w, "Attempt is not ready for questions yet")
+		case errors.Is(err, domain.ErrDialogLimitReached):
+			response.ErrorWithCode(w, http.StatusConflict, "Too many questions for this task", "dialog_limit_reached")
+		case errors.Is(err, domain.ErrRateLimited):
+			w.Header().Set("Retry-After", "60")
+			response.Error(w, http.StatusTooManyRequests, "Too many questions, please wait a bit")
+		default:
+			log.Printf("[DialogHandler] Failed to send message for attempt %s: %v", attemptID, err)
+			response.InternalError(w, "Failed to send message")
+		}
+		return
+	}
+
+	response.OK(w, SendMessageResponse{
+		Reply:         newDialogMessageResponse(reply.Message),
+		QuestionsLeft: reply.QuestionsLeft,
+	})
+}
+
+// ListMessages возвращает историю диалога по попытке
+// GET /attempts/{id}/messages
+func (h *DialogHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	attemptID := r.PathValue("id")
+	if err := validation.ValidateUUID(attemptID); err != nil {
+		response.BadRequest(w, "invalid attempt_id: "+err.Error())
+		return
+	}
+
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	messages, err := h.service.GetAttemptMessages(r.Context(), attemptID, childProfileID)
+	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			response.Forbidden(w, "Attempt belongs to another user")
+			return
+		}
+		log.Printf("[DialogHandler] Failed to get messages for attempt %s: %v", attemptID, err)
+		response.InternalError(w, "Failed to get messages")
+		return
+	}
+
+	result := make([]DialogMessageResponse, 0, len(messages))
+	asked := 0
+	for _, m := range messages {
+		if m.Role == domain.DialogRoleChild {
+			asked++
+		}
+		result = append(result, newDialogMessageResponse(m))
+	}
+
+	response.OK(w, map[string]interface{}{
+		"messages":       result,
+		"questions_left": max(service.MaxDialogQuestions-asked, 0),
+	})
+}
+
+// newDialogMessageResponse преобразует сообщение в формат API
+func newDialogMessageResponse(m domain.DialogMessage) DialogMessageResponse {
+	return DialogMessageResponse{
+		Role:      m.Role,
+		Text:      m.Text,
+		CreatedAt: m.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,399 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/family"
+	"child-bot/api/internal/service"
+	"child-bot/api/internal/store"
+)
+
+// familyNotificationsLimit сколько последних уведомлений отдаётся
+const familyNotificationsLimit = 50
+
+// FamilyServiceInterface интерфейс для FamilyService
+type FamilyServiceInterface interface {
+	GetQuests(ctx context.Context, childProfileID string) (*service.FamilyQuests, error)
+	StartQuest(ctx context.Context, childProfileID, templateID, parentSession string) (*store.FamilyQuest, error)
+	ConfirmQuest(ctx context.Context, childProfileID string, questID int64, parentSession, key, note string) (*store.FamilyQuest, bool, error)
+	GetNotifications(ctx context.Context, childProfileID, recipient, parentSession string, limit int) ([]store.FamilyNotification, int, error)
+	MarkNotificationRead(ctx context.Context, childProfileID, recipient, parentSession string, id int64) error
+}
+
+// FamilyHandler обрабатывает запросы семейных квестов
+type FamilyHandler struct {
+	service FamilyServiceInterface
+}
+
+// NewFamilyHandler создает новый FamilyHandler
+func NewFamilyHandler(familyService FamilyServiceInterface) *FamilyHandler {
+	return &FamilyHandler{service: familyService}
+}
+
+// FamilyRewardResponse награда за квест
+type FamilyRewardResponse struct {
+	Coins     int    `json:"coins"`
+	XP        int    `json:"xp"`
+	BadgeID   string `json:"badge_id,omitempty"`
+	BadgeName string `json:"badge_name,omitempty"`
+}
+
+// FamilyGoalResponse цель одной стороны квеста
+type FamilyGoalResponse struct {
+	Kind     string `json:"kind"` // метрика ребёнка или действие родителя
+	Target   int    `json:"target"`
+	Progress int    `json:"progress"`
+	Label    string `json:"label,omitempty"`
+}
+
+// FamilyTemplateResponse квест, который можно начать
+type FamilyTemplateResponse struct {
+	ID           string               `json:"id"`
+	Type         string               `json:"type"`
+	Icon         string               `json:"icon"`
+	Title        string               `json:"title"`
+	Description  string               `json:"description"`
+	Child        FamilyGoalResponse   `json:"child"`
+	Parent       *FamilyGoalResponse  `json:"parent,omitempty"`
+	Window       string               `json:"window"`
+	DurationDays int                  `json:"duration_days,omitempty"`
+	Reward       FamilyRewardResponse `json:"reward"`
+}
+
+// FamilyQuestResponse начатый квест
+type FamilyQuestResponse struct {
+	ID          int64                `json:"id"`
+	TemplateID  string               `json:"template_id"`
+	Type        string               `json:"type"`
+	Icon        string               `json:"icon"`
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Child       FamilyGoalResponse   `json:"child"`
+	Parent      *FamilyGoalResponse  `json:"parent,omitempty"`
+	Window      string               `json:"window"`
+	Reward      FamilyRewardResponse `json:"reward"`
+	StartedBy   string               `json:"started_by"`
+	Status      string               `json:"status"`
+	StartedAt   string               `json:"started_at"`
+	EndsAt      string               `json:"ends_at"`
+	CompletedAt string               `json:"completed_at,omitempty"`
+}
+
+// FamilyQuestsResponse квесты ребёнка
+type FamilyQuestsResponse struct {
+	Templates []FamilyTemplateResponse `json:"templates"`
+	Active    []FamilyQuestResponse    `json:"active"`
+	History   []FamilyQuestResponse    `json:"history"`
+}
+
+// ConfirmFamilyQuestRequest подтверждение родителя
+type ConfirmFamilyQuestRequest struct {
+	IdempotencyKey string `json:"idempotency_key"`
+	Note           string `json:"note,omitempty"`
+}
+
+// ConfirmFamilyQuestResponse квест после подтверждения
+type ConfirmFamilyQuestResponse struct {
+	Quest     FamilyQuestResponse `json:"quest"`
+	Completed bool                `json:"completed"` // квест выполнен этим подтверждением
+}
+
+// FamilyNotificationResponse уведомление
+type FamilyNotificationResponse struct {
+	ID           int64  `json:"id"`
+	Kind         string `json:"kind"`
+	QuestID      int64  `json:"quest_id,omitempty"`
+	RedemptionID int64  `json:"redemption_id,omitempty"`
+	Title        string `json:"title"`
+	Body         string `json:"body"`
+	Read         bool   `json:"read"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// FamilyNotificationsResponse уведомления получателя
+type FamilyNotificationsResponse struct {
+	Recipient     string                       `json:"recipient"`
+	Notifications []FamilyNotificationResponse `json:"notifications"`
+	Unread        int                          `json:"unread"`
+}
+
+// GetQuests возвращает квесты ребёнка и шаблоны, которые можно начать
+// GET /family/quests
+func (h *FamilyHandler) GetQuests(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	quests, err := h.service.GetQuests(r.Context(), childProfileID)
+	if err != nil {
+		log.Printf("[FamilyHandler] Failed to get quests for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get family quests")
+		return
+	}
+
+	resp := FamilyQuestsResponse{
+		Templates: make([]FamilyTemplateResponse, 0, len(quests.Templates)),
+		Active:    make([]FamilyQuestResponse, 0, len(quests.Active)),
+		History:   make([]FamilyQuestResponse, 0, len(quests.History)),
+	}
+	for _, t := range quests.Templates {
+		resp.Templates = append(resp.Templates, toFamilyTemplateResponse(t))
+	}
+	for _, q := range quests.Active {
+		resp.Active = append(resp.Active, toFamilyQuestResponse(q))
+	}
+	for _, q := range quests.History {
+		resp.History = append(resp.History, toFamilyQuestResponse(q))
+	}
+	response.OK(w, resp)
+}
+
+// StartQuest начинает квест по шаблону; с X-Parent-Session квест начинает родитель
+// POST /family/quests/{templateId}/start
+func (h *FamilyHandler) StartQuest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID := middleware.GetChildProfileID(ctx)
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	templateID := r.PathValue("templateId")
+	if err := validation.ValidateRequired(templateID, "template_id"); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	quest, err := h.service.StartQuest(ctx, childProfileID, templateID, middleware.GetParentSession(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Quest template not found")
+		case errors.Is(err, domain.ErrUnauthorized):
+			response.Unauthorized(w, "Parent session expired")
+		case errors.Is(err, domain.ErrForbidden):
+			response.Forbidden(w, "Not a parent of this child")
+		case errors.Is(err, domain.ErrConflict):
+			response.Conflict(w, "Quest is already active")
+		default:
+			log.Printf("[FamilyHandler] Failed to start quest %s for child %s: %v", templateID, childProfileID, err)
+			response.InternalError(w, "Failed to start quest")
+		}
+		return
+	}
+
+	response.Created(w, toFamilyQuestResponse(*quest))
+}
+
+// ConfirmQuest засчитывает подтверждение родителя («объяснил задачу»)
+// POST /family/quests/{id}/confirm
+func (h *FamilyHandler) ConfirmQuest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID := middleware.GetChildProfileID(ctx)
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+	parentSession := middleware.GetParentSession(ctx)
+	if parentSession == "" {
+		response.Unauthorized(w, "Missing X-Parent-Session header")
+		return
+	}
+
+	questID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || questID <= 0 {
+		response.BadRequest(w, "invalid quest id")
+		return
+	}
+
+	var req ConfirmFamilyQuestRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if err := validation.ValidateRequired(req.IdempotencyKey, "idempotency_key"); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if err := validation.ValidateMaxLength(req.IdempotencyKey, "idempotency_key", 100); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if err := validation.ValidateMaxLength(req.Note, "note", 500); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	quest, completed, err := h.service.ConfirmQuest(ctx, childProfileID, questID, parentSession, req.IdempotencyKey, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Quest not found")
+		case errors.Is(err, domain.ErrUnauthorized):
+			response.Unauthorized(w, "Parent session expired")
+		case errors.Is(err, domain.ErrForbidden):
+			response.Forbidden(w, "Not a parent of this child")
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.BadRequest(w, "Quest does not need parent confirmations")
+		case errors.Is(err, domain.ErrConflict):
+			response.Conflict(w, "Quest is not active")
+		default:
+			log.Printf("[FamilyHandler] Failed to confirm quest %d for child %s: %v", questID, childProfileID, err)
+			response.InternalError(w, "Failed to confirm quest")
+		}
+		return
+	}
+
+	response.OK(w, ConfirmFamilyQuestResponse{Quest: toFamilyQuestResponse(*quest), Completed: completed})
+}
+
+// GetNotifications возвращает уведомления ребёнка или родителя (?recipient=child|parent)
+// GET /family/notifications
+func (h *FamilyHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID := middleware.GetChildProfileID(ctx)
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	recipient := familyRecipient(r)
+	notes, unread, err := h.service.GetNotifications(ctx, childProfileID, recipient, middleware.GetParentSession(ctx), familyNotificationsLimit)
+	if err != nil {
+		writeFamilyRecipientError(w, err, "Failed to get notifications", childProfileID)
+		return
+	}
+
+	resp := FamilyNotificationsResponse{
+		Recipient:     recipient,
+		Notifications: make([]FamilyNotificationResponse, 0, len(notes)),
+		Unread:        unread,
+	}
+	for _, n := range notes {
+		resp.Notifications = append(resp.Notifications, FamilyNotificationResponse{
+			ID:           n.ID,
+			Kind:         n.Kind,
+			QuestID:      n.QuestID,
+			RedemptionID: n.RedemptionID,
+			Title:        n.Title,
+			Body:         n.Body,
+			Read:         n.ReadAt != nil,
+			CreatedAt:    n.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	response.OK(w, resp)
+}
+
+// MarkNotificationRead отмечает уведомление прочитанным (?recipient=child|parent)
+// POST /family/notifications/{id}/read
+func (h *FamilyHandler) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID := middleware.GetChildProfileID(ctx)
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(w, "invalid notification id")
+		return
+	}
+
+	err = h.service.MarkNotificationRead(ctx, childProfileID, familyRecipient(r), middleware.GetParentSession(ctx), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.NotFound(w, "Notification not found")
+			return
+		}
+		writeFamilyRecipientError(w, err, "Failed to mark notification read", childProfileID)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// familyRecipient получатель уведомлений из query, по умолчанию ребёнок
+func familyRecipient(r *http.Request) string {
+	if recipient := r.URL.Query().Get("recipient"); recipient != "" {
+		return recipient
+	}
+	return store.FamilyActorChild
+}
+
+func writeFamilyRecipientError(w http.ResponseWriter, err error, message, childProfileID string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.BadRequest(w, "recipient must be child or parent")
+	case errors.Is(err, domain.ErrUnauthorized):
+		response.Unauthorized(w, "Parent session required")
+	case errors.Is(err, domain.ErrForbidden):
+		response.Forbidden(w, "Not a parent of this child")
+	default:
+		log.Printf("[FamilyHandler] %s for child %s: %v", message, childProfileID, err)
+		response.InternalError(w, message)
+	}
+}
+
+func toFamilyReward(coins, xp int, badgeID, badgeName string) FamilyRewardResponse {
+	return FamilyRewardResponse{Coins: coins, XP: xp, BadgeID: badgeID, BadgeName: badgeName}
+}
+
+func toFamilyTemplateResponse(t family.Template) FamilyTemplateResponse {
+	resp := FamilyTemplateResponse{
+		ID:           t.ID,
+		Type:         t.Type,
+		Icon:         t.Icon,
+		Title:        t.Title,
+		Description:  t.Description,
+		Child:        FamilyGoalResponse{Kind: t.Child.Metric, Target: t.Child.Target},
+		Window:       t.Window,
+		DurationDays: t.DurationDays,
+		Reward:       toFamilyReward(t.Reward.Coins, t.Reward.XP, t.Reward.BadgeID, t.Reward.BadgeName),
+	}
+	if t.Parent != nil {
+		resp.Parent = &FamilyGoalResponse{Kind: t.Parent.Action, Target: t.Parent.Target, Label: t.Parent.Label}
+	}
+	return resp
+}
+
+func toFamilyQuestResponse(q store.FamilyQuest) FamilyQuestResponse {
+	resp := FamilyQuestResponse{
+		ID:          q.ID,
+		TemplateID:  q.TemplateID,
+		Type:        q.QuestType,
+		Icon:        q.Icon,
+		Title:       q.Title,
+		Description: q.Description,
+		Child:       FamilyGoalResponse{Kind: q.ChildMetric, Target: q.ChildTarget, Progress: q.ChildProgress},
+		Window:      q.Window,
+		Reward:      toFamilyReward(q.RewardCoins, q.RewardXP, q.RewardBadgeID, q.RewardBadgeName),
+		StartedBy:   q.StartedBy,
+		Status:      q.Status,
+		StartedAt:   q.StartedAt.Format(time.RFC3339),
+		EndsAt:      q.EndsAt.Format(time.RFC3339),
+	}
+	if q.ParentAction != "" {
+		resp.Parent = &FamilyGoalResponse{
+			Kind:     q.ParentAction,
+			Target:   q.ParentTarget,
+			Progress: q.ParentProgress,
+			Label:    q.ParentLabel,
+		}
+	}
+	if q.CompletedAt != nil {
+		resp.CompletedAt = q.CompletedAt.Format(time.RFC3339)
+	}
+	return resp
+}
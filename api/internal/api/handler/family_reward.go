@@ -0,0 +1,386 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/service"
+	"child-bot/api/internal/store"
+)
+
+// FamilyRewardServiceInterface интерфейс для FamilyRewardService
+type FamilyRewardServiceInterface interface {
+	GetRewards(ctx context.Context, childProfileID string) (*service.FamilyRewards, error)
+	CreateReward(ctx context.Context, childProfileID, parentSession string, in service.FamilyRewardInput) (*store.FamilyReward, error)
+	UpdateReward(ctx context.Context, childProfileID string, id int64, parentSession string, in service.FamilyRewardInput) (*store.FamilyReward, error)
+	ArchiveReward(ctx context.Context, childProfileID string, id int64, parentSession string) error
+	Redeem(ctx context.Context, childProfileID string, rewardID int64, idempotencyKey string) (*store.FamilyRedemption, bool, error)
+	Decide(ctx context.Context, childProfileID string, id int64, parentSession string, approve bool, note string) (*store.FamilyRedemption, error)
+}
+
+// FamilyRewardHandler обрабатывает запросы наград за монеты
+type FamilyRewardHandler struct {
+	service FamilyRewardServiceInterface
+}
+
+// NewFamilyRewardHandler создает новый FamilyRewardHandler
+func NewFamilyRewardHandler(familyRewardService FamilyRewardServiceInterface) *FamilyRewardHandler {
+	return &FamilyRewardHandler{service: familyRewardService}
+}
+
+// FamilyRewardRequest награда, которую добавляет или меняет родитель
+type FamilyRewardRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Icon        string `json:"icon,omitempty"` // по умолчанию 🎁
+	Price       int    `json:"price"`
+}
+
+// RedeemFamilyRewardRequest запрос награды ребёнком
+type RedeemFamilyRewardRequest struct {
+	IdempotencyKey string `json:"idempotency_key"` // повтор с тем же ключом не списывает монеты
+}
+
+// DecideFamilyRedemptionRequest решение родителя
+type DecideFamilyRedemptionRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// FamilyRewardItemResponse награда в каталоге
+type FamilyRewardItemResponse struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Icon        string `json:"icon"`
+	Price       int    `json:"price"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// FamilyRedemptionResponse запрос награды
+type FamilyRedemptionResponse struct {
+	ID          int64  `json:"id"`
+	RewardID    int64  `json:"reward_id"`
+	Title       string `json:"title"`
+	Icon        string `json:"icon"`
+	Price       int    `json:"price"`
+	Status      string `json:"status"` // pending, approved, declined
+	ParentNote  string `json:"parent_note,omitempty"`
+	RequestedAt string `json:"requested_at"`
+	DecidedAt   string `json:"decided_at,omitempty"`
+}
+
+// FamilyRewardsResponse каталог наград и запросы ребёнка
+type FamilyRewardsResponse struct {
+	Rewards      []FamilyRewardItemResponse `json:"rewards"`
+	Redemptions  []FamilyRedemptionResponse `json:"redemptions"`
+	CoinsBalance int                        `json:"coins_balance"`
+}
+
+// RedeemFamilyRewardResponse результат запроса награды
+type RedeemFamilyRewardResponse struct {
+	Redemption FamilyRedemptionResponse `json:"redemption"`
+	Replayed   bool                     `json:"replayed,omitempty"`
+}
+
+func toFamilyRewardItemResponse(r store.FamilyReward) FamilyRewardItemResponse {
+	return FamilyRewardItemResponse{
+		ID:          r.ID,
+		Title:       r.Title,
+		Description: r.Description,
+		Icon:        r.Icon,
+		Price:       r.Price,
+		UpdatedAt:   r.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toFamilyRedemptionResponse(r store.FamilyRedemption) FamilyRedemptionResponse {
+	resp := FamilyRedemptionResponse{
+		ID:          r.ID,
+		RewardID:    r.RewardID,
+		Title:       r.Title,
+		Icon:        r.Icon,
+		Price:       r.Price,
+		Status:      r.Status,
+		ParentNote:  r.ParentNote,
+		RequestedAt: r.RequestedAt.Format(time.RFC3339),
+	}
+	if r.DecidedAt != nil {
+		resp.DecidedAt = r.DecidedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+// GetRewards возвращает каталог наград, запросы ребёнка и баланс монет
+// GET /family/rewards
+func (h *FamilyRewardHandler) GetRewards(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	result, err := h.service.GetRewards(r.Context(), childProfileID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.NotFound(w, "Child profile not found")
+			return
+		}
+		log.Printf("[FamilyRewardHandler] Failed to get rewards for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get rewards")
+		return
+	}
+
+	resp := FamilyRewardsResponse{
+		Rewards:      make([]FamilyRewardItemResponse, 0, len(result.Rewards)),
+		Redemptions:  make([]FamilyRedemptionResponse, 0, len(result.Redemptions)),
+		CoinsBalance: result.CoinsBalance,
+	}
+	for _, reward := range result.Rewards {
+		resp.Rewards = append(resp.Rewards, toFamilyRewardItemResponse(reward))
+	}
+	for _, redemption := range result.Redemptions {
+		resp.Redemptions = append(resp.Redemptions, toFamilyRedemptionResponse(redemption))
+	}
+	response.OK(w, resp)
+}
+
+// CreateReward добавляет награду в каталог ребёнка (родитель, X-Parent-Session)
+// POST /family/rewards
+func (h *FamilyRewardHandler) CreateReward(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID, parentSession, ok := familyRewardParent(w, r)
+	if !ok {
+		return
+	}
+	in, ok := decodeFamilyRewardRequest(w, r)
+	if !ok {
+		return
+	}
+
+	reward, err := h.service.CreateReward(ctx, childProfileID, parentSession, in)
+	if err != nil {
+		h.writeRewardError(w, err, "create reward", childProfileID)
+		return
+	}
+	response.Created(w, toFamilyRewardItemResponse(*reward))
+}
+
+// UpdateReward меняет награду (родитель, X-Parent-Session)
+// PATCH /family/rewards/{id}
+func (h *FamilyRewardHandler) UpdateReward(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID, parentSession, ok := familyRewardParent(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(w, "invalid reward id")
+		return
+	}
+	in, ok := decodeFamilyRewardRequest(w, r)
+	if !ok {
+		return
+	}
+
+	reward, err := h.service.UpdateReward(ctx, childProfileID, id, parentSession, in)
+	if err != nil {
+		h.writeRewardError(w, err, "update reward", childProfileID)
+		return
+	}
+	response.OK(w, toFamilyRewardItemResponse(*reward))
+}
+
+// ArchiveReward снимает награду из каталога (родитель, X-Parent-Session)
+// DELETE /family/rewards/{id}
+func (h *FamilyRewardHandler) ArchiveReward(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID, parentSession, ok := familyRewardParent(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(w, "invalid reward id")
+		return
+	}
+
+	if err := h.service.ArchiveReward(ctx, childProfileID, id, parentSession); err != nil {
+		h.writeRewardError(w, err, "archive reward", childProfileID)
+		return
+	}
+	response.NoContent(w)
+}
+
+// Redeem запрашивает награду за монеты; монеты списываются и ждут решения родителя
+// POST /family/rewards/{id}/redeem
+func (h *FamilyRewardHandler) Redeem(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(w, "invalid reward id")
+		return
+	}
+
+	var req RedeemFamilyRewardRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if err := validation.ValidateRequired(req.IdempotencyKey, "idempotency_key"); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if err := validation.ValidateMaxLength(req.IdempotencyKey, "idempotency_key", 100); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	redemption, replayed, err := h.service.Redeem(r.Context(), childProfileID, id, req.IdempotencyKey)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Reward not found")
+		case errors.Is(err, domain.ErrInsufficientFunds):
+			response.ErrorWithCode(w, http.StatusPaymentRequired, "Not enough coins", "INSUFFICIENT_FUNDS")
+		case errors.Is(err, domain.ErrConflict):
+			response.Conflict(w, "Too many rewards are waiting for a parent decision")
+		default:
+			log.Printf("[FamilyRewardHandler] Failed to redeem reward %d for child %s: %v", id, childProfileID, err)
+			response.InternalError(w, "Failed to redeem reward")
+		}
+		return
+	}
+
+	response.OK(w, RedeemFamilyRewardResponse{Redemption: toFamilyRedemptionResponse(*redemption), Replayed: replayed})
+}
+
+// Approve одобряет запрос награды (родитель, X-Parent-Session)
+// POST /family/redemptions/{id}/approve
+func (h *FamilyRewardHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, true)
+}
+
+// Decline отклоняет запрос награды и возвращает монеты (родитель, X-Parent-Session)
+// POST /family/redemptions/{id}/decline
+func (h *FamilyRewardHandler) Decline(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, false)
+}
+
+func (h *FamilyRewardHandler) decide(w http.ResponseWriter, r *http.Request, approve bool) {
+	ctx := r.Context()
+	childProfileID, parentSession, ok := familyRewardParent(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(w, "invalid redemption id")
+		return
+	}
+
+	// Тело необязательно: решение без комментария
+	var req DecideFamilyRedemptionRequest
+	if err := validation.DecodeJSON(r, &req); err != nil && !errors.Is(err, validation.ErrEmptyBody) {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if err := validation.ValidateMaxLength(req.Note, "note", 500); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	redemption, err := h.service.Decide(ctx, childProfileID, id, parentSession, approve, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Redemption not found")
+		case errors.Is(err, domain.ErrUnauthorized):
+			response.Unauthorized(w, "Parent session expired")
+		case errors.Is(err, domain.ErrForbidden):
+			response.Forbidden(w, "Not a parent of this child")
+		case errors.Is(err, domain.ErrConflict):
+			response.Conflict(w, "Redemption is already decided")
+		default:
+			log.Printf("[FamilyRewardHandler] Failed to decide redemption %d for child %s: %v", id, childProfileID, err)
+			response.InternalError(w, "Failed to decide redemption")
+		}
+		return
+	}
+
+	response.OK(w, toFamilyRedemptionResponse(*redemption))
+}
+
+// familyRewardParent достаёт ребёнка и родительскую сессию из запроса родителя
+func familyRewardParent(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return "", "", false
+	}
+	parentSession := middleware.GetParentSession(r.Context())
+	if parentSession == "" {
+		response.Unauthorized(w, "Missing X-Parent-Session header")
+		return "", "", false
+	}
+	return childProfileID, parentSession, true
+}
+
+// decodeFamilyRewardRequest разбирает и проверяет награду родителя
+func decodeFamilyRewardRequest(w http.ResponseWriter, r *http.Request) (service.FamilyRewardInput, bool) {
+	var req FamilyRewardRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		response.BadRequest(w, err.Error())
+		return service.FamilyRewardInput{}, false
+	}
+	if err := validation.ValidateRequired(req.Title, "title"); err != nil {
+		response.BadRequest(w, err.Error())
+		return service.FamilyRewardInput{}, false
+	}
+	for _, check := range []error{
+		validation.ValidateMaxLength(req.Title, "title", 100),
+		validation.ValidateMaxLength(req.Description, "description", 500),
+		validation.ValidateMaxLength(req.Icon, "icon", 20),
+	} {
+		if check != nil {
+			response.BadRequest(w, check.Error())
+			return service.FamilyRewardInput{}, false
+		}
+	}
+	if req.Price <= 0 || req.Price > service.MaxFamilyRewardPrice {
+		response.BadRequest(w, "price must be between 1 and "+strconv.Itoa(service.MaxFamilyRewardPrice))
+		return service.FamilyRewardInput{}, false
+	}
+	return service.FamilyRewardInput{Title: req.Title, Description: req.Description, Icon: req.Icon, Price: req.Price}, true
+}
+
+// writeRewardError отвечает на ошибку изменения каталога наград
+func (h *FamilyRewardHandler) writeRewardError(w http.ResponseWriter, err error, action, childProfileID string) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		response.NotFound(w, "Reward not found")
+	case errors.Is(err, domain.ErrUnauthorized):
+		response.Unauthorized(w, "Parent session expired")
+	case errors.Is(err, domain.ErrForbidden):
+		response.Forbidden(w, "Not a parent of this child")
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.BadRequest(w, "title is required and price must be between 1 and "+strconv.Itoa(service.MaxFamilyRewardPrice))
+	case errors.Is(err, domain.ErrConflict):
+		response.Conflict(w, "Too many rewards, archive some first")
+	default:
+		log.Printf("[FamilyRewardHandler] Failed to %s for child %s: %v", action, childProfileID, err)
+		response.InternalError(w, "Failed to "+action)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"child-bot/api/internal/api/middleware"
 	"child-bot/api/internal/domain"
 	"child-bot/api/internal/service"
 )
@@ -66,6 +67,12 @@ func makeRequest(t *testing.T, method, path string, body interface{}) *http.Requ
 	return req
 }
 
+// withChildProfile adds child_profile_id to the request context like middleware.Auth
+func withChildProfile(req *http.Request, childProfileID string) *http.Request {
+	ctx := context.WithValue(req.Context(), middleware.ContextKeyChildProfileID, childProfileID)
+	return req.WithContext(ctx)
+}
+
 // extractPathParam extracts path parameter from URL for testing
 func extractPathParam(path, param string) string {
 	// Simple extraction: /attempts/attempt-123/images -> "attempt-123"
@@ -154,12 +161,19 @@ type mockAttemptService struct {
 	createFunc            func(ctx context.Context, childProfileID, attemptType string) (string, error)
 	uploadImageFunc       func(ctx context.Context, attemptID, imageType, imageData string) (string, error)
 	processHelpFunc       func(ctx context.Context, attemptID, imageBase64 string) error
-	processCheckFunc      func(ctx context.Context, attemptID, taskImageBase64, answerImageBase64 string) error
+	processCheckFunc      func(ctx context.Context, attemptID, childProfileID, taskImageBase64, answerImageBase64, powerUpID string) error
+	startPageCheckFunc    func(ctx context.Context, attemptID string) error
 	getAttemptResultFunc  func(ctx context.Context, attemptID string) (*service.AttemptData, error)
 	getNextHintFunc       func(ctx context.Context, attemptID string) (*domain.HelpResult, error)
 	deleteFunc            func(ctx context.Context, attemptID string) error
 	getUnfinishedFunc     func(ctx context.Context, childProfileID string) (*service.AttemptData, error)
 	getRecentAttemptsFunc func(ctx context.Context, childProfileID string, limit int) ([]service.AttemptData, error)
+	explainMistakesFunc   func(ctx context.Context, attemptID, childProfileID string) (*domain.MistakeStep, error)
+	nextMistakeFunc       func(ctx context.Context, attemptID, childProfileID string) (*domain.MistakeStep, error)
+	processPageFunc       func(ctx context.Context, attemptID, imageBase64 string) error
+	getPageProgressFunc   func(ctx context.Context, attemptID, childProfileID string) (*domain.PageProgress, error)
+	rateHintFunc          func(ctx context.Context, attemptID, childProfileID string, hintIndex int, rating, comment string) error
+	regenerateHintFunc    func(ctx context.Context, attemptID, childProfileID string, hintIndex int, rating, comment string) (*domain.RegeneratedHint, error)
 }
 
 func (m *mockAttemptService) CreateAttempt(ctx context.Context, childProfileID, attemptType string) (string, error) {
@@ -183,9 +197,16 @@ func (m *mockAttemptService) ProcessHelp(ctx context.Context, attemptID, imageBa
 	return errors.New("not implemented")
 }
 
-func (m *mockAttemptService) ProcessCheck(ctx context.Context, attemptID, taskImageBase64, answerImageBase64 string) error {
+func (m *mockAttemptService) ProcessCheck(ctx context.Context, attemptID, childProfileID, taskImageBase64, answerImageBase64, powerUpID string) error {
 	if m.processCheckFunc != nil {
-		return m.processCheckFunc(ctx, attemptID, taskImageBase64, answerImageBase64)
+		return m.processCheckFunc(ctx, attemptID, childProfileID, taskImageBase64, answerImageBase64, powerUpID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockAttemptService) StartPageTaskCheck(ctx context.Context, attemptID string) error {
+	if m.startPageCheckFunc != nil {
+		return m.startPageCheckFunc(ctx, attemptID)
 	}
 	return errors.New("not implemented")
 }
@@ -224,3 +245,45 @@ func (m *mockAttemptService) GetRecentAttempts(ctx context.Context, childProfile
 	}
 	return nil, errors.New("not implemented")
 }
+
+func (m *mockAttemptService) ExplainMistakes(ctx context.Context, attemptID, childProfileID string) (*domain.MistakeStep, error) {
+	if m.explainMistakesFunc != nil {
+		return m.explainMistakesFunc(ctx, attemptID, childProfileID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockAttemptService) NextMistake(ctx context.Context, attemptID, childProfileID string) (*domain.MistakeStep, error) {
+	if m.nextMistakeFunc != nil {
+		return m.nextMistakeFunc(ctx, attemptID, childProfileID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockAttemptService) ProcessPage(ctx context.Context, attemptID, imageBase64 string) error {
+	if m.processPageFunc != nil {
+		return m.processPageFunc(ctx, attemptID, imageBase64)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockAttemptService) GetPageProgress(ctx context.Context, attemptID, childProfileID string) (*domain.PageProgress, error) {
+	if m.getPageProgressFunc != nil {
+		return m.getPageProgressFunc(ctx, attemptID, childProfileID)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockAttemptService) RateHint(ctx context.Context, attemptID, childProfileID string, hintIndex int, rating, comment string) error {
+	if m.rateHintFunc != nil {
+		return m.rateHintFunc(ctx, attemptID, childProfileID, hintIndex, rating, comment)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *mockAttemptService) RegenerateHint(ctx context.Context, attemptID, childProfileID string, hintIndex int, rating, comment string) (*domain.RegeneratedHint, error) {
+	if m.regenerateHintFunc != nil {
+		return m.regenerateHintFunc(ctx, attemptID, childProfileID, hintIndex, rating, comment)
+	}
+	return nil, errors.New("not implemented")
+}
@@ -7,7 +7,6 @@ import (
 	"child-bot/api/internal/api/response"
 	"child-bot/api/internal/api/validation"
 	"child-bot/api/internal/service"
-	"child-bot/api/internal/store"
 )
 
 // HomeHandler обрабатывает запросы главного экрана
@@ -33,10 +32,11 @@ type HomeData struct {
 		TasksSolvedCorrectCount int    `json:"tasksSolvedCorrectCount"`
 	} `json:"profile"`
 	Mascot struct {
-		ID       string `json:"id"`
-		State    string `json:"state"` // idle, happy, thinking, celebrating
-		ImageURL string `json:"imageUrl"`
-		Message  string `json:"message"`
+		ID       string       `json:"id"`
+		State    string       `json:"state"` // idle, happy, thinking, celebrating, encouraging
+		ImageURL string       `json:"imageUrl"`
+		Message  string       `json:"message"`
+		Pet      *HomePetInfo `json:"pet,omitempty"`
 	} `json:"mascot"`
 	Villain           *VillainInfo    `json:"villain"`
 	UnfinishedAttempt *AttemptInfo    `json:"unfinishedAttempt"`
@@ -45,6 +45,52 @@ type HomeData struct {
 		UnlockedCount int `json:"unlockedCount"`
 		TotalCount    int `json:"totalCount"`
 	} `json:"achievements"`
+	Missions *HomeMissionsInfo `json:"missions,omitempty"`
+	Event    *HomeEventInfo    `json:"event,omitempty"`
+}
+
+// HomeEventInfo сводка сезонного события на главном экране
+type HomeEventInfo struct {
+	ID                   string `json:"id"`
+	Title                string `json:"title"`
+	Icon                 string `json:"icon"`
+	ThemeColor           string `json:"themeColor"`
+	EndDate              string `json:"endDate"`
+	DaysLeft             int    `json:"daysLeft"`
+	AchievementsUnlocked int    `json:"achievementsUnlocked"`
+	AchievementsTotal    int    `json:"achievementsTotal"`
+}
+
+// HomeMissionsInfo сводка миссий дня на главном экране
+type HomeMissionsInfo struct {
+	CompletedCount int               `json:"completedCount"`
+	TotalCount     int               `json:"totalCount"`
+	Items          []HomeMissionInfo `json:"items"`
+}
+
+// HomeMissionInfo миссия дня на главном экране
+type HomeMissionInfo struct {
+	ID        int64  `json:"id"`
+	Icon      string `json:"icon"`
+	Title     string `json:"title"`
+	Progress  int    `json:"progress"`
+	Target    int    `json:"target"`
+	Completed bool   `json:"completed"`
+}
+
+// HomePetInfo показатели питомца на главном экране
+type HomePetInfo struct {
+	Type           string `json:"type"`
+	Stage          int    `json:"stage"`
+	StageCode      string `json:"stageCode"`
+	StageName      string `json:"stageName"`
+	Hunger         int    `json:"hunger"` // 0 — сыт, 100 — очень голоден
+	Happiness      int    `json:"happiness"`
+	Energy         int    `json:"energy"`
+	Mood           string `json:"mood"` // happy, ok, hungry, sad, tired, sleeping
+	IsSleeping     bool   `json:"isSleeping"`
+	NextStageLevel int    `json:"nextStageLevel,omitempty"`
+	JustEvolved    bool   `json:"justEvolved"`
 }
 
 type VillainInfo struct {
@@ -101,6 +147,52 @@ func (h *HomeHandler) GetHomeData(w http.ResponseWriter, r *http.Request) {
 	data.Mascot.State = serviceData.Mascot.State
 	data.Mascot.ImageURL = serviceData.Mascot.ImageURL
 	data.Mascot.Message = serviceData.Mascot.Message
+	if p := serviceData.Mascot.Pet; p != nil {
+		data.Mascot.Pet = &HomePetInfo{
+			Type:           p.Type,
+			Stage:          p.Stage,
+			StageCode:      p.StageCode,
+			StageName:      p.StageName,
+			Hunger:         p.Hunger,
+			Happiness:      p.Happiness,
+			Energy:         p.Energy,
+			Mood:           p.Mood,
+			IsSleeping:     p.IsSleeping,
+			NextStageLevel: p.NextStageLevel,
+			JustEvolved:    p.JustEvolved,
+		}
+	}
+
+	if m := serviceData.Missions; m != nil {
+		data.Missions = &HomeMissionsInfo{
+			CompletedCount: m.Completed,
+			TotalCount:     m.Total,
+			Items:          make([]HomeMissionInfo, 0, len(m.Items)),
+		}
+		for _, item := range m.Items {
+			data.Missions.Items = append(data.Missions.Items, HomeMissionInfo{
+				ID:        item.ID,
+				Icon:      item.Icon,
+				Title:     item.Title,
+				Progress:  item.Progress,
+				Target:    item.Target,
+				Completed: item.Completed,
+			})
+		}
+	}
+
+	if e := serviceData.Event; e != nil {
+		data.Event = &HomeEventInfo{
+			ID:                   e.ID,
+			Title:                e.Title,
+			Icon:                 e.Icon,
+			ThemeColor:           e.ThemeColor,
+			EndDate:              e.EndDate,
+			DaysLeft:             e.DaysLeft,
+			AchievementsUnlocked: e.AchievementsUnlocked,
+			AchievementsTotal:    len(e.Achievements),
+		}
+	}
 
 	// Преобразуем данные villain
 	if serviceData.Villain != nil {
@@ -127,8 +219,8 @@ func (h *HomeHandler) GetHomeData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Рассчитываем прогресс до следующего уровня
-	xpForCurrentLevel := store.XPForLevel(level - 1)
-	xpForNextLevel := store.XPForLevel(level)
+	xpForCurrentLevel := h.service.GetStore().XP.XPForLevel(level - 1)
+	xpForNextLevel := h.service.GetStore().XP.XPForLevel(level)
 	xpInCurrentLevel := xpTotal - xpForCurrentLevel
 	xpNeeded := xpForNextLevel - xpForCurrentLevel
 
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/knowledge"
+	"child-bot/api/internal/service"
+)
+
+// KnowledgeServiceInterface интерфейс для KnowledgeService
+type KnowledgeServiceInterface interface {
+	GetKnowledgeMap(ctx context.Context, childProfileID, subject string, grade int) (*service.KnowledgeMap, error)
+}
+
+// KnowledgeHandler обрабатывает запросы карты знаний
+type KnowledgeHandler struct {
+	service KnowledgeServiceInterface
+}
+
+// NewKnowledgeHandler создает новый KnowledgeHandler
+func NewKnowledgeHandler(knowledgeService KnowledgeServiceInterface) *KnowledgeHandler {
+	return &KnowledgeHandler{service: knowledgeService}
+}
+
+// KnowledgeNodeResponse раздел или тема карты знаний
+type KnowledgeNodeResponse struct {
+	ID              string                  `json:"id"`
+	Kind            string                  `json:"kind"` // section, topic
+	Title           string                  `json:"title"`
+	TemplateCode    string                  `json:"template_code,omitempty"`
+	GradeMin        int                     `json:"grade_min"`
+	GradeMax        int                     `json:"grade_max"`
+	Mastery         int                     `json:"mastery"` // 0..100
+	Level           int                     `json:"level"`   // 0 не начата, 1 изучается, 2 закрепляется, 3 освоена
+	Attempts        int                     `json:"attempts"`
+	Correct         int                     `json:"correct"`
+	HintsUsed       int                     `json:"hints_used"`
+	IsWeak          bool                    `json:"is_weak"`
+	LastPracticedAt string                  `json:"last_practiced_at,omitempty"`
+	TopicsTotal     int                     `json:"topics_total,omitempty"`
+	TopicsPracticed int                     `json:"topics_practiced,omitempty"`
+	TopicsMastered  int                     `json:"topics_mastered,omitempty"`
+	Topics          []KnowledgeNodeResponse `json:"topics,omitempty"`
+}
+
+// KnowledgeWeakSpotResponse слабое место
+type KnowledgeWeakSpotResponse struct {
+	KnowledgeNodeResponse
+	SectionID      string `json:"section_id"`
+	SectionTitle   string `json:"section_title"`
+	Recommendation string `json:"recommendation"`
+}
+
+// KnowledgeMapResponse карта знаний
+type KnowledgeMapResponse struct {
+	Subject         string                      `json:"subject"`
+	Grade           int                         `json:"grade"` // 0 — все классы
+	Mastery         int                         `json:"mastery"`
+	TopicsTotal     int                         `json:"topics_total"`
+	TopicsPracticed int                         `json:"topics_practiced"`
+	TopicsMastered  int                         `json:"topics_mastered"`
+	Sections        []KnowledgeNodeResponse     `json:"sections"`
+	WeakSpots       []KnowledgeWeakSpotResponse `json:"weak_spots"`
+}
+
+// Get возвращает карту знаний ребёнка
+// GET /knowledge-map?subject=math&grade=2
+func (h *KnowledgeHandler) Get(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+	h.respondMap(w, r, childProfileID)
+}
+
+// GetForParent возвращает карту знаний ребёнка для родителя
+// GET /reports/{childProfileId}/knowledge-map?subject=math&grade=2
+func (h *KnowledgeHandler) GetForParent(w http.ResponseWriter, r *http.Request) {
+	childProfileID := r.PathValue("childProfileId")
+	if err := validation.ValidateUUID(childProfileID); err != nil {
+		response.BadRequest(w, "invalid child_profile_id: "+err.Error())
+		return
+	}
+	h.respondMap(w, r, childProfileID)
+}
+
+func (h *KnowledgeHandler) respondMap(w http.ResponseWriter, r *http.Request, childProfileID string) {
+	grade := 0
+	if v := r.URL.Query().Get("grade"); v != "" {
+		g, err := strconv.Atoi(v)
+		if err != nil || g < 1 || g > 4 {
+			response.BadRequest(w, "grade must be between 1 and 4")
+			return
+		}
+		grade = g
+	}
+
+	m, err := h.service.GetKnowledgeMap(r.Context(), childProfileID, r.URL.Query().Get("subject"), grade)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.NotFound(w, "Knowledge map not found")
+			return
+		}
+		log.Printf("[KnowledgeHandler] Failed to get knowledge map for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get knowledge map")
+		return
+	}
+
+	resp := KnowledgeMapResponse{
+		Subject:         m.Subject,
+		Grade:           m.Grade,
+		Mastery:         m.Mastery,
+		TopicsTotal:     m.Topics,
+		TopicsPracticed: m.Practiced,
+		TopicsMastered:  m.Mastered,
+		Sections:        make([]KnowledgeNodeResponse, 0, len(m.Sections)),
+		WeakSpots:       make([]KnowledgeWeakSpotResponse, 0, len(m.WeakSpots)),
+	}
+	for _, section := range m.Sections {
+		resp.Sections = append(resp.Sections, toKnowledgeNode(section))
+	}
+	for _, spot := range m.WeakSpots {
+		resp.WeakSpots = append(resp.WeakSpots, KnowledgeWeakSpotResponse{
+			KnowledgeNodeResponse: toKnowledgeNode(spot.TreeNode),
+			SectionID:             spot.ParentID,
+			SectionTitle:          spot.SectionTitle,
+			Recommendation:        spot.Recommendation,
+		})
+	}
+
+	response.OK(w, resp)
+}
+
+func toKnowledgeNode(n *knowledge.TreeNode) KnowledgeNodeResponse {
+	resp := KnowledgeNodeResponse{
+		ID:              n.ID,
+		Kind:            n.Kind,
+		Title:           n.Title,
+		TemplateCode:    n.TemplateCode,
+		GradeMin:        n.GradeMin,
+		GradeMax:        n.GradeMax,
+		Mastery:         n.Mastery,
+		Level:           n.Level,
+		Attempts:        n.Attempts,
+		Correct:         n.Correct,
+		HintsUsed:       n.HintsUsed,
+		IsWeak:          n.Weak,
+		TopicsTotal:     n.TopicsTotal,
+		TopicsPracticed: n.TopicsPracticed,
+		TopicsMastered:  n.TopicsMastered,
+	}
+	if n.LastPracticedAt != nil {
+		resp.LastPracticedAt = n.LastPracticedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	for _, topic := range n.Children {
+		resp.Topics = append(resp.Topics, toKnowledgeNode(topic))
+	}
+	return resp
+}
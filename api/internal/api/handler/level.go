@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/level"
+	"child-bot/api/internal/service"
+)
+
+// LevelServiceInterface интерфейс для LevelService
+type LevelServiceInterface interface {
+	GetLevels(ctx context.Context, childProfileID string) (*service.LevelOverview, error)
+	MarkSeen(ctx context.Context, childProfileID string, upTo int) (int, error)
+}
+
+// LevelHandler обрабатывает запросы таблицы уровней
+type LevelHandler struct {
+	service LevelServiceInterface
+}
+
+// NewLevelHandler создает новый LevelHandler
+func NewLevelHandler(levelService LevelServiceInterface) *LevelHandler {
+	return &LevelHandler{service: levelService}
+}
+
+// LevelRewardResponse награда за уровень
+type LevelRewardResponse struct {
+	Type    string `json:"type"` // coins, avatar, mascot_item, title, shop_discount
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Amount  int    `json:"amount,omitempty"`
+	Percent int    `json:"percent,omitempty"`
+}
+
+// LevelRowResponse строка таблицы уровней
+type LevelRowResponse struct {
+	Level   int                   `json:"level"`
+	XP      int                   `json:"xp"`
+	Title   string                `json:"title"`
+	Rewards []LevelRewardResponse `json:"rewards"`
+	Reached bool                  `json:"reached"`
+}
+
+// LevelUpResponse переход на уровень с выданными наградами
+type LevelUpResponse struct {
+	Level      int                   `json:"level"`
+	Title      string                `json:"title"`
+	Rewards    []LevelRewardResponse `json:"rewards"`
+	Backfilled bool                  `json:"backfilled"`
+	CreatedAt  string                `json:"created_at"`
+}
+
+// LevelsResponse прогресс ребёнка по таблице уровней
+type LevelsResponse struct {
+	Version      int                `json:"version"`
+	Level        int                `json:"level"`
+	Title        string             `json:"title"`
+	XPTotal      int                `json:"xp_total"`
+	XPInLevel    int                `json:"xp_in_level"`
+	XPNeeded     int                `json:"xp_needed"`
+	ShopDiscount int                `json:"shop_discount_percent"`
+	Levels       []LevelRowResponse `json:"levels"`
+	LevelUps     []LevelUpResponse  `json:"level_ups"` // непросмотренные поздравления, новые первыми
+}
+
+// MarkLevelsSeenResponse результат отметки поздравлений
+type MarkLevelsSeenResponse struct {
+	Marked int `json:"marked"`
+}
+
+// toLevelRewardsResponse преобразует награды уровня в JSON
+func toLevelRewardsResponse(rewards []level.Reward) []LevelRewardResponse {
+	resp := make([]LevelRewardResponse, 0, len(rewards))
+	for _, r := range rewards {
+		resp = append(resp, LevelRewardResponse{Type: r.Type, ID: r.ID, Name: r.Name, Amount: r.Amount, Percent: r.Percent})
+	}
+	return resp
+}
+
+// GetLevels возвращает таблицу уровней, прогресс ребёнка и непросмотренные переходы
+// на новый уровень с наградами
+// GET /levels
+func (h *LevelHandler) GetLevels(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	o, err := h.service.GetLevels(r.Context(), childProfileID)
+	if err != nil {
+		log.Printf("[LevelHandler] Failed to get levels for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get levels")
+		return
+	}
+
+	resp := LevelsResponse{
+		Version:      o.Version,
+		Level:        o.Level,
+		Title:        o.Title,
+		XPTotal:      o.XPTotal,
+		XPInLevel:    o.XPInLevel,
+		XPNeeded:     o.XPNeeded,
+		ShopDiscount: o.ShopDiscount,
+		Levels:       make([]LevelRowResponse, 0, len(o.Levels)),
+		LevelUps:     make([]LevelUpResponse, 0, len(o.Pending)),
+	}
+	titles := make(map[int]string, len(o.Levels))
+	for _, l := range o.Levels {
+		titles[l.Level.Level] = l.Title
+		resp.Levels = append(resp.Levels, LevelRowResponse{
+			Level:   l.Level.Level,
+			XP:      l.XP,
+			Title:   l.Title,
+			Rewards: toLevelRewardsResponse(l.Rewards),
+			Reached: l.Reached,
+		})
+	}
+	for _, u := range o.Pending {
+		resp.LevelUps = append(resp.LevelUps, LevelUpResponse{
+			Level:      u.Level,
+			Title:      titles[u.Level],
+			Rewards:    toLevelRewardsResponse(u.Rewards),
+			Backfilled: u.Backfilled,
+			CreatedAt:  u.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	response.OK(w, resp)
+}
+
+// MarkSeen отмечает поздравления с уровнями до level включительно просмотренными
+// POST /levels/{level}/seen
+func (h *LevelHandler) MarkSeen(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	upTo, err := strconv.Atoi(r.PathValue("level"))
+	if err != nil || upTo < 2 {
+		response.BadRequest(w, "level must be an integer >= 2")
+		return
+	}
+
+	n, err := h.service.MarkSeen(r.Context(), childProfileID, upTo)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.BadRequest(w, "level must be an integer >= 2")
+			return
+		}
+		log.Printf("[LevelHandler] Failed to mark level ups seen for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to mark level ups seen")
+		return
+	}
+	response.OK(w, MarkLevelsSeenResponse{Marked: n})
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/service"
+)
+
+// MissionServiceInterface интерфейс для MissionService
+type MissionServiceInterface interface {
+	GetTodayMissions(ctx context.Context, childProfileID string) (*service.DailyMissions, error)
+}
+
+// MissionHandler обрабатывает запросы ежедневных миссий
+type MissionHandler struct {
+	service MissionServiceInterface
+}
+
+// NewMissionHandler создает новый MissionHandler
+func NewMissionHandler(missionService MissionServiceInterface) *MissionHandler {
+	return &MissionHandler{service: missionService}
+}
+
+// MissionRewardResponse награда за миссию
+type MissionRewardResponse struct {
+	Coins int `json:"coins"`
+	XP    int `json:"xp"`
+}
+
+// DailyMissionResponse миссия дня
+type DailyMissionResponse struct {
+	ID          int64                 `json:"id"`
+	MissionID   string                `json:"mission_id"`
+	Icon        string                `json:"icon"`
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	Subject     string                `json:"subject,omitempty"`
+	Target      int                   `json:"target"`
+	Progress    int                   `json:"progress"`
+	Reward      MissionRewardResponse `json:"reward"`
+	Completed   bool                  `json:"completed"`
+	CompletedAt string                `json:"completed_at,omitempty"`
+}
+
+// DailyMissionsResponse миссии ребёнка на сегодня
+type DailyMissionsResponse struct {
+	Date         string                 `json:"date"`
+	Items        []DailyMissionResponse `json:"items"`
+	Completed    int                    `json:"completed_count"`
+	Total        int                    `json:"total_count"`
+	AllDoneBonus MissionRewardResponse  `json:"all_done_bonus"`
+}
+
+// GetToday возвращает миссии ребёнка на сегодня
+// GET /missions/today
+func (h *MissionHandler) GetToday(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	missions, err := h.service.GetTodayMissions(r.Context(), childProfileID)
+	if err != nil {
+		log.Printf("[MissionHandler] Failed to get missions for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get missions")
+		return
+	}
+
+	response.OK(w, toDailyMissionsResponse(missions))
+}
+
+func toDailyMissionsResponse(missions *service.DailyMissions) DailyMissionsResponse {
+	resp := DailyMissionsResponse{
+		Date:      missions.Date,
+		Items:     make([]DailyMissionResponse, 0, len(missions.Items)),
+		Completed: missions.Completed,
+		Total:     missions.Total,
+		AllDoneBonus: MissionRewardResponse{
+			Coins: missions.AllDoneBonus.Coins,
+			XP:    missions.AllDoneBonus.XP,
+		},
+	}
+	for _, m := range missions.Items {
+		item := DailyMissionResponse{
+			ID:          m.ID,
+			MissionID:   m.MissionID,
+			Icon:        m.Icon,
+			Title:       m.Title,
+			Description: m.Description,
+			Subject:     m.Subject,
+			Target:      m.Target,
+			Progress:    m.Progress,
+			Reward:      MissionRewardResponse{Coins: m.RewardCoins, XP: m.RewardXP},
+			Completed:   m.Completed,
+		}
+		if m.CompletedAt != nil {
+			item.CompletedAt = m.CompletedAt.Format("2006-01-02T15:04:05Z07:00")
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	return resp
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/service"
+)
+
+// ParentServiceInterface интерфейс для ParentService
+type ParentServiceInterface interface {
+	GetPINStatus(ctx context.Context, childProfileID, platformID, vkUserID string) (*service.ParentPINStatus, error)
+	RequestPINCode(ctx context.Context, childProfileID, platformID, vkUserID string) (*service.ParentPINCode, error)
+	SetPIN(ctx context.Context, childProfileID, platformID, vkUserID, currentPIN, emailCode, newPIN string) error
+	StartSession(ctx context.Context, childProfileID, platformID, vkUserID, pin string) (*service.ParentSessionToken, error)
+	EndSession(ctx context.Context, token string) error
+}
+
+// ParentHandler обрабатывает запросы родительской сессии
+type ParentHandler struct {
+	service ParentServiceInterface
+}
+
+// NewParentHandler создает новый ParentHandler
+func NewParentHandler(parentService ParentServiceInterface) *ParentHandler {
+	return &ParentHandler{service: parentService}
+}
+
+// SetParentPINRequest запрос установки PIN родителя
+type SetParentPINRequest struct {
+	CurrentPIN string `json:"current_pin,omitempty"` // смена заданного PIN
+	Code       string `json:"code,omitempty"`        // код из письма: первый PIN или сброс забытого
+	PIN        string `json:"pin"`
+}
+
+// StartParentSessionRequest запрос родительской сессии
+type StartParentSessionRequest struct {
+	PIN string `json:"pin"`
+}
+
+// ParentPINStatusResponse задан ли PIN родителя
+type ParentPINStatusResponse struct {
+	PINSet      bool    `json:"pin_set"`
+	LockedUntil *string `json:"locked_until,omitempty"`
+}
+
+// ParentPINCodeResponse куда отправлен код для PIN
+type ParentPINCodeResponse struct {
+	Email     string `json:"email"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// ParentSessionResponse токен родительской сессии
+type ParentSessionResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// GetPINStatus возвращает, задан ли PIN родителя (нужны подписанные параметры запуска)
+// GET /parent/pin
+func (h *ParentHandler) GetPINStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID := middleware.GetChildProfileID(ctx)
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	status, err := h.service.GetPINStatus(ctx, childProfileID, middleware.GetPlatformID(ctx), middleware.GetVKUserID(ctx))
+	if err != nil {
+		writeParentError(w, err, "Failed to get PIN status", childProfileID)
+		return
+	}
+
+	resp := ParentPINStatusResponse{PINSet: status.PINSet}
+	if status.LockedUntil != nil {
+		lockedUntil := status.LockedUntil.Format(time.RFC3339)
+		resp.LockedUntil = &lockedUntil
+	}
+	response.OK(w, resp)
+}
+
+// RequestPINCode отправляет код для первого PIN или сброса на email родителя
+// (нужны подписанные параметры запуска)
+// POST /parent/pin/code
+func (h *ParentHandler) RequestPINCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID := middleware.GetChildProfileID(ctx)
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	code, err := h.service.RequestPINCode(ctx, childProfileID, middleware.GetPlatformID(ctx), middleware.GetVKUserID(ctx))
+	if err != nil {
+		writeParentError(w, err, "Failed to send PIN code", childProfileID)
+		return
+	}
+
+	response.OK(w, ParentPINCodeResponse{Email: code.Email, ExpiresAt: code.ExpiresAt.Format(time.RFC3339)})
+}
+
+// SetPIN задаёт, меняет или сбрасывает PIN родителя (нужны подписанные параметры запуска)
+// POST /parent/pin
+func (h *ParentHandler) SetPIN(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID := middleware.GetChildProfileID(ctx)
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	var req SetParentPINRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	err := h.service.SetPIN(ctx, childProfileID, middleware.GetPlatformID(ctx), middleware.GetVKUserID(ctx), req.CurrentPIN, req.Code, req.PIN)
+	if err != nil {
+		writeParentError(w, err, "Failed to set PIN", childProfileID)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// StartSession открывает родительскую сессию по PIN (нужны подписанные параметры запуска)
+// POST /parent/session
+func (h *ParentHandler) StartSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	childProfileID := middleware.GetChildProfileID(ctx)
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	var req StartParentSessionRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if err := validation.ValidateRequired(req.PIN, "pin"); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	session, err := h.service.StartSession(ctx, childProfileID, middleware.GetPlatformID(ctx), middleware.GetVKUserID(ctx), req.PIN)
+	if err != nil {
+		writeParentError(w, err, "Failed to start parent session", childProfileID)
+		return
+	}
+
+	response.Created(w, ParentSessionResponse{Token: session.Token, ExpiresAt: session.ExpiresAt.Format(time.RFC3339)})
+}
+
+// EndSession завершает родительскую сессию из X-Parent-Session
+// DELETE /parent/session
+func (h *ParentHandler) EndSession(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.EndSession(r.Context(), middleware.GetParentSession(r.Context())); err != nil {
+		log.Printf("[ParentHandler] Failed to end parent session: %v", err)
+		response.InternalError(w, "Failed to end parent session")
+		return
+	}
+	response.NoContent(w)
+}
+
+func writeParentError(w http.ResponseWriter, err error, message, childProfileID string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		response.BadRequest(w, "pin must be 6-8 digits")
+	case errors.Is(err, domain.ErrUnauthorized):
+		response.Unauthorized(w, "Signed launch params required")
+	case errors.Is(err, domain.ErrForbidden):
+		response.Forbidden(w, "Not a parent of this child, wrong PIN or wrong code")
+	case errors.Is(err, domain.ErrParentEmailRequired):
+		response.Conflict(w, "Verify parent email first")
+	case errors.Is(err, domain.ErrConflict):
+		response.Conflict(w, "Parent PIN is not set")
+	case errors.Is(err, domain.ErrRateLimited):
+		w.Header().Set("Retry-After", "900")
+		response.Error(w, http.StatusTooManyRequests, "Too many wrong PIN attempts, try later")
+	default:
+		log.Printf("[ParentHandler] %s for child %s: %v", message, childProfileID, err)
+		response.InternalError(w, message)
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/service"
+)
+
+// PetServiceInterface интерфейс для PetService
+type PetServiceInterface interface {
+	GetPet(ctx context.Context, childProfileID string) (*service.Pet, error)
+}
+
+// PetHandler обрабатывает запросы питомца
+type PetHandler struct {
+	service PetServiceInterface
+}
+
+// NewPetHandler создает новый PetHandler
+func NewPetHandler(petService PetServiceInterface) *PetHandler {
+	return &PetHandler{service: petService}
+}
+
+// PetResponse питомец ребёнка
+type PetResponse struct {
+	Type           string `json:"type"`
+	Stage          int    `json:"stage"`
+	StageCode      string `json:"stage_code"` // egg, baby, teen, adult
+	StageName      string `json:"stage_name"`
+	Hunger         int    `json:"hunger"` // 0 — сыт, 100 — очень голоден
+	Happiness      int    `json:"happiness"`
+	Energy         int    `json:"energy"`
+	Mood           string `json:"mood"` // happy, ok, hungry, sad, tired, sleeping
+	IsSleeping     bool   `json:"is_sleeping"`
+	Message        string `json:"message"`
+	ImageURL       string `json:"image_url"`
+	Level          int    `json:"level"`
+	NextStageLevel int    `json:"next_stage_level,omitempty"`
+	JustEvolved    bool   `json:"just_evolved"`
+	LastFedAt      string `json:"last_fed_at,omitempty"`
+}
+
+// Get возвращает питомца с показателями на текущий момент
+// GET /pet
+func (h *PetHandler) Get(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	p, err := h.service.GetPet(r.Context(), childProfileID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.NotFound(w, "Pet not found")
+			return
+		}
+		log.Printf("[PetHandler] Failed to get pet for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get pet")
+		return
+	}
+
+	resp := PetResponse{
+		Type:           p.Type,
+		Stage:          p.Stage,
+		StageCode:      p.StageCode,
+		StageName:      p.StageName,
+		Hunger:         p.Hunger,
+		Happiness:      p.Happiness,
+		Energy:         p.Energy,
+		Mood:           p.Mood,
+		IsSleeping:     p.IsSleeping,
+		Message:        p.Message,
+		ImageURL:       p.ImageURL,
+		Level:          p.Level,
+		NextStageLevel: p.NextStageLevel,
+		JustEvolved:    p.JustEvolved,
+	}
+	if p.LastFedAt != nil {
+		resp.LastFedAt = p.LastFedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	response.OK(w, resp)
+}
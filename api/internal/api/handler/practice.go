@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/service"
+	"child-bot/api/internal/store"
+)
+
+// PracticeServiceInterface интерфейс для PracticeService
+type PracticeServiceInterface interface {
+	GetDrills(ctx context.Context, childProfileID string) (*service.PracticeOverview, error)
+	StartSession(ctx context.Context, childProfileID, kind string) (*store.PracticeSession, error)
+	GetSession(ctx context.Context, childProfileID string, sessionID int64) (*store.PracticeSession, error)
+	Answer(ctx context.Context, childProfileID string, sessionID int64, position int, answer string) (*store.PracticeAnswerResult, error)
+	Finish(ctx context.Context, childProfileID string, sessionID int64) (*store.PracticeFinishResult, error)
+}
+
+// PracticeHandler обрабатывает запросы разминок
+type PracticeHandler struct {
+	service PracticeServiceInterface
+}
+
+// NewPracticeHandler создает новый PracticeHandler
+func NewPracticeHandler(practiceService PracticeServiceInterface) *PracticeHandler {
+	return &PracticeHandler{service: practiceService}
+}
+
+// PracticeDrillResponse вид разминки
+type PracticeDrillResponse struct {
+	Kind      string `json:"kind"`
+	Subject   string `json:"subject"`
+	Icon      string `json:"icon"`
+	Title     string `json:"title"`
+	Available bool   `json:"available"`
+	Weak      bool   `json:"weak"`
+}
+
+// PracticeRewardsResponse награда за разминки и сколько уже получено сегодня
+type PracticeRewardsResponse struct {
+	XPPerCorrect    int `json:"xp_per_correct"`
+	CoinsPerCorrect int `json:"coins_per_correct"`
+	DailyXPCap      int `json:"daily_xp_cap"`
+	DailyCoinsCap   int `json:"daily_coins_cap"`
+	XPToday         int `json:"xp_today"`
+	CoinsToday      int `json:"coins_today"`
+}
+
+// PracticeDrillsResponse разминки ребёнка
+type PracticeDrillsResponse struct {
+	Grade       int                     `json:"grade"`
+	Recommended string                  `json:"recommended,omitempty"`
+	Drills      []PracticeDrillResponse `json:"drills"`
+	Rewards     PracticeRewardsResponse `json:"rewards"`
+}
+
+// StartPracticeRequest запрос начала разминки
+type StartPracticeRequest struct {
+	Kind string `json:"kind,omitempty"` // пусто — разминка для самого слабого места
+}
+
+// PracticeItemResponse задание; верный ответ и правило — только после ответа ребёнка
+type PracticeItemResponse struct {
+	Position      int      `json:"position"`
+	Prompt        string   `json:"prompt"`
+	Options       []string `json:"options,omitempty"`
+	Hint          string   `json:"hint,omitempty"`
+	Answered      bool     `json:"answered"`
+	GivenAnswer   string   `json:"given_answer,omitempty"`
+	IsCorrect     *bool    `json:"is_correct,omitempty"`
+	CorrectAnswer string   `json:"correct_answer,omitempty"`
+}
+
+// PracticeSessionResponse сессия разминки
+type PracticeSessionResponse struct {
+	ID           int64                  `json:"id"`
+	Kind         string                 `json:"kind"`
+	Grade        int                    `json:"grade"`
+	Status       string                 `json:"status"`
+	StartedAt    string                 `json:"started_at"`
+	ExpiresAt    string                 `json:"expires_at"`
+	Items        []PracticeItemResponse `json:"items"`
+	CorrectCount int                    `json:"correct_count"`
+	XPAwarded    int                    `json:"xp_awarded"`
+	CoinsAwarded int                    `json:"coins_awarded"`
+}
+
+// PracticeAnswerRequest ответ на задание
+type PracticeAnswerRequest struct {
+	Position *int   `json:"position"`
+	Answer   string `json:"answer"`
+}
+
+// PracticeAnswerResponse итог ответа
+type PracticeAnswerResponse struct {
+	Position      int    `json:"position"`
+	IsCorrect     bool   `json:"is_correct"`
+	Counted       bool   `json:"counted"`
+	CorrectAnswer string `json:"correct_answer"`
+	Hint          string `json:"hint,omitempty"`
+	Answered      int    `json:"answered"`
+	Total         int    `json:"total"`
+}
+
+// PracticeFinishResponse итог разминки
+type PracticeFinishResponse struct {
+	SessionID    int64 `json:"session_id"`
+	Answered     int   `json:"answered"`
+	Total        int   `json:"total"`
+	CorrectCount int   `json:"correct_count"`
+	XPAwarded    int   `json:"xp_awarded"`
+	CoinsAwarded int   `json:"coins_awarded"`
+	CapReached   bool  `json:"cap_reached"`
+	LeveledUp    bool  `json:"leveled_up"`
+}
+
+// GetDrills возвращает виды разминок с отметкой слабых мест
+// GET /practice/drills
+func (h *PracticeHandler) GetDrills(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	o, err := h.service.GetDrills(r.Context(), childProfileID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.NotFound(w, "Child profile not found")
+			return
+		}
+		log.Printf("[PracticeHandler] Failed to get drills for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get drills")
+		return
+	}
+
+	resp := PracticeDrillsResponse{
+		Grade:       o.Grade,
+		Recommended: o.Recommended,
+		Drills:      make([]PracticeDrillResponse, 0, len(o.Drills)),
+		Rewards: PracticeRewardsResponse{
+			XPPerCorrect:    o.Rewards.XPPerCorrect,
+			CoinsPerCorrect: o.Rewards.CoinsPerCorrect,
+			DailyXPCap:      o.Rewards.DailyXPCap,
+			DailyCoinsCap:   o.Rewards.DailyCoinsCap,
+			XPToday:         o.XPToday,
+			CoinsToday:      o.CoinsToday,
+		},
+	}
+	for _, d := range o.Drills {
+		resp.Drills = append(resp.Drills, PracticeDrillResponse{
+			Kind:      d.Kind,
+			Subject:   d.Subject,
+			Icon:      d.Icon,
+			Title:     d.Title,
+			Available: d.Available,
+			Weak:      d.Weak,
+		})
+	}
+	response.OK(w, resp)
+}
+
+// StartSession начинает разминку
+// POST /practice/sessions
+func (h *PracticeHandler) StartSession(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	var req StartPracticeRequest
+	if r.ContentLength != 0 {
+		if err := validation.DecodeJSON(r, &req); err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+	}
+
+	session, err := h.service.StartSession(r.Context(), childProfileID, req.Kind)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Drill not found")
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.BadRequest(w, "Drill is not available for this grade")
+		default:
+			log.Printf("[PracticeHandler] Failed to start %q for child %s: %v", req.Kind, childProfileID, err)
+			response.InternalError(w, "Failed to start drill")
+		}
+		return
+	}
+
+	response.Created(w, toPracticeSessionResponse(session))
+}
+
+// GetSession возвращает сессию разминки
+// GET /practice/sessions/{id}
+func (h *PracticeHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	childProfileID, sessionID, ok := practiceSessionParams(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := h.service.GetSession(r.Context(), childProfileID, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.NotFound(w, "Session not found")
+			return
+		}
+		log.Printf("[PracticeHandler] Failed to get session %d for child %s: %v", sessionID, childProfileID, err)
+		response.InternalError(w, "Failed to get session")
+		return
+	}
+
+	response.OK(w, toPracticeSessionResponse(session))
+}
+
+// Answer проверяет ответ на задание
+// POST /practice/sessions/{id}/answer
+func (h *PracticeHandler) Answer(w http.ResponseWriter, r *http.Request) {
+	childProfileID, sessionID, ok := practiceSessionParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req PracticeAnswerRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if req.Position == nil || *req.Position < 0 {
+		response.BadRequest(w, "position is required")
+		return
+	}
+	if err := validation.ValidateRequired(req.Answer, "answer"); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if err := validation.ValidateMaxLength(req.Answer, "answer", 50); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	res, err := h.service.Answer(r.Context(), childProfileID, sessionID, *req.Position, req.Answer)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Session or item not found")
+		case errors.Is(err, domain.ErrConflict):
+			response.Conflict(w, "Item already answered or session is over")
+		default:
+			log.Printf("[PracticeHandler] Failed to answer session %d for child %s: %v", sessionID, childProfileID, err)
+			response.InternalError(w, "Failed to check answer")
+		}
+		return
+	}
+
+	response.OK(w, PracticeAnswerResponse{
+		Position:      res.Position,
+		IsCorrect:     res.IsCorrect,
+		Counted:       res.Counted,
+		CorrectAnswer: res.CorrectAnswer,
+		Hint:          res.Hint,
+		Answered:      res.Answered,
+		Total:         res.Total,
+	})
+}
+
+// Finish завершает разминку и начисляет награду
+// POST /practice/sessions/{id}/finish
+func (h *PracticeHandler) Finish(w http.ResponseWriter, r *http.Request) {
+	childProfileID, sessionID, ok := practiceSessionParams(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.Finish(r.Context(), childProfileID, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.NotFound(w, "Session not found")
+			return
+		}
+		log.Printf("[PracticeHandler] Failed to finish session %d for child %s: %v", sessionID, childProfileID, err)
+		response.InternalError(w, "Failed to finish session")
+		return
+	}
+
+	p := res.Session
+	response.OK(w, PracticeFinishResponse{
+		SessionID:    p.ID,
+		Answered:     res.Answered,
+		Total:        p.ItemsTotal,
+		CorrectCount: p.CorrectCount,
+		XPAwarded:    p.XPAwarded,
+		CoinsAwarded: p.CoinsAwarded,
+		CapReached:   res.CapReached,
+		LeveledUp:    res.LeveledUp,
+	})
+}
+
+// practiceSessionParams ребёнок и id сессии из запроса; при ошибке ответ уже отправлен
+func practiceSessionParams(w http.ResponseWriter, r *http.Request) (string, int64, bool) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return "", 0, false
+	}
+	sessionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || sessionID <= 0 {
+		response.BadRequest(w, "invalid session id")
+		return "", 0, false
+	}
+	return childProfileID, sessionID, true
+}
+
+func toPracticeSessionResponse(p *store.PracticeSession) PracticeSessionResponse {
+	resp := PracticeSessionResponse{
+		ID:           p.ID,
+		Kind:         p.Kind,
+		Grade:        p.Grade,
+		Status:       p.Status,
+		StartedAt:    p.StartedAt.Format(time.RFC3339),
+		ExpiresAt:    p.ExpiresAt.Format(time.RFC3339),
+		Items:        make([]PracticeItemResponse, 0, len(p.Items)),
+		CorrectCount: p.CorrectCount,
+		XPAwarded:    p.XPAwarded,
+		CoinsAwarded: p.CoinsAwarded,
+	}
+	for _, item := range p.Items {
+		ir := PracticeItemResponse{
+			Position: item.Position,
+			Prompt:   item.Prompt,
+			Options:  item.Options,
+			Answered: item.Answered,
+		}
+		if item.Answered {
+			isCorrect := item.IsCorrect
+			ir.GivenAnswer, ir.IsCorrect, ir.CorrectAnswer, ir.Hint = item.GivenAnswer, &isCorrect, item.Answer, item.Hint
+		}
+		resp.Items = append(resp.Items, ir)
+	}
+	return resp
+}
@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 
 	"child-bot/api/internal/api/middleware"
 	"child-bot/api/internal/api/response"
 	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/domain"
 	"child-bot/api/internal/service"
 )
 
@@ -27,6 +31,7 @@ type ProfileData struct {
 	AvatarID     string           `json:"avatar_id"`
 	AvatarURL    string           `json:"avatar_url"`
 	Grade        int              `json:"grade"`
+	Timezone     string           `json:"timezone"`
 	Subscription SubscriptionData `json:"subscription"`
 }
 
@@ -44,6 +49,7 @@ type CreateChildProfileRequest struct {
 	AvatarID     string `json:"avatarId"`
 	DisplayName  string `json:"displayName"`
 	ReferralCode string `json:"referralCode,omitempty"`
+	Timezone     string `json:"timezone,omitempty"` // IANA-пояс из окружения запуска мини-приложения
 }
 
 type CreateChildProfileResponse struct {
@@ -54,6 +60,7 @@ type UpdateProfileRequest struct {
 	DisplayName string `json:"display_name,omitempty"`
 	AvatarID    string `json:"avatar_id,omitempty"`
 	Grade       int    `json:"grade,omitempty"`
+	Timezone    string `json:"timezone,omitempty"`
 }
 
 type HistoryAttempt struct {
@@ -90,6 +97,28 @@ type ErrorFeedback struct {
 	LocationType  string `json:"location_type"` // line, step, general
 }
 
+// WalletTransaction запись журнала монет и XP
+type WalletTransaction struct {
+	ID           int64  `json:"id"`
+	Currency     string `json:"currency"` // coins, xp
+	Amount       int    `json:"amount"`   // > 0 начисление, < 0 списание
+	BalanceAfter int    `json:"balance_after"`
+	Source       string `json:"source"` // attempt, hint, achievement, villain_battle, level_up, ...
+	SourceID     string `json:"source_id,omitempty"`
+	Description  string `json:"description,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// WalletTransactionsResponse страница журнала монет и XP
+type WalletTransactionsResponse struct {
+	Transactions []WalletTransaction `json:"transactions"`
+	Total        int                 `json:"total"`
+	Limit        int                 `json:"limit"`
+	Offset       int                 `json:"offset"`
+	CoinsBalance int                 `json:"coins_balance"`
+	XPTotal      int                 `json:"xp_total"`
+}
+
 type ProfileStats struct {
 	TotalAttempts      int     `json:"total_attempts"`
 	SuccessfulAttempts int     `json:"successful_attempts"`
@@ -137,9 +166,19 @@ func (h *ProfileHandler) CreateChild(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Пояс приходит из окружения устройства: если он незнакомый, не блокируем онбординг
+	if req.Timezone != "" && !calendar.IsValidTimezone(req.Timezone) {
+		log.Printf("Unknown timezone %q for new child profile, using %s", req.Timezone, calendar.DefaultTimezone)
+		req.Timezone = ""
+	}
+
 	// Создание профиля через service layer
-	childProfileID, err := h.service.CreateChildProfile(r.Context(), req.ParentUserID, req.DisplayName, req.AvatarID, platformID, req.Grade)
+	childProfileID, err := h.service.CreateChildProfile(r.Context(), req.ParentUserID, req.DisplayName, req.AvatarID, platformID, req.Grade, req.Timezone)
 	if err != nil {
+		if errors.Is(err, domain.ErrForbidden) {
+			response.Forbidden(w, "Avatar is not owned")
+			return
+		}
 		response.InternalError(w, "Failed to create child profile")
 		return
 	}
@@ -180,6 +219,7 @@ func (h *ProfileHandler) Get(w http.ResponseWriter, r *http.Request) {
 		AvatarID:    serviceProfile.AvatarID,
 		AvatarURL:   serviceProfile.AvatarURL,
 		Grade:       serviceProfile.Grade,
+		Timezone:    serviceProfile.Timezone,
 		Subscription: SubscriptionData{
 			Status:             serviceProfile.Subscription.Status,
 			PlanID:             serviceProfile.Subscription.PlanID,
@@ -214,13 +254,36 @@ func (h *ProfileHandler) Update(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if req.Grade < 1 || req.Grade > 4 {
+	if req.Grade != 0 && (req.Grade < 1 || req.Grade > 4) {
 		response.BadRequest(w, "grade must be between 1 and 4")
 		return
 	}
 
-	// TODO: Phase 4 - обновление через service layer
-	// err := h.service.UpdateProfile(r.Context(), childProfileID, req)
+	if req.Timezone != "" && !calendar.IsValidTimezone(req.Timezone) {
+		response.BadRequest(w, "timezone must be an IANA time zone, e.g. Europe/Moscow")
+		return
+	}
+
+	err := h.service.UpdateProfile(r.Context(), childProfileID, service.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		AvatarID:    req.AvatarID,
+		Grade:       req.Grade,
+		Timezone:    req.Timezone,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrForbidden):
+			response.ErrorWithCode(w, http.StatusForbidden, "Avatar is not owned", "AVATAR_NOT_OWNED")
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.BadRequest(w, "Invalid profile data")
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Profile not found")
+		default:
+			log.Printf("UpdateProfile error: %v", err)
+			response.InternalError(w, "Failed to update profile")
+		}
+		return
+	}
 
 	response.OK(w, map[string]string{"message": "Profile updated successfully"})
 }
@@ -283,6 +346,67 @@ func (h *ProfileHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, history)
 }
 
+// GetTransactions получает журнал начислений и списаний монет и XP
+// GET /profile/transactions?currency=coins|xp&limit=50&offset=0
+func (h *ProfileHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if _, err := fmt.Sscanf(limitStr, "%d", &limit); err != nil || limit <= 0 || limit > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+	}
+
+	offset := 0
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if _, err := fmt.Sscanf(offsetStr, "%d", &offset); err != nil || offset < 0 {
+			response.BadRequest(w, "offset must be non-negative")
+			return
+		}
+	}
+
+	currency := r.URL.Query().Get("currency")
+	result, err := h.service.GetTransactions(r.Context(), childProfileID, currency, limit, offset)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.BadRequest(w, "currency must be coins or xp")
+			return
+		}
+		log.Printf("GetTransactions error: %v", err)
+		response.InternalError(w, "Failed to get transactions")
+		return
+	}
+
+	resp := WalletTransactionsResponse{
+		Transactions: make([]WalletTransaction, 0, len(result.Transactions)),
+		Total:        result.Total,
+		Limit:        limit,
+		Offset:       offset,
+		CoinsBalance: result.CoinsBalance,
+		XPTotal:      result.XPTotal,
+	}
+	for _, t := range result.Transactions {
+		resp.Transactions = append(resp.Transactions, WalletTransaction{
+			ID:           t.ID,
+			Currency:     t.Currency,
+			Amount:       t.Amount,
+			BalanceAfter: t.BalanceAfter,
+			Source:       t.Source,
+			SourceID:     t.SourceID,
+			Description:  t.Description,
+			CreatedAt:    t.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	response.OK(w, resp)
+}
+
 // GetStats получает статистику профиля
 // GET /profile/stats
 func (h *ProfileHandler) GetStats(w http.ResponseWriter, r *http.Request) {
@@ -2,25 +2,31 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 
 	"child-bot/api/internal/api/middleware"
 	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/service"
 	"child-bot/api/internal/store"
 )
 
 // ReferralHandler обрабатывает запросы друзей и рефералов
 type ReferralHandler struct {
-	store  *store.Store
-	appURL string
+	store       *store.Store
+	leaderboard *service.LeaderboardService
+	appURL      string
 }
 
 // NewReferralHandler создает новый ReferralHandler
-func NewReferralHandler(store *store.Store, appURL string) *ReferralHandler {
+func NewReferralHandler(store *store.Store, leaderboard *service.LeaderboardService, appURL string) *ReferralHandler {
 	return &ReferralHandler{
-		store:  store,
-		appURL: appURL,
+		store:       store,
+		leaderboard: leaderboard,
+		appURL:      appURL,
 	}
 }
 
@@ -59,15 +65,17 @@ type InvitedFriend struct {
 	RewardEarned int    `json:"reward_earned"`
 }
 
-// LeaderboardEntry запись в leaderboard
+// LeaderboardEntry запись в leaderboard. ID профилей друзей не отдаются: по ним проходит
+// авторизация (X-Child-Profile-ID); себя ребёнок находит по IsCurrentUser.
 type LeaderboardEntry struct {
-	Rank          int    `json:"rank"`
-	ChildID       string `json:"child_id"`
-	DisplayName   string `json:"display_name"`
-	AvatarURL     string `json:"avatar_url"`
-	TasksSolved   int    `json:"tasks_solved"`
-	Level         int    `json:"level"`
-	IsCurrentUser bool   `json:"is_current_user"`
+	Rank             int    `json:"rank"`
+	DisplayName      string `json:"display_name"`
+	AvatarURL        string `json:"avatar_url"`
+	TasksSolved      int    `json:"tasks_solved"`
+	XPGained         int    `json:"xp_gained"`
+	VillainsDefeated int    `json:"villains_defeated"`
+	Level            int    `json:"level"`
+	IsCurrentUser    bool   `json:"is_current_user"`
 }
 
 // InviteRequest запрос на приглашение
@@ -180,7 +188,7 @@ func (h *ReferralHandler) Invite(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetLeaderboard получает leaderboard друзей
-// GET /friends/leaderboard
+// GET /friends/leaderboard?period=week|month|all&metric=tasks|xp|villains&limit=10
 func (h *ReferralHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
 	childProfileID := middleware.GetChildProfileID(r.Context())
 	if childProfileID == "" {
@@ -189,31 +197,41 @@ func (h *ReferralHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Query parameters
-	// period := r.URL.Query().Get("period") // week, month, all
+	query := r.URL.Query()
+	period := query.Get("period")
+	metric := query.Get("metric")
+
+	limit := service.DefaultLeaderboardLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if _, err := fmt.Sscanf(limitStr, "%d", &limit); err != nil || limit < 1 || limit > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+	}
 
-	// TODO: Phase 4 - получение leaderboard через service layer
-	// leaderboard, err := h.service.GetFriendsLeaderboard(r.Context(), childProfileID, period)
+	entries, err := h.leaderboard.GetFriendsLeaderboard(r.Context(), childProfileID, period, metric, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.BadRequest(w, "period must be week, month or all; metric must be tasks, xp or villains")
+			return
+		}
+		log.Printf("[ReferralHandler] Failed to get leaderboard for %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get leaderboard")
+		return
+	}
 
-	// Placeholder
-	leaderboard := []LeaderboardEntry{
-		{
-			Rank:          1,
-			ChildID:       "child_1",
-			DisplayName:   "Ваня",
-			AvatarURL:     "/assets/avatars/avatar_2.png",
-			TasksSolved:   50,
-			Level:         6,
-			IsCurrentUser: false,
-		},
-		{
-			Rank:          2,
-			ChildID:       childProfileID,
-			DisplayName:   "Ты",
-			AvatarURL:     "/assets/avatars/avatar_1.png",
-			TasksSolved:   42,
-			Level:         5,
-			IsCurrentUser: true,
-		},
+	leaderboard := make([]LeaderboardEntry, 0, len(entries))
+	for _, e := range entries {
+		leaderboard = append(leaderboard, LeaderboardEntry{
+			Rank:             e.Rank,
+			DisplayName:      e.DisplayName,
+			AvatarURL:        "/assets/avatars/" + e.AvatarID + ".png",
+			TasksSolved:      e.TasksSolved,
+			XPGained:         e.XPGained,
+			VillainsDefeated: e.VillainsDefeated,
+			Level:            e.Level,
+			IsCurrentUser:    e.IsCurrentUser,
+		})
 	}
 
 	response.OK(w, leaderboard)
@@ -32,13 +32,11 @@ func (h *ReportHandler) GetWeeklyData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Определяем период
-	now := time.Now()
-	weekday := now.Weekday()
-	if weekday == time.Sunday {
-		weekday = 7
+	weekStart, err := h.reportService.WeekStart(r.Context(), childProfileID, "")
+	if err != nil {
+		response.InternalError(w, "Failed to determine report week")
+		return
 	}
-	weekStart := now.AddDate(0, 0, -int(weekday-1))
-	weekStart = time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, now.Location())
 
 	data, err := h.reportService.GetWeeklyReportData(r.Context(), childProfileID, weekStart)
 	if err != nil {
@@ -60,13 +58,11 @@ func (h *ReportHandler) GetWeeklyHTML(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Определяем период: начало текущей недели (понедельник)
-	now := time.Now()
-	weekday := now.Weekday()
-	if weekday == time.Sunday {
-		weekday = 7
+	weekStart, err := h.reportService.WeekStart(r.Context(), childProfileID, "")
+	if err != nil {
+		response.InternalError(w, "Failed to determine report week")
+		return
 	}
-	weekStart := now.AddDate(0, 0, -int(weekday-1))
-	weekStart = time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, now.Location())
 
 	log.Printf("[ReportHandler] Getting weekly HTML for profile %s, week starting %s", childProfileID, weekStart.Format("2006-01-02"))
 
@@ -161,7 +157,7 @@ func (h *ReportHandler) GetReportByDate(w http.ResponseWriter, r *http.Request)
 	// Получаем отчёт из БД
 	var htmlContent string
 	query := `SELECT html_content FROM weekly_reports WHERE user_id = $1 AND report_date = $2`
-	err = h.reportService.GetStore().DB.QueryRowContext(r.Context(), query, profileUUID, reportDate).Scan(&htmlContent)
+	err = h.reportService.GetStore().DB.QueryRowContext(r.Context(), query, profileUUID, reportDate.Format("2006-01-02")).Scan(&htmlContent)
 	if err != nil {
 		log.Printf("[ReportHandler] Report not found for date %s: %v", reportDateStr, err)
 		response.NotFound(w, "Report not found for this date")
@@ -205,7 +201,7 @@ func (h *ReportHandler) DownloadReportPDF(w http.ResponseWriter, r *http.Request
 	// Получаем отчёт из БД
 	var htmlContent string
 	query := `SELECT html_content FROM weekly_reports WHERE user_id = $1 AND report_date = $2`
-	err = h.reportService.GetStore().DB.QueryRowContext(r.Context(), query, profileUUID, reportDate).Scan(&htmlContent)
+	err = h.reportService.GetStore().DB.QueryRowContext(r.Context(), query, profileUUID, reportDate.Format("2006-01-02")).Scan(&htmlContent)
 	if err != nil {
 		log.Printf("[ReportHandler] Report not found for date %s: %v", reportDateStr, err)
 		response.NotFound(w, "Report not found for this date")
@@ -255,22 +251,11 @@ func (h *ReportHandler) GenerateReport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var weekStart time.Time
-	if weekStartStr != "" {
-		weekStart, err = time.Parse("2006-01-02", weekStartStr)
-		if err != nil {
-			response.BadRequest(w, "invalid week_start format, use YYYY-MM-DD")
-			return
-		}
-	} else {
-		// По умолчанию - текущая неделя
-		now := time.Now()
-		weekday := now.Weekday()
-		if weekday == time.Sunday {
-			weekday = 7
-		}
-		weekStart = now.AddDate(0, 0, -int(weekday-1))
-		weekStart = time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, now.Location())
+	// Неделя по календарю ребёнка, по умолчанию - текущая
+	weekStart, err := h.reportService.WeekStart(r.Context(), childProfileID, weekStartStr)
+	if err != nil {
+		response.BadRequest(w, "invalid week_start format, use YYYY-MM-DD")
+		return
 	}
 
 	log.Printf("[ReportHandler] Generating report for profile %s, week starting %s", childProfileID, weekStart.Format("2006-01-02"))
@@ -423,13 +408,11 @@ func (h *ReportHandler) SendTestReport(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Генерируем текущий отчёт
-	now := time.Now()
-	weekday := now.Weekday()
-	if weekday == time.Sunday {
-		weekday = 7
+	weekStart, err := h.reportService.WeekStart(r.Context(), childProfileID, "")
+	if err != nil {
+		response.InternalError(w, "Failed to determine report week")
+		return
 	}
-	weekStart := now.AddDate(0, 0, -int(weekday-1))
-	weekStart = time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, now.Location())
 
 	htmlContent, err := h.reportService.GetWeeklyHTML(r.Context(), childProfileID, weekStart)
 	if err != nil {
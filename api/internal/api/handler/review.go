@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/service"
+)
+
+// ReviewServiceInterface интерфейс для ReviewService
+type ReviewServiceInterface interface {
+	GetDueReviews(ctx context.Context, childProfileID string) (*service.DueReviews, error)
+	SubmitReview(ctx context.Context, childProfileID string, reviewID int64, answerImage string) (*service.ReviewResult, error)
+}
+
+// ReviewHandler обрабатывает запросы очереди повторения
+type ReviewHandler struct {
+	service ReviewServiceInterface
+}
+
+// NewReviewHandler создает новый ReviewHandler
+func NewReviewHandler(reviewService ReviewServiceInterface) *ReviewHandler {
+	return &ReviewHandler{service: reviewService}
+}
+
+// ReviewTaskResponse задача на повторение
+type ReviewTaskResponse struct {
+	ID           int64  `json:"id"`
+	Reason       string `json:"reason"` // after_incorrect, after_3_hints
+	Subject      string `json:"subject"`
+	TaskText     string `json:"task_text"`
+	TaskSource   string `json:"task_source"` // analogue, original
+	DueOn        string `json:"due_on"`
+	ReviewsCount int    `json:"reviews_count"`
+	IntervalDays int    `json:"interval_days"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// DueReviewsResponse задачи на повторение на сегодня
+type DueReviewsResponse struct {
+	Items     []ReviewTaskResponse `json:"items"`
+	DueCount  int                  `json:"due_count"`
+	Upcoming  int                  `json:"upcoming_count"`
+	Retired   int                  `json:"retired_count"`
+	NextDueOn string               `json:"next_due_on,omitempty"`
+}
+
+// SubmitReviewRequest решение задачи на повторение
+type SubmitReviewRequest struct {
+	ImageData string `json:"image_data"` // base64 фото ответа
+}
+
+// SubmitReviewResponse результат повторения
+type SubmitReviewResponse struct {
+	Decision     string `json:"decision"`
+	IsCorrect    bool   `json:"is_correct"`
+	Feedback     string `json:"feedback,omitempty"`
+	Rescheduled  bool   `json:"rescheduled"`
+	Retired      bool   `json:"retired"`
+	IntervalDays int    `json:"interval_days"`
+	NextDueOn    string `json:"next_due_on,omitempty"`
+}
+
+// GetDue возвращает задачи, которые пора повторить сегодня
+// GET /reviews/due
+func (h *ReviewHandler) GetDue(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	due, err := h.service.GetDueReviews(r.Context(), childProfileID)
+	if err != nil {
+		log.Printf("[ReviewHandler] Failed to get due reviews for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get due reviews")
+		return
+	}
+
+	resp := DueReviewsResponse{
+		Items:     make([]ReviewTaskResponse, 0, len(due.Items)),
+		DueCount:  due.Due,
+		Upcoming:  due.Upcoming,
+		Retired:   due.Retired,
+		NextDueOn: due.NextDueOn,
+	}
+	for _, item := range due.Items {
+		resp.Items = append(resp.Items, ReviewTaskResponse{
+			ID:           item.ID,
+			Reason:       item.Reason,
+			Subject:      item.Subject,
+			TaskText:     item.TaskText,
+			TaskSource:   item.TaskSource,
+			DueOn:        item.DueOn,
+			ReviewsCount: item.ReviewsCount,
+			IntervalDays: item.IntervalDays,
+			CreatedAt:    item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	response.OK(w, resp)
+}
+
+// Submit проверяет решение задачи на повторение и переносит её
+// POST /reviews/{id}/submit
+func (h *ReviewHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	reviewID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || reviewID <= 0 {
+		response.BadRequest(w, "invalid review id")
+		return
+	}
+
+	var req SubmitReviewRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if err := validation.ValidateBase64Image(req.ImageData); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	result, err := h.service.SubmitReview(r.Context(), childProfileID, reviewID, req.ImageData)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Review not found")
+		case errors.Is(err, domain.ErrReviewNotDue):
+			response.Conflict(w, "Review is not due yet")
+		default:
+			log.Printf("[ReviewHandler] Failed to submit review %d for child %s: %v", reviewID, childProfileID, err)
+			response.InternalError(w, "Failed to submit review")
+		}
+		return
+	}
+
+	response.OK(w, SubmitReviewResponse{
+		Decision:     result.Decision,
+		IsCorrect:    result.IsCorrect,
+		Feedback:     result.Feedback,
+		Rescheduled:  result.Rescheduled,
+		Retired:      result.Retired,
+		IntervalDays: result.IntervalDays,
+		NextDueOn:    result.NextDueOn,
+	})
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/service"
+)
+
+// RewardServiceInterface интерфейс для RewardService
+type RewardServiceInterface interface {
+	GetAudit(ctx context.Context, childProfileID string, days int) (*service.RewardAudit, error)
+}
+
+// RewardHandler обрабатывает запросы аудита наград
+type RewardHandler struct {
+	service RewardServiceInterface
+}
+
+// NewRewardHandler создает новый RewardHandler
+func NewRewardHandler(rewardService RewardServiceInterface) *RewardHandler {
+	return &RewardHandler{service: rewardService}
+}
+
+// RewardCapResponse дневной лимит источника
+type RewardCapResponse struct {
+	Source   string `json:"source"`
+	Currency string `json:"currency"`
+	Daily    int    `json:"daily"`
+}
+
+// RewardPolicyResponse правила начислений
+type RewardPolicyResponse struct {
+	Caps               []RewardCapResponse `json:"caps"`
+	RepeatPercents     []int               `json:"repeat_percents"`
+	AnswerShownSources []string            `json:"answer_shown_sources"`
+	SuspiciousPerDay   int                 `json:"suspicious_per_day"`
+}
+
+// RewardAuditDayResponse урезанные начисления одной причины за день
+type RewardAuditDayResponse struct {
+	Date          string `json:"date"`
+	Reason        string `json:"reason"`
+	Events        int    `json:"events"`
+	WithheldXP    int    `json:"withheld_xp"`
+	WithheldCoins int    `json:"withheld_coins"`
+	Suspicious    bool   `json:"suspicious"`
+}
+
+// RewardAuditEventResponse урезанное начисление
+type RewardAuditEventResponse struct {
+	ID        int64  `json:"id"`
+	Currency  string `json:"currency"`
+	Source    string `json:"source"`
+	SourceID  string `json:"source_id,omitempty"`
+	Requested int    `json:"requested"`
+	Granted   int    `json:"granted"`
+	Reason    string `json:"reason"`
+	Repeats   int    `json:"repeats"`
+	CreatedAt string `json:"created_at"`
+}
+
+// RewardAuditResponse аудит наград ребёнка за период
+type RewardAuditResponse struct {
+	Days       int                        `json:"days"`
+	Suspicious bool                       `json:"suspicious"`
+	Policy     RewardPolicyResponse       `json:"policy"`
+	Summary    []RewardAuditDayResponse   `json:"summary"`
+	Events     []RewardAuditEventResponse `json:"events"`
+}
+
+// GetAudit возвращает родителю урезанные политикой начисления и подозрительные дни
+// GET /reports/{childProfileId}/reward-audit?days=14
+func (h *RewardHandler) GetAudit(w http.ResponseWriter, r *http.Request) {
+	childProfileID := r.PathValue("childProfileId")
+	if err := validation.ValidateUUID(childProfileID); err != nil {
+		response.BadRequest(w, "invalid child_profile_id: "+err.Error())
+		return
+	}
+
+	days := service.RewardAuditDefaultDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 1 || d > service.RewardAuditMaxDays {
+			response.BadRequest(w, "days must be between 1 and "+strconv.Itoa(service.RewardAuditMaxDays))
+			return
+		}
+		days = d
+	}
+
+	audit, err := h.service.GetAudit(r.Context(), childProfileID, days)
+	if err != nil {
+		log.Printf("[RewardHandler] Failed to get reward audit for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get reward audit")
+		return
+	}
+
+	resp := RewardAuditResponse{
+		Days:       audit.Days,
+		Suspicious: audit.Suspicious,
+		Policy: RewardPolicyResponse{
+			Caps:               make([]RewardCapResponse, 0, len(audit.Policy.Caps)),
+			RepeatPercents:     audit.Policy.RepeatPercents,
+			AnswerShownSources: audit.Policy.AnswerShownSources,
+			SuspiciousPerDay:   audit.Policy.SuspiciousPerDay,
+		},
+		Summary: make([]RewardAuditDayResponse, 0, len(audit.Summary)),
+		Events:  make([]RewardAuditEventResponse, 0, len(audit.Recent)),
+	}
+	for _, c := range audit.Policy.Caps {
+		resp.Policy.Caps = append(resp.Policy.Caps, RewardCapResponse{Source: c.Source, Currency: c.Currency, Daily: c.Daily})
+	}
+	for _, d := range audit.Summary {
+		resp.Summary = append(resp.Summary, RewardAuditDayResponse{
+			Date:          d.Date,
+			Reason:        d.Reason,
+			Events:        d.Events,
+			WithheldXP:    d.WithheldXP,
+			WithheldCoins: d.WithheldCoins,
+			Suspicious:    d.Suspicious,
+		})
+	}
+	for _, e := range audit.Recent {
+		resp.Events = append(resp.Events, RewardAuditEventResponse{
+			ID:        e.ID,
+			Currency:  e.Currency,
+			Source:    e.Source,
+			SourceID:  e.SourceID.String,
+			Requested: e.Requested,
+			Granted:   e.Granted,
+			Reason:    e.Reason,
+			Repeats:   e.Repeats,
+			CreatedAt: e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	response.OK(w, resp)
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/service"
+)
+
+// SeasonalServiceInterface интерфейс для SeasonalService
+type SeasonalServiceInterface interface {
+	GetCurrentEvents(ctx context.Context, childProfileID string) (*service.CurrentEvents, error)
+}
+
+// SeasonalHandler обрабатывает запросы сезонных событий
+type SeasonalHandler struct {
+	service SeasonalServiceInterface
+}
+
+// NewSeasonalHandler создает новый SeasonalHandler
+func NewSeasonalHandler(seasonalService SeasonalServiceInterface) *SeasonalHandler {
+	return &SeasonalHandler{service: seasonalService}
+}
+
+// EventAchievementResponse достижение события
+type EventAchievementResponse struct {
+	ID          string `json:"id"`
+	Icon        string `json:"icon"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Progress    int    `json:"progress"`
+	Target      int    `json:"target"`
+	Unlocked    bool   `json:"unlocked"`
+	RewardType  string `json:"reward_type"`
+	RewardName  string `json:"reward_name,omitempty"`
+}
+
+// EventVillainResponse злодей события
+type EventVillainResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ImageURL string `json:"image_url"`
+	Weekdays []int  `json:"weekdays"`
+	Defeated bool   `json:"defeated"`
+}
+
+// EventShopItemResponse предмет события
+type EventShopItemResponse struct {
+	ID       string `json:"id"`
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	Icon     string `json:"icon"`
+	Price    int    `json:"price"`
+	MinLevel int    `json:"min_level"`
+	Owned    bool   `json:"owned"`
+}
+
+// SeasonalEventResponse событие с прогрессом ребёнка
+type SeasonalEventResponse struct {
+	ID                   string                     `json:"id"`
+	Title                string                     `json:"title"`
+	Description          string                     `json:"description"`
+	Icon                 string                     `json:"icon"`
+	ThemeColor           string                     `json:"theme_color"`
+	StartDate            string                     `json:"start_date"`
+	EndDate              string                     `json:"end_date"`
+	DaysLeft             int                        `json:"days_left"`
+	AchievementsUnlocked int                        `json:"achievements_unlocked"`
+	AchievementsTotal    int                        `json:"achievements_total"`
+	Achievements         []EventAchievementResponse `json:"achievements"`
+	Villains             []EventVillainResponse     `json:"villains"`
+	ShopItems            []EventShopItemResponse    `json:"shop_items"`
+}
+
+// CurrentEventsResponse события, идущие сегодня
+type CurrentEventsResponse struct {
+	Date   string                  `json:"date"`
+	Events []SeasonalEventResponse `json:"events"`
+}
+
+// GetCurrent возвращает события, идущие сегодня, с прогрессом ребёнка
+// GET /events/current
+func (h *SeasonalHandler) GetCurrent(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	events, err := h.service.GetCurrentEvents(r.Context(), childProfileID)
+	if err != nil {
+		log.Printf("[SeasonalHandler] Failed to get events for child %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to get events")
+		return
+	}
+
+	response.OK(w, toCurrentEventsResponse(events))
+}
+
+func toCurrentEventsResponse(events *service.CurrentEvents) CurrentEventsResponse {
+	resp := CurrentEventsResponse{
+		Date:   events.Date,
+		Events: make([]SeasonalEventResponse, 0, len(events.Events)),
+	}
+	for _, e := range events.Events {
+		item := SeasonalEventResponse{
+			ID:                   e.ID,
+			Title:                e.Title,
+			Description:          e.Description,
+			Icon:                 e.Icon,
+			ThemeColor:           e.ThemeColor,
+			StartDate:            e.StartDate,
+			EndDate:              e.EndDate,
+			DaysLeft:             e.DaysLeft,
+			AchievementsUnlocked: e.AchievementsUnlocked,
+			AchievementsTotal:    len(e.Achievements),
+			Achievements:         make([]EventAchievementResponse, 0, len(e.Achievements)),
+			Villains:             make([]EventVillainResponse, 0, len(e.Villains)),
+			ShopItems:            make([]EventShopItemResponse, 0, len(e.ShopItems)),
+		}
+		for _, a := range e.Achievements {
+			item.Achievements = append(item.Achievements, EventAchievementResponse{
+				ID:          a.ID,
+				Icon:        a.Icon,
+				Title:       a.Title,
+				Description: a.Description,
+				Progress:    a.Progress,
+				Target:      a.Target,
+				Unlocked:    a.Unlocked,
+				RewardType:  a.RewardType,
+				RewardName:  a.RewardName,
+			})
+		}
+		for _, v := range e.Villains {
+			item.Villains = append(item.Villains, EventVillainResponse{
+				ID:       v.ID,
+				Name:     v.Name,
+				ImageURL: v.ImageURL,
+				Weekdays: v.Weekdays,
+				Defeated: v.Defeated,
+			})
+		}
+		for _, it := range e.ShopItems {
+			item.ShopItems = append(item.ShopItems, EventShopItemResponse{
+				ID:       it.ID,
+				Category: it.Category,
+				Name:     it.Name,
+				Icon:     it.Icon,
+				Price:    it.Price,
+				MinLevel: it.MinLevel,
+				Owned:    it.Owned,
+			})
+		}
+		resp.Events = append(resp.Events, item)
+	}
+	return resp
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/service"
+)
+
+// ShopHandler обрабатывает запросы магазина за монеты
+type ShopHandler struct {
+	service *service.ShopService
+}
+
+// NewShopHandler создает новый ShopHandler
+func NewShopHandler(service *service.ShopService) *ShopHandler {
+	return &ShopHandler{service: service}
+}
+
+// ShopItem предмет магазина
+type ShopItem struct {
+	ID          string          `json:"id"`
+	Category    string          `json:"category"` // avatar, mascot_item, power_up
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Icon        string          `json:"icon"`
+	Price       int             `json:"price"`
+	BasePrice   int             `json:"base_price,omitempty"`       // цена без скидки, если действует скидка
+	Discount    int             `json:"discount_percent,omitempty"` // скидка уровня на усилители
+	MinLevel    int             `json:"min_level"`
+	IsPremium   bool            `json:"is_premium"`
+	IsStackable bool            `json:"is_stackable"`
+	Effect      json.RawMessage `json:"effect,omitempty"`
+	Owned       bool            `json:"owned"`
+	Quantity    int             `json:"quantity,omitempty"`
+	Locked      bool            `json:"locked"`     // уровень ниже требуемого
+	Affordable  bool            `json:"affordable"` // хватает монет
+}
+
+// ShopCatalogResponse каталог магазина
+type ShopCatalogResponse struct {
+	Items        []ShopItem `json:"items"`
+	CoinsBalance int        `json:"coins_balance"`
+	Level        int        `json:"level"`
+	Discount     int        `json:"power_up_discount_percent"` // скидка уровня на усилители
+}
+
+// PurchaseRequest запрос на покупку
+type PurchaseRequest struct {
+	Quantity       int    `json:"quantity,omitempty"`        // для расходуемых усилителей, по умолчанию 1
+	IdempotencyKey string `json:"idempotency_key,omitempty"` // повтор с тем же ключом не списывает монеты
+}
+
+// PurchaseResponse результат покупки
+type PurchaseResponse struct {
+	Item         ShopItem `json:"item"`
+	Quantity     int      `json:"quantity"`
+	TotalPrice   int      `json:"total_price"`
+	CoinsBalance int      `json:"coins_balance"`
+	Replayed     bool     `json:"replayed,omitempty"`
+}
+
+// toShopItemResponse преобразует предмет из service в JSON
+func toShopItemResponse(item service.ShopItem) ShopItem {
+	resp := ShopItem{
+		ID:          item.ID,
+		Category:    item.Category,
+		Name:        item.Name,
+		Description: item.Description,
+		Icon:        item.Icon,
+		Price:       item.Price,
+		MinLevel:    item.MinLevel,
+		IsPremium:   item.IsPremium,
+		IsStackable: item.IsStackable,
+		Effect:      item.Effect,
+		Owned:       item.Owned,
+		Quantity:    item.Quantity,
+		Locked:      item.Locked,
+		Affordable:  item.Affordable,
+	}
+	if item.Discount > 0 {
+		resp.BasePrice = item.BasePrice
+		resp.Discount = item.Discount
+	}
+	return resp
+}
+
+// ListItems возвращает каталог магазина
+// GET /shop/items?category=avatar|mascot_item|power_up
+func (h *ShopHandler) ListItems(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	catalog, err := h.service.GetCatalog(r.Context(), childProfileID, r.URL.Query().Get("category"))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			response.BadRequest(w, "category must be avatar, mascot_item or power_up")
+			return
+		}
+		log.Printf("[ShopHandler] Failed to get catalog for %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to load shop")
+		return
+	}
+
+	resp := ShopCatalogResponse{
+		Items:        make([]ShopItem, 0, len(catalog.Items)),
+		CoinsBalance: catalog.CoinsBalance,
+		Level:        catalog.Level,
+		Discount:     catalog.Discount,
+	}
+	for _, item := range catalog.Items {
+		resp.Items = append(resp.Items, toShopItemResponse(item))
+	}
+
+	response.OK(w, resp)
+}
+
+// Purchase покупает предмет за монеты
+// POST /shop/items/{id}/purchase
+func (h *ShopHandler) Purchase(w http.ResponseWriter, r *http.Request) {
+	itemID := r.PathValue("id")
+	if err := validation.ValidateRequired(itemID, "item_id"); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	var req PurchaseRequest
+	if r.ContentLength != 0 {
+		if err := validation.DecodeJSON(r, &req); err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+	}
+	if err := validation.ValidateMaxLength(req.IdempotencyKey, "idempotency_key", 100); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	purchase, err := h.service.Purchase(r.Context(), childProfileID, itemID, req.Quantity, req.IdempotencyKey)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Item not found")
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.BadRequest(w, "Invalid quantity")
+		case errors.Is(err, domain.ErrInsufficientFunds):
+			response.ErrorWithCode(w, http.StatusPaymentRequired, "Not enough coins", "INSUFFICIENT_FUNDS")
+		case errors.Is(err, domain.ErrLevelTooLow):
+			response.ErrorWithCode(w, http.StatusForbidden, "Level is too low for this item", "LEVEL_TOO_LOW")
+		case errors.Is(err, domain.ErrItemAlreadyOwned):
+			response.ErrorWithCode(w, http.StatusConflict, "Item already owned", "ALREADY_OWNED")
+		case errors.Is(err, domain.ErrConflict):
+			response.ErrorWithCode(w, http.StatusConflict, "Idempotency key was used for another item", "IDEMPOTENCY_KEY_REUSED")
+		default:
+			log.Printf("[ShopHandler] Failed to purchase %s for %s: %v", itemID, childProfileID, err)
+			response.InternalError(w, "Failed to purchase item")
+		}
+		return
+	}
+
+	response.OK(w, PurchaseResponse{
+		Item:         toShopItemResponse(purchase.Item),
+		Quantity:     purchase.Quantity,
+		TotalPrice:   purchase.TotalPrice,
+		CoinsBalance: purchase.CoinsBalance,
+		Replayed:     purchase.Replayed,
+	})
+}
+
+// GetInventory возвращает купленные предметы
+// GET /shop/inventory
+func (h *ShopHandler) GetInventory(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	items, err := h.service.GetInventory(r.Context(), childProfileID)
+	if err != nil {
+		log.Printf("[ShopHandler] Failed to get inventory for %s: %v", childProfileID, err)
+		response.InternalError(w, "Failed to load inventory")
+		return
+	}
+
+	resp := make([]ShopItem, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toShopItemResponse(item))
+	}
+
+	response.OK(w, resp)
+}
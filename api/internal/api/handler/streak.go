@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"child-bot/api/internal/api/middleware"
+	"child-bot/api/internal/api/response"
+	"child-bot/api/internal/api/validation"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/service"
+)
+
+// StreakHandler обрабатывает запросы серии дней
+type StreakHandler struct {
+	service *service.StreakService
+}
+
+// NewStreakHandler создает новый StreakHandler
+func NewStreakHandler(service *service.StreakService) *StreakHandler {
+	return &StreakHandler{service: service}
+}
+
+// StreakDay день в календаре серии
+type StreakDay struct {
+	Date   string `json:"date"`   // YYYY-MM-DD по времени ребёнка
+	Status string `json:"status"` // active, frozen, excused, missed, none
+	Reason string `json:"reason,omitempty"`
+}
+
+// StreakResponse серия и календарь
+type StreakResponse struct {
+	StreakDays        int         `json:"streak_days"`
+	FreezesAvailable  int         `json:"freezes_available"`
+	RecoverableStreak int         `json:"recoverable_streak,omitempty"` // вернётся, если родитель простит пропуск
+	Today             string      `json:"today"`
+	ExcuseMaxDaysBack int         `json:"excuse_max_days_back"`
+	Days              []StreakDay `json:"days"`
+}
+
+// ExcuseDayRequest запрос родителя простить день
+type ExcuseDayRequest struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Reason string `json:"reason,omitempty"`
+}
+
+// ExcuseDayResponse результат прощения дня
+type ExcuseDayResponse struct {
+	Date           string `json:"date"`
+	Status         string `json:"status"` // excused или active, если ребёнок в этот день занимался
+	FreezeRefunded bool   `json:"freeze_refunded"`
+	Recovered      bool   `json:"recovered"`
+	StreakDays     int    `json:"streak_days"`
+}
+
+// Get возвращает серию и календарь дней
+// GET /streak?days=30
+func (h *StreakHandler) Get(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+
+	days := service.DefaultStreakCalendarDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		if _, err := fmt.Sscanf(daysStr, "%d", &days); err != nil || days < 1 || days > service.MaxStreakCalendarDays {
+			response.BadRequest(w, fmt.Sprintf("days must be between 1 and %d", service.MaxStreakCalendarDays))
+			return
+		}
+	}
+
+	info, err := h.service.GetStreak(r.Context(), childProfileID, days)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			response.NotFound(w, "Profile not found")
+			return
+		}
+		log.Printf("[StreakHandler] Failed to get streak: %v", err)
+		response.InternalError(w, "Failed to get streak")
+		return
+	}
+
+	resp := StreakResponse{
+		StreakDays:        info.StreakDays,
+		FreezesAvailable:  info.Freezes,
+		RecoverableStreak: info.Recoverable,
+		Today:             info.Today,
+		ExcuseMaxDaysBack: service.MaxExcuseDaysBack,
+		Days:              make([]StreakDay, 0, len(info.Days)),
+	}
+	for _, d := range info.Days {
+		resp.Days = append(resp.Days, StreakDay{Date: d.Date, Status: d.Status, Reason: d.Reason})
+	}
+
+	response.OK(w, resp)
+}
+
+// ExcuseDay прощает пропущенный день по решению родителя (X-Parent-Session)
+// POST /streak/excused-days
+func (h *StreakHandler) ExcuseDay(w http.ResponseWriter, r *http.Request) {
+	childProfileID := middleware.GetChildProfileID(r.Context())
+	if childProfileID == "" {
+		response.Unauthorized(w, "Missing child_profile_id")
+		return
+	}
+	parentSession := middleware.GetParentSession(r.Context())
+	if parentSession == "" {
+		response.Unauthorized(w, "Missing X-Parent-Session header")
+		return
+	}
+
+	var req ExcuseDayRequest
+	if err := validation.DecodeJSON(r, &req); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	if req.Date == "" {
+		response.BadRequest(w, "date is required")
+		return
+	}
+
+	excuse, err := h.service.ExcuseDay(r.Context(), childProfileID, req.Date, req.Reason, parentSession)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			response.BadRequest(w, fmt.Sprintf("date must be YYYY-MM-DD, not in the future and at most %d days ago", service.MaxExcuseDaysBack))
+		case errors.Is(err, domain.ErrNotFound):
+			response.NotFound(w, "Profile not found")
+		case errors.Is(err, domain.ErrUnauthorized):
+			response.Unauthorized(w, "Parent session expired")
+		case errors.Is(err, domain.ErrForbidden):
+			response.Forbidden(w, "Not a parent of this child")
+		default:
+			log.Printf("[StreakHandler] Failed to excuse day: %v", err)
+			response.InternalError(w, "Failed to excuse day")
+		}
+		return
+	}
+
+	response.OK(w, ExcuseDayResponse{
+		Date:           excuse.Date,
+		Status:         excuse.Status,
+		FreezeRefunded: excuse.FreezeRefunded,
+		Recovered:      excuse.Recovered,
+		StreakDays:     excuse.Streak,
+	})
+}
@@ -26,6 +26,11 @@ type UploadImageResponse struct {
 	Message  string `json:"message"`
 }
 
+// ProcessAttemptRequest запрос на обработку попытки (тело необязательно)
+type ProcessAttemptRequest struct {
+	PowerUpID string `json:"power_up_id,omitempty"` // усилитель для урона злодею при проверке
+}
+
 // ProcessAttemptResponse ответ на обработку попытки
 type ProcessAttemptResponse struct {
 	Status  string `json:"status"` // "processing", "completed", "failed"
@@ -51,6 +56,34 @@ type NextHintResponse struct {
 	Completed   bool   `json:"completed"` // true если все подсказки просмотрены и попытка завершена
 }
 
+// HintFeedbackRequest оценка подсказки ребёнком
+type HintFeedbackRequest struct {
+	Rating  string `json:"rating"` // helpful, unclear или gave_away_answer
+	Comment string `json:"comment,omitempty"`
+}
+
+// RegenerateHintResponse ответ с перегенерированной подсказкой
+type RegenerateHintResponse struct {
+	Hint              string `json:"hint"`
+	HintIndex         int    `json:"hint_index"`
+	TotalHints        int    `json:"total_hints"`
+	RegenerationsLeft int    `json:"regenerations_left"`
+}
+
+// MistakeStepResponse ответ с текущей ошибкой в разборе
+type MistakeStepResponse struct {
+	Label          string   `json:"label"`
+	Title          string   `json:"title"`
+	Fragment       string   `json:"fragment,omitempty"`
+	Explanation    string   `json:"explanation"`
+	CommonMistake  string   `json:"common_mistake,omitempty"`
+	Hints          []string `json:"hints"`
+	MistakeIndex   int      `json:"mistake_index"`
+	TotalMistakes  int      `json:"total_mistakes"`
+	HasMoreMistake bool     `json:"has_more_mistakes"`
+	Completed      bool     `json:"completed"` // true если все ошибки разобраны
+}
+
 // ErrorResponse стандартный формат ошибки
 type ErrorResponse struct {
 	Error string `json:"error"`
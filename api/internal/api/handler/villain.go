@@ -63,10 +63,20 @@ type BattleStats struct {
 }
 
 type DamageEvent struct {
-	ID        string `json:"id"`
-	Damage    int    `json:"damage"`
-	TaskType  string `json:"task_type"` // help, check
-	CreatedAt string `json:"created_at"`
+	ID         string        `json:"id"`
+	Damage     int           `json:"damage"`
+	BaseDamage int           `json:"base_damage"`
+	IsCritical bool          `json:"is_critical"`
+	Bonuses    []DamageBonus `json:"bonuses"`
+	TaskType   string        `json:"task_type"` // help, check
+	CreatedAt  string        `json:"created_at"`
+}
+
+// DamageBonus строка разбивки урона (items, task_type, template, grade, first_try, critical, cap)
+type DamageBonus struct {
+	Code   string `json:"code"`
+	Label  string `json:"label"`
+	Damage int    `json:"damage"`
 }
 
 type VictoryData struct {
@@ -272,11 +282,22 @@ func (h *VillainHandler) GetBattle(w http.ResponseWriter, r *http.Request) {
 	// Конвертируем события урона
 	recentDamage := make([]DamageEvent, 0, len(battleData.RecentDamage))
 	for _, event := range battleData.RecentDamage {
+		bonuses := make([]DamageBonus, 0, len(event.Bonuses))
+		for _, bonus := range event.Bonuses {
+			bonuses = append(bonuses, DamageBonus{
+				Code:   bonus.Code,
+				Label:  bonus.Label,
+				Damage: bonus.Damage,
+			})
+		}
 		recentDamage = append(recentDamage, DamageEvent{
-			ID:        event.ID,
-			Damage:    event.Damage,
-			TaskType:  event.TaskType,
-			CreatedAt: event.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ID:         event.ID,
+			Damage:     event.Damage,
+			BaseDamage: event.BaseDamage,
+			IsCritical: event.IsCritical,
+			Bonuses:    bonuses,
+			TaskType:   event.TaskType,
+			CreatedAt:  event.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		})
 	}
 
@@ -17,16 +17,20 @@ const (
 	ContextKeyChildProfileID contextKey = "childProfileID"
 	// ContextKeyVKUserID ключ для VK user ID в context
 	ContextKeyVKUserID contextKey = "vkUserID"
+	// ContextKeyParentSession ключ для токена родительской сессии в context
+	ContextKeyParentSession contextKey = "parentSession"
 )
 
 // Auth middleware для проверки платформы и профиля
 // Ожидает заголовки:
 // - X-Platform-ID: vk|telegram|max|web
 // - X-Child-Profile-ID: uuid профиля ребенка
+// - X-Parent-Session: токен родительской сессии (необязательный, выдаётся POST /parent/session)
 func Auth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		platformID := strings.TrimSpace(r.Header.Get("X-Platform-ID"))
 		childProfileID := strings.TrimSpace(r.Header.Get("X-Child-Profile-ID"))
+		parentSession := strings.TrimSpace(r.Header.Get("X-Parent-Session"))
 
 		// Для некоторых endpoints (health, onboarding) auth не требуется
 		// Проверим, нужна ли аутентификация для этого пути
@@ -58,6 +62,9 @@ func Auth(next http.Handler) http.Handler {
 		if childProfileID != "" {
 			ctx = context.WithValue(ctx, ContextKeyChildProfileID, childProfileID)
 		}
+		if parentSession != "" {
+			ctx = context.WithValue(ctx, ContextKeyParentSession, parentSession)
+		}
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -87,6 +94,14 @@ func GetVKUserID(ctx context.Context) string {
 	return ""
 }
 
+// GetParentSession извлекает токен родительской сессии из context
+func GetParentSession(ctx context.Context) string {
+	if token, ok := ctx.Value(ContextKeyParentSession).(string); ok {
+		return token
+	}
+	return ""
+}
+
 // requiresAuth проверяет, требует ли путь аутентификации
 func requiresAuth(path string) bool {
 	publicPaths := []string{
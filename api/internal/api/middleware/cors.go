@@ -21,7 +21,7 @@ func CORS(next http.Handler) http.Handler {
 		}
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Platform-ID, X-Child-Profile-ID")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Platform-ID, X-Child-Profile-ID, X-Parent-Session")
 		w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
 
 		// Обработка preflight запросов
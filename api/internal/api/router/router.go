@@ -1,14 +1,18 @@
 package router
 
 import (
+	"context"
 	"net/http"
 	"os"
+	"time"
 
 	"child-bot/api/internal/api/handler"
 	"child-bot/api/internal/api/middleware"
 	"child-bot/api/internal/api/response"
 	"child-bot/api/internal/config"
+	"child-bot/api/internal/family"
 	"child-bot/api/internal/llm"
+	"child-bot/api/internal/mission"
 	"child-bot/api/internal/service"
 	"child-bot/api/internal/store"
 )
@@ -19,6 +23,12 @@ type Dependencies struct {
 	LLMClient  *llm.Client
 	Config     *config.Config
 	DefaultLLM string
+	// Каталоги контента, проверенные при старте. Nil — миссии или семейные квесты выключены.
+	Missions     *mission.Catalog
+	FamilyQuests *family.Catalog
+	// Context живёт, пока работает сервер; фоновые задачи (повтор событий) останавливаются
+	// при его отмене. Nil — фоновые задачи не запускаются.
+	Context context.Context
 }
 
 // New создает новый router с middleware
@@ -34,17 +44,53 @@ func New(deps *Dependencies) http.Handler {
 	attemptService := service.NewAttemptService(deps.Store, deps.LLMClient, deps.DefaultLLM)
 	profileService := service.NewProfileService(deps.Store)
 	villainService := service.NewVillainService(deps.Store)
+	bossService := service.NewBossService(deps.Store)
+	petService := service.NewPetService(deps.Store)
+	knowledgeService := service.NewKnowledgeService(deps.Store)
+	reviewService := service.NewReviewService(deps.Store, deps.LLMClient, deps.DefaultLLM)
+	missionService := service.NewMissionService(deps.Store, deps.Missions)
+	seasonalService := service.NewSeasonalService(deps.Store)
+	familyService := service.NewFamilyService(deps.Store, deps.FamilyQuests)
+	parentService := service.NewParentService(deps.Store, service.NewEmailService())
+	practiceService := service.NewPracticeService(deps.Store)
+	rewardService := service.NewRewardService(deps.Store)
+	levelService := service.NewLevelService(deps.Store)
+	familyRewardService := service.NewFamilyRewardService(deps.Store)
 	achievementService := service.NewAchievementService(deps.Store)
 
+	// Доменные события: сервисы публикуют, движок достижений, питомец, карта знаний,
+	// очередь повторения, миссии дня и семейные квесты подписаны
+	streakService := service.NewStreakService(deps.Store)
+	eventBus := service.NewEventBus(deps.Store)
+	achievementService.Subscribe(eventBus)
+	petService.Subscribe(eventBus)
+	knowledgeService.Subscribe(eventBus)
+	reviewService.Subscribe(eventBus)
+	missionService.Subscribe(eventBus)
+	familyService.Subscribe(eventBus)
+	if deps.Context != nil {
+		go eventBus.RunRetries(deps.Context, time.Minute)
+		go reviewService.RunTaskPreparation(deps.Context, 5*time.Minute)
+	}
+
 	// Устанавливаем зависимости между сервисами (для избежания циклических зависимостей)
 	attemptService.SetProfileService(profileService)
 	attemptService.SetVillainService(villainService)
-	attemptService.SetAchievementService(achievementService)
-	profileService.SetAchievementService(achievementService)
-	villainService.SetAchievementService(achievementService)
+	attemptService.SetBossService(bossService)
+	attemptService.SetEventBus(eventBus)
+	profileService.SetEventBus(eventBus)
+	villainService.SetEventBus(eventBus)
+	bossService.SetEventBus(eventBus)
+	streakService.SetEventBus(eventBus)
 
 	homeService := service.NewHomeService(deps.Store, attemptService, profileService, villainService)
+	homeService.SetPetService(petService)
+	homeService.SetMissionService(missionService)
+	homeService.SetSeasonalService(seasonalService)
 	reportService := service.NewReportService(deps.Store)
+	dialogService := service.NewDialogService(deps.Store, deps.LLMClient, deps.DefaultLLM)
+	shopService := service.NewShopService(deps.Store)
+	leaderboardService := service.NewLeaderboardService(deps.Store)
 
 	// Инициализируем VK Pay service
 	vkPayConfig := service.VKPayConfig{
@@ -60,9 +106,21 @@ func New(deps *Dependencies) http.Handler {
 	profileHandler := handler.NewProfileHandler(profileService)
 	achievementHandler := handler.NewAchievementHandler(deps.Store)
 	villainHandler := handler.NewVillainHandler(villainService)
+	bossHandler := handler.NewBossHandler(bossService)
+	petHandler := handler.NewPetHandler(petService)
+	knowledgeHandler := handler.NewKnowledgeHandler(knowledgeService)
+	reviewHandler := handler.NewReviewHandler(reviewService)
+	missionHandler := handler.NewMissionHandler(missionService)
+	seasonalHandler := handler.NewSeasonalHandler(seasonalService)
+	familyHandler := handler.NewFamilyHandler(familyService)
+	parentHandler := handler.NewParentHandler(parentService)
+	practiceHandler := handler.NewPracticeHandler(practiceService)
+	rewardHandler := handler.NewRewardHandler(rewardService)
+	levelHandler := handler.NewLevelHandler(levelService)
+	familyRewardHandler := handler.NewFamilyRewardHandler(familyRewardService)
 	subscriptionHandler := handler.NewSubscriptionHandler(deps.Store, vkPayService)
-	referralHandler := handler.NewReferralHandler(deps.Store, deps.Config.AppURL)
-	avatarHandler := handler.NewAvatarHandler()
+	referralHandler := handler.NewReferralHandler(deps.Store, leaderboardService, deps.Config.AppURL)
+	avatarHandler := handler.NewAvatarHandler(shopService)
 	consentHandler := handler.NewConsentHandler(deps.Store)
 	analyticsHandler := handler.NewAnalyticsHandler()
 	legalHandler := handler.NewLegalHandler(deps.Store)
@@ -70,6 +128,9 @@ func New(deps *Dependencies) http.Handler {
 	reportHandler := handler.NewReportHandler(reportService)
 	csrfHandler := handler.NewCSRFHandler()
 	vkPayWebhookHandler := handler.NewVKPayWebhookHandler(vkPayService)
+	dialogHandler := handler.NewDialogHandler(dialogService)
+	shopHandler := handler.NewShopHandler(shopService)
+	streakHandler := handler.NewStreakHandler(streakService)
 
 	// Регистрация routes
 	registerAttemptRoutes(mux, attemptHandler)
@@ -77,6 +138,18 @@ func New(deps *Dependencies) http.Handler {
 	registerProfileRoutes(mux, profileHandler)
 	registerAchievementRoutes(mux, achievementHandler)
 	registerVillainRoutes(mux, villainHandler)
+	registerBossRoutes(mux, bossHandler)
+	registerPetRoutes(mux, petHandler)
+	registerKnowledgeRoutes(mux, knowledgeHandler)
+	registerReviewRoutes(mux, reviewHandler)
+	registerMissionRoutes(mux, missionHandler)
+	registerSeasonalRoutes(mux, seasonalHandler)
+	registerFamilyRoutes(mux, familyHandler)
+	registerParentRoutes(mux, parentHandler)
+	registerPracticeRoutes(mux, practiceHandler)
+	registerRewardRoutes(mux, rewardHandler)
+	registerLevelRoutes(mux, levelHandler)
+	registerFamilyRewardRoutes(mux, familyRewardHandler)
 	registerSubscriptionRoutes(mux, subscriptionHandler)
 	registerReferralRoutes(mux, referralHandler)
 	registerAvatarRoutes(mux, avatarHandler)
@@ -87,6 +160,9 @@ func New(deps *Dependencies) http.Handler {
 	registerReportRoutes(mux, reportHandler)
 	registerCSRFRoutes(mux, csrfHandler)
 	registerWebhookRoutes(mux, vkPayWebhookHandler)
+	registerDialogRoutes(mux, dialogHandler)
+	registerShopRoutes(mux, shopHandler)
+	registerStreakRoutes(mux, streakHandler)
 
 	// Применяем middleware в правильном порядке:
 	// HTTPSRedirect -> SecurityHeaders -> Recovery -> Logging -> RateLimit -> CORS -> VKAuth -> Auth -> CSRFProtection
@@ -112,9 +188,20 @@ func registerAttemptRoutes(mux *http.ServeMux, h *handler.AttemptHandler) {
 	mux.HandleFunc("POST /attempts/{id}/process", h.Process)
 	mux.HandleFunc("GET /attempts/{id}/result", h.GetResult)
 	mux.HandleFunc("POST /attempts/{id}/next-hint", h.NextHint)
+	mux.HandleFunc("GET /attempts/{id}/page", h.GetPage)
+	mux.HandleFunc("POST /attempts/{id}/hints/{index}/rating", h.RateHint)
+	mux.HandleFunc("POST /attempts/{id}/hints/{index}/regenerate", h.RegenerateHint)
+	mux.HandleFunc("POST /attempts/{id}/explain-mistakes", h.ExplainMistakes)
+	mux.HandleFunc("POST /attempts/{id}/next-mistake", h.NextMistake)
 	mux.HandleFunc("DELETE /attempts/{id}", h.Delete)
 }
 
+// registerDialogRoutes регистрирует routes для диалога по попытке
+func registerDialogRoutes(mux *http.ServeMux, h *handler.DialogHandler) {
+	mux.HandleFunc("GET /attempts/{id}/messages", h.ListMessages)
+	mux.HandleFunc("POST /attempts/{id}/messages", h.SendMessage)
+}
+
 // registerHomeRoutes регистрирует routes для home
 func registerHomeRoutes(mux *http.ServeMux, h *handler.HomeHandler) {
 	mux.HandleFunc("GET /home/{childProfileId}", h.GetHomeData)
@@ -127,6 +214,7 @@ func registerProfileRoutes(mux *http.ServeMux, h *handler.ProfileHandler) {
 	mux.HandleFunc("GET /profile", h.Get)
 	mux.HandleFunc("PUT /profile", h.Update)
 	mux.HandleFunc("GET /profile/history", h.GetHistory)
+	mux.HandleFunc("GET /profile/transactions", h.GetTransactions)
 	mux.HandleFunc("GET /profile/stats", h.GetStats)
 }
 
@@ -151,6 +239,87 @@ func registerVillainRoutes(mux *http.ServeMux, h *handler.VillainHandler) {
 	mux.HandleFunc("POST /villains/{id}/damage", h.DealDamage)
 }
 
+// registerBossRoutes регистрирует routes для недельного босса
+func registerBossRoutes(mux *http.ServeMux, h *handler.BossHandler) {
+	mux.HandleFunc("GET /boss", h.GetWeekly)
+}
+
+// registerPetRoutes регистрирует routes для питомца
+func registerPetRoutes(mux *http.ServeMux, h *handler.PetHandler) {
+	mux.HandleFunc("GET /pet", h.Get)
+}
+
+// registerKnowledgeRoutes регистрирует routes для карты знаний
+func registerKnowledgeRoutes(mux *http.ServeMux, h *handler.KnowledgeHandler) {
+	mux.HandleFunc("GET /knowledge-map", h.Get)
+	mux.HandleFunc("GET /reports/{childProfileId}/knowledge-map", h.GetForParent)
+}
+
+// registerReviewRoutes регистрирует routes для очереди повторения
+func registerReviewRoutes(mux *http.ServeMux, h *handler.ReviewHandler) {
+	mux.HandleFunc("GET /reviews/due", h.GetDue)
+	mux.HandleFunc("POST /reviews/{id}/submit", h.Submit)
+}
+
+// registerMissionRoutes регистрирует routes для миссий дня
+func registerMissionRoutes(mux *http.ServeMux, h *handler.MissionHandler) {
+	mux.HandleFunc("GET /missions/today", h.GetToday)
+}
+
+// registerSeasonalRoutes регистрирует routes для сезонных событий
+func registerSeasonalRoutes(mux *http.ServeMux, h *handler.SeasonalHandler) {
+	mux.HandleFunc("GET /events/current", h.GetCurrent)
+}
+
+// registerFamilyRoutes регистрирует routes для семейных квестов
+func registerFamilyRoutes(mux *http.ServeMux, h *handler.FamilyHandler) {
+	mux.HandleFunc("GET /family/quests", h.GetQuests)
+	mux.HandleFunc("POST /family/quests/{templateId}/start", h.StartQuest)
+	mux.HandleFunc("POST /family/quests/{id}/confirm", h.ConfirmQuest)
+	mux.HandleFunc("GET /family/notifications", h.GetNotifications)
+	mux.HandleFunc("POST /family/notifications/{id}/read", h.MarkNotificationRead)
+}
+
+// registerParentRoutes регистрирует routes для родительской сессии
+func registerParentRoutes(mux *http.ServeMux, h *handler.ParentHandler) {
+	mux.HandleFunc("GET /parent/pin", h.GetPINStatus)
+	mux.HandleFunc("POST /parent/pin", h.SetPIN)
+	mux.HandleFunc("POST /parent/pin/code", h.RequestPINCode)
+	mux.HandleFunc("POST /parent/session", h.StartSession)
+	mux.HandleFunc("DELETE /parent/session", h.EndSession)
+}
+
+// registerPracticeRoutes регистрирует routes для разминок
+func registerPracticeRoutes(mux *http.ServeMux, h *handler.PracticeHandler) {
+	mux.HandleFunc("GET /practice/drills", h.GetDrills)
+	mux.HandleFunc("POST /practice/sessions", h.StartSession)
+	mux.HandleFunc("GET /practice/sessions/{id}", h.GetSession)
+	mux.HandleFunc("POST /practice/sessions/{id}/answer", h.Answer)
+	mux.HandleFunc("POST /practice/sessions/{id}/finish", h.Finish)
+}
+
+// registerRewardRoutes регистрирует routes для аудита наград
+func registerRewardRoutes(mux *http.ServeMux, h *handler.RewardHandler) {
+	mux.HandleFunc("GET /reports/{childProfileId}/reward-audit", h.GetAudit)
+}
+
+// registerLevelRoutes регистрирует routes для таблицы уровней
+func registerLevelRoutes(mux *http.ServeMux, h *handler.LevelHandler) {
+	mux.HandleFunc("GET /levels", h.GetLevels)
+	mux.HandleFunc("POST /levels/{level}/seen", h.MarkSeen)
+}
+
+// registerFamilyRewardRoutes регистрирует routes для наград за монеты
+func registerFamilyRewardRoutes(mux *http.ServeMux, h *handler.FamilyRewardHandler) {
+	mux.HandleFunc("GET /family/rewards", h.GetRewards)
+	mux.HandleFunc("POST /family/rewards", h.CreateReward)
+	mux.HandleFunc("PATCH /family/rewards/{id}", h.UpdateReward)
+	mux.HandleFunc("DELETE /family/rewards/{id}", h.ArchiveReward)
+	mux.HandleFunc("POST /family/rewards/{id}/redeem", h.Redeem)
+	mux.HandleFunc("POST /family/redemptions/{id}/approve", h.Approve)
+	mux.HandleFunc("POST /family/redemptions/{id}/decline", h.Decline)
+}
+
 // registerSubscriptionRoutes регистрирует routes для subscription
 func registerSubscriptionRoutes(mux *http.ServeMux, h *handler.SubscriptionHandler) {
 	mux.HandleFunc("GET /subscription/status", h.GetStatus)
@@ -173,6 +342,19 @@ func registerAvatarRoutes(mux *http.ServeMux, h *handler.AvatarHandler) {
 	mux.HandleFunc("GET /avatars", h.GetAll)
 }
 
+// registerShopRoutes регистрирует routes для магазина
+func registerShopRoutes(mux *http.ServeMux, h *handler.ShopHandler) {
+	mux.HandleFunc("GET /shop/items", h.ListItems)
+	mux.HandleFunc("POST /shop/items/{id}/purchase", h.Purchase)
+	mux.HandleFunc("GET /shop/inventory", h.GetInventory)
+}
+
+// registerStreakRoutes регистрирует routes для серии дней
+func registerStreakRoutes(mux *http.ServeMux, h *handler.StreakHandler) {
+	mux.HandleFunc("GET /streak", h.Get)
+	mux.HandleFunc("POST /streak/excused-days", h.ExcuseDay)
+}
+
 // registerConsentRoutes регистрирует routes для consent
 func registerConsentRoutes(mux *http.ServeMux, h *handler.ConsentHandler) {
 	mux.HandleFunc("POST /consent", h.SaveConsent)
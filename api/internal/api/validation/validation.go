@@ -89,7 +89,7 @@ func ValidateEnum(value, fieldName string, validValues []string) error {
 
 // ValidateAttemptType проверяет тип попытки (help или check)
 func ValidateAttemptType(attemptType string) error {
-	return ValidateEnum(attemptType, "attempt_type", []string{"help", "check"})
+	return ValidateEnum(attemptType, "attempt_type", []string{"help", "check", "page"})
 }
 
 // ValidatePlatformID проверяет валидность platformID
@@ -0,0 +1,182 @@
+// Package boss — правила недельного босса: битва идёт несколько дней и делится на фазы,
+// каждая фаза требует своего предмета или типа задач. Если ребёнок пропускает день,
+// босс восстанавливает HP текущей фазы. Здесь только чистая логика без БД:
+// даты — строки YYYY-MM-DD по календарю ребёнка.
+package boss
+
+import (
+	"fmt"
+	"time"
+)
+
+// dateLayout формат календарной даты
+const dateLayout = "2006-01-02"
+
+// epochMonday понедельник, от которого считаются недели ротации боссов
+var epochMonday = time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// Phase фаза битвы с боссом
+type Phase struct {
+	Number           int
+	Title            string
+	HP               int
+	RequiredSubject  string // math, ru, en, world, literature; пусто — любой предмет
+	RequiredTaskType string // ped_keys.task_type (word_problems...); пусто — любой тип
+}
+
+// Matches проверяет, что задача подходит для фазы: предмет совпадает
+// и хотя бы одно задание нужного типа
+func (p Phase) Matches(subject string, taskTypes []string) bool {
+	if p.RequiredSubject != "" && p.RequiredSubject != subject {
+		return false
+	}
+	if p.RequiredTaskType == "" {
+		return true
+	}
+	for _, taskType := range taskTypes {
+		if taskType == p.RequiredTaskType {
+			return true
+		}
+	}
+	return false
+}
+
+// Progress состояние битвы: текущая фаза и её оставшееся HP
+type Progress struct {
+	Phase   int // номер текущей фазы (с 1)
+	PhaseHP int
+}
+
+// Hit результат удара по текущей фазе
+type Hit struct {
+	Progress
+	Damage       int  // сколько урона реально ушло в фазу (без перебора)
+	PhaseCleared bool // текущая фаза пройдена
+	Defeated     bool // пройдена последняя фаза
+	ClearedPhase int  // номер пройденной фазы (если PhaseCleared)
+}
+
+// Apply наносит урон текущей фазе. Лишний урон не переносится: следующая фаза
+// требует другого предмета и начинается с полного HP.
+func Apply(phases []Phase, p Progress, damage int) (Hit, error) {
+	if p.Phase < 1 || p.Phase > len(phases) {
+		return Hit{}, fmt.Errorf("boss phase %d out of range 1..%d", p.Phase, len(phases))
+	}
+	hit := Hit{Progress: p}
+	if damage <= 0 {
+		return hit, nil
+	}
+
+	if damage < p.PhaseHP {
+		hit.PhaseHP = p.PhaseHP - damage
+		hit.Damage = damage
+		return hit, nil
+	}
+
+	hit.Damage = p.PhaseHP
+	hit.PhaseCleared = true
+	hit.ClearedPhase = p.Phase
+	if p.Phase == len(phases) {
+		hit.Defeated = true
+		hit.PhaseHP = 0
+		return hit, nil
+	}
+	hit.Phase = p.Phase + 1
+	hit.PhaseHP = phases[p.Phase].HP
+	return hit, nil
+}
+
+// MissedDays сколько дней ребёнок пропустил и за сколько из них босс ещё не восстановился.
+// Пропущены дни строго между последним днём с уроном (или началом битвы) и сегодняшним днём;
+// дни до regenThrough включительно уже учтены.
+func MissedDays(lastActive, regenThrough, today string) (int, error) {
+	from := lastActive
+	if regenThrough > from {
+		from = regenThrough
+	}
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return 0, fmt.Errorf("parse boss day %q: %w", from, err)
+	}
+	end, err := time.Parse(dateLayout, today)
+	if err != nil {
+		return 0, fmt.Errorf("parse boss day %q: %w", today, err)
+	}
+	days := int(end.Sub(start)/(24*time.Hour)) - 1
+	if days < 0 {
+		return 0, nil
+	}
+	return days, nil
+}
+
+// Regenerate восстанавливает HP текущей фазы за пропущенные дни, не выше максимума фазы.
+// Возвращает новое HP и сколько реально восстановлено.
+func Regenerate(phaseHP, phaseMaxHP, missedDays, perDay int) (int, int) {
+	if missedDays <= 0 || perDay <= 0 || phaseHP >= phaseMaxHP {
+		return phaseHP, 0
+	}
+	healed := missedDays * perDay
+	if phaseHP+healed > phaseMaxHP {
+		healed = phaseMaxHP - phaseHP
+	}
+	return phaseHP + healed, healed
+}
+
+// WeekIndex номер недели с понедельником weekStart (YYYY-MM-DD) от 5 января 1970 —
+// по нему боссы сменяют друг друга по кругу
+func WeekIndex(weekStart string) (int, error) {
+	day, err := time.Parse(dateLayout, weekStart)
+	if err != nil {
+		return 0, fmt.Errorf("parse week start %q: %w", weekStart, err)
+	}
+	return int(day.Sub(epochMonday) / (7 * 24 * time.Hour)), nil
+}
+
+// EndsOn последний день битвы, которая начинается weekStart и длится durationDays дней
+func EndsOn(weekStart string, durationDays int) (string, error) {
+	day, err := time.Parse(dateLayout, weekStart)
+	if err != nil {
+		return "", fmt.Errorf("parse week start %q: %w", weekStart, err)
+	}
+	if durationDays < 1 {
+		durationDays = 1
+	}
+	return day.AddDate(0, 0, durationDays-1).Format(dateLayout), nil
+}
+
+// DaysLeft сколько дней битвы осталось, включая сегодняшний; 0 — битва закончилась
+func DaysLeft(today, endsOn string) int {
+	start, err := time.Parse(dateLayout, today)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse(dateLayout, endsOn)
+	if err != nil {
+		return 0
+	}
+	days := int(end.Sub(start)/(24*time.Hour)) + 1
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// TotalHP суммарное HP всех фаз
+func TotalHP(phases []Phase) int {
+	total := 0
+	for _, p := range phases {
+		total += p.HP
+	}
+	return total
+}
+
+// RemainingHP сколько HP осталось у босса: текущая фаза плюс все следующие
+func RemainingHP(phases []Phase, p Progress) int {
+	remaining := p.PhaseHP
+	for _, phase := range phases {
+		if phase.Number > p.Phase {
+			remaining += phase.HP
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,169 @@
+package boss
+
+import "testing"
+
+var testPhases = []Phase{
+	{Number: 1, Title: "Щит из чисел", HP: 100, RequiredSubject: "math"},
+	{Number: 2, Title: "Путаница букв", HP: 80, RequiredSubject: "ru"},
+	{Number: 3, Title: "Сердце хаоса", HP: 60, RequiredSubject: "math", RequiredTaskType: "word_problems"},
+}
+
+func TestPhaseMatches(t *testing.T) {
+	tests := []struct {
+		phase     Phase
+		subject   string
+		taskTypes []string
+		want      bool
+	}{
+		{testPhases[0], "math", nil, true},
+		{testPhases[0], "ru", nil, false},
+		{testPhases[2], "math", []string{"arithmetic_fluency"}, false},
+		{testPhases[2], "math", []string{"arithmetic_fluency", "word_problems"}, true},
+		{testPhases[2], "ru", []string{"word_problems"}, false},
+		{Phase{Number: 1, HP: 10}, "other", nil, true},
+	}
+	for _, tt := range tests {
+		if got := tt.phase.Matches(tt.subject, tt.taskTypes); got != tt.want {
+			t.Errorf("phase %d Matches(%q, %v) = %v, want %v", tt.phase.Number, tt.subject, tt.taskTypes, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  Progress
+		damage int
+		want   Hit
+	}{
+		{
+			name:   "partial damage",
+			start:  Progress{Phase: 1, PhaseHP: 100},
+			damage: 30,
+			want:   Hit{Progress: Progress{Phase: 1, PhaseHP: 70}, Damage: 30},
+		},
+		{
+			name:   "phase cleared, overkill is not carried over",
+			start:  Progress{Phase: 1, PhaseHP: 20},
+			damage: 50,
+			want:   Hit{Progress: Progress{Phase: 2, PhaseHP: 80}, Damage: 20, PhaseCleared: true, ClearedPhase: 1},
+		},
+		{
+			name:   "last phase defeats boss",
+			start:  Progress{Phase: 3, PhaseHP: 60},
+			damage: 60,
+			want:   Hit{Progress: Progress{Phase: 3, PhaseHP: 0}, Damage: 60, PhaseCleared: true, ClearedPhase: 3, Defeated: true},
+		},
+		{
+			name:   "zero damage",
+			start:  Progress{Phase: 2, PhaseHP: 40},
+			damage: 0,
+			want:   Hit{Progress: Progress{Phase: 2, PhaseHP: 40}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(testPhases, tt.start, tt.damage)
+			if err != nil {
+				t.Fatalf("Apply error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Apply(testPhases, Progress{Phase: 4, PhaseHP: 1}, 1); err == nil {
+		t.Error("Apply with phase out of range: error = nil")
+	}
+}
+
+func TestMissedDays(t *testing.T) {
+	tests := []struct {
+		lastActive, regenThrough, today string
+		want                            int
+	}{
+		{"2026-03-02", "", "2026-03-02", 0},           // тот же день
+		{"2026-03-02", "", "2026-03-03", 0},           // занимался вчера
+		{"2026-03-02", "", "2026-03-05", 2},           // пропустил 3 и 4 марта
+		{"2026-03-02", "2026-03-04", "2026-03-05", 0}, // 3 и 4 марта уже учтены
+		{"2026-03-02", "2026-03-03", "2026-03-06", 2}, // учтён только 3 марта
+		{"2026-03-05", "2026-03-03", "2026-03-06", 0}, // после учёта снова занимался
+		{"2026-03-31", "", "2026-04-02", 1},           // через границу месяца
+	}
+	for _, tt := range tests {
+		got, err := MissedDays(tt.lastActive, tt.regenThrough, tt.today)
+		if err != nil {
+			t.Fatalf("MissedDays error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("MissedDays(%s, %q, %s) = %d, want %d", tt.lastActive, tt.regenThrough, tt.today, got, tt.want)
+		}
+	}
+
+	if _, err := MissedDays("bad", "", "2026-03-02"); err == nil {
+		t.Error("MissedDays with bad date: error = nil")
+	}
+}
+
+func TestRegenerate(t *testing.T) {
+	tests := []struct {
+		hp, max, days, perDay int
+		wantHP, wantHealed    int
+	}{
+		{50, 100, 2, 15, 80, 30},
+		{90, 100, 2, 15, 100, 10},
+		{100, 100, 3, 15, 100, 0},
+		{50, 100, 0, 15, 50, 0},
+		{50, 100, 2, 0, 50, 0},
+	}
+	for _, tt := range tests {
+		hp, healed := Regenerate(tt.hp, tt.max, tt.days, tt.perDay)
+		if hp != tt.wantHP || healed != tt.wantHealed {
+			t.Errorf("Regenerate(%d, %d, %d, %d) = (%d, %d), want (%d, %d)",
+				tt.hp, tt.max, tt.days, tt.perDay, hp, healed, tt.wantHP, tt.wantHealed)
+		}
+	}
+}
+
+func TestWeekIndexAndEndsOn(t *testing.T) {
+	a, err := WeekIndex("2026-03-02")
+	if err != nil {
+		t.Fatalf("WeekIndex error = %v", err)
+	}
+	b, _ := WeekIndex("2026-03-09")
+	if b != a+1 {
+		t.Errorf("WeekIndex of next Monday = %d, want %d", b, a+1)
+	}
+
+	end, err := EndsOn("2026-03-02", 7)
+	if err != nil || end != "2026-03-08" {
+		t.Errorf("EndsOn(2026-03-02, 7) = %q, %v, want 2026-03-08", end, err)
+	}
+	if end, _ := EndsOn("2026-03-02", 0); end != "2026-03-02" {
+		t.Errorf("EndsOn(2026-03-02, 0) = %q, want 2026-03-02", end)
+	}
+
+	for _, tt := range []struct {
+		today string
+		want  int
+	}{
+		{"2026-03-02", 7},
+		{"2026-03-08", 1},
+		{"2026-03-09", 0},
+		{"2026-03-20", 0},
+	} {
+		if got := DaysLeft(tt.today, "2026-03-08"); got != tt.want {
+			t.Errorf("DaysLeft(%s, 2026-03-08) = %d, want %d", tt.today, got, tt.want)
+		}
+	}
+}
+
+func TestRemainingHP(t *testing.T) {
+	if got := TotalHP(testPhases); got != 240 {
+		t.Errorf("TotalHP = %d, want 240", got)
+	}
+	if got := RemainingHP(testPhases, Progress{Phase: 2, PhaseHP: 30}); got != 90 {
+		t.Errorf("RemainingHP = %d, want 90", got)
+	}
+}
@@ -0,0 +1,95 @@
+// Package calendar считает дни и недели по местному календарю ребёнка.
+// Сутки начинаются в полночь часового пояса профиля, а не UTC и не сервера,
+// поэтому серия дней, злодей дня и недельные отчёты совпадают с тем, что ребёнок
+// видит на своих часах, в том числе в дни перехода на летнее время.
+package calendar
+
+import (
+	"time"
+	_ "time/tzdata" // база часовых поясов внутри бинарника: в минимальных образах её нет
+)
+
+// DefaultTimezone часовой пояс по умолчанию (основная аудитория — Россия)
+const DefaultTimezone = "Europe/Moscow"
+
+// maxTimezoneLength ограничение колонки child_profiles.timezone
+const maxTimezoneLength = 64
+
+// dateLayout формат календарной даты
+const dateLayout = "2006-01-02"
+
+// IsValidTimezone проверяет, что name — IANA-идентификатор часового пояса (Europe/Moscow, Asia/Vladivostok).
+// Пустая строка и "Local" не принимаются: пояс сервера не должен попадать в профиль.
+func IsValidTimezone(name string) bool {
+	if name == "" || name == "Local" || len(name) > maxTimezoneLength {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// Location возвращает часовой пояс по имени, для пустого или неизвестного — DefaultTimezone
+func Location(name string) *time.Location {
+	if IsValidTimezone(name) {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	if loc, err := time.LoadLocation(DefaultTimezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// StartOfDay местная полночь дня, в который попадает t
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// DaysBetween число календарных дней от from до to по местному времени.
+// 23:59 и 00:01 следующего дня — это 1 день, хотя прошло две минуты;
+// сутки перехода на летнее время (23 или 25 часов) — тоже 1 день.
+func DaysBetween(from, to time.Time, loc *time.Location) int {
+	fy, fm, fd := from.In(loc).Date()
+	ty, tm, td := to.In(loc).Date()
+	a := time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)
+	b := time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a) / (24 * time.Hour))
+}
+
+// SameDay проверяет, что a и b — один и тот же местный день
+func SameDay(a, b time.Time, loc *time.Location) bool {
+	return DaysBetween(a, b, loc) == 0
+}
+
+// ISOWeekday день недели по местному времени: 1 — понедельник, 7 — воскресенье
+func ISOWeekday(t time.Time, loc *time.Location) int {
+	wd := int(t.In(loc).Weekday())
+	if wd == 0 {
+		return 7
+	}
+	return wd
+}
+
+// WeekStart местная полночь понедельника недели, в которую попадает t
+func WeekStart(t time.Time, loc *time.Location) time.Time {
+	day := StartOfDay(t, loc)
+	return day.AddDate(0, 0, 1-ISOWeekday(day, loc))
+}
+
+// MonthStart местная полночь первого числа месяца, в который попадает t
+func MonthStart(t time.Time, loc *time.Location) time.Time {
+	y, m, _ := t.In(loc).Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, loc)
+}
+
+// DateKey местная дата в формате YYYY-MM-DD (для DATE-колонок и ключей идемпотентности)
+func DateKey(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(dateLayout)
+}
+
+// ParseDate разбирает YYYY-MM-DD как местную полночь
+func ParseDate(s string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(dateLayout, s, loc)
+}
@@ -0,0 +1,225 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q) error = %v", name, err)
+	}
+	return loc
+}
+
+func TestIsValidTimezone(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Europe/Moscow", true},
+		{"Asia/Vladivostok", true},
+		{"America/New_York", true},
+		{"UTC", true},
+		{"", false},
+		{"Local", false},
+		{"Mars/Olympus", false},
+		{"Europe/Moscow; DROP TABLE", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidTimezone(tt.name); got != tt.want {
+			t.Errorf("IsValidTimezone(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLocationFallback(t *testing.T) {
+	for _, name := range []string{"", "Local", "Mars/Olympus"} {
+		if got := Location(name).String(); got != DefaultTimezone {
+			t.Errorf("Location(%q) = %s, want %s", name, got, DefaultTimezone)
+		}
+	}
+	if got := Location("Asia/Vladivostok").String(); got != "Asia/Vladivostok" {
+		t.Errorf("Location(Asia/Vladivostok) = %s", got)
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	moscow := mustLoad(t, "Europe/Moscow")
+	vladivostok := mustLoad(t, "Asia/Vladivostok")
+	newYork := mustLoad(t, "America/New_York")
+
+	tests := []struct {
+		name     string
+		loc      *time.Location
+		from, to time.Time
+		want     int
+	}{
+		{
+			name: "two minutes across local midnight",
+			loc:  moscow,
+			from: time.Date(2026, 10, 17, 23, 59, 0, 0, moscow),
+			to:   time.Date(2026, 10, 18, 0, 1, 0, 0, moscow),
+			want: 1,
+		},
+		{
+			name: "across UTC midnight but same local day",
+			loc:  moscow,
+			from: time.Date(2026, 10, 17, 23, 30, 0, 0, time.UTC), // 02:30 MSK 18.10
+			to:   time.Date(2026, 10, 18, 0, 30, 0, 0, time.UTC),  // 03:30 MSK 18.10
+			want: 0,
+		},
+		{
+			name: "morning and noon in Vladivostok are the same day",
+			loc:  vladivostok,
+			from: time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC), // 08:00 18.10
+			to:   time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC),  // 12:00 18.10
+			want: 0,
+		},
+		{
+			name: "evening in Vladivostok is the next day for UTC-morning",
+			loc:  vladivostok,
+			from: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),   // 19:00 18.10
+			to:   time.Date(2026, 10, 18, 14, 30, 0, 0, time.UTC), // 00:30 19.10
+			want: 1,
+		},
+		{
+			name: "spring forward day has 23 hours",
+			loc:  newYork,
+			from: time.Date(2026, 3, 7, 23, 30, 0, 0, newYork),
+			to:   time.Date(2026, 3, 8, 23, 30, 0, 0, newYork),
+			want: 1,
+		},
+		{
+			name: "fall back day has 25 hours",
+			loc:  newYork,
+			from: time.Date(2026, 10, 31, 0, 30, 0, 0, newYork),
+			to:   time.Date(2026, 11, 1, 23, 30, 0, 0, newYork),
+			want: 1,
+		},
+		{
+			name: "missed a day",
+			loc:  moscow,
+			from: time.Date(2026, 10, 16, 20, 0, 0, 0, moscow),
+			to:   time.Date(2026, 10, 18, 8, 0, 0, 0, moscow),
+			want: 2,
+		},
+		{
+			name: "clock went backwards",
+			loc:  moscow,
+			from: time.Date(2026, 10, 18, 8, 0, 0, 0, moscow),
+			to:   time.Date(2026, 10, 17, 8, 0, 0, 0, moscow),
+			want: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DaysBetween(tt.from, tt.to, tt.loc); got != tt.want {
+				t.Errorf("DaysBetween(%s, %s) = %d, want %d", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartOfDayDST(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+
+	// 8 марта 2026 в 02:00 часы переводятся на 03:00: полночь существует, сутки длятся 23 часа
+	start := StartOfDay(time.Date(2026, 3, 8, 15, 0, 0, 0, newYork), newYork)
+	next := StartOfDay(time.Date(2026, 3, 9, 15, 0, 0, 0, newYork), newYork)
+	if start.Hour() != 0 || next.Hour() != 0 {
+		t.Fatalf("StartOfDay hours = %d, %d, want 0", start.Hour(), next.Hour())
+	}
+	if got := next.Sub(start); got != 23*time.Hour {
+		t.Errorf("spring forward day length = %s, want 23h", got)
+	}
+}
+
+func TestISOWeekday(t *testing.T) {
+	moscow := mustLoad(t, "Europe/Moscow")
+
+	// Воскресенье 22:30 UTC — это уже понедельник 01:30 в Москве
+	sundayUTC := time.Date(2026, 10, 18, 22, 30, 0, 0, time.UTC)
+	if got := ISOWeekday(sundayUTC, time.UTC); got != 7 {
+		t.Errorf("ISOWeekday(UTC) = %d, want 7", got)
+	}
+	if got := ISOWeekday(sundayUTC, moscow); got != 1 {
+		t.Errorf("ISOWeekday(Moscow) = %d, want 1", got)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	moscow := mustLoad(t, "Europe/Moscow")
+	newYork := mustLoad(t, "America/New_York")
+
+	tests := []struct {
+		name string
+		loc  *time.Location
+		t    time.Time
+		want time.Time
+	}{
+		{
+			name: "sunday night belongs to the ending week",
+			loc:  moscow,
+			t:    time.Date(2026, 10, 18, 23, 59, 0, 0, moscow),
+			want: time.Date(2026, 10, 12, 0, 0, 0, 0, moscow),
+		},
+		{
+			name: "monday midnight starts a new week",
+			loc:  moscow,
+			t:    time.Date(2026, 10, 19, 0, 0, 0, 0, moscow),
+			want: time.Date(2026, 10, 19, 0, 0, 0, 0, moscow),
+		},
+		{
+			name: "UTC sunday is already monday in Moscow",
+			loc:  moscow,
+			t:    time.Date(2026, 10, 18, 22, 30, 0, 0, time.UTC),
+			want: time.Date(2026, 10, 19, 0, 0, 0, 0, moscow),
+		},
+		{
+			name: "week after spring forward",
+			loc:  newYork,
+			t:    time.Date(2026, 3, 11, 12, 0, 0, 0, newYork),
+			want: time.Date(2026, 3, 9, 0, 0, 0, 0, newYork),
+		},
+		{
+			name: "week containing fall back",
+			loc:  newYork,
+			t:    time.Date(2026, 11, 1, 23, 0, 0, 0, newYork),
+			want: time.Date(2026, 10, 26, 0, 0, 0, 0, newYork),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeekStart(tt.t, tt.loc); !got.Equal(tt.want) {
+				t.Errorf("WeekStart(%s) = %s, want %s", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonthStartAndDateKey(t *testing.T) {
+	vladivostok := mustLoad(t, "Asia/Vladivostok")
+
+	// 31 октября 20:00 UTC — уже 1 ноября во Владивостоке
+	ts := time.Date(2026, 10, 31, 20, 0, 0, 0, time.UTC)
+	if got, want := MonthStart(ts, vladivostok), time.Date(2026, 11, 1, 0, 0, 0, 0, vladivostok); !got.Equal(want) {
+		t.Errorf("MonthStart = %s, want %s", got, want)
+	}
+	if got := DateKey(ts, vladivostok); got != "2026-11-01" {
+		t.Errorf("DateKey = %s, want 2026-11-01", got)
+	}
+	if got := DateKey(ts, time.UTC); got != "2026-10-31" {
+		t.Errorf("DateKey(UTC) = %s, want 2026-10-31", got)
+	}
+
+	day, err := ParseDate("2026-11-01", vladivostok)
+	if err != nil {
+		t.Fatalf("ParseDate error = %v", err)
+	}
+	if !day.Equal(StartOfDay(ts, vladivostok)) {
+		t.Errorf("ParseDate = %s, want local midnight", day)
+	}
+}
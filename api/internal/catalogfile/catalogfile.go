@@ -0,0 +1,48 @@
+// Package catalogfile загружает JSON-каталоги контента (достижения, карта знаний,
+// миссии, сезонные события, семейные квесты, таблица уровней). Каталог встроен в
+// бинарник и может быть заменён файлом на диске без пересборки.
+package catalogfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Load читает каталог из файла path, а при пустом пути — встроенный файл name из fsys
+func Load[T any](fsys fs.FS, name, path string) (*T, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "" {
+		data, err = fs.ReadFile(fsys, name)
+	} else {
+		data, err = os.ReadFile(path)
+		name = path
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read catalog %s: %w", name, err)
+	}
+
+	c, err := Parse[T](data)
+	if err != nil {
+		return nil, fmt.Errorf("catalog %s: %w", name, err)
+	}
+	return c, nil
+}
+
+// Parse разбирает JSON каталога. Неизвестные поля считаются ошибкой,
+// чтобы опечатки в ключах не терялись молча.
+func Parse[T any](data []byte) (*T, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var c T
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	return &c, nil
+}
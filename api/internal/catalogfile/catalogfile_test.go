@@ -0,0 +1,57 @@
+package catalogfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type testCatalog struct {
+	Version int      `json:"version"`
+	Items   []string `json:"items"`
+}
+
+func TestLoad(t *testing.T) {
+	embedded := fstest.MapFS{"catalog/items.json": {Data: []byte(`{"version": 1, "items": ["a"]}`)}}
+	dir := t.TempDir()
+	override := filepath.Join(dir, "items.json")
+	if err := os.WriteFile(override, []byte(`{"version": 2, "items": ["a", "b"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	typo := filepath.Join(dir, "typo.json")
+	if err := os.WriteFile(typo, []byte(`{"version": 3, "itemz": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantVersion int
+		wantErr     string
+	}{
+		{name: "embedded", path: "", wantVersion: 1},
+		{name: "file on disk", path: override, wantVersion: 2},
+		{name: "missing file", path: filepath.Join(dir, "missing.json"), wantErr: "missing.json"},
+		{name: "unknown field", path: typo, wantErr: "itemz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Load[testCatalog](embedded, "catalog/items.json", tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Load() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if c.Version != tt.wantVersion {
+				t.Errorf("Load() version = %d, want %d", c.Version, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -17,6 +17,9 @@ type Config struct {
 
 	// App
 	AppURL string // базовый URL приложения для реферальных ссылок
+
+	// Контент
+	CatalogDir string // каталог с JSON-каталогами контента; пусто — встроенные в бинарник
 }
 
 func mustEnv(k string) string {
@@ -43,5 +46,7 @@ func Load() *Config {
 
 		AllowedOrigins: getEnv("ALLOWED_ORIGINS", "http://localhost:5173,http://localhost:3000"),
 		AppURL:         getEnv("APP_URL", "http://localhost:5173"),
+
+		CatalogDir: os.Getenv("CATALOG_DIR"),
 	}
 }
@@ -0,0 +1,146 @@
+// Package content собирает каталоги контента, которые загружает сервер: достижения,
+// карту знаний, миссии, сезонные события, семейные квесты и таблицу уровней.
+// Сервер и cmd/catalogs загружают, проверяют и синхронизируют их одинаково.
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"child-bot/api/internal/achievement"
+	"child-bot/api/internal/family"
+	"child-bot/api/internal/knowledge"
+	"child-bot/api/internal/level"
+	"child-bot/api/internal/mission"
+	"child-bot/api/internal/seasonal"
+	"child-bot/api/internal/store"
+)
+
+// Catalogs каталоги контента одной версии
+type Catalogs struct {
+	Achievements *achievement.Catalog
+	Curriculum   *knowledge.Catalog
+	Missions     *mission.Catalog
+	Events       *seasonal.Catalog
+	Quests       *family.Catalog
+	Levels       *level.Table
+}
+
+// Load загружает каталоги из dir (CATALOG_DIR), а при пустом dir — встроенные в бинарник
+func Load(dir string) (*Catalogs, error) {
+	c := &Catalogs{}
+	loads := []struct {
+		name string
+		file string
+		load func(path string) error
+	}{
+		{"achievement catalog", achievement.FileName, func(path string) (err error) {
+			c.Achievements, err = achievement.Load(path)
+			return err
+		}},
+		{"curriculum", knowledge.FileName, func(path string) (err error) {
+			c.Curriculum, err = knowledge.Load(path)
+			return err
+		}},
+		{"mission catalog", mission.FileName, func(path string) (err error) {
+			c.Missions, err = mission.Load(path)
+			return err
+		}},
+		{"event catalog", seasonal.FileName, func(path string) (err error) {
+			c.Events, err = seasonal.Load(path)
+			return err
+		}},
+		{"family quest catalog", family.FileName, func(path string) (err error) {
+			c.Quests, err = family.Load(path)
+			return err
+		}},
+		{"level table", level.FileName, func(path string) (err error) {
+			c.Levels, err = level.Load(path)
+			return err
+		}},
+	}
+
+	for _, l := range loads {
+		path := ""
+		if dir != "" {
+			path = filepath.Join(dir, l.file)
+		}
+		if err := l.load(path); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", l.name, err)
+		}
+	}
+	return c, nil
+}
+
+// step проверка и синхронизация одного каталога
+type step struct {
+	name     string
+	version  int
+	validate func() error
+	sync     func(ctx context.Context, st *store.Store) error
+}
+
+func (c *Catalogs) steps() []step {
+	return []step{
+		{"achievement catalog", c.Achievements.Version, c.Achievements.Validate, func(ctx context.Context, st *store.Store) error {
+			_, err := c.Achievements.Sync(ctx, st)
+			return err
+		}},
+		{"curriculum", c.Curriculum.Version, c.Curriculum.Validate, func(ctx context.Context, st *store.Store) error {
+			_, err := st.SyncKnowledgeNodes(ctx, c.Curriculum.Version, c.Curriculum.Nodes())
+			return err
+		}},
+		{"mission catalog", c.Missions.Version, c.Missions.Validate, func(ctx context.Context, st *store.Store) error {
+			_, err := st.SyncMissionDefinitions(ctx, c.Missions.Version, c.Missions.Missions)
+			return err
+		}},
+		// Сезонные события вместе с их злодеями, достижениями и предметами
+		{"event catalog", c.Events.Version, c.Events.Validate, func(ctx context.Context, st *store.Store) error {
+			_, err := st.SyncSeasonalEvents(ctx, c.Events.Version, c.Events.Events)
+			return err
+		}},
+		{"family quest catalog", c.Quests.Version, c.Quests.Validate, func(ctx context.Context, st *store.Store) error {
+			_, err := st.SyncFamilyQuestTemplates(ctx, c.Quests.Version, c.Quests.Templates)
+			return err
+		}},
+		// Таблица уровней в БД не хранится, но предметы-награды должны быть в магазине
+		{"level table", c.Levels.Version, c.Levels.Validate, func(ctx context.Context, st *store.Store) error {
+			missing, err := st.MissingShopItems(ctx, c.Levels.ItemIDs())
+			if err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("unknown reward shop items: %v", missing)
+			}
+			return nil
+		}},
+	}
+}
+
+// Validate проверяет все каталоги без БД и возвращает все найденные ошибки
+func (c *Catalogs) Validate() error {
+	var errs []error
+	for _, s := range c.steps() {
+		if err := s.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s v%d is invalid: %w", s.name, s.version, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync проверяет каталоги и синхронизирует их с БД (идемпотентно)
+func (c *Catalogs) Sync(ctx context.Context, st *store.Store) error {
+	for _, s := range c.steps() {
+		if err := s.validate(); err != nil {
+			return fmt.Errorf("%s v%d is invalid: %w", s.name, s.version, err)
+		}
+		if err := s.sync(ctx, st); err != nil {
+			return fmt.Errorf("failed to sync %s v%d: %w", s.name, s.version, err)
+		}
+		log.Printf("✓ Synced %s v%d", s.name, s.version)
+	}
+	return nil
+}
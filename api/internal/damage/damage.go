@@ -0,0 +1,207 @@
+// Package damage считает урон злодею за решённую задачу.
+// Урон зависит от самой задачи (число заданий, тип и шаблон, класс), от того,
+// решил ли ребёнок без подсказок (крит) и с первой ли проверки. Формула задаётся
+// для каждого злодея в villains.damage_formula, разбивка урона сохраняется
+// в damage_events, чтобы экран битвы показал «крит!» и причины бонусов.
+package damage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Коды бонусов в разбивке урона
+const (
+	BonusItems    = "items"     // дополнительные задания в задаче
+	BonusTaskType = "task_type" // сложный тип задачи
+	BonusTemplate = "template"  // сложный шаблон задачи
+	BonusGrade    = "grade"     // класс ученика
+	BonusFirstTry = "first_try" // верно с первой проверки
+	BonusCritical = "critical"  // решено без подсказок
+	BonusCap      = "cap"       // срезано ограничением max_damage (отрицательный)
+	BonusPowerUp  = "power_up"  // усилитель из магазина («Двойной удар»)
+)
+
+// Formula настройки урона злодея (JSON в villains.damage_formula).
+// Нулевые поля отключают соответствующий бонус.
+type Formula struct {
+	Base               int            `json:"base"`                // базовый урон; 0 — damage_per_correct_task злодея
+	PerExtraItem       int            `json:"per_extra_item"`      // за каждое задание сверх первого
+	MaxItems           int            `json:"max_items"`           // сколько заданий учитывать (0 — все)
+	PerGrade           int            `json:"per_grade"`           // за каждый класс после первого
+	TaskTypeBonus      map[string]int `json:"task_type_bonus"`     // по ped_keys.task_type (word_problems, geometry...)
+	TemplateBonus      map[string]int `json:"template_bonus"`      // по ped_keys.template_id (T1...T52)
+	FirstTryBonus      int            `json:"first_try_bonus"`     // верно с первой проверки
+	CriticalMultiplier float64        `json:"critical_multiplier"` // множитель крита (без подсказок); <= 1 — крита нет
+	MaxDamage          int            `json:"max_damage"`          // потолок урона за задачу (0 — без потолка)
+}
+
+// Task атрибуты решённой задачи, известные в пайплайне проверки
+type Task struct {
+	Subject    string   // предмет задачи (math, ru...): для фаз недельного босса
+	ItemsCount int      // число заданий (items) в задаче
+	TaskTypes  []string // ped_keys.task_type заданий
+	Templates  []string // ped_keys.template_id заданий
+	Grade      int      // класс ученика
+	HintsUsed  int      // сколько подсказок открыл ребёнок
+	FirstTry   bool     // верно с первой проверки
+	Multiplier float64  // множитель усилителя; <= 1 — усилителя нет
+}
+
+// Bonus одна строка разбивки урона
+type Bonus struct {
+	Code   string `json:"code"`
+	Label  string `json:"label"`
+	Damage int    `json:"damage"`
+}
+
+// Breakdown итоговый урон и из чего он сложился
+type Breakdown struct {
+	Base       int
+	Total      int
+	IsCritical bool
+	Bonuses    []Bonus
+}
+
+// DefaultTaskTypeBonus бонусы за тип задачи по умолчанию
+var DefaultTaskTypeBonus = map[string]int{
+	"word_problems":     4,
+	"fractions_percent": 3,
+	"patterns_logic":    3,
+	"geometry":          2,
+	"measurement_units": 1,
+}
+
+// DefaultFormula формула для злодея без своей настройки
+func DefaultFormula(base int) Formula {
+	taskTypeBonus := make(map[string]int, len(DefaultTaskTypeBonus))
+	for key, bonus := range DefaultTaskTypeBonus {
+		taskTypeBonus[key] = bonus
+	}
+	return Formula{
+		Base:               base,
+		PerExtraItem:       2,
+		MaxItems:           6,
+		PerGrade:           1,
+		TaskTypeBonus:      taskTypeBonus,
+		FirstTryBonus:      3,
+		CriticalMultiplier: 1.5,
+		MaxDamage:          base * 3,
+	}
+}
+
+// ParseFormula разбирает villains.damage_formula. Пустой JSON — формула по умолчанию.
+// Поля, не указанные в JSON, берутся из формулы по умолчанию; base 0 — damage_per_correct_task.
+func ParseFormula(raw []byte, damagePerTask int) (Formula, error) {
+	formula := DefaultFormula(damagePerTask)
+	if len(raw) == 0 || string(raw) == "null" {
+		return formula, nil
+	}
+	if err := json.Unmarshal(raw, &formula); err != nil {
+		return DefaultFormula(damagePerTask), fmt.Errorf("parse damage formula: %w", err)
+	}
+	if formula.Base == 0 {
+		formula.Base = damagePerTask
+	}
+	if err := formula.Validate(); err != nil {
+		return DefaultFormula(damagePerTask), err
+	}
+	return formula, nil
+}
+
+// Validate проверяет, что формула не даёт отрицательного урона
+func (f Formula) Validate() error {
+	if f.Base < 0 || f.PerExtraItem < 0 || f.MaxItems < 0 || f.PerGrade < 0 || f.FirstTryBonus < 0 || f.MaxDamage < 0 {
+		return fmt.Errorf("damage formula: negative values are not allowed")
+	}
+	if f.CriticalMultiplier < 0 {
+		return fmt.Errorf("damage formula: critical_multiplier must be >= 0")
+	}
+	for key, bonus := range f.TaskTypeBonus {
+		if bonus < 0 {
+			return fmt.Errorf("damage formula: negative task_type_bonus %q", key)
+		}
+	}
+	for key, bonus := range f.TemplateBonus {
+		if bonus < 0 {
+			return fmt.Errorf("damage formula: negative template_bonus %q", key)
+		}
+	}
+	return nil
+}
+
+// Calculate считает урон за правильно решённую задачу.
+// Бонусы складываются с базой, крит умножает сумму, потолок срезает итог.
+// Усилитель умножает урон после потолка: купленный «Двойной удар» удваивает даже максимальный удар.
+// Урон всегда не меньше 1: правильное решение должно ранить злодея.
+func Calculate(f Formula, task Task) Breakdown {
+	result := Breakdown{Base: f.Base}
+	total := f.Base
+
+	add := func(code, label string, value int) {
+		if value <= 0 {
+			return
+		}
+		result.Bonuses = append(result.Bonuses, Bonus{Code: code, Label: label, Damage: value})
+		total += value
+	}
+
+	items := task.ItemsCount
+	if f.MaxItems > 0 && items > f.MaxItems {
+		items = f.MaxItems
+	}
+	if items > 1 {
+		add(BonusItems, fmt.Sprintf("Заданий в задаче: %d", task.ItemsCount), (items-1)*f.PerExtraItem)
+	}
+
+	add(BonusTaskType, "Сложный тип задачи", maxBonus(f.TaskTypeBonus, task.TaskTypes))
+	add(BonusTemplate, "Сложный шаблон задачи", maxBonus(f.TemplateBonus, task.Templates))
+
+	if task.Grade > 1 {
+		add(BonusGrade, fmt.Sprintf("%d класс", task.Grade), (task.Grade-1)*f.PerGrade)
+	}
+
+	if task.FirstTry {
+		add(BonusFirstTry, "С первой попытки", f.FirstTryBonus)
+	}
+
+	if task.HintsUsed == 0 && f.CriticalMultiplier > 1 {
+		critical := int(math.Round(float64(total)*f.CriticalMultiplier)) - total
+		if critical > 0 {
+			result.IsCritical = true
+			add(BonusCritical, "Крит! Без подсказок", critical)
+		}
+	}
+
+	if f.MaxDamage > 0 && total > f.MaxDamage {
+		result.Bonuses = append(result.Bonuses, Bonus{Code: BonusCap, Label: "Предел урона", Damage: f.MaxDamage - total})
+		total = f.MaxDamage
+	}
+
+	if task.Multiplier > 1 {
+		add(BonusPowerUp, fmt.Sprintf("Усилитель: урон ×%g", task.Multiplier), int(math.Round(float64(total)*task.Multiplier))-total)
+	}
+
+	if total < 1 {
+		total = 1
+	}
+	result.Total = total
+	return result
+}
+
+// Fixed разбивка для урона, заданного явно (ручной урон без формулы)
+func Fixed(amount int) Breakdown {
+	return Breakdown{Base: amount, Total: amount}
+}
+
+// maxBonus наибольший бонус среди ключей задачи: одна сложная подзадача делает сложной всю задачу
+func maxBonus(bonuses map[string]int, keys []string) int {
+	best := 0
+	for _, key := range keys {
+		if bonus := bonuses[key]; bonus > best {
+			best = bonus
+		}
+	}
+	return best
+}
@@ -0,0 +1,170 @@
+package damage
+
+import "testing"
+
+func bonusCodes(b Breakdown) map[string]int {
+	codes := make(map[string]int, len(b.Bonuses))
+	for _, bonus := range b.Bonuses {
+		codes[bonus.Code] = bonus.Damage
+	}
+	return codes
+}
+
+func TestCalculate(t *testing.T) {
+	plain := Formula{Base: 10}
+	full := Formula{
+		Base:               10,
+		PerExtraItem:       2,
+		MaxItems:           4,
+		PerGrade:           1,
+		TaskTypeBonus:      map[string]int{"word_problems": 4, "geometry": 2},
+		TemplateBonus:      map[string]int{"T44": 5},
+		FirstTryBonus:      3,
+		CriticalMultiplier: 1.5,
+	}
+
+	tests := []struct {
+		name         string
+		formula      Formula
+		task         Task
+		wantTotal    int
+		wantCritical bool
+		wantBonuses  map[string]int
+	}{
+		{
+			name:        "plain formula ignores task",
+			formula:     plain,
+			task:        Task{ItemsCount: 5, Grade: 4, FirstTry: true},
+			wantTotal:   10,
+			wantBonuses: map[string]int{},
+		},
+		{
+			name:        "trivial task with hints",
+			formula:     full,
+			task:        Task{ItemsCount: 1, Grade: 1, HintsUsed: 2},
+			wantTotal:   10,
+			wantBonuses: map[string]int{},
+		},
+		{
+			name:        "items capped by max_items",
+			formula:     full,
+			task:        Task{ItemsCount: 10, Grade: 1, HintsUsed: 1},
+			wantTotal:   16,
+			wantBonuses: map[string]int{BonusItems: 6},
+		},
+		{
+			name:        "hardest item type counts once",
+			formula:     full,
+			task:        Task{ItemsCount: 1, TaskTypes: []string{"geometry", "word_problems", "unknown"}, HintsUsed: 1},
+			wantTotal:   14,
+			wantBonuses: map[string]int{BonusTaskType: 4},
+		},
+		{
+			name:         "no hints is critical",
+			formula:      full,
+			task:         Task{ItemsCount: 1, Grade: 3, FirstTry: true},
+			wantTotal:    23, // (10 + 2 + 3) * 1.5 = 22.5
+			wantCritical: true,
+			wantBonuses:  map[string]int{BonusGrade: 2, BonusFirstTry: 3, BonusCritical: 8},
+		},
+		{
+			name:         "template bonus and cap",
+			formula:      Formula{Base: 10, TemplateBonus: map[string]int{"T44": 5}, CriticalMultiplier: 2, MaxDamage: 25},
+			task:         Task{ItemsCount: 1, Templates: []string{"T44"}},
+			wantTotal:    25,
+			wantCritical: true,
+			wantBonuses:  map[string]int{BonusTemplate: 5, BonusCritical: 15, BonusCap: -5},
+		},
+		{
+			name:         "power-up doubles capped damage",
+			formula:      Formula{Base: 10, TemplateBonus: map[string]int{"T44": 5}, CriticalMultiplier: 2, MaxDamage: 25},
+			task:         Task{ItemsCount: 1, Templates: []string{"T44"}, Multiplier: 2},
+			wantTotal:    50,
+			wantCritical: true,
+			wantBonuses:  map[string]int{BonusTemplate: 5, BonusCritical: 15, BonusCap: -5, BonusPowerUp: 25},
+		},
+		{
+			name:        "power-up multiplier 1 is ignored",
+			formula:     plain,
+			task:        Task{ItemsCount: 1, Multiplier: 1},
+			wantTotal:   10,
+			wantBonuses: map[string]int{},
+		},
+		{
+			name:        "multiplier 1 disables critical",
+			formula:     Formula{Base: 10, CriticalMultiplier: 1},
+			task:        Task{ItemsCount: 1},
+			wantTotal:   10,
+			wantBonuses: map[string]int{},
+		},
+		{
+			name:        "zero base still hurts",
+			formula:     Formula{},
+			task:        Task{ItemsCount: 1},
+			wantTotal:   1,
+			wantBonuses: map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Calculate(tt.formula, tt.task)
+			if got.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d (bonuses %+v)", got.Total, tt.wantTotal, got.Bonuses)
+			}
+			if got.IsCritical != tt.wantCritical {
+				t.Errorf("IsCritical = %v, want %v", got.IsCritical, tt.wantCritical)
+			}
+			codes := bonusCodes(got)
+			if len(codes) != len(tt.wantBonuses) {
+				t.Errorf("bonuses = %+v, want %v", got.Bonuses, tt.wantBonuses)
+			}
+			for code, want := range tt.wantBonuses {
+				if codes[code] != want {
+					t.Errorf("bonus %s = %d, want %d", code, codes[code], want)
+				}
+			}
+			sum := got.Base
+			for _, bonus := range got.Bonuses {
+				sum += bonus.Damage
+			}
+			if got.Total > 1 && sum != got.Total {
+				t.Errorf("base + bonuses = %d, want total %d", sum, got.Total)
+			}
+		})
+	}
+}
+
+func TestParseFormula(t *testing.T) {
+	f, err := ParseFormula(nil, 20)
+	if err != nil {
+		t.Fatalf("ParseFormula(nil) error = %v", err)
+	}
+	if f.Base != 20 || f.MaxDamage != 60 || f.TaskTypeBonus["word_problems"] != DefaultTaskTypeBonus["word_problems"] {
+		t.Errorf("default formula = %+v", f)
+	}
+
+	f, err = ParseFormula([]byte(`{"first_try_bonus": 7, "task_type_bonus": {"geometry": 9}}`), 20)
+	if err != nil {
+		t.Fatalf("ParseFormula error = %v", err)
+	}
+	if f.Base != 20 || f.FirstTryBonus != 7 || f.PerExtraItem != 2 {
+		t.Errorf("partial formula = %+v", f)
+	}
+	if f.TaskTypeBonus["geometry"] != 9 {
+		t.Errorf("task_type_bonus[geometry] = %d, want 9", f.TaskTypeBonus["geometry"])
+	}
+	if DefaultTaskTypeBonus["geometry"] != 2 {
+		t.Errorf("ParseFormula mutated DefaultTaskTypeBonus: %v", DefaultTaskTypeBonus)
+	}
+
+	for _, raw := range []string{`{"base": -1}`, `{"critical_multiplier": -2}`, `{"template_bonus": {"T1": -3}}`, `not json`} {
+		f, err := ParseFormula([]byte(raw), 20)
+		if err == nil {
+			t.Errorf("ParseFormula(%s) error = nil", raw)
+		}
+		if f.Base != 20 {
+			t.Errorf("ParseFormula(%s) fallback base = %d, want 20", raw, f.Base)
+		}
+	}
+}
@@ -48,3 +48,114 @@ type CheckResult struct {
 	Explanation string `json:"explanation"`
 	Score       int    `json:"score,omitempty"`
 }
+
+// MistakeExplanation разбор одной ошибки из проверки
+type MistakeExplanation struct {
+	Label         string   `json:"label"`                    // код ошибки из ErrorSpan
+	Fragment      string   `json:"fragment,omitempty"`       // фрагмент ответа ребёнка
+	Explanation   string   `json:"explanation"`              // объяснение, что пошло не так
+	CommonMistake string   `json:"common_mistake,omitempty"` // типичная ошибка из шаблона
+	Hints         []string `json:"hints"`                    // мини-лестница подсказок L1 → L3
+}
+
+// MistakesResult разбор всех ошибок попытки
+type MistakesResult struct {
+	TemplateID string               `json:"template_id,omitempty"`
+	Mistakes   []MistakeExplanation `json:"mistakes"`
+}
+
+// MistakeStep текущий шаг разбора ошибок
+type MistakeStep struct {
+	Mistake       MistakeExplanation `json:"mistake"`
+	MistakeIndex  int                `json:"mistake_index"`
+	TotalMistakes int                `json:"total_mistakes"`
+}
+
+// Роли участников диалога по задаче
+const (
+	DialogRoleChild = "child"
+	DialogRoleTutor = "tutor"
+)
+
+// DialogMessage сообщение в диалоге по задаче
+type DialogMessage struct {
+	Role      string    `json:"role"` // child или tutor
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DialogReply ответ наставника в диалоге
+type DialogReply struct {
+	Message       DialogMessage `json:"message"`
+	QuestionsLeft int           `json:"questions_left"`
+	Guarded       bool          `json:"guarded"` // ответ LLM заменён, так как раскрывал решение
+}
+
+// Оценки подсказки ребёнком
+const (
+	HintRatingHelpful        = "helpful"
+	HintRatingUnclear        = "unclear"
+	HintRatingGaveAwayAnswer = "gave_away_answer"
+)
+
+// IsValidHintRating проверяет, что оценка подсказки допустима
+func IsValidHintRating(rating string) bool {
+	switch rating {
+	case HintRatingHelpful, HintRatingUnclear, HintRatingGaveAwayAnswer:
+		return true
+	}
+	return false
+}
+
+// RegeneratedHint перегенерированная подсказка того же уровня
+type RegeneratedHint struct {
+	Hint              string `json:"hint"`
+	HintIndex         int    `json:"hint_index"`
+	TotalHints        int    `json:"total_hints"`
+	RegenerationsLeft int    `json:"regenerations_left"`
+}
+
+// Стили адаптивной лестницы подсказок
+const (
+	HintStyleStandard = "standard" // политика из PARSE без изменений
+	HintStyleGentle   = "gentle"   // больше мелких шагов, мягкий L1
+	HintStyleCompact  = "compact"  // меньше подсказок, короче формулировки
+)
+
+// AdaptiveHintPolicy политика подсказок, подобранная по истории ребёнка, и её обоснование
+type AdaptiveHintPolicy struct {
+	Style          string   `json:"style"`
+	MaxHints       int      `json:"max_hints"`
+	DefaultVisible int      `json:"default_visible"`
+	BaseMaxHints   int      `json:"base_max_hints"` // значение из PARSE
+	Scope          string   `json:"scope"`          // template, task_type или none
+	ScopeKey       string   `json:"scope_key,omitempty"`
+	Samples        int      `json:"samples"`
+	AvgHintsUsed   float64  `json:"avg_hints_used"`
+	L3Share        float64  `json:"l3_share"`     // доля попыток, где понадобилась L3
+	CorrectRate    float64  `json:"correct_rate"` // доля верных проверок
+	AvgTimeSeconds float64  `json:"avg_time_seconds"`
+	Reasons        []string `json:"reasons"`
+}
+
+// PageTaskProgress состояние одной задачи со страницы домашки
+type PageTaskProgress struct {
+	AttemptID string `json:"attempt_id"`
+	Position  int    `json:"position"`
+	TaskText  string `json:"task_text"`
+	Status    string `json:"status"`
+	HintsUsed int    `json:"hints_used"`
+	Checked   bool   `json:"checked"` // решение отправлено на проверку
+	Solved    bool   `json:"solved"`  // решение проверено и верно
+}
+
+// PageProgress прогресс решения страницы домашки целиком
+type PageProgress struct {
+	AttemptID       string             `json:"attempt_id"`
+	Status          string             `json:"status"`
+	Tasks           []PageTaskProgress `json:"tasks"`
+	TotalTasks      int                `json:"total_tasks"`
+	SolvedTasks     int                `json:"solved_tasks"`
+	ProgressPercent int                `json:"progress_percent"`
+	Completed       bool               `json:"completed"` // решены все задачи страницы
+}
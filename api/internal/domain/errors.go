@@ -30,4 +30,34 @@ var (
 
 	// ErrNoHintsAvailable возвращается, когда подсказки закончились
 	ErrNoHintsAvailable = errors.New("no more hints available")
+
+	// ErrNoMistakesLeft возвращается, когда все ошибки разобраны
+	ErrNoMistakesLeft = errors.New("no more mistakes to explain")
+
+	// ErrDialogLimitReached возвращается, когда исчерпан лимит вопросов в диалоге по задаче
+	ErrDialogLimitReached = errors.New("dialog limit reached")
+
+	// ErrRegenerationLimitReached возвращается, когда подсказку больше нельзя перегенерировать
+	ErrRegenerationLimitReached = errors.New("hint regeneration limit reached")
+
+	// ErrRateLimited возвращается при превышении частоты запросов
+	ErrRateLimited = errors.New("rate limit exceeded")
+
+	// ErrAchievementLocked возвращается, когда награду забирают за неразблокированное достижение
+	ErrAchievementLocked = errors.New("achievement is not unlocked")
+
+	// ErrInsufficientFunds возвращается, когда монет не хватает для списания
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrItemAlreadyOwned возвращается при повторной покупке неповторяемого предмета
+	ErrItemAlreadyOwned = errors.New("item already owned")
+
+	// ErrLevelTooLow возвращается, когда уровень ребёнка ниже требуемого
+	ErrLevelTooLow = errors.New("level too low")
+
+	// ErrReviewNotDue возвращается, когда задачу из очереди повторения ещё рано повторять
+	ErrReviewNotDue = errors.New("review is not due yet")
+
+	// ErrParentEmailRequired возвращается, когда у родителя нет подтверждённого email для кода PIN
+	ErrParentEmailRequired = errors.New("parent email is not verified")
 )
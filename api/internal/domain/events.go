@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Типы доменных событий
+const (
+	EventAttemptCompleted = "attempt_completed"
+	EventHintRequested    = "hint_requested"
+	EventVillainDefeated  = "villain_defeated"
+	EventStreakUpdated    = "streak_updated"
+	EventFriendActivated  = "friend_activated"
+)
+
+// Event доменное событие, относящееся к одному ребёнку
+type Event interface {
+	EventType() string
+	EventChildProfileID() string
+}
+
+// AttemptCompleted проверка решения завершена
+type AttemptCompleted struct {
+	ChildProfileID string
+	AttemptID      string
+	IsCorrect      bool
+	HasErrors      bool
+	HintsUsed      int
+	Subject        string        // предмет из Parse
+	Grade          int           // класс из Parse
+	Items          []AttemptItem // задания из Parse
+	FixedErrors    bool          // верная перепроверка решения, в котором были ошибки
+}
+
+// AttemptItem задание проверенной задачи: шаблон и тип задачи из Parse
+type AttemptItem struct {
+	TemplateID string
+	TaskType   string
+}
+
+func (e AttemptCompleted) EventType() string           { return EventAttemptCompleted }
+func (e AttemptCompleted) EventChildProfileID() string { return e.ChildProfileID }
+
+// HintRequested ребёнок открыл очередную подсказку
+type HintRequested struct {
+	ChildProfileID string
+	AttemptID      string
+	HintIndex      int
+}
+
+func (e HintRequested) EventType() string           { return EventHintRequested }
+func (e HintRequested) EventChildProfileID() string { return e.ChildProfileID }
+
+// VillainDefeated злодей побеждён
+type VillainDefeated struct {
+	ChildProfileID string
+	BattleID       int64
+	VillainID      string
+}
+
+func (e VillainDefeated) EventType() string           { return EventVillainDefeated }
+func (e VillainDefeated) EventChildProfileID() string { return e.ChildProfileID }
+
+// StreakUpdated изменилась серия дней подряд
+type StreakUpdated struct {
+	ChildProfileID string
+	StreakDays     int
+}
+
+func (e StreakUpdated) EventType() string           { return EventStreakUpdated }
+func (e StreakUpdated) EventChildProfileID() string { return e.ChildProfileID }
+
+// FriendActivated приглашённый друг стал активным (событие для пригласившего)
+type FriendActivated struct {
+	ChildProfileID  string // кто пригласил
+	FriendProfileID string // кого пригласили
+}
+
+func (e FriendActivated) EventType() string           { return EventFriendActivated }
+func (e FriendActivated) EventChildProfileID() string { return e.ChildProfileID }
+
+// DecodeEvent восстанавливает событие из JSON по его типу (для повторной доставки)
+func DecodeEvent(eventType string, payload []byte) (Event, error) {
+	switch eventType {
+	case EventAttemptCompleted:
+		return decodeEvent[AttemptCompleted](eventType, payload)
+	case EventHintRequested:
+		return decodeEvent[HintRequested](eventType, payload)
+	case EventVillainDefeated:
+		return decodeEvent[VillainDefeated](eventType, payload)
+	case EventStreakUpdated:
+		return decodeEvent[StreakUpdated](eventType, payload)
+	case EventFriendActivated:
+		return decodeEvent[FriendActivated](eventType, payload)
+	default:
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+}
+
+func decodeEvent[E Event](eventType string, payload []byte) (Event, error) {
+	var e E
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", eventType, err)
+	}
+	return e, nil
+}
@@ -0,0 +1,179 @@
+// Package family описывает семейные квесты: задания, которые ребёнок и родитель
+// выполняют вместе. Ребёнок продвигает квест проверками, родитель — подтверждениями
+// («объяснил задачу», «позанимались вместе»). Шаблоны квестов хранятся в JSON,
+// проверяются валидатором и синхронизируются в family_quest_templates при старте сервера.
+package family
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"child-bot/api/internal/catalogfile"
+)
+
+// Встроенный каталог: новый квест добавляется правкой JSON, без миграции
+//
+//go:embed catalog/quests.json
+var catalogFS embed.FS
+
+// FileName имя файла каталога: встроенного и в каталоге контента на диске
+const FileName = "quests.json"
+
+// Метрики прогресса ребёнка
+const (
+	MetricTasksCorrect = "tasks_correct" // верно решённые задачи
+	MetricTasksChecked = "tasks_checked" // отправленные на проверку решения
+	MetricActiveDays   = "active_days"   // дни с хотя бы одной проверкой
+)
+
+// Действия родителя, которые он подтверждает в квесте
+const (
+	ActionExplainedTask     = "explained_task"     // родитель объяснил задачу
+	ActionPracticedTogether = "practiced_together" // позанимались вместе
+)
+
+// Окна квеста
+const (
+	WindowDays    = "days"    // duration_days дней с начала квеста
+	WindowWeekend = "weekend" // до конца ближайших выходных, засчитываются только суббота и воскресенье
+)
+
+var knownMetrics = map[string]bool{
+	MetricTasksCorrect: true,
+	MetricTasksChecked: true,
+	MetricActiveDays:   true,
+}
+
+var knownActions = map[string]bool{
+	ActionExplainedTask:     true,
+	ActionPracticedTogether: true,
+}
+
+// Catalog версионированный каталог шаблонов квестов
+type Catalog struct {
+	Version   int        `json:"version"`
+	Templates []Template `json:"templates"`
+}
+
+// Template шаблон семейного квеста
+type Template struct {
+	ID           string      `json:"id"`
+	Type         string      `json:"type"` // weekend_challenge, teach_me, streak_together
+	Icon         string      `json:"icon"`
+	Title        string      `json:"title"`
+	Description  string      `json:"description"`
+	Child        ChildGoal   `json:"child"`
+	Parent       *ParentGoal `json:"parent,omitempty"` // nil — подтверждения родителя не нужны
+	Window       string      `json:"window"`
+	DurationDays int         `json:"duration_days,omitempty"` // для window = days
+	Reward       Reward      `json:"reward"`
+}
+
+// ChildGoal цель ребёнка
+type ChildGoal struct {
+	Metric string `json:"metric"`
+	Target int    `json:"target"`
+}
+
+// ParentGoal цель родителя: столько подтверждений действия
+type ParentGoal struct {
+	Action string `json:"action"`
+	Target int    `json:"target"`
+	Label  string `json:"label"` // текст кнопки подтверждения
+}
+
+// Reward награда ребёнку за квест; семейный бейдж попадает в коллекцию
+type Reward struct {
+	Coins     int    `json:"coins,omitempty"`
+	XP        int    `json:"xp,omitempty"`
+	BadgeID   string `json:"badge_id,omitempty"`
+	BadgeName string `json:"badge_name,omitempty"`
+}
+
+var idPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Default возвращает встроенный каталог
+func Default() (*Catalog, error) {
+	return Load("")
+}
+
+// Load читает каталог из файла path, а при пустом пути — встроенный
+func Load(path string) (*Catalog, error) {
+	return catalogfile.Load[Catalog](catalogFS, "catalog/"+FileName, path)
+}
+
+// Validate проверяет каталог и возвращает все найденные ошибки разом
+func (c *Catalog) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Version <= 0 {
+		add("version must be positive")
+	}
+
+	seen := make(map[string]bool)
+	for i, t := range c.Templates {
+		where := fmt.Sprintf("templates[%d] %q", i, t.ID)
+		switch {
+		case t.ID == "" || len(t.ID) > 50 || !idPattern.MatchString(t.ID):
+			add("%s: id must match [a-z0-9_]+ and be at most 50 chars", where)
+		case seen[t.ID]:
+			add("%s: duplicate id", where)
+		}
+		seen[t.ID] = true
+
+		if t.Type == "" || t.Title == "" {
+			add("%s: type and title are required", where)
+		}
+		if !knownMetrics[t.Child.Metric] {
+			add("%s: unknown child metric %q", where, t.Child.Metric)
+		}
+		if t.Child.Target <= 0 {
+			add("%s: child target must be positive", where)
+		}
+		if p := t.Parent; p != nil {
+			if !knownActions[p.Action] {
+				add("%s: unknown parent action %q", where, p.Action)
+			}
+			if p.Target <= 0 || p.Label == "" {
+				add("%s: parent target must be positive and label is required", where)
+			}
+		}
+
+		switch t.Window {
+		case WindowDays:
+			if t.DurationDays <= 0 {
+				add("%s: duration_days must be positive for window days", where)
+			}
+			if t.Child.Metric == MetricActiveDays && t.Child.Target > t.DurationDays {
+				add("%s: active_days target exceeds duration_days", where)
+			}
+		case WindowWeekend:
+			if t.DurationDays != 0 {
+				add("%s: duration_days is not used for window weekend", where)
+			}
+			if t.Child.Metric == MetricActiveDays && t.Child.Target > 2 {
+				add("%s: active_days target exceeds a weekend", where)
+			}
+		default:
+			add("%s: unknown window %q", where, t.Window)
+		}
+
+		r := t.Reward
+		if r.Coins < 0 || r.XP < 0 {
+			add("%s: reward must not be negative", where)
+		}
+		if r.Coins == 0 && r.XP == 0 && r.BadgeID == "" {
+			add("%s: reward is empty", where)
+		}
+		if (r.BadgeID == "") != (r.BadgeName == "") {
+			add("%s: badge_id and badge_name go together", where)
+		}
+	}
+
+	return errors.Join(errs...)
+}
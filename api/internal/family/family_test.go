@@ -0,0 +1,89 @@
+package family
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultCatalogValid(t *testing.T) {
+	c, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("embedded catalog is invalid:\n%v", err)
+	}
+}
+
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	c := &Catalog{
+		Version: 1,
+		Templates: []Template{
+			{ID: "a", Type: "x", Title: "A", Child: ChildGoal{Metric: "unknown", Target: 1}, Window: WindowDays, Reward: Reward{Coins: 1}},
+			{
+				ID: "a", Type: "x", Title: "B", Child: ChildGoal{Metric: MetricActiveDays, Target: 3},
+				Parent: &ParentGoal{Action: "cooked_dinner", Target: 1, Label: "Ужин"},
+				Window: WindowWeekend, Reward: Reward{BadgeID: "b"},
+			},
+		},
+	}
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want errors")
+	}
+	for _, want := range []string{"unknown child metric", "duration_days must be positive", "duplicate id",
+		"unknown parent action", "exceeds a weekend", "badge_id and badge_name"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestTemplateWindow(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	weekend := Template{Window: WindowWeekend}
+	week := Template{Window: WindowDays, DurationDays: 7}
+
+	wednesday := time.Date(2026, 3, 4, 15, 0, 0, 0, loc)
+	saturday := time.Date(2026, 3, 7, 23, 30, 0, 0, loc)
+	nextMonday := time.Date(2026, 3, 9, 0, 0, 0, 0, loc)
+
+	if got := weekend.EndsAt(wednesday, loc); !got.Equal(nextMonday) {
+		t.Errorf("weekend EndsAt(wednesday) = %v, want %v", got, nextMonday)
+	}
+	if got := weekend.EndsAt(saturday, loc); !got.Equal(nextMonday) {
+		t.Errorf("weekend EndsAt(saturday) = %v, want %v", got, nextMonday)
+	}
+	if got, want := week.EndsAt(wednesday, loc), time.Date(2026, 3, 11, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("days EndsAt(wednesday) = %v, want %v", got, want)
+	}
+
+	if weekend.CountsOn(wednesday, loc) || !weekend.CountsOn(saturday, loc) {
+		t.Error("weekend quest must count only Saturday and Sunday")
+	}
+	if !week.CountsOn(wednesday, loc) {
+		t.Error("days quest must count every day")
+	}
+}
+
+func TestChildProgress(t *testing.T) {
+	loc := time.UTC
+	at := time.Date(2026, 3, 4, 10, 0, 0, 0, loc)
+
+	if ChildIncrement(MetricTasksCorrect, false) != 0 || ChildIncrement(MetricTasksCorrect, true) != 1 {
+		t.Error("tasks_correct must count only correct checks")
+	}
+	if ChildIncrement(MetricTasksChecked, false) != 1 {
+		t.Error("tasks_checked must count every check")
+	}
+	if a, b := ChildSourceKey(MetricActiveDays, "a1", at, loc), ChildSourceKey(MetricActiveDays, "a2", at, loc); a != b {
+		t.Errorf("active_days keys of the same day differ: %s, %s", a, b)
+	}
+	if got := ChildSourceKey(MetricTasksCorrect, "a1", at, loc); got != "attempt:a1" {
+		t.Errorf("ChildSourceKey = %s, want attempt:a1", got)
+	}
+}
@@ -0,0 +1,47 @@
+package family
+
+import (
+	"time"
+
+	"child-bot/api/internal/calendar"
+)
+
+// EndsAt конец квеста, начатого в момент start, по календарю ребёнка:
+// для window days — полночь через duration_days дней, для weekend — полночь понедельника
+// после ближайших выходных (начатый в субботу или воскресенье квест идёт до конца этих выходных)
+func (t Template) EndsAt(start time.Time, loc *time.Location) time.Time {
+	if t.Window == WindowWeekend {
+		return calendar.WeekStart(start, loc).AddDate(0, 0, 7)
+	}
+	return calendar.StartOfDay(start, loc).AddDate(0, 0, t.DurationDays)
+}
+
+// CountsOn засчитываются ли действия ребёнка в момент at
+func (t Template) CountsOn(at time.Time, loc *time.Location) bool {
+	if t.Window == WindowWeekend {
+		return calendar.ISOWeekday(at, loc) >= 6
+	}
+	return true
+}
+
+// ChildIncrement на сколько проверка двигает цель ребёнка с метрикой metric.
+// Для active_days прогресс — разные дни, поэтому повторы дня отсекает ключ источника.
+func ChildIncrement(metric string, isCorrect bool) int {
+	switch metric {
+	case MetricTasksCorrect:
+		if isCorrect {
+			return 1
+		}
+	case MetricTasksChecked, MetricActiveDays:
+		return 1
+	}
+	return 0
+}
+
+// ChildSourceKey ключ, по которому проверка засчитывается квесту один раз
+func ChildSourceKey(metric, attemptID string, at time.Time, loc *time.Location) string {
+	if metric == MetricActiveDays {
+		return "day:" + calendar.DateKey(at, loc)
+	}
+	return "attempt:" + attemptID
+}
@@ -0,0 +1,230 @@
+// Package knowledge описывает карту знаний: дерево тем по предметам и классам
+// и освоение каждой темы по истории проверок. Разделы дерева повторяют структуру
+// курса Петерсон, темы строятся из педагогических шаблонов T1..T52: название, классы
+// и тема по умолчанию берутся из реестра шаблонов. Каталог хранится в JSON,
+// проверяется валидатором и синхронизируется в таблицу knowledge_nodes при старте сервера.
+package knowledge
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"child-bot/api/internal/catalogfile"
+	"child-bot/api/internal/llm"
+)
+
+// Встроенный каталог: новая тема добавляется правкой JSON, без миграции
+//
+//go:embed curriculum/curriculum.json
+var curriculumFS embed.FS
+
+// FileName имя файла каталога: встроенного и в каталоге контента на диске
+const FileName = "curriculum.json"
+
+// Виды узлов дерева
+const (
+	KindSection = "section"
+	KindTopic   = "topic"
+)
+
+// Catalog версионированный каталог учебной программы
+type Catalog struct {
+	Version  int       `json:"version"`
+	Subjects []Subject `json:"subjects"`
+}
+
+// Subject дерево тем одного предмета
+type Subject struct {
+	Subject  string    `json:"subject"` // math, ru, ...
+	Title    string    `json:"title"`
+	Sections []Section `json:"sections"`
+}
+
+// Section раздел курса
+type Section struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	TaskTypes []string `json:"task_types,omitempty"` // ped_keys.task_type, которые без шаблона засчитываются разделу
+	Topics    []Topic  `json:"topics"`
+}
+
+// Topic тема раздела — один педагогический шаблон
+type Topic struct {
+	Template string `json:"template"`        // template_code: T1..T52
+	ID       string `json:"id,omitempty"`    // по умолчанию — тема шаблона из реестра
+	Title    string `json:"title,omitempty"` // по умолчанию — название шаблона
+}
+
+// Node узел дерева для синхронизации с БД и построения карты
+type Node struct {
+	ID           string
+	Subject      string
+	ParentID     string // пусто у разделов
+	Kind         string // section, topic
+	Title        string
+	GradeMin     int
+	GradeMax     int
+	TemplateCode string   // у тем
+	TaskTypes    []string // у разделов
+	SortOrder    int
+}
+
+var idPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Default возвращает встроенный каталог
+func Default() (*Catalog, error) {
+	return Load("")
+}
+
+// Load читает каталог из файла path, а при пустом пути — встроенный
+func Load(path string) (*Catalog, error) {
+	return catalogfile.Load[Catalog](curriculumFS, "curriculum/"+FileName, path)
+}
+
+// Validate проверяет каталог и возвращает все найденные ошибки разом
+func (c *Catalog) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Version <= 0 {
+		add("version must be positive")
+	}
+
+	subjects := make(map[string]bool)
+	ids := make(map[string]string)
+	templates := make(map[string]string)
+	for _, subj := range c.Subjects {
+		if subj.Subject == "" || !idPattern.MatchString(subj.Subject) {
+			add("subject %q must match [a-z0-9_]+", subj.Subject)
+		}
+		if subjects[subj.Subject] {
+			add("subject %q: duplicate", subj.Subject)
+		}
+		subjects[subj.Subject] = true
+		if subj.Title == "" {
+			add("subject %q: title is required", subj.Subject)
+		}
+		if len(subj.Sections) == 0 {
+			add("subject %q: at least one section is required", subj.Subject)
+		}
+
+		taskTypes := make(map[string]string)
+		for i, sec := range subj.Sections {
+			where := fmt.Sprintf("%s.sections[%d] %q", subj.Subject, i, sec.ID)
+			checkID := func(id, what string) {
+				switch {
+				case id == "" || len(id) > 100 || !idPattern.MatchString(id):
+					add("%s: %s id %q must match [a-z0-9_]+ and be at most 100 chars", where, what, id)
+				case ids[id] != "":
+					add("%s: %s id %q already used by %s", where, what, id, ids[id])
+				}
+				ids[id] = where
+			}
+
+			checkID(sec.ID, "section")
+			if sec.Title == "" {
+				add("%s: title is required", where)
+			}
+			if len(sec.Topics) == 0 {
+				add("%s: at least one topic is required", where)
+			}
+			for _, tt := range sec.TaskTypes {
+				if other, ok := taskTypes[tt]; ok {
+					add("%s: task type %q already belongs to section %q", where, tt, other)
+				}
+				taskTypes[tt] = sec.ID
+			}
+
+			for _, t := range sec.Topics {
+				entry, ok := llm.FindTemplate(t.Template)
+				if !ok || entry.Template.TemplateCode != t.Template {
+					add("%s: unknown template_code %q", where, t.Template)
+					continue
+				}
+				if other, ok := templates[t.Template]; ok {
+					add("%s: template %s already used in %s", where, t.Template, other)
+				}
+				templates[t.Template] = where
+				checkID(topicID(t, entry), "topic "+t.Template)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Nodes разворачивает каталог в узлы дерева: разделы, затем их темы.
+// Классы темы берутся из реестра шаблонов, классы раздела — общий диапазон его тем.
+func (c *Catalog) Nodes() []Node {
+	var nodes []Node
+	for _, subj := range c.Subjects {
+		for i, sec := range subj.Sections {
+			section := Node{
+				ID:        sec.ID,
+				Subject:   subj.Subject,
+				Kind:      KindSection,
+				Title:     sec.Title,
+				TaskTypes: sec.TaskTypes,
+				SortOrder: i + 1,
+			}
+
+			var topics []Node
+			for j, t := range sec.Topics {
+				entry, ok := llm.FindTemplate(t.Template)
+				if !ok {
+					continue
+				}
+				title := t.Title
+				if title == "" {
+					title = entry.Template.Title
+				}
+				topic := Node{
+					ID:           topicID(t, entry),
+					Subject:      subj.Subject,
+					ParentID:     sec.ID,
+					Kind:         KindTopic,
+					Title:        title,
+					GradeMin:     int(entry.Template.GradeMin),
+					GradeMax:     int(entry.Template.GradeMax),
+					TemplateCode: entry.Template.TemplateCode,
+					SortOrder:    j + 1,
+				}
+				if section.GradeMin == 0 || topic.GradeMin < section.GradeMin {
+					section.GradeMin = topic.GradeMin
+				}
+				if topic.GradeMax > section.GradeMax {
+					section.GradeMax = topic.GradeMax
+				}
+				topics = append(topics, topic)
+			}
+
+			nodes = append(nodes, section)
+			nodes = append(nodes, topics...)
+		}
+	}
+	return nodes
+}
+
+// TemplateCode приводит template_id из Parse (T1_pedagogical_template или T1) к template_code
+func TemplateCode(templateID string) string {
+	if entry, ok := llm.FindTemplate(templateID); ok {
+		return entry.Template.TemplateCode
+	}
+	return ""
+}
+
+// topicID id темы: явный из каталога или тема шаблона по умолчанию
+func topicID(t Topic, entry *llm.TemplateEntry) string {
+	if t.ID != "" {
+		return t.ID
+	}
+	if entry.Template.PedKeysDefaults.Topic != "" {
+		return entry.Template.PedKeysDefaults.Topic
+	}
+	return strings.ToLower(entry.Template.TemplateCode)
+}
@@ -0,0 +1,167 @@
+package knowledge
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultCatalogIsValid(t *testing.T) {
+	c, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("embedded curriculum is invalid:\n%v", err)
+	}
+
+	nodes := c.Nodes()
+	byID := make(map[string]Node)
+	for _, n := range nodes {
+		byID[n.ID] = n
+	}
+
+	// Тема строится из шаблона: название, классы и id берутся из реестра
+	composition, ok := byID["number_composition"]
+	if !ok {
+		t.Fatal("topic number_composition (T45) not found")
+	}
+	if composition.TemplateCode != "T45" || composition.ParentID != "math_numbers" || composition.GradeMin != 1 || composition.GradeMax != 1 {
+		t.Errorf("number_composition = %+v, want T45 in math_numbers for grade 1", composition)
+	}
+	if section := byID["math_numbers"]; section.GradeMin != 1 || section.GradeMax != 4 {
+		t.Errorf("math_numbers grades = %d..%d, want 1..4", section.GradeMin, section.GradeMax)
+	}
+}
+
+func TestValidateReportsErrors(t *testing.T) {
+	c := &Catalog{
+		Version: 1,
+		Subjects: []Subject{{
+			Subject: "math",
+			Title:   "Математика",
+			Sections: []Section{
+				{ID: "a", Title: "A", TaskTypes: []string{"geometry"}, Topics: []Topic{{Template: "T24"}, {Template: "T999"}}},
+				{ID: "b", Title: "B", TaskTypes: []string{"geometry"}, Topics: []Topic{{Template: "T24"}}},
+				{ID: "a", Title: "", Topics: nil},
+			},
+		}},
+	}
+
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want errors")
+	}
+	for _, want := range []string{"unknown template_code \"T999\"", "template T24 already used", "task type \"geometry\" already belongs", "section id \"a\" already used", "title is required", "at least one topic"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestMastery(t *testing.T) {
+	for _, tt := range []struct {
+		correct bool
+		hints   int
+		want    int
+	}{
+		{true, 0, 100},
+		{true, 1, 80},
+		{true, 5, 40},
+		{false, 0, 0},
+	} {
+		if got := EvidenceScore(tt.correct, tt.hints); got != tt.want {
+			t.Errorf("EvidenceScore(%v, %d) = %d, want %d", tt.correct, tt.hints, got, tt.want)
+		}
+	}
+
+	// Первые задачи усредняются поровну: 100, 0, 100 -> 67
+	m := UpdateMastery(0, 0, 100)
+	m = UpdateMastery(m, 1, 0)
+	m = UpdateMastery(m, 2, 100)
+	if m != 67 {
+		t.Errorf("mastery after 100, 0, 100 = %d, want 67", m)
+	}
+	// Дальше новая задача весит четверть
+	if got := UpdateMastery(60, 10, 100); got != 70 {
+		t.Errorf("UpdateMastery(60, 10, 100) = %d, want 70", got)
+	}
+
+	for _, tt := range []struct {
+		mastery, attempts, want int
+	}{
+		{0, 0, LevelNotStarted},
+		{30, 4, LevelLearning},
+		{90, 2, LevelPracticing},
+		{90, 3, LevelMastered},
+		{65, 10, LevelPracticing},
+	} {
+		if got := LevelOf(tt.mastery, tt.attempts); got != tt.want {
+			t.Errorf("LevelOf(%d, %d) = %d, want %d", tt.mastery, tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	nodes := []Node{
+		{ID: "geo", Kind: KindSection, TaskTypes: []string{"geometry"}},
+		{ID: "shapes", Kind: KindTopic, ParentID: "geo", TemplateCode: "T24"},
+		{ID: "angles", Kind: KindTopic, ParentID: "geo", TemplateCode: "T25"},
+	}
+	got := Resolve(nodes, []Item{
+		{TemplateCode: "T24", TaskType: "geometry"},
+		{TemplateCode: "T24", TaskType: "geometry"}, // то же задание второй раз не считается
+		{TaskType: "geometry"},                      // без шаблона — в раздел
+		{TaskType: "unknown"},
+	})
+	if strings.Join(got, ",") != "shapes,geo" {
+		t.Errorf("Resolve = %v, want [shapes geo]", got)
+	}
+}
+
+func TestBuildTreeAndWeakSpots(t *testing.T) {
+	nodes := []Node{
+		{ID: "numbers", Kind: KindSection, SortOrder: 1, GradeMin: 1, GradeMax: 4},
+		{ID: "composition", Kind: KindTopic, ParentID: "numbers", SortOrder: 1, GradeMin: 1, GradeMax: 1},
+		{ID: "line", Kind: KindTopic, ParentID: "numbers", SortOrder: 2, GradeMin: 1, GradeMax: 4},
+		{ID: "roman", Kind: KindTopic, ParentID: "numbers", SortOrder: 3, GradeMin: 1, GradeMax: 4},
+		{ID: "fractions", Kind: KindSection, SortOrder: 2, GradeMin: 3, GradeMax: 4},
+		{ID: "parts", Kind: KindTopic, ParentID: "fractions", SortOrder: 1, GradeMin: 3, GradeMax: 4},
+	}
+	now := time.Now()
+	progress := map[string]Progress{
+		"line":  {Attempts: 3, Correct: 3, Mastery: 90, LastPracticedAt: &now},
+		"roman": {Attempts: 4, Correct: 1, Mastery: 30},
+		"parts": {Attempts: 1, Mastery: 0},
+	}
+
+	tree := BuildTree(nodes, progress, 2)
+	if len(tree) != 1 || tree[0].ID != "numbers" {
+		t.Fatalf("BuildTree(grade 2) sections = %d, want only numbers", len(tree))
+	}
+	numbers := tree[0]
+	if len(numbers.Children) != 2 {
+		t.Fatalf("numbers topics for grade 2 = %d, want 2 (composition is grade 1 only)", len(numbers.Children))
+	}
+	// (90*3 + 30*4) / 7 = 55.7 -> 56
+	if numbers.Mastery != 56 || numbers.Attempts != 7 || numbers.TopicsPracticed != 2 || numbers.TopicsMastered != 1 {
+		t.Errorf("numbers aggregate = %+v, want mastery 56 over 7 tasks", numbers.Progress)
+	}
+	if numbers.LastPracticedAt == nil || !numbers.LastPracticedAt.Equal(now) {
+		t.Errorf("numbers last practiced = %v, want %v", numbers.LastPracticedAt, now)
+	}
+
+	weak := WeakSpots(tree, 5)
+	if len(weak) != 1 || weak[0].ID != "roman" {
+		t.Errorf("WeakSpots = %d, want [roman]", len(weak))
+	}
+
+	// Без фильтра по классу видны все разделы; одна задача — ещё не слабое место
+	all := BuildTree(nodes, progress, 0)
+	if len(all) != 2 || len(all[0].Children) != 3 {
+		t.Errorf("BuildTree(all) = %d sections, want 2 with 3 topics in the first", len(all))
+	}
+	if got := WeakSpots(all, 5); len(got) != 1 {
+		t.Errorf("WeakSpots(all) = %d, want 1", len(got))
+	}
+}
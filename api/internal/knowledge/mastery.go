@@ -0,0 +1,230 @@
+package knowledge
+
+import (
+	"sort"
+	"time"
+)
+
+// Уровни освоения темы
+const (
+	LevelNotStarted = 0 // задач по теме ещё не было
+	LevelLearning   = 1 // освоение ниже 50
+	LevelPracticing = 2 // 50..79 или мало задач для «освоено»
+	LevelMastered   = 3 // от 80 и хотя бы MasteredMinAttempts задач
+)
+
+// MasteredMinAttempts сколько задач нужно, чтобы тема считалась освоенной
+const MasteredMinAttempts = 3
+
+// WeakMinAttempts с какого числа задач тема может попасть в слабые места
+const WeakMinAttempts = 2
+
+// weakMastery порог освоения слабого места
+const weakMastery = 50
+
+// Item задание из Parse: шаблон и тип задачи
+type Item struct {
+	TemplateCode string
+	TaskType     string
+}
+
+// Progress освоение узла ребёнком
+type Progress struct {
+	Attempts        int
+	Correct         int
+	HintsUsed       int
+	Mastery         int // 0..100
+	LastPracticedAt *time.Time
+}
+
+// EvidenceScore насколько проверка говорит об освоении темы, 0..100:
+// верно без подсказок — 100, каждая подсказка снижает оценку, ошибка — 0
+func EvidenceScore(isCorrect bool, hintsUsed int) int {
+	if !isCorrect {
+		return 0
+	}
+	score := 100 - 20*hintsUsed
+	if score < 40 {
+		score = 40
+	}
+	return score
+}
+
+// UpdateMastery новое освоение после проверки с оценкой score.
+// Первые задачи усредняются поровну, дальше новые весят четверть —
+// давние ошибки постепенно перестают тянуть тему вниз.
+func UpdateMastery(mastery, attempts, score int) int {
+	weight := 0.25
+	if attempts < 3 {
+		weight = 1 / float64(attempts+1)
+	}
+	next := float64(mastery) + weight*float64(score-mastery)
+	return clampMastery(int(next + 0.5))
+}
+
+// LevelOf уровень освоения
+func LevelOf(mastery, attempts int) int {
+	switch {
+	case attempts == 0:
+		return LevelNotStarted
+	case mastery < weakMastery:
+		return LevelLearning
+	case mastery >= 80 && attempts >= MasteredMinAttempts:
+		return LevelMastered
+	default:
+		return LevelPracticing
+	}
+}
+
+// IsWeak слабое место: задач достаточно, а освоение низкое
+func IsWeak(p Progress) bool {
+	return p.Attempts >= WeakMinAttempts && p.Mastery < weakMastery
+}
+
+// Resolve узлы дерева, которым засчитывается проверка: тема по шаблону задания,
+// а если шаблон не в каталоге — раздел по типу задачи. Узлы без повторов.
+func Resolve(nodes []Node, items []Item) []string {
+	byTemplate := make(map[string]string)
+	byTaskType := make(map[string]string)
+	for _, n := range nodes {
+		if n.Kind == KindTopic && n.TemplateCode != "" {
+			byTemplate[n.TemplateCode] = n.ID
+		}
+		for _, tt := range n.TaskTypes {
+			byTaskType[tt] = n.ID
+		}
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, item := range items {
+		id, ok := byTemplate[item.TemplateCode]
+		if !ok {
+			id, ok = byTaskType[item.TaskType]
+		}
+		if ok && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// TreeNode узел карты знаний с освоением
+type TreeNode struct {
+	Node
+	Progress
+	Level           int
+	Weak            bool
+	TopicsTotal     int // у разделов
+	TopicsPracticed int
+	TopicsMastered  int
+	Children        []*TreeNode
+}
+
+// BuildTree строит дерево разделов с темами для класса grade (0 — все классы).
+// Освоение раздела — среднее по его темам (и задачам, засчитанным самому разделу),
+// взвешенное числом задач.
+func BuildTree(nodes []Node, progress map[string]Progress, grade int) []*TreeNode {
+	var sections []*TreeNode
+	byID := make(map[string]*TreeNode)
+	for _, n := range nodes {
+		if n.Kind != KindSection {
+			continue
+		}
+		section := &TreeNode{Node: n, Progress: progress[n.ID]}
+		byID[n.ID] = section
+		sections = append(sections, section)
+	}
+
+	for _, n := range nodes {
+		section, ok := byID[n.ParentID]
+		if n.Kind != KindTopic || !ok || !inGrade(n, grade) {
+			continue
+		}
+		p := progress[n.ID]
+		topic := &TreeNode{Node: n, Progress: p, Level: LevelOf(p.Mastery, p.Attempts), Weak: IsWeak(p)}
+		section.Children = append(section.Children, topic)
+	}
+
+	result := make([]*TreeNode, 0, len(sections))
+	for _, section := range sections {
+		if len(section.Children) == 0 && section.Attempts == 0 {
+			continue
+		}
+		aggregate(section)
+		sort.SliceStable(section.Children, func(i, j int) bool {
+			return section.Children[i].SortOrder < section.Children[j].SortOrder
+		})
+		result = append(result, section)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].SortOrder < result[j].SortOrder })
+	return result
+}
+
+// WeakSpots слабые темы дерева: сначала с самым низким освоением, при равенстве — где больше задач
+func WeakSpots(tree []*TreeNode, limit int) []*TreeNode {
+	var weak []*TreeNode
+	for _, section := range tree {
+		for _, topic := range section.Children {
+			if topic.Weak {
+				weak = append(weak, topic)
+			}
+		}
+	}
+	sort.SliceStable(weak, func(i, j int) bool {
+		if weak[i].Mastery != weak[j].Mastery {
+			return weak[i].Mastery < weak[j].Mastery
+		}
+		return weak[i].Attempts > weak[j].Attempts
+	})
+	if limit > 0 && len(weak) > limit {
+		weak = weak[:limit]
+	}
+	return weak
+}
+
+// aggregate сводит освоение тем в освоение раздела
+func aggregate(section *TreeNode) {
+	own := section.Progress
+	weighted := own.Mastery * own.Attempts
+	total := section.Progress
+
+	section.TopicsTotal = len(section.Children)
+	for _, topic := range section.Children {
+		if topic.Attempts == 0 {
+			continue
+		}
+		section.TopicsPracticed++
+		if topic.Level == LevelMastered {
+			section.TopicsMastered++
+		}
+		weighted += topic.Mastery * topic.Attempts
+		total.Attempts += topic.Attempts
+		total.Correct += topic.Correct
+		total.HintsUsed += topic.HintsUsed
+		if topic.LastPracticedAt != nil && (total.LastPracticedAt == nil || topic.LastPracticedAt.After(*total.LastPracticedAt)) {
+			total.LastPracticedAt = topic.LastPracticedAt
+		}
+	}
+	if total.Attempts > 0 {
+		total.Mastery = (weighted + total.Attempts/2) / total.Attempts
+	}
+	section.Progress = total
+	section.Level = LevelOf(total.Mastery, total.Attempts)
+	section.Weak = IsWeak(total)
+}
+
+func inGrade(n Node, grade int) bool {
+	return grade <= 0 || (n.GradeMin <= grade && grade <= n.GradeMax)
+}
+
+func clampMastery(v int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > 100 {
+		return 100
+	}
+	return v
+}
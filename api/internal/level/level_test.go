@@ -0,0 +1,85 @@
+package level
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultTableIsValid(t *testing.T) {
+	table, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if err := table.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+}
+
+// Встроенная таблица повторяет прежнюю формулу 50 × level² + 50 × level,
+// поэтому уровни уже играющих детей не меняются
+func TestDefaultTableKeepsLegacyCurve(t *testing.T) {
+	table := MustDefault()
+	for level := 1; level <= 60; level++ {
+		if got, want := table.XPForLevel(level), 50*level*level+50*level; got != want {
+			t.Errorf("XPForLevel(%d) = %d, want %d", level, got, want)
+		}
+	}
+	for _, tt := range []struct{ xp, level int }{{0, 1}, {99, 1}, {100, 2}, {299, 2}, {300, 3}, {1000, 5}, {25299, 22}, {25300, 23}} {
+		if got := table.LevelFor(tt.xp); got != tt.level {
+			t.Errorf("LevelFor(%d) = %d, want %d", tt.xp, got, tt.level)
+		}
+	}
+}
+
+func TestLevelBeyondTable(t *testing.T) {
+	table := MustDefault()
+	last := table.Levels[len(table.Levels)-1]
+	l := table.Level(last.Level + 3)
+	if l.Title != last.Title || len(l.Rewards) != 1 || l.Rewards[0].Type != RewardCoins {
+		t.Errorf("Level beyond table = %+v, want last title and coins only", l)
+	}
+}
+
+func TestShopDiscount(t *testing.T) {
+	table := &Table{Version: 1, Levels: []Level{
+		{Level: 1, XP: 0, Title: "a"},
+		{Level: 2, XP: 10, Title: "b", Rewards: []Reward{{Type: RewardShopDiscount, Percent: 10}}},
+		{Level: 3, XP: 30, Title: "c"},
+		{Level: 4, XP: 60, Title: "d", Rewards: []Reward{{Type: RewardShopDiscount, Percent: 5}}},
+	}}
+	for _, tt := range []struct{ level, want int }{{1, 0}, {2, 10}, {4, 10}, {9, 10}} {
+		if got := table.ShopDiscount(tt.level); got != tt.want {
+			t.Errorf("ShopDiscount(%d) = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+	if got := Discounted(45, 10); got != 41 {
+		t.Errorf("Discounted(45, 10) = %d, want 41", got)
+	}
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	table := &Table{Levels: []Level{
+		{Level: 1, XP: 5, Title: "a"},
+		{Level: 3, XP: 100, Title: "", Rewards: []Reward{{Type: "sticker"}, {Type: RewardCoins}}},
+		{Level: 3, XP: 150, Title: "c", Rewards: []Reward{{Type: RewardShopDiscount, Percent: 90}, {Type: RewardTitle, ID: "x"}}},
+	}}
+	err := table.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want errors")
+	}
+	for _, want := range []string{
+		"version must be positive",
+		"levels[0]: first level must start at 0 xp",
+		"levels[1]: level must be 2",
+		"levels[1]: title is required",
+		`unknown reward type "sticker"`,
+		"coins amount must be positive",
+		"levels[2]: xp step must not shrink",
+		"discount percent must be within",
+		"title needs id",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error missing %q:\n%v", want, err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Package level описывает таблицу уровней: порог XP каждого уровня и награды за его
+// достижение (монеты, аватары, предметы маскота, звания, скидка в магазине усилителей
+// для битв со злодеями). Таблица хранится в JSON и проверяется валидатором; за
+// последней строкой пороги продолжаются с тем же приростом шага.
+package level
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"child-bot/api/internal/catalogfile"
+)
+
+// Встроенная таблица: уровни и награды меняются правкой JSON, без миграции
+//
+//go:embed catalog/levels.json
+var catalogFS embed.FS
+
+// FileName имя файла таблицы: встроенного и в каталоге контента на диске
+const FileName = "levels.json"
+
+// Виды наград за уровень
+const (
+	RewardCoins        = "coins"         // монеты (amount)
+	RewardAvatar       = "avatar"        // аватар из магазина (id)
+	RewardMascotItem   = "mascot_item"   // предмет маскота из магазина (id)
+	RewardTitle        = "title"         // звание (id, name)
+	RewardShopDiscount = "shop_discount" // скидка на усилители для битв со злодеями (percent)
+)
+
+// MaxShopDiscount предельная скидка на усилители, %
+const MaxShopDiscount = 50
+
+// Table версионированная таблица уровней
+type Table struct {
+	Version int     `json:"version"`
+	Levels  []Level `json:"levels"`
+}
+
+// Level строка таблицы: XP — сколько всего XP нужно, чтобы достичь уровня
+type Level struct {
+	Level   int      `json:"level"`
+	XP      int      `json:"xp"`
+	Title   string   `json:"title"`
+	Rewards []Reward `json:"rewards,omitempty"`
+}
+
+// Reward награда за уровень
+type Reward struct {
+	Type    string `json:"type"`
+	ID      string `json:"id,omitempty"`      // предмет магазина или звание
+	Name    string `json:"name,omitempty"`    // название звания
+	Amount  int    `json:"amount,omitempty"`  // монеты
+	Percent int    `json:"percent,omitempty"` // скидка
+}
+
+var idPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Default возвращает встроенную таблицу
+func Default() (*Table, error) {
+	return Load("")
+}
+
+// MustDefault встроенная таблица; корректность проверяет тест пакета
+func MustDefault() *Table {
+	t, err := Default()
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Load читает таблицу из файла path, а при пустом пути — встроенную
+func Load(path string) (*Table, error) {
+	return catalogfile.Load[Table](catalogFS, "catalog/"+FileName, path)
+}
+
+// Validate проверяет таблицу и возвращает все найденные ошибки разом
+func (t *Table) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if t.Version <= 0 {
+		add("version must be positive")
+	}
+	// Для продолжения порогов за таблицей нужны хотя бы два шага
+	if len(t.Levels) < 3 {
+		add("table must have at least 3 levels")
+	}
+
+	titles := make(map[string]bool)
+	for i, l := range t.Levels {
+		where := fmt.Sprintf("levels[%d]", i)
+		if l.Level != i+1 {
+			add("%s: level must be %d", where, i+1)
+		}
+		switch {
+		case i == 0 && l.XP != 0:
+			add("%s: first level must start at 0 xp", where)
+		case i > 0 && l.XP <= t.Levels[i-1].XP:
+			add("%s: xp must be greater than previous level", where)
+		case i > 1 && l.XP-t.Levels[i-1].XP < t.Levels[i-1].XP-t.Levels[i-2].XP:
+			add("%s: xp step must not shrink", where)
+		}
+		if l.Title == "" {
+			add("%s: title is required", where)
+		}
+		if i == 0 && len(l.Rewards) > 0 {
+			add("%s: first level has no rewards", where)
+		}
+
+		for j, r := range l.Rewards {
+			rw := fmt.Sprintf("%s.rewards[%d]", where, j)
+			switch r.Type {
+			case RewardCoins:
+				if r.Amount <= 0 {
+					add("%s: coins amount must be positive", rw)
+				}
+			case RewardAvatar, RewardMascotItem:
+				if r.ID == "" || !idPattern.MatchString(r.ID) {
+					add("%s: %s id must match [a-z0-9_]+", rw, r.Type)
+				}
+			case RewardTitle:
+				if r.ID == "" || !idPattern.MatchString(r.ID) || r.Name == "" {
+					add("%s: title needs id matching [a-z0-9_]+ and name", rw)
+				}
+				if titles[r.ID] {
+					add("%s: duplicate title %q", rw, r.ID)
+				}
+				titles[r.ID] = true
+			case RewardShopDiscount:
+				if r.Percent <= 0 || r.Percent > MaxShopDiscount {
+					add("%s: discount percent must be within 1..%d", rw, MaxShopDiscount)
+				}
+			default:
+				add("%s: unknown reward type %q", rw, r.Type)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Threshold сколько всего XP нужно для уровня level. За таблицей каждый следующий шаг
+// больше предыдущего на столько же, на сколько последний шаг таблицы больше предпоследнего.
+func (t *Table) Threshold(level int) int {
+	n := len(t.Levels)
+	if level <= 1 || n == 0 {
+		return 0
+	}
+	if level <= n {
+		return t.Levels[level-1].XP
+	}
+	if n < 3 {
+		return t.Levels[n-1].XP
+	}
+	step := t.Levels[n-1].XP - t.Levels[n-2].XP
+	growth := step - (t.Levels[n-2].XP - t.Levels[n-3].XP)
+	xp := t.Levels[n-1].XP
+	for l := n + 1; l <= level; l++ {
+		step += growth
+		xp += step
+	}
+	return xp
+}
+
+// XPForLevel сколько всего XP нужно, чтобы перейти с уровня level на следующий
+func (t *Table) XPForLevel(level int) int {
+	return t.Threshold(level + 1)
+}
+
+// LevelFor уровень, соответствующий xp
+func (t *Table) LevelFor(xp int) int {
+	level := 1
+	for xp >= t.Threshold(level+1) {
+		level++
+	}
+	return level
+}
+
+// Level строка уровня level. За таблицей — уровень с порогом по продолжению и
+// монетами, как у последнего уровня таблицы; предметы и звания не повторяются.
+func (t *Table) Level(level int) Level {
+	if level >= 1 && level <= len(t.Levels) {
+		return t.Levels[level-1]
+	}
+	l := Level{Level: level, XP: t.Threshold(level)}
+	if n := len(t.Levels); n > 0 {
+		last := t.Levels[n-1]
+		l.Title = last.Title
+		for _, r := range last.Rewards {
+			if r.Type == RewardCoins {
+				l.Rewards = append(l.Rewards, r)
+			}
+		}
+	}
+	return l
+}
+
+// Title звание уровня level
+func (t *Table) Title(level int) string {
+	return t.Level(level).Title
+}
+
+// ShopDiscount скидка на усилители, открытая к уровню level: наибольшая из полученных
+func (t *Table) ShopDiscount(level int) int {
+	best := 0
+	for _, l := range t.Levels {
+		if l.Level > level {
+			break
+		}
+		for _, r := range l.Rewards {
+			if r.Type == RewardShopDiscount && r.Percent > best {
+				best = r.Percent
+			}
+		}
+	}
+	return best
+}
+
+// ItemIDs предметы магазина, которые выдаются за уровни (для проверки каталога при старте)
+func (t *Table) ItemIDs() []string {
+	var ids []string
+	for _, l := range t.Levels {
+		for _, r := range l.Rewards {
+			if r.Type == RewardAvatar || r.Type == RewardMascotItem {
+				ids = append(ids, r.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// Discounted цена со скидкой percent, округлённая вверх до монеты
+func Discounted(price, percent int) int {
+	if percent <= 0 {
+		return price
+	}
+	return price - price*percent/100
+}
@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"embed"
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// Педагогические шаблоны T1..T52 встраиваются в бинарник,
+// чтобы REST API не зависел от рабочей директории и содержимого образа.
+//
+//go:embed templates/T*.json
+var templatesFS embed.FS
+
+// TemplateRegistry — корневая структура JSON-файла шаблона
+type TemplateRegistry struct {
+	Registry RegistryMeta               `json:"template_registry"`
+	Profiles map[string]TemplateProfile `json:"template_profiles"`
+}
+
+// RegistryMeta — метаданные реестра шаблонов
+type RegistryMeta struct {
+	RegistryVersion string     `json:"registry_version"`
+	Scope           string     `json:"scope"`
+	Templates       []Template `json:"templates"`
+}
+
+// Template — описание шаблона (без правил роутинга)
+type Template struct {
+	TemplateCode    string          `json:"template_code"`
+	TemplateID      string          `json:"template_id"`
+	Title           string          `json:"title"`
+	GradeMin        int64           `json:"grade_min"`
+	GradeMax        int64           `json:"grade_max"`
+	FormatsAllowed  []string        `json:"formats_allowed"`
+	PedKeysDefaults PedKeysDefaults `json:"ped_keys_defaults"`
+}
+
+// PedKeysDefaults — тип задачи и тема шаблона по умолчанию
+type PedKeysDefaults struct {
+	TaskType string `json:"task_type"`
+	Topic    string `json:"topic"`
+}
+
+// TemplateProfile — педагогический профиль шаблона (template_profile_core)
+type TemplateProfile struct {
+	MaxHintsDefault    int                    `json:"max_hints_default"`
+	AgeLanguage        AgeLanguage            `json:"age_language"`
+	TeachingPattern    TeachingPattern        `json:"teaching_pattern"`
+	CommonMistakes     []string               `json:"common_mistakes"`
+	DisclosureDefaults map[string]interface{} `json:"disclosure_defaults"`
+}
+
+// AgeLanguage — требования к языку для возраста
+type AgeLanguage struct {
+	GradeMin        int      `json:"grade_min"`
+	GradeMax        int      `json:"grade_max"`
+	Tone            string   `json:"tone"`
+	ComplexityRules []string `json:"complexity_rules"`
+}
+
+// TeachingPattern — лестница подсказок L1 → L3
+type TeachingPattern struct {
+	Goal string    `json:"goal"`
+	L1   HintLevel `json:"l1"`
+	L2   HintLevel `json:"l2"`
+	L3   HintLevel `json:"l3"`
+}
+
+// HintLevel — правила одного уровня подсказки
+type HintLevel struct {
+	Rules       []string `json:"rules"`
+	Format      string   `json:"format"`
+	Forbidden   []string `json:"forbidden"`
+	WhenAllowed string   `json:"when_allowed,omitempty"`
+}
+
+// TemplateEntry — шаблон вместе с его профилем
+type TemplateEntry struct {
+	Template Template
+	Profile  TemplateProfile
+}
+
+// ProfileCore возвращает template_profile_core для передачи в HINT
+func (e *TemplateEntry) ProfileCore() map[string]interface{} {
+	return map[string]interface{}{
+		"template_id":         e.Template.TemplateID,
+		"max_hints_default":   e.Profile.MaxHintsDefault,
+		"age_language":        e.Profile.AgeLanguage,
+		"teaching_pattern":    e.Profile.TeachingPattern,
+		"common_mistakes":     e.Profile.CommonMistakes,
+		"disclosure_defaults": e.Profile.DisclosureDefaults,
+	}
+}
+
+// ProfileCoreJSON возвращает template_profile_core в виде JSON-строки
+func (e *TemplateEntry) ProfileCoreJSON() string {
+	js, err := json.Marshal(e.ProfileCore())
+	if err != nil {
+		return ""
+	}
+	return string(js)
+}
+
+var (
+	templatesOnce  sync.Once
+	templatesIndex map[string]*TemplateEntry
+)
+
+// loadTemplates загружает встроенные шаблоны один раз и индексирует их
+// по template_id и template_code
+func loadTemplates() map[string]*TemplateEntry {
+	templatesOnce.Do(func() {
+		templatesIndex = make(map[string]*TemplateEntry)
+
+		files, err := templatesFS.ReadDir("templates")
+		if err != nil {
+			log.Printf("[llm] Failed to read embedded templates: %v", err)
+			return
+		}
+
+		for _, f := range files {
+			data, err := templatesFS.ReadFile("templates/" + f.Name())
+			if err != nil {
+				log.Printf("[llm] Failed to read template %s: %v", f.Name(), err)
+				continue
+			}
+
+			var reg TemplateRegistry
+			if err := json.Unmarshal(data, &reg); err != nil {
+				log.Printf("[llm] Failed to parse template %s: %v", f.Name(), err)
+				continue
+			}
+
+			for _, t := range reg.Registry.Templates {
+				profile, ok := reg.Profiles[t.TemplateID]
+				if !ok {
+					continue
+				}
+				entry := &TemplateEntry{Template: t, Profile: profile}
+				templatesIndex[t.TemplateID] = entry
+				if t.TemplateCode != "" {
+					templatesIndex[t.TemplateCode] = entry
+				}
+			}
+		}
+
+		log.Printf("[llm] Loaded %d template keys from embedded registry", len(templatesIndex))
+	})
+	return templatesIndex
+}
+
+// FindTemplate ищет шаблон по template_id (T1_pedagogical_template) или template_code (T1)
+func FindTemplate(id string) (*TemplateEntry, bool) {
+	if id == "" {
+		return nil, false
+	}
+	entry, ok := loadTemplates()[id]
+	return entry, ok
+}
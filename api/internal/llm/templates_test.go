@@ -0,0 +1,40 @@
+package llm
+
+import "testing"
+
+func TestFindTemplate(t *testing.T) {
+	byCode, ok := FindTemplate("T1")
+	if !ok {
+		t.Fatal("expected T1 to be found by template_code")
+	}
+
+	byID, ok := FindTemplate(byCode.Template.TemplateID)
+	if !ok {
+		t.Fatalf("expected %s to be found by template_id", byCode.Template.TemplateID)
+	}
+	if byID != byCode {
+		t.Error("template_code and template_id should resolve to the same entry")
+	}
+
+	if len(byCode.Profile.CommonMistakes) == 0 {
+		t.Error("expected common_mistakes to be loaded")
+	}
+	if len(byCode.Profile.TeachingPattern.L1.Rules) == 0 {
+		t.Error("expected teaching_pattern.l1.rules to be loaded")
+	}
+
+	if _, ok := FindTemplate(""); ok {
+		t.Error("empty id should not match")
+	}
+	if _, ok := FindTemplate("T999"); ok {
+		t.Error("unknown id should not match")
+	}
+}
+
+func TestEmbeddedTemplatesLoaded(t *testing.T) {
+	for _, code := range []string{"T1", "T10", "T52"} {
+		if _, ok := FindTemplate(code); !ok {
+			t.Errorf("expected embedded template %s", code)
+		}
+	}
+}
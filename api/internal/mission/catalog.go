@@ -0,0 +1,164 @@
+// Package mission описывает ежедневные миссии: каталог заданий с правилами отбора,
+// выбор трёх миссий на день по истории ребёнка и подсчёт прогресса по событиям
+// попыток и подсказок. Каталог хранится в JSON, проверяется валидатором и
+// синхронизируется в таблицу mission_definitions при старте сервера.
+package mission
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"child-bot/api/internal/catalogfile"
+)
+
+// Встроенный каталог: новая миссия добавляется правкой JSON, без миграции
+//
+//go:embed catalog/missions.json
+var catalogFS embed.FS
+
+// FileName имя файла каталога: встроенного и в каталоге контента на диске
+const FileName = "missions.json"
+
+// Метрики прогресса миссии
+const (
+	MetricTasksCorrect = "tasks_correct"  // верно решённые задачи (с фильтром по предмету)
+	MetricTasksChecked = "tasks_checked"  // отправленные на проверку решения
+	MetricTasksNoHints = "tasks_no_hints" // верно решённые задачи без подсказок
+	MetricErrorsFixed  = "errors_fixed"   // верные перепроверки решений с ошибкой
+	MetricHintsUsed    = "hints_used"     // открытые подсказки
+)
+
+var knownMetrics = map[string]bool{
+	MetricTasksCorrect: true,
+	MetricTasksChecked: true,
+	MetricTasksNoHints: true,
+	MetricErrorsFixed:  true,
+	MetricHintsUsed:    true,
+}
+
+// Catalog версионированный каталог миссий
+type Catalog struct {
+	Version        int          `json:"version"`
+	MissionsPerDay int          `json:"missions_per_day"`
+	AllDoneBonus   Reward       `json:"all_done_bonus"` // за выполнение всех миссий дня
+	Missions       []Definition `json:"missions"`
+}
+
+// Definition миссия каталога
+type Definition struct {
+	ID          string `json:"id"`
+	Group       string `json:"group"`             // в один день — не больше одной миссии группы
+	Metric      string `json:"metric"`            // tasks_correct, tasks_checked, ...
+	Subject     string `json:"subject,omitempty"` // засчитываются только задачи предмета
+	Target      int    `json:"target"`
+	When        Rules  `json:"when"`
+	Weight      int    `json:"weight"` // вес при случайном выборе
+	Reward      Reward `json:"reward"`
+	Icon        string `json:"icon"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// Rules условия, при которых миссию можно выдать ребёнку
+type Rules struct {
+	MinTasks         int    `json:"min_tasks,omitempty"`          // проверок за окно истории не меньше
+	MaxTasks         *int   `json:"max_tasks,omitempty"`          // проверок за окно истории не больше
+	PracticedSubject string `json:"practiced_subject,omitempty"`  // предмет встречался в истории
+	HasUnfixedErrors bool   `json:"has_unfixed_errors,omitempty"` // есть неисправленные ошибки
+	MaxHintRate      *int   `json:"max_hint_rate,omitempty"`      // доля задач с подсказками, %, не больше
+	MaxAccuracy      *int   `json:"max_accuracy,omitempty"`       // доля верных проверок, %, не больше
+}
+
+// Reward награда за миссию
+type Reward struct {
+	Coins int `json:"coins"`
+	XP    int `json:"xp"`
+}
+
+var idPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Default возвращает встроенный каталог
+func Default() (*Catalog, error) {
+	return Load("")
+}
+
+// Load читает каталог из файла path, а при пустом пути — встроенный
+func Load(path string) (*Catalog, error) {
+	return catalogfile.Load[Catalog](catalogFS, "catalog/"+FileName, path)
+}
+
+// Validate проверяет каталог и возвращает все найденные ошибки разом
+func (c *Catalog) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Version <= 0 {
+		add("version must be positive")
+	}
+	if c.MissionsPerDay <= 0 {
+		add("missions_per_day must be positive")
+	}
+	if c.AllDoneBonus.Coins < 0 || c.AllDoneBonus.XP < 0 {
+		add("all_done_bonus must not be negative")
+	}
+
+	ids := make(map[string]bool)
+	groups := make(map[string]bool)
+	for i, d := range c.Missions {
+		where := fmt.Sprintf("missions[%d] %q", i, d.ID)
+		switch {
+		case d.ID == "" || len(d.ID) > 50 || !idPattern.MatchString(d.ID):
+			add("%s: id must match [a-z0-9_]+ and be at most 50 chars", where)
+		case ids[d.ID]:
+			add("%s: duplicate id", where)
+		}
+		ids[d.ID] = true
+
+		if d.Group == "" || !idPattern.MatchString(d.Group) {
+			add("%s: group %q must match [a-z0-9_]+", where, d.Group)
+		}
+		groups[d.Group] = true
+		if !knownMetrics[d.Metric] {
+			add("%s: unknown metric %q", where, d.Metric)
+		}
+		// Подсказка не знает предмета задачи — фильтр по предмету для неё не работает
+		if d.Subject != "" && d.Metric == MetricHintsUsed {
+			add("%s: subject filter is not supported for metric %s", where, d.Metric)
+		}
+		if d.Target <= 0 {
+			add("%s: target must be positive", where)
+		}
+		if d.Weight <= 0 {
+			add("%s: weight must be positive", where)
+		}
+		if d.Reward.Coins < 0 || d.Reward.XP < 0 || d.Reward.Coins+d.Reward.XP == 0 {
+			add("%s: reward must grant coins or xp and not be negative", where)
+		}
+		if d.Title == "" {
+			add("%s: title is required", where)
+		}
+
+		w := d.When
+		if w.MinTasks < 0 || (w.MaxTasks != nil && *w.MaxTasks < w.MinTasks) {
+			add("%s: when.min_tasks/max_tasks are inconsistent", where)
+		}
+		checkPercent := func(name string, pct *int) {
+			if pct != nil && (*pct < 0 || *pct > 100) {
+				add("%s: when.%s must be within 0..100", where, name)
+			}
+		}
+		checkPercent("max_hint_rate", w.MaxHintRate)
+		checkPercent("max_accuracy", w.MaxAccuracy)
+	}
+
+	// Меньше групп, чем миссий в день, — день нельзя собрать полностью
+	if c.MissionsPerDay > 0 && len(groups) < c.MissionsPerDay {
+		add("catalog has %d groups, need at least missions_per_day=%d", len(groups), c.MissionsPerDay)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,146 @@
+package mission
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultCatalogValid(t *testing.T) {
+	c, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("embedded catalog is invalid:\n%v", err)
+	}
+}
+
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	c := &Catalog{
+		Version:        1,
+		MissionsPerDay: 3,
+		Missions: []Definition{
+			{ID: "a", Group: "g", Metric: "unknown", Target: 1, Weight: 1, Reward: Reward{Coins: 1}, Title: "A"},
+			{ID: "a", Group: "g", Metric: MetricHintsUsed, Subject: "math", Target: 0, Weight: 1, Title: "B"},
+		},
+	}
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want errors")
+	}
+	for _, want := range []string{"unknown metric", "duplicate id", "subject filter", "target must be positive", "reward", "need at least missions_per_day"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestEligible(t *testing.T) {
+	h := History{Tasks: 10, Correct: 4, WithHints: 5, Subjects: map[string]int{"math": 10}, UnfixedErrors: 0}
+
+	tests := []struct {
+		name string
+		when Rules
+		want bool
+	}{
+		{"no rules", Rules{}, true},
+		{"enough tasks", Rules{MinTasks: 10}, true},
+		{"too few tasks", Rules{MinTasks: 11}, false},
+		{"newcomers only", Rules{MaxTasks: intPtr(2)}, false},
+		{"practiced subject", Rules{PracticedSubject: "math"}, true},
+		{"unpracticed subject", Rules{PracticedSubject: "ru"}, false},
+		{"needs unfixed errors", Rules{HasUnfixedErrors: true}, false},
+		{"hint rate 50% above 30%", Rules{MaxHintRate: intPtr(30)}, false},
+		{"accuracy 40% within 50%", Rules{MaxAccuracy: intPtr(50)}, true},
+	}
+	for _, tt := range tests {
+		if got := (Definition{When: tt.when}).Eligible(h); got != tt.want {
+			t.Errorf("%s: Eligible = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPick_OnePerGroupAndDeterministic(t *testing.T) {
+	c, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	h := History{Tasks: 20, Correct: 12, WithHints: 4, Subjects: map[string]int{"math": 15, "ru": 5}, UnfixedErrors: 2}
+
+	for _, child := range []string{"child-1", "child-2", "child-3"} {
+		seed := Seed(child, "2026-03-03")
+		picked := Pick(c.Missions, h, seed, c.MissionsPerDay)
+		if len(picked) != c.MissionsPerDay {
+			t.Fatalf("Pick = %d missions, want %d", len(picked), c.MissionsPerDay)
+		}
+		groups := make(map[string]bool)
+		for _, d := range picked {
+			if groups[d.Group] {
+				t.Errorf("Pick returned two missions of group %q: %+v", d.Group, picked)
+			}
+			groups[d.Group] = true
+			if !d.Eligible(h) {
+				t.Errorf("Pick returned ineligible mission %s", d.ID)
+			}
+		}
+
+		again := Pick(c.Missions, h, seed, c.MissionsPerDay)
+		for i := range picked {
+			if picked[i].ID != again[i].ID {
+				t.Fatalf("Pick is not deterministic: %v vs %v", picked, again)
+			}
+		}
+	}
+}
+
+func TestPick_NewcomerGetsFirstCheck(t *testing.T) {
+	c, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	picked := Pick(c.Missions, History{}, Seed("new-child", "2026-03-03"), c.MissionsPerDay)
+	if len(picked) != c.MissionsPerDay {
+		t.Fatalf("newcomer got %d missions, want %d", len(picked), c.MissionsPerDay)
+	}
+	found := false
+	for _, d := range picked {
+		if d.ID == "first_check" {
+			found = true
+		}
+		if d.When.MinTasks > 0 || d.When.HasUnfixedErrors {
+			t.Errorf("newcomer got mission %s that needs history", d.ID)
+		}
+	}
+	if !found {
+		t.Errorf("newcomer missions = %+v, want first_check", picked)
+	}
+}
+
+func TestIncrement(t *testing.T) {
+	correctMath := Action{Kind: ActionCheck, Subject: "math", IsCorrect: true}
+	fixed := Action{Kind: ActionCheck, Subject: "ru", IsCorrect: true, HintsUsed: 2, FixedErrors: true}
+	hint := Action{Kind: ActionHint}
+
+	tests := []struct {
+		metric, subject string
+		action          Action
+		want            int
+	}{
+		{MetricTasksCorrect, "math", correctMath, 1},
+		{MetricTasksCorrect, "ru", correctMath, 0},
+		{MetricTasksChecked, "", Action{Kind: ActionCheck}, 1},
+		{MetricTasksNoHints, "", correctMath, 1},
+		{MetricTasksNoHints, "", fixed, 0},
+		{MetricErrorsFixed, "", fixed, 1},
+		{MetricErrorsFixed, "", correctMath, 0},
+		{MetricHintsUsed, "", hint, 1},
+		{MetricTasksChecked, "", hint, 0},
+	}
+	for _, tt := range tests {
+		if got := Increment(tt.metric, tt.subject, tt.action); got != tt.want {
+			t.Errorf("Increment(%s, %q, %+v) = %d, want %d", tt.metric, tt.subject, tt.action, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package mission
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// HistoryDays окно истории, по которому выбираются миссии
+const HistoryDays = 14
+
+// History сводка проверок ребёнка за последние HistoryDays дней
+type History struct {
+	Tasks         int            // проверенных решений
+	Correct       int            // из них верных
+	WithHints     int            // из них с подсказками
+	Subjects      map[string]int // проверок по предметам
+	UnfixedErrors int            // решений с ошибкой, которые так и не исправлены
+}
+
+// Accuracy доля верных проверок, %
+func (h History) Accuracy() int {
+	if h.Tasks == 0 {
+		return 0
+	}
+	return h.Correct * 100 / h.Tasks
+}
+
+// HintRate доля проверок с подсказками, %
+func (h History) HintRate() int {
+	if h.Tasks == 0 {
+		return 0
+	}
+	return h.WithHints * 100 / h.Tasks
+}
+
+// Eligible можно ли выдать миссию ребёнку с такой историей
+func (d Definition) Eligible(h History) bool {
+	w := d.When
+	if h.Tasks < w.MinTasks {
+		return false
+	}
+	if w.MaxTasks != nil && h.Tasks > *w.MaxTasks {
+		return false
+	}
+	if w.PracticedSubject != "" && h.Subjects[w.PracticedSubject] == 0 {
+		return false
+	}
+	if w.HasUnfixedErrors && h.UnfixedErrors == 0 {
+		return false
+	}
+	if w.MaxHintRate != nil && h.HintRate() > *w.MaxHintRate {
+		return false
+	}
+	if w.MaxAccuracy != nil && h.Accuracy() > *w.MaxAccuracy {
+		return false
+	}
+	return true
+}
+
+// Seed зерно выбора для ребёнка и дня: повторный выбор в тот же день даёт те же миссии
+func Seed(childProfileID, date string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(childProfileID + ":" + date))
+	return int64(h.Sum64())
+}
+
+// Pick выбирает до n миссий из подходящих по истории: случайно с учётом веса,
+// не больше одной миссии каждой группы. Порядок результата — порядок выбора.
+func Pick(defs []Definition, h History, seed int64, n int) []Definition {
+	var pool []Definition
+	for _, d := range defs {
+		if d.Eligible(h) {
+			pool = append(pool, d)
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+	picked := make([]Definition, 0, n)
+	for len(picked) < n && len(pool) > 0 {
+		total := 0
+		for _, d := range pool {
+			total += d.Weight
+		}
+		r := rnd.Intn(total)
+		i := 0
+		for ; r >= pool[i].Weight; i++ {
+			r -= pool[i].Weight
+		}
+		chosen := pool[i]
+		picked = append(picked, chosen)
+
+		rest := pool[:0]
+		for _, d := range pool {
+			if d.Group != chosen.Group {
+				rest = append(rest, d)
+			}
+		}
+		pool = rest
+	}
+	return picked
+}
+
+// Action действие ребёнка, которое двигает миссии
+type Action struct {
+	Kind        string // check — проверка решения, hint — открыта подсказка
+	Subject     string
+	IsCorrect   bool
+	HintsUsed   int
+	FixedErrors bool // верная перепроверка решения, в котором были ошибки
+}
+
+// Виды действий
+const (
+	ActionCheck = "check"
+	ActionHint  = "hint"
+)
+
+// Increment на сколько действие продвигает миссию с метрикой metric и фильтром subject
+func Increment(metric, subject string, a Action) int {
+	if subject != "" && a.Subject != subject {
+		return 0
+	}
+	switch metric {
+	case MetricTasksChecked:
+		if a.Kind == ActionCheck {
+			return 1
+		}
+	case MetricTasksCorrect:
+		if a.Kind == ActionCheck && a.IsCorrect {
+			return 1
+		}
+	case MetricTasksNoHints:
+		if a.Kind == ActionCheck && a.IsCorrect && a.HintsUsed == 0 {
+			return 1
+		}
+	case MetricErrorsFixed:
+		if a.Kind == ActionCheck && a.FixedErrors {
+			return 1
+		}
+	case MetricHintsUsed:
+		if a.Kind == ActionHint {
+			return 1
+		}
+	}
+	return 0
+}
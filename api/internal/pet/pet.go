@@ -0,0 +1,225 @@
+// Package pet — правила питомца-компаньона. Сытость, настроение и бодрость питомца
+// меняются по часам ребёнка: днём он голодает, грустит и устаёт, ночью спит
+// и восстанавливает бодрость. Решённые задачи и победы над злодеями кормят питомца,
+// а стадия эволюции растёт вместе с уровнем ребёнка. Здесь только чистая логика без БД.
+package pet
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"child-bot/api/internal/calendar"
+)
+
+// MaxStat максимум показателя питомца
+const MaxStat = 100
+
+// DefaultType вид питомца по умолчанию (совёнок с главного экрана)
+const DefaultType = "owl"
+
+// Настроение питомца — по нему выбираются картинка и реплика
+const (
+	MoodHappy    = "happy"
+	MoodOK       = "ok"
+	MoodHungry   = "hungry"
+	MoodSad      = "sad"
+	MoodTired    = "tired"
+	MoodSleeping = "sleeping"
+)
+
+// Stats показатели питомца, 0..MaxStat
+type Stats struct {
+	Hunger    int // голод: 0 — сыт, 100 — очень голоден
+	Happiness int
+	Energy    int
+}
+
+// NewStats показатели только что появившегося питомца
+func NewStats() Stats {
+	return Stats{Hunger: 30, Happiness: 70, Energy: 80}
+}
+
+// Rules скорость изменения показателей. Время бодрствования и сна — местное время ребёнка.
+type Rules struct {
+	HungerPerHour        float64 // рост голода за час бодрствования
+	HappinessPerHour     float64 // падение настроения за час бодрствования
+	EnergyPerHour        float64 // расход бодрости за час бодрствования
+	EnergyRestorePerHour float64 // восстановление бодрости за час сна
+	WakeHour             int     // питомец просыпается (местное время)
+	SleepHour            int     // питомец засыпает (местное время)
+	MaxCatchUp           time.Duration
+}
+
+// DefaultRules скорости по умолчанию: без задач питомец проголодается примерно
+// за два дня, а за ночь полностью восстанавливает бодрость
+func DefaultRules() Rules {
+	return Rules{
+		HungerPerHour:        4,
+		HappinessPerHour:     2,
+		EnergyPerHour:        3,
+		EnergyRestorePerHour: 12,
+		WakeHour:             7,
+		SleepHour:            21,
+		MaxCatchUp:           7 * 24 * time.Hour,
+	}
+}
+
+// IsSleeping спит ли питомец в момент t по местному времени ребёнка
+func (r Rules) IsSleeping(t time.Time, loc *time.Location) bool {
+	hour := t.In(loc).Hour()
+	return hour < r.WakeHour || hour >= r.SleepHour
+}
+
+// AwakeDuration сколько времени из промежутка [from, to) питомец бодрствовал.
+// Границы дня берутся из календаря ребёнка, поэтому дни перехода на летнее время
+// считаются правильно.
+func (r Rules) AwakeDuration(from, to time.Time, loc *time.Location) time.Duration {
+	var awake time.Duration
+	for day := calendar.StartOfDay(from, loc); day.Before(to); {
+		y, m, d := day.Date()
+		wake := time.Date(y, m, d, r.WakeHour, 0, 0, 0, loc)
+		sleep := time.Date(y, m, d, r.SleepHour, 0, 0, 0, loc)
+		awake += overlap(from, to, wake, sleep)
+		day = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	}
+	return awake
+}
+
+// Decay показатели питомца в момент to, если в момент from они были s.
+// Промежуток длиннее MaxCatchUp обрезается: к этому времени показатели всё равно на пределе.
+func Decay(s Stats, from, to time.Time, loc *time.Location, r Rules) Stats {
+	if !to.After(from) {
+		return s
+	}
+	if r.MaxCatchUp > 0 && to.Sub(from) > r.MaxCatchUp {
+		from = to.Add(-r.MaxCatchUp)
+	}
+
+	awake := r.AwakeDuration(from, to, loc).Hours()
+	asleep := to.Sub(from).Hours() - awake
+
+	s.Hunger = clamp(s.Hunger + round(awake*r.HungerPerHour))
+	s.Happiness = clamp(s.Happiness - round(awake*r.HappinessPerHour))
+	s.Energy = clamp(s.Energy + round(asleep*r.EnergyRestorePerHour-awake*r.EnergyPerHour))
+	return s
+}
+
+// Food чем кормят питомца; поля — изменения показателей
+type Food struct {
+	Code      string
+	Hunger    int
+	Happiness int
+	Energy    int
+}
+
+// Еда за результаты ребёнка
+var (
+	Meal  = Food{Code: "meal", Hunger: -30, Happiness: 10, Energy: 5}   // правильно решённая задача
+	Snack = Food{Code: "snack", Hunger: -15, Happiness: 5}              // проверка с ошибками — ребёнок всё равно старался
+	Treat = Food{Code: "treat", Hunger: -20, Happiness: 30, Energy: 20} // победа над злодеем
+)
+
+// Feed кормит питомца
+func Feed(s Stats, f Food) Stats {
+	return Stats{
+		Hunger:    clamp(s.Hunger + f.Hunger),
+		Happiness: clamp(s.Happiness + f.Happiness),
+		Energy:    clamp(s.Energy + f.Energy),
+	}
+}
+
+// MoodOf настроение бодрствующего питомца: сначала самое срочное
+func MoodOf(s Stats) string {
+	switch {
+	case s.Hunger >= 70:
+		return MoodHungry
+	case s.Energy <= 20:
+		return MoodTired
+	case s.Happiness <= 30:
+		return MoodSad
+	case s.Happiness >= 70 && s.Hunger <= 40:
+		return MoodHappy
+	default:
+		return MoodOK
+	}
+}
+
+// Stage стадия эволюции питомца
+type Stage struct {
+	Number   int
+	Code     string
+	Name     string
+	MinLevel int // уровень ребёнка, с которого открывается стадия
+}
+
+// Stages стадии эволюции по возрастанию уровня
+var Stages = []Stage{
+	{Number: 1, Code: "egg", Name: "Яйцо", MinLevel: 1},
+	{Number: 2, Code: "baby", Name: "Малыш", MinLevel: 3},
+	{Number: 3, Code: "teen", Name: "Подросток", MinLevel: 6},
+	{Number: 4, Code: "adult", Name: "Взрослый", MinLevel: 10},
+}
+
+// StageForLevel последняя стадия, открытая на уровне level
+func StageForLevel(level int) Stage {
+	stage := Stages[0]
+	for _, s := range Stages {
+		if level >= s.MinLevel {
+			stage = s
+		}
+	}
+	return stage
+}
+
+// StageByNumber стадия по номеру; неизвестный номер — первая стадия
+func StageByNumber(number int) Stage {
+	for _, s := range Stages {
+		if s.Number == number {
+			return s
+		}
+	}
+	return Stages[0]
+}
+
+// NextStage следующая стадия после number; false — питомец уже взрослый
+func NextStage(number int) (Stage, bool) {
+	for _, s := range Stages {
+		if s.Number > number {
+			return s, true
+		}
+	}
+	return Stage{}, false
+}
+
+// ImageURL картинка питомца для вида, стадии и настроения
+func ImageURL(petType string, stage Stage, mood string) string {
+	return fmt.Sprintf("/assets/mascot/%s_%s_%s.png", petType, stage.Code, mood)
+}
+
+func overlap(from, to, start, end time.Time) time.Duration {
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+func round(v float64) int {
+	return int(math.Round(v))
+}
+
+func clamp(v int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > MaxStat {
+		return MaxStat
+	}
+	return v
+}
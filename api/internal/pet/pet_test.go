@@ -0,0 +1,162 @@
+package pet
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%s): %v", name, err)
+	}
+	return loc
+}
+
+func TestAwakeDuration(t *testing.T) {
+	moscow := mustLoad(t, "Europe/Moscow")
+	vladivostok := mustLoad(t, "Asia/Vladivostok")
+	r := DefaultRules()
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		loc      *time.Location
+		want     time.Duration
+	}{
+		{
+			name: "inside one day",
+			from: time.Date(2026, 3, 2, 10, 0, 0, 0, moscow),
+			to:   time.Date(2026, 3, 2, 13, 30, 0, 0, moscow),
+			loc:  moscow,
+			want: 3*time.Hour + 30*time.Minute,
+		},
+		{
+			name: "overnight counts only evening and morning",
+			from: time.Date(2026, 3, 2, 20, 0, 0, 0, moscow),
+			to:   time.Date(2026, 3, 3, 9, 0, 0, 0, moscow),
+			loc:  moscow,
+			want: 3 * time.Hour,
+		},
+		{
+			name: "full day",
+			from: time.Date(2026, 3, 2, 0, 0, 0, 0, moscow),
+			to:   time.Date(2026, 3, 3, 0, 0, 0, 0, moscow),
+			loc:  moscow,
+			want: 14 * time.Hour,
+		},
+		{
+			// 12:00 UTC — это 15:00 в Москве, но 22:00 во Владивостоке: там питомец уже спит
+			name: "same instant in another timezone",
+			from: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+			to:   time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC),
+			loc:  vladivostok,
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.AwakeDuration(tt.from, tt.to, tt.loc); got != tt.want {
+				t.Errorf("AwakeDuration = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecay(t *testing.T) {
+	moscow := mustLoad(t, "Europe/Moscow")
+	r := DefaultRules()
+	start := Stats{Hunger: 20, Happiness: 80, Energy: 50}
+
+	// Пять часов днём: голод +20, настроение -10, бодрость -15
+	got := Decay(start, time.Date(2026, 3, 2, 10, 0, 0, 0, moscow), time.Date(2026, 3, 2, 15, 0, 0, 0, moscow), moscow, r)
+	if want := (Stats{Hunger: 40, Happiness: 70, Energy: 35}); got != want {
+		t.Errorf("daytime Decay = %+v, want %+v", got, want)
+	}
+
+	// Ночь: только восстановление бодрости, не выше максимума
+	got = Decay(start, time.Date(2026, 3, 2, 22, 0, 0, 0, moscow), time.Date(2026, 3, 3, 6, 0, 0, 0, moscow), moscow, r)
+	if want := (Stats{Hunger: 20, Happiness: 80, Energy: MaxStat}); got != want {
+		t.Errorf("night Decay = %+v, want %+v", got, want)
+	}
+
+	// Месяц без задач: показатели упираются в пределы
+	got = Decay(start, time.Date(2026, 2, 1, 12, 0, 0, 0, moscow), time.Date(2026, 3, 2, 12, 0, 0, 0, moscow), moscow, r)
+	if got.Hunger != MaxStat || got.Happiness != 0 {
+		t.Errorf("month Decay = %+v, want hunger %d and happiness 0", got, MaxStat)
+	}
+
+	if got := Decay(start, time.Date(2026, 3, 2, 12, 0, 0, 0, moscow), time.Date(2026, 3, 2, 11, 0, 0, 0, moscow), moscow, r); got != start {
+		t.Errorf("Decay backwards = %+v, want unchanged", got)
+	}
+}
+
+func TestFeedAndMood(t *testing.T) {
+	hungry := Stats{Hunger: 80, Happiness: 40, Energy: 50}
+	if got := MoodOf(hungry); got != MoodHungry {
+		t.Errorf("MoodOf(%+v) = %s, want %s", hungry, got, MoodHungry)
+	}
+
+	fed := Feed(hungry, Meal)
+	if want := (Stats{Hunger: 50, Happiness: 50, Energy: 55}); fed != want {
+		t.Errorf("Feed(Meal) = %+v, want %+v", fed, want)
+	}
+	if got := MoodOf(fed); got != MoodOK {
+		t.Errorf("MoodOf(%+v) = %s, want %s", fed, got, MoodOK)
+	}
+
+	happy := Feed(fed, Treat)
+	if want := (Stats{Hunger: 30, Happiness: 80, Energy: 75}); happy != want {
+		t.Errorf("Feed(Treat) = %+v, want %+v", happy, want)
+	}
+	if got := MoodOf(happy); got != MoodHappy {
+		t.Errorf("MoodOf(%+v) = %s, want %s", happy, got, MoodHappy)
+	}
+
+	if got := Feed(Stats{Hunger: 5, Happiness: 95, Energy: 95}, Treat); got != (Stats{Hunger: 0, Happiness: MaxStat, Energy: MaxStat}) {
+		t.Errorf("Feed past limits = %+v, want clamped", got)
+	}
+
+	for _, tt := range []struct {
+		stats Stats
+		want  string
+	}{
+		{Stats{Hunger: 30, Happiness: 50, Energy: 10}, MoodTired},
+		{Stats{Hunger: 30, Happiness: 20, Energy: 50}, MoodSad},
+	} {
+		if got := MoodOf(tt.stats); got != tt.want {
+			t.Errorf("MoodOf(%+v) = %s, want %s", tt.stats, got, tt.want)
+		}
+	}
+}
+
+func TestStages(t *testing.T) {
+	tests := []struct {
+		level int
+		want  int
+	}{
+		{0, 1},
+		{1, 1},
+		{3, 2},
+		{5, 2},
+		{6, 3},
+		{10, 4},
+		{42, 4},
+	}
+	for _, tt := range tests {
+		if got := StageForLevel(tt.level); got.Number != tt.want {
+			t.Errorf("StageForLevel(%d) = %d, want %d", tt.level, got.Number, tt.want)
+		}
+	}
+
+	if next, ok := NextStage(1); !ok || next.Number != 2 {
+		t.Errorf("NextStage(1) = %+v, %v, want stage 2", next, ok)
+	}
+	if _, ok := NextStage(len(Stages)); ok {
+		t.Error("NextStage(last) ok = true, want false")
+	}
+	if got := StageByNumber(99); got.Number != 1 {
+		t.Errorf("StageByNumber(99) = %d, want 1", got.Number)
+	}
+}
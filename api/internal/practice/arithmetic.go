@@ -0,0 +1,99 @@
+package practice
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+)
+
+// between случайное число из [lo, hi]
+func between(rnd *rand.Rand, lo, hi int) int {
+	return lo + rnd.Intn(hi-lo+1)
+}
+
+func numberItem(prompt string, answer int) Item {
+	return Item{Prompt: prompt, Answer: strconv.Itoa(answer)}
+}
+
+// multiplication пример на таблицу умножения: во 2 классе — на 2..5,
+// в 3 — вся таблица, в 4 — ещё и двузначное на однозначное
+func multiplication(grade int, rnd *rand.Rand) Item {
+	var a, b int
+	switch {
+	case grade <= 2:
+		a, b = between(rnd, 2, 5), between(rnd, 1, 10)
+	case grade == 3:
+		a, b = between(rnd, 2, 9), between(rnd, 2, 9)
+	default:
+		a, b = between(rnd, 2, 9), between(rnd, 2, 19)
+	}
+	if rnd.Intn(2) == 0 {
+		a, b = b, a
+	}
+	return numberItem(fmt.Sprintf("%d × %d", a, b), a*b)
+}
+
+// columnAddition сложение в столбик с переходом через разряд:
+// во 2 классе — двузначные, в 3 — трёхзначные, в 4 — четырёхзначные
+func columnAddition(grade int, rnd *rand.Rand) Item {
+	lo, hi := 10, 99
+	switch {
+	case grade == 3:
+		lo, hi = 100, 999
+	case grade >= 4:
+		lo, hi = 1000, 9999
+	}
+	for {
+		a, b := between(rnd, lo, hi), between(rnd, lo, hi)
+		if grade <= 2 && a+b > 100 {
+			continue
+		}
+		if a%10+b%10 < 10 {
+			continue // без перехода через разряд столбик не нужен
+		}
+		return numberItem(fmt.Sprintf("%d + %d", a, b), a+b)
+	}
+}
+
+// orderOfOperations выражение на порядок действий: во 2 классе — скобки со сложением
+// и вычитанием, с 3 — умножение и деление раньше сложения и вычитания
+func orderOfOperations(grade int, rnd *rand.Rand) Item {
+	if grade <= 2 {
+		b, c := between(rnd, 2, 20), between(rnd, 2, 20)
+		switch rnd.Intn(3) {
+		case 0:
+			a := between(rnd, b+c, 99)
+			return numberItem(fmt.Sprintf("%d − (%d + %d)", a, b, c), a-(b+c))
+		case 1:
+			a := between(rnd, b, 80)
+			return numberItem(fmt.Sprintf("(%d − %d) + %d", a, b, c), a-b+c)
+		default:
+			a, d := between(rnd, 2, 50), b+c // d > c: разность в скобках положительна
+			return numberItem(fmt.Sprintf("%d + (%d − %d)", a, d, c), a+(d-c))
+		}
+	}
+
+	maxA := 50
+	if grade >= 4 {
+		maxA = 100
+	}
+	b, c := between(rnd, 2, 9), between(rnd, 2, 9)
+	switch rnd.Intn(5) {
+	case 0:
+		a := between(rnd, 1, maxA)
+		return numberItem(fmt.Sprintf("%d + %d × %d", a, b, c), a+b*c)
+	case 1:
+		a := between(rnd, b*c, b*c+maxA)
+		return numberItem(fmt.Sprintf("%d − %d × %d", a, b, c), a-b*c)
+	case 2:
+		a := between(rnd, 1, 20)
+		return numberItem(fmt.Sprintf("(%d + %d) × %d", a, b, c), (a+b)*c)
+	case 3:
+		a := b * c
+		d := between(rnd, 1, maxA)
+		return numberItem(fmt.Sprintf("%d : %d + %d", a, b, d), c+d)
+	default:
+		a := between(rnd, 1, maxA)
+		return numberItem(fmt.Sprintf("%d + %d : %d", a, b*c, b), a+c)
+	}
+}
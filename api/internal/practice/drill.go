@@ -0,0 +1,172 @@
+// Package practice генерирует тренировки-разминки (brain breaks): примеры на таблицу
+// умножения, сложение в столбик, порядок действий и пропущенные буквы. Задания
+// создаёт сервер, ответы остаются на сервере и проверяются там же, поэтому клиент
+// не может прислать готовый счёт.
+package practice
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Виды тренировок
+const (
+	KindMultiplication    = "multiplication"      // таблица умножения
+	KindColumnAddition    = "column_addition"     // сложение в столбик
+	KindOrderOfOperations = "order_of_operations" // порядок действий
+	KindOrthography       = "orthography"         // пропущенная буква
+)
+
+// Параметры сессии
+const (
+	ItemsPerSession = 10
+	SessionTTL      = 15 * time.Minute
+	// MinAnswerInterval ответ быстрее этого после предыдущего не засчитывается в счёт
+	MinAnswerInterval = 800 * time.Millisecond
+)
+
+// Drill вид тренировки
+type Drill struct {
+	Kind     string
+	Subject  string
+	Icon     string
+	Title    string
+	GradeMin int
+	Sections []string // разделы карты знаний, слабые места в которых тренирует разминка
+}
+
+var drills = []Drill{
+	{Kind: KindMultiplication, Subject: "math", Icon: "✖️", Title: "Таблица умножения", GradeMin: 2, Sections: []string{"math_mul_div"}},
+	{Kind: KindColumnAddition, Subject: "math", Icon: "➕", Title: "Сложение в столбик", GradeMin: 2, Sections: []string{"math_add_sub"}},
+	{Kind: KindOrderOfOperations, Subject: "math", Icon: "🧮", Title: "Порядок действий", GradeMin: 2, Sections: []string{"math_expressions"}},
+	{Kind: KindOrthography, Subject: "russian", Icon: "✏️", Title: "Пропущенная буква", GradeMin: 1},
+}
+
+// Drills все виды тренировок
+func Drills() []Drill {
+	return append([]Drill(nil), drills...)
+}
+
+// Lookup вид тренировки по kind
+func Lookup(kind string) (Drill, bool) {
+	for _, d := range drills {
+		if d.Kind == kind {
+			return d, true
+		}
+	}
+	return Drill{}, false
+}
+
+// AvailableFor доступна ли тренировка в классе grade; 0 — класс не указан
+func (d Drill) AvailableFor(grade int) bool {
+	return grade == 0 || grade >= d.GradeMin
+}
+
+// Recommend тренировка для самого слабого раздела; weakSections отсортированы от слабейшего
+func Recommend(grade int, weakSections []string) (Drill, bool) {
+	for _, section := range weakSections {
+		for _, d := range drills {
+			if d.AvailableFor(grade) && d.trains(section) {
+				return d, true
+			}
+		}
+	}
+	return Drill{}, false
+}
+
+func (d Drill) trains(section string) bool {
+	for _, s := range d.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// Item задание тренировки. Answer клиенту до ответа не отдаётся.
+type Item struct {
+	Prompt  string
+	Options []string // варианты ответа; пусто — ответ вводится
+	Hint    string   // правило для орфографии, показывается после ответа
+	Answer  string
+}
+
+// Generate создаёт n заданий вида kind для класса grade
+func Generate(kind string, grade, n int, rnd *rand.Rand) ([]Item, error) {
+	grade = clampGrade(grade)
+	switch kind {
+	case KindMultiplication:
+		return unique(n, rnd, func() Item { return multiplication(grade, rnd) }), nil
+	case KindColumnAddition:
+		return unique(n, rnd, func() Item { return columnAddition(grade, rnd) }), nil
+	case KindOrderOfOperations:
+		return unique(n, rnd, func() Item { return orderOfOperations(grade, rnd) }), nil
+	case KindOrthography:
+		return orthography(grade, n, rnd)
+	}
+	return nil, fmt.Errorf("unknown drill kind %q", kind)
+}
+
+// clampGrade класс для генерации: не указанный считается вторым, старшие — четвёртым
+func clampGrade(grade int) int {
+	switch {
+	case grade <= 0:
+		return 2
+	case grade > 4:
+		return 4
+	}
+	return grade
+}
+
+// unique до n заданий без повторов условия
+func unique(n int, rnd *rand.Rand, gen func() Item) []Item {
+	items := make([]Item, 0, n)
+	seen := make(map[string]bool)
+	for tries := 0; len(items) < n && tries < n*20; tries++ {
+		item := gen()
+		if seen[item.Prompt] {
+			continue
+		}
+		seen[item.Prompt] = true
+		items = append(items, item)
+	}
+	return items
+}
+
+// Check совпадает ли ответ ребёнка с верным: пробелы и регистр не важны, ё равна е
+func Check(answer, given string) bool {
+	return normalize(answer) == normalize(given)
+}
+
+func normalize(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), ""))
+	return strings.ReplaceAll(s, "ё", "е")
+}
+
+// Rewards награда за тренировки: за каждый засчитанный верный ответ, не больше дневного лимита
+type Rewards struct {
+	XPPerCorrect    int
+	CoinsPerCorrect int
+	DailyXPCap      int
+	DailyCoinsCap   int
+}
+
+// DefaultRewards награда по умолчанию
+var DefaultRewards = Rewards{XPPerCorrect: 2, CoinsPerCorrect: 1, DailyXPCap: 40, DailyCoinsCap: 20}
+
+// Grant награда за correct верных ответов, если сегодня уже получено earnedXP и earnedCoins
+func (r Rewards) Grant(correct, earnedXP, earnedCoins int) (xp, coins int) {
+	return capped(correct*r.XPPerCorrect, r.DailyXPCap-earnedXP), capped(correct*r.CoinsPerCorrect, r.DailyCoinsCap-earnedCoins)
+}
+
+func capped(amount, left int) int {
+	if left < 0 {
+		left = 0
+	}
+	if amount > left {
+		return left
+	}
+	return amount
+}
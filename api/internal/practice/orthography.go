@@ -0,0 +1,129 @@
+package practice
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"child-bot/api/internal/catalogfile"
+)
+
+// Словарь орфографических разминок: новые слова добавляются правкой JSON
+//
+//go:embed words/orthography.json
+var wordsFS embed.FS
+
+// Gap место пропущенной буквы в слове
+const Gap = "_"
+
+// Word слово с пропущенной буквой
+type Word struct {
+	Text    string   `json:"text"` // слово с Gap вместо буквы
+	Answer  string   `json:"answer"`
+	Options []string `json:"options"`
+	Rule    string   `json:"rule"`
+	Grade   int      `json:"grade"` // с какого класса
+}
+
+var (
+	wordsOnce sync.Once
+	wordBank  []Word
+	wordsErr  error
+)
+
+// Words встроенный словарь
+func Words() ([]Word, error) {
+	wordsOnce.Do(func() {
+		data, err := wordsFS.ReadFile("words/orthography.json")
+		if err != nil {
+			wordsErr = fmt.Errorf("read embedded word bank: %w", err)
+			return
+		}
+		wordBank, wordsErr = ParseWords(data)
+	})
+	return wordBank, wordsErr
+}
+
+// ParseWords разбирает JSON словаря по правилам каталогов контента
+func ParseWords(data []byte) ([]Word, error) {
+	words, err := catalogfile.Parse[[]Word](data)
+	if err != nil {
+		return nil, fmt.Errorf("word bank: %w", err)
+	}
+	return *words, nil
+}
+
+// ValidateWords проверяет словарь и возвращает все найденные ошибки разом
+func ValidateWords(words []Word) error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	seen := make(map[string]bool)
+	for i, w := range words {
+		where := fmt.Sprintf("words[%d] %q", i, w.Text)
+		if strings.Count(w.Text, Gap) != 1 {
+			add("%s: text must contain exactly one %q", where, Gap)
+		}
+		if seen[w.Text] {
+			add("%s: duplicate word", where)
+		}
+		seen[w.Text] = true
+
+		if len(w.Options) < 2 {
+			add("%s: at least two options are required", where)
+		}
+		found, options := false, make(map[string]bool)
+		for _, o := range w.Options {
+			if options[o] {
+				add("%s: duplicate option %q", where, o)
+			}
+			if o == "" {
+				add("%s: empty option", where)
+			}
+			options[o] = true
+			found = found || o == w.Answer
+		}
+		if !found {
+			add("%s: answer %q is not among options", where, w.Answer)
+		}
+		if w.Rule == "" {
+			add("%s: rule is required", where)
+		}
+		if w.Grade < 1 || w.Grade > 4 {
+			add("%s: grade must be 1..4", where)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// orthography до n разных слов не старше класса grade с перемешанными вариантами
+func orthography(grade, n int, rnd *rand.Rand) ([]Item, error) {
+	words, err := Words()
+	if err != nil {
+		return nil, err
+	}
+
+	var pool []Word
+	for _, w := range words {
+		if w.Grade <= grade {
+			pool = append(pool, w)
+		}
+	}
+	rnd.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	if len(pool) > n {
+		pool = pool[:n]
+	}
+
+	items := make([]Item, 0, len(pool))
+	for _, w := range pool {
+		options := append([]string(nil), w.Options...)
+		rnd.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+		items = append(items, Item{Prompt: w.Text, Options: options, Hint: w.Rule, Answer: w.Answer})
+	}
+	return items, nil
+}
@@ -0,0 +1,195 @@
+package practice
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWordBankValid(t *testing.T) {
+	words, err := Words()
+	if err != nil {
+		t.Fatalf("Words() error = %v", err)
+	}
+	if err := ValidateWords(words); err != nil {
+		t.Fatalf("embedded word bank is invalid:\n%v", err)
+	}
+	first := 0
+	for _, w := range words {
+		if w.Grade == 1 {
+			first++
+		}
+	}
+	if first < ItemsPerSession {
+		t.Errorf("grade 1 has %d words, want at least %d for a full session", first, ItemsPerSession)
+	}
+}
+
+func TestValidateWords_ReportsAllErrors(t *testing.T) {
+	err := ValidateWords([]Word{
+		{Text: "молоко", Answer: "о", Options: []string{"о", "а"}, Rule: "r", Grade: 1},
+		{Text: "м_л_ко", Answer: "о", Options: []string{"о"}, Grade: 5},
+		{Text: "с_бака", Answer: "у", Options: []string{"о", "о", ""}, Rule: "r", Grade: 1},
+	})
+	if err == nil {
+		t.Fatal("ValidateWords() = nil, want errors")
+	}
+	for _, want := range []string{"exactly one", "at least two options", "rule is required", "grade must be", "duplicate option", "empty option", "not among options"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateWords() error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+// eval считает выражение разминки: + − × : и скобки, целые числа
+func eval(t *testing.T, expr string) int {
+	t.Helper()
+	r := strings.NewReplacer("−", "-", "×", "*", ":", "/", " ", "")
+	s := r.Replace(expr)
+	pos := 0
+
+	var sum func() int
+	factor := func() int {
+		if s[pos] == '(' {
+			pos++
+			v := sum()
+			pos++ // ')'
+			return v
+		}
+		start := pos
+		for pos < len(s) && s[pos] >= '0' && s[pos] <= '9' {
+			pos++
+		}
+		v, err := strconv.Atoi(s[start:pos])
+		if err != nil {
+			t.Fatalf("bad expression %q", expr)
+		}
+		return v
+	}
+	product := func() int {
+		v := factor()
+		for pos < len(s) && (s[pos] == '*' || s[pos] == '/') {
+			op := s[pos]
+			pos++
+			w := factor()
+			if op == '*' {
+				v *= w
+			} else {
+				if w == 0 || v%w != 0 {
+					t.Fatalf("%q: division is not exact", expr)
+				}
+				v /= w
+			}
+		}
+		return v
+	}
+	sum = func() int {
+		v := product()
+		for pos < len(s) && (s[pos] == '+' || s[pos] == '-') {
+			op := s[pos]
+			pos++
+			if op == '+' {
+				v += product()
+			} else {
+				v -= product()
+			}
+		}
+		return v
+	}
+	return sum()
+}
+
+func TestGenerate_ArithmeticAnswers(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, kind := range []string{KindMultiplication, KindColumnAddition, KindOrderOfOperations} {
+		for grade := 0; grade <= 5; grade++ {
+			items, err := Generate(kind, grade, ItemsPerSession, rnd)
+			if err != nil {
+				t.Fatalf("Generate(%s, %d) error = %v", kind, grade, err)
+			}
+			if len(items) != ItemsPerSession {
+				t.Fatalf("Generate(%s, %d) = %d items, want %d", kind, grade, len(items), ItemsPerSession)
+			}
+			for _, item := range items {
+				got := eval(t, item.Prompt)
+				if strconv.Itoa(got) != item.Answer || got < 0 {
+					t.Errorf("%s grade %d: %q answer = %s, want %d (non-negative)", kind, grade, item.Prompt, item.Answer, got)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerate_Orthography(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	items, err := Generate(KindOrthography, 1, ItemsPerSession, rnd)
+	if err != nil {
+		t.Fatalf("Generate error = %v", err)
+	}
+	words, _ := Words()
+	grades := make(map[string]int)
+	for _, w := range words {
+		grades[w.Text] = w.Grade
+	}
+	for _, item := range items {
+		if grades[item.Prompt] != 1 {
+			t.Errorf("%q is not a grade 1 word", item.Prompt)
+		}
+		if len(item.Options) < 2 || item.Hint == "" {
+			t.Errorf("%q: options %v, hint %q", item.Prompt, item.Options, item.Hint)
+		}
+	}
+	if _, err := Generate("unknown", 1, 1, rnd); err == nil {
+		t.Error("Generate(unknown) error = nil")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		answer, given string
+		want          bool
+	}{
+		{"56", " 56 ", true},
+		{"1 024", "1024", true},
+		{"е", "Ё", true},
+		{"ст", "с", false},
+		{"56", "", false},
+	}
+	for _, tt := range tests {
+		if got := Check(tt.answer, tt.given); got != tt.want {
+			t.Errorf("Check(%q, %q) = %v, want %v", tt.answer, tt.given, got, tt.want)
+		}
+	}
+}
+
+func TestRewardsGrant_DailyCap(t *testing.T) {
+	r := Rewards{XPPerCorrect: 2, CoinsPerCorrect: 1, DailyXPCap: 40, DailyCoinsCap: 20}
+	tests := []struct {
+		correct, earnedXP, earnedCoins int
+		wantXP, wantCoins              int
+	}{
+		{10, 0, 0, 20, 10},
+		{10, 30, 15, 10, 5},
+		{10, 40, 20, 0, 0},
+		{10, 50, 0, 0, 10}, // лимит уменьшили: уже полученное не отнимается
+	}
+	for _, tt := range tests {
+		xp, coins := r.Grant(tt.correct, tt.earnedXP, tt.earnedCoins)
+		if xp != tt.wantXP || coins != tt.wantCoins {
+			t.Errorf("Grant(%d, %d, %d) = %d, %d; want %d, %d", tt.correct, tt.earnedXP, tt.earnedCoins, xp, coins, tt.wantXP, tt.wantCoins)
+		}
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	if d, ok := Recommend(3, []string{"math_geometry", "math_expressions", "math_mul_div"}); !ok || d.Kind != KindOrderOfOperations {
+		t.Errorf("Recommend = %v, %v; want order_of_operations", d.Kind, ok)
+	}
+	if _, ok := Recommend(1, []string{"math_mul_div"}); ok {
+		t.Error("Recommend for grade 1 offered multiplication")
+	}
+	if _, ok := Recommend(3, nil); ok {
+		t.Error("Recommend without weak sections = ok")
+	}
+}
@@ -0,0 +1,78 @@
+// Package review планирует повторение ошибок по алгоритму SM-2 (SuperMemo):
+// задача, решённая с ошибкой или с третьей подсказкой, возвращается к ребёнку
+// через растущие интервалы, пока не закрепится. Пакет без зависимостей от БД.
+package review
+
+import "math"
+
+// Причины попадания задачи в очередь повторения (как reason у ANALOGUE)
+const (
+	ReasonAfterIncorrect = "after_incorrect"
+	ReasonAfter3Hints    = "after_3_hints"
+)
+
+// Качество ответа по шкале SM-2
+const (
+	QualityFailed  = 1 // неверно
+	QualityHard    = 3 // верно, но с трудом
+	QualityPerfect = 5 // верно
+)
+
+const (
+	// DefaultEaseFactor начальный коэффициент лёгкости
+	DefaultEaseFactor = 2.5
+	// MinEaseFactor минимальный коэффициент лёгкости
+	MinEaseFactor = 1.3
+	// RetireIntervalDays задача закреплена, если следующий интервал не меньше этого
+	RetireIntervalDays = 30
+)
+
+// Schedule состояние повторения задачи
+type Schedule struct {
+	Repetitions  int     // успешных повторений подряд
+	IntervalDays int     // через сколько дней следующее повторение
+	EaseFactor   float64 // коэффициент лёгкости
+}
+
+// Initial расписание новой задачи: первое повторение на следующий день
+func Initial() Schedule {
+	return Schedule{IntervalDays: 1, EaseFactor: DefaultEaseFactor}
+}
+
+// Next расписание после повторения с качеством q (0..5).
+// Ошибка (q < 3) сбрасывает серию: задача вернётся завтра. Верный ответ удлиняет интервал:
+// 1 день, 6 дней, дальше предыдущий интервал × коэффициент лёгкости.
+func Next(s Schedule, q int) Schedule {
+	if q < 0 {
+		q = 0
+	}
+	if q > 5 {
+		q = 5
+	}
+	if s.EaseFactor < MinEaseFactor {
+		s.EaseFactor = DefaultEaseFactor
+	}
+
+	ef := s.EaseFactor + (0.1 - float64(5-q)*(0.08+float64(5-q)*0.02))
+	ef = math.Max(MinEaseFactor, math.Round(ef*100)/100)
+
+	if q < 3 {
+		return Schedule{Repetitions: 0, IntervalDays: 1, EaseFactor: ef}
+	}
+
+	next := Schedule{Repetitions: s.Repetitions + 1, EaseFactor: ef}
+	switch next.Repetitions {
+	case 1:
+		next.IntervalDays = 1
+	case 2:
+		next.IntervalDays = 6
+	default:
+		next.IntervalDays = int(math.Round(float64(s.IntervalDays) * ef))
+	}
+	return next
+}
+
+// Retired задача закреплена и больше не повторяется
+func Retired(s Schedule) bool {
+	return s.IntervalDays >= RetireIntervalDays
+}
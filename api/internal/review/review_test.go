@@ -0,0 +1,55 @@
+package review
+
+import "testing"
+
+func TestNext_SuccessfulReviewsGrowInterval(t *testing.T) {
+	s := Initial()
+	var intervals []int
+	for i := 0; i < 4; i++ {
+		s = Next(s, QualityPerfect)
+		intervals = append(intervals, s.IntervalDays)
+	}
+
+	// EF растёт на 0.1 за каждый верный ответ: 2.6, 2.7, 2.8, 2.9; 6×2.8 ≈ 17, 17×2.9 ≈ 49
+	want := []int{1, 6, 17, 49}
+	for i := range want {
+		if intervals[i] != want[i] {
+			t.Fatalf("intervals = %v, want %v", intervals, want)
+		}
+	}
+	if s.Repetitions != 4 || s.EaseFactor != 2.9 {
+		t.Errorf("schedule = %+v, want 4 repetitions, EF 2.9", s)
+	}
+	if !Retired(s) {
+		t.Errorf("Retired(%+v) = false, want true", s)
+	}
+}
+
+func TestNext_FailureResetsSeries(t *testing.T) {
+	s := Schedule{Repetitions: 3, IntervalDays: 16, EaseFactor: 2.5}
+	s = Next(s, QualityFailed)
+
+	// EF: 2.5 + (0.1 - 4*(0.08 + 4*0.02)) = 1.96
+	if s.Repetitions != 0 || s.IntervalDays != 1 || s.EaseFactor != 1.96 {
+		t.Errorf("Next after failure = %+v, want {0 1 1.96}", s)
+	}
+	if Retired(s) {
+		t.Error("failed item must not be retired")
+	}
+
+	// Коэффициент не опускается ниже минимума
+	for i := 0; i < 10; i++ {
+		s = Next(s, QualityFailed)
+	}
+	if s.EaseFactor != MinEaseFactor {
+		t.Errorf("EF after many failures = %v, want %v", s.EaseFactor, MinEaseFactor)
+	}
+}
+
+func TestNext_HardAnswerKeepsEaseLower(t *testing.T) {
+	hard := Next(Next(Initial(), QualityHard), QualityHard)
+	perfect := Next(Next(Initial(), QualityPerfect), QualityPerfect)
+	if hard.IntervalDays != 6 || hard.EaseFactor >= perfect.EaseFactor {
+		t.Errorf("hard = %+v, perfect = %+v, want same interval and lower EF for hard", hard, perfect)
+	}
+}
@@ -0,0 +1,161 @@
+// Package reward политика начислений против «фарма» XP и монет: дневные лимиты по
+// источникам, убывающая награда за повтор одной и той же задачи и никакого XP за
+// подсказки, когда ответ уже показан. Факты о ребёнке и задаче собирает store,
+// здесь только решение, сколько начислить.
+package reward
+
+import (
+	"strings"
+
+	"child-bot/api/internal/util"
+)
+
+// Валюты (совпадают с валютами журнала)
+const (
+	CurrencyCoins = "coins"
+	CurrencyXP    = "xp"
+)
+
+// Причины урезанной награды
+const (
+	ReasonDailyCap     = "daily_cap"     // исчерпан дневной лимит источника
+	ReasonRepeatedTask = "repeated_task" // та же задача уже решалась
+	ReasonAnswerShown  = "answer_shown"  // ответ уже показан
+)
+
+// Cap дневной лимит источника в валюте
+type Cap struct {
+	Source   string
+	Currency string
+	Daily    int
+}
+
+// Policy правила начислений
+type Policy struct {
+	Caps []Cap
+	// RepeatPercents доля награды за первую, вторую, ... попытку той же задачи;
+	// дальше действует последнее значение
+	RepeatPercents []int
+	// AnswerShownSources источники, которые ничего не дают после показа ответа
+	AnswerShownSources []string
+	// SuspiciousPerDay столько урезанных начислений одной причины за день — повод
+	// показать день родителю
+	SuspiciousPerDay int
+}
+
+// DefaultPolicy политика по умолчанию
+var DefaultPolicy = Policy{
+	Caps: []Cap{
+		{Source: "attempt", Currency: CurrencyXP, Daily: 500},
+		{Source: "attempt", Currency: CurrencyCoins, Daily: 50},
+		{Source: "hint", Currency: CurrencyXP, Daily: 100},
+		{Source: "achievement", Currency: CurrencyXP, Daily: 300},
+	},
+	RepeatPercents:     []int{100, 50, 0},
+	AnswerShownSources: []string{"hint"},
+	SuspiciousPerDay:   5,
+}
+
+// Request начисление, которое нужно оценить
+type Request struct {
+	Source      string
+	Currency    string
+	Amount      int
+	EarnedToday int  // уже начислено сегодня из этого источника в этой валюте
+	Repeats     int  // сколько раз ребёнок уже брался за ту же задачу; 0 — впервые
+	AnswerShown bool // ответ на задачу уже показан
+}
+
+// Decision итог: сколько начислить и почему меньше запрошенного
+type Decision struct {
+	Amount int
+	Reason string // пусто — начислено полностью
+}
+
+// Reduced начислено меньше запрошенного
+func (d Decision) Reduced(requested int) bool {
+	return d.Amount < requested
+}
+
+// Apply оценивает начисление. Правила применяются по очереди: показанный ответ,
+// повтор задачи, дневной лимит; причина — первое правило, которое урезало награду.
+func (p Policy) Apply(r Request) Decision {
+	if r.Amount <= 0 {
+		return Decision{Amount: r.Amount}
+	}
+	d := Decision{Amount: r.Amount}
+	cut := func(amount int, reason string) {
+		if amount < d.Amount {
+			d.Amount = amount
+			if d.Reason == "" {
+				d.Reason = reason
+			}
+		}
+	}
+
+	if r.AnswerShown && contains(p.AnswerShownSources, r.Source) {
+		cut(0, ReasonAnswerShown)
+	}
+	if percent, ok := p.repeatPercent(r.Repeats); ok {
+		cut(d.Amount*percent/100, ReasonRepeatedTask)
+	}
+	if limit, ok := p.dailyCap(r.Source, r.Currency); ok {
+		left := limit - r.EarnedToday
+		if left < 0 {
+			left = 0
+		}
+		cut(left, ReasonDailyCap)
+	}
+	return d
+}
+
+// Suspicious стоит ли показать родителю день с events урезанными начислениями одной причины
+func (p Policy) Suspicious(events int) bool {
+	return p.SuspiciousPerDay > 0 && events >= p.SuspiciousPerDay
+}
+
+func (p Policy) repeatPercent(repeats int) (int, bool) {
+	if repeats <= 0 || len(p.RepeatPercents) == 0 {
+		return 0, false
+	}
+	if repeats >= len(p.RepeatPercents) {
+		return p.RepeatPercents[len(p.RepeatPercents)-1], true
+	}
+	return p.RepeatPercents[repeats], true
+}
+
+func (p Policy) dailyCap(source, currency string) (int, bool) {
+	for _, c := range p.Caps {
+		if c.Source == source && c.Currency == currency {
+			return c.Daily, true
+		}
+	}
+	return 0, false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskHash отпечаток условия задачи: регистр, пробелы и ё не важны, поэтому повторное
+// фото той же задачи узнаётся по распознанному тексту. Пустой текст — пустой отпечаток.
+func TaskHash(text string) string {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	if text == "" {
+		return ""
+	}
+	return util.SHA256Hex([]byte(strings.ReplaceAll(text, "ё", "е")))
+}
+
+// ImageHash отпечаток присланного изображения: ловит повторную отправку того же файла
+func ImageHash(data string) string {
+	if data == "" {
+		return ""
+	}
+	return util.SHA256Hex([]byte(data))
+}
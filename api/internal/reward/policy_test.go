@@ -0,0 +1,49 @@
+package reward
+
+import "testing"
+
+func TestPolicyApply(t *testing.T) {
+	p := DefaultPolicy
+	tests := []struct {
+		name       string
+		req        Request
+		wantAmount int
+		wantReason string
+	}{
+		{"first try", Request{Source: "attempt", Currency: CurrencyXP, Amount: 50}, 50, ""},
+		{"second try of the same task", Request{Source: "attempt", Currency: CurrencyXP, Amount: 50, Repeats: 1}, 25, ReasonRepeatedTask},
+		{"third try of the same task", Request{Source: "hint", Currency: CurrencyXP, Amount: 10, Repeats: 2}, 0, ReasonRepeatedTask},
+		{"many tries keep the last percent", Request{Source: "attempt", Currency: CurrencyCoins, Amount: 5, Repeats: 7}, 0, ReasonRepeatedTask},
+		{"hint after answer shown", Request{Source: "hint", Currency: CurrencyXP, Amount: 10, AnswerShown: true}, 0, ReasonAnswerShown},
+		{"answer shown does not affect checks", Request{Source: "attempt", Currency: CurrencyXP, Amount: 50, AnswerShown: true}, 50, ""},
+		{"partly over daily cap", Request{Source: "hint", Currency: CurrencyXP, Amount: 10, EarnedToday: 95}, 5, ReasonDailyCap},
+		{"over daily cap", Request{Source: "achievement", Currency: CurrencyXP, Amount: 50, EarnedToday: 320}, 0, ReasonDailyCap},
+		{"repeat and cap: first rule wins", Request{Source: "attempt", Currency: CurrencyXP, Amount: 50, Repeats: 1, EarnedToday: 490}, 10, ReasonRepeatedTask},
+		{"source without cap", Request{Source: "daily_login", Currency: CurrencyXP, Amount: 30, EarnedToday: 1000}, 30, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := p.Apply(tt.req)
+			if d.Amount != tt.wantAmount || d.Reason != tt.wantReason {
+				t.Errorf("Apply() = %+v, want %d (%q)", d, tt.wantAmount, tt.wantReason)
+			}
+			if d.Reduced(tt.req.Amount) != (tt.wantAmount < tt.req.Amount) {
+				t.Errorf("Reduced() = %v", d.Reduced(tt.req.Amount))
+			}
+		})
+	}
+}
+
+func TestTaskHash(t *testing.T) {
+	a := TaskHash("Реши пример:  12 + ЁЖ")
+	b := TaskHash(" реши пример: 12 + еж ")
+	if a == "" || a != b {
+		t.Errorf("TaskHash differs for the same task: %q vs %q", a, b)
+	}
+	if TaskHash("  ") != "" {
+		t.Error("TaskHash of blank text should be empty")
+	}
+	if TaskHash("12 + 3") == TaskHash("12 + 4") {
+		t.Error("TaskHash should differ for different tasks")
+	}
+}
@@ -0,0 +1,245 @@
+// Package seasonal описывает сезонные события: ограниченные по датам праздники
+// (Новый год, 1 сентября) со своими злодеями, достижениями и предметами магазина.
+// Каталог хранится в JSON, проверяется валидатором и синхронизируется в БД при старте
+// сервера; всё, что принадлежит событию, доступно детям только в его даты.
+package seasonal
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"child-bot/api/internal/catalogfile"
+)
+
+// Встроенный каталог: новое событие добавляется правкой JSON, без миграции
+//
+//go:embed catalog/events.json
+var catalogFS embed.FS
+
+// FileName имя файла каталога: встроенного и в каталоге контента на диске
+const FileName = "events.json"
+
+// dateLayout формат дат события (день по календарю ребёнка)
+const dateLayout = "2006-01-02"
+
+// maxEventIDLen длина id события: счётчик достижения event:<id>:<тип> должен влезть в 50 символов
+const maxEventIDLen = 24
+
+// CategorySeasonal категория (achievements.type) достижений события
+const CategorySeasonal = "seasonal"
+
+// Требования, которые можно считать внутри события: только накопительные счётчики
+var eventRequirements = map[string]bool{
+	"tasks_correct":     true,
+	"tasks_no_hints":    true,
+	"errors_found":      true,
+	"hints_used":        true,
+	"villains_defeated": true,
+}
+
+var rewardTypes = map[string]bool{"coins": true, "xp": true, "sticker": true, "badge": true}
+
+var shopCategories = map[string]bool{"avatar": true, "mascot_item": true}
+
+// Catalog версионированный каталог событий
+type Catalog struct {
+	Version int     `json:"version"`
+	Events  []Event `json:"events"`
+}
+
+// Event сезонное событие
+type Event struct {
+	ID           string        `json:"id"`
+	Title        string        `json:"title"`
+	Description  string        `json:"description"`
+	Icon         string        `json:"icon"`
+	ThemeColor   string        `json:"theme_color"`
+	StartDate    string        `json:"start_date"` // YYYY-MM-DD, включительно
+	EndDate      string        `json:"end_date"`   // YYYY-MM-DD, включительно
+	Villains     []Villain     `json:"villains"`
+	Achievements []Achievement `json:"achievements"`
+	ShopItems    []ShopItem    `json:"shop_items"`
+}
+
+// Villain злодей события: в свои дни недели заменяет злодея ежедневной ротации
+type Villain struct {
+	ID                   string `json:"id"`
+	Name                 string `json:"name"`
+	Description          string `json:"description"`
+	ImageURL             string `json:"image_url"`
+	Level                int    `json:"level"`
+	MaxHP                int    `json:"max_hp"`
+	DamagePerCorrectTask int    `json:"damage_per_correct_task"`
+	RewardCoins          int    `json:"reward_coins"`
+	Weekdays             []int  `json:"weekdays"` // 1 — понедельник, 7 — воскресенье
+}
+
+// Achievement достижение события: засчитываются только действия в даты события
+type Achievement struct {
+	ID          string      `json:"id"`
+	Icon        string      `json:"icon"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	RewardName  string      `json:"reward_name"`
+	Requirement Requirement `json:"requirement"`
+	Reward      Reward      `json:"reward"`
+	Priority    int         `json:"priority"`
+}
+
+// Requirement условие достижения
+type Requirement struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+// Reward награда за достижение
+type Reward struct {
+	Type   string `json:"type"`             // coins, xp, sticker, badge
+	Amount int    `json:"amount,omitempty"` // для coins и xp
+}
+
+// ShopItem предмет магазина, который продаётся только во время события
+type ShopItem struct {
+	ID          string `json:"id"`
+	Category    string `json:"category"` // avatar, mascot_item
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Price       int    `json:"price"`
+	MinLevel    int    `json:"min_level"`
+}
+
+var idPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Default возвращает встроенный каталог
+func Default() (*Catalog, error) {
+	return Load("")
+}
+
+// Load читает каталог из файла path, а при пустом пути — встроенный
+func Load(path string) (*Catalog, error) {
+	return catalogfile.Load[Catalog](catalogFS, "catalog/"+FileName, path)
+}
+
+// Validate проверяет каталог и возвращает все найденные ошибки разом
+func (c *Catalog) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Version <= 0 {
+		add("version must be positive")
+	}
+
+	// id злодеев, достижений и предметов уникальны во всём каталоге
+	ids := make(map[string]string)
+	checkID := func(where, what, id string, maxLen int) {
+		switch {
+		case id == "" || len(id) > maxLen || !idPattern.MatchString(id):
+			add("%s: %s id %q must match [a-z0-9_]+ and be at most %d chars", where, what, id, maxLen)
+		case ids[what+":"+id] != "":
+			add("%s: %s id %q already used by %s", where, what, id, ids[what+":"+id])
+		}
+		ids[what+":"+id] = where
+	}
+
+	for i, e := range c.Events {
+		where := fmt.Sprintf("events[%d] %q", i, e.ID)
+		checkID(where, "event", e.ID, maxEventIDLen)
+		if e.Title == "" {
+			add("%s: title is required", where)
+		}
+		start, errStart := time.Parse(dateLayout, e.StartDate)
+		end, errEnd := time.Parse(dateLayout, e.EndDate)
+		switch {
+		case errStart != nil || errEnd != nil:
+			add("%s: start_date and end_date must be YYYY-MM-DD", where)
+		case end.Before(start):
+			add("%s: end_date is before start_date", where)
+		}
+
+		for j, v := range e.Villains {
+			vw := fmt.Sprintf("%s.villains[%d]", where, j)
+			checkID(vw, "villain", v.ID, 100)
+			if v.Name == "" || v.ImageURL == "" {
+				add("%s: name and image_url are required", vw)
+			}
+			if v.MaxHP <= 0 || v.Level <= 0 || v.DamagePerCorrectTask <= 0 || v.RewardCoins < 0 {
+				add("%s: max_hp, level and damage_per_correct_task must be positive", vw)
+			}
+			if len(v.Weekdays) == 0 {
+				add("%s: at least one weekday is required", vw)
+			}
+			for _, d := range v.Weekdays {
+				if d < 1 || d > 7 {
+					add("%s: weekday %d must be within 1..7", vw, d)
+				}
+			}
+		}
+
+		for j, a := range e.Achievements {
+			aw := fmt.Sprintf("%s.achievements[%d]", where, j)
+			checkID(aw, "achievement", a.ID, 100)
+			if a.Title == "" {
+				add("%s: title is required", aw)
+			}
+			if !eventRequirements[a.Requirement.Type] {
+				add("%s: requirement %q cannot be counted within an event", aw, a.Requirement.Type)
+			}
+			if a.Requirement.Value <= 0 {
+				add("%s: requirement value must be positive", aw)
+			}
+			if !rewardTypes[a.Reward.Type] {
+				add("%s: unknown reward type %q", aw, a.Reward.Type)
+			}
+			if (a.Reward.Type == "coins" || a.Reward.Type == "xp") && a.Reward.Amount <= 0 {
+				add("%s: %s reward needs a positive amount", aw, a.Reward.Type)
+			}
+		}
+
+		for j, it := range e.ShopItems {
+			sw := fmt.Sprintf("%s.shop_items[%d]", where, j)
+			checkID(sw, "shop item", it.ID, 100)
+			if !shopCategories[it.Category] {
+				add("%s: category %q is not allowed for event items", sw, it.Category)
+			}
+			if it.Name == "" || it.Icon == "" {
+				add("%s: name and icon are required", sw)
+			}
+			if it.Price <= 0 || it.MinLevel < 1 {
+				add("%s: price must be positive and min_level at least 1", sw)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// OpenOn идёт ли событие в день date (YYYY-MM-DD)
+func (e Event) OpenOn(date string) bool {
+	return e.StartDate <= date && date <= e.EndDate
+}
+
+// DaysLeft сколько дней события осталось, включая день date
+func (e Event) DaysLeft(date string) int {
+	day, err1 := time.Parse(dateLayout, date)
+	end, err2 := time.Parse(dateLayout, e.EndDate)
+	if err1 != nil || err2 != nil || day.After(end) {
+		return 0
+	}
+	return int(end.Sub(day).Hours()/24) + 1
+}
+
+// CounterType счётчик требования внутри события: действия вне дат события его не двигают
+func CounterType(eventID, requirementType string) string {
+	return "event:" + eventID + ":" + requirementType
+}
+
+// CountedRequirement можно ли считать требование внутри события
+func CountedRequirement(requirementType string) bool {
+	return eventRequirements[requirementType]
+}
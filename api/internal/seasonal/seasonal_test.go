@@ -0,0 +1,73 @@
+package seasonal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultCatalogValid(t *testing.T) {
+	c, err := Default()
+	if err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("embedded catalog is invalid:\n%v", err)
+	}
+	for _, e := range c.Events {
+		for _, a := range e.Achievements {
+			if got := CounterType(e.ID, a.Requirement.Type); len(got) > 50 {
+				t.Errorf("counter %q does not fit requirement_type VARCHAR(50)", got)
+			}
+		}
+	}
+}
+
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	c := &Catalog{
+		Version: 1,
+		Events: []Event{
+			{
+				ID: "winter", Title: "Зима", StartDate: "2026-12-31", EndDate: "2026-12-01",
+				Villains: []Villain{{ID: "frost", Name: "Мороз", ImageURL: "/x.png", Level: 1, MaxHP: 10, DamagePerCorrectTask: 5, Weekdays: []int{8}}},
+				Achievements: []Achievement{
+					{ID: "streaker", Title: "Серия", Requirement: Requirement{Type: "streak_days", Value: 3}, Reward: Reward{Type: "sticker"}},
+				},
+				ShopItems: []ShopItem{{ID: "boost", Category: "power_up", Name: "Удар", Icon: "⚡", Price: 10, MinLevel: 1}},
+			},
+			{ID: "winter", Title: "Снова зима", StartDate: "2027-01-01", EndDate: "2027-01-02"},
+		},
+	}
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want errors")
+	}
+	for _, want := range []string{"end_date is before start_date", "weekday 8", "cannot be counted within an event", "not allowed for event items", "already used"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestEvent_Window(t *testing.T) {
+	e := Event{StartDate: "2026-12-20", EndDate: "2027-01-10"}
+
+	tests := []struct {
+		date     string
+		open     bool
+		daysLeft int
+	}{
+		{"2026-12-19", false, 23},
+		{"2026-12-20", true, 22},
+		{"2026-12-31", true, 11},
+		{"2027-01-10", true, 1},
+		{"2027-01-11", false, 0},
+	}
+	for _, tt := range tests {
+		if got := e.OpenOn(tt.date); got != tt.open {
+			t.Errorf("OpenOn(%s) = %v, want %v", tt.date, got, tt.open)
+		}
+		if got := e.DaysLeft(tt.date); got != tt.daysLeft {
+			t.Errorf("DaysLeft(%s) = %d, want %d", tt.date, got, tt.daysLeft)
+		}
+	}
+}
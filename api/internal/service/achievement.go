@@ -2,14 +2,21 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strconv"
+	"time"
 
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/seasonal"
 	"child-bot/api/internal/store"
 )
 
 const XPForAchievement = 50
 
-// AchievementService бизнес-логика для достижений
+// AchievementService движок достижений: подписан на доменные события
+// и продвигает счётчики требований инкрементально
 type AchievementService struct {
 	store *store.Store
 }
@@ -19,204 +26,129 @@ func NewAchievementService(store *store.Store) *AchievementService {
 	return &AchievementService{store: store}
 }
 
-// CheckStreakAchievements проверяет достижения за streak (дни подряд)
-func (s *AchievementService) CheckStreakAchievements(ctx context.Context, childProfileID string) error {
-	streakDays, err := s.store.GetCurrentStreakDays(ctx, childProfileID)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to get streak days for %s: %v", childProfileID, err)
-		return err
-	}
-
-	unlockedIDs, err := s.store.CheckAndUpdateAchievementsByType(ctx, childProfileID, "streak_days", streakDays)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to check streak achievements for %s: %v", childProfileID, err)
-		return err
-	}
-
-	if len(unlockedIDs) > 0 {
-		log.Printf("[AchievementService] 🎉 Unlocked %d streak achievements for child %s: %v",
-			len(unlockedIDs), childProfileID, unlockedIDs)
-
-		// Начисляем XP за каждое разблокированное достижение
-		for range unlockedIDs {
-			_, _, _ = s.store.AddXP(ctx, childProfileID, XPForAchievement, store.DefaultXPConfig)
-		}
-
-		// Стрик награждает стикерами, проверяем коллекционера
-		s.checkCollectorAfterUnlock(ctx, childProfileID)
+// Subscribe подписывает движок на события, влияющие на достижения
+func (s *AchievementService) Subscribe(bus *EventBus) {
+	for _, eventType := range []string{
+		domain.EventAttemptCompleted,
+		domain.EventHintRequested,
+		domain.EventVillainDefeated,
+		domain.EventStreakUpdated,
+		domain.EventFriendActivated,
+	} {
+		bus.Subscribe(eventType, "achievements", s.HandleEvent)
 	}
-
-	return nil
 }
 
-// CheckVillainAchievements проверяет достижения за побеждённых монстров
-func (s *AchievementService) CheckVillainAchievements(ctx context.Context, childProfileID string) error {
-	villainsCount, err := s.store.GetVillainsDefeatedCount(ctx, childProfileID)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to get villains count for %s: %v", childProfileID, err)
-		return err
-	}
-
-	unlockedIDs, err := s.store.CheckAndUpdateAchievementsByType(ctx, childProfileID, "villains_defeated", villainsCount)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to check villain achievements for %s: %v", childProfileID, err)
-		return err
-	}
-
-	if len(unlockedIDs) > 0 {
-		log.Printf("[AchievementService] 🎉 Unlocked %d villain achievements for child %s: %v",
-			len(unlockedIDs), childProfileID, unlockedIDs)
-
-		// Злодеи награждают стикерами, проверяем коллекционера
-		s.checkCollectorAfterUnlock(ctx, childProfileID)
-	}
-
-	return nil
-}
-
-// CheckTasksCorrectAchievements проверяет достижения за правильно решённые задачи
-func (s *AchievementService) CheckTasksCorrectAchievements(ctx context.Context, childProfileID string) error {
-	tasksCount, err := s.store.GetTasksCorrectCount(ctx, childProfileID)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to get tasks correct count for %s: %v", childProfileID, err)
-		return err
-	}
-
-	unlockedIDs, err := s.store.CheckAndUpdateAchievementsByType(ctx, childProfileID, "tasks_correct", tasksCount)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to check tasks correct achievements for %s: %v", childProfileID, err)
-		return err
-	}
-
-	if len(unlockedIDs) > 0 {
-		log.Printf("[AchievementService] 🎉 Unlocked %d tasks correct achievements for child %s: %v",
-			len(unlockedIDs), childProfileID, unlockedIDs)
-	}
-
-	return nil
-}
-
-// CheckTasksNoHintsAchievements проверяет достижения за задачи без подсказок
-func (s *AchievementService) CheckTasksNoHintsAchievements(ctx context.Context, childProfileID string) error {
-	tasksCount, err := s.store.GetTasksNoHintsCount(ctx, childProfileID)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to get tasks no hints count for %s: %v", childProfileID, err)
-		return err
-	}
-
-	unlockedIDs, err := s.store.CheckAndUpdateAchievementsByType(ctx, childProfileID, "tasks_no_hints", tasksCount)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to check tasks no hints achievements for %s: %v", childProfileID, err)
-		return err
-	}
-
-	if len(unlockedIDs) > 0 {
-		log.Printf("[AchievementService] 🎉 Unlocked %d tasks no hints achievements for child %s: %v",
-			len(unlockedIDs), childProfileID, unlockedIDs)
+// HandleEvent учитывает событие. Повтор того же события ничего не разблокирует
+// и не начисляет XP повторно.
+func (s *AchievementService) HandleEvent(ctx context.Context, event domain.Event) error {
+	eventKey, updates := achievementUpdates(event)
+	if len(updates) == 0 {
+		return nil
 	}
 
-	return nil
-}
-
-// CheckFriendsInvitedAchievements проверяет достижения за приглашённых друзей
-func (s *AchievementService) CheckFriendsInvitedAchievements(ctx context.Context, childProfileID string) error {
-	friendsCount, err := s.store.GetFriendsInvitedCount(ctx, childProfileID)
+	childProfileID := event.EventChildProfileID()
+	unlocked, err := s.store.ApplyAchievementEvent(ctx, childProfileID, eventKey, updates, XPForAchievement)
 	if err != nil {
-		log.Printf("[AchievementService] Failed to get friends invited count for %s: %v", childProfileID, err)
-		return err
+		return fmt.Errorf("apply %s: %w", event.EventType(), err)
 	}
 
-	unlockedIDs, err := s.store.CheckAndUpdateAchievementsByType(ctx, childProfileID, "friends_invited", friendsCount)
+	eventUnlocked, err := s.applySeasonalUpdates(ctx, childProfileID, eventKey, updates)
 	if err != nil {
-		log.Printf("[AchievementService] Failed to check friends invited achievements for %s: %v", childProfileID, err)
-		return err
+		return fmt.Errorf("apply %s to seasonal events: %w", event.EventType(), err)
 	}
+	unlocked = append(unlocked, eventUnlocked...)
 
-	if len(unlockedIDs) > 0 {
-		log.Printf("[AchievementService] 🎉 Unlocked %d friends invited achievements for child %s: %v",
-			len(unlockedIDs), childProfileID, unlockedIDs)
-
-		// Дружба награждает стикерами, проверяем коллекционера
-		s.checkCollectorAfterUnlock(ctx, childProfileID)
+	if len(unlocked) > 0 {
+		ids := make([]string, 0, len(unlocked))
+		for _, u := range unlocked {
+			ids = append(ids, u.AchievementID)
+		}
+		log.Printf("[AchievementService] 🎉 Unlocked %d achievements for child %s on %s: %v",
+			len(unlocked), childProfileID, event.EventType(), ids)
 	}
 
 	return nil
 }
 
-// CheckHintsUsedAchievements проверяет достижения за использованные подсказки (Мудрая сова)
-func (s *AchievementService) CheckHintsUsedAchievements(ctx context.Context, childProfileID string) error {
-	hintsCount, err := s.store.GetHintsUsedCount(ctx, childProfileID)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to get hints used count for %s: %v", childProfileID, err)
-		return err
-	}
-
-	unlockedIDs, err := s.store.CheckAndUpdateAchievementsByType(ctx, childProfileID, "hints_used", hintsCount)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to check hints used achievements for %s: %v", childProfileID, err)
-		return err
+// applySeasonalUpdates продвигает счётчики событий, идущих сегодня по календарю ребёнка.
+// У каждого события свой ключ идемпотентности: злодей, побеждённый до события,
+// в событии засчитывается заново.
+func (s *AchievementService) applySeasonalUpdates(ctx context.Context, childProfileID, eventKey string, updates []store.AchievementCounterUpdate) ([]store.UnlockedAchievement, error) {
+	if eventKey == "" {
+		return nil, nil
+	}
+	var counted []store.AchievementCounterUpdate
+	for _, u := range updates {
+		if !u.IsGauge && seasonal.CountedRequirement(u.RequirementType) {
+			counted = append(counted, u)
+		}
 	}
-
-	if len(unlockedIDs) > 0 {
-		log.Printf("[AchievementService] 🦉 Unlocked hints used achievement for child %s: %v",
-			childProfileID, unlockedIDs)
+	if len(counted) == 0 {
+		return nil, nil
 	}
 
-	return nil
-}
-
-// CheckStickersCollectedAchievements проверяет достижения за собранные стикеры (Коллекционер)
-func (s *AchievementService) CheckStickersCollectedAchievements(ctx context.Context, childProfileID string) error {
-	stickersCount, err := s.store.GetStickersCollectedCount(ctx, childProfileID)
+	date := calendar.DateKey(time.Now(), childLocation(ctx, s.store, childProfileID))
+	events, err := s.store.GetOpenSeasonalEvents(ctx, date)
 	if err != nil {
-		log.Printf("[AchievementService] Failed to get stickers collected count for %s: %v", childProfileID, err)
-		return err
+		return nil, err
 	}
 
-	unlockedIDs, err := s.store.CheckAndUpdateAchievementsByType(ctx, childProfileID, "stickers_collected", stickersCount)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to check stickers collected achievements for %s: %v", childProfileID, err)
-		return err
-	}
-
-	if len(unlockedIDs) > 0 {
-		log.Printf("[AchievementService] 🎨 Unlocked stickers collected achievement for child %s: %v",
-			childProfileID, unlockedIDs)
+	var unlocked []store.UnlockedAchievement
+	for _, e := range events {
+		scoped := make([]store.AchievementCounterUpdate, 0, len(counted))
+		for _, u := range counted {
+			scoped = append(scoped, store.AchievementCounterUpdate{
+				RequirementType: seasonal.CounterType(e.ID, u.RequirementType),
+				Delta:           u.Delta,
+			})
+		}
+		ids, err := s.store.ApplyAchievementEvent(ctx, childProfileID, eventKey+"@"+e.ID, scoped, XPForAchievement)
+		if err != nil {
+			return nil, fmt.Errorf("event %s: %w", e.ID, err)
+		}
+		unlocked = append(unlocked, ids...)
 	}
-
-	return nil
+	return unlocked, nil
 }
 
-// checkCollectorAfterUnlock вспомогательный метод для проверки достижения "Коллекционер"
-// после разблокировки стикеров. Вызывается автоматически после streak, villains, friends.
-func (s *AchievementService) checkCollectorAfterUnlock(ctx context.Context, childProfileID string) {
-	err := s.CheckStickersCollectedAchievements(ctx, childProfileID)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to check collector achievement for %s: %v", childProfileID, err)
-	}
-}
+// achievementUpdates переводит событие в ключ идемпотентности и изменения счётчиков
+func achievementUpdates(event domain.Event) (string, []store.AchievementCounterUpdate) {
+	switch e := event.(type) {
+	case domain.AttemptCompleted:
+		var updates []store.AchievementCounterUpdate
+		if e.IsCorrect {
+			updates = append(updates, store.AchievementCounterUpdate{RequirementType: store.RequirementTasksCorrect, Delta: 1})
+			if e.HintsUsed == 0 {
+				updates = append(updates, store.AchievementCounterUpdate{RequirementType: store.RequirementTasksNoHints, Delta: 1})
+			}
+		} else if e.HasErrors {
+			updates = append(updates, store.AchievementCounterUpdate{RequirementType: store.RequirementErrorsFound, Delta: 1})
+		}
+		return "attempt_completed:" + e.AttemptID, updates
 
-// CheckErrorsFoundAchievements проверяет достижения за найденные ошибки
-func (s *AchievementService) CheckErrorsFoundAchievements(ctx context.Context, childProfileID string) error {
-	errorsCount, err := s.store.GetErrorsFoundCount(ctx, childProfileID)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to get errors found count for %s: %v", childProfileID, err)
-		return err
-	}
+	case domain.HintRequested:
+		return "hint:" + e.AttemptID + ":" + strconv.Itoa(e.HintIndex), []store.AchievementCounterUpdate{
+			{RequirementType: store.RequirementHintsUsed, Delta: 1},
+		}
 
-	unlockedIDs, err := s.store.CheckAndUpdateAchievementsByType(ctx, childProfileID, "errors_found", errorsCount)
-	if err != nil {
-		log.Printf("[AchievementService] Failed to check errors found achievements for %s: %v", childProfileID, err)
-		return err
-	}
+	case domain.VillainDefeated:
+		// Считаются разные злодеи: повторная победа над тем же не продвигает счётчик
+		return "villain_defeated:" + e.VillainID, []store.AchievementCounterUpdate{
+			{RequirementType: store.RequirementVillainsDefeated, Delta: 1},
+		}
 
-	if len(unlockedIDs) > 0 {
-		log.Printf("[AchievementService] 📝 Unlocked errors found achievements for child %s: %v",
-			childProfileID, unlockedIDs)
+	case domain.StreakUpdated:
+		// Серия — текущее значение, а не приращение, поэтому ключ не нужен
+		return "", []store.AchievementCounterUpdate{
+			{RequirementType: store.RequirementStreakDays, Value: e.StreakDays, IsGauge: true},
+		}
 
-		// Исправленные ошибки награждают стикерами, проверяем коллекционера
-		s.checkCollectorAfterUnlock(ctx, childProfileID)
+	case domain.FriendActivated:
+		return "friend_activated:" + e.FriendProfileID, []store.AchievementCounterUpdate{
+			{RequirementType: store.RequirementFriendsInvited, Delta: 1},
+		}
 	}
 
-	return nil
+	return "", nil
 }
@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"time"
 
+	"child-bot/api/internal/damage"
 	"child-bot/api/internal/domain"
 	"child-bot/api/internal/llm"
 	"child-bot/api/internal/llm/types"
@@ -18,12 +19,13 @@ import (
 
 // AttemptService бизнес-логика для работы с попытками
 type AttemptService struct {
-	store              *store.Store
-	llmClient          *llm.Client
-	defaultLLM         string
-	profileService     *ProfileService
-	villainService     *VillainService
-	achievementService *AchievementService
+	store          *store.Store
+	llmClient      *llm.Client
+	defaultLLM     string
+	profileService *ProfileService
+	villainService *VillainService
+	bossService    *BossService
+	events         *EventBus
 }
 
 // NewAttemptService создает новый AttemptService
@@ -45,16 +47,21 @@ func (s *AttemptService) SetVillainService(villainService *VillainService) {
 	s.villainService = villainService
 }
 
-// SetAchievementService устанавливает AchievementService (для избежания циклических зависимостей)
-func (s *AttemptService) SetAchievementService(achievementService *AchievementService) {
-	s.achievementService = achievementService
+// SetBossService устанавливает BossService (урон недельному боссу)
+func (s *AttemptService) SetBossService(bossService *BossService) {
+	s.bossService = bossService
+}
+
+// SetEventBus устанавливает шину доменных событий
+func (s *AttemptService) SetEventBus(events *EventBus) {
+	s.events = events
 }
 
 // AttemptData внутренняя структура для хранения данных попытки
 type AttemptData struct {
 	ID              string
 	ChildProfileID  string
-	Type            string // help or check
+	Type            string // help, check or page
 	Status          string // created, processing, completed, failed
 	TaskImageData   string // base64
 	AnswerImageData string // base64
@@ -63,6 +70,9 @@ type AttemptData struct {
 	HintsResult     *types.HintResponse
 	CheckResult     *types.CheckResponse
 	CurrentHint     int
+	ParentAttemptID string // страница домашки (пусто, если попытка не со страницы)
+	PagePosition    int
+	HintPolicy      *domain.AdaptiveHintPolicy // политика подсказок и её обоснование (help)
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
@@ -70,7 +80,7 @@ type AttemptData struct {
 // CreateAttempt создает новую попытку
 func (s *AttemptService) CreateAttempt(ctx context.Context, childProfileID, attemptType string) (string, error) {
 	// Валидация типа
-	if attemptType != "help" && attemptType != "check" {
+	if attemptType != "help" && attemptType != "check" && attemptType != "page" {
 		return "", domain.ErrInvalidInput
 	}
 
@@ -153,70 +163,48 @@ func (s *AttemptService) ProcessHelp(ctx context.Context, attemptID string, imag
 
 	log.Printf("[AttemptService] Processing help attempt: %s", attemptID)
 
-	// 1. Detect - определить предмет и качество
-	detectReq := types.DetectRequest{
-		Image:  imageBase64,
-		Locale: "ru-RU",
-	}
-
-	detectResp, err := s.llmClient.Detect(ctx, s.defaultLLM, detectReq)
+	// 1-2. Detect + Parse (задача со страницы уже разобрана — берём сохранённый Parse)
+	parseResp, err := s.taskParse(ctx, id, attemptID, imageBase64)
 	if err != nil {
-		// Обновляем статус на failed
-		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
-		return fmt.Errorf("detect failed: %w", err)
+		return err
 	}
 
-	// Проверяем что ответ не пустой
-	if detectResp.Classification.SubjectCandidate == "" {
-		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
-		return fmt.Errorf("detect returned empty classification")
+	// 3. Адаптивная политика подсказок по истории ребёнка
+	policy := s.adaptiveHintPolicy(ctx, s.attemptChildProfileID(ctx, id), id, &parseResp)
+	if err := s.store.Attempts.SaveHintPolicy(ctx, id, policy); err != nil {
+		log.Printf("[AttemptService] Failed to save hint policy: %v", err)
 	}
 
-	// Сохраняем результат Detect
-	err = s.store.Attempts.SaveDetectResult(ctx, id, &detectResp)
-	if err != nil {
-		log.Printf("[AttemptService] Failed to save detect result: %v", err)
-	}
+	log.Printf("[AttemptService] Hint policy: style=%s, max_hints=%d (base %d), scope=%s, samples=%d",
+		policy.Style, policy.MaxHints, policy.BaseMaxHints, policy.Scope, policy.Samples)
 
-	log.Printf("[AttemptService] Detect completed: subject=%s, confidence=%.2f",
-		detectResp.Classification.SubjectCandidate, detectResp.Classification.Confidence)
-
-	// 2. Parse - распарсить задачу
-	parseReq := types.ParseRequest{
-		Image:             imageBase64,
-		TaskId:            attemptID,
-		Grade:             5, // TODO: получать из профиля пользователя
-		SubjectCandidate:  string(detectResp.Classification.SubjectCandidate),
-		SubjectConfidence: fmt.Sprintf("%.2f", detectResp.Classification.Confidence),
-		Locale:            "ru-RU",
+	var h3Reason types.H3Reason
+	if len(parseResp.Items) > 0 {
+		h3Reason = parseResp.Items[0].HintPolicy.H3Reason
 	}
 
-	parseResp, err := s.llmClient.Parse(ctx, s.defaultLLM, parseReq)
-	if err != nil {
-		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
-		return fmt.Errorf("parse failed: %w", err)
+	profileCore := map[string]interface{}{}
+	if entry := findParseTemplate(&parseResp); entry != nil {
+		profileCore = entry.ProfileCore()
 	}
-
-	// Проверяем что Task не пустой
-	if parseResp.Task.TaskTextClean == "" {
-		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
-		return fmt.Errorf("parse returned empty task")
+	if instruction := adaptiveInstruction(policy.Style); instruction != "" {
+		profileCore["adaptive_policy"] = map[string]interface{}{
+			"style":       policy.Style,
+			"instruction": instruction,
+		}
 	}
-
-	// Сохраняем результат Parse
-	err = s.store.Attempts.SaveParseResult(ctx, id, &parseResp)
+	templateJSON, err := json.Marshal(profileCore)
 	if err != nil {
-		log.Printf("[AttemptService] Failed to save parse result: %v", err)
+		log.Printf("[AttemptService] Failed to marshal hint template: %v", err)
 	}
 
-	log.Printf("[AttemptService] Parse completed: task_text=%s", parseResp.Task.TaskTextClean)
-
-	// 3. Hint - сгенерировать подсказки
+	// 4. Hint - сгенерировать подсказки
 	hintReq := types.HintRequest{
-		Task:  parseResp.Task,
-		Mode:  "learn",
-		Items: parseResp.Items,
-		// TODO: правильно заполнить AppliedPolicy и Template
+		Task:          parseResp.Task,
+		Mode:          "learn",
+		Items:         parseResp.Items,
+		AppliedPolicy: appliedHintPolicy(policy, h3Reason),
+		Template:      string(templateJSON),
 	}
 
 	hintResp, err := s.llmClient.Hint(ctx, s.defaultLLM, hintReq)
@@ -225,6 +213,8 @@ func (s *AttemptService) ProcessHelp(ctx context.Context, attemptID string, imag
 		return fmt.Errorf("hint generation failed: %w", err)
 	}
 
+	trimHintsToPolicy(&hintResp, policy.MaxHints)
+
 	// Сохраняем результат Hints (и обновляем статус на completed)
 	err = s.store.Attempts.SaveHintsResult(ctx, id, &hintResp)
 	if err != nil {
@@ -238,8 +228,9 @@ func (s *AttemptService) ProcessHelp(ctx context.Context, attemptID string, imag
 	return nil
 }
 
-// ProcessCheck обрабатывает check попытку через LLM
-func (s *AttemptService) ProcessCheck(ctx context.Context, attemptID, childProfileID, taskImageBase64, answerImageBase64 string) error {
+// ProcessCheck обрабатывает check попытку через LLM.
+// powerUpID — усилитель, который ребёнок выбрал для урона злодею (пусто — без усилителя).
+func (s *AttemptService) ProcessCheck(ctx context.Context, attemptID, childProfileID, taskImageBase64, answerImageBase64, powerUpID string) error {
 	// Восстановление после паники
 	defer func() {
 		if r := recover(); r != nil {
@@ -268,62 +259,12 @@ func (s *AttemptService) ProcessCheck(ctx context.Context, attemptID, childProfi
 
 	log.Printf("[AttemptService] Processing check attempt: %s", attemptID)
 
-	// 1. Detect + Parse задачу
-	detectReq := types.DetectRequest{
-		Image:  taskImageBase64,
-		Locale: "ru-RU",
-	}
-
-	detectResp, err := s.llmClient.Detect(ctx, s.defaultLLM, detectReq)
-	if err != nil {
-		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
-		return fmt.Errorf("detect failed: %w", err)
-	}
-
-	// Проверяем что ответ не пустой
-	if detectResp.Classification.SubjectCandidate == "" {
-		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
-		return fmt.Errorf("detect returned empty classification")
-	}
-
-	// Сохраняем результат Detect
-	err = s.store.Attempts.SaveDetectResult(ctx, id, &detectResp)
-	if err != nil {
-		log.Printf("[AttemptService] Failed to save detect result: %v", err)
-	}
-
-	log.Printf("[AttemptService] Detect completed: subject=%s, confidence=%.2f",
-		detectResp.Classification.SubjectCandidate, detectResp.Classification.Confidence)
-
-	parseReq := types.ParseRequest{
-		Image:             taskImageBase64,
-		TaskId:            attemptID,
-		Grade:             5, // TODO: получать из профиля
-		SubjectCandidate:  string(detectResp.Classification.SubjectCandidate),
-		SubjectConfidence: fmt.Sprintf("%.2f", detectResp.Classification.Confidence),
-		Locale:            "ru-RU",
-	}
-
-	parseResp, err := s.llmClient.Parse(ctx, s.defaultLLM, parseReq)
+	// 1. Detect + Parse задачу (задача со страницы уже разобрана — берём сохранённый Parse)
+	parseResp, err := s.taskParse(ctx, id, attemptID, taskImageBase64)
 	if err != nil {
-		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
-		return fmt.Errorf("parse failed: %w", err)
-	}
-
-	// Проверяем что Task не пустой
-	if parseResp.Task.TaskTextClean == "" {
-		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
-		return fmt.Errorf("parse returned empty task")
+		return err
 	}
 
-	// Сохраняем результат Parse
-	err = s.store.Attempts.SaveParseResult(ctx, id, &parseResp)
-	if err != nil {
-		log.Printf("[AttemptService] Failed to save parse result: %v", err)
-	}
-
-	log.Printf("[AttemptService] Parse completed: task_text=%s", parseResp.Task.TaskTextClean)
-
 	// 2. CheckSolution - проверить решение
 	checkReq := types.CheckRequest{
 		Image: answerImageBase64,
@@ -357,93 +298,67 @@ func (s *AttemptService) ProcessCheck(ctx context.Context, attemptID, childProfi
 
 	// 3. Проверяем результат и обрабатываем правильный ответ
 	if checkResp.Decision == types.CheckDecisionCorrect {
-		// 3.1. Начисляем 5 монет за правильное решение
+		// 3.1. Начисляем монеты за правильное решение (повтор той же задачи даёт меньше)
 		if s.profileService != nil {
-			err := s.profileService.AddCoins(ctx, childProfileID, 5)
+			err := s.profileService.AwardCorrectAnswerCoins(ctx, childProfileID, attemptID)
 			if err != nil {
 				log.Printf("[AttemptService] Failed to add coins for child %s: %v", childProfileID, err)
-			} else {
-				log.Printf("[AttemptService] Added 5 coins for correct answer, child: %s", childProfileID)
 			}
 		}
 
 		// 3.2. Наносим урон активному монстру
+		var task damage.Task
+		if s.villainService != nil || s.bossService != nil {
+			task = s.damageTask(ctx, id, parseResp)
+		}
 		if s.villainService != nil {
-			defeated, villainCoins, err := s.villainService.DealDamageToVillain(ctx, childProfileID, id, "check")
+			defeated, villainCoins, err := s.villainService.DealDamageToVillain(ctx, childProfileID, id, "check", task, powerUpID)
 			if err != nil {
 				log.Printf("[AttemptService] Failed to deal damage to villain for child %s: %v", childProfileID, err)
 			} else {
 				log.Printf("[AttemptService] Dealt damage to villain for child %s, defeated: %v", childProfileID, defeated)
 
-				// 3.3. Если монстр побеждён, начисляем дополнительные монеты за победу
-				if defeated && villainCoins > 0 && s.profileService != nil {
-					err := s.profileService.AddCoins(ctx, childProfileID, villainCoins)
-					if err != nil {
-						log.Printf("[AttemptService] Failed to add victory coins for child %s: %v", childProfileID, err)
-					} else {
-						log.Printf("[AttemptService] Added %d victory coins for defeating villain, child: %s", villainCoins, childProfileID)
-					}
-				}
-
-				// 3.4. Если монстр побеждён, проверяем достижения за злодеев
-				if defeated && s.achievementService != nil {
-					err := s.achievementService.CheckVillainAchievements(ctx, childProfileID)
-					if err != nil {
-						log.Printf("[AttemptService] Failed to check villain achievements for child %s: %v", childProfileID, err)
-					}
-				}
-
-				// 3.5. Если монстр побеждён, начисляем XP
-				if defeated && s.profileService != nil {
-					err := s.profileService.AwardVillainDefeat(ctx, childProfileID)
-					if err != nil {
-						log.Printf("[AttemptService] Failed to award villain defeat XP for child %s: %v", childProfileID, err)
-					}
+				// 3.3. Монеты, XP и событие победы обрабатывает VillainService (по id битвы)
+				if defeated {
+					log.Printf("[AttemptService] Villain defeated, victory coins: %d, child: %s", villainCoins, childProfileID)
 				}
 			}
 		}
 
-		// 3.5. Проверяем достижения за правильно решённые задачи
-		if s.achievementService != nil {
-			// Проверяем достижения за правильные задачи
-			err := s.achievementService.CheckTasksCorrectAchievements(ctx, childProfileID)
+		// 3.3.1. Урон недельному боссу: засчитывается, только если задача подходит текущей фазе
+		if s.bossService != nil {
+			hit, err := s.bossService.DealDamage(ctx, childProfileID, id, task)
 			if err != nil {
-				log.Printf("[AttemptService] Failed to check tasks correct achievements: %v", err)
-			}
-
-			// Проверяем достижения за задачи без подсказок
-			err = s.achievementService.CheckTasksNoHintsAchievements(ctx, childProfileID)
-			if err != nil {
-				log.Printf("[AttemptService] Failed to check tasks no hints achievements: %v", err)
+				log.Printf("[AttemptService] Failed to deal damage to weekly boss for child %s: %v", childProfileID, err)
+			} else if hit != nil {
+				log.Printf("[AttemptService] Weekly boss hit for child %s: matched=%v damage=%d phase=%d defeated=%v",
+					childProfileID, hit.Matched, hit.Damage, hit.Phase, hit.Defeated)
 			}
 		}
 
-		// 3.6. Начисляем XP за правильное решение
+		// 3.4. Начисляем XP за правильное решение
 		if s.profileService != nil {
-			err := s.profileService.AwardCorrectAnswer(ctx, childProfileID)
+			err := s.profileService.AwardCorrectAnswer(ctx, childProfileID, attemptID)
 			if err != nil {
 				log.Printf("[AttemptService] Failed to award correct answer XP for %s: %v", childProfileID, err)
 			}
 		}
 	} else {
-		// 3.7. Если решение неправильное (найдены ошибки), проверяем достижения за найденные ошибки
-		if s.achievementService != nil {
-			err := s.achievementService.CheckErrorsFoundAchievements(ctx, childProfileID)
-			if err != nil {
-				log.Printf("[AttemptService] Failed to check errors found achievements: %v", err)
-			}
-		}
-
-		// 3.8. Начисляем XP за попытку исправления ошибок
+		// 3.5. Начисляем XP за попытку исправления ошибок
 		if s.profileService != nil {
-			err := s.profileService.AwardFixErrors(ctx, childProfileID)
+			err := s.profileService.AwardFixErrors(ctx, childProfileID, attemptID)
 			if err != nil {
 				log.Printf("[AttemptService] Failed to award fix errors XP for %s: %v", childProfileID, err)
 			}
 		}
 	}
 
-	// 4. Сохраняем результат Check (и обновляем статус на completed)
+	// 4. Сохраняем результат Check (и обновляем статус на completed).
+	// Прошлая проверка с ошибками означает, что верное решение — исправленная ошибка
+	hadErrors := false
+	if prev, err := s.store.Attempts.GetAttempt(ctx, id); err == nil {
+		hadErrors = prev.HasErrors.Bool
+	}
 	err = s.store.Attempts.SaveCheckResult(ctx, id, &checkResp)
 	if err != nil {
 		log.Printf("[AttemptService] Failed to save check result: %v", err)
@@ -451,19 +366,23 @@ func (s *AttemptService) ProcessCheck(ctx context.Context, attemptID, childProfi
 		return fmt.Errorf("failed to save check result: %w", err)
 	}
 
-	// 5. Проверяем достижения после сохранения результата (даже если LLM не смог оценить)
-	if s.achievementService != nil {
-		// Проверяем достижения за правильные задачи
-		err := s.achievementService.CheckTasksCorrectAchievements(ctx, childProfileID)
-		if err != nil {
-			log.Printf("[AttemptService] Failed to check tasks correct achievements: %v", err)
-		}
-
-		// Проверяем достижения за найденные ошибки
-		err = s.achievementService.CheckErrorsFoundAchievements(ctx, childProfileID)
-		if err != nil {
-			log.Printf("[AttemptService] Failed to check errors found achievements: %v", err)
-		}
+	// 5. Сообщаем о завершённой проверке (достижения считает подписчик)
+	// и для задачи со страницы обновляем прогресс страницы
+	if attempt, err := s.store.Attempts.GetAttempt(ctx, id); err == nil {
+		s.events.Publish(ctx, domain.AttemptCompleted{
+			ChildProfileID: childProfileID,
+			AttemptID:      attemptID,
+			IsCorrect:      attempt.IsCorrect.Bool,
+			HasErrors:      attempt.HasErrors.Bool,
+			HintsUsed:      attempt.HintsUsed,
+			Subject:        string(parseResp.Task.Subject),
+			Grade:          int(parseResp.Task.Grade),
+			Items:          attemptItems(parseResp),
+			FixedErrors:    hadErrors && attempt.IsCorrect.Bool,
+		})
+		s.updatePageProgress(ctx, attempt)
+	} else {
+		log.Printf("[AttemptService] Failed to reload attempt %s after check: %v", attemptID, err)
 	}
 
 	log.Printf("[AttemptService] Check completed successfully: decision=%s", checkResp.Decision)
@@ -471,6 +390,46 @@ func (s *AttemptService) ProcessCheck(ctx context.Context, attemptID, childProfi
 	return nil
 }
 
+// damageTask собирает атрибуты задачи для расчёта урона злодею: задания и их типы из Parse,
+// подсказки и первая ли это проверка — из попытки (результат Check ещё не сохранён)
+func (s *AttemptService) damageTask(ctx context.Context, id uuid.UUID, parseResp types.ParseResponse) damage.Task {
+	task := damage.Task{
+		Subject:    string(parseResp.Task.Subject),
+		ItemsCount: len(parseResp.Items),
+		Grade:      int(parseResp.Task.Grade),
+		FirstTry:   true,
+	}
+	for _, item := range parseResp.Items {
+		if item.PedKeys.TaskType != "" {
+			task.TaskTypes = append(task.TaskTypes, item.PedKeys.TaskType)
+		}
+		if item.PedKeys.TemplateId != "" {
+			task.Templates = append(task.Templates, item.PedKeys.TemplateId)
+		}
+	}
+
+	attempt, err := s.store.Attempts.GetAttempt(ctx, id)
+	if err != nil {
+		log.Printf("[AttemptService] Failed to load attempt %s for damage calculation: %v", id, err)
+		return task
+	}
+	task.HintsUsed = attempt.HintsUsed
+	task.FirstTry = len(attempt.CheckResult) == 0 || string(attempt.CheckResult) == "null"
+	return task
+}
+
+// attemptItems задания задачи для события проверки
+func attemptItems(parseResp types.ParseResponse) []domain.AttemptItem {
+	items := make([]domain.AttemptItem, 0, len(parseResp.Items))
+	for _, item := range parseResp.Items {
+		items = append(items, domain.AttemptItem{
+			TemplateID: item.PedKeys.TemplateId,
+			TaskType:   item.PedKeys.TaskType,
+		})
+	}
+	return items
+}
+
 // GetNextHint получает следующую подсказку
 func (s *AttemptService) GetNextHint(ctx context.Context, attemptID string) (*domain.HelpResult, error) {
 	// Парсим UUID
@@ -550,28 +509,32 @@ func (s *AttemptService) GetNextHint(ctx context.Context, attemptID string) (*do
 			log.Printf("[AttemptService.GetNextHint] IncrementHintsUsed succeeded, now calling AwardHintRequest...")
 
 			// Начисляем XP за запрос подсказки
-			err = s.profileService.AwardHintRequest(ctx, attempt.ChildProfileID.String())
+			err = s.profileService.AwardHintRequest(ctx, attempt.ChildProfileID.String(), attemptID, currentIndex)
 			if err != nil {
 				log.Printf("[AttemptService] Failed to award hint request XP for %s: %v",
 					attempt.ChildProfileID, err)
 			} else {
-				log.Printf("[AttemptService.GetNextHint] ✓ Successfully awarded 10 XP for hint request to profile %s", attempt.ChildProfileID)
+				log.Printf("[AttemptService.GetNextHint] ✓ Hint request XP processed for profile %s", attempt.ChildProfileID)
 			}
 
-			// Проверяем достижения за использование подсказок (Мудрая сова)
-			if s.achievementService != nil {
-				err = s.achievementService.CheckHintsUsedAchievements(ctx, attempt.ChildProfileID.String())
-				if err != nil {
-					log.Printf("[AttemptService] Failed to check hints used achievements for %s: %v",
-						attempt.ChildProfileID, err)
-					// Не блокируем, продолжаем
-				}
-			}
+			// Достижения за подсказки (Мудрая сова) считает подписчик
+			s.events.Publish(ctx, domain.HintRequested{
+				ChildProfileID: attempt.ChildProfileID.String(),
+				AttemptID:      attemptID,
+				HintIndex:      currentIndex,
+			})
 		}
 	} else {
 		log.Printf("[AttemptService.GetNextHint] ⚠️ profileService is NIL - cannot award XP for hint")
 	}
 
+	// Последняя подсказка фактически раскрывает решение: дальше подсказки к этой задаче XP не дают
+	if newIndex == len(allHints) {
+		if err := s.store.Attempts.MarkAnswerShown(ctx, id); err != nil {
+			log.Printf("[AttemptService] Failed to mark answer shown for attempt %s: %v", attemptID, err)
+		}
+	}
+
 	// Распарсим ParseResult для получения темы и текста задачи
 	var parseResult types.ParseResponse
 	if attempt.ParseResult != nil {
@@ -618,7 +581,17 @@ func (s *AttemptService) GetAttemptResult(ctx context.Context, attemptID string)
 		return nil, fmt.Errorf("failed to get attempt: %w", err)
 	}
 
-	return s.convertToAttemptData(attempt), nil
+	data := s.convertToAttemptData(attempt)
+
+	if attempt.AttemptType == "help" {
+		policy, err := s.store.Attempts.GetHintPolicy(ctx, id)
+		if err != nil {
+			log.Printf("[AttemptService] Failed to get hint policy for %s: %v", attemptID, err)
+		}
+		data.HintPolicy = policy
+	}
+
+	return data, nil
 }
 
 // DeleteAttempt удаляет попытку
@@ -718,6 +691,11 @@ func (s *AttemptService) convertToAttemptData(attempt *store.Attempt) *AttemptDa
 		answerImage = attempt.AnswerImageURL.String
 	}
 
+	parentAttemptID := ""
+	if attempt.ParentAttemptID.Valid {
+		parentAttemptID = attempt.ParentAttemptID.UUID.String()
+	}
+
 	return &AttemptData{
 		ID:              attempt.ID.String(),
 		ChildProfileID:  attempt.ChildProfileID.String(),
@@ -730,6 +708,8 @@ func (s *AttemptService) convertToAttemptData(attempt *store.Attempt) *AttemptDa
 		HintsResult:     hintsResult,
 		CheckResult:     checkResult,
 		CurrentHint:     attempt.CurrentHintIndex,
+		ParentAttemptID: parentAttemptID,
+		PagePosition:    int(attempt.PagePosition.Int64),
 		CreatedAt:       attempt.CreatedAt,
 		UpdatedAt:       attempt.UpdatedAt,
 	}
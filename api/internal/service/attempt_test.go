@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"testing"
 	"time"
+
+	"child-bot/api/internal/domain"
 )
 
 func TestAttemptService_CreateAttempt(t *testing.T) {
@@ -20,7 +22,7 @@ func TestAttemptService_CreateAttempt(t *testing.T) {
 
 	// Create service
 	mockLLM := &mockLLMClient{}
-	service := NewAttemptService(st, mockLLM, "gpt-4")
+	service := NewAttemptService(st, mockLLM.client(t), "gpt-4")
 
 	tests := []struct {
 		name        string
@@ -111,7 +113,7 @@ func TestAttemptService_UploadImage(t *testing.T) {
 
 	// Create service
 	mockLLM := &mockLLMClient{}
-	service := NewAttemptService(st, mockLLM, "gpt-4")
+	service := NewAttemptService(st, mockLLM.client(t), "gpt-4")
 
 	// Sample base64 image (1x1 transparent PNG)
 	sampleImage := "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="
@@ -164,7 +166,7 @@ func TestAttemptService_UploadImage(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
 
-			err := service.UploadImage(ctx, tt.attemptID, tt.imageType, tt.imageBase64)
+			_, err := service.UploadImage(ctx, tt.attemptID, tt.imageType, tt.imageBase64)
 
 			if tt.expectError {
 				if err == nil {
@@ -233,40 +235,40 @@ func TestAttemptService_ProcessHelp_Integration(t *testing.T) {
 		parseFunc: func(ctx context.Context, llmName string, req interface{}) (interface{}, error) {
 			return map[string]interface{}{
 				"task": map[string]interface{}{
-					"id":   attemptID,
-					"text": "Solve 2+2",
+					"task_id":         attemptID,
+					"subject":         "math",
+					"task_text_clean": "Solve 2+2",
 				},
 				"items": []interface{}{
 					map[string]interface{}{
-						"id":   "item1",
-						"text": "2+2=?",
+						"item_id":         "item1",
+						"item_text_clean": "2+2=?",
 					},
 				},
 			}, nil
 		},
 		hintFunc: func(ctx context.Context, llmName string, req interface{}) (interface{}, error) {
 			return map[string]interface{}{
-				"hints": []interface{}{
-					map[string]interface{}{
-						"text":  "First hint: add the numbers",
-						"level": 1,
-					},
+				"items": []interface{}{
 					map[string]interface{}{
-						"text":  "Second hint: 2+2=4",
-						"level": 2,
+						"item_id": "item1",
+						"hints": []interface{}{
+							map[string]interface{}{"level": "L1", "hint_text": "First hint: add the numbers"},
+							map[string]interface{}{"level": "L2", "hint_text": "Second hint: 2+2=4"},
+						},
 					},
 				},
 			}, nil
 		},
 	}
 
-	service := NewAttemptService(st, mockLLM, "gpt-4")
+	service := NewAttemptService(st, mockLLM.client(t), "gpt-4")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	// Process help
-	err = service.ProcessHelp(ctx, attemptID)
+	err = service.ProcessHelp(ctx, attemptID, sampleImage)
 	if err != nil {
 		t.Fatalf("ProcessHelp failed: %v", err)
 	}
@@ -316,11 +318,14 @@ func TestAttemptService_NextHint(t *testing.T) {
 
 	// Set up hints_result with 3 hints
 	hintsJSON := `{
-		"hints": [
-			{"text": "Hint 1", "level": 1},
-			{"text": "Hint 2", "level": 2},
-			{"text": "Hint 3", "level": 3}
-		]
+		"items": [{
+			"item_id": "item1",
+			"hints": [
+				{"level": "L1", "hint_text": "Hint 1"},
+				{"level": "L2", "hint_text": "Hint 2"},
+				{"level": "L3", "hint_text": "Hint 3"}
+			]
+		}]
 	}`
 	_, err := db.Exec("UPDATE attempts SET hints_result = $1, current_hint_index = 0 WHERE id = $2", hintsJSON, attemptID)
 	if err != nil {
@@ -329,55 +334,33 @@ func TestAttemptService_NextHint(t *testing.T) {
 
 	// Create service
 	mockLLM := &mockLLMClient{}
-	service := NewAttemptService(st, mockLLM, "gpt-4")
+	service := NewAttemptService(st, mockLLM.client(t), "gpt-4")
 
 	ctx := context.Background()
 
 	// Test getting hints sequentially
 	for i := 0; i < 3; i++ {
 		t.Run("hint_"+string(rune('0'+i)), func(t *testing.T) {
-			result, err := service.NextHint(ctx, attemptID)
+			result, err := service.GetNextHint(ctx, attemptID)
 			if err != nil {
-				t.Fatalf("NextHint failed: %v", err)
+				t.Fatalf("GetNextHint failed: %v", err)
 			}
 
-			resultMap, ok := result.(map[string]interface{})
-			if !ok {
-				t.Fatalf("expected map result, got %T", result)
+			if result.CurrentHint != i {
+				t.Errorf("expected current_hint %d, got %d", i, result.CurrentHint)
 			}
 
-			hintIndex, ok := resultMap["hint_index"].(int)
-			if !ok || hintIndex != i {
-				t.Errorf("expected hint_index %d, got %v", i, resultMap["hint_index"])
-			}
-
-			hasMore, ok := resultMap["has_more"].(bool)
-			if !ok {
-				t.Error("expected has_more field")
-			}
-
-			expectedHasMore := i < 2
-			if hasMore != expectedHasMore {
-				t.Errorf("expected has_more=%v, got %v", expectedHasMore, hasMore)
+			if result.TotalHints != 3 {
+				t.Errorf("expected total_hints 3, got %d", result.TotalHints)
 			}
 		})
 	}
 
 	// Test getting hint when all hints exhausted
 	t.Run("no_more_hints", func(t *testing.T) {
-		result, err := service.NextHint(ctx, attemptID)
-		if err != nil {
-			t.Fatalf("NextHint failed: %v", err)
-		}
-
-		resultMap, ok := result.(map[string]interface{})
-		if !ok {
-			t.Fatalf("expected map result, got %T", result)
-		}
-
-		hasMore, ok := resultMap["has_more"].(bool)
-		if !ok || hasMore {
-			t.Error("expected has_more=false when hints exhausted")
+		_, err := service.GetNextHint(ctx, attemptID)
+		if !errors.Is(err, domain.ErrNoHintsAvailable) {
+			t.Errorf("expected ErrNoHintsAvailable when hints exhausted, got %v", err)
 		}
 	})
 }
@@ -396,7 +379,7 @@ func TestAttemptService_DeleteAttempt(t *testing.T) {
 
 	// Create service
 	mockLLM := &mockLLMClient{}
-	service := NewAttemptService(st, mockLLM, "gpt-4")
+	service := NewAttemptService(st, mockLLM.client(t), "gpt-4")
 
 	ctx := context.Background()
 
@@ -427,7 +410,7 @@ func BenchmarkAttemptService_CreateAttempt(b *testing.B) {
 	profileID := createTestProfile(&testing.T{}, db, "test", "bench_user", 5)
 
 	mockLLM := &mockLLMClient{}
-	service := NewAttemptService(st, mockLLM, "gpt-4")
+	service := NewAttemptService(st, mockLLM.client(b), "gpt-4")
 
 	ctx := context.Background()
 
@@ -451,7 +434,7 @@ func BenchmarkAttemptService_UploadImage(b *testing.B) {
 	attemptID := createTestAttempt(&testing.T{}, db, profileID, "help")
 
 	mockLLM := &mockLLMClient{}
-	service := NewAttemptService(st, mockLLM, "gpt-4")
+	service := NewAttemptService(st, mockLLM.client(b), "gpt-4")
 
 	sampleImage := "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="
 
@@ -459,7 +442,7 @@ func BenchmarkAttemptService_UploadImage(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		err := service.UploadImage(ctx, attemptID, "task", sampleImage)
+		_, err := service.UploadImage(ctx, attemptID, "task", sampleImage)
 		if err != nil {
 			b.Fatalf("UploadImage failed: %v", err)
 		}
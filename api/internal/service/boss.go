@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"child-bot/api/internal/boss"
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/damage"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// Статусы фазы на экране битвы
+const (
+	BossPhaseCompleted = "completed"
+	BossPhaseCurrent   = "current"
+	BossPhaseLocked    = "locked"
+)
+
+// bossRecentDamageLimit сколько последних ударов показывать на экране битвы
+const bossRecentDamageLimit = 10
+
+// subjectNames названия предметов для подсказки «что решать дальше»
+var subjectNames = map[string]string{
+	"math":       "математика",
+	"ru":         "русский язык",
+	"en":         "английский язык",
+	"world":      "окружающий мир",
+	"literature": "литературное чтение",
+}
+
+// taskTypeNames названия типов задач (ped_keys.task_type)
+var taskTypeNames = map[string]string{
+	"number_sense":        "числа и счёт",
+	"numeral_systems":     "разряды и запись чисел",
+	"arithmetic_fluency":  "примеры на вычисления",
+	"fractions_percent":   "дроби и проценты",
+	"word_problems":       "текстовые задачи",
+	"measurement_units":   "величины и единицы",
+	"geometry":            "геометрия",
+	"data_representation": "таблицы и диаграммы",
+	"patterns_logic":      "закономерности и логика",
+}
+
+// BossService недельный босс: битва на несколько дней с фазами
+type BossService struct {
+	store  *store.Store
+	events *EventBus
+	now    func() time.Time
+}
+
+// NewBossService создает новый BossService
+func NewBossService(store *store.Store) *BossService {
+	return &BossService{store: store, now: time.Now}
+}
+
+// SetEventBus устанавливает шину доменных событий
+func (s *BossService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// BossRequirement что нужно решать, чтобы ранить босса в фазе
+type BossRequirement struct {
+	Subject      string // пусто — любой предмет
+	SubjectName  string
+	TaskType     string // пусто — любой тип
+	TaskTypeName string
+}
+
+// BossPhaseInfo фаза босса на экране битвы
+type BossPhaseInfo struct {
+	Number      int
+	Title       string
+	HP          int
+	MaxHP       int
+	Status      string // completed, current, locked
+	Requirement BossRequirement
+}
+
+// BossDamage удар по боссу
+type BossDamage struct {
+	Phase      int
+	Damage     int
+	IsCritical bool
+	Bonuses    []damage.Bonus
+	Subject    string
+	CreatedAt  time.Time
+}
+
+// BossFight недельный босс для экрана битвы
+type BossFight struct {
+	BattleID      int64
+	VillainID     string
+	Name          string
+	Description   string
+	ImageURL      string
+	Level         int
+	Status        string // active, defeated, expired
+	WeekStart     string
+	EndsOn        string
+	Today         string
+	DaysLeft      int // сколько дней ещё идёт битва, включая сегодня
+	HP            int // осталось HP у босса (все фазы)
+	MaxHP         int
+	CurrentPhase  int
+	Phases        []BossPhaseInfo
+	Next          *BossRequirement // что решать сейчас; nil, если битва закончена
+	RegenHPPerDay int
+	HealedNow     int // восстановлено за пропущенные дни при этом запросе
+	RegenTotal    int
+	TotalDamage   int
+	TasksCount    int
+	Rewards       []store.BossReward
+	RecentDamage  []BossDamage
+	DefeatedAt    *time.Time
+}
+
+// BossHit итог удара по боссу после правильно решённой задачи
+type BossHit struct {
+	Matched      bool // задача подходит для текущей фазы
+	Damage       int
+	IsCritical   bool
+	PhaseCleared bool
+	Defeated     bool
+	Phase        int
+	PhaseHP      int
+	Next         *BossRequirement
+	Rewards      []store.BossReward
+}
+
+// bossBattleState битва недели вместе с настройками и фазами босса
+type bossBattleState struct {
+	battle *store.BossBattleRow
+	config store.BossConfig
+	phases []boss.Phase
+	today  string
+}
+
+// GetWeeklyBoss возвращает битву с боссом текущей недели, начиная её при первом обращении.
+// Перед ответом босс восстанавливает HP за дни, которые ребёнок пропустил.
+func (s *BossService) GetWeeklyBoss(ctx context.Context, childProfileID string) (*BossFight, error) {
+	state, err := s.weeklyBattle(ctx, childProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	healed := 0
+	if state.battle.Status == store.BossBattleActive {
+		state.battle, healed, err = s.store.RegenerateBossBattle(ctx, state.battle.ID, state.phases, state.config.RegenHPPerDay, state.today)
+		if err != nil {
+			return nil, fmt.Errorf("failed to regenerate boss: %w", err)
+		}
+		if healed > 0 {
+			log.Printf("[BossService] Boss %s healed %d HP for child %s (skipped days)", state.battle.VillainID, healed, childProfileID)
+		}
+	}
+
+	villain, err := s.store.Villains.GetVillainByID(ctx, state.battle.VillainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boss villain: %w", err)
+	}
+	if villain == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	rewards, err := s.store.GetBossRewards(ctx, state.battle.VillainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boss rewards: %w", err)
+	}
+
+	events, err := s.store.GetBossDamageEvents(ctx, state.battle.ID, bossRecentDamageLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boss damage: %w", err)
+	}
+
+	b := state.battle
+	fight := &BossFight{
+		BattleID:      b.ID,
+		VillainID:     villain.ID,
+		Name:          villain.Name,
+		Description:   villain.Description,
+		ImageURL:      villain.ImageURL,
+		Level:         villain.Level,
+		Status:        b.Status,
+		WeekStart:     b.WeekStart,
+		EndsOn:        b.EndsOn,
+		Today:         state.today,
+		MaxHP:         boss.TotalHP(state.phases),
+		CurrentPhase:  b.CurrentPhase,
+		RegenHPPerDay: state.config.RegenHPPerDay,
+		HealedNow:     healed,
+		RegenTotal:    b.RegenTotal,
+		TotalDamage:   b.TotalDamage,
+		TasksCount:    b.TasksCount,
+		Rewards:       rewards,
+		RecentDamage:  make([]BossDamage, 0, len(events)),
+	}
+	if b.DefeatedAt.Valid {
+		fight.DefeatedAt = &b.DefeatedAt.Time
+	}
+
+	if b.Status == store.BossBattleActive {
+		fight.HP = boss.RemainingHP(state.phases, boss.Progress{Phase: b.CurrentPhase, PhaseHP: b.PhaseHP})
+		fight.DaysLeft = boss.DaysLeft(state.today, b.EndsOn)
+		fight.Next = phaseRequirement(state.phases, b.CurrentPhase)
+	} else if b.Status == store.BossBattleExpired {
+		fight.HP = boss.RemainingHP(state.phases, boss.Progress{Phase: b.CurrentPhase, PhaseHP: b.PhaseHP})
+	}
+
+	for _, p := range state.phases {
+		info := BossPhaseInfo{
+			Number:      p.Number,
+			Title:       p.Title,
+			HP:          p.HP,
+			MaxHP:       p.HP,
+			Status:      BossPhaseLocked,
+			Requirement: requirementFor(p),
+		}
+		switch {
+		case b.Status == store.BossBattleDefeated || p.Number < b.CurrentPhase:
+			info.Status = BossPhaseCompleted
+			info.HP = 0
+		case p.Number == b.CurrentPhase:
+			info.Status = BossPhaseCurrent
+			info.HP = b.PhaseHP
+		}
+		fight.Phases = append(fight.Phases, info)
+	}
+
+	for _, e := range events {
+		var bonuses []damage.Bonus
+		if len(e.Bonuses) > 0 {
+			if err := json.Unmarshal(e.Bonuses, &bonuses); err != nil {
+				log.Printf("[BossService] Failed to parse boss damage bonuses for event %d: %v", e.ID, err)
+			}
+		}
+		fight.RecentDamage = append(fight.RecentDamage, BossDamage{
+			Phase:      e.Phase,
+			Damage:     e.Damage,
+			IsCritical: e.IsCritical,
+			Bonuses:    bonuses,
+			Subject:    e.Subject,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+
+	return fight, nil
+}
+
+// DealDamage бьёт босса недели правильно решённой задачей. Урон считается по формуле злодея-босса,
+// но засчитывается, только если задача подходит для текущей фазы (предмет и тип задач).
+// Возвращает nil, если недельных боссов нет или битва уже закончена.
+func (s *BossService) DealDamage(ctx context.Context, childProfileID string, attemptID uuid.UUID, task damage.Task) (*BossHit, error) {
+	state, err := s.weeklyBattle(ctx, childProfileID)
+	if err == domain.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if state.battle.Status != store.BossBattleActive {
+		return nil, nil
+	}
+
+	villain, err := s.store.Villains.GetVillainByID(ctx, state.battle.VillainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boss villain: %w", err)
+	}
+	if villain == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	formula, err := damage.ParseFormula(villain.DamageFormula, villain.DamagePerCorrectTask)
+	if err != nil {
+		log.Printf("[BossService] Invalid damage formula for boss %s, using default: %v", villain.ID, err)
+	}
+
+	rewards, err := s.store.GetBossRewards(ctx, villain.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boss rewards: %w", err)
+	}
+
+	breakdown := damage.Calculate(formula, task)
+	res, err := s.store.RecordBossHit(ctx, store.BossHitInput{
+		BattleID:      state.battle.ID,
+		AttemptID:     attemptID,
+		Subject:       task.Subject,
+		TaskTypes:     task.TaskTypes,
+		Hit:           breakdown,
+		Today:         state.today,
+		RegenHPPerDay: state.config.RegenHPPerDay,
+	}, state.phases, rewards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record boss hit: %w", err)
+	}
+
+	hit := &BossHit{
+		Matched:      res.Matched,
+		Damage:       res.Damage,
+		PhaseCleared: res.PhaseCleared,
+		Defeated:     res.Defeated,
+		Phase:        res.Phase,
+		PhaseHP:      res.PhaseHP,
+		Rewards:      res.Rewards,
+	}
+	if res.Matched && !res.AlreadyHit {
+		hit.IsCritical = breakdown.IsCritical
+	}
+	if !res.Defeated {
+		hit.Next = phaseRequirement(state.phases, res.Phase)
+	}
+
+	switch {
+	case res.AlreadyHit:
+		log.Printf("[BossService] Attempt %s already hit boss for child %s", attemptID, childProfileID)
+	case !res.Matched:
+		log.Printf("[BossService] Task (%s) does not match boss phase %d for child %s", task.Subject, res.Phase, childProfileID)
+	default:
+		log.Printf("[BossService] Dealt %d damage to boss %s (phase %d, HP %d) for child %s",
+			res.Damage, villain.ID, res.Phase, res.PhaseHP, childProfileID)
+	}
+
+	if res.Defeated && !res.AlreadyHit {
+		log.Printf("[BossService] Weekly boss %s defeated by child %s, rewards: %d", villain.ID, childProfileID, len(res.Rewards))
+		s.events.Publish(ctx, domain.VillainDefeated{
+			ChildProfileID: childProfileID,
+			BattleID:       state.battle.ID,
+			VillainID:      villain.ID,
+		})
+	}
+
+	return hit, nil
+}
+
+// weeklyBattle находит или начинает битву с боссом текущей недели ребёнка.
+// Битвы прошлых недель, оставшиеся непобеждёнными, помечаются expired.
+func (s *BossService) weeklyBattle(ctx context.Context, childProfileID string) (*bossBattleState, error) {
+	loc := childLocation(ctx, s.store, childProfileID)
+	now := s.now().In(loc)
+	today := calendar.DateKey(now, loc)
+	weekStart := calendar.DateKey(calendar.WeekStart(now, loc), loc)
+
+	if err := s.store.ExpireBossBattles(ctx, childProfileID, today); err != nil {
+		log.Printf("[BossService] Failed to expire old boss battles for %s: %v", childProfileID, err)
+	}
+
+	battle, err := s.store.GetBossBattle(ctx, childProfileID, weekStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boss battle: %w", err)
+	}
+
+	var config store.BossConfig
+	if battle == nil {
+		configs, err := s.store.ListBossConfigs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list bosses: %w", err)
+		}
+		if len(configs) == 0 {
+			return nil, domain.ErrNotFound
+		}
+		week, err := boss.WeekIndex(weekStart)
+		if err != nil {
+			return nil, err
+		}
+		config = configs[week%len(configs)]
+	} else {
+		cfg, err := s.store.GetBossConfig(ctx, battle.VillainID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get boss config: %w", err)
+		}
+		if cfg != nil {
+			config = *cfg
+		}
+	}
+
+	villainID := config.VillainID
+	if battle != nil {
+		villainID = battle.VillainID
+	}
+	phases, err := s.store.GetBossPhases(ctx, villainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get boss phases: %w", err)
+	}
+	if len(phases) == 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	if battle == nil {
+		endsOn, err := boss.EndsOn(weekStart, config.DurationDays)
+		if err != nil {
+			return nil, err
+		}
+		battle, err = s.store.CreateBossBattle(ctx, childProfileID, config.VillainID, weekStart, endsOn, today, phases[0].HP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start boss battle: %w", err)
+		}
+		log.Printf("[BossService] Started weekly boss %s for child %s (week %s, until %s)",
+			battle.VillainID, childProfileID, weekStart, battle.EndsOn)
+	}
+
+	return &bossBattleState{battle: battle, config: config, phases: phases, today: today}, nil
+}
+
+// phaseRequirement что нужно решать в фазе number; nil, если такой фазы нет
+func phaseRequirement(phases []boss.Phase, number int) *BossRequirement {
+	for _, p := range phases {
+		if p.Number == number {
+			req := requirementFor(p)
+			return &req
+		}
+	}
+	return nil
+}
+
+// requirementFor требование фазы с человекочитаемыми названиями
+func requirementFor(p boss.Phase) BossRequirement {
+	return BossRequirement{
+		Subject:      p.RequiredSubject,
+		SubjectName:  subjectNames[p.RequiredSubject],
+		TaskType:     p.RequiredTaskType,
+		TaskTypeName: taskTypeNames[p.RequiredTaskType],
+	}
+}
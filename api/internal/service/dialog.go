@@ -0,0 +1,499 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/llm"
+	"child-bot/api/internal/llm/types"
+	"child-bot/api/internal/store"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxDialogQuestions максимум вопросов ребёнка в одном диалоге по задаче
+	MaxDialogQuestions = 10
+
+	// MaxDialogMessageLength максимальная длина вопроса ребёнка
+	MaxDialogMessageLength = 500
+
+	// dialogHistoryLimit сколько последних сообщений передаём в LLM
+	dialogHistoryLimit = 8
+
+	// dialogRateLimit / dialogRateWindow — частота вопросов одного ребёнка
+	dialogRateLimit  = 6
+	dialogRateWindow = time.Minute
+)
+
+// Тексты наставника, которые не требуют запроса к LLM
+const (
+	dialogRefuseAnswerText = "Ответ я не скажу — иначе будет неинтересно 🙂 Но помогу до него дойти! Скажи, какой шаг сейчас непонятен?"
+	dialogGuardedText      = "Ой, так я случайно подскажу ответ целиком 🙈 Давай лучше вместе: с чего ты бы начал решение?"
+	dialogFallbackText     = "Хороший вопрос! Перечитай условие и скажи, что в задаче уже известно, а что нужно найти?"
+)
+
+// answerRequestPattern вопросы, в которых ребёнок просит готовый ответ
+var answerRequestPattern = regexp.MustCompile(`(?i)(скажи|дай|напиши|покажи|назови)\s+(мне\s+)?(сразу\s+)?(правильный\s+|готовый\s+)?ответ|какой\s+(же\s+)?(правильный\s+)?ответ|реши\s+за\s+меня|реши\s+(сам|мне)`)
+
+// numberPattern числа в тексте (целые и дробные)
+var numberPattern = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+
+// DialogInput контекст диалога: разбор задачи, подсказки и история сообщений
+type DialogInput struct {
+	Parse      *types.ParseResponse
+	Hints      *types.HintResponse
+	HintsShown int // сколько подсказок ребёнок уже видел
+	History    []domain.DialogMessage
+	Question   string
+}
+
+// DialogService сократический диалог по задаче: отвечает на вопросы ребёнка,
+// опираясь на разбор задачи и подсказки, и никогда не раскрывает итоговый ответ
+type DialogService struct {
+	store      *store.Store
+	llmClient  *llm.Client
+	defaultLLM string
+	limiter    *dialogLimiter
+}
+
+// NewDialogService создает новый DialogService
+func NewDialogService(store *store.Store, llmClient *llm.Client, defaultLLM string) *DialogService {
+	return &DialogService{
+		store:      store,
+		llmClient:  llmClient,
+		defaultLLM: defaultLLM,
+		limiter:    newDialogLimiter(dialogRateLimit, dialogRateWindow),
+	}
+}
+
+// GetAttemptMessages возвращает диалог по попытке
+func (s *DialogService) GetAttemptMessages(ctx context.Context, attemptID, childProfileID string) ([]domain.DialogMessage, error) {
+	id, _, err := s.loadOwnedAttempt(ctx, attemptID, childProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loadHistory(ctx, id)
+}
+
+// SendAttemptMessage принимает вопрос ребёнка по попытке и возвращает ответ наставника
+func (s *DialogService) SendAttemptMessage(ctx context.Context, attemptID, childProfileID, text string) (*domain.DialogReply, error) {
+	id, attempt, err := s.loadOwnedAttempt(ctx, attemptID, childProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Диалог возможен только когда задача уже разобрана
+	if len(attempt.ParseResult) == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	var parseResult types.ParseResponse
+	if err := json.Unmarshal(attempt.ParseResult, &parseResult); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal parse_result: %w", err)
+	}
+
+	var hintsResult *types.HintResponse
+	if len(attempt.HintsResult) > 0 {
+		if err := json.Unmarshal(attempt.HintsResult, &hintsResult); err != nil {
+			log.Printf("[DialogService] Failed to unmarshal hints_result: %v", err)
+			hintsResult = nil
+		}
+	}
+
+	history, err := s.loadHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	question := strings.TrimSpace(text)
+	if question == "" || len([]rune(question)) > MaxDialogMessageLength {
+		return nil, domain.ErrInvalidInput
+	}
+
+	// Слот вопроса занимаем до запроса к LLM: параллельные вопросы не обойдут лимит
+	asked, err := s.store.Attempts.ReserveDialogQuestion(ctx, id, MaxDialogQuestions)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := s.Reply(ctx, s.defaultLLM, childProfileID, DialogInput{
+		Parse:      &parseResult,
+		Hints:      hintsResult,
+		HintsShown: attempt.CurrentHintIndex,
+		History:    history,
+		Question:   question,
+	})
+	if err != nil {
+		s.releaseQuestion(ctx, id)
+		return nil, err
+	}
+	saved, err := s.store.Attempts.AddDialogExchange(ctx, id, question, reply.Message.Text, reply.Guarded)
+	if err != nil {
+		s.releaseQuestion(ctx, id)
+		return nil, err
+	}
+	reply.Message.CreatedAt = saved.CreatedAt
+	reply.QuestionsLeft = MaxDialogQuestions - asked
+
+	return reply, nil
+}
+
+// releaseQuestion возвращает занятый слот вопроса, если ответ не сохранён
+func (s *DialogService) releaseQuestion(ctx context.Context, attemptID uuid.UUID) {
+	if err := s.store.Attempts.ReleaseDialogQuestion(ctx, attemptID); err != nil {
+		log.Printf("[DialogService] Failed to release dialog question for attempt %s: %v", attemptID, err)
+	}
+}
+
+// Reply формирует ответ наставника. Не зависит от хранилища: используется и REST API,
+// и Telegram-ботом (limiterKey — child_profile_id или chat_id).
+func (s *DialogService) Reply(ctx context.Context, llmName, limiterKey string, in DialogInput) (*domain.DialogReply, error) {
+	question := strings.TrimSpace(in.Question)
+	if question == "" || len([]rune(question)) > MaxDialogMessageLength {
+		return nil, domain.ErrInvalidInput
+	}
+	if in.Parse == nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	asked := countChildMessages(in.History)
+	if asked >= MaxDialogQuestions {
+		return nil, domain.ErrDialogLimitReached
+	}
+
+	if !s.limiter.Allow(limiterKey) {
+		return nil, domain.ErrRateLimited
+	}
+
+	if llmName == "" {
+		llmName = s.defaultLLM
+	}
+
+	reply := &domain.DialogReply{
+		Message: domain.DialogMessage{
+			Role:      domain.DialogRoleTutor,
+			CreatedAt: time.Now(),
+		},
+		QuestionsLeft: MaxDialogQuestions - asked - 1,
+	}
+
+	// Просьбу дать готовый ответ обрабатываем без LLM
+	if answerRequestPattern.MatchString(question) {
+		reply.Message.Text = dialogRefuseAnswerText
+		return reply, nil
+	}
+
+	entry := findParseTemplate(in.Parse)
+	text, err := s.requestReply(ctx, llmName, entry, in, question)
+	if err != nil {
+		log.Printf("[DialogService] LLM reply failed, using fallback: %v", err)
+		text = ""
+	}
+	if strings.TrimSpace(text) == "" {
+		text = dialogFallbackText
+	}
+
+	// Последний рубеж: ответ LLM не должен содержать итоговый ответ задачи
+	if revealsFinalAnswer(text, in.Parse) {
+		log.Printf("[DialogService] Reply revealed final answer, replaced with guarded text")
+		text = dialogGuardedText
+		reply.Guarded = true
+	}
+
+	reply.Message.Text = text
+	return reply, nil
+}
+
+// requestReply запрашивает у LLM ответ на вопрос через HINT: вопрос, история и запреты
+// текущего уровня передаются вместе с template_profile_core
+func (s *DialogService) requestReply(ctx context.Context, llmName string, entry *llm.TemplateEntry, in DialogInput, question string) (string, error) {
+	if s.llmClient == nil {
+		return "", nil
+	}
+
+	profileCore := map[string]interface{}{}
+	if entry != nil {
+		profileCore = entry.ProfileCore()
+	}
+
+	history := in.History
+	if len(history) > dialogHistoryLimit {
+		history = history[len(history)-dialogHistoryLimit:]
+	}
+
+	profileCore["dialog"] = map[string]interface{}{
+		"question":    question,
+		"history":     history,
+		"hints_shown": shownHintTexts(in.Hints, in.HintsShown),
+		"forbidden":   dialogForbiddenRules(entry, in.HintsShown),
+		"instruction": "Ответь на вопрос ребёнка коротко, в 1–3 предложениях, наводящим вопросом. Никогда не называй итоговый ответ.",
+	}
+
+	templateJSON, err := json.Marshal(profileCore)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal template: %w", err)
+	}
+
+	hintResp, err := s.llmClient.Hint(ctx, llmName, types.HintRequest{
+		Task:  in.Parse.Task,
+		Mode:  string(types.HintModeLearn),
+		Items: in.Parse.Items,
+		AppliedPolicy: types.HintPolicy{
+			MaxHints:       1,
+			DefaultVisible: 1,
+			H3Reason:       types.H3ReasonNone,
+		},
+		Template: string(templateJSON),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, item := range hintResp.Items {
+		for _, h := range item.Hints {
+			if strings.TrimSpace(h.HintText) != "" {
+				return strings.TrimSpace(h.HintText), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// loadOwnedAttempt загружает попытку и проверяет, что она принадлежит ребёнку
+func (s *DialogService) loadOwnedAttempt(ctx context.Context, attemptID, childProfileID string) (uuid.UUID, *store.Attempt, error) {
+	id, err := uuid.Parse(attemptID)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("invalid attempt_id: %w", err)
+	}
+
+	attempt, err := s.store.Attempts.GetAttempt(ctx, id)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed to get attempt: %w", err)
+	}
+
+	if attempt.ChildProfileID.String() != childProfileID {
+		return uuid.Nil, nil, domain.ErrForbidden
+	}
+
+	return id, attempt, nil
+}
+
+// loadHistory загружает историю диалога из БД
+func (s *DialogService) loadHistory(ctx context.Context, attemptID uuid.UUID) ([]domain.DialogMessage, error) {
+	messages, err := s.store.Attempts.GetAttemptMessages(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]domain.DialogMessage, 0, len(messages))
+	for _, m := range messages {
+		history = append(history, domain.DialogMessage{
+			Role:      m.Role,
+			Text:      m.Content,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+	return history, nil
+}
+
+// findParseTemplate ищет шаблон задачи по template_id из PARSE
+func findParseTemplate(parse *types.ParseResponse) *llm.TemplateEntry {
+	if parse == nil {
+		return nil
+	}
+	for _, item := range parse.Items {
+		if entry, ok := llm.FindTemplate(item.PedKeys.TemplateId); ok {
+			return entry
+		}
+	}
+	return nil
+}
+
+// dialogForbiddenRules собирает запреты всех уровней, доступных ребёнку на данный момент
+func dialogForbiddenRules(entry *llm.TemplateEntry, hintsShown int) []string {
+	rules := []string{"сообщать конечный ответ целиком"}
+	if entry == nil {
+		return rules
+	}
+
+	pattern := entry.Profile.TeachingPattern
+	levels := []llm.HintLevel{pattern.L1, pattern.L2, pattern.L3}
+	if hintsShown < 1 {
+		hintsShown = 1
+	}
+	if hintsShown > len(levels) {
+		hintsShown = len(levels)
+	}
+	for _, level := range levels[:hintsShown] {
+		rules = append(rules, level.Forbidden...)
+	}
+	return rules
+}
+
+// shownHintTexts возвращает тексты подсказок, которые ребёнок уже видел
+func shownHintTexts(hints *types.HintResponse, shown int) []string {
+	if hints == nil || shown <= 0 {
+		return nil
+	}
+
+	var texts []string
+	for _, item := range hints.Items {
+		for _, h := range item.Hints {
+			if len(texts) >= shown {
+				return texts
+			}
+			texts = append(texts, h.HintText)
+		}
+	}
+	return texts
+}
+
+// countChildMessages считает вопросы ребёнка в истории
+func countChildMessages(history []domain.DialogMessage) int {
+	count := 0
+	for _, m := range history {
+		if m.Role == domain.DialogRoleChild {
+			count++
+		}
+	}
+	return count
+}
+
+// revealsFinalAnswer проверяет, содержит ли текст итоговый ответ хотя бы одного пункта задачи.
+// Числа, которые уже есть в условии, не считаются раскрытием ответа.
+func revealsFinalAnswer(text string, parse *types.ParseResponse) bool {
+	if parse == nil {
+		return false
+	}
+
+	normalizedText := normalizeAnswerText(text)
+	taskNumbers := numberSet(parse.Task.TaskTextClean)
+	textNumbers := numberSet(text)
+
+	for _, item := range parse.Items {
+		answer := finalAnswerString(item.SolutionInternal.FinalAnswer)
+		if answer == "" {
+			continue
+		}
+
+		if numberPattern.FindString(answer) == answer {
+			// Числовой ответ: ищем точное совпадение числа, если его нет в условии
+			if textNumbers[normalizeNumber(answer)] && !taskNumbers[normalizeNumber(answer)] {
+				return true
+			}
+			continue
+		}
+
+		// Текстовый ответ: ищем вхождение (слишком короткие ответы не проверяем)
+		normalizedAnswer := normalizeAnswerText(answer)
+		if len([]rune(normalizedAnswer)) >= 3 && strings.Contains(normalizedText, normalizedAnswer) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// finalAnswerString приводит final_answer (string | number | null) к строке
+func finalAnswerString(v interface{}) string {
+	switch a := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(a)
+	case float64:
+		return strings.TrimSpace(fmt.Sprintf("%v", a))
+	default:
+		return strings.TrimSpace(fmt.Sprint(a))
+	}
+}
+
+// normalizeAnswerText приводит текст к нижнему регистру и убирает пробелы
+func normalizeAnswerText(s string) string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "ё", "е")
+	return strings.Join(strings.Fields(s), "")
+}
+
+// normalizeNumber приводит запись числа к единому виду (запятая → точка)
+func normalizeNumber(s string) string {
+	return strings.ReplaceAll(s, ",", ".")
+}
+
+// numberSet возвращает множество чисел в тексте
+func numberSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, n := range numberPattern.FindAllString(s, -1) {
+		set[normalizeNumber(n)] = true
+	}
+	return set
+}
+
+// dialogLimiter ограничивает частоту вопросов одного ребёнка (скользящее окно).
+// Ключи без запросов в пределах окна удаляются, поэтому размер карты ограничен
+// числом детей, спрашивавших за последнее окно.
+type dialogLimiter struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	requests  map[string][]time.Time
+	lastSweep time.Time
+}
+
+// newDialogLimiter создает новый dialogLimiter
+func newDialogLimiter(limit int, window time.Duration) *dialogLimiter {
+	return &dialogLimiter{
+		limit:     limit,
+		window:    window,
+		requests:  make(map[string][]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow проверяет, можно ли принять ещё один вопрос
+func (l *dialogLimiter) Allow(key string) bool {
+	now := time.Now()
+	windowStart := now.Add(-l.window)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= l.window {
+		l.sweep(windowStart)
+		l.lastSweep = now
+	}
+
+	valid := l.requests[key][:0]
+	for _, t := range l.requests[key] {
+		if t.After(windowStart) {
+			valid = append(valid, t)
+		}
+	}
+
+	if len(valid) >= l.limit {
+		l.requests[key] = valid
+		return false
+	}
+
+	l.requests[key] = append(valid, now)
+	return true
+}
+
+// sweep удаляет ключи, последний запрос которых вышел за окно
+func (l *dialogLimiter) sweep(windowStart time.Time) {
+	for key, times := range l.requests {
+		if len(times) == 0 || !times[len(times)-1].After(windowStart) {
+			delete(l.requests, key)
+		}
+	}
+}
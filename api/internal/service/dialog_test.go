@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+
+	"child-bot/api/internal/llm/types"
+)
+
+func TestRevealsFinalAnswer(t *testing.T) {
+	parse := func(taskText string, answers ...interface{}) *types.ParseResponse {
+		p := &types.ParseResponse{Task: types.ParseTask{TaskTextClean: taskText}}
+		for _, a := range answers {
+			p.Items = append(p.Items, types.ParseItem{SolutionInternal: types.SolutionInternal{FinalAnswer: a}})
+		}
+		return p
+	}
+
+	tests := []struct {
+		name  string
+		text  string
+		parse *types.ParseResponse
+		want  bool
+	}{
+		{
+			name:  "no parse",
+			text:  "Ответ 17",
+			parse: nil,
+			want:  false,
+		},
+		{
+			name:  "numeric answer revealed",
+			text:  "Получится 17 яблок",
+			parse: parse("У Маши 12 яблок, ей дали ещё 5. Сколько стало?", float64(17)),
+			want:  true,
+		},
+		{
+			name:  "number from the condition is not the answer",
+			text:  "Сначала возьми 12 и прибавь 5",
+			parse: parse("У Маши 12 яблок, ей дали ещё 5. Сколько стало?", float64(17)),
+			want:  false,
+		},
+		{
+			name:  "answer inside a bigger number is not revealed",
+			text:  "Попробуй посчитать до 170",
+			parse: parse("Сколько будет 12 + 5?", "17"),
+			want:  false,
+		},
+		{
+			name:  "decimal answer with comma",
+			text:  "Выходит 2,5 литра",
+			parse: parse("Разлей 5 литров в 2 банки поровну", "2.5"),
+			want:  true,
+		},
+		{
+			name:  "text answer ignores case and spaces",
+			text:  "Это Северный   Полюс!",
+			parse: parse("Где живут белые медведи?", "северный полюс"),
+			want:  true,
+		},
+		{
+			name:  "too short text answer is not checked",
+			text:  "да, подумай ещё",
+			parse: parse("Верно ли, что 2 > 1?", "да"),
+			want:  false,
+		},
+		{
+			name:  "second item revealed",
+			text:  "Во втором пункте будет 40",
+			parse: parse("Реши: а) 3 + 4; б) 8 · 5", nil, float64(40)),
+			want:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := revealsFinalAnswer(tt.text, tt.parse); got != tt.want {
+				t.Errorf("revealsFinalAnswer(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
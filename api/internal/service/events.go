@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/store"
+)
+
+// Повторная доставка событий
+const (
+	eventRetryBaseDelay   = 30 * time.Second
+	eventRetryMaxDelay    = time.Hour
+	eventRetryMaxAttempts = 10
+	eventRetryLease       = 5 * time.Minute
+	eventRetryBatch       = 100
+)
+
+// EventHandler обработчик доменного события
+type EventHandler func(ctx context.Context, event domain.Event) error
+
+// EventOutbox хранилище событий, не доставленных подписчику
+type EventOutbox interface {
+	SavePendingEvent(ctx context.Context, event store.PendingEvent, nextAttemptAt time.Time) error
+	ClaimPendingEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]store.PendingEvent, error)
+	DeletePendingEvent(ctx context.Context, id int64) error
+	FailPendingEvent(ctx context.Context, id int64, lastError string, nextAttemptAt *time.Time) error
+}
+
+type eventSubscription struct {
+	subscriber string
+	handler    EventHandler
+}
+
+// EventBus внутренняя шина доменных событий.
+// Обработчики вызываются синхронно в порядке подписки, ошибка одного не мешает остальным.
+// Событие для упавшего обработчика записывается в outbox и доставляется повторно (RetryPending),
+// поэтому обработчики должны быть идемпотентны.
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]eventSubscription
+	outbox   EventOutbox
+	now      func() time.Time
+}
+
+// NewEventBus создает новый EventBus. Без outbox ошибки обработчиков только логируются.
+func NewEventBus(outbox EventOutbox) *EventBus {
+	return &EventBus{
+		handlers: make(map[string][]eventSubscription),
+		outbox:   outbox,
+		now:      time.Now,
+	}
+}
+
+// Subscribe подписывает обработчик subscriber на события типа eventType.
+// Имя подписчика сохраняется с недоставленным событием, чтобы повторить только его обработчик.
+func (b *EventBus) Subscribe(eventType, subscriber string, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], eventSubscription{subscriber: subscriber, handler: handler})
+}
+
+// Publish доставляет событие всем подписчикам. Nil-шина ничего не делает.
+func (b *EventBus) Publish(ctx context.Context, event domain.Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	subs := b.handlers[event.EventType()]
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if err := sub.handler(ctx, event); err != nil {
+			log.Printf("[EventBus] Handler %s failed for %s (child %s): %v",
+				sub.subscriber, event.EventType(), event.EventChildProfileID(), err)
+			b.savePending(ctx, sub.subscriber, event, err)
+		}
+	}
+}
+
+// savePending записывает событие для повторной доставки подписчику
+func (b *EventBus) savePending(ctx context.Context, subscriber string, event domain.Event, handlerErr error) {
+	if b.outbox == nil {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[EventBus] Failed to encode %s for retry: %v", event.EventType(), err)
+		return
+	}
+	// Запрос мог уже завершиться — событие всё равно нужно сохранить
+	err = b.outbox.SavePendingEvent(context.WithoutCancel(ctx), store.PendingEvent{
+		Subscriber:     subscriber,
+		EventType:      event.EventType(),
+		ChildProfileID: event.EventChildProfileID(),
+		Payload:        payload,
+		LastError:      handlerErr.Error(),
+	}, b.now().Add(eventRetryDelay(1)))
+	if err != nil {
+		log.Printf("[EventBus] Failed to save %s for %s (child %s), event lost: %v",
+			event.EventType(), subscriber, event.EventChildProfileID(), err)
+	}
+}
+
+// RetryPending повторяет доставку событий, срок которых наступил. Возвращает число доставленных.
+func (b *EventBus) RetryPending(ctx context.Context) (int, error) {
+	if b == nil || b.outbox == nil {
+		return 0, nil
+	}
+	pending, err := b.outbox.ClaimPendingEvents(ctx, b.now(), eventRetryLease, eventRetryBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, p := range pending {
+		err := b.deliver(ctx, p)
+		if err == nil {
+			if err := b.outbox.DeletePendingEvent(ctx, p.ID); err != nil {
+				return delivered, err
+			}
+			delivered++
+			continue
+		}
+
+		var next *time.Time
+		if p.Attempts+1 < eventRetryMaxAttempts {
+			at := b.now().Add(eventRetryDelay(p.Attempts + 1))
+			next = &at
+		} else {
+			log.Printf("[EventBus] Giving up on %s for %s (child %s) after %d attempts: %v",
+				p.EventType, p.Subscriber, p.ChildProfileID, p.Attempts+1, err)
+		}
+		if err := b.outbox.FailPendingEvent(ctx, p.ID, err.Error(), next); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// RunRetries повторяет недоставленные события каждые interval до отмены ctx
+func (b *EventBus) RunRetries(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			delivered, err := b.RetryPending(ctx)
+			if err != nil {
+				log.Printf("[EventBus] Retry failed: %v", err)
+			}
+			if delivered > 0 {
+				log.Printf("[EventBus] Redelivered %d events", delivered)
+			}
+		}
+	}
+}
+
+// deliver вызывает обработчик подписчика для сохранённого события
+func (b *EventBus) deliver(ctx context.Context, p store.PendingEvent) error {
+	event, err := domain.DecodeEvent(p.EventType, p.Payload)
+	if err != nil {
+		return err
+	}
+
+	b.mu.RLock()
+	subs := b.handlers[p.EventType]
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if sub.subscriber == p.Subscriber {
+			return sub.handler(ctx, event)
+		}
+	}
+	return fmt.Errorf("no subscriber %q for %s", p.Subscriber, p.EventType)
+}
+
+// eventRetryDelay пауза перед попыткой номер attempt+1: 30с, 1м, 2м, ... не больше часа
+func eventRetryDelay(attempt int) time.Duration {
+	delay := eventRetryBaseDelay
+	for i := 1; i < attempt && delay < eventRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, eventRetryMaxDelay)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/store"
+)
+
+// memoryOutbox хранит недоставленные события в памяти
+type memoryOutbox struct {
+	nextID  int64
+	pending map[int64]*store.PendingEvent
+	due     map[int64]*time.Time
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{pending: map[int64]*store.PendingEvent{}, due: map[int64]*time.Time{}}
+}
+
+func (o *memoryOutbox) SavePendingEvent(_ context.Context, event store.PendingEvent, nextAttemptAt time.Time) error {
+	o.nextID++
+	event.ID = o.nextID
+	event.Attempts = 1
+	o.pending[event.ID] = &event
+	o.due[event.ID] = &nextAttemptAt
+	return nil
+}
+
+func (o *memoryOutbox) ClaimPendingEvents(_ context.Context, now time.Time, lease time.Duration, _ int) ([]store.PendingEvent, error) {
+	var claimed []store.PendingEvent
+	for id, e := range o.pending {
+		if at := o.due[id]; at != nil && !at.After(now) {
+			leased := now.Add(lease)
+			o.due[id] = &leased
+			claimed = append(claimed, *e)
+		}
+	}
+	return claimed, nil
+}
+
+func (o *memoryOutbox) DeletePendingEvent(_ context.Context, id int64) error {
+	delete(o.pending, id)
+	delete(o.due, id)
+	return nil
+}
+
+func (o *memoryOutbox) FailPendingEvent(_ context.Context, id int64, lastError string, nextAttemptAt *time.Time) error {
+	o.pending[id].Attempts++
+	o.pending[id].LastError = lastError
+	o.due[id] = nextAttemptAt
+	return nil
+}
+
+func TestEventBus_RedeliversToFailedSubscriberOnly(t *testing.T) {
+	ctx := context.Background()
+	outbox := newMemoryOutbox()
+	bus := NewEventBus(outbox)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	bus.now = func() time.Time { return now }
+
+	var okCalls, flakyCalls int
+	var redelivered domain.Event
+	bus.Subscribe(domain.EventAttemptCompleted, "ok", func(context.Context, domain.Event) error {
+		okCalls++
+		return nil
+	})
+	bus.Subscribe(domain.EventAttemptCompleted, "flaky", func(_ context.Context, e domain.Event) error {
+		flakyCalls++
+		if flakyCalls < 3 {
+			return errors.New("db is down")
+		}
+		redelivered = e
+		return nil
+	})
+
+	event := domain.AttemptCompleted{ChildProfileID: "child-1", AttemptID: "attempt-1", IsCorrect: true}
+	bus.Publish(ctx, event)
+	if okCalls != 1 || flakyCalls != 1 || len(outbox.pending) != 1 {
+		t.Fatalf("after publish: ok=%d flaky=%d pending=%d, want 1/1/1", okCalls, flakyCalls, len(outbox.pending))
+	}
+
+	// Рано — повтор не наступил
+	if n, err := bus.RetryPending(ctx); err != nil || n != 0 || flakyCalls != 1 {
+		t.Fatalf("early RetryPending() = %d, %v (flaky=%d), want nothing retried", n, err, flakyCalls)
+	}
+
+	// Первый повтор снова падает, второй доставляет
+	now = now.Add(eventRetryDelay(1))
+	if n, _ := bus.RetryPending(ctx); n != 0 || flakyCalls != 2 {
+		t.Fatalf("first RetryPending() = %d (flaky=%d), want failed retry", n, flakyCalls)
+	}
+	now = now.Add(eventRetryDelay(2))
+	if n, err := bus.RetryPending(ctx); err != nil || n != 1 {
+		t.Fatalf("second RetryPending() = %d, %v; want 1 delivered", n, err)
+	}
+
+	if okCalls != 1 {
+		t.Errorf("ok subscriber called %d times, want 1", okCalls)
+	}
+	if !reflect.DeepEqual(redelivered, event) {
+		t.Errorf("redelivered event = %#v, want %#v", redelivered, event)
+	}
+	if len(outbox.pending) != 0 {
+		t.Errorf("pending after delivery = %d, want 0", len(outbox.pending))
+	}
+}
+
+func TestEventBus_GivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	outbox := newMemoryOutbox()
+	bus := NewEventBus(outbox)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	bus.now = func() time.Time { return now }
+	bus.Subscribe(domain.EventStreakUpdated, "broken", func(context.Context, domain.Event) error {
+		return errors.New("always fails")
+	})
+
+	bus.Publish(ctx, domain.StreakUpdated{ChildProfileID: "child-1", StreakDays: 3})
+	for i := 0; i < eventRetryMaxAttempts+2; i++ {
+		now = now.Add(eventRetryMaxDelay)
+		if _, err := bus.RetryPending(ctx); err != nil {
+			t.Fatalf("RetryPending() error = %v", err)
+		}
+	}
+
+	if len(outbox.pending) != 1 {
+		t.Fatalf("pending = %d, want the exhausted event kept", len(outbox.pending))
+	}
+	for id, e := range outbox.pending {
+		if e.Attempts != eventRetryMaxAttempts || outbox.due[id] != nil {
+			t.Errorf("pending = %+v (due %v), want %d attempts and no next attempt", e, outbox.due[id], eventRetryMaxAttempts)
+		}
+	}
+}
+
+func TestEventRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := eventRetryDelay(tt.attempt); got != tt.want {
+			t.Errorf("eventRetryDelay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/family"
+	"child-bot/api/internal/store"
+)
+
+// familyHistoryDays за сколько дней показывается история квестов
+const familyHistoryDays = 30
+
+// FamilyService семейные квесты: ребёнок продвигает их проверками, родитель — подтверждениями,
+// при выполнении ребёнок получает награду, а оба — уведомление
+type FamilyService struct {
+	store   *store.Store
+	catalog *family.Catalog
+	now     func() time.Time
+}
+
+// NewFamilyService создает новый FamilyService с проверенным каталогом квестов
+func NewFamilyService(store *store.Store, catalog *family.Catalog) *FamilyService {
+	return &FamilyService{store: store, catalog: catalog, now: time.Now}
+}
+
+// FamilyQuests доступные шаблоны и квесты ребёнка
+type FamilyQuests struct {
+	Templates []family.Template   // шаблоны без активного квеста — их можно начать
+	Active    []store.FamilyQuest // идущие квесты
+	History   []store.FamilyQuest // выполненные и истёкшие за последние 30 дней
+}
+
+// Subscribe подписывает семейные квесты на проверки
+func (s *FamilyService) Subscribe(bus *EventBus) {
+	bus.Subscribe(domain.EventAttemptCompleted, "family", s.HandleEvent)
+}
+
+// HandleEvent засчитывает проверку активным квестам ребёнка
+func (s *FamilyService) HandleEvent(ctx context.Context, event domain.Event) error {
+	e, ok := event.(domain.AttemptCompleted)
+	if !ok {
+		return nil
+	}
+
+	childProfileID := e.EventChildProfileID()
+	loc := childLocation(ctx, s.store, childProfileID)
+	res, err := s.store.AdvanceFamilyQuestsForCheck(ctx, childProfileID, e.AttemptID, e.IsCorrect, s.now(), loc)
+	if err != nil {
+		return fmt.Errorf("advance family quests: %w", err)
+	}
+	logFamilyCompletions(childProfileID, res)
+	return nil
+}
+
+// GetQuests квесты ребёнка и шаблоны, которые можно начать
+func (s *FamilyService) GetQuests(ctx context.Context, childProfileID string) (*FamilyQuests, error) {
+	now := s.now()
+	quests, err := s.store.ListFamilyQuests(ctx, childProfileID, now.AddDate(0, 0, -familyHistoryDays), now)
+	if err != nil {
+		return nil, fmt.Errorf("list family quests: %w", err)
+	}
+
+	result := &FamilyQuests{
+		Templates: []family.Template{},
+		Active:    []store.FamilyQuest{},
+		History:   []store.FamilyQuest{},
+	}
+	active := make(map[string]bool)
+	for _, q := range quests {
+		if q.Status == store.FamilyQuestActive {
+			active[q.TemplateID] = true
+			result.Active = append(result.Active, q)
+		} else {
+			result.History = append(result.History, q)
+		}
+	}
+	if s.catalog != nil {
+		for _, t := range s.catalog.Templates {
+			if !active[t.ID] {
+				result.Templates = append(result.Templates, t)
+			}
+		}
+	}
+	return result, nil
+}
+
+// StartQuest начинает квест по шаблону. Начать может ребёнок или родитель;
+// родитель подтверждается родительской сессией (ParentService).
+func (s *FamilyService) StartQuest(ctx context.Context, childProfileID, templateID, parentSession string) (*store.FamilyQuest, error) {
+	t, ok := s.template(templateID)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	startedBy := store.FamilyActorChild
+	if parentSession != "" {
+		if _, err := parentFromSession(ctx, s.store, childProfileID, parentSession); err != nil {
+			return nil, err
+		}
+		startedBy = store.FamilyActorParent
+	}
+
+	now := s.now()
+	loc := childLocation(ctx, s.store, childProfileID)
+	quest, err := s.store.StartFamilyQuest(ctx, childProfileID, t, startedBy, now, t.EndsAt(now, loc))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[FamilyService] Quest %s started by %s for child %s", t.ID, startedBy, childProfileID)
+	return quest, nil
+}
+
+// ConfirmQuest засчитывает подтверждение родителя. Повтор с тем же ключом не засчитывается.
+func (s *FamilyService) ConfirmQuest(ctx context.Context, childProfileID string, questID int64, parentSession, key, note string) (*store.FamilyQuest, bool, error) {
+	if _, err := parentFromSession(ctx, s.store, childProfileID, parentSession); err != nil {
+		return nil, false, err
+	}
+
+	quest, res, err := s.store.ConfirmFamilyQuest(ctx, childProfileID, questID, key, note, s.now())
+	if err != nil {
+		return nil, false, err
+	}
+	logFamilyCompletions(childProfileID, res)
+	return quest, len(res.Completed) > 0, nil
+}
+
+// GetNotifications уведомления ребёнка или родителя и число непрочитанных
+func (s *FamilyService) GetNotifications(ctx context.Context, childProfileID, recipient, parentSession string, limit int) ([]store.FamilyNotification, int, error) {
+	if err := s.checkRecipient(ctx, childProfileID, recipient, parentSession); err != nil {
+		return nil, 0, err
+	}
+	return s.store.ListFamilyNotifications(ctx, childProfileID, recipient, limit)
+}
+
+// MarkNotificationRead отмечает уведомление прочитанным
+func (s *FamilyService) MarkNotificationRead(ctx context.Context, childProfileID, recipient, parentSession string, id int64) error {
+	if err := s.checkRecipient(ctx, childProfileID, recipient, parentSession); err != nil {
+		return err
+	}
+	return s.store.MarkFamilyNotificationRead(ctx, childProfileID, recipient, id)
+}
+
+func (s *FamilyService) template(id string) (family.Template, bool) {
+	if s.catalog != nil {
+		for _, t := range s.catalog.Templates {
+			if t.ID == id {
+				return t, true
+			}
+		}
+	}
+	return family.Template{}, false
+}
+
+func (s *FamilyService) checkRecipient(ctx context.Context, childProfileID, recipient, parentSession string) error {
+	switch recipient {
+	case store.FamilyActorChild:
+		return nil
+	case store.FamilyActorParent:
+		_, err := parentFromSession(ctx, s.store, childProfileID, parentSession)
+		return err
+	}
+	return domain.ErrInvalidInput
+}
+
+func logFamilyCompletions(childProfileID string, res *store.FamilyQuestAdvanceResult) {
+	for _, q := range res.Completed {
+		log.Printf("[FamilyService] Quest %s completed by child %s (+%d coins, +%d xp, badge %q)",
+			q.TemplateID, childProfileID, q.RewardCoins, q.RewardXP, q.RewardBadgeID)
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/store"
+)
+
+// Ограничения наград за монеты
+const (
+	MaxFamilyRewards         = 20     // активных наград в каталоге ребёнка
+	MaxFamilyRewardPrice     = 100000 // монет
+	MaxPendingRedemptions    = 3      // запросов, ждущих решения родителя
+	familyRedemptionHistDays = 30     // за сколько дней показываются решённые запросы
+	defaultFamilyRewardIcon  = "🎁"
+)
+
+// FamilyRewardService награды в реальном мире за монеты: каталог ведёт родитель,
+// ребёнок запрашивает награду (монеты списываются сразу), родитель одобряет или
+// отклоняет запрос — при отказе монеты возвращаются
+type FamilyRewardService struct {
+	store *store.Store
+	now   func() time.Time
+}
+
+// NewFamilyRewardService создает новый FamilyRewardService
+func NewFamilyRewardService(store *store.Store) *FamilyRewardService {
+	return &FamilyRewardService{store: store, now: time.Now}
+}
+
+// FamilyRewardInput награда, которую добавляет или меняет родитель
+type FamilyRewardInput struct {
+	Title       string
+	Description string
+	Icon        string
+	Price       int
+}
+
+// FamilyRewards каталог наград ребёнка, его запросы и баланс
+type FamilyRewards struct {
+	Rewards      []store.FamilyReward
+	Redemptions  []store.FamilyRedemption // ожидающие и решённые за 30 дней, новые первыми
+	CoinsBalance int
+}
+
+// GetRewards каталог наград, запросы ребёнка и баланс монет
+func (s *FamilyRewardService) GetRewards(ctx context.Context, childProfileID string) (*FamilyRewards, error) {
+	result := &FamilyRewards{}
+	query := `SELECT COALESCE(coins_balance, 0) FROM child_profiles WHERE id = $1`
+	if err := s.store.DB.QueryRowContext(ctx, query, childProfileID).Scan(&result.CoinsBalance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get coins balance: %w", err)
+	}
+
+	rewards, err := s.store.ListFamilyRewards(ctx, childProfileID)
+	if err != nil {
+		return nil, err
+	}
+	redemptions, err := s.store.ListFamilyRedemptions(ctx, childProfileID, s.now().AddDate(0, 0, -familyRedemptionHistDays))
+	if err != nil {
+		return nil, err
+	}
+	result.Rewards = append([]store.FamilyReward{}, rewards...)
+	result.Redemptions = append([]store.FamilyRedemption{}, redemptions...)
+	return result, nil
+}
+
+// CreateReward добавляет награду в каталог ребёнка (только родитель)
+func (s *FamilyRewardService) CreateReward(ctx context.Context, childProfileID, parentSession string, in FamilyRewardInput) (*store.FamilyReward, error) {
+	parentUserID, err := parentFromSession(ctx, s.store, childProfileID, parentSession)
+	if err != nil {
+		return nil, err
+	}
+	in, err = normalizeFamilyReward(in)
+	if err != nil {
+		return nil, err
+	}
+
+	reward, err := s.store.CreateFamilyReward(ctx, store.FamilyReward{
+		ChildProfileID: childProfileID,
+		Title:          in.Title,
+		Description:    in.Description,
+		Icon:           in.Icon,
+		Price:          in.Price,
+		CreatedBy:      parentUserID,
+	}, MaxFamilyRewards)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[FamilyRewardService] Parent %s added reward %d %q (%d coins) for child %s",
+		parentUserID, reward.ID, reward.Title, reward.Price, childProfileID)
+	return reward, nil
+}
+
+// UpdateReward меняет награду (только родитель); поданные запросы сохраняют прежнюю цену
+func (s *FamilyRewardService) UpdateReward(ctx context.Context, childProfileID string, id int64, parentSession string, in FamilyRewardInput) (*store.FamilyReward, error) {
+	if _, err := parentFromSession(ctx, s.store, childProfileID, parentSession); err != nil {
+		return nil, err
+	}
+	in, err := normalizeFamilyReward(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.UpdateFamilyReward(ctx, store.FamilyReward{
+		ID:             id,
+		ChildProfileID: childProfileID,
+		Title:          in.Title,
+		Description:    in.Description,
+		Icon:           in.Icon,
+		Price:          in.Price,
+	})
+}
+
+// ArchiveReward снимает награду из каталога (только родитель); ожидающие запросы остаются
+func (s *FamilyRewardService) ArchiveReward(ctx context.Context, childProfileID string, id int64, parentSession string) error {
+	if _, err := parentFromSession(ctx, s.store, childProfileID, parentSession); err != nil {
+		return err
+	}
+	return s.store.ArchiveFamilyReward(ctx, childProfileID, id)
+}
+
+// Redeem запрашивает награду: монеты списываются и ждут решения родителя.
+// Повтор с тем же idempotencyKey возвращает прежний запрос.
+func (s *FamilyRewardService) Redeem(ctx context.Context, childProfileID string, rewardID int64, idempotencyKey string) (*store.FamilyRedemption, bool, error) {
+	redemption, replayed, err := s.store.RedeemFamilyReward(ctx, childProfileID, rewardID, idempotencyKey, MaxPendingRedemptions)
+	if err != nil {
+		return nil, false, err
+	}
+	log.Printf("[FamilyRewardService] Child %s requested reward %d for %d coins (replayed=%v)",
+		childProfileID, rewardID, redemption.Price, replayed)
+	return redemption, replayed, nil
+}
+
+// Decide одобряет или отклоняет запрос награды (только родитель)
+func (s *FamilyRewardService) Decide(ctx context.Context, childProfileID string, id int64, parentSession string, approve bool, note string) (*store.FamilyRedemption, error) {
+	if _, err := parentFromSession(ctx, s.store, childProfileID, parentSession); err != nil {
+		return nil, err
+	}
+	return s.store.DecideFamilyRedemption(ctx, childProfileID, id, approve, strings.TrimSpace(note), s.now())
+}
+
+// normalizeFamilyReward обрезает пробелы, подставляет иконку и проверяет цену
+func normalizeFamilyReward(in FamilyRewardInput) (FamilyRewardInput, error) {
+	in.Title = strings.TrimSpace(in.Title)
+	in.Description = strings.TrimSpace(in.Description)
+	in.Icon = strings.TrimSpace(in.Icon)
+	if in.Icon == "" {
+		in.Icon = defaultFamilyRewardIcon
+	}
+	if in.Title == "" || in.Price <= 0 || in.Price > MaxFamilyRewardPrice {
+		return in, domain.ErrInvalidInput
+	}
+	return in, nil
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/llm"
+	"child-bot/api/internal/llm/types"
+	"child-bot/api/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// maxHintRegenerations ограничивает число перегенераций одной подсказки
+const maxHintRegenerations = 2
+
+// MaxHintCommentLength максимальная длина комментария к оценке подсказки
+const MaxHintCommentLength = 300
+
+// hintRef позиция подсказки в hints_result
+type hintRef struct {
+	item  int
+	hint  int
+	level types.HintLevel
+	text  string
+}
+
+// helpHints подсказки help попытки вместе с разбором задачи
+type helpHints struct {
+	id      uuid.UUID
+	attempt *store.Attempt
+	result  types.HintResponse
+	parse   *types.ParseResponse
+	refs    []hintRef
+}
+
+// RateHint сохраняет оценку уже показанной подсказки
+func (s *AttemptService) RateHint(ctx context.Context, attemptID, childProfileID string, hintIndex int, rating, comment string) error {
+	if !domain.IsValidHintRating(rating) {
+		return domain.ErrInvalidInput
+	}
+
+	hh, err := s.loadShownHint(ctx, attemptID, childProfileID, hintIndex)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Attempts.SaveHintFeedback(ctx, newHintFeedback(hh, hintIndex, rating, comment)); err != nil {
+		return err
+	}
+
+	log.Printf("[AttemptService] Hint rated: attempt=%s, hint_index=%d, rating=%s", attemptID, hintIndex, rating)
+	return nil
+}
+
+// RegenerateHint перегенерирует показанную подсказку на том же уровне,
+// передавая в LLM оценку ребёнка (непонятно / раскрыла ответ)
+func (s *AttemptService) RegenerateHint(ctx context.Context, attemptID, childProfileID string, hintIndex int, rating, comment string) (*domain.RegeneratedHint, error) {
+	if rating == "" {
+		rating = domain.HintRatingUnclear
+	}
+	// Полезную подсказку перегенерировать незачем
+	if !domain.IsValidHintRating(rating) || rating == domain.HintRatingHelpful {
+		return nil, domain.ErrInvalidInput
+	}
+
+	hh, err := s.loadShownHint(ctx, attemptID, childProfileID, hintIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Перегенерация засчитывается до запроса к LLM: лимит не превысить параллельными запросами
+	regenerations, err := s.store.Attempts.ReserveHintRegeneration(ctx, newHintFeedback(hh, hintIndex, rating, comment), maxHintRegenerations)
+	if err != nil {
+		return nil, err
+	}
+	release := func() {
+		if err := s.store.Attempts.ReleaseHintRegeneration(ctx, hh.id, hintIndex); err != nil {
+			log.Printf("[AttemptService] Failed to release hint regeneration for attempt %s: %v", attemptID, err)
+		}
+	}
+
+	ref := hh.refs[hintIndex]
+	entry := findParseTemplate(hh.parse)
+
+	text, err := s.requestRegeneratedHint(ctx, hh.parse, entry, ref, rating, comment)
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("hint regeneration failed: %w", err)
+	}
+
+	// Новая подсказка не должна раскрывать ответ — иначе берём правило уровня из шаблона
+	if text == "" || revealsFinalAnswer(text, hh.parse) {
+		log.Printf("[AttemptService] Regenerated hint is empty or reveals answer, using template rule (attempt=%s)", attemptID)
+		text = templateLevelRule(entry, ref.level)
+	}
+	if text == "" {
+		release()
+		return nil, fmt.Errorf("hint regeneration returned no hint for level %s", ref.level)
+	}
+
+	hh.result.Items[ref.item].Hints[ref.hint].HintText = text
+	if err := s.store.Attempts.SaveHintsResult(ctx, hh.id, &hh.result); err != nil {
+		release()
+		return nil, err
+	}
+
+	log.Printf("[AttemptService] Hint regenerated: attempt=%s, hint_index=%d, level=%s, rating=%s",
+		attemptID, hintIndex, ref.level, rating)
+
+	return &domain.RegeneratedHint{
+		Hint:              text,
+		HintIndex:         hintIndex,
+		TotalHints:        len(hh.refs),
+		RegenerationsLeft: maxHintRegenerations - regenerations,
+	}, nil
+}
+
+// loadShownHint загружает подсказки help попытки и проверяет, что подсказка уже была показана
+func (s *AttemptService) loadShownHint(ctx context.Context, attemptID, childProfileID string, hintIndex int) (*helpHints, error) {
+	id, err := uuid.Parse(attemptID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attempt_id: %w", err)
+	}
+
+	attempt, err := s.store.Attempts.GetAttempt(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempt: %w", err)
+	}
+
+	if attempt.ChildProfileID.String() != childProfileID {
+		return nil, domain.ErrForbidden
+	}
+
+	if attempt.AttemptType != "help" || len(attempt.HintsResult) == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	hh := &helpHints{id: id, attempt: attempt}
+	if err := json.Unmarshal(attempt.HintsResult, &hh.result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hints_result: %w", err)
+	}
+
+	// Тот же плоский порядок, что и в GetNextHint
+	for i, item := range hh.result.Items {
+		for j, hint := range item.Hints {
+			hh.refs = append(hh.refs, hintRef{item: i, hint: j, level: hint.Level, text: hint.HintText})
+		}
+	}
+
+	// Оценивать можно только подсказки, которые ребёнок уже видел
+	if hintIndex < 0 || hintIndex >= len(hh.refs) || hintIndex >= attempt.CurrentHintIndex {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if len(attempt.ParseResult) > 0 {
+		if err := json.Unmarshal(attempt.ParseResult, &hh.parse); err != nil {
+			log.Printf("[AttemptService] Failed to unmarshal parse_result: %v", err)
+			hh.parse = nil
+		}
+	}
+
+	return hh, nil
+}
+
+// requestRegeneratedHint запрашивает у LLM новую подсказку того же уровня.
+// Оценка и прошлая подсказка передаются вместе с template_profile_core.
+func (s *AttemptService) requestRegeneratedHint(ctx context.Context, parseResult *types.ParseResponse, entry *llm.TemplateEntry, ref hintRef, rating, comment string) (string, error) {
+	if parseResult == nil || s.llmClient == nil {
+		return "", nil
+	}
+
+	profileCore := map[string]interface{}{}
+	if entry != nil {
+		profileCore = entry.ProfileCore()
+	}
+
+	instruction := "Ребёнку непонятна подсказка. Объясни ту же мысль проще и короче, другими словами."
+	if rating == domain.HintRatingGaveAwayAnswer {
+		instruction = "Подсказка раскрыла ответ. Дай более общую подсказку того же уровня, не называя ответ и промежуточные результаты."
+	}
+	profileCore["hint_feedback"] = map[string]interface{}{
+		"level":         ref.level,
+		"previous_hint": ref.text,
+		"rating":        rating,
+		"comment":       comment,
+		"instruction":   instruction,
+	}
+
+	templateJSON, err := json.Marshal(profileCore)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal template: %w", err)
+	}
+
+	policy := types.HintPolicy{
+		MaxHints:       3,
+		DefaultVisible: 1,
+		H3Reason:       types.H3ReasonNone,
+	}
+	if len(parseResult.Items) > 0 && parseResult.Items[0].HintPolicy.MaxHints > 0 {
+		policy = parseResult.Items[0].HintPolicy
+	}
+
+	hintResp, err := s.llmClient.Hint(ctx, s.defaultLLM, types.HintRequest{
+		Task:          parseResult.Task,
+		Mode:          string(types.HintModeLearn),
+		Items:         parseResult.Items,
+		AppliedPolicy: policy,
+		Template:      string(templateJSON),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Сначала ищем подсказку того же уровня для того же пункта задачи
+	if ref.item < len(hintResp.Items) {
+		if text := firstHintText(hintResp.Items[ref.item].Hints, ref.level); text != "" {
+			return strings.TrimSpace(text), nil
+		}
+	}
+	for _, item := range hintResp.Items {
+		if text := firstHintText(item.Hints, ref.level); text != "" {
+			return strings.TrimSpace(text), nil
+		}
+	}
+
+	return "", nil
+}
+
+// newHintFeedback формирует запись оценки подсказки
+func newHintFeedback(hh *helpHints, hintIndex int, rating, comment string) store.HintFeedback {
+	ref := hh.refs[hintIndex]
+
+	templateID := ""
+	if entry := findParseTemplate(hh.parse); entry != nil {
+		templateID = entry.Template.TemplateID
+	} else if hh.parse != nil && ref.item < len(hh.parse.Items) {
+		templateID = hh.parse.Items[ref.item].PedKeys.TemplateId
+	}
+
+	return store.HintFeedback{
+		AttemptID:      hh.id,
+		ChildProfileID: hh.attempt.ChildProfileID,
+		HintIndex:      hintIndex,
+		HintLevel:      string(ref.level),
+		TemplateID:     templateID,
+		Rating:         rating,
+		Comment:        sql.NullString{String: comment, Valid: comment != ""},
+	}
+}
+
+// templateLevelRule возвращает первое правило уровня подсказки из шаблона
+func templateLevelRule(entry *llm.TemplateEntry, level types.HintLevel) string {
+	if entry == nil {
+		return ""
+	}
+
+	pattern := entry.Profile.TeachingPattern
+	var rules []string
+	switch level {
+	case types.HintL1:
+		rules = pattern.L1.Rules
+	case types.HintL2:
+		rules = pattern.L2.Rules
+	case types.HintL3:
+		rules = pattern.L3.Rules
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+	return rules[0]
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/llm/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// adaptiveHistoryLimit сколько последних попыток по теме учитывается
+	adaptiveHistoryLimit = 20
+	// minAdaptiveSamples меньше попыток — политику из PARSE не меняем
+	minAdaptiveSamples = 3
+
+	// Ребёнку нужна поддержка: часто доходит до L3, ошибается или долго решает
+	gentleL3Share     = 0.5
+	gentleCorrectRate = 0.5
+	gentleTimeSeconds = 900.0
+
+	// Сильный ребёнок: почти без подсказок, решает верно и быстро
+	compactAvgHints    = 1.0
+	compactCorrectRate = 0.8
+	compactTimeSeconds = 300.0
+)
+
+// adaptiveHintPolicy подбирает политику подсказок по истории ребёнка: сначала по тому же
+// шаблону, а если истории мало — по тому же типу задачи
+func (s *AttemptService) adaptiveHintPolicy(ctx context.Context, childProfileID, attemptID uuid.UUID, parse *types.ParseResponse) *domain.AdaptiveHintPolicy {
+	base := types.HintPolicy{
+		MaxHints:       3,
+		DefaultVisible: 1,
+		H3Reason:       types.H3ReasonNone,
+	}
+	if parse == nil || len(parse.Items) == 0 {
+		return adaptHintPolicy(base, "none", "", nil)
+	}
+	if parse.Items[0].HintPolicy.MaxHints > 0 {
+		base = parse.Items[0].HintPolicy
+	}
+
+	pedKeys := parse.Items[0].PedKeys
+	scopes := []struct{ key, value string }{
+		{"template_id", pedKeys.TemplateId},
+		{"task_type", pedKeys.TaskType},
+	}
+
+	for _, scope := range scopes {
+		if scope.value == "" {
+			continue
+		}
+		stats, err := s.store.Attempts.GetHintHistoryStats(ctx, childProfileID, attemptID, scope.key, scope.value, adaptiveHistoryLimit)
+		if err != nil {
+			log.Printf("[AttemptService] Failed to get hint history by %s for %s: %v", scope.key, childProfileID, err)
+			continue
+		}
+		if stats.HelpAttempts+stats.CheckAttempts < minAdaptiveSamples {
+			continue
+		}
+		return adaptHintPolicy(base, scope.key, scope.value, &historyStats{
+			help:        stats.HelpAttempts,
+			check:       stats.CheckAttempts,
+			avgHints:    stats.AvgHintsUsed,
+			l3Share:     stats.L3Share,
+			correctRate: stats.CorrectRate,
+			avgTime:     stats.AvgTimeSeconds,
+		})
+	}
+
+	return adaptHintPolicy(base, "none", "", nil)
+}
+
+// attemptChildProfileID возвращает ребёнка, которому принадлежит попытка (uuid.Nil если не найдена)
+func (s *AttemptService) attemptChildProfileID(ctx context.Context, attemptID uuid.UUID) uuid.UUID {
+	attempt, err := s.store.Attempts.GetAttempt(ctx, attemptID)
+	if err != nil {
+		log.Printf("[AttemptService] Failed to load attempt %s for hint policy: %v", attemptID, err)
+		return uuid.Nil
+	}
+	return attempt.ChildProfileID
+}
+
+// historyStats агрегаты истории ребёнка, на которых строится политика
+type historyStats struct {
+	help        int
+	check       int
+	avgHints    float64
+	l3Share     float64
+	correctRate float64
+	avgTime     float64
+}
+
+// adaptHintPolicy строит политику из базовой политики PARSE и статистики ребёнка
+func adaptHintPolicy(base types.HintPolicy, scope, scopeKey string, stats *historyStats) *domain.AdaptiveHintPolicy {
+	policy := &domain.AdaptiveHintPolicy{
+		Style:          domain.HintStyleStandard,
+		MaxHints:       base.MaxHints,
+		DefaultVisible: base.DefaultVisible,
+		BaseMaxHints:   base.MaxHints,
+		Scope:          scope,
+		ScopeKey:       scopeKey,
+	}
+
+	if stats == nil {
+		policy.Scope = "none"
+		policy.ScopeKey = ""
+		policy.Reasons = []string{"мало истории по этой теме — используем политику из разбора задачи"}
+		return policy
+	}
+
+	policy.Samples = stats.help + stats.check
+	policy.AvgHintsUsed = stats.avgHints
+	policy.L3Share = stats.l3Share
+	policy.CorrectRate = stats.correctRate
+	policy.AvgTimeSeconds = stats.avgTime
+
+	// Признаки того, что ребёнку нужна более мягкая лестница
+	var gentle []string
+	if stats.help > 0 && stats.l3Share >= gentleL3Share {
+		gentle = append(gentle, fmt.Sprintf("в %.0f%% похожих задач понадобилась третья подсказка", stats.l3Share*100))
+	}
+	if stats.check > 0 && stats.correctRate < gentleCorrectRate {
+		gentle = append(gentle, fmt.Sprintf("верно решено только %.0f%% проверок", stats.correctRate*100))
+	}
+	if stats.avgTime >= gentleTimeSeconds {
+		gentle = append(gentle, fmt.Sprintf("в среднем на задачу уходит %.0f мин", stats.avgTime/60))
+	}
+
+	if len(gentle) > 0 {
+		policy.Style = domain.HintStyleGentle
+		policy.MaxHints = max(base.MaxHints, 3)
+		policy.DefaultVisible = 1
+		policy.Reasons = append(gentle, "даём больше мелких шагов и мягкую первую подсказку")
+		return policy
+	}
+
+	// Сильный ребёнок: мало подсказок, высокая точность, быстрое решение
+	strong := (stats.help == 0 || stats.avgHints <= compactAvgHints) &&
+		(stats.check == 0 || stats.correctRate >= compactCorrectRate) &&
+		(stats.avgTime == 0 || stats.avgTime <= compactTimeSeconds)
+	if strong {
+		policy.Style = domain.HintStyleCompact
+		policy.MaxHints = max(min(base.MaxHints, 2), 1)
+		policy.DefaultVisible = 1
+		policy.Reasons = []string{
+			fmt.Sprintf("в среднем %.1f подсказки на задачу, верно %.0f%% проверок", stats.avgHints, stats.correctRate*100),
+			"даём меньше подсказок и короче формулировки",
+		}
+		return policy
+	}
+
+	policy.Reasons = []string{"результаты ребёнка в норме — используем политику из разбора задачи"}
+	return policy
+}
+
+// appliedHintPolicy преобразует адаптивную политику в политику для HINT
+func appliedHintPolicy(policy *domain.AdaptiveHintPolicy, base types.H3Reason) types.HintPolicy {
+	if base == "" {
+		base = types.H3ReasonNone
+	}
+	return types.HintPolicy{
+		MaxHints:       policy.MaxHints,
+		DefaultVisible: policy.DefaultVisible,
+		H3Reason:       base,
+	}
+}
+
+// adaptiveInstruction возвращает указание для LLM по стилю подсказок
+func adaptiveInstruction(style string) string {
+	switch style {
+	case domain.HintStyleGentle:
+		return "Ребёнку часто нужна помощь с этой темой. Сделай первую подсказку особенно мягкой: один маленький шаг, простые слова, без новых терминов."
+	case domain.HintStyleCompact:
+		return "Ребёнок уверенно решает такие задачи. Дай меньше подсказок, каждая — одно короткое предложение."
+	default:
+		return ""
+	}
+}
+
+// trimHintsToPolicy оставляет в каждом пункте не больше maxHints подсказок (L1 → L3)
+func trimHintsToPolicy(resp *types.HintResponse, maxHints int) {
+	if maxHints <= 0 {
+		return
+	}
+	for i := range resp.Items {
+		if len(resp.Items[i].Hints) > maxHints {
+			resp.Items[i].Hints = resp.Items[i].Hints[:maxHints]
+		}
+	}
+}
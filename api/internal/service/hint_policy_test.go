@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/llm/types"
+)
+
+func TestAdaptHintPolicy(t *testing.T) {
+	base := types.HintPolicy{MaxHints: 2, DefaultVisible: 0, H3Reason: types.H3ReasonNone}
+
+	tests := []struct {
+		name        string
+		stats       *historyStats
+		wantStyle   string
+		wantMax     int
+		wantVisible int
+		wantScope   string
+		wantReasons int
+	}{
+		{
+			name:        "no history keeps parse policy",
+			stats:       nil,
+			wantStyle:   domain.HintStyleStandard,
+			wantMax:     2,
+			wantVisible: 0,
+			wantScope:   "none",
+			wantReasons: 1,
+		},
+		{
+			name:        "often reaches L3",
+			stats:       &historyStats{help: 4, avgHints: 2.5, l3Share: 0.75},
+			wantStyle:   domain.HintStyleGentle,
+			wantMax:     3,
+			wantVisible: 1,
+			wantScope:   "template_id",
+			wantReasons: 2,
+		},
+		{
+			name:        "mostly wrong and slow",
+			stats:       &historyStats{check: 4, correctRate: 0.25, avgTime: 1200},
+			wantStyle:   domain.HintStyleGentle,
+			wantMax:     3,
+			wantVisible: 1,
+			wantScope:   "template_id",
+			wantReasons: 3,
+		},
+		{
+			name:        "strong child",
+			stats:       &historyStats{help: 2, check: 3, avgHints: 0.5, correctRate: 0.9, avgTime: 120},
+			wantStyle:   domain.HintStyleCompact,
+			wantMax:     2,
+			wantVisible: 1,
+			wantScope:   "template_id",
+			wantReasons: 2,
+		},
+		{
+			name:        "average child keeps parse policy",
+			stats:       &historyStats{help: 2, check: 2, avgHints: 1.5, l3Share: 0.25, correctRate: 0.75, avgTime: 400},
+			wantStyle:   domain.HintStyleStandard,
+			wantMax:     2,
+			wantVisible: 0,
+			wantScope:   "template_id",
+			wantReasons: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := adaptHintPolicy(base, "template_id", "sum_two_numbers", tt.stats)
+			if got.Style != tt.wantStyle || got.MaxHints != tt.wantMax || got.DefaultVisible != tt.wantVisible {
+				t.Errorf("adaptHintPolicy() = %s/%d/%d, want %s/%d/%d",
+					got.Style, got.MaxHints, got.DefaultVisible, tt.wantStyle, tt.wantMax, tt.wantVisible)
+			}
+			if got.Scope != tt.wantScope || got.BaseMaxHints != base.MaxHints {
+				t.Errorf("scope = %s, base max = %d, want %s, %d", got.Scope, got.BaseMaxHints, tt.wantScope, base.MaxHints)
+			}
+			if len(got.Reasons) != tt.wantReasons {
+				t.Errorf("reasons = %q, want %d", got.Reasons, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestAdaptHintPolicy_CompactKeepsOneHint(t *testing.T) {
+	base := types.HintPolicy{MaxHints: 1, DefaultVisible: 1}
+	got := adaptHintPolicy(base, "task_type", "addition", &historyStats{check: 3, correctRate: 1})
+	if got.Style != domain.HintStyleCompact || got.MaxHints != 1 {
+		t.Errorf("adaptHintPolicy() = %s/%d, want compact/1", got.Style, got.MaxHints)
+	}
+	if got.Samples != 3 {
+		t.Errorf("samples = %d, want 3", got.Samples)
+	}
+}
@@ -2,17 +2,22 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log"
 
+	"child-bot/api/internal/pet"
 	"child-bot/api/internal/store"
 )
 
 // HomeService бизнес-логика для главного экрана
 type HomeService struct {
-	store          *store.Store
-	attemptService *AttemptService
-	profileService *ProfileService
-	villainService *VillainService
+	store           *store.Store
+	attemptService  *AttemptService
+	profileService  *ProfileService
+	villainService  *VillainService
+	petService      *PetService
+	missionService  *MissionService
+	seasonalService *SeasonalService
 }
 
 // NewHomeService создает новый HomeService
@@ -30,6 +35,21 @@ func NewHomeService(
 	}
 }
 
+// SetPetService устанавливает PetService (маскот главного экрана — питомец ребёнка)
+func (s *HomeService) SetPetService(petService *PetService) {
+	s.petService = petService
+}
+
+// SetMissionService устанавливает MissionService (сводка миссий дня на главном экране)
+func (s *HomeService) SetMissionService(missionService *MissionService) {
+	s.missionService = missionService
+}
+
+// SetSeasonalService устанавливает SeasonalService (текущее событие на главном экране)
+func (s *HomeService) SetSeasonalService(seasonalService *SeasonalService) {
+	s.seasonalService = seasonalService
+}
+
 // GetStore возвращает store для прямого доступа
 func (s *HomeService) GetStore() *store.Store {
 	return s.store
@@ -43,6 +63,8 @@ type HomeData struct {
 	UnfinishedAttempt *AttemptData
 	RecentAttempts    []RecentAttempt
 	Achievements      AchievementsSummary
+	Missions          *DailyMissions // миссии дня; nil, если недоступны
+	Event             *SeasonalEvent // ближайшее к окончанию идущее событие; nil, если событий нет
 }
 
 // AchievementsSummary статистика достижений
@@ -64,9 +86,10 @@ type ProfileSummary struct {
 // MascotData данные маскота
 type MascotData struct {
 	ID       string
-	State    string // idle, happy, thinking, celebrating
+	State    string // idle, happy, thinking, celebrating, encouraging
 	ImageURL string
 	Message  string
+	Pet      *Pet // состояние питомца; nil, если питомец недоступен
 }
 
 // VillainSummary краткие данные злодея
@@ -131,8 +154,8 @@ func (s *HomeService) GetHomeData(ctx context.Context, childProfileID string) (*
 	}
 
 	// Рассчитываем прогресс уровня
-	xpForCurrentLevel := store.XPForLevel(level - 1)
-	xpForNextLevel := store.XPForLevel(level)
+	xpForCurrentLevel := s.store.XP.XPForLevel(level - 1)
+	xpForNextLevel := s.store.XP.XPForLevel(level)
 	xpInCurrentLevel := xpTotal - xpForCurrentLevel
 	xpNeeded := xpForNextLevel - xpForCurrentLevel
 
@@ -164,6 +187,16 @@ func (s *HomeService) GetHomeData(ctx context.Context, childProfileID string) (*
 		},
 	}
 
+	// Маскот — питомец ребёнка с настоящими показателями
+	if s.petService != nil {
+		p, err := s.petService.GetPet(ctx, childProfileID)
+		if err != nil {
+			log.Printf("[HomeService] Failed to get pet: %v", err)
+		} else {
+			data.Mascot = mascotFromPet(p)
+		}
+	}
+
 	// Загружаем активного злодея из БД
 	activeVillain, err := s.villainService.GetActiveVillain(ctx, childProfileID)
 	if err != nil {
@@ -209,6 +242,26 @@ func (s *HomeService) GetHomeData(ctx context.Context, childProfileID string) (*
 		log.Printf("[HomeService] No unfinished attempt found for profile: %s", childProfileID)
 	}
 
+	// Миссии дня (при первом заходе за день они выбираются)
+	if s.missionService != nil {
+		missions, err := s.missionService.GetTodayMissions(ctx, childProfileID)
+		if err != nil {
+			log.Printf("[HomeService] Failed to get daily missions: %v", err)
+		} else {
+			data.Missions = missions
+		}
+	}
+
+	// Сезонное событие: показываем то, что закончится раньше
+	if s.seasonalService != nil {
+		events, err := s.seasonalService.GetCurrentEvents(ctx, childProfileID)
+		if err != nil {
+			log.Printf("[HomeService] Failed to get seasonal events: %v", err)
+		} else if len(events.Events) > 0 {
+			data.Event = &events.Events[0]
+		}
+	}
+
 	// Получить последние 3 завершенные попытки
 	recentAttempts, err := s.attemptService.GetRecentAttempts(ctx, childProfileID, 3)
 	if err != nil {
@@ -231,3 +284,23 @@ func (s *HomeService) GetHomeData(ctx context.Context, childProfileID string) (*
 
 	return data, nil
 }
+
+// mascotFromPet маскот главного экрана по состоянию питомца
+func mascotFromPet(p *Pet) MascotData {
+	state := "idle"
+	switch {
+	case p.JustEvolved:
+		state = "celebrating"
+	case p.Mood == pet.MoodHappy:
+		state = "happy"
+	case p.Mood == pet.MoodHungry, p.Mood == pet.MoodSad, p.Mood == pet.MoodTired:
+		state = "encouraging"
+	}
+	return MascotData{
+		ID:       fmt.Sprintf("%s_%d", p.Type, p.Stage),
+		State:    state,
+		ImageURL: p.ImageURL,
+		Message:  p.Message,
+		Pet:      p,
+	}
+}
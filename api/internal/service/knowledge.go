@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/knowledge"
+	"child-bot/api/internal/store"
+)
+
+// DefaultKnowledgeSubject предмет карты знаний по умолчанию
+const DefaultKnowledgeSubject = "math"
+
+// knowledgeWeakSpotsLimit сколько слабых мест показывать
+const knowledgeWeakSpotsLimit = 5
+
+// KnowledgeService карта знаний: освоение тем по проверкам и подсказкам
+type KnowledgeService struct {
+	store *store.Store
+	now   func() time.Time
+}
+
+// NewKnowledgeService создает новый KnowledgeService
+func NewKnowledgeService(store *store.Store) *KnowledgeService {
+	return &KnowledgeService{store: store, now: time.Now}
+}
+
+// KnowledgeMap карта знаний ребёнка по предмету
+type KnowledgeMap struct {
+	Subject   string
+	Grade     int // 0 — все классы
+	Mastery   int // среднее освоение по решённым задачам
+	Topics    int
+	Practiced int
+	Mastered  int
+	Sections  []*knowledge.TreeNode
+	WeakSpots []WeakSpot
+}
+
+// WeakSpot слабое место с рекомендацией
+type WeakSpot struct {
+	*knowledge.TreeNode
+	SectionTitle   string
+	Recommendation string
+}
+
+// Subscribe подписывает карту знаний на завершённые проверки
+func (s *KnowledgeService) Subscribe(bus *EventBus) {
+	bus.Subscribe(domain.EventAttemptCompleted, "knowledge", s.HandleEvent)
+}
+
+// HandleEvent засчитывает проверку темам её заданий. Повтор события освоение не меняет.
+func (s *KnowledgeService) HandleEvent(ctx context.Context, event domain.Event) error {
+	e, ok := event.(domain.AttemptCompleted)
+	if !ok || len(e.Items) == 0 {
+		return nil
+	}
+
+	subject := e.Subject
+	if subject == "" {
+		subject = DefaultKnowledgeSubject
+	}
+	nodes, err := s.store.GetKnowledgeNodes(ctx, subject)
+	if err != nil {
+		return fmt.Errorf("get knowledge nodes: %w", err)
+	}
+
+	items := make([]knowledge.Item, 0, len(e.Items))
+	for _, item := range e.Items {
+		items = append(items, knowledge.Item{TemplateCode: knowledge.TemplateCode(item.TemplateID), TaskType: item.TaskType})
+	}
+	nodeIDs := knowledge.Resolve(nodes, items)
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+
+	res, err := s.store.RecordKnowledgeEvidence(ctx, e.ChildProfileID, e.AttemptID, nodeIDs, e.IsCorrect, e.HintsUsed, s.now())
+	if err != nil {
+		return fmt.Errorf("record knowledge evidence: %w", err)
+	}
+	if len(res.Updated) > 0 {
+		log.Printf("[KnowledgeService] Attempt %s of child %s counted for %v (correct=%v, hints=%d)",
+			e.AttemptID, e.ChildProfileID, res.Updated, e.IsCorrect, e.HintsUsed)
+	}
+	return nil
+}
+
+// GetKnowledgeMap возвращает дерево тем предмета с освоением и слабые места.
+// grade 0 — класс из профиля ребёнка; если он не указан, показываются все классы.
+func (s *KnowledgeService) GetKnowledgeMap(ctx context.Context, childProfileID, subject string, grade int) (*KnowledgeMap, error) {
+	if subject == "" {
+		subject = DefaultKnowledgeSubject
+	}
+	if grade == 0 {
+		g, err := s.store.GetChildGrade(ctx, childProfileID)
+		if err != nil {
+			return nil, fmt.Errorf("get child grade: %w", err)
+		}
+		grade = g
+	}
+
+	nodes, err := s.store.GetKnowledgeNodes(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("get knowledge nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	progress, err := s.store.GetKnowledgeProgress(ctx, childProfileID)
+	if err != nil {
+		return nil, fmt.Errorf("get knowledge progress: %w", err)
+	}
+
+	tree := knowledge.BuildTree(nodes, progress, grade)
+	m := &KnowledgeMap{Subject: subject, Grade: grade, Sections: tree}
+
+	weighted, attempts := 0, 0
+	sectionTitles := make(map[string]string)
+	for _, section := range tree {
+		sectionTitles[section.ID] = section.Title
+		m.Topics += section.TopicsTotal
+		m.Practiced += section.TopicsPracticed
+		m.Mastered += section.TopicsMastered
+		weighted += section.Mastery * section.Attempts
+		attempts += section.Attempts
+	}
+	if attempts > 0 {
+		m.Mastery = (weighted + attempts/2) / attempts
+	}
+
+	for _, topic := range knowledge.WeakSpots(tree, knowledgeWeakSpotsLimit) {
+		m.WeakSpots = append(m.WeakSpots, WeakSpot{
+			TreeNode:       topic,
+			SectionTitle:   sectionTitles[topic.ParentID],
+			Recommendation: weakSpotRecommendation(topic),
+		})
+	}
+	return m, nil
+}
+
+// weakSpotRecommendation совет, как подтянуть тему
+func weakSpotRecommendation(topic *knowledge.TreeNode) string {
+	if topic.HintsUsed > topic.Attempts {
+		return fmt.Sprintf("Тема «%s» пока даётся с подсказками. Реши пару задач сам, а подсказку открывай, только если застрял", topic.Title)
+	}
+	return fmt.Sprintf("Тема «%s» пока вызывает ошибки. Реши ещё несколько задач на эту тему", topic.Title)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/store"
+)
+
+// Периоды leaderboard
+const (
+	LeaderboardPeriodWeek  = "week"
+	LeaderboardPeriodMonth = "month"
+	LeaderboardPeriodAll   = "all"
+)
+
+const (
+	// DefaultLeaderboardLimit сколько мест показывать по умолчанию
+	DefaultLeaderboardLimit = 10
+	// maxLeaderboardNameRunes длина имени, видимого другим детям
+	maxLeaderboardNameRunes = 12
+	// leaderboardFallbackName имя, если в профиле нет подходящего
+	leaderboardFallbackName = "Друг"
+	// leaderboardDefaultAvatar аватар, если в профиле нет подходящего
+	leaderboardDefaultAvatar = "cat"
+)
+
+// LeaderboardService рейтинг среди друзей
+type LeaderboardService struct {
+	store *store.Store
+	now   func() time.Time
+}
+
+// NewLeaderboardService создает новый LeaderboardService
+func NewLeaderboardService(store *store.Store) *LeaderboardService {
+	return &LeaderboardService{store: store, now: time.Now}
+}
+
+// LeaderboardEntry место в рейтинге с безопасными для ребёнка данными
+type LeaderboardEntry struct {
+	Rank             int
+	ChildProfileID   string
+	DisplayName      string // только первое слово имени
+	AvatarID         string
+	Level            int
+	TasksSolved      int
+	XPGained         int
+	VillainsDefeated int
+	IsCurrentUser    bool
+}
+
+// GetFriendsLeaderboard возвращает рейтинг ребёнка среди друзей за период по метрике.
+// Сам ребёнок всегда есть в списке, даже если не попал в первые limit мест.
+func (s *LeaderboardService) GetFriendsLeaderboard(ctx context.Context, childProfileID, period, metric string, limit int) ([]LeaderboardEntry, error) {
+	if period == "" {
+		period = LeaderboardPeriodWeek
+	}
+	if metric == "" {
+		metric = store.LeaderboardMetricTasks
+	}
+	switch metric {
+	case store.LeaderboardMetricTasks, store.LeaderboardMetricXP, store.LeaderboardMetricVillains:
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+	loc := childLocation(ctx, s.store, childProfileID)
+	since, err := leaderboardPeriodStart(period, s.now().In(loc))
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultLeaderboardLimit
+	}
+
+	rows, err := s.store.GetFriendsLeaderboard(ctx, childProfileID, metric, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get friends leaderboard: %w", err)
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, LeaderboardEntry{
+			Rank:             r.Rank,
+			ChildProfileID:   r.ChildProfileID,
+			DisplayName:      safeDisplayName(r.DisplayName),
+			AvatarID:         safeAvatarID(r.AvatarID),
+			Level:            r.Level,
+			TasksSolved:      r.TasksSolved,
+			XPGained:         r.XPGained,
+			VillainsDefeated: r.VillainsDefeated,
+			IsCurrentUser:    r.ChildProfileID == childProfileID,
+		})
+	}
+
+	return entries, nil
+}
+
+// leaderboardPeriodStart начало периода в поясе now: неделя с понедельника, месяц с первого числа.
+// Для all возвращает нулевое время.
+func leaderboardPeriodStart(period string, now time.Time) (time.Time, error) {
+	switch period {
+	case LeaderboardPeriodWeek:
+		return calendar.WeekStart(now, now.Location()), nil
+	case LeaderboardPeriodMonth:
+		return calendar.MonthStart(now, now.Location()), nil
+	case LeaderboardPeriodAll:
+		return time.Time{}, nil
+	default:
+		return time.Time{}, domain.ErrInvalidInput
+	}
+}
+
+// safeDisplayName оставляет только первое слово имени из букв, без фамилии и контактов
+func safeDisplayName(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return leaderboardFallbackName
+	}
+
+	var b strings.Builder
+	for _, r := range fields[0] {
+		if !unicode.IsLetter(r) && r != '-' {
+			continue
+		}
+		if utf8.RuneCountInString(b.String()) >= maxLeaderboardNameRunes {
+			break
+		}
+		b.WriteRune(r)
+	}
+
+	safe := strings.Trim(b.String(), "-")
+	if safe == "" {
+		return leaderboardFallbackName
+	}
+	return safe
+}
+
+// safeAvatarID пропускает только идентификаторы аватаров из каталога вида [a-z0-9_]
+func safeAvatarID(avatarID string) string {
+	if avatarID == "" || len(avatarID) > 50 {
+		return leaderboardDefaultAvatar
+	}
+	for _, r := range avatarID {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '_' {
+			return leaderboardDefaultAvatar
+		}
+	}
+	return avatarID
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"child-bot/api/internal/level"
+	"child-bot/api/internal/store"
+)
+
+// LevelService таблица уровней и поздравления с новыми уровнями
+type LevelService struct {
+	store *store.Store
+}
+
+// NewLevelService создает новый LevelService
+func NewLevelService(store *store.Store) *LevelService {
+	return &LevelService{store: store}
+}
+
+// LevelRow строка таблицы уровней для ребёнка
+type LevelRow struct {
+	level.Level
+	Reached bool
+}
+
+// LevelOverview уровень ребёнка, таблица уровней и непросмотренные переходы
+type LevelOverview struct {
+	Version      int
+	Level        int
+	Title        string
+	XPTotal      int
+	XPInLevel    int // XP, набранный на текущем уровне
+	XPNeeded     int // всего XP для следующего уровня
+	ShopDiscount int // скидка на усилители, %
+	Levels       []LevelRow
+	Pending      []store.LevelUp // поздравления, которые ребёнок ещё не видел
+}
+
+// GetLevels возвращает прогресс ребёнка по таблице уровней и непросмотренные переходы.
+// Если ребёнок ушёл за таблицу, строки продолжаются до его следующего уровня.
+func (s *LevelService) GetLevels(ctx context.Context, childProfileID string) (*LevelOverview, error) {
+	xpTotal, current, err := s.store.GetXPAndLevel(ctx, childProfileID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.store.ListLevelUps(ctx, childProfileID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	t := s.store.XP.Levels
+	inLevel, needed := s.store.XP.XPProgress(xpTotal, current)
+	o := &LevelOverview{
+		Version:      t.Version,
+		Level:        current,
+		Title:        t.Title(current),
+		XPTotal:      xpTotal,
+		XPInLevel:    inLevel,
+		XPNeeded:     needed,
+		ShopDiscount: t.ShopDiscount(current),
+		Pending:      pending,
+	}
+
+	last := len(t.Levels)
+	if current+1 > last {
+		last = current + 1
+	}
+	o.Levels = make([]LevelRow, 0, last)
+	for l := 1; l <= last; l++ {
+		o.Levels = append(o.Levels, LevelRow{Level: t.Level(l), Reached: l <= current})
+	}
+	return o, nil
+}
+
+// MarkSeen отмечает поздравления с уровнями до upTo включительно просмотренными
+func (s *LevelService) MarkSeen(ctx context.Context, childProfileID string, upTo int) (int, error) {
+	n, err := s.store.MarkLevelUpsSeen(ctx, childProfileID, upTo)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("[LevelService] Child %s saw level ups up to %d (marked=%d)", childProfileID, upTo, n)
+	return n, nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/mission"
+	"child-bot/api/internal/store"
+)
+
+// MissionService ежедневные миссии: три задания на день по календарю ребёнка,
+// прогресс по проверкам и подсказкам, награда монетами и XP при выполнении
+type MissionService struct {
+	store   *store.Store
+	catalog *mission.Catalog
+	now     func() time.Time
+}
+
+// NewMissionService создает новый MissionService с проверенным каталогом миссий
+func NewMissionService(store *store.Store, catalog *mission.Catalog) *MissionService {
+	return &MissionService{store: store, catalog: catalog, now: time.Now}
+}
+
+// DailyMission миссия ребёнка на сегодня
+type DailyMission struct {
+	ID          int64
+	MissionID   string
+	Icon        string
+	Title       string
+	Description string
+	Subject     string
+	Target      int
+	Progress    int
+	RewardCoins int
+	RewardXP    int
+	Completed   bool
+	CompletedAt *time.Time
+}
+
+// DailyMissions миссии ребёнка на день
+type DailyMissions struct {
+	Date         string // YYYY-MM-DD по календарю ребёнка
+	Items        []DailyMission
+	Completed    int
+	Total        int
+	AllDoneBonus mission.Reward // награда за выполнение всех миссий дня
+}
+
+// Subscribe подписывает миссии на проверки и подсказки
+func (s *MissionService) Subscribe(bus *EventBus) {
+	bus.Subscribe(domain.EventAttemptCompleted, "missions", s.HandleEvent)
+	bus.Subscribe(domain.EventHintRequested, "missions", s.HandleEvent)
+}
+
+// HandleEvent засчитывает проверку или подсказку миссиям сегодняшнего дня.
+// Миссии дня выдаются при первом действии, если ребёнок ещё не открывал их.
+func (s *MissionService) HandleEvent(ctx context.Context, event domain.Event) error {
+	if s.catalog == nil {
+		return nil
+	}
+
+	var action mission.Action
+	var sourceKey string
+	switch e := event.(type) {
+	case domain.AttemptCompleted:
+		action = mission.Action{
+			Kind:        mission.ActionCheck,
+			Subject:     e.Subject,
+			IsCorrect:   e.IsCorrect,
+			HintsUsed:   e.HintsUsed,
+			FixedErrors: e.FixedErrors,
+		}
+		sourceKey = "attempt:" + e.AttemptID
+	case domain.HintRequested:
+		action = mission.Action{Kind: mission.ActionHint}
+		sourceKey = "hint:" + e.AttemptID + ":" + strconv.Itoa(e.HintIndex)
+	default:
+		return nil
+	}
+
+	childProfileID := event.EventChildProfileID()
+	now := s.now()
+	date, _, err := s.ensureMissions(ctx, childProfileID, now)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.store.AdvanceDailyMissions(ctx, childProfileID, date, sourceKey, action, s.catalog.AllDoneBonus, now)
+	if err != nil {
+		return fmt.Errorf("advance daily missions: %w", err)
+	}
+	for _, m := range res.Completed {
+		log.Printf("[MissionService] Mission %s completed by child %s (+%d coins, +%d xp)",
+			m.MissionID, childProfileID, m.RewardCoins, m.RewardXP)
+	}
+	if res.AllDone {
+		log.Printf("[MissionService] All missions of %s completed by child %s", date, childProfileID)
+	}
+	return nil
+}
+
+// GetTodayMissions миссии ребёнка на сегодня; при первом запросе за день они выбираются
+func (s *MissionService) GetTodayMissions(ctx context.Context, childProfileID string) (*DailyMissions, error) {
+	if s.catalog == nil {
+		return &DailyMissions{Date: calendar.DateKey(s.now(), childLocation(ctx, s.store, childProfileID))}, nil
+	}
+
+	date, missions, err := s.ensureMissions(ctx, childProfileID, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DailyMissions{
+		Date:         date,
+		Items:        make([]DailyMission, 0, len(missions)),
+		Total:        len(missions),
+		AllDoneBonus: s.catalog.AllDoneBonus,
+	}
+	for _, m := range missions {
+		completed := m.Status == store.DailyMissionCompleted
+		if completed {
+			result.Completed++
+		}
+		result.Items = append(result.Items, DailyMission{
+			ID:          m.ID,
+			MissionID:   m.MissionID,
+			Icon:        m.Icon,
+			Title:       m.Title,
+			Description: m.Description,
+			Subject:     m.Subject,
+			Target:      m.Target,
+			Progress:    m.Progress,
+			RewardCoins: m.RewardCoins,
+			RewardXP:    m.RewardXP,
+			Completed:   completed,
+			CompletedAt: m.CompletedAt,
+		})
+	}
+	return result, nil
+}
+
+// ensureMissions возвращает день ребёнка и его миссии, выбирая их по истории, если ещё не выданы
+func (s *MissionService) ensureMissions(ctx context.Context, childProfileID string, now time.Time) (string, []store.DailyMission, error) {
+	loc := childLocation(ctx, s.store, childProfileID)
+	date := calendar.DateKey(now, loc)
+
+	missions, err := s.store.GetDailyMissions(ctx, childProfileID, date)
+	if err != nil {
+		return "", nil, fmt.Errorf("get daily missions: %w", err)
+	}
+	if len(missions) > 0 {
+		return date, missions, nil
+	}
+
+	since := calendar.StartOfDay(now, loc).AddDate(0, 0, -mission.HistoryDays)
+	history, err := s.store.GetMissionHistory(ctx, childProfileID, since)
+	if err != nil {
+		return "", nil, fmt.Errorf("get mission history: %w", err)
+	}
+	picked := mission.Pick(s.catalog.Missions, *history, mission.Seed(childProfileID, date), s.catalog.MissionsPerDay)
+	if err := s.store.CreateDailyMissions(ctx, childProfileID, date, picked); err != nil {
+		return "", nil, fmt.Errorf("create daily missions: %w", err)
+	}
+	log.Printf("[MissionService] Issued %d missions for %s to child %s", len(picked), date, childProfileID)
+
+	missions, err = s.store.GetDailyMissions(ctx, childProfileID, date)
+	if err != nil {
+		return "", nil, fmt.Errorf("get daily missions: %w", err)
+	}
+	return date, missions, nil
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/llm"
+	"child-bot/api/internal/llm/types"
+	"child-bot/api/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// maxExplainedMistakes ограничивает число разбираемых ошибок (на каждую — отдельный запрос к LLM)
+const maxExplainedMistakes = 5
+
+// mistakeKeyword связывает часть кода ошибки с основами слов из common_mistakes шаблона
+type mistakeKeyword struct {
+	labelPart string
+	stems     []string
+}
+
+// mistakeKeywords порядок важен: более специфичные признаки проверяются первыми
+var mistakeKeywords = []mistakeKeyword{
+	{labelPart: "sign", stems: []string{"знак"}},
+	{labelPart: "order", stems: []string{"порядк", "порядок"}},
+	{labelPart: "operation", stems: []string{"действи", "операци"}},
+	{labelPart: "units", stems: []string{"единиц"}},
+	{labelPart: "formula", stems: []string{"формул"}},
+	{labelPart: "step", stems: []string{"шаг"}},
+	{labelPart: "calculation", stems: []string{"вычисл", "счит", "счёт"}},
+	{labelPart: "result", stems: []string{"результат", "ответ"}},
+	{labelPart: "answer", stems: []string{"ответ"}},
+	{labelPart: "logic", stems: []string{"услови", "логик"}},
+}
+
+// ExplainMistakes строит (или берёт сохранённый) разбор ошибок check попытки
+// и возвращает ошибку, которую ребёнок разбирает сейчас
+func (s *AttemptService) ExplainMistakes(ctx context.Context, attemptID, childProfileID string) (*domain.MistakeStep, error) {
+	id, err := uuid.Parse(attemptID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attempt_id: %w", err)
+	}
+
+	attempt, err := s.store.Attempts.GetAttempt(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempt: %w", err)
+	}
+
+	if attempt.ChildProfileID.String() != childProfileID {
+		return nil, domain.ErrForbidden
+	}
+
+	result, index, err := s.store.Attempts.GetMistakesState(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		result, err = s.buildMistakesResult(ctx, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.store.Attempts.SaveMistakesResult(ctx, id, result); err != nil {
+			return nil, err
+		}
+		index = 0
+
+		log.Printf("[AttemptService] Built mistakes explanation for attempt %s: %d mistakes, template=%s",
+			attemptID, len(result.Mistakes), result.TemplateID)
+	}
+
+	// Все ошибки уже разобраны — начинаем разбор заново
+	if index >= len(result.Mistakes) {
+		index = 0
+		if err := s.store.Attempts.SetCurrentMistakeIndex(ctx, id, index); err != nil {
+			log.Printf("[AttemptService] Failed to reset mistake index for attempt %s: %v", attemptID, err)
+		}
+	}
+
+	return &domain.MistakeStep{
+		Mistake:       result.Mistakes[index],
+		MistakeIndex:  index,
+		TotalMistakes: len(result.Mistakes),
+	}, nil
+}
+
+// NextMistake переходит к следующей ошибке в разборе
+func (s *AttemptService) NextMistake(ctx context.Context, attemptID, childProfileID string) (*domain.MistakeStep, error) {
+	id, err := uuid.Parse(attemptID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attempt_id: %w", err)
+	}
+
+	attempt, err := s.store.Attempts.GetAttempt(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempt: %w", err)
+	}
+
+	if attempt.ChildProfileID.String() != childProfileID {
+		return nil, domain.ErrForbidden
+	}
+
+	result, index, err := s.store.Attempts.GetMistakesState(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Разбор ещё не строился — сначала нужно вызвать ExplainMistakes
+	if result == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	next := index + 1
+	if next >= len(result.Mistakes) {
+		// Запоминаем, что разбор пройден до конца
+		if err := s.store.Attempts.SetCurrentMistakeIndex(ctx, id, len(result.Mistakes)); err != nil {
+			log.Printf("[AttemptService] Failed to complete mistakes for attempt %s: %v", attemptID, err)
+		}
+		return nil, domain.ErrNoMistakesLeft
+	}
+
+	if err := s.store.Attempts.SetCurrentMistakeIndex(ctx, id, next); err != nil {
+		return nil, err
+	}
+
+	return &domain.MistakeStep{
+		Mistake:       result.Mistakes[next],
+		MistakeIndex:  next,
+		TotalMistakes: len(result.Mistakes),
+	}, nil
+}
+
+// buildMistakesResult строит объяснение и мини-подсказки для каждого ErrorSpan
+func (s *AttemptService) buildMistakesResult(ctx context.Context, attempt *store.Attempt) (*domain.MistakesResult, error) {
+	if attempt.AttemptType != "check" || len(attempt.CheckResult) == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	var checkResult types.CheckResponse
+	if err := json.Unmarshal(attempt.CheckResult, &checkResult); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal check_result: %w", err)
+	}
+
+	// Разбираем только неверные решения с найденными ошибками
+	if checkResult.Decision != types.CheckDecisionIncorrect || len(checkResult.ErrorSpans) == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	var parseResult *types.ParseResponse
+	if len(attempt.ParseResult) > 0 {
+		if err := json.Unmarshal(attempt.ParseResult, &parseResult); err != nil {
+			log.Printf("[AttemptService] Failed to unmarshal parse_result: %v", err)
+			parseResult = nil
+		}
+	}
+
+	entry := findParseTemplate(parseResult)
+
+	answerText := ""
+	if checkResult.Debug != nil && checkResult.Debug.RawAnswerText != nil {
+		answerText = *checkResult.Debug.RawAnswerText
+	}
+
+	spans := checkResult.ErrorSpans
+	if len(spans) > maxExplainedMistakes {
+		spans = spans[:maxExplainedMistakes]
+	}
+
+	result := &domain.MistakesResult{
+		Mistakes: make([]domain.MistakeExplanation, 0, len(spans)),
+	}
+	if entry != nil {
+		result.TemplateID = entry.Template.TemplateID
+	}
+
+	for _, span := range spans {
+		mistake := domain.MistakeExplanation{
+			Label:         span.Label,
+			Fragment:      spanFragment(answerText, span),
+			CommonMistake: matchCommonMistake(entry, span.Label),
+		}
+		mistake.Explanation = mistakeExplanationText(mistake, checkResult.Feedback)
+
+		hints, err := s.requestMistakeHints(ctx, parseResult, entry, mistake, checkResult.Feedback)
+		if err != nil || len(hints) == 0 {
+			if err != nil {
+				log.Printf("[AttemptService] Failed to get mistake hints for %s, using template ladder: %v", span.Label, err)
+			}
+			hints = templateHintLadder(entry)
+		}
+		mistake.Hints = hints
+
+		result.Mistakes = append(result.Mistakes, mistake)
+	}
+
+	return result, nil
+}
+
+// requestMistakeHints запрашивает у LLM мини-лестницу подсказок, сфокусированную на одной ошибке.
+// Фокус передаётся вместе с template_profile_core, чтобы подсказки следовали TeachingPattern шаблона.
+func (s *AttemptService) requestMistakeHints(ctx context.Context, parseResult *types.ParseResponse, entry *llm.TemplateEntry, mistake domain.MistakeExplanation, feedback string) ([]string, error) {
+	if parseResult == nil || s.llmClient == nil {
+		return nil, nil
+	}
+
+	profileCore := map[string]interface{}{}
+	if entry != nil {
+		profileCore = entry.ProfileCore()
+	}
+	profileCore["mistake_focus"] = map[string]interface{}{
+		"label":          mistake.Label,
+		"fragment":       mistake.Fragment,
+		"feedback":       feedback,
+		"common_mistake": mistake.CommonMistake,
+	}
+
+	templateJSON, err := json.Marshal(profileCore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal template: %w", err)
+	}
+
+	policy := types.HintPolicy{
+		MaxHints:       3,
+		DefaultVisible: 1,
+		H3Reason:       types.H3ReasonNone,
+	}
+	if len(parseResult.Items) > 0 && parseResult.Items[0].HintPolicy.MaxHints > 0 {
+		policy = parseResult.Items[0].HintPolicy
+	}
+
+	hintResp, err := s.llmClient.Hint(ctx, s.defaultLLM, types.HintRequest{
+		Task:          parseResult.Task,
+		Mode:          string(types.HintModeLearn),
+		Items:         parseResult.Items,
+		AppliedPolicy: policy,
+		Template:      string(templateJSON),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Берём по одной подсказке каждого уровня, по порядку L1 → L3
+	var hints []string
+	for _, level := range []types.HintLevel{types.HintL1, types.HintL2, types.HintL3} {
+		if len(hints) >= policy.MaxHints {
+			break
+		}
+		for _, item := range hintResp.Items {
+			text := firstHintText(item.Hints, level)
+			if text != "" {
+				hints = append(hints, text)
+				break
+			}
+		}
+	}
+
+	return hints, nil
+}
+
+// firstHintText возвращает текст первой подсказки указанного уровня
+func firstHintText(hints []types.Hint, level types.HintLevel) string {
+	for _, h := range hints {
+		if h.Level == level && strings.TrimSpace(h.HintText) != "" {
+			return h.HintText
+		}
+	}
+	return ""
+}
+
+// spanFragment вырезает фрагмент ответа по ErrorSpan (позиции в символах)
+func spanFragment(answer string, span types.ErrorSpan) string {
+	runes := []rune(answer)
+	if span.From < 0 || span.To <= span.From || span.From >= len(runes) {
+		return ""
+	}
+	to := span.To
+	if to > len(runes) {
+		to = len(runes)
+	}
+	return strings.TrimSpace(string(runes[span.From:to]))
+}
+
+// matchCommonMistake подбирает типичную ошибку шаблона, подходящую под код ошибки
+func matchCommonMistake(entry *llm.TemplateEntry, label string) string {
+	if entry == nil {
+		return ""
+	}
+
+	label = strings.ToLower(label)
+	for _, kw := range mistakeKeywords {
+		if !strings.Contains(label, kw.labelPart) {
+			continue
+		}
+		for _, cm := range entry.Profile.CommonMistakes {
+			lower := strings.ToLower(cm)
+			for _, stem := range kw.stems {
+				if strings.Contains(lower, stem) {
+					return cm
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// mistakeExplanationText формирует короткое объяснение ошибки для ребёнка
+func mistakeExplanationText(mistake domain.MistakeExplanation, feedback string) string {
+	var parts []string
+	if mistake.Fragment != "" {
+		parts = append(parts, fmt.Sprintf("Посмотри внимательно на «%s».", mistake.Fragment))
+	}
+	if mistake.CommonMistake != "" {
+		parts = append(parts, "Похоже на частую ошибку: "+strings.TrimSuffix(mistake.CommonMistake, ".")+".")
+	} else if feedback != "" {
+		parts = append(parts, feedback)
+	}
+	if len(parts) == 0 {
+		return "Здесь закралась ошибка. Давай разберёмся по шагам."
+	}
+	return strings.Join(parts, " ")
+}
+
+// templateHintLadder строит мини-лестницу из правил TeachingPattern, если LLM недоступен
+func templateHintLadder(entry *llm.TemplateEntry) []string {
+	if entry == nil {
+		return []string{
+			"Перечитай условие и найди место, где ответ расходится с заданием.",
+			"Повтори этот шаг ещё раз, медленно, по одному действию.",
+			"Сравни новый результат с тем, что спрашивают в задаче.",
+		}
+	}
+
+	var ladder []string
+	pattern := entry.Profile.TeachingPattern
+	for _, level := range []llm.HintLevel{pattern.L1, pattern.L2, pattern.L3} {
+		if len(level.Rules) > 0 {
+			ladder = append(ladder, level.Rules[0])
+		}
+	}
+	return ladder
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/llm"
+	"child-bot/api/internal/llm/types"
+	"child-bot/api/internal/store"
+)
+
+func mustJSON(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return data
+}
+
+func TestBuildMistakesResult(t *testing.T) {
+	answer := "12 + 5 = 18"
+	spans := func(n int) []types.ErrorSpan {
+		out := make([]types.ErrorSpan, n)
+		for i := range out {
+			out[i] = types.ErrorSpan{From: 8, To: 11, Label: "calculation_error"}
+		}
+		return out
+	}
+	check := func(decision types.CheckDecision, errorSpans []types.ErrorSpan) types.CheckResponse {
+		return types.CheckResponse{
+			Decision:   decision,
+			Feedback:   "Проверь сложение.",
+			ErrorSpans: errorSpans,
+			Debug:      &types.CheckDebug{RawAnswerText: &answer},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		attemptType   string
+		check         types.CheckResponse
+		wantErr       error
+		wantMistakes  int
+		wantFragment  string
+		wantExplained string
+	}{
+		{
+			name:        "help attempt",
+			attemptType: "help",
+			check:       check(types.CheckDecisionIncorrect, spans(1)),
+			wantErr:     domain.ErrInvalidInput,
+		},
+		{
+			name:        "correct answer",
+			attemptType: "check",
+			check:       check(types.CheckDecisionCorrect, nil),
+			wantErr:     domain.ErrInvalidInput,
+		},
+		{
+			name:        "incorrect without spans",
+			attemptType: "check",
+			check:       check(types.CheckDecisionIncorrect, nil),
+			wantErr:     domain.ErrInvalidInput,
+		},
+		{
+			name:          "one span falls back to default ladder",
+			attemptType:   "check",
+			check:         check(types.CheckDecisionIncorrect, spans(1)),
+			wantMistakes:  1,
+			wantFragment:  "18",
+			wantExplained: "Посмотри внимательно на «18». Проверь сложение.",
+		},
+		{
+			name:          "spans capped",
+			attemptType:   "check",
+			check:         check(types.CheckDecisionIncorrect, spans(maxExplainedMistakes+2)),
+			wantMistakes:  maxExplainedMistakes,
+			wantFragment:  "18",
+			wantExplained: "Посмотри внимательно на «18». Проверь сложение.",
+		},
+	}
+
+	// Без LLM клиента подсказки берутся из лестницы шаблона
+	s := &AttemptService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempt := &store.Attempt{AttemptType: tt.attemptType, CheckResult: mustJSON(t, tt.check)}
+			result, err := s.buildMistakesResult(context.Background(), attempt)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("buildMistakesResult() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildMistakesResult() error = %v", err)
+			}
+			if len(result.Mistakes) != tt.wantMistakes {
+				t.Fatalf("mistakes = %d, want %d", len(result.Mistakes), tt.wantMistakes)
+			}
+			m := result.Mistakes[0]
+			if m.Fragment != tt.wantFragment || m.Explanation != tt.wantExplained {
+				t.Errorf("mistake = %q / %q, want %q / %q", m.Fragment, m.Explanation, tt.wantFragment, tt.wantExplained)
+			}
+			if !reflect.DeepEqual(m.Hints, templateHintLadder(nil)) {
+				t.Errorf("hints = %v, want default ladder", m.Hints)
+			}
+		})
+	}
+}
+
+func TestTemplateHintLadder(t *testing.T) {
+	withRules := func(l1, l2, l3 []string) *llm.TemplateEntry {
+		return &llm.TemplateEntry{Profile: llm.TemplateProfile{TeachingPattern: llm.TeachingPattern{
+			L1: llm.HintLevel{Rules: l1},
+			L2: llm.HintLevel{Rules: l2},
+			L3: llm.HintLevel{Rules: l3},
+		}}}
+	}
+
+	if got := templateHintLadder(nil); len(got) != 3 {
+		t.Errorf("templateHintLadder(nil) = %v, want 3 default hints", got)
+	}
+
+	tests := []struct {
+		name  string
+		entry *llm.TemplateEntry
+		want  []string
+	}{
+		{
+			name:  "first rule of each level",
+			entry: withRules([]string{"Найди данные", "лишнее"}, []string{"Выбери действие"}, []string{"Посчитай"}),
+			want:  []string{"Найди данные", "Выбери действие", "Посчитай"},
+		},
+		{
+			name:  "levels without rules are skipped",
+			entry: withRules([]string{"Найди данные"}, nil, []string{"Посчитай"}),
+			want:  []string{"Найди данные", "Посчитай"},
+		},
+		{
+			name:  "template without rules",
+			entry: withRules(nil, nil, nil),
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := templateHintLadder(tt.entry)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("templateHintLadder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/llm/types"
+	"child-bot/api/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// maxPageTasks ограничивает число задач, выделяемых из одной страницы
+const maxPageTasks = 8
+
+// pageTaskCheckStaleAfter после этого проверку задачи со страницы можно запустить заново,
+// даже если прошлая так и осталась в processing
+const pageTaskCheckStaleAfter = 10 * time.Minute
+
+// ProcessPage обрабатывает фото целой страницы: Detect + Parse, затем каждое
+// независимое задание становится отдельной попыткой-задачей под страницей
+func (s *AttemptService) ProcessPage(ctx context.Context, attemptID string, imageBase64 string) error {
+	// Восстановление после паники
+	defer func() {
+		if r := recover(); r != nil {
+			buf := make([]byte, 4096)
+			n := runtime.Stack(buf, false)
+			log.Printf("[AttemptService] PANIC in ProcessPage for attempt %s: %v\nStack trace:\n%s", attemptID, r, buf[:n])
+			if id, err := uuid.Parse(attemptID); err == nil {
+				_ = s.store.Attempts.UpdateStatus(context.Background(), id, "failed")
+			}
+		}
+	}()
+
+	id, err := uuid.Parse(attemptID)
+	if err != nil {
+		return fmt.Errorf("invalid attempt_id: %w", err)
+	}
+
+	page, err := s.store.Attempts.GetAttempt(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get attempt: %w", err)
+	}
+
+	if page.AttemptType != "page" {
+		return domain.ErrInvalidInput
+	}
+
+	// Страницу уже разбили на задачи — повторно не обрабатываем
+	existing, err := s.store.Attempts.GetPageTasks(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return domain.ErrAttemptAlreadyProcessed
+	}
+
+	if err := s.store.Attempts.UpdateStatus(ctx, id, "processing"); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+
+	log.Printf("[AttemptService] Processing homework page: %s", attemptID)
+
+	detectResp, parseResp, err := s.detectAndParse(ctx, id, attemptID, imageBase64)
+	if err != nil {
+		return err
+	}
+
+	tasks := splitPageTasks(parseResp)
+	if len(tasks) == 0 {
+		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
+		return fmt.Errorf("parse returned no tasks for page")
+	}
+
+	for i := range tasks {
+		taskID, err := s.store.Attempts.CreatePageTask(ctx, page, i, detectResp, &tasks[i])
+		if err != nil {
+			_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
+			return err
+		}
+		log.Printf("[AttemptService] Page %s: task %d → attempt %s", attemptID, i+1, taskID)
+	}
+
+	// Обработка страницы закончена; completed_at появится, когда будут решены все задачи
+	if err := s.store.Attempts.UpdateStatus(ctx, id, "completed"); err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+
+	log.Printf("[AttemptService] Homework page %s split into %d tasks", attemptID, len(tasks))
+
+	return nil
+}
+
+// GetPageProgress возвращает задачи страницы и прогресс их решения
+func (s *AttemptService) GetPageProgress(ctx context.Context, attemptID, childProfileID string) (*domain.PageProgress, error) {
+	id, err := uuid.Parse(attemptID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attempt_id: %w", err)
+	}
+
+	page, err := s.store.Attempts.GetAttempt(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempt: %w", err)
+	}
+
+	if page.ChildProfileID.String() != childProfileID {
+		return nil, domain.ErrForbidden
+	}
+
+	if page.AttemptType != "page" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	tasks, err := s.store.Attempts.GetPageTasks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &domain.PageProgress{
+		AttemptID:  attemptID,
+		Status:     page.Status,
+		Tasks:      make([]domain.PageTaskProgress, 0, len(tasks)),
+		TotalTasks: len(tasks),
+	}
+
+	for _, t := range tasks {
+		task := domain.PageTaskProgress{
+			AttemptID: t.ID.String(),
+			Position:  t.Position,
+			Status:    t.Status,
+			HintsUsed: t.HintsUsed,
+			Checked:   t.HasCheck,
+			Solved:    t.IsCorrect.Valid && t.IsCorrect.Bool,
+		}
+
+		var parse types.ParseResponse
+		if len(t.ParseResult) > 0 {
+			if err := json.Unmarshal(t.ParseResult, &parse); err == nil {
+				task.TaskText = parse.Task.TaskTextClean
+			}
+		}
+
+		if task.Solved {
+			progress.SolvedTasks++
+		}
+		progress.Tasks = append(progress.Tasks, task)
+	}
+
+	if progress.TotalTasks > 0 {
+		progress.ProgressPercent = progress.SolvedTasks * 100 / progress.TotalTasks
+	}
+	progress.Completed = progress.TotalTasks > 0 && progress.SolvedTasks == progress.TotalTasks
+
+	return progress, nil
+}
+
+// StartPageTaskCheck забирает задачу со страницы на проверку решения. Решённую задачу
+// и задачу, которая уже проверяется, повторно не проверяем — иначе урон злодею и награды
+// начислились бы дважды (domain.ErrConflict).
+func (s *AttemptService) StartPageTaskCheck(ctx context.Context, attemptID string) error {
+	id, err := uuid.Parse(attemptID)
+	if err != nil {
+		return fmt.Errorf("invalid attempt_id: %w", err)
+	}
+
+	claimed, err := s.store.Attempts.ClaimCheck(ctx, id, time.Now().Add(-pageTaskCheckStaleAfter))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// updatePageProgress после проверки задачи со страницы завершает страницу, если решены все задачи
+func (s *AttemptService) updatePageProgress(ctx context.Context, task *store.Attempt) {
+	if !task.ParentAttemptID.Valid {
+		return
+	}
+	pageID := task.ParentAttemptID.UUID
+
+	tasks, err := s.store.Attempts.GetPageTasks(ctx, pageID)
+	if err != nil {
+		log.Printf("[AttemptService] Failed to get page tasks for %s: %v", pageID, err)
+		return
+	}
+
+	solved := 0
+	for _, t := range tasks {
+		if t.IsCorrect.Valid && t.IsCorrect.Bool {
+			solved++
+		}
+	}
+
+	log.Printf("[AttemptService] Page %s progress: %d/%d tasks solved", pageID, solved, len(tasks))
+
+	if len(tasks) > 0 && solved == len(tasks) {
+		if err := s.store.Attempts.MarkPageCompleted(ctx, pageID); err != nil {
+			log.Printf("[AttemptService] Failed to complete page %s: %v", pageID, err)
+		}
+	}
+}
+
+// taskParse возвращает Parse задачи: для задачи со страницы — сохранённый при разбиении,
+// иначе выполняет Detect + Parse по изображению
+func (s *AttemptService) taskParse(ctx context.Context, id uuid.UUID, attemptID, imageBase64 string) (types.ParseResponse, error) {
+	attempt, err := s.store.Attempts.GetAttempt(ctx, id)
+	if err == nil && attempt.ParentAttemptID.Valid && len(attempt.ParseResult) > 0 {
+		var parseResp types.ParseResponse
+		if err := json.Unmarshal(attempt.ParseResult, &parseResp); err == nil && parseResp.Task.TaskTextClean != "" {
+			log.Printf("[AttemptService] Using stored page task parse for attempt %s", attemptID)
+			return parseResp, nil
+		}
+	}
+
+	_, parseResp, err := s.detectAndParse(ctx, id, attemptID, imageBase64)
+	if err != nil {
+		return types.ParseResponse{}, err
+	}
+	return *parseResp, nil
+}
+
+// detectAndParse выполняет Detect и Parse по изображению и сохраняет результаты в попытку.
+// При ошибке переводит попытку в статус failed.
+func (s *AttemptService) detectAndParse(ctx context.Context, id uuid.UUID, attemptID, imageBase64 string) (*types.DetectResponse, *types.ParseResponse, error) {
+	// Detect - определить предмет и качество
+	detectReq := types.DetectRequest{
+		Image:  imageBase64,
+		Locale: "ru-RU",
+	}
+
+	detectResp, err := s.llmClient.Detect(ctx, s.defaultLLM, detectReq)
+	if err != nil {
+		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
+		return nil, nil, fmt.Errorf("detect failed: %w", err)
+	}
+
+	// Проверяем что ответ не пустой
+	if detectResp.Classification.SubjectCandidate == "" {
+		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
+		return nil, nil, fmt.Errorf("detect returned empty classification")
+	}
+
+	// Сохраняем результат Detect
+	err = s.store.Attempts.SaveDetectResult(ctx, id, &detectResp)
+	if err != nil {
+		log.Printf("[AttemptService] Failed to save detect result: %v", err)
+	}
+
+	log.Printf("[AttemptService] Detect completed: subject=%s, confidence=%.2f",
+		detectResp.Classification.SubjectCandidate, detectResp.Classification.Confidence)
+
+	// Parse - распарсить задачу
+	parseReq := types.ParseRequest{
+		Image:             imageBase64,
+		TaskId:            attemptID,
+		Grade:             5, // TODO: получать из профиля пользователя
+		SubjectCandidate:  string(detectResp.Classification.SubjectCandidate),
+		SubjectConfidence: fmt.Sprintf("%.2f", detectResp.Classification.Confidence),
+		Locale:            "ru-RU",
+	}
+
+	parseResp, err := s.llmClient.Parse(ctx, s.defaultLLM, parseReq)
+	if err != nil {
+		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
+		return nil, nil, fmt.Errorf("parse failed: %w", err)
+	}
+
+	// Проверяем что Task не пустой
+	if parseResp.Task.TaskTextClean == "" {
+		_ = s.store.Attempts.UpdateStatus(ctx, id, "failed")
+		return nil, nil, fmt.Errorf("parse returned empty task")
+	}
+
+	// Сохраняем результат Parse
+	err = s.store.Attempts.SaveParseResult(ctx, id, &parseResp)
+	if err != nil {
+		log.Printf("[AttemptService] Failed to save parse result: %v", err)
+	}
+
+	log.Printf("[AttemptService] Parse completed: task_text=%s", parseResp.Task.TaskTextClean)
+
+	return &detectResp, &parseResp, nil
+}
+
+// splitPageTasks превращает пункты PARSE страницы в отдельные задачи:
+// у каждой свой текст и единственный пункт со своим решением и политикой подсказок
+func splitPageTasks(page *types.ParseResponse) []types.ParseResponse {
+	items := page.Items
+	if len(items) > maxPageTasks {
+		items = items[:maxPageTasks]
+	}
+
+	tasks := make([]types.ParseResponse, 0, len(items))
+	for i, item := range items {
+		task := page.Task
+		task.TaskId = fmt.Sprintf("%s#%d", page.Task.TaskId, i+1)
+		if text := strings.TrimSpace(item.ItemTextClean); text != "" {
+			task.TaskTextClean = text
+		}
+
+		tasks = append(tasks, types.ParseResponse{
+			SchemaVersion: page.SchemaVersion,
+			Task:          task,
+			Items:         []types.ParseItem{item},
+		})
+	}
+
+	return tasks
+}
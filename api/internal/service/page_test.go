@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"child-bot/api/internal/llm/types"
+)
+
+func TestSplitPageTasks(t *testing.T) {
+	page := func(itemTexts ...string) *types.ParseResponse {
+		p := &types.ParseResponse{
+			SchemaVersion: "1.0",
+			Task:          types.ParseTask{TaskId: "page", Grade: 2, TaskTextClean: "Реши примеры"},
+		}
+		for i, text := range itemTexts {
+			p.Items = append(p.Items, types.ParseItem{
+				ItemId:           fmt.Sprintf("item%d", i+1),
+				ItemTextClean:    text,
+				SolutionInternal: types.SolutionInternal{FinalAnswer: float64(i + 1)},
+			})
+		}
+		return p
+	}
+	many := make([]string, maxPageTasks+3)
+	for i := range many {
+		many[i] = fmt.Sprintf("%d + 1", i)
+	}
+
+	tests := []struct {
+		name      string
+		page      *types.ParseResponse
+		wantTexts []string
+	}{
+		{
+			name:      "empty page",
+			page:      page(),
+			wantTexts: []string{},
+		},
+		{
+			name:      "item texts become task texts",
+			page:      page(" 3 + 4 ", "8 - 5"),
+			wantTexts: []string{"3 + 4", "8 - 5"},
+		},
+		{
+			name:      "item without text keeps page text",
+			page:      page("3 + 4", "  "),
+			wantTexts: []string{"3 + 4", "Реши примеры"},
+		},
+		{
+			name:      "tasks capped",
+			page:      page(many...),
+			wantTexts: many[:maxPageTasks],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitPageTasks(tt.page)
+			if len(got) != len(tt.wantTexts) {
+				t.Fatalf("splitPageTasks() = %d tasks, want %d", len(got), len(tt.wantTexts))
+			}
+			for i, task := range got {
+				if task.Task.TaskTextClean != tt.wantTexts[i] {
+					t.Errorf("task %d text = %q, want %q", i, task.Task.TaskTextClean, tt.wantTexts[i])
+				}
+				if want := fmt.Sprintf("page#%d", i+1); task.Task.TaskId != want {
+					t.Errorf("task %d id = %q, want %q", i, task.Task.TaskId, want)
+				}
+				if len(task.Items) != 1 || task.Items[0].ItemId != tt.page.Items[i].ItemId {
+					t.Errorf("task %d items = %+v, want only %s", i, task.Items, tt.page.Items[i].ItemId)
+				}
+				if task.SchemaVersion != "1.0" || task.Task.Grade != 2 {
+					t.Errorf("task %d lost page fields: %+v", i, task)
+				}
+			}
+			if tt.page.Task.TaskId != "page" || tt.page.Task.TaskTextClean != "Реши примеры" {
+				t.Errorf("page task modified: %+v", tt.page.Task)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/store"
+)
+
+// Параметры родительской сессии
+const (
+	ParentSessionTTL        = 30 * time.Minute
+	ParentPINCodeTTL        = 15 * time.Minute
+	parentPINMinLength      = 6
+	parentPINMaxLength      = 8
+	parentPINMaxFailures    = 5
+	parentPINLockDuration   = 15 * time.Minute
+	parentPINMaxLockShift   = 6 // самая долгая блокировка — 15 минут × 2^6 = 16 часов
+	parentPINIterations     = 100000
+	parentPINCodeMaxAttempt = 5
+)
+
+// ParentCodeSender отправляет одноразовый код на email родителя (EmailService)
+type ParentCodeSender interface {
+	SendVerificationCode(toEmail, code string, expiresAt time.Time) error
+}
+
+// ParentService родительские сессии. Родитель — владелец подписанного аккаунта платформы,
+// создавшего профиль ребёнка; ребёнок запускает приложение с того же аккаунта,
+// поэтому родитель дополнительно подтверждает себя PIN и получает отдельный токен сессии.
+// Задать PIN впервые или сбросить забытый можно только с кодом из письма на email родителя.
+type ParentService struct {
+	store  *store.Store
+	sender ParentCodeSender
+	now    func() time.Time
+}
+
+// NewParentService создает новый ParentService
+func NewParentService(store *store.Store, sender ParentCodeSender) *ParentService {
+	return &ParentService{store: store, sender: sender, now: time.Now}
+}
+
+// ParentPINCode куда и до какого времени отправлен код для PIN
+type ParentPINCode struct {
+	Email     string // адрес со скрытой частью: p***@mail.ru
+	ExpiresAt time.Time
+}
+
+// ParentSessionToken токен родительской сессии для заголовка X-Parent-Session
+type ParentSessionToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// ParentPINStatus задан ли PIN и до какого времени ввод заблокирован
+type ParentPINStatus struct {
+	PINSet      bool
+	LockedUntil *time.Time
+}
+
+// GetPINStatus задан ли PIN у владельца профиля
+func (s *ParentService) GetPINStatus(ctx context.Context, childProfileID, platformID, vkUserID string) (*ParentPINStatus, error) {
+	if err := s.checkOwner(ctx, childProfileID, platformID, vkUserID); err != nil {
+		return nil, err
+	}
+	pin, err := s.store.GetParentPIN(ctx, platformID, vkUserID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return &ParentPINStatus{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	status := &ParentPINStatus{PINSet: true}
+	if pin.LockedUntil != nil && pin.LockedUntil.After(s.now()) {
+		status.LockedUntil = pin.LockedUntil
+	}
+	return status, nil
+}
+
+// RequestPINCode отправляет одноразовый код для первого PIN или сброса забытого
+// на подтверждённый email родителя. Нет такого email — domain.ErrParentEmailRequired.
+func (s *ParentService) RequestPINCode(ctx context.Context, childProfileID, platformID, vkUserID string) (*ParentPINCode, error) {
+	if err := s.checkOwner(ctx, childProfileID, platformID, vkUserID); err != nil {
+		return nil, err
+	}
+	email, err := s.store.GetParentEmail(ctx, platformID, vkUserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrParentEmailRequired
+		}
+		return nil, err
+	}
+
+	code, err := randomDigits(6)
+	if err != nil {
+		return nil, fmt.Errorf("generate pin code: %w", err)
+	}
+	expiresAt := s.now().Add(ParentPINCodeTTL)
+	if err := s.store.SaveParentPINCode(ctx, platformID, vkUserID, parentTokenHash(code), expiresAt); err != nil {
+		return nil, err
+	}
+	if err := s.sender.SendVerificationCode(email, code, expiresAt); err != nil {
+		return nil, fmt.Errorf("send pin code: %w", err)
+	}
+	log.Printf("[ParentService] PIN code sent for parent %s:%s (child %s)", platformID, vkUserID, childProfileID)
+	return &ParentPINCode{Email: maskEmail(email), ExpiresAt: expiresAt}, nil
+}
+
+// SetPIN задаёт PIN родителя. Уже заданный PIN меняется со старым PIN; первый PIN и сброс
+// забытого — только с кодом из письма (RequestPINCode).
+func (s *ParentService) SetPIN(ctx context.Context, childProfileID, platformID, vkUserID, currentPIN, emailCode, newPIN string) error {
+	if !validParentPIN(newPIN) {
+		return domain.ErrInvalidInput
+	}
+	if err := s.checkOwner(ctx, childProfileID, platformID, vkUserID); err != nil {
+		return err
+	}
+
+	pin, err := s.store.GetParentPIN(ctx, platformID, vkUserID)
+	switch {
+	case err != nil && !errors.Is(err, domain.ErrNotFound):
+		return err
+	case pin != nil && currentPIN != "":
+		if err := s.verifyPIN(ctx, pin, currentPIN); err != nil {
+			return err
+		}
+	default:
+		if err := s.verifyCode(ctx, platformID, vkUserID, emailCode); err != nil {
+			return err
+		}
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("generate pin salt: %w", err)
+	}
+	hash, err := hashParentPIN(newPIN, salt)
+	if err != nil {
+		return err
+	}
+	if err := s.store.SetParentPIN(ctx, platformID, vkUserID, hash, salt); err != nil {
+		return err
+	}
+	log.Printf("[ParentService] PIN set for parent %s:%s (child %s)", platformID, vkUserID, childProfileID)
+	return nil
+}
+
+// StartSession открывает родительскую сессию для профиля ребёнка по подписанному
+// аккаунту платформы и PIN родителя
+func (s *ParentService) StartSession(ctx context.Context, childProfileID, platformID, vkUserID, pinCode string) (*ParentSessionToken, error) {
+	if err := s.checkOwner(ctx, childProfileID, platformID, vkUserID); err != nil {
+		return nil, err
+	}
+	pin, err := s.store.GetParentPIN(ctx, platformID, vkUserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.ErrConflict
+		}
+		return nil, err
+	}
+	if err := s.verifyPIN(ctx, pin, pinCode); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("generate parent session token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	now := s.now()
+	expiresAt := now.Add(ParentSessionTTL)
+	err = s.store.CreateParentSession(ctx, store.ParentSession{
+		TokenHash:      parentTokenHash(token),
+		ChildProfileID: childProfileID,
+		PlatformID:     platformID,
+		ParentUserID:   vkUserID,
+		ExpiresAt:      expiresAt,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[ParentService] Parent session started for child %s by %s:%s", childProfileID, platformID, vkUserID)
+	return &ParentSessionToken{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// EndSession завершает родительскую сессию
+func (s *ParentService) EndSession(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	return s.store.DeleteParentSession(ctx, parentTokenHash(token))
+}
+
+// checkOwner подписанный аккаунт платформы должен быть владельцем профиля ребёнка
+func (s *ParentService) checkOwner(ctx context.Context, childProfileID, platformID, vkUserID string) error {
+	if vkUserID == "" {
+		return domain.ErrUnauthorized
+	}
+	ok, err := s.store.IsChildParent(ctx, childProfileID, platformID, vkUserID)
+	if err != nil {
+		return fmt.Errorf("check parent: %w", err)
+	}
+	if !ok {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// verifyPIN сверяет PIN с учётом блокировки после серии ошибок
+func (s *ParentService) verifyPIN(ctx context.Context, pin *store.ParentPIN, pinCode string) error {
+	now := s.now()
+	if pin.LockedUntil != nil && pin.LockedUntil.After(now) {
+		return domain.ErrRateLimited
+	}
+	hash, err := hashParentPIN(pinCode, pin.Salt)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(hash, pin.Hash) != 1 {
+		if err := s.store.RecordParentPINFailure(ctx, pin.PlatformID, pin.ParentUserID,
+			parentPINMaxFailures, parentPINLockDuration, parentPINMaxLockShift, now); err != nil {
+			log.Printf("[ParentService] Failed to record PIN failure for %s:%s: %v", pin.PlatformID, pin.ParentUserID, err)
+		}
+		return domain.ErrForbidden
+	}
+	if pin.FailedAttempts > 0 || pin.LockedUntil != nil || pin.LockCount > 0 {
+		return s.store.ResetParentPINFailures(ctx, pin.PlatformID, pin.ParentUserID)
+	}
+	return nil
+}
+
+// verifyCode тратит одноразовый код из письма; неверный, истёкший или пустой — ErrForbidden
+func (s *ParentService) verifyCode(ctx context.Context, platformID, vkUserID, code string) error {
+	if code == "" {
+		return domain.ErrForbidden
+	}
+	ok, err := s.store.UseParentPINCode(ctx, platformID, vkUserID, parentTokenHash(code), parentPINCodeMaxAttempt, s.now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// parentFromSession проверяет родительскую сессию для профиля ребёнка и возвращает
+// platform user ID родителя. Нет сессии или она истекла — ErrUnauthorized,
+// сессия другого профиля — ErrForbidden.
+func parentFromSession(ctx context.Context, st *store.Store, childProfileID, sessionToken string) (string, error) {
+	if sessionToken == "" {
+		return "", domain.ErrUnauthorized
+	}
+	session, err := st.GetParentSession(ctx, parentTokenHash(sessionToken), time.Now())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return "", domain.ErrUnauthorized
+		}
+		return "", fmt.Errorf("check parent session: %w", err)
+	}
+	if session.ChildProfileID != childProfileID {
+		return "", domain.ErrForbidden
+	}
+	return session.ParentUserID, nil
+}
+
+func validParentPIN(pin string) bool {
+	if len(pin) < parentPINMinLength || len(pin) > parentPINMaxLength {
+		return false
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// randomDigits случайный код из n цифр
+func randomDigits(n int) (string, error) {
+	digits := make([]byte, n)
+	for i := range digits {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + d.Int64())
+	}
+	return string(digits), nil
+}
+
+// maskEmail скрывает адрес, оставляя первую букву и домен: p***@mail.ru
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+func hashParentPIN(pin string, salt []byte) ([]byte, error) {
+	hash, err := pbkdf2.Key(sha256.New, pin, salt, parentPINIterations, sha256.Size)
+	if err != nil {
+		return nil, fmt.Errorf("hash parent pin: %w", err)
+	}
+	return hash, nil
+}
+
+func parentTokenHash(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
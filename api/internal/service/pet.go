@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/pet"
+	"child-bot/api/internal/store"
+)
+
+// petMessages реплики питомца по настроению
+var petMessages = map[string]string{
+	pet.MoodHappy:    "Ура! Я сыт и счастлив. Решим ещё задачку?",
+	pet.MoodOK:       "Привет! Готов решать задачи?",
+	pet.MoodHungry:   "Я проголодался! Реши задачку — и я перекушу",
+	pet.MoodSad:      "Мне скучно без тебя. Поиграем в задачки?",
+	pet.MoodTired:    "Я немного устал… Одна задачка — и отдохнём",
+	pet.MoodSleeping: "Тс-с… я сплю. Приходи утром!",
+}
+
+// PetService питомец-компаньон: кормится за решённые задачи и победы,
+// показатели меняются по часам ребёнка, стадия растёт с уровнем
+type PetService struct {
+	store *store.Store
+	rules pet.Rules
+	now   func() time.Time
+}
+
+// NewPetService создает новый PetService
+func NewPetService(store *store.Store) *PetService {
+	return &PetService{store: store, rules: pet.DefaultRules(), now: time.Now}
+}
+
+// Pet питомец для главного экрана и экрана питомца
+type Pet struct {
+	Type           string
+	Stage          int
+	StageCode      string // egg, baby, teen, adult
+	StageName      string
+	Hunger         int // 0 — сыт, 100 — очень голоден
+	Happiness      int
+	Energy         int
+	Mood           string // happy, ok, hungry, sad, tired, sleeping
+	IsSleeping     bool
+	Message        string
+	ImageURL       string
+	Level          int // уровень ребёнка
+	NextStageLevel int // уровень следующей стадии; 0 — питомец уже взрослый
+	JustEvolved    bool
+	LastFedAt      *time.Time
+}
+
+// Subscribe подписывает кормление питомца на результаты ребёнка
+func (s *PetService) Subscribe(bus *EventBus) {
+	bus.Subscribe(domain.EventAttemptCompleted, "pet", s.HandleEvent)
+	bus.Subscribe(domain.EventVillainDefeated, "pet", s.HandleEvent)
+}
+
+// HandleEvent кормит питомца: решённая задача — обед, проверка с ошибками — перекус,
+// победа над злодеем — лакомство. Повтор события питомца второй раз не кормит.
+func (s *PetService) HandleEvent(ctx context.Context, event domain.Event) error {
+	var food pet.Food
+	var sourceKey string
+	switch e := event.(type) {
+	case domain.AttemptCompleted:
+		food = pet.Snack
+		if e.IsCorrect {
+			food = pet.Meal
+		}
+		sourceKey = "attempt:" + e.AttemptID
+	case domain.VillainDefeated:
+		food = pet.Treat
+		sourceKey = fmt.Sprintf("villain:%s:%d", e.VillainID, e.BattleID)
+	default:
+		return nil
+	}
+
+	childProfileID := event.EventChildProfileID()
+	loc := childLocation(ctx, s.store, childProfileID)
+	res, err := s.store.FeedPet(ctx, childProfileID, sourceKey, food, s.now(), loc, s.rules)
+	if err != nil {
+		return fmt.Errorf("feed pet: %w", err)
+	}
+	if !res.AlreadyFed {
+		log.Printf("[PetService] Fed pet of child %s with %s (%s): hunger %d -> %d, happiness %d -> %d",
+			childProfileID, food.Code, sourceKey, res.Before.Hunger, res.Pet.Hunger, res.Before.Happiness, res.Pet.Happiness)
+	}
+	return nil
+}
+
+// GetPet возвращает питомца с показателями на текущий момент.
+// Если ребёнок дорос до новой стадии, питомец эволюционирует.
+func (s *PetService) GetPet(ctx context.Context, childProfileID string) (*Pet, error) {
+	now := s.now()
+	row, err := s.store.EnsurePet(ctx, childProfileID, pet.NewStats(), now)
+	if err != nil {
+		return nil, fmt.Errorf("get pet: %w", err)
+	}
+	if row == nil {
+		return nil, domain.ErrNotFound
+	}
+
+	_, level, err := s.store.GetXPAndLevel(ctx, childProfileID)
+	if err != nil {
+		log.Printf("[PetService] Failed to get level for %s: %v", childProfileID, err)
+		level = 1
+	}
+
+	stage := pet.StageByNumber(row.Stage)
+	justEvolved := false
+	if unlocked := pet.StageForLevel(level); unlocked.Number > stage.Number {
+		evolved, err := s.store.EvolvePet(ctx, childProfileID, unlocked.Number, now)
+		if err != nil {
+			log.Printf("[PetService] Failed to evolve pet of child %s: %v", childProfileID, err)
+		} else if evolved {
+			log.Printf("[PetService] Pet of child %s evolved: %s -> %s", childProfileID, stage.Code, unlocked.Code)
+			stage = unlocked
+			justEvolved = true
+		}
+	}
+
+	loc := childLocation(ctx, s.store, childProfileID)
+	stats := pet.Decay(row.Stats, row.StatsAt, now, loc, s.rules)
+	sleeping := s.rules.IsSleeping(now, loc)
+	mood := pet.MoodOf(stats)
+	if sleeping {
+		mood = pet.MoodSleeping
+	}
+
+	p := &Pet{
+		Type:        row.PetType,
+		Stage:       stage.Number,
+		StageCode:   stage.Code,
+		StageName:   stage.Name,
+		Hunger:      stats.Hunger,
+		Happiness:   stats.Happiness,
+		Energy:      stats.Energy,
+		Mood:        mood,
+		IsSleeping:  sleeping,
+		Message:     petMessages[mood],
+		ImageURL:    pet.ImageURL(row.PetType, stage, mood),
+		Level:       level,
+		JustEvolved: justEvolved,
+		LastFedAt:   row.LastFedAt,
+	}
+	if next, ok := pet.NextStage(stage.Number); ok {
+		p.NextStageLevel = next.MinLevel
+	}
+	if justEvolved {
+		p.Message = fmt.Sprintf("Смотри, я вырос! Теперь я — %s", stage.Name)
+	}
+	return p, nil
+}
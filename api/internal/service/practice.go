@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/knowledge"
+	"child-bot/api/internal/practice"
+	"child-bot/api/internal/store"
+)
+
+// PracticeService разминки: задания по классу и слабым местам ребёнка, проверка
+// ответов на сервере и награда в пределах дневного лимита
+type PracticeService struct {
+	store   *store.Store
+	rewards practice.Rewards
+	now     func() time.Time
+}
+
+// NewPracticeService создает новый PracticeService
+func NewPracticeService(store *store.Store) *PracticeService {
+	return &PracticeService{store: store, rewards: practice.DefaultRewards, now: time.Now}
+}
+
+// PracticeDrill вид разминки для ребёнка
+type PracticeDrill struct {
+	practice.Drill
+	Available bool // подходит классу ребёнка
+	Weak      bool // тренирует слабое место ребёнка
+}
+
+// PracticeOverview разминки ребёнка и награда за сегодня
+type PracticeOverview struct {
+	Grade       int
+	Drills      []PracticeDrill
+	Recommended string // вид разминки для самого слабого места; пусто — слабых мест нет
+	XPToday     int
+	CoinsToday  int
+	Rewards     practice.Rewards
+}
+
+// GetDrills виды разминок с отметкой слабых мест и уже полученная сегодня награда
+func (s *PracticeService) GetDrills(ctx context.Context, childProfileID string) (*PracticeOverview, error) {
+	grade, err := s.store.GetChildGrade(ctx, childProfileID)
+	if err != nil {
+		return nil, fmt.Errorf("get child grade: %w", err)
+	}
+	weak, err := s.weakSections(ctx, childProfileID, grade)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := childLocation(ctx, s.store, childProfileID)
+	xp, coins, err := s.store.GetPracticeEarnings(ctx, childProfileID, calendar.DateKey(s.now(), loc))
+	if err != nil {
+		return nil, err
+	}
+
+	o := &PracticeOverview{Grade: grade, XPToday: xp, CoinsToday: coins, Rewards: s.rewards}
+	weakSet := make(map[string]bool)
+	for _, section := range weak {
+		weakSet[section] = true
+	}
+	for _, d := range practice.Drills() {
+		item := PracticeDrill{Drill: d, Available: d.AvailableFor(grade)}
+		for _, section := range d.Sections {
+			item.Weak = item.Weak || weakSet[section]
+		}
+		o.Drills = append(o.Drills, item)
+	}
+	if d, ok := practice.Recommend(grade, weak); ok {
+		o.Recommended = d.Kind
+	}
+	return o, nil
+}
+
+// StartSession начинает разминку вида kind; пустой kind — разминка для самого слабого
+// места, а если слабых мест нет — первая подходящая классу
+func (s *PracticeService) StartSession(ctx context.Context, childProfileID, kind string) (*store.PracticeSession, error) {
+	grade, err := s.store.GetChildGrade(ctx, childProfileID)
+	if err != nil {
+		return nil, fmt.Errorf("get child grade: %w", err)
+	}
+
+	if kind == "" {
+		weak, err := s.weakSections(ctx, childProfileID, grade)
+		if err != nil {
+			return nil, err
+		}
+		kind = defaultDrill(grade, weak)
+	}
+	drill, ok := practice.Lookup(kind)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	if !drill.AvailableFor(grade) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	now := s.now()
+	items, err := practice.Generate(drill.Kind, grade, practice.ItemsPerSession, rand.New(rand.NewSource(now.UnixNano())))
+	if err != nil {
+		return nil, fmt.Errorf("generate drill: %w", err)
+	}
+	session, err := s.store.CreatePracticeSession(ctx, childProfileID, drill.Kind, grade, items, now, now.Add(practice.SessionTTL))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[PracticeService] Session %d (%s, grade %d) started for child %s", session.ID, drill.Kind, grade, childProfileID)
+	return session, nil
+}
+
+// GetSession сессия разминки, например чтобы продолжить её после перезагрузки
+func (s *PracticeService) GetSession(ctx context.Context, childProfileID string, sessionID int64) (*store.PracticeSession, error) {
+	return s.store.GetPracticeSession(ctx, childProfileID, sessionID)
+}
+
+// Answer проверяет ответ на задание
+func (s *PracticeService) Answer(ctx context.Context, childProfileID string, sessionID int64, position int, answer string) (*store.PracticeAnswerResult, error) {
+	return s.store.AnswerPracticeItem(ctx, childProfileID, sessionID, position, answer, s.now())
+}
+
+// Finish завершает разминку и начисляет награду за сегодняшний день ребёнка
+func (s *PracticeService) Finish(ctx context.Context, childProfileID string, sessionID int64) (*store.PracticeFinishResult, error) {
+	now := s.now()
+	date := calendar.DateKey(now, childLocation(ctx, s.store, childProfileID))
+	res, err := s.store.FinishPracticeSession(ctx, childProfileID, sessionID, date, s.rewards, now)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Replayed {
+		p := res.Session
+		log.Printf("[PracticeService] Session %d finished by child %s: %d/%d correct (+%d xp, +%d coins, cap reached: %v)",
+			p.ID, childProfileID, p.CorrectCount, p.ItemsTotal, p.XPAwarded, p.CoinsAwarded, res.CapReached)
+	}
+	return res, nil
+}
+
+// weakSections разделы карты знаний со слабыми местами, от самого слабого
+func (s *PracticeService) weakSections(ctx context.Context, childProfileID string, grade int) ([]string, error) {
+	nodes, err := s.store.GetKnowledgeNodes(ctx, DefaultKnowledgeSubject)
+	if err != nil {
+		return nil, fmt.Errorf("get knowledge nodes: %w", err)
+	}
+	progress, err := s.store.GetKnowledgeProgress(ctx, childProfileID)
+	if err != nil {
+		return nil, fmt.Errorf("get knowledge progress: %w", err)
+	}
+
+	var sections []string
+	seen := make(map[string]bool)
+	for _, topic := range knowledge.WeakSpots(knowledge.BuildTree(nodes, progress, grade), 0) {
+		if !seen[topic.ParentID] {
+			seen[topic.ParentID] = true
+			sections = append(sections, topic.ParentID)
+		}
+	}
+	return sections, nil
+}
+
+// defaultDrill разминка для самого слабого места или первая подходящая классу
+func defaultDrill(grade int, weak []string) string {
+	if d, ok := practice.Recommend(grade, weak); ok {
+		return d.Kind
+	}
+	for _, d := range practice.Drills() {
+		if d.AvailableFor(grade) {
+			return d.Kind
+		}
+	}
+	return ""
+}
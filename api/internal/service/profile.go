@@ -3,10 +3,12 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
+	"child-bot/api/internal/calendar"
 	"child-bot/api/internal/domain"
 	"child-bot/api/internal/store"
 
@@ -15,8 +17,8 @@ import (
 
 // ProfileService бизнес-логика для профиля
 type ProfileService struct {
-	store              *store.Store
-	achievementService *AchievementService
+	store  *store.Store
+	events *EventBus
 }
 
 // NewProfileService создает новый ProfileService
@@ -24,9 +26,9 @@ func NewProfileService(store *store.Store) *ProfileService {
 	return &ProfileService{store: store}
 }
 
-// SetAchievementService устанавливает AchievementService (для избежания циклических зависимостей)
-func (s *ProfileService) SetAchievementService(achievementService *AchievementService) {
-	s.achievementService = achievementService
+// SetEventBus устанавливает шину доменных событий
+func (s *ProfileService) SetEventBus(events *EventBus) {
+	s.events = events
 }
 
 // ProfileData полные данные профиля
@@ -37,6 +39,7 @@ type ProfileData struct {
 	AvatarURL    string
 	Grade        int
 	Email        string
+	Timezone     string
 	Subscription SubscriptionData
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
@@ -54,11 +57,35 @@ type SubscriptionData struct {
 }
 
 // CreateChildProfile создает профиль ребенка или обновляет существующий (UPSERT)
-// Если профиль с таким platform_id + platform_user_id уже существует, обновляет его данные
-func (s *ProfileService) CreateChildProfile(ctx context.Context, platformUserID, displayName, avatarID, platformID string, grade int) (string, error) {
+// Если профиль с таким platform_id + platform_user_id уже существует, обновляет его данные.
+// Часовой пояс задаётся только при создании: выбранный позже в настройках не перетирается.
+// Аватар должен быть бесплатным или уже принадлежать существующему профилю, иначе ErrForbidden.
+func (s *ProfileService) CreateChildProfile(ctx context.Context, platformUserID, displayName, avatarID, platformID string, grade int, timezone string) (string, error) {
+	if timezone == "" {
+		timezone = calendar.DefaultTimezone
+	}
+	if !calendar.IsValidTimezone(timezone) {
+		return "", domain.ErrInvalidInput
+	}
+
+	var existingID string
+	err := s.store.DB.QueryRowContext(ctx, `
+		SELECT id FROM child_profiles WHERE platform_id = $1 AND platform_user_id = $2
+	`, platformID, platformUserID).Scan(&existingID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("find child profile: %w", err)
+	}
+	owned, err := s.store.IsAvatarOwned(ctx, existingID, avatarID)
+	if err != nil {
+		return "", err
+	}
+	if !owned {
+		return "", domain.ErrForbidden
+	}
+
 	query := `
-		INSERT INTO child_profiles (display_name, avatar_id, grade, platform_id, platform_user_id)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO child_profiles (display_name, avatar_id, grade, platform_id, platform_user_id, timezone)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (platform_id, platform_user_id)
 		DO UPDATE SET
 			display_name = EXCLUDED.display_name,
@@ -69,7 +96,7 @@ func (s *ProfileService) CreateChildProfile(ctx context.Context, platformUserID,
 	`
 
 	var childProfileID string
-	err := s.store.DB.QueryRowContext(ctx, query, displayName, avatarID, grade, platformID, platformUserID).Scan(&childProfileID)
+	err = s.store.DB.QueryRowContext(ctx, query, displayName, avatarID, grade, platformID, platformUserID, timezone).Scan(&childProfileID)
 	if err != nil {
 		return "", err
 	}
@@ -95,7 +122,7 @@ var avatarEmojiMap = map[string]string{
 func (s *ProfileService) GetProfile(ctx context.Context, childProfileID string) (*ProfileData, error) {
 	// Загрузка базовых данных профиля
 	query := `
-		SELECT id, display_name, avatar_id, grade, created_at, updated_at
+		SELECT id, display_name, avatar_id, grade, timezone, created_at, updated_at
 		FROM child_profiles
 		WHERE id = $1
 	`
@@ -106,6 +133,7 @@ func (s *ProfileService) GetProfile(ctx context.Context, childProfileID string)
 		&profile.DisplayName,
 		&profile.AvatarID,
 		&profile.Grade,
+		&profile.Timezone,
 		&profile.CreatedAt,
 		&profile.UpdatedAt,
 	)
@@ -177,23 +205,58 @@ func (s *ProfileService) GetProfile(ctx context.Context, childProfileID string)
 	return &profile, nil
 }
 
-// UpdateProfile обновляет профиль
-func (s *ProfileService) UpdateProfile(ctx context.Context, childProfileID string, updates map[string]interface{}) error {
-	// TODO: Phase 5 - обновление в БД
+// ProfileUpdate изменяемые поля профиля (пустые значения не меняются)
+type ProfileUpdate struct {
+	DisplayName string
+	AvatarID    string
+	Grade       int
+	Timezone    string
+}
 
-	// Валидация updates
-	if displayName, ok := updates["display_name"].(string); ok {
-		if len(displayName) > 50 {
-			return domain.ErrInvalidInput
-		}
+// UpdateProfile обновляет профиль. Надеть можно только свой аватар:
+// бесплатный, купленный в магазине или полученный за достижение.
+func (s *ProfileService) UpdateProfile(ctx context.Context, childProfileID string, update ProfileUpdate) error {
+	if len(update.DisplayName) > 50 {
+		return domain.ErrInvalidInput
+	}
+
+	if update.Grade != 0 && (update.Grade < 1 || update.Grade > 4) {
+		return domain.ErrInvalidInput
+	}
+
+	if update.Timezone != "" && !calendar.IsValidTimezone(update.Timezone) {
+		return domain.ErrInvalidInput
 	}
 
-	if grade, ok := updates["grade"].(int); ok {
-		if grade < 1 || grade > 4 {
-			return domain.ErrInvalidInput
+	if update.AvatarID != "" {
+		owned, err := s.store.IsAvatarOwned(ctx, childProfileID, update.AvatarID)
+		if err != nil {
+			return err
+		}
+		if !owned {
+			return domain.ErrForbidden
 		}
 	}
 
+	query := `
+		UPDATE child_profiles
+		SET display_name = COALESCE(NULLIF($1, ''), display_name),
+		    avatar_id = COALESCE(NULLIF($2, ''), avatar_id),
+		    grade = COALESCE(NULLIF($3, 0), grade),
+		    timezone = COALESCE(NULLIF($4, ''), timezone),
+		    updated_at = NOW()
+		WHERE id = $5
+	`
+	result, err := s.store.DB.ExecContext(ctx, query, update.DisplayName, update.AvatarID, update.Grade, update.Timezone, childProfileID)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	log.Printf("[ProfileService] Profile %s updated (avatar=%q)", childProfileID, update.AvatarID)
 	return nil
 }
 
@@ -440,43 +503,39 @@ func (s *ProfileService) ActivateReferral(ctx context.Context, childProfileID st
 	rowsAffected, _ := result.RowsAffected()
 	log.Printf("[ActivateReferral] Successfully activated referral for %s, rows affected: %d", childProfileID, rowsAffected)
 
-	// Проверяем достижения за приглашённых друзей у реферера
-	if s.achievementService != nil && referrerID != "" {
-		err = s.achievementService.CheckFriendsInvitedAchievements(ctx, referrerID)
-		if err != nil {
-			log.Printf("[ActivateReferral] Failed to check friends invited achievements for referrer %s: %v", referrerID, err)
-			// Не блокируем, продолжаем
-		}
+	// Достижения за приглашённых друзей у реферера считает подписчик
+	if rowsAffected > 0 && referrerID != "" {
+		s.events.Publish(ctx, domain.FriendActivated{
+			ChildProfileID:  referrerID,
+			FriendProfileID: childProfileID,
+		})
 	}
 
 	return nil
 }
 
-// AddCoins начисляет монеты пользователю
-func (s *ProfileService) AddCoins(ctx context.Context, childProfileID string, amount int) error {
+// AddCoins начисляет монеты пользователю через журнал.
+// Повтор события с тем же ключом (src) монеты не начисляет.
+func (s *ProfileService) AddCoins(ctx context.Context, childProfileID string, amount int, src store.WalletSource) error {
 	if amount <= 0 {
 		return domain.ErrInvalidInput
 	}
 
-	query := `
-		UPDATE child_profiles
-		SET coins_balance = coins_balance + $1,
-		    updated_at = NOW()
-		WHERE id = $2
-	`
-
-	result, err := s.store.DB.ExecContext(ctx, query, amount, childProfileID)
+	applied, err := s.store.AddCoins(ctx, childProfileID, amount, src)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrNotFound
+	}
 	if err != nil {
 		log.Printf("[AddCoins] Error adding %d coins to %s: %v", amount, childProfileID, err)
 		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return domain.ErrNotFound
+	if !applied {
+		log.Printf("[AddCoins] Coins for %s/%s already added to child %s", src.Type, src.ID, childProfileID)
+		return nil
 	}
 
-	log.Printf("[AddCoins] Added %d coins to child %s", amount, childProfileID)
+	log.Printf("[AddCoins] Added %d coins to child %s (%s/%s)", amount, childProfileID, src.Type, src.ID)
 	return nil
 }
 
@@ -505,100 +564,45 @@ func (s *ProfileService) IncrementHintsUsed(ctx context.Context, childProfileID
 }
 
 // UpdateStreakAndActivity обновляет серию дней (streak) и last_activity_at
-// Вызывается ОДИН РАЗ В ДЕНЬ при первом действии пользователя
+// Вызывается ОДИН РАЗ В ДЕНЬ при первом действии пользователя.
+// Дни считаются по календарю ребёнка (child_profiles.timezone), а не по UTC.
+// Пропуски закрываются прощёнными родителем днями и заморозками серии.
 func (s *ProfileService) UpdateStreakAndActivity(ctx context.Context, childProfileID string) error {
-	// Получаем профиль
-	query := `
-		SELECT last_activity_at, streak_days
-		FROM child_profiles
-		WHERE id = $1
-	`
-
-	var lastActivityAt *time.Time
-	var currentStreak int
+	loc := childLocation(ctx, s.store, childProfileID)
+	now := time.Now().In(loc)
 
-	err := s.store.DB.QueryRowContext(ctx, query, childProfileID).Scan(&lastActivityAt, &currentStreak)
+	act, err := s.store.RecordStreakActivity(ctx, childProfileID, now, loc)
 	if err != nil {
-		log.Printf("[UpdateStreakAndActivity] Error getting profile %s: %v", childProfileID, err)
+		log.Printf("[UpdateStreakAndActivity] Error updating profile %s: %v", childProfileID, err)
 		return err
 	}
 
-	now := time.Now().UTC()
-	newStreak := currentStreak
-
-	// Если last_activity_at пустой (первый заход), устанавливаем streak = 1
-	if lastActivityAt == nil {
-		newStreak = 1
-		log.Printf("[UpdateStreakAndActivity] First login for %s, setting streak = 1", childProfileID)
-	} else {
-		// Вычисляем разницу в днях (только дата, без времени)
-		lastDate := lastActivityAt.Truncate(24 * time.Hour)
-		currentDate := now.Truncate(24 * time.Hour)
-		daysDiff := int(currentDate.Sub(lastDate).Hours() / 24)
-
-		log.Printf("[UpdateStreakAndActivity] Profile %s: last_activity=%s, now=%s, days_diff=%d",
-			childProfileID, lastActivityAt.Format("2006-01-02 15:04"), now.Format("2006-01-02 15:04"), daysDiff)
-
-		if daysDiff == 0 {
-			// Тот же день - ничего не делаем со streak, только обновляем time
-			log.Printf("[UpdateStreakAndActivity] Same day, keeping streak=%d", currentStreak)
-			// Обновляем только last_activity_at
-			updateQuery := `
-				UPDATE child_profiles
-				SET last_activity_at = $1,
-				    updated_at = NOW()
-				WHERE id = $2
-			`
-			_, err = s.store.DB.ExecContext(ctx, updateQuery, now, childProfileID)
-			if err != nil {
-				return err
-			}
-			return nil
-		} else if daysDiff == 1 {
-			// Следующий день - увеличиваем streak
-			newStreak = currentStreak + 1
-			log.Printf("[UpdateStreakAndActivity] Next day, incrementing streak: %d -> %d", currentStreak, newStreak)
-		} else {
-			// Пропущено больше 1 дня - сбрасываем streak
-			newStreak = 1
-			log.Printf("[UpdateStreakAndActivity] Missed days (%d), resetting streak: %d -> 1", daysDiff, currentStreak)
-		}
-	}
-
-	// Обновляем streak_days и last_activity_at
-	updateQuery := `
-		UPDATE child_profiles
-		SET streak_days = $1,
-		    last_activity_at = $2,
-		    updated_at = NOW()
-		WHERE id = $3
-	`
-
-	result, err := s.store.DB.ExecContext(ctx, updateQuery, newStreak, now, childProfileID)
-	if err != nil {
-		log.Printf("[UpdateStreakAndActivity] Error updating profile %s: %v", childProfileID, err)
-		return err
+	if act.SameDay {
+		// Тот же день - streak не меняется, обновлено только время активности
+		return nil
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return domain.ErrNotFound
+	switch {
+	case act.Broken:
+		log.Printf("[UpdateStreakAndActivity] Missed days for %s, resetting streak: %d -> 1", childProfileID, act.PreviousStreak)
+	case act.FreezesUsed > 0 || act.ExcusedDays > 0:
+		log.Printf("[UpdateStreakAndActivity] Gap covered for %s (freezes=%d, excused=%d), streak: %d -> %d",
+			childProfileID, act.FreezesUsed, act.ExcusedDays, act.PreviousStreak, act.Streak)
 	}
 
-	log.Printf("[UpdateStreakAndActivity] Successfully updated profile %s: streak=%d, last_activity=%s",
-		childProfileID, newStreak, now.Format("2006-01-02 15:04:05"))
+	log.Printf("[UpdateStreakAndActivity] Successfully updated profile %s: streak=%d, last_activity=%s (%s)",
+		childProfileID, act.Streak, now.Format("2006-01-02 15:04:05"), loc)
 
-	// Проверяем достижения за streak (только если изменился)
-	if s.achievementService != nil && newStreak != currentStreak {
-		err = s.achievementService.CheckStreakAchievements(ctx, childProfileID)
-		if err != nil {
-			log.Printf("[UpdateStreakAndActivity] Failed to check streak achievements: %v", err)
-			// Не блокируем, продолжаем
-		}
+	// Достижения за streak считает подписчик (только если изменился)
+	if act.Streak != act.PreviousStreak {
+		s.events.Publish(ctx, domain.StreakUpdated{
+			ChildProfileID: childProfileID,
+			StreakDays:     act.Streak,
+		})
 	}
 
 	// Начисляем XP за ежедневный вход
-	err = s.AwardDailyLogin(ctx, childProfileID)
+	err = s.AwardDailyLogin(ctx, childProfileID, now)
 	if err != nil {
 		log.Printf("[UpdateStreakAndActivity] Failed to award daily login XP: %v", err)
 		// Не блокируем, продолжаем
@@ -4,12 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"html/template"
 	"log"
 	"math"
 	"os"
 	"os/exec"
 	"time"
 
+	"child-bot/api/internal/calendar"
 	"child-bot/api/internal/store"
 
 	"github.com/google/uuid"
@@ -47,6 +49,7 @@ type WeeklyReportData struct {
 	WeekAvgTimeMinutes float64
 	NewAchievements    []AchievementData
 	VillainBattles     []VillainBattleData
+	RewardRedemptions  []RewardRedemptionData // награды за монеты, запрошенные за неделю
 	AttemptsChange     *int
 	AccuracyChange     *float64
 	TimeChange         *int
@@ -74,6 +77,15 @@ type VillainBattleData struct {
 	Status            string
 }
 
+// RewardRedemptionData запрос награды за монеты в отчёте
+type RewardRedemptionData struct {
+	Title       string
+	Icon        string
+	Price       int
+	Status      string // pending, approved, declined
+	RequestedAt time.Time
+}
+
 type WeeklyReport struct {
 	ID          uuid.UUID  `json:"id"`
 	UserID      uuid.UUID  `json:"user_id"`
@@ -91,13 +103,36 @@ type AchievementInfo struct {
 	UnlockedAt string
 }
 
-// GetWeeklyReportData получает данные для отчёта за неделю
+// WeekStart понедельник недели отчёта по календарю ребёнка.
+// date в формате YYYY-MM-DD — любой день нужной недели, пустая строка — текущая неделя.
+func (s *ReportService) WeekStart(ctx context.Context, childProfileID, date string) (time.Time, error) {
+	loc := childLocation(ctx, s.store, childProfileID)
+	if date == "" {
+		return calendar.WeekStart(time.Now(), loc), nil
+	}
+	day, err := calendar.ParseDate(date, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid week date %q: %w", date, err)
+	}
+	return calendar.WeekStart(day, loc), nil
+}
+
+// reportWeekStart понедельник недели, в которую попадает календарная дата day, в поясе loc.
+// Берём дату, а не момент времени: полночь UTC во Владивостоке и в Нью-Йорке — разные дни.
+func reportWeekStart(day time.Time, loc *time.Location) time.Time {
+	y, m, d := day.Date()
+	return calendar.WeekStart(time.Date(y, m, d, 0, 0, 0, 0, loc), loc)
+}
+
+// GetWeeklyReportData получает данные для отчёта за неделю.
+// Границы недели — полночь понедельника и следующего понедельника по времени ребёнка.
 func (s *ReportService) GetWeeklyReportData(ctx context.Context, childProfileID string, weekStart time.Time) (*WeeklyReportData, error) {
 	profileUUID, err := uuid.Parse(childProfileID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid child_profile_id: %w", err)
 	}
 
+	weekStart = reportWeekStart(weekStart, childLocation(ctx, s.store, childProfileID))
 	weekEnd := weekStart.AddDate(0, 0, 6) // Sunday
 	prevWeekStart := weekStart.AddDate(0, 0, -7)
 	prevWeekEnd := weekEnd.AddDate(0, 0, -7)
@@ -115,7 +150,7 @@ func (s *ReportService) GetWeeklyReportData(ctx context.Context, childProfileID
 	}
 
 	// Calculate XP for next level
-	data.XPForNext = store.XPForLevel(data.Level)
+	data.XPForNext = s.store.XP.XPForLevel(data.Level)
 
 	// Avatar emoji
 	avatarEmojis := map[string]string{
@@ -175,6 +210,21 @@ func (s *ReportService) GetWeeklyReportData(ctx context.Context, childProfileID
 	}
 	data.VillainBattles = villainBattles
 
+	// Награды за монеты, запрошенные за неделю
+	redemptions, err := s.store.ListFamilyRedemptionsBetween(ctx, childProfileID, weekStart, weekEnd.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reward redemptions: %w", err)
+	}
+	for _, r := range redemptions {
+		data.RewardRedemptions = append(data.RewardRedemptions, RewardRedemptionData{
+			Title:       r.Title,
+			Icon:        r.Icon,
+			Price:       r.Price,
+			Status:      r.Status,
+			RequestedAt: r.RequestedAt,
+		})
+	}
+
 	// Calculate comparison with previous week
 	log.Printf("[ReportService] Calculating comparison: weekStart=%s, prevWeekStart=%s, totalAttempts=%d",
 		weekStart.Format("2006-01-02"), prevWeekStart.Format("2006-01-02"), totalAttempts)
@@ -305,13 +355,11 @@ func (s *ReportService) getVillainBattles(ctx context.Context, childProfileID uu
 	return battles, rows.Err()
 }
 
+// GenerateWeeklyReport генерирует отчёт за неделю, в которую попадает reportDate.
+// Отчёт сохраняется под датой понедельника этой недели по календарю ребёнка.
 func (s *ReportService) GenerateWeeklyReport(ctx context.Context, childProfileID uuid.UUID, reportDate time.Time) (*WeeklyReport, error) {
 	// Calculate week start (Monday) and end (Sunday) for the report date
-	daysSinceMonday := int(reportDate.Weekday() - time.Monday)
-	if daysSinceMonday < 0 {
-		daysSinceMonday += 7
-	}
-	weekStart := reportDate.AddDate(0, 0, -daysSinceMonday)
+	weekStart := reportWeekStart(reportDate, childLocation(ctx, s.store, childProfileID.String()))
 	weekEnd := weekStart.AddDate(0, 0, 6)
 
 	data, err := s.GetWeeklyReportData(ctx, childProfileID.String(), weekStart)
@@ -330,7 +378,7 @@ func (s *ReportService) GenerateWeeklyReport(ctx context.Context, childProfileID
 
 	report := &WeeklyReport{
 		UserID:      childProfileID,
-		ReportDate:  reportDate,
+		ReportDate:  weekStart,
 		HTMLContent: html,
 	}
 
@@ -348,7 +396,7 @@ func (s *ReportService) SaveWeeklyReport(ctx context.Context, report *WeeklyRepo
 
 	now := time.Now()
 	err := s.store.DB.QueryRowContext(ctx, query,
-		report.UserID, report.ReportDate, report.HTMLContent, now, now).Scan(&report.ID)
+		report.UserID, report.ReportDate.Format("2006-01-02"), report.HTMLContent, now, now).Scan(&report.ID)
 	if err != nil {
 		return fmt.Errorf("failed to save weekly report: %w", err)
 	}
@@ -452,6 +500,27 @@ func (s *ReportService) generateWeeklyReportHTML(data *WeeklyReportData) (string
 		}
 	}
 
+	// Generate reward redemptions HTML: названия наград вводит родитель, поэтому экранируем
+	redemptionsHTML := ""
+	for _, r := range data.RewardRedemptions {
+		status := "⏳ Ждёт решения"
+		switch r.Status {
+		case store.FamilyRedemptionApproved:
+			status = "✓ Одобрена"
+		case store.FamilyRedemptionDeclined:
+			status = "✗ Отклонена, монеты возвращены"
+		}
+		redemptionsHTML += fmt.Sprintf(`
+				<div class="achievement-item">
+					<span class="achievement-icon">%s</span>
+					<div class="achievement-info">
+						<div class="achievement-title">%s <span class="badge">%d 🪙</span></div>
+						<div class="achievement-date">%s · %s</div>
+					</div>
+				</div>`,
+			template.HTMLEscapeString(r.Icon), template.HTMLEscapeString(r.Title), r.Price, r.RequestedAt.Format("02.01.2006"), status)
+	}
+
 	// Generate comparison HTML
 	comparisonHTML := ""
 	if data.AttemptsChange != nil || data.AccuracyChange != nil || data.TimeChange != nil {
@@ -838,6 +907,8 @@ func (s *ReportService) generateWeeklyReportHTML(data *WeeklyReportData) (string
 
         %s
 
+        %s
+
         <div class="section">
             <h2>📊 Сравнение с прошлой неделей</h2>
             %s
@@ -886,6 +957,15 @@ func (s *ReportService) generateWeeklyReportHTML(data *WeeklyReportData) (string
 			}
 			return ""
 		}(),
+		func() string {
+			if redemptionsHTML != "" {
+				return fmt.Sprintf(`<div class="section">
+            <h2>🎁 Награды за монеты</h2>
+            %s
+        </div>`, redemptionsHTML)
+			}
+			return ""
+		}(),
 		comparisonHTML)
 
 	return html, nil
@@ -908,40 +988,42 @@ func (s *ReportService) StartWeeklyReportScheduler(ctx context.Context) {
 }
 
 func (s *ReportService) generateAndSaveWeeklyReports(ctx context.Context) {
-	// Generate reports for the previous week (Monday to Sunday)
+	// Generate reports for the previous week (Monday to Sunday) of each child's calendar
 	now := time.Now()
-	// Find previous Sunday (end of previous week)
-	daysSinceSunday := int(now.Weekday())
-	if daysSinceSunday == 0 { // Sunday
-		daysSinceSunday = 7
-	}
-	weekEnd := now.AddDate(0, 0, -daysSinceSunday)
-	weekStart := weekEnd.AddDate(0, 0, -6) // Monday of that week
 
-	// Get all child profile IDs
-	rows, err := s.store.DB.QueryContext(ctx, "SELECT id FROM child_profiles")
+	// Get all child profile IDs with their timezones
+	rows, err := s.store.DB.QueryContext(ctx, "SELECT id, timezone FROM child_profiles")
 	if err != nil {
 		log.Printf("Failed to query profiles for weekly reports: %v", err)
 		return
 	}
 	defer rows.Close()
 
-	var profileIDs []uuid.UUID
+	type profileWeek struct {
+		id  uuid.UUID
+		loc *time.Location
+	}
+	var profiles []profileWeek
 	for rows.Next() {
 		var id uuid.UUID
-		if err := rows.Scan(&id); err != nil {
+		var timezone string
+		if err := rows.Scan(&id, &timezone); err != nil {
 			log.Printf("Failed to scan profile ID: %v", err)
 			continue
 		}
-		profileIDs = append(profileIDs, id)
+		profiles = append(profiles, profileWeek{id: id, loc: calendar.Location(timezone)})
 	}
 	if err := rows.Err(); err != nil {
 		log.Printf("Error iterating profiles: %v", err)
 		return
 	}
 
-	for _, profileID := range profileIDs {
-		report, err := s.GenerateWeeklyReport(ctx, profileID, weekEnd)
+	for _, p := range profiles {
+		profileID := p.id
+		weekStart := calendar.WeekStart(now, p.loc).AddDate(0, 0, -7) // Monday of the previous week
+		weekEnd := weekStart.AddDate(0, 0, 6)
+
+		report, err := s.GenerateWeeklyReport(ctx, profileID, weekStart)
 		if err != nil {
 			log.Printf("Failed to generate weekly report for user %s: %v", profileID, err)
 			continue
@@ -968,8 +1050,9 @@ func (s *ReportService) GetWeeklyHTML(ctx context.Context, childProfileID string
 
 	// Пытаемся получить существующий отчёт из БД
 	var htmlContent string
+	weekStart = reportWeekStart(weekStart, childLocation(ctx, s.store, childProfileID))
 	query := `SELECT html_content FROM weekly_reports WHERE user_id = $1 AND report_date = $2`
-	err = s.store.DB.QueryRowContext(ctx, query, profileUUID, weekStart).Scan(&htmlContent)
+	err = s.store.DB.QueryRowContext(ctx, query, profileUUID, weekStart.Format("2006-01-02")).Scan(&htmlContent)
 	if err == nil {
 		// Отчёт найден в БД
 		return htmlContent, nil
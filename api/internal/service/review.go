@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/llm"
+	"child-bot/api/internal/llm/types"
+	"child-bot/api/internal/review"
+	"child-bot/api/internal/store"
+)
+
+const (
+	// reviewHintsThreshold с какой подсказки (L3) задача уходит на повторение
+	reviewHintsThreshold = 3
+	// reviewDueLimit сколько задач отдавать на повторение за раз
+	reviewDueLimit = 5
+	// reviewAnalogueTimeout сколько ждать аналог от LLM, прежде чем взять исходную задачу
+	reviewAnalogueTimeout = 30 * time.Second
+	// reviewPrepareBatch сколько заданий готовить за один проход воркера
+	reviewPrepareBatch = 20
+)
+
+// ReviewService «Машина времени»: задачи с ошибкой или третьей подсказкой
+// возвращаются к ребёнку по интервалам SM-2. Задания-аналоги готовит фоновый воркер
+// (RunTaskPreparation), запросы ребёнка LLM не ждут.
+type ReviewService struct {
+	store      *store.Store
+	llmClient  *llm.Client
+	defaultLLM string
+	now        func() time.Time
+	wake       chan struct{} // будит воркер заданий после постановки в очередь и переноса
+}
+
+// NewReviewService создает новый ReviewService
+func NewReviewService(store *store.Store, llmClient *llm.Client, defaultLLM string) *ReviewService {
+	return &ReviewService{
+		store:      store,
+		llmClient:  llmClient,
+		defaultLLM: defaultLLM,
+		now:        time.Now,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// ReviewTask задача на повторение
+type ReviewTask struct {
+	ID           int64
+	Reason       string // after_incorrect, after_3_hints
+	Subject      string
+	TaskText     string // задание для повторения
+	TaskSource   string // analogue, original
+	DueOn        string
+	ReviewsCount int
+	IntervalDays int
+	CreatedAt    time.Time
+}
+
+// DueReviews задачи на повторение на сегодня
+type DueReviews struct {
+	Items     []ReviewTask
+	Due       int // всего к повторению сегодня (Items — не больше reviewDueLimit из них)
+	Upcoming  int
+	Retired   int
+	NextDueOn string
+}
+
+// ReviewResult результат повторения
+type ReviewResult struct {
+	Decision     string
+	IsCorrect    bool
+	Feedback     string
+	Rescheduled  bool // false — ответ не удалось проверить, повторение не засчитано
+	Retired      bool // задача закреплена и ушла из очереди
+	IntervalDays int
+	NextDueOn    string
+}
+
+// Subscribe подписывает очередь повторения на проверки и подсказки
+func (s *ReviewService) Subscribe(bus *EventBus) {
+	bus.Subscribe(domain.EventAttemptCompleted, "review", s.HandleEvent)
+	bus.Subscribe(domain.EventHintRequested, "review", s.HandleEvent)
+}
+
+// HandleEvent ставит в очередь задачу, решённую с ошибкой или с третьей подсказкой.
+// Одна попытка попадает в очередь один раз.
+func (s *ReviewService) HandleEvent(ctx context.Context, event domain.Event) error {
+	var attemptID, reason string
+	switch e := event.(type) {
+	case domain.AttemptCompleted:
+		switch {
+		case !e.IsCorrect:
+			reason = review.ReasonAfterIncorrect
+		case e.HintsUsed >= reviewHintsThreshold:
+			reason = review.ReasonAfter3Hints
+		default:
+			return nil
+		}
+		attemptID = e.AttemptID
+	case domain.HintRequested:
+		if e.HintIndex+1 < reviewHintsThreshold {
+			return nil
+		}
+		attemptID, reason = e.AttemptID, review.ReasonAfter3Hints
+	default:
+		return nil
+	}
+
+	childProfileID := event.EventChildProfileID()
+	in, err := s.reviewInput(ctx, childProfileID, attemptID, reason)
+	if err != nil {
+		return err
+	}
+	if in == nil {
+		return nil
+	}
+
+	loc := childLocation(ctx, s.store, childProfileID)
+	dueOn := calendar.DateKey(calendar.StartOfDay(s.now(), loc).AddDate(0, 0, review.Initial().IntervalDays), loc)
+	created, err := s.store.EnqueueReviewItem(ctx, *in, dueOn)
+	if err != nil {
+		return fmt.Errorf("enqueue review: %w", err)
+	}
+	if created {
+		log.Printf("[ReviewService] Attempt %s of child %s queued for review on %s (%s)", attemptID, childProfileID, dueOn, reason)
+		s.wakeTaskPreparation()
+	}
+	return nil
+}
+
+// reviewInput снимок задачи из попытки; nil — задача не распознана, повторять нечего
+func (s *ReviewService) reviewInput(ctx context.Context, childProfileID, attemptID, reason string) (*store.ReviewItemInput, error) {
+	id, err := uuid.Parse(attemptID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attempt_id: %w", err)
+	}
+	attempt, err := s.store.Attempts.GetAttempt(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get attempt: %w", err)
+	}
+	if len(attempt.ParseResult) == 0 {
+		return nil, nil
+	}
+
+	var parseResp types.ParseResponse
+	if err := json.Unmarshal(attempt.ParseResult, &parseResp); err != nil {
+		return nil, fmt.Errorf("unmarshal parse result: %w", err)
+	}
+	if strings.TrimSpace(parseResp.Task.TaskTextClean) == "" {
+		return nil, nil
+	}
+
+	return &store.ReviewItemInput{
+		ChildProfileID:  childProfileID,
+		SourceAttemptID: attemptID,
+		Reason:          reason,
+		Subject:         string(parseResp.Task.Subject),
+		Grade:           int(parseResp.Task.Grade),
+		TaskText:        parseResp.Task.TaskTextClean,
+		TaskStruct:      attempt.ParseResult,
+	}, nil
+}
+
+// GetDueReviews возвращает задачи, которые пора повторить сегодня по календарю ребёнка.
+// Задание берётся готовое: аналог от воркера, а если его ещё нет — исходная задача.
+func (s *ReviewService) GetDueReviews(ctx context.Context, childProfileID string) (*DueReviews, error) {
+	loc := childLocation(ctx, s.store, childProfileID)
+	today := calendar.DateKey(s.now(), loc)
+
+	items, err := s.store.GetDueReviewItems(ctx, childProfileID, today, reviewDueLimit)
+	if err != nil {
+		return nil, fmt.Errorf("get due reviews: %w", err)
+	}
+	stats, err := s.store.GetReviewStats(ctx, childProfileID, today)
+	if err != nil {
+		return nil, fmt.Errorf("get review stats: %w", err)
+	}
+
+	due := &DueReviews{Due: stats.Due, Upcoming: stats.Upcoming, Retired: stats.Retired, NextDueOn: stats.NextDueOn}
+	for i := range items {
+		item := &items[i]
+		if err := s.ensureTask(ctx, item); err != nil {
+			log.Printf("[ReviewService] Failed to save task for review %d, showing original: %v", item.ID, err)
+			item.ReviewTaskText, item.ReviewTaskSource = item.TaskText, store.ReviewTaskOriginal
+		}
+		due.Items = append(due.Items, ReviewTask{
+			ID:           item.ID,
+			Reason:       item.Reason,
+			Subject:      item.Subject,
+			TaskText:     item.ReviewTaskText,
+			TaskSource:   item.ReviewTaskSource,
+			DueOn:        item.DueOn,
+			ReviewsCount: item.ReviewsCount,
+			IntervalDays: item.IntervalDays,
+			CreatedAt:    item.CreatedAt,
+		})
+	}
+	return due, nil
+}
+
+// SubmitReview проверяет решение задачи на повторение (фото ответа) и переносит задачу по SM-2:
+// верно — интервал растёт, ошибка — задача вернётся завтра
+func (s *ReviewService) SubmitReview(ctx context.Context, childProfileID string, reviewID int64, answerImage string) (*ReviewResult, error) {
+	item, err := s.store.GetReviewItem(ctx, childProfileID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	loc := childLocation(ctx, s.store, childProfileID)
+	today := calendar.DateKey(s.now(), loc)
+	if item.Status != store.ReviewItemActive || item.DueOn > today {
+		return nil, domain.ErrReviewNotDue
+	}
+	if err := s.ensureTask(ctx, item); err != nil {
+		return nil, err
+	}
+
+	checkReq := types.CheckRequest{
+		Image: answerImage,
+		TaskStruct: types.TaskStructCheck{
+			TaskTextClean: item.ReviewTaskText,
+		},
+		RawTaskText: item.ReviewTaskText,
+		Student: types.StudentCheck{
+			Grade:   int64(item.Grade),
+			Subject: item.Subject,
+			Locale:  "ru-RU",
+		},
+	}
+	// Исходную задачу проверяем по её разбору; аналог — только по тексту
+	if item.ReviewTaskSource == store.ReviewTaskOriginal && len(item.TaskStruct) > 0 {
+		var parseResp types.ParseResponse
+		if err := json.Unmarshal(item.TaskStruct, &parseResp); err == nil {
+			checkReq.TaskStruct.VisualReasoning = parseResp.Task.VisualReasoning
+			checkReq.TaskStruct.VisualFacts = parseResp.Task.VisualFacts
+			checkReq.TaskStruct.QualityFlags = parseResp.Task.Quality
+			checkReq.TaskStruct.Items = parseResp.Items
+		}
+	}
+
+	checkResp, err := s.llmClient.CheckSolution(ctx, s.defaultLLM, checkReq)
+	if err != nil {
+		return nil, fmt.Errorf("check review solution: %w", err)
+	}
+	checkResp.NormalizeDecision()
+
+	result := &ReviewResult{Decision: string(checkResp.Decision), Feedback: checkResp.Feedback, NextDueOn: item.DueOn, IntervalDays: item.IntervalDays}
+	var quality int
+	switch checkResp.Decision {
+	case types.CheckDecisionCorrect:
+		quality = review.QualityPerfect
+		result.IsCorrect = true
+	case types.CheckDecisionIncorrect:
+		quality = review.QualityFailed
+	default:
+		// Ответ не удалось проверить (плохое фото и т.п.) — повторение не засчитываем
+		return result, nil
+	}
+
+	updated, err := s.store.RecordReviewResult(ctx, childProfileID, reviewID, result.Decision, quality, today, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("record review result: %w", err)
+	}
+	log.Printf("[ReviewService] Review %d of child %s: %s, next in %d days (%s)",
+		reviewID, childProfileID, result.Decision, updated.IntervalDays, updated.Status)
+
+	result.Rescheduled = true
+	result.Retired = updated.Status == store.ReviewItemRetired
+	if !result.Retired {
+		// Для следующего повторения нужно новое задание
+		s.wakeTaskPreparation()
+	}
+	result.IntervalDays = updated.IntervalDays
+	result.NextDueOn = updated.DueOn
+	if result.Retired {
+		result.NextDueOn = ""
+	}
+	return result, nil
+}
+
+// ensureTask закрепляет задание за ближайшим повторением без обращения к LLM:
+// если воркер ещё не подготовил аналог, ребёнок решает исходную задачу
+func (s *ReviewService) ensureTask(ctx context.Context, item *store.ReviewItem) error {
+	if item.HasReviewTask() {
+		return nil
+	}
+
+	set, err := s.store.SetReviewTask(ctx, item.ID, item.TaskText, store.ReviewTaskOriginal, item.ReviewsCount)
+	if err != nil {
+		return err
+	}
+	if !set {
+		// Воркер успел сохранить аналог — берём его
+		stored, err := s.store.GetReviewItem(ctx, item.ChildProfileID, item.ID)
+		if err != nil {
+			return err
+		}
+		item.ReviewTaskText, item.ReviewTaskSource, item.ReviewTaskFor = stored.ReviewTaskText, stored.ReviewTaskSource, stored.ReviewTaskFor
+		return nil
+	}
+	item.ReviewTaskText, item.ReviewTaskSource, item.ReviewTaskFor = item.TaskText, store.ReviewTaskOriginal, item.ReviewsCount
+	return nil
+}
+
+// PrepareTasks готовит задания для задач, у которых их ещё нет: аналог от LLM,
+// а если он недоступен — исходная задача. Возвращает число сохранённых заданий.
+func (s *ReviewService) PrepareTasks(ctx context.Context) (int, error) {
+	items, err := s.store.GetReviewItemsWithoutTask(ctx, reviewPrepareBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	prepared := 0
+	for i := range items {
+		item := &items[i]
+		text, source := item.TaskText, store.ReviewTaskOriginal
+		if analogue, err := s.generateAnalogue(ctx, item); err != nil {
+			log.Printf("[ReviewService] Analogue for review %d unavailable, using original task: %v", item.ID, err)
+		} else {
+			text, source = analogue, store.ReviewTaskAnalogue
+		}
+
+		set, err := s.store.SetReviewTask(ctx, item.ID, text, source, item.ReviewsCount)
+		if err != nil {
+			return prepared, err
+		}
+		if set {
+			prepared++
+		}
+	}
+	return prepared, nil
+}
+
+// RunTaskPreparation готовит задания после постановки в очередь и переноса,
+// а также каждые interval (после рестарта или сбоя LLM) до отмены ctx
+func (s *ReviewService) RunTaskPreparation(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		prepared, err := s.PrepareTasks(ctx)
+		if err != nil {
+			log.Printf("[ReviewService] Task preparation failed: %v", err)
+		}
+		if prepared > 0 {
+			log.Printf("[ReviewService] Prepared %d review tasks", prepared)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// wakeTaskPreparation будит воркер заданий, не дожидаясь его
+func (s *ReviewService) wakeTaskPreparation() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// generateAnalogue аналогичная задача тем же приёмом через ANALOGUE
+func (s *ReviewService) generateAnalogue(ctx context.Context, item *store.ReviewItem) (string, error) {
+	if s.llmClient == nil {
+		return "", fmt.Errorf("llm client is not configured")
+	}
+
+	req := types.AnalogueRequest{
+		TaskStruct: types.TaskStruct{
+			Subject:           analogueSubject(item.Subject),
+			CombinedSubpoints: true,
+		},
+		Reason:      types.AnalogueReason(item.Reason),
+		Locale:      "ru-RU",
+		RawTaskText: item.TaskText,
+		Grade:       int64(item.Grade),
+	}
+	if len(item.TaskStruct) > 0 {
+		var parseResp types.ParseResponse
+		if err := json.Unmarshal(item.TaskStruct, &parseResp); err == nil && len(parseResp.Items) > 0 {
+			req.TaskStruct.Type = parseResp.Items[0].PedKeys.TaskType
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reviewAnalogueTimeout)
+	defer cancel()
+	resp, err := s.llmClient.AnalogueSolution(ctx, s.defaultLLM, req)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(resp.ExampleTask) == "" {
+		return "", fmt.Errorf("analogue returned empty task")
+	}
+	return resp.ExampleTask, nil
+}
+
+// analogueSubject предмет в терминах ANALOGUE: math, russian или generic
+func analogueSubject(subject string) string {
+	switch types.Subject(subject) {
+	case types.SubjectMath:
+		return "math"
+	case types.SubjectRu:
+		return "russian"
+	default:
+		return "generic"
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/reward"
+	"child-bot/api/internal/store"
+)
+
+// Период аудита наград
+const (
+	RewardAuditDefaultDays = 14
+	RewardAuditMaxDays     = 90
+	rewardAuditEventsLimit = 500
+	rewardAuditRecentLimit = 50
+)
+
+// RewardService аудит политики наград: какие начисления урезаны и в какие дни это
+// похоже на «фарм» XP
+type RewardService struct {
+	store *store.Store
+	now   func() time.Time
+}
+
+// NewRewardService создает новый RewardService
+func NewRewardService(store *store.Store) *RewardService {
+	return &RewardService{store: store, now: time.Now}
+}
+
+// RewardAuditDay урезанные начисления одной причины за день ребёнка
+type RewardAuditDay struct {
+	Date          string
+	Reason        string
+	Events        int
+	WithheldXP    int
+	WithheldCoins int
+	Suspicious    bool
+}
+
+// RewardAudit урезанные начисления ребёнка за период
+type RewardAudit struct {
+	Days       int
+	Policy     reward.Policy
+	Summary    []RewardAuditDay          // по дням, новые первыми
+	Recent     []store.RewardPolicyEvent // последние урезанные начисления
+	Suspicious bool                      // есть подозрительные дни
+}
+
+// GetAudit сводка урезанных политикой начислений за последние days дней по календарю ребёнка
+func (s *RewardService) GetAudit(ctx context.Context, childProfileID string, days int) (*RewardAudit, error) {
+	loc := childLocation(ctx, s.store, childProfileID)
+	since := calendar.StartOfDay(s.now(), loc).AddDate(0, 0, -(days - 1))
+
+	events, err := s.store.ListRewardPolicyEvents(ctx, childProfileID, since, rewardAuditEventsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	audit := &RewardAudit{Days: days, Policy: s.store.Rewards}
+	byDay := make(map[[2]string]*RewardAuditDay)
+	for _, e := range events {
+		key := [2]string{calendar.DateKey(e.CreatedAt, loc), e.Reason}
+		day := byDay[key]
+		if day == nil {
+			day = &RewardAuditDay{Date: key[0], Reason: key[1]}
+			byDay[key] = day
+		}
+		day.Events++
+		if e.Currency == store.CurrencyXP {
+			day.WithheldXP += e.Requested - e.Granted
+		} else {
+			day.WithheldCoins += e.Requested - e.Granted
+		}
+	}
+	for _, day := range byDay {
+		day.Suspicious = audit.Policy.Suspicious(day.Events)
+		audit.Suspicious = audit.Suspicious || day.Suspicious
+		audit.Summary = append(audit.Summary, *day)
+	}
+	sort.Slice(audit.Summary, func(i, j int) bool {
+		a, b := audit.Summary[i], audit.Summary[j]
+		if a.Date != b.Date {
+			return a.Date > b.Date
+		}
+		return a.Reason < b.Reason
+	})
+
+	audit.Recent = events
+	if len(audit.Recent) > rewardAuditRecentLimit {
+		audit.Recent = audit.Recent[:rewardAuditRecentLimit]
+	}
+	return audit, nil
+}
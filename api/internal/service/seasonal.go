@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/seasonal"
+	"child-bot/api/internal/store"
+)
+
+// SeasonalService сезонные события: какие идут сегодня по календарю ребёнка
+// и как ребёнок продвинулся в их достижениях, злодеях и предметах
+type SeasonalService struct {
+	store *store.Store
+	now   func() time.Time
+}
+
+// NewSeasonalService создает новый SeasonalService
+func NewSeasonalService(store *store.Store) *SeasonalService {
+	return &SeasonalService{store: store, now: time.Now}
+}
+
+// EventAchievement достижение события с прогрессом
+type EventAchievement struct {
+	ID          string
+	Icon        string
+	Title       string
+	Description string
+	Progress    int
+	Target      int
+	Unlocked    bool
+	RewardType  string
+	RewardName  string
+}
+
+// EventVillain злодей события
+type EventVillain struct {
+	ID       string
+	Name     string
+	ImageURL string
+	Weekdays []int // 1 — понедельник, 7 — воскресенье
+	Defeated bool
+}
+
+// EventShopItem предмет события в магазине
+type EventShopItem struct {
+	ID       string
+	Category string
+	Name     string
+	Icon     string
+	Price    int
+	MinLevel int
+	Owned    bool
+}
+
+// SeasonalEvent событие, идущее сегодня, с прогрессом ребёнка
+type SeasonalEvent struct {
+	ID                   string
+	Title                string
+	Description          string
+	Icon                 string
+	ThemeColor           string
+	StartDate            string
+	EndDate              string
+	DaysLeft             int // включая сегодня
+	Achievements         []EventAchievement
+	AchievementsUnlocked int
+	Villains             []EventVillain
+	ShopItems            []EventShopItem
+}
+
+// CurrentEvents события ребёнка на сегодня
+type CurrentEvents struct {
+	Date   string // YYYY-MM-DD по календарю ребёнка
+	Events []SeasonalEvent
+}
+
+// GetCurrentEvents события, идущие сегодня, с прогрессом ребёнка; раньше заканчивающиеся — первыми
+func (s *SeasonalService) GetCurrentEvents(ctx context.Context, childProfileID string) (*CurrentEvents, error) {
+	date := calendar.DateKey(s.now(), childLocation(ctx, s.store, childProfileID))
+
+	open, err := s.store.GetOpenSeasonalEvents(ctx, date)
+	if err != nil {
+		return nil, fmt.Errorf("get open events: %w", err)
+	}
+
+	result := &CurrentEvents{Date: date, Events: make([]SeasonalEvent, 0, len(open))}
+	for _, e := range open {
+		progress, err := s.store.GetSeasonalEventProgress(ctx, childProfileID, e.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get progress of event %s: %w", e.ID, err)
+		}
+		result.Events = append(result.Events, toSeasonalEvent(e, progress, date))
+	}
+	return result, nil
+}
+
+func toSeasonalEvent(e store.SeasonalEvent, progress *store.SeasonalEventProgress, date string) SeasonalEvent {
+	event := SeasonalEvent{
+		ID:           e.ID,
+		Title:        e.Title,
+		Description:  e.Description,
+		Icon:         e.Icon,
+		ThemeColor:   e.ThemeColor,
+		StartDate:    e.StartDate,
+		EndDate:      e.EndDate,
+		DaysLeft:     seasonal.Event{StartDate: e.StartDate, EndDate: e.EndDate}.DaysLeft(date),
+		Achievements: make([]EventAchievement, 0, len(progress.Achievements)),
+		Villains:     make([]EventVillain, 0, len(progress.Villains)),
+		ShopItems:    make([]EventShopItem, 0, len(progress.ShopItems)),
+	}
+	for _, a := range progress.Achievements {
+		if a.IsUnlocked {
+			event.AchievementsUnlocked++
+		}
+		event.Achievements = append(event.Achievements, EventAchievement{
+			ID:          a.ID,
+			Icon:        a.Icon,
+			Title:       a.Title,
+			Description: a.Description,
+			Progress:    a.Progress,
+			Target:      a.Target,
+			Unlocked:    a.IsUnlocked,
+			RewardType:  a.RewardType,
+			RewardName:  a.RewardName,
+		})
+	}
+	for _, v := range progress.Villains {
+		event.Villains = append(event.Villains, EventVillain{
+			ID:       v.ID,
+			Name:     v.Name,
+			ImageURL: v.ImageURL,
+			Weekdays: v.Weekdays,
+			Defeated: v.IsDefeated,
+		})
+	}
+	for _, it := range progress.ShopItems {
+		event.ShopItems = append(event.ShopItems, EventShopItem{
+			ID:       it.ID,
+			Category: it.Category,
+			Name:     it.Name,
+			Icon:     it.Icon,
+			Price:    it.Price,
+			MinLevel: it.MinLevel,
+			Owned:    it.Owned,
+		})
+	}
+	return event
+}
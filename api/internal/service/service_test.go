@@ -3,12 +3,16 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
+	"child-bot/api/internal/llm"
 	"child-bot/api/internal/store"
 )
 
@@ -109,7 +113,8 @@ func createTestAttempt(t *testing.T, db *sql.DB, childProfileID, attemptType str
 
 // Mock LLM Client for testing
 
-// mockLLMClient is a mock LLM client that returns predefined responses
+// mockLLMClient answers LLM server requests with predefined responses.
+// AttemptService takes a concrete *llm.Client, so the mock is served over HTTP (see client).
 type mockLLMClient struct {
 	detectFunc func(ctx context.Context, llmName string, req interface{}) (interface{}, error)
 	parseFunc  func(ctx context.Context, llmName string, req interface{}) (interface{}, error)
@@ -117,18 +122,53 @@ type mockLLMClient struct {
 	checkFunc  func(ctx context.Context, llmName string, req interface{}) (interface{}, error)
 }
 
+// client starts a test LLM server backed by the mock and returns an llm.Client pointing to it
+func (m *mockLLMClient) client(tb testing.TB) *llm.Client {
+	tb.Helper()
+
+	mux := http.NewServeMux()
+	handle := func(path string, fn func(ctx context.Context, llmName string, req interface{}) (interface{}, error)) {
+		mux.HandleFunc("POST "+path, func(w http.ResponseWriter, r *http.Request) {
+			var req map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			llmName, _ := req["llm_name"].(string)
+
+			resp, err := fn(r.Context(), llmName, req)
+			w.Header().Set("Content-Type", "application/json")
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+			json.NewEncoder(w).Encode(resp)
+		})
+	}
+	handle("/v2/detect", m.Detect)
+	handle("/v2/parse", m.Parse)
+	handle("/v2/hint", m.Hint)
+	handle("/v2/check_solution", m.CheckSolution)
+
+	server := httptest.NewServer(mux)
+	tb.Cleanup(server.Close)
+	return llm.NewClient(server.URL)
+}
+
 func (m *mockLLMClient) Detect(ctx context.Context, llmName string, req interface{}) (interface{}, error) {
 	if m.detectFunc != nil {
 		return m.detectFunc(ctx, llmName, req)
 	}
 	// Return default mock response
 	return map[string]interface{}{
+		"schema_version": "1.0",
 		"classification": map[string]interface{}{
 			"subject_candidate": "math",
 			"confidence":        0.95,
 		},
 		"quality": map[string]interface{}{
-			"is_acceptable": true,
+			"recommend_retake": false,
 		},
 	}, nil
 }
@@ -140,11 +180,14 @@ func (m *mockLLMClient) Parse(ctx context.Context, llmName string, req interface
 	// Return default mock response
 	return map[string]interface{}{
 		"task": map[string]interface{}{
-			"text": "Solve 2+2",
+			"subject":         "math",
+			"grade":           2,
+			"task_text_clean": "Solve 2+2",
 		},
 		"items": []interface{}{
 			map[string]interface{}{
-				"text": "2+2=?",
+				"item_id":         "item1",
+				"item_text_clean": "2+2=?",
 			},
 		},
 	}, nil
@@ -156,14 +199,13 @@ func (m *mockLLMClient) Hint(ctx context.Context, llmName string, req interface{
 	}
 	// Return default mock response
 	return map[string]interface{}{
-		"hints": []interface{}{
-			map[string]interface{}{
-				"text":  "First hint",
-				"level": 1,
-			},
+		"items": []interface{}{
 			map[string]interface{}{
-				"text":  "Second hint",
-				"level": 2,
+				"item_id": "item1",
+				"hints": []interface{}{
+					map[string]interface{}{"level": "L1", "hint_text": "First hint"},
+					map[string]interface{}{"level": "L2", "hint_text": "Second hint"},
+				},
 			},
 		},
 	}, nil
@@ -175,7 +217,9 @@ func (m *mockLLMClient) CheckSolution(ctx context.Context, llmName string, req i
 	}
 	// Return default mock response
 	return map[string]interface{}{
-		"is_correct": true,
-		"feedback":   "Correct answer!",
+		"status":       "evaluated",
+		"can_evaluate": true,
+		"decision":     "correct",
+		"feedback":     "Correct answer!",
 	}, nil
 }
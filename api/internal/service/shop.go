@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// MaxPurchaseQuantity сколько расходуемых предметов можно купить за раз
+const MaxPurchaseQuantity = 10
+
+// ShopService бизнес-логика магазина за монеты
+type ShopService struct {
+	store *store.Store
+}
+
+// NewShopService создает новый ShopService
+func NewShopService(store *store.Store) *ShopService {
+	return &ShopService{store: store}
+}
+
+// ShopItem предмет магазина с учётом прогресса ребёнка
+type ShopItem struct {
+	ID          string
+	Category    string // avatar, mascot_item, power_up
+	Name        string
+	Description string
+	Icon        string
+	Price       int
+	BasePrice   int // цена без скидки уровня
+	Discount    int // скидка уровня на усилители, %
+	MinLevel    int
+	IsPremium   bool // платный предмет
+	IsStackable bool
+	Effect      json.RawMessage
+	Owned       bool
+	Quantity    int
+	Locked      bool // уровень ребёнка ниже требуемого
+	Affordable  bool
+}
+
+// ShopCatalog каталог магазина и баланс ребёнка
+type ShopCatalog struct {
+	Items        []ShopItem
+	CoinsBalance int
+	Level        int
+	Discount     int // скидка уровня на усилители, %
+}
+
+// ShopPurchase результат покупки
+type ShopPurchase struct {
+	Item         ShopItem
+	Quantity     int
+	TotalPrice   int
+	OwnedQty     int
+	CoinsBalance int
+	Replayed     bool
+}
+
+// GetCatalog возвращает каталог (опционально одной категории).
+// Без childProfileID — только каталог, без владения и баланса.
+func (s *ShopService) GetCatalog(ctx context.Context, childProfileID, category string) (*ShopCatalog, error) {
+	if category != "" && !isShopCategory(category) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	catalog := &ShopCatalog{Level: 1}
+	if childProfileID != "" {
+		query := `SELECT COALESCE(coins_balance, 0), COALESCE(level, 1) FROM child_profiles WHERE id = $1`
+		if err := s.store.DB.QueryRowContext(ctx, query, childProfileID).Scan(&catalog.CoinsBalance, &catalog.Level); err != nil {
+			return nil, fmt.Errorf("failed to get profile balance: %w", err)
+		}
+	}
+
+	rows, err := s.store.ListShopItems(ctx, childProfileID, category)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog.Discount = s.store.XP.Levels.ShopDiscount(catalog.Level)
+	catalog.Items = make([]ShopItem, 0, len(rows))
+	for _, row := range rows {
+		item := toShopItem(row.ShopItem)
+		item.Owned = row.Owned
+		item.Quantity = row.Quantity
+		if childProfileID != "" {
+			item.Price = s.store.XP.ShopPrice(row.ShopItem, catalog.Level)
+			if item.Price < item.BasePrice {
+				item.Discount = catalog.Discount
+			}
+			item.Locked = catalog.Level < row.MinLevel
+			item.Affordable = catalog.CoinsBalance >= item.Price
+		}
+		catalog.Items = append(catalog.Items, item)
+	}
+
+	return catalog, nil
+}
+
+// GetInventory возвращает купленные ребёнком предметы
+func (s *ShopService) GetInventory(ctx context.Context, childProfileID string) ([]ShopItem, error) {
+	rows, err := s.store.GetInventory(ctx, childProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ShopItem, 0, len(rows))
+	for _, row := range rows {
+		item := toShopItem(row.ShopItem)
+		item.Owned = true
+		item.Quantity = row.Quantity
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Purchase покупает предмет за монеты. idempotencyKey защищает от повторного списания
+// при повторе запроса; если он не передан, каждая покупка уникальна.
+func (s *ShopService) Purchase(ctx context.Context, childProfileID, itemID string, quantity int, idempotencyKey string) (*ShopPurchase, error) {
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 || quantity > MaxPurchaseQuantity {
+		return nil, domain.ErrInvalidInput
+	}
+	if idempotencyKey == "" {
+		idempotencyKey = uuid.NewString()
+	}
+
+	p, err := s.store.PurchaseShopItem(ctx, childProfileID, itemID, quantity, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[ShopService] Child %s bought %dx %s for %d coins (replayed=%v)",
+		childProfileID, p.Quantity, p.Item.ID, p.TotalPrice, p.Replayed)
+
+	item := toShopItem(p.Item)
+	item.Owned = p.OwnedQty > 0
+	item.Quantity = p.OwnedQty
+
+	return &ShopPurchase{
+		Item:         item,
+		Quantity:     p.Quantity,
+		TotalPrice:   p.TotalPrice,
+		OwnedQty:     p.OwnedQty,
+		CoinsBalance: p.CoinsBalance,
+		Replayed:     p.Replayed,
+	}, nil
+}
+
+// toShopItem преобразует предмет каталога из store
+func toShopItem(row store.ShopItem) ShopItem {
+	return ShopItem{
+		ID:          row.ID,
+		Category:    row.Category,
+		Name:        row.Name,
+		Description: row.Description,
+		Icon:        row.Icon,
+		Price:       row.Price,
+		BasePrice:   row.Price,
+		MinLevel:    row.MinLevel,
+		IsPremium:   row.Price > 0,
+		IsStackable: row.IsStackable,
+		Effect:      row.Effect,
+	}
+}
+
+// isShopCategory проверяет категорию каталога
+func isShopCategory(category string) bool {
+	switch category {
+	case store.ShopCategoryAvatar, store.ShopCategoryMascotItem, store.ShopCategoryPowerUp:
+		return true
+	}
+	return false
+}
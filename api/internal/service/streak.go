@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/store"
+)
+
+const (
+	// DefaultStreakCalendarDays сколько дней показывать в календаре серии
+	DefaultStreakCalendarDays = 30
+	// MaxStreakCalendarDays максимальная глубина календаря
+	MaxStreakCalendarDays = 120
+	// MaxExcuseDaysBack на сколько дней назад родитель может простить пропуск
+	MaxExcuseDaysBack = store.MaxExcuseDaysBack
+	// maxExcuseReasonLength длина причины, которую указывает родитель
+	maxExcuseReasonLength = 200
+)
+
+// StreakService серия дней: календарь, заморозки и прощённые родителем дни
+type StreakService struct {
+	store  *store.Store
+	events *EventBus
+	now    func() time.Time
+}
+
+// NewStreakService создает новый StreakService
+func NewStreakService(store *store.Store) *StreakService {
+	return &StreakService{store: store, now: time.Now}
+}
+
+// SetEventBus устанавливает шину доменных событий
+func (s *StreakService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// StreakInfo текущая серия и календарь за последние дни
+type StreakInfo struct {
+	StreakDays  int
+	Freezes     int
+	Recoverable int // длина оборванной серии, которую вернёт прощение пропущенных дней
+	Today       string
+	Days        []store.StreakDay
+}
+
+// GetStreak возвращает серию ребёнка и календарь active/frozen/excused/missed за days дней
+func (s *StreakService) GetStreak(ctx context.Context, childProfileID string, days int) (*StreakInfo, error) {
+	if days <= 0 {
+		days = DefaultStreakCalendarDays
+	}
+	if days > MaxStreakCalendarDays {
+		return nil, domain.ErrInvalidInput
+	}
+
+	loc := childLocation(ctx, s.store, childProfileID)
+	now := s.now().In(loc)
+	today := calendar.DateKey(now, loc)
+	from := calendar.DateKey(calendar.StartOfDay(now, loc).AddDate(0, 0, 1-days), loc)
+
+	history, err := s.store.GetStreakHistory(ctx, childProfileID, from, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streak history: %w", err)
+	}
+
+	calendarDays, err := store.StreakCalendar(from, today, today, history.FirstDay, history.Days)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build streak calendar: %w", err)
+	}
+
+	return &StreakInfo{
+		StreakDays:  history.Streak,
+		Freezes:     history.Freezes,
+		Recoverable: history.BrokenValue,
+		Today:       today,
+		Days:        calendarDays,
+	}, nil
+}
+
+// ExcuseDay прощает пропуск по решению родителя (болезнь, поездка); нужна родительская сессия.
+// Если прощение закрыло пропуск, оборвавший серию, серия восстанавливается.
+func (s *StreakService) ExcuseDay(ctx context.Context, childProfileID, date, reason, parentSession string) (*store.StreakExcuse, error) {
+	reason = strings.TrimSpace(reason)
+	if date == "" || len([]rune(reason)) > maxExcuseReasonLength {
+		return nil, domain.ErrInvalidInput
+	}
+	grantedBy, err := parentFromSession(ctx, s.store, childProfileID, parentSession)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := childLocation(ctx, s.store, childProfileID)
+	excuse, err := s.store.ExcuseStreakDay(ctx, childProfileID, date, reason, grantedBy, s.now(), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if excuse.Recovered {
+		log.Printf("[StreakService] Streak recovered for %s after excused day %s: streak=%d", childProfileID, excuse.Date, excuse.Streak)
+		s.events.Publish(ctx, domain.StreakUpdated{
+			ChildProfileID: childProfileID,
+			StreakDays:     excuse.Streak,
+		})
+	}
+
+	return excuse, nil
+}
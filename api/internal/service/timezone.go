@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/store"
+)
+
+// childLocation часовой пояс ребёнка для расчёта дней и недель.
+// Ошибка чтения не должна ломать игру, поэтому откатываемся на пояс по умолчанию.
+func childLocation(ctx context.Context, st *store.Store, childProfileID string) *time.Location {
+	tz, err := st.GetChildTimezone(ctx, childProfileID)
+	if err != nil {
+		log.Printf("[Calendar] Failed to get timezone for %s, using %s: %v", childProfileID, calendar.DefaultTimezone, err)
+	}
+	return calendar.Location(tz)
+}
@@ -2,10 +2,15 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
 	"time"
 
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/damage"
+	"child-bot/api/internal/domain"
 	"child-bot/api/internal/store"
 
 	"github.com/google/uuid"
@@ -13,8 +18,8 @@ import (
 
 // VillainService бизнес-логика для злодеев
 type VillainService struct {
-	store              *store.Store
-	achievementService *AchievementService
+	store  *store.Store
+	events *EventBus
 }
 
 // NewVillainService создает новый VillainService
@@ -22,9 +27,9 @@ func NewVillainService(store *store.Store) *VillainService {
 	return &VillainService{store: store}
 }
 
-// SetAchievementService устанавливает AchievementService (для избежания циклических зависимостей)
-func (s *VillainService) SetAchievementService(achievementService *AchievementService) {
-	s.achievementService = achievementService
+// SetEventBus устанавливает шину доменных событий
+func (s *VillainService) SetEventBus(events *EventBus) {
+	s.events = events
 }
 
 // Villain злодей
@@ -61,10 +66,13 @@ type BattleStats struct {
 
 // DamageEvent урон злодею
 type DamageEvent struct {
-	ID        string
-	Damage    int
-	TaskType  string // help, check
-	CreatedAt time.Time
+	ID         string
+	Damage     int
+	BaseDamage int
+	IsCritical bool
+	Bonuses    []damage.Bonus
+	TaskType   string // help, check
+	CreatedAt  time.Time
 }
 
 // VictoryData данные победы
@@ -142,12 +150,10 @@ func (s *VillainService) GetActiveVillain(ctx context.Context, childProfileID st
 		return nil, err
 	}
 
-	// Определяем какой злодей должен быть сегодня
-	today := time.Now().UTC()
-	dayOfWeek := int(today.Weekday()) // Sunday=0, Monday=1, ..., Saturday=6
-	if dayOfWeek == 0 {
-		dayOfWeek = 7 // Sunday = 7
-	}
+	// Определяем какой злодей должен быть сегодня (по календарю ребёнка)
+	loc := childLocation(ctx, s.store, childProfileID)
+	today := time.Now().In(loc)
+	dayOfWeek := calendar.ISOWeekday(today, loc) // 1=понедельник, 7=воскресенье
 
 	// Если нет активной битвы ИЛИ злодей не соответствует дню недели
 	shouldCreateNew := false
@@ -170,7 +176,7 @@ func (s *VillainService) GetActiveVillain(ctx context.Context, childProfileID st
 
 	if shouldCreateNew {
 		// Проверяем, был ли злодей побеждён сегодня (тогда не создаём нового)
-		defeatedToday, err := s.wasDefeatedToday(ctx, childProfileID)
+		defeatedToday, err := s.wasDefeatedToday(ctx, childProfileID, loc)
 		if err != nil {
 			log.Printf("[VillainService] Failed to check if defeated today: %v", err)
 		}
@@ -183,7 +189,7 @@ func (s *VillainService) GetActiveVillain(ctx context.Context, childProfileID st
 		// Создаём нового злодея на сегодня
 		log.Printf("[VillainService] Creating new villain for day %d (%s) for %s",
 			dayOfWeek, today.Weekday().String(), childProfileID)
-		err = s.ensureDailyVillain(ctx, childProfileID)
+		err = s.ensureDailyVillain(ctx, childProfileID, loc)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create daily villain: %w", err)
 		}
@@ -195,12 +201,9 @@ func (s *VillainService) GetActiveVillain(ctx context.Context, childProfileID st
 	}
 
 	// Проверяем, что битва начата сегодня (для сброса HP)
-	battleDate := battle.StartedAt.Truncate(24 * time.Hour)
-	todayDate := today.Truncate(24 * time.Hour)
-
-	if battleDate.Before(todayDate) {
+	if calendar.DaysBetween(battle.StartedAt, today, loc) > 0 {
 		// Битва начата не сегодня - сбрасываем HP
-		log.Printf("[VillainService] Battle from %s, resetting HP for today", battle.StartedAt.Format("2006-01-02"))
+		log.Printf("[VillainService] Battle from %s, resetting HP for today", calendar.DateKey(battle.StartedAt, loc))
 
 		if battle.Status == "active" {
 			err = s.store.Villains.ResetBattleHP(ctx, battle.ID, villainRow.MaxHP)
@@ -232,8 +235,8 @@ func (s *VillainService) GetActiveVillain(ctx context.Context, childProfileID st
 	return villain, nil
 }
 
-// wasDefeatedToday проверяет, был ли злодей побеждён сегодня
-func (s *VillainService) wasDefeatedToday(ctx context.Context, childProfileID string) (bool, error) {
+// wasDefeatedToday проверяет, был ли злодей побеждён сегодня по местному календарю
+func (s *VillainService) wasDefeatedToday(ctx context.Context, childProfileID string, loc *time.Location) (bool, error) {
 	defeatedAt, err := s.store.Villains.GetLastDefeatedAt(ctx, childProfileID)
 	if err != nil {
 		return false, err
@@ -244,14 +247,11 @@ func (s *VillainService) wasDefeatedToday(ctx context.Context, childProfileID st
 	}
 
 	// Проверяем что победа была сегодня
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-	battleDate := defeatedAt.Truncate(24 * time.Hour)
-
-	return battleDate.Equal(today), nil
+	return calendar.SameDay(*defeatedAt, time.Now(), loc), nil
 }
 
 // ensureDailyVillain создаёт злодея на сегодня если ещё нет
-func (s *VillainService) ensureDailyVillain(ctx context.Context, childProfileID string) error {
+func (s *VillainService) ensureDailyVillain(ctx context.Context, childProfileID string, loc *time.Location) error {
 	// Проверяем, нет ли уже активного злодея
 	battle, _, err := s.store.Villains.GetActiveVillainBattle(ctx, childProfileID)
 	if err != nil {
@@ -263,17 +263,22 @@ func (s *VillainService) ensureDailyVillain(ctx context.Context, childProfileID
 		return nil
 	}
 
-	// Определяем день недели (1=понедельник, 7=воскресенье)
-	today := time.Now().UTC()
-	dayOfWeek := int(today.Weekday()) // Sunday=0, Monday=1, ..., Saturday=6
-	if dayOfWeek == 0 {
-		dayOfWeek = 7 // Sunday = 7
-	}
+	// Определяем день недели (1=понедельник, 7=воскресенье) по местному времени ребёнка
+	today := time.Now().In(loc)
+	dayOfWeek := calendar.ISOWeekday(today, loc)
 
-	// Получаем злодея для этого дня недели (unlock_order = day_of_week)
-	villain, err := s.store.Villains.GetVillainByOrder(ctx, dayOfWeek)
+	// Во время сезонного события в его дни недели приходит злодей события
+	villain, err := s.store.Villains.GetEventVillainForDay(ctx, childProfileID, calendar.DateKey(today, loc), dayOfWeek)
 	if err != nil {
-		return fmt.Errorf("failed to get villain for day %d: %w", dayOfWeek, err)
+		return fmt.Errorf("failed to get event villain for day %d: %w", dayOfWeek, err)
+	}
+
+	// Иначе злодей этого дня недели (unlock_order = day_of_week)
+	if villain == nil {
+		villain, err = s.store.Villains.GetVillainByOrder(ctx, dayOfWeek)
+		if err != nil {
+			return fmt.Errorf("failed to get villain for day %d: %w", dayOfWeek, err)
+		}
 	}
 
 	if villain == nil {
@@ -296,8 +301,7 @@ func (s *VillainService) ensureDailyVillain(ctx context.Context, childProfileID
 	if villain.IsBoss {
 		lastBossDate, err := s.store.Villains.GetLastBossDefeatedAt(ctx, childProfileID)
 		if err == nil && lastBossDate != nil {
-			daysSinceLastBoss := time.Since(*lastBossDate).Hours() / 24
-			if daysSinceLastBoss < 7 {
+			if calendar.DaysBetween(*lastBossDate, today, loc) < 7 {
 				// Берём любого не-босса
 				for i := 1; i <= 7; i++ {
 					v, err := s.store.Villains.GetVillainByOrder(ctx, i)
@@ -321,9 +325,11 @@ func (s *VillainService) ensureDailyVillain(ctx context.Context, childProfileID
 	return nil
 }
 
-// DealDamageToVillain наносит урон активному злодею и проверяет победу
+// DealDamageToVillain наносит урон активному злодею и проверяет победу.
+// Урон считается по формуле злодея из атрибутов задачи (см. damage.Calculate);
+// powerUpID — усилитель, который ребёнок выбрал для этой проверки (пусто — без усилителя).
 // Возвращает: (defeated bool, coinsEarned int, error)
-func (s *VillainService) DealDamageToVillain(ctx context.Context, childProfileID string, attemptID uuid.UUID, taskType string) (bool, int, error) {
+func (s *VillainService) DealDamageToVillain(ctx context.Context, childProfileID string, attemptID uuid.UUID, taskType string, task damage.Task, powerUpID string) (bool, int, error) {
 	// Получаем активную битву
 	battle, villainRow, err := s.store.Villains.GetActiveVillainBattle(ctx, childProfileID)
 	if err != nil {
@@ -332,7 +338,8 @@ func (s *VillainService) DealDamageToVillain(ctx context.Context, childProfileID
 
 	if battle == nil || villainRow == nil {
 		// Нет активной битвы - проверяем, был ли побеждён сегодня
-		defeatedToday, checkErr := s.wasDefeatedToday(ctx, childProfileID)
+		loc := childLocation(ctx, s.store, childProfileID)
+		defeatedToday, checkErr := s.wasDefeatedToday(ctx, childProfileID, loc)
 		if checkErr != nil {
 			log.Printf("[VillainService] Failed to check defeat status: %v", checkErr)
 		}
@@ -345,7 +352,7 @@ func (s *VillainService) DealDamageToVillain(ctx context.Context, childProfileID
 
 		// Создаём нового злодея
 		log.Printf("[VillainService] No active battle for %s, creating new villain", childProfileID)
-		err = s.ensureDailyVillain(ctx, childProfileID)
+		err = s.ensureDailyVillain(ctx, childProfileID, loc)
 		if err != nil {
 			return false, 0, fmt.Errorf("failed to create villain: %w", err)
 		}
@@ -361,27 +368,18 @@ func (s *VillainService) DealDamageToVillain(ctx context.Context, childProfileID
 		return false, 0, fmt.Errorf("battle is not active")
 	}
 
-	// Вычисляем урон
-	damage := villainRow.DamagePerCorrectTask
-	log.Printf("[VillainService] Dealing %d damage to villain %s (current HP: %d/%d)",
-		damage, villainRow.ID, battle.CurrentHP, villainRow.MaxHP)
-
-	// Записываем событие урона
-	err = s.store.Villains.RecordDamageEvent(ctx, battle.ID, attemptID, damage, taskType)
+	// Вычисляем урон по формуле злодея
+	formula, err := damage.ParseFormula(villainRow.DamageFormula, villainRow.DamagePerCorrectTask)
 	if err != nil {
-		log.Printf("[VillainService] Failed to record damage event: %v", err)
-	}
-
-	// Обновляем HP и счётчики битвы
-	newHP := battle.CurrentHP - damage
-	if newHP < 0 {
-		newHP = 0
+		log.Printf("[VillainService] Invalid damage formula for villain %s, using default: %v", villainRow.ID, err)
 	}
-
-	err = s.store.Villains.UpdateBattleProgress(ctx, battle.ID, newHP, damage)
+	// Усилитель, событие урона и HP — одной транзакцией: при ошибке усилитель не пропадает
+	hit, newHP, err := s.store.Villains.ApplyDamage(ctx, childProfileID, battle.ID, attemptID, taskType, formula, task, powerUpID)
 	if err != nil {
-		return false, 0, fmt.Errorf("failed to update battle progress: %w", err)
+		return false, 0, fmt.Errorf("failed to apply damage: %w", err)
 	}
+	log.Printf("[VillainService] Dealt %d damage (base %d, critical %v, power-up %q) to villain %s (HP: %d -> %d/%d)",
+		hit.Total, hit.Base, hit.IsCritical, powerUpID, villainRow.ID, battle.CurrentHP, newHP, villainRow.MaxHP)
 
 	// Проверяем победу
 	defeated := newHP <= 0
@@ -396,24 +394,45 @@ func (s *VillainService) DealDamageToVillain(ctx context.Context, childProfileID
 			log.Printf("[VillainService] Failed to mark battle as defeated: %v", err)
 		}
 
-		// Начисляем монеты за победу
+		// Начисляем монеты и XP за победу, сообщаем о ней
 		coinsEarned = villainRow.RewardCoins
-		// Монеты начислит вызывающий код
+		s.awardVictory(ctx, childProfileID, battle.ID, villainRow)
 
 		// Не создаём следующего злодея до завтра
 		log.Printf("[VillainService] Villain defeated for today, next villain will spawn tomorrow for %s", childProfileID)
+	}
 
-		// Проверяем достижения за побеждённых монстров
-		if s.achievementService != nil {
-			err = s.achievementService.CheckVillainAchievements(ctx, childProfileID)
-			if err != nil {
-				log.Printf("[VillainService] Failed to check villain achievements: %v", err)
-				// Не блокируем, продолжаем
-			}
+	return defeated, coinsEarned, nil
+}
+
+// awardVictory начисляет монеты и XP за победу над злодеем через журнал и публикует VillainDefeated.
+// Ключ — id битвы, поэтому повторная обработка победы ничего не начисляет.
+func (s *VillainService) awardVictory(ctx context.Context, childProfileID string, battleID int64, villainRow *store.VillainRow) {
+	src := store.WalletSource{
+		Type:        store.WalletSourceVillainBattle,
+		ID:          strconv.FormatInt(battleID, 10),
+		Description: villainRow.Name,
+	}
+
+	if villainRow.RewardCoins > 0 {
+		if _, err := s.store.AddCoins(ctx, childProfileID, villainRow.RewardCoins, src); err != nil {
+			log.Printf("[VillainService] Failed to add victory coins for child %s: %v", childProfileID, err)
 		}
 	}
 
-	return defeated, coinsEarned, nil
+	level, leveledUp, err := s.store.AddXP(ctx, childProfileID, XPForVillainDefeat, s.store.XP, src)
+	if err != nil {
+		log.Printf("[VillainService] Failed to award villain defeat XP for %s: %v", childProfileID, err)
+	} else if leveledUp {
+		log.Printf("[VillainService] 🎉 Level up from villain defeat! child=%s, level=%d", childProfileID, level)
+	}
+
+	// Достижения за побеждённых монстров считает подписчик
+	s.events.Publish(ctx, domain.VillainDefeated{
+		ChildProfileID: childProfileID,
+		BattleID:       battleID,
+		VillainID:      villainRow.ID,
+	})
 }
 
 // ensureActiveVillain создаёт первого монстра если нет активного
@@ -563,14 +582,29 @@ func (s *VillainService) GetVillainBattle(ctx context.Context, childProfileID, v
 	// Конвертируем в доменные объекты
 	recentDamage := make([]DamageEvent, 0, len(damageEvents))
 	for _, event := range damageEvents {
+		var bonuses []damage.Bonus
+		if len(event.Bonuses) > 0 {
+			if err := json.Unmarshal(event.Bonuses, &bonuses); err != nil {
+				log.Printf("[VillainService] Failed to parse damage bonuses for event %d: %v", event.ID, err)
+			}
+		}
 		recentDamage = append(recentDamage, DamageEvent{
-			ID:        string(rune(event.ID)),
-			Damage:    event.Damage,
-			TaskType:  event.TaskType,
-			CreatedAt: event.CreatedAt,
+			ID:         string(rune(event.ID)),
+			Damage:     event.Damage,
+			BaseDamage: event.BaseDamage,
+			IsCritical: event.IsCritical,
+			Bonuses:    bonuses,
+			TaskType:   event.TaskType,
+			CreatedAt:  event.CreatedAt,
 		})
 	}
 
+	// Базовый урон за задачу по формуле злодея
+	formula, err := damage.ParseFormula(villainRow.DamageFormula, villainRow.DamagePerCorrectTask)
+	if err != nil {
+		log.Printf("[VillainService] Invalid damage formula for villain %s, using default: %v", villainRow.ID, err)
+	}
+
 	// Вычисляем прогресс
 	progressPercent := 0.0
 	if villainRow.MaxHP > 0 {
@@ -582,7 +616,7 @@ func (s *VillainService) GetVillainBattle(ctx context.Context, childProfileID, v
 		BattleStats: BattleStats{
 			TotalDamageDealt:  battleRow.TotalDamageDealt,
 			CorrectTasksCount: battleRow.CorrectTasksCount,
-			DamagePerTask:     villainRow.DamagePerCorrectTask,
+			DamagePerTask:     formula.Base,
 			ProgressPercent:   progressPercent,
 		},
 		RecentDamage: recentDamage,
@@ -593,7 +627,7 @@ func (s *VillainService) GetVillainBattle(ctx context.Context, childProfileID, v
 }
 
 // DealDamageToVillain наносит урон злодею и проверяет победу
-func (s *VillainService) DealDamage(ctx context.Context, childProfileID, villainID string, attemptID string, damage int) (*DamageResult, error) {
+func (s *VillainService) DealDamage(ctx context.Context, childProfileID, villainID string, attemptID string, amount int) (*DamageResult, error) {
 	// Получаем битву
 	battle, villainRow, err := s.store.Villains.GetActiveVillainBattle(ctx, childProfileID)
 	if err != nil {
@@ -610,7 +644,7 @@ func (s *VillainService) DealDamage(ctx context.Context, childProfileID, villain
 	}
 
 	// Обновляем HP
-	newHP := battle.CurrentHP - damage
+	newHP := battle.CurrentHP - amount
 	if newHP < 0 {
 		newHP = 0
 	}
@@ -621,13 +655,13 @@ func (s *VillainService) DealDamage(ctx context.Context, childProfileID, villain
 		return nil, fmt.Errorf("invalid attempt ID: %w", err)
 	}
 
-	err = s.store.Villains.RecordDamageEvent(ctx, battle.ID, attemptUUID, damage, "manual")
+	err = s.store.Villains.RecordDamageEvent(ctx, battle.ID, attemptUUID, "manual", damage.Fixed(amount))
 	if err != nil {
 		log.Printf("[VillainService] Failed to record damage event: %v", err)
 	}
 
 	// Обновляем прогресс
-	err = s.store.Villains.UpdateBattleProgress(ctx, battle.ID, newHP, damage)
+	err = s.store.Villains.UpdateBattleProgress(ctx, battle.ID, newHP, amount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update battle progress: %w", err)
 	}
@@ -644,24 +678,19 @@ func (s *VillainService) DealDamage(ctx context.Context, childProfileID, villain
 			log.Printf("[VillainService] Failed to mark battle as defeated: %v", err)
 		}
 
+		// Начисляем монеты и XP за победу, сообщаем о ней
+		s.awardVictory(ctx, childProfileID, battle.ID, villainRow)
+
 		// Создаём следующего монстра
 		err = s.createNextVillain(ctx, childProfileID, villainRow.UnlockOrder)
 		if err != nil {
 			log.Printf("[VillainService] Failed to create next villain: %v", err)
 		}
-
-		// Проверяем достижения
-		if s.achievementService != nil {
-			err = s.achievementService.CheckVillainAchievements(ctx, childProfileID)
-			if err != nil {
-				log.Printf("[VillainService] Failed to check villain achievements: %v", err)
-			}
-		}
 	}
 
 	// Формируем результат
 	result := &DamageResult{
-		DamageDealt:  damage,
+		DamageDealt:  amount,
 		VillainHP:    newHP,
 		VillainMaxHP: villainRow.MaxHP,
 		IsDefeated:   defeated,
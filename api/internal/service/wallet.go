@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/store"
+)
+
+// WalletTransaction запись журнала монет и XP для родителя
+type WalletTransaction struct {
+	ID           int64
+	Currency     string // coins, xp
+	Amount       int    // > 0 начисление, < 0 списание
+	BalanceAfter int
+	Source       string // attempt, hint, achievement, villain_battle, level_up, ...
+	SourceID     string
+	Description  string
+	CreatedAt    time.Time
+}
+
+// WalletTransactions страница журнала
+type WalletTransactions struct {
+	Transactions []WalletTransaction
+	Total        int
+	CoinsBalance int
+	XPTotal      int
+}
+
+// GetTransactions возвращает журнал начислений и списаний ребёнка (новые первыми)
+func (s *ProfileService) GetTransactions(ctx context.Context, childProfileID, currency string, limit, offset int) (*WalletTransactions, error) {
+	if currency != "" && currency != store.CurrencyCoins && currency != store.CurrencyXP {
+		return nil, domain.ErrInvalidInput
+	}
+
+	rows, total, err := s.store.ListWalletTransactions(ctx, childProfileID, currency, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	result := &WalletTransactions{
+		Transactions: make([]WalletTransaction, 0, len(rows)),
+		Total:        total,
+	}
+
+	query := `SELECT COALESCE(coins_balance, 0), COALESCE(xp_total, 0) FROM child_profiles WHERE id = $1`
+	if err := s.store.DB.QueryRowContext(ctx, query, childProfileID).Scan(&result.CoinsBalance, &result.XPTotal); err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", err)
+	}
+
+	for _, row := range rows {
+		result.Transactions = append(result.Transactions, WalletTransaction{
+			ID:           row.ID,
+			Currency:     row.Currency,
+			Amount:       row.Amount,
+			BalanceAfter: row.BalanceAfter,
+			Source:       row.Source,
+			SourceID:     row.SourceID.String,
+			Description:  row.Description.String,
+			CreatedAt:    row.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
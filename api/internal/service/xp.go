@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"time"
 
 	"child-bot/api/internal/store"
 )
@@ -17,54 +19,75 @@ const (
 	// XPForAchievement declared in achievement.go to avoid circular dependency
 )
 
-// AwardCorrectAnswer начисляет XP за правильное решение
-func (s *ProfileService) AwardCorrectAnswer(ctx context.Context, childProfileID string) error {
-	level, leveledUp, err := s.store.AddXP(ctx, childProfileID, XPForCorrectAnswer, store.DefaultXPConfig)
-	if err != nil {
-		log.Printf("[ProfileService] Failed to award correct answer XP for %s: %v", childProfileID, err)
-		return err
-	}
-
-	if leveledUp {
-		log.Printf("[ProfileService] 🎉 Level up from correct answer! child=%s, level=%d", childProfileID, level)
-	}
-
-	return nil
+// CoinsForCorrectAnswer монеты за правильное решение задачи
+const CoinsForCorrectAnswer = 5
+
+// AwardCorrectAnswer начисляет XP за правильное решение (один раз на попытку).
+// Награда за повтор той же задачи убывает, дневной лимит — по политике наград.
+func (s *ProfileService) AwardCorrectAnswer(ctx context.Context, childProfileID, attemptID string) error {
+	return s.grantReward(ctx, childProfileID, "correct answer", store.RewardGrant{
+		Currency:  store.CurrencyXP,
+		Amount:    XPForCorrectAnswer,
+		AttemptID: attemptID,
+		Source: store.WalletSource{
+			Type: store.WalletSourceAttempt,
+			ID:   attemptID,
+			Key:  "attempt_correct:" + attemptID,
+		},
+	})
 }
 
-// AwardFixErrors начисляет XP за исправление ошибок
-func (s *ProfileService) AwardFixErrors(ctx context.Context, childProfileID string) error {
-	level, leveledUp, err := s.store.AddXP(ctx, childProfileID, XPForFixErrors, store.DefaultXPConfig)
-	if err != nil {
-		log.Printf("[ProfileService] Failed to award fix errors XP for %s: %v", childProfileID, err)
-		return err
-	}
-
-	if leveledUp {
-		log.Printf("[ProfileService] 🎉 Level up from fixing errors! child=%s, level=%d", childProfileID, level)
-	}
-
-	return nil
+// AwardCorrectAnswerCoins начисляет монеты за правильное решение (один раз на попытку)
+func (s *ProfileService) AwardCorrectAnswerCoins(ctx context.Context, childProfileID, attemptID string) error {
+	return s.grantReward(ctx, childProfileID, "correct answer coins", store.RewardGrant{
+		Currency:  store.CurrencyCoins,
+		Amount:    CoinsForCorrectAnswer,
+		AttemptID: attemptID,
+		Source: store.WalletSource{
+			Type:        store.WalletSourceAttempt,
+			ID:          attemptID,
+			Key:         "attempt_correct:" + attemptID,
+			Description: "Правильное решение",
+		},
+	})
 }
 
-// AwardHintRequest начисляет XP за запрос подсказки
-func (s *ProfileService) AwardHintRequest(ctx context.Context, childProfileID string) error {
-	level, leveledUp, err := s.store.AddXP(ctx, childProfileID, XPForHintRequest, store.DefaultXPConfig)
-	if err != nil {
-		log.Printf("[ProfileService] Failed to award hint request XP for %s: %v", childProfileID, err)
-		return err
-	}
-
-	if leveledUp {
-		log.Printf("[ProfileService] 🎉 Level up from hint request! child=%s, level=%d", childProfileID, level)
-	}
+// AwardFixErrors начисляет XP за исправление ошибок (один раз на попытку)
+func (s *ProfileService) AwardFixErrors(ctx context.Context, childProfileID, attemptID string) error {
+	return s.grantReward(ctx, childProfileID, "fixing errors", store.RewardGrant{
+		Currency:  store.CurrencyXP,
+		Amount:    XPForFixErrors,
+		AttemptID: attemptID,
+		Source: store.WalletSource{
+			Type: store.WalletSourceAttempt,
+			ID:   attemptID,
+			Key:  "attempt_fix:" + attemptID,
+		},
+	})
+}
 
-	return nil
+// AwardHintRequest начисляет XP за запрос подсказки (один раз на каждую подсказку попытки).
+// После показа ответа на задачу подсказки XP не дают.
+func (s *ProfileService) AwardHintRequest(ctx context.Context, childProfileID, attemptID string, hintIndex int) error {
+	return s.grantReward(ctx, childProfileID, "hint request", store.RewardGrant{
+		Currency:  store.CurrencyXP,
+		Amount:    XPForHintRequest,
+		AttemptID: attemptID,
+		Source: store.WalletSource{
+			Type: store.WalletSourceHint,
+			ID:   attemptID,
+			Key:  fmt.Sprintf("hint:%s:%d", attemptID, hintIndex),
+		},
+	})
 }
 
-// AwardDailyLogin начисляет XP за ежедневный вход
-func (s *ProfileService) AwardDailyLogin(ctx context.Context, childProfileID string) error {
-	level, leveledUp, err := s.store.AddXP(ctx, childProfileID, XPForDailyLogin, store.DefaultXPConfig)
+// AwardDailyLogin начисляет XP за ежедневный вход (один раз за день)
+func (s *ProfileService) AwardDailyLogin(ctx context.Context, childProfileID string, day time.Time) error {
+	date := day.Format("2006-01-02")
+	level, leveledUp, err := s.store.AddXP(ctx, childProfileID, XPForDailyLogin, s.store.XP, store.WalletSource{
+		Type: store.WalletSourceDailyLogin,
+		ID:   date,
+	})
 	if err != nil {
 		log.Printf("[ProfileService] Failed to award daily login XP for %s: %v", childProfileID, err)
 		return err
@@ -77,31 +100,33 @@ func (s *ProfileService) AwardDailyLogin(ctx context.Context, childProfileID str
 	return nil
 }
 
-// AwardVillainDefeat начисляет XP за победу над злодеем
-func (s *ProfileService) AwardVillainDefeat(ctx context.Context, childProfileID string) error {
-	level, leveledUp, err := s.store.AddXP(ctx, childProfileID, XPForVillainDefeat, store.DefaultXPConfig)
-	if err != nil {
-		log.Printf("[ProfileService] Failed to award villain defeat XP for %s: %v", childProfileID, err)
-		return err
-	}
-
-	if leveledUp {
-		log.Printf("[ProfileService] 🎉 Level up from villain defeat! child=%s, level=%d", childProfileID, level)
-	}
-
-	return nil
+// AwardAchievementUnlock начисляет XP за разблокировку достижения (в пределах дневного лимита)
+func (s *ProfileService) AwardAchievementUnlock(ctx context.Context, childProfileID, achievementID string) error {
+	return s.grantReward(ctx, childProfileID, "achievement", store.RewardGrant{
+		Currency: store.CurrencyXP,
+		Amount:   XPForAchievement,
+		Source: store.WalletSource{
+			Type: store.WalletSourceAchievement,
+			ID:   achievementID,
+			Key:  "achievement_unlock:" + achievementID,
+		},
+	})
 }
 
-// AwardAchievementUnlock начисляет XP за разблокировку достижения
-func (s *ProfileService) AwardAchievementUnlock(ctx context.Context, childProfileID string) error {
-	level, leveledUp, err := s.store.AddXP(ctx, childProfileID, XPForAchievement, store.DefaultXPConfig)
+// grantReward начисляет награду через политику наград и пишет в лог урезание и повышение уровня
+func (s *ProfileService) grantReward(ctx context.Context, childProfileID, what string, g store.RewardGrant) error {
+	res, err := s.store.GrantReward(ctx, childProfileID, g)
 	if err != nil {
-		log.Printf("[ProfileService] Failed to award achievement XP for %s: %v", childProfileID, err)
+		log.Printf("[ProfileService] Failed to award %s for %s: %v", what, childProfileID, err)
 		return err
 	}
 
-	if leveledUp {
-		log.Printf("[ProfileService] 🎉 Level up from achievement! child=%s, level=%d", childProfileID, level)
+	if res.Reason != "" {
+		log.Printf("[ProfileService] Reward for %s reduced by policy: child=%s, %d -> %d %s (%s)",
+			what, childProfileID, res.Requested, res.Granted, g.Currency, res.Reason)
+	}
+	if res.LeveledUp {
+		log.Printf("[ProfileService] 🎉 Level up from %s! child=%s, level=%d", what, childProfileID, res.Level)
 	}
 
 	return nil
@@ -101,6 +101,10 @@ func (s *Store) ListAchievementsWithProgress(ctx context.Context, childProfileID
 			LEFT JOIN child_achievements ca
 				ON a.id = ca.achievement_id
 				AND ca.child_profile_id = $1
+			-- Достижения события видны в его даты, после — только полученные
+			WHERE a.event_id IS NULL
+			   OR COALESCE(ca.is_unlocked, FALSE)
+			   OR seasonal_event_open(a.event_id, $1::TEXT)
 		)
 		SELECT
 			id, type, title, description, icon,
@@ -237,6 +241,9 @@ func (s *Store) GetAchievementStats(ctx context.Context, childProfileID string)
 		LEFT JOIN child_achievements ca
 			ON a.id = ca.achievement_id
 			AND ca.child_profile_id = $1
+		WHERE a.event_id IS NULL
+		   OR COALESCE(ca.is_unlocked, FALSE)
+		   OR seasonal_event_open(a.event_id, $1::TEXT)
 	`
 
 	err = s.DB.QueryRowContext(ctx, query, childProfileID).Scan(&unlockedCount, &totalCount)
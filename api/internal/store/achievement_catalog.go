@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// AchievementDefinition строка achievements из каталога
+type AchievementDefinition struct {
+	ID               string
+	Category         string // achievements.type
+	Series           string
+	SeriesLevel      int
+	Title            string
+	Description      string
+	Icon             string
+	ArtworkKey       string
+	RequirementType  string
+	RequirementValue int
+	RewardType       string
+	RewardID         string
+	RewardName       string
+	RewardAmount     int
+	Priority         int
+	Texts            json.RawMessage
+	CatalogVersion   int
+}
+
+// AchievementSyncResult итог синхронизации каталога
+type AchievementSyncResult struct {
+	Inserted  int
+	Updated   int
+	Unchanged int
+}
+
+// SyncAchievementCatalog в одной транзакции добавляет и обновляет достижения каталога.
+// Повторный запуск с тем же каталогом ничего не меняет. Достижения, которых нет
+// в каталоге, не удаляются: у детей может быть по ним прогресс.
+func (s *Store) SyncAchievementCatalog(ctx context.Context, defs []AchievementDefinition) (*AchievementSyncResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Уровни серии могут переставляться: освобождаем (series, series_level), занятые
+	// другими достижениями. При неизменном каталоге запрос ничего не трогает.
+	levelsJSON, err := json.Marshal(seriesLevels(defs))
+	if err != nil {
+		return nil, fmt.Errorf("marshal series levels: %w", err)
+	}
+	releaseQuery := `
+		UPDATE achievements a
+		SET series_level = NULL
+		FROM jsonb_to_recordset($1::JSONB) AS d(id TEXT, series TEXT, series_level INTEGER)
+		WHERE a.series = d.series AND a.series_level = d.series_level AND a.id <> d.id
+	`
+	if _, err := tx.ExecContext(ctx, releaseQuery, levelsJSON); err != nil {
+		return nil, fmt.Errorf("release series levels: %w", err)
+	}
+
+	query := `
+		INSERT INTO achievements (id, type, series, series_level, title, description, icon, artwork_key,
+		                          requirement_type, requirement_value, reward_type, reward_id, reward_name,
+		                          reward_amount, priority, texts, catalog_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		ON CONFLICT (id) DO UPDATE
+		SET type = EXCLUDED.type,
+		    series = EXCLUDED.series,
+		    series_level = EXCLUDED.series_level,
+		    title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    icon = EXCLUDED.icon,
+		    artwork_key = EXCLUDED.artwork_key,
+		    requirement_type = EXCLUDED.requirement_type,
+		    requirement_value = EXCLUDED.requirement_value,
+		    reward_type = EXCLUDED.reward_type,
+		    reward_id = EXCLUDED.reward_id,
+		    reward_name = EXCLUDED.reward_name,
+		    reward_amount = EXCLUDED.reward_amount,
+		    priority = EXCLUDED.priority,
+		    texts = EXCLUDED.texts,
+		    catalog_version = EXCLUDED.catalog_version,
+		    updated_at = NOW()
+		WHERE (achievements.type, achievements.series, achievements.series_level, achievements.title,
+		       achievements.description, achievements.icon, achievements.artwork_key,
+		       achievements.requirement_type, achievements.requirement_value, achievements.reward_type,
+		       achievements.reward_id, achievements.reward_name, achievements.reward_amount,
+		       achievements.priority, achievements.texts, achievements.catalog_version)
+		  IS DISTINCT FROM
+		      (EXCLUDED.type, EXCLUDED.series, EXCLUDED.series_level, EXCLUDED.title,
+		       EXCLUDED.description, EXCLUDED.icon, EXCLUDED.artwork_key,
+		       EXCLUDED.requirement_type, EXCLUDED.requirement_value, EXCLUDED.reward_type,
+		       EXCLUDED.reward_id, EXCLUDED.reward_name, EXCLUDED.reward_amount,
+		       EXCLUDED.priority, EXCLUDED.texts, EXCLUDED.catalog_version)
+		RETURNING (xmax = 0) AS inserted
+	`
+
+	result := &AchievementSyncResult{}
+	for _, d := range defs {
+		var inserted bool
+		err := tx.QueryRowContext(ctx, query,
+			d.ID, d.Category, d.Series, d.SeriesLevel, d.Title, d.Description, d.Icon, d.ArtworkKey,
+			d.RequirementType, d.RequirementValue, d.RewardType,
+			sql.NullString{String: d.RewardID, Valid: d.RewardID != ""},
+			d.RewardName, d.RewardAmount, d.Priority, []byte(d.Texts), d.CatalogVersion,
+		).Scan(&inserted)
+		switch {
+		case err == sql.ErrNoRows:
+			result.Unchanged++
+		case err != nil:
+			return nil, fmt.Errorf("upsert achievement %s: %w", d.ID, err)
+		case inserted:
+			result.Inserted++
+		default:
+			result.Updated++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	log.Printf("[Store] Achievement catalog synced: inserted=%d, updated=%d, unchanged=%d",
+		result.Inserted, result.Updated, result.Unchanged)
+
+	return result, nil
+}
+
+type seriesLevel struct {
+	ID          string `json:"id"`
+	Series      string `json:"series"`
+	SeriesLevel int    `json:"series_level"`
+}
+
+func seriesLevels(defs []AchievementDefinition) []seriesLevel {
+	levels := make([]seriesLevel, 0, len(defs))
+	for _, d := range defs {
+		levels = append(levels, seriesLevel{ID: d.ID, Series: d.Series, SeriesLevel: d.SeriesLevel})
+	}
+	return levels
+}
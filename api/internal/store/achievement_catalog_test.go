@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSyncAchievementCatalog_Idempotent(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	series := testID("test_series")
+	def := func(id string, level, value int) AchievementDefinition {
+		return AchievementDefinition{
+			ID: id, Category: "test", Series: series, SeriesLevel: level,
+			Title: "Test", Description: "Test achievement", Icon: "🏆", ArtworkKey: "sticker_test",
+			RequirementType: RequirementTasksCorrect, RequirementValue: value,
+			RewardType: "sticker", RewardName: "Test reward",
+			Texts:          []byte(`{"ru": {"title": "Test", "description": "Test achievement", "reward_name": "Test reward"}}`),
+			CatalogVersion: 1,
+		}
+	}
+	first, second := series+"_1", series+"_2"
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM achievements WHERE series = $1`, series); err != nil {
+			t.Logf("Warning: failed to cleanup test achievements: %v", err)
+		}
+	})
+
+	defs := []AchievementDefinition{def(first, 1, 1), def(second, 2, 5)}
+	result, err := s.SyncAchievementCatalog(ctx, defs)
+	if err != nil {
+		t.Fatalf("SyncAchievementCatalog() error = %v", err)
+	}
+	if result.Inserted != 2 {
+		t.Errorf("first sync = %+v, want 2 inserted", result)
+	}
+
+	result, err = s.SyncAchievementCatalog(ctx, defs)
+	if err != nil {
+		t.Fatalf("repeated SyncAchievementCatalog() error = %v", err)
+	}
+	if result.Unchanged != 2 || result.Inserted != 0 || result.Updated != 0 {
+		t.Errorf("repeated sync = %+v, want 2 unchanged", result)
+	}
+
+	// Уровни меняются местами без нарушения уникальности (series, series_level)
+	swapped := []AchievementDefinition{def(first, 2, 5), def(second, 1, 1)}
+	result, err = s.SyncAchievementCatalog(ctx, swapped)
+	if err != nil {
+		t.Fatalf("swapped SyncAchievementCatalog() error = %v", err)
+	}
+	if result.Updated != 2 {
+		t.Errorf("swapped sync = %+v, want 2 updated", result)
+	}
+
+	var level, value int
+	query := `SELECT series_level, requirement_value FROM achievements WHERE id = $1`
+	if err := db.QueryRow(query, first).Scan(&level, &value); err != nil {
+		t.Fatalf("failed to get achievement: %v", err)
+	}
+	if level != 2 || value != 5 {
+		t.Errorf("%s = level %d, value %d; want 2, 5", first, level, value)
+	}
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"child-bot/api/internal/domain"
+)
+
+// AchievementClaim результат получения награды за достижение
+type AchievementClaim struct {
+	AchievementID  string
+	RewardType     string
+	RewardID       string
+	RewardName     string
+	RewardAmount   int
+	AlreadyClaimed bool // награда уже была получена ранее (вручную или автоматически)
+	LeveledUp      bool
+	CoinsBalance   int
+	XPTotal        int
+	Level          int
+}
+
+// ClaimAchievementReward в одной транзакции проверяет, что достижение разблокировано,
+// начисляет награду и помечает её полученной. Повторный вызов ничего не начисляет
+// и возвращает текущие балансы с AlreadyClaimed = true.
+func (s *Store) ClaimAchievementReward(ctx context.Context, childProfileID, achievementID string) (*AchievementClaim, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем строку прогресса, чтобы параллельные запросы не начислили награду дважды
+	var (
+		isUnlocked, isClaimed bool
+		rewardID, rewardName  sql.NullString
+		rewardAmount          sql.NullInt32
+	)
+	claim := &AchievementClaim{AchievementID: achievementID}
+	query := `
+		SELECT COALESCE(ca.is_unlocked, FALSE), COALESCE(ca.is_claimed, FALSE),
+		       a.reward_type, a.reward_id, a.reward_name, a.reward_amount
+		FROM child_achievements ca
+		JOIN achievements a ON a.id = ca.achievement_id
+		WHERE ca.child_profile_id = $1 AND ca.achievement_id = $2
+		FOR UPDATE OF ca
+	`
+	err = tx.QueryRowContext(ctx, query, childProfileID, achievementID).Scan(
+		&isUnlocked, &isClaimed, &claim.RewardType, &rewardID, &rewardName, &rewardAmount,
+	)
+	if err == sql.ErrNoRows {
+		// Прогресса нет: либо такого достижения нет, либо оно ещё не начато
+		var exists bool
+		existsQuery := `SELECT EXISTS (SELECT 1 FROM achievements WHERE id = $1)`
+		if err := tx.QueryRowContext(ctx, existsQuery, achievementID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("check achievement exists: %w", err)
+		}
+		if !exists {
+			return nil, domain.ErrNotFound
+		}
+		return nil, domain.ErrAchievementLocked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get child achievement: %w", err)
+	}
+
+	if !isUnlocked {
+		return nil, domain.ErrAchievementLocked
+	}
+
+	claim.RewardName = rewardName.String
+	claim.RewardAmount = int(rewardAmount.Int32)
+	claim.RewardID = achievementID
+	if rewardID.Valid && rewardID.String != "" {
+		claim.RewardID = rewardID.String
+	}
+	claim.AlreadyClaimed = isClaimed
+
+	// Монеты и XP начисляются только при первом получении
+	if !isClaimed {
+		// Ключ совпадает с автозабором монет в триггере, поэтому двойное начисление невозможно
+		src := WalletSource{Type: WalletSourceAchievement, ID: achievementID, Description: claim.RewardName}
+		switch claim.RewardType {
+		case "coins":
+			if _, _, err := applyWalletEntryTx(ctx, tx, childProfileID, CurrencyCoins, claim.RewardAmount, src); err != nil {
+				return nil, fmt.Errorf("add reward coins: %w", err)
+			}
+		case "xp":
+			if claim.RewardAmount > 0 {
+				_, leveledUp, err := addXPTx(ctx, tx, childProfileID, claim.RewardAmount, s.XP, src)
+				if err != nil {
+					return nil, err
+				}
+				claim.LeveledUp = leveledUp
+			}
+		case "streak_freeze":
+			if claim.RewardAmount > 0 {
+				if err := addInventoryTx(ctx, tx, childProfileID, StreakFreezeItemID, claim.RewardAmount); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		claimQuery := `
+			UPDATE child_achievements
+			SET is_claimed = TRUE,
+			    claimed_at = NOW(),
+			    updated_at = NOW()
+			WHERE child_profile_id = $1 AND achievement_id = $2
+		`
+		if _, err := tx.ExecContext(ctx, claimQuery, childProfileID, achievementID); err != nil {
+			return nil, fmt.Errorf("mark achievement claimed: %w", err)
+		}
+	}
+
+	// Предмет кладём в коллекцию и при повторном получении: так восстанавливаются
+	// награды, автоматически помеченные полученными до появления child_rewards
+	switch claim.RewardType {
+	case "sticker", "avatar", "badge":
+		rewardQuery := `
+			INSERT INTO child_rewards (child_profile_id, reward_type, reward_id, reward_name, achievement_id)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (child_profile_id, reward_type, reward_id) DO NOTHING
+		`
+		if _, err := tx.ExecContext(ctx, rewardQuery, childProfileID, claim.RewardType, claim.RewardID, rewardName, achievementID); err != nil {
+			return nil, fmt.Errorf("add child reward: %w", err)
+		}
+	}
+
+	balanceQuery := `
+		SELECT coins_balance, COALESCE(xp_total, 0), COALESCE(level, 1)
+		FROM child_profiles
+		WHERE id = $1
+	`
+	if err := tx.QueryRowContext(ctx, balanceQuery, childProfileID).Scan(&claim.CoinsBalance, &claim.XPTotal, &claim.Level); err != nil {
+		return nil, fmt.Errorf("get balances: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	log.Printf("[Store] Achievement reward claimed: child=%s, achievement=%s, reward=%s/%s, amount=%d, already_claimed=%v",
+		childProfileID, achievementID, claim.RewardType, claim.RewardID, claim.RewardAmount, claim.AlreadyClaimed)
+
+	return claim, nil
+}
+
+// HasChildReward проверяет, есть ли у ребёнка предмет-награда
+func (s *Store) HasChildReward(ctx context.Context, childProfileID, rewardType, rewardID string) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM child_rewards
+			WHERE child_profile_id = $1 AND reward_type = $2 AND reward_id = $3
+		)
+	`
+	if err := s.DB.QueryRowContext(ctx, query, childProfileID, rewardType, rewardID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check child reward: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,292 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"child-bot/api/internal/domain"
+)
+
+// setupTestDB creates a test database connection
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration test")
+	}
+
+	db, err := sql.Open("pgx", databaseURL)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		t.Fatalf("failed to ping test database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+// createTestProfile creates a test child_profile with the given coins balance and returns its ID
+func createTestProfile(t *testing.T, db *sql.DB, platformUserID string, coins int) string {
+	t.Helper()
+
+	var profileID string
+	query := `
+		INSERT INTO child_profiles (display_name, platform_id, platform_user_id, grade, avatar_id, coins_balance)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err := db.QueryRow(query, "Test User", "web", platformUserID, 3, "avatar1", coins).Scan(&profileID)
+	if err != nil {
+		t.Fatalf("failed to create test profile: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM child_profiles WHERE id = $1`, profileID); err != nil {
+			t.Logf("Warning: failed to cleanup test profile: %v", err)
+		}
+	})
+
+	return profileID
+}
+
+// createTestAchievement creates an achievement with the given reward
+func createTestAchievement(t *testing.T, db *sql.DB, id, rewardType string, rewardID sql.NullString, amount int) {
+	t.Helper()
+
+	query := `
+		INSERT INTO achievements (id, type, title, description, icon, requirement_type, requirement_value,
+		                          reward_type, reward_id, reward_name, reward_amount)
+		VALUES ($1, 'test', 'Test', 'Test achievement', '🏆', 'tasks_correct', 1, $2, $3, 'Test reward', $4)
+	`
+	if _, err := db.Exec(query, id, rewardType, rewardID, amount); err != nil {
+		t.Fatalf("failed to create test achievement: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM achievements WHERE id = $1`, id); err != nil {
+			t.Logf("Warning: failed to cleanup test achievement: %v", err)
+		}
+	})
+}
+
+// setProgress creates or updates child_achievements the way the achievement engine does
+func setProgress(t *testing.T, db *sql.DB, childProfileID, achievementID string, unlocked bool) {
+	t.Helper()
+
+	query := `
+		INSERT INTO child_achievements (child_profile_id, achievement_id, current_progress, is_unlocked, unlocked_at)
+		VALUES ($1, $2, 1, $3, CASE WHEN $3 THEN NOW() ELSE NULL END)
+		ON CONFLICT (child_profile_id, achievement_id) DO UPDATE
+		SET is_unlocked = EXCLUDED.is_unlocked,
+		    unlocked_at = EXCLUDED.unlocked_at
+	`
+	if _, err := db.Exec(query, childProfileID, achievementID, unlocked); err != nil {
+		t.Fatalf("failed to set achievement progress: %v", err)
+	}
+}
+
+func getBalances(t *testing.T, db *sql.DB, childProfileID string) (coins, xp int) {
+	t.Helper()
+
+	query := `SELECT coins_balance, COALESCE(xp_total, 0) FROM child_profiles WHERE id = $1`
+	if err := db.QueryRow(query, childProfileID).Scan(&coins, &xp); err != nil {
+		t.Fatalf("failed to get balances: %v", err)
+	}
+	return coins, xp
+}
+
+func testID(prefix string) string {
+	return prefix + "_" + time.Now().Format("150405.000000000")
+}
+
+func TestClaimAchievementReward_CoinsAutoClaimed(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("claim_coins"), 10)
+	achievementID := testID("test_claim_coins")
+	createTestAchievement(t, db, achievementID, "coins", sql.NullString{}, 50)
+
+	// Разблокировка при вставке: триггер из 038 сразу начисляет монеты
+	setProgress(t, db, childID, achievementID, true)
+
+	coins, _ := getBalances(t, db, childID)
+	if coins != 60 {
+		t.Fatalf("expected auto-claim to credit coins to 60, got %d", coins)
+	}
+
+	claim, err := s.ClaimAchievementReward(ctx, childID, achievementID)
+	if err != nil {
+		t.Fatalf("ClaimAchievementReward() error = %v", err)
+	}
+	if !claim.AlreadyClaimed {
+		t.Error("expected auto-claimed coins achievement to be already claimed")
+	}
+	if claim.CoinsBalance != 60 {
+		t.Errorf("CoinsBalance = %d, want 60 (no double credit)", claim.CoinsBalance)
+	}
+}
+
+func TestClaimAchievementReward_CoinsAutoClaimedOnUpdate(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("claim_coins_upd"), 0)
+	achievementID := testID("test_claim_coins_upd")
+	createTestAchievement(t, db, achievementID, "coins", sql.NullString{}, 30)
+
+	// Сначала прогресс, затем разблокировка UPDATE-ом
+	setProgress(t, db, childID, achievementID, false)
+	setProgress(t, db, childID, achievementID, true)
+
+	for i := 0; i < 2; i++ {
+		claim, err := s.ClaimAchievementReward(ctx, childID, achievementID)
+		if err != nil {
+			t.Fatalf("ClaimAchievementReward() error = %v", err)
+		}
+		if !claim.AlreadyClaimed || claim.CoinsBalance != 30 {
+			t.Errorf("claim %d: already_claimed=%v, coins=%d; want true, 30", i, claim.AlreadyClaimed, claim.CoinsBalance)
+		}
+	}
+}
+
+func TestClaimAchievementReward_Sticker(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("claim_sticker"), 5)
+	achievementID := testID("test_claim_sticker")
+	createTestAchievement(t, db, achievementID, "sticker", sql.NullString{}, 0)
+	setProgress(t, db, childID, achievementID, true)
+
+	claim, err := s.ClaimAchievementReward(ctx, childID, achievementID)
+	if err != nil {
+		t.Fatalf("ClaimAchievementReward() error = %v", err)
+	}
+	if claim.AlreadyClaimed {
+		t.Error("sticker must not be auto-claimed")
+	}
+	if claim.RewardID != achievementID {
+		t.Errorf("RewardID = %q, want achievement id %q when reward_id is NULL", claim.RewardID, achievementID)
+	}
+
+	has, err := s.HasChildReward(ctx, childID, "sticker", achievementID)
+	if err != nil {
+		t.Fatalf("HasChildReward() error = %v", err)
+	}
+	if !has {
+		t.Error("expected sticker in child_rewards")
+	}
+
+	again, err := s.ClaimAchievementReward(ctx, childID, achievementID)
+	if err != nil {
+		t.Fatalf("repeated ClaimAchievementReward() error = %v", err)
+	}
+	if !again.AlreadyClaimed || again.CoinsBalance != 5 {
+		t.Errorf("repeated claim: already_claimed=%v, coins=%d; want true, 5", again.AlreadyClaimed, again.CoinsBalance)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM child_rewards WHERE child_profile_id = $1`, childID).Scan(&count); err != nil {
+		t.Fatalf("failed to count rewards: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("child_rewards count = %d, want 1", count)
+	}
+}
+
+func TestClaimAchievementReward_XPConcurrent(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("claim_xp"), 0)
+	achievementID := testID("test_claim_xp")
+	createTestAchievement(t, db, achievementID, "xp", sql.NullString{}, 40)
+	setProgress(t, db, childID, achievementID, true)
+
+	var wg sync.WaitGroup
+	results := make([]*AchievementClaim, 5)
+	errs := make([]error, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = s.ClaimAchievementReward(ctx, childID, achievementID)
+		}(i)
+	}
+	wg.Wait()
+
+	fresh := 0
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("claim %d error = %v", i, err)
+		}
+		if !results[i].AlreadyClaimed {
+			fresh++
+		}
+	}
+	if fresh != 1 {
+		t.Errorf("expected exactly one fresh claim, got %d", fresh)
+	}
+
+	_, xp := getBalances(t, db, childID)
+	if xp != 40 {
+		t.Errorf("xp_total = %d, want 40", xp)
+	}
+}
+
+func TestClaimAchievementReward_Errors(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("claim_errors"), 0)
+	achievementID := testID("test_claim_locked")
+	createTestAchievement(t, db, achievementID, "avatar", sql.NullString{String: "avatar_test", Valid: true}, 0)
+
+	// Прогресса ещё нет
+	if _, err := s.ClaimAchievementReward(ctx, childID, achievementID); !errors.Is(err, domain.ErrAchievementLocked) {
+		t.Errorf("no progress: error = %v, want ErrAchievementLocked", err)
+	}
+
+	// Есть прогресс, но не разблокировано
+	setProgress(t, db, childID, achievementID, false)
+	if _, err := s.ClaimAchievementReward(ctx, childID, achievementID); !errors.Is(err, domain.ErrAchievementLocked) {
+		t.Errorf("locked: error = %v, want ErrAchievementLocked", err)
+	}
+
+	if _, err := s.ClaimAchievementReward(ctx, childID, "missing_achievement_id"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("missing: error = %v, want ErrNotFound", err)
+	}
+
+	// После разблокировки аватар с reward_id попадает в коллекцию
+	setProgress(t, db, childID, achievementID, true)
+	claim, err := s.ClaimAchievementReward(ctx, childID, achievementID)
+	if err != nil {
+		t.Fatalf("ClaimAchievementReward() error = %v", err)
+	}
+	if claim.RewardID != "avatar_test" {
+		t.Errorf("RewardID = %q, want avatar_test", claim.RewardID)
+	}
+}
@@ -5,263 +5,197 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 )
 
-// UpdateAchievementProgress обновляет прогресс достижения и проверяет разблокировку
-// Возвращает: (wasUnlocked bool, error)
-func (s *Store) UpdateAchievementProgress(ctx context.Context, childProfileID, achievementID string, newProgress int) (bool, error) {
+// Типы требований достижений
+const (
+	RequirementTasksCorrect      = "tasks_correct"
+	RequirementTasksNoHints      = "tasks_no_hints"
+	RequirementErrorsFound       = "errors_found"
+	RequirementHintsUsed         = "hints_used"
+	RequirementVillainsDefeated  = "villains_defeated"
+	RequirementStreakDays        = "streak_days"
+	RequirementFriendsInvited    = "friends_invited"
+	RequirementStickersCollected = "stickers_collected"
+)
+
+// AchievementCounterUpdate изменение счётчика требования
+type AchievementCounterUpdate struct {
+	RequirementType string
+	Delta           int  // прибавить к счётчику
+	Value           int  // новое значение, если IsGauge
+	IsGauge         bool // счётчик-показатель (streak), а не накопительный
+}
+
+// UnlockedAchievement достижение, разблокированное событием
+type UnlockedAchievement struct {
+	AchievementID string
+	RewardType    string
+	LeveledUp     bool
+}
+
+// ApplyAchievementEvent в одной транзакции учитывает событие: обновляет счётчики,
+// разблокирует достигнутые достижения и начисляет unlockXP за каждое (в пределах
+// дневного лимита политики наград).
+// Событие с уже обработанным eventKey ничего не меняет. Стикеры-награды
+// сразу продвигают счётчик stickers_collected.
+func (s *Store) ApplyAchievementEvent(ctx context.Context, childProfileID, eventKey string, updates []AchievementCounterUpdate, unlockXP int) ([]UnlockedAchievement, error) {
 	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return false, fmt.Errorf("begin tx: %w", err)
+		return nil, fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback()
 
-	// Получаем requirement_value из achievements
-	var requirementValue int
-	achievementQuery := `SELECT requirement_value FROM achievements WHERE id = $1`
-	err = tx.QueryRowContext(ctx, achievementQuery, achievementID).Scan(&requirementValue)
-	if err != nil {
-		return false, fmt.Errorf("get achievement requirement: %w", err)
+	// События одного ребёнка применяются последовательно
+	var lockedID string
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM child_profiles WHERE id = $1 FOR UPDATE`, childProfileID).Scan(&lockedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("child profile %s not found", childProfileID)
+		}
+		return nil, fmt.Errorf("lock profile: %w", err)
 	}
 
-	// Проверяем есть ли уже запись в child_achievements
-	var existingProgress int
-	var isUnlocked bool
-	checkQuery := `
-		SELECT current_progress, is_unlocked
-		FROM child_achievements
-		WHERE child_profile_id = $1 AND achievement_id = $2
-	`
-	err = tx.QueryRowContext(ctx, checkQuery, childProfileID, achievementID).Scan(&existingProgress, &isUnlocked)
-
-	if err == sql.ErrNoRows {
-		// Создаём новую запись
-		isUnlocked = newProgress >= requirementValue
-		insertQuery := `
-			INSERT INTO child_achievements (child_profile_id, achievement_id, current_progress, is_unlocked, unlocked_at)
-			VALUES ($1, $2, $3, $4, CASE WHEN $4 THEN NOW() ELSE NULL END)
-		`
-		_, err = tx.ExecContext(ctx, insertQuery, childProfileID, achievementID, newProgress, isUnlocked)
+	if eventKey != "" {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO achievement_processed_events (child_profile_id, event_key)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, childProfileID, eventKey)
 		if err != nil {
-			return false, fmt.Errorf("insert achievement progress: %w", err)
+			return nil, fmt.Errorf("record processed event: %w", err)
 		}
-
-		if err := tx.Commit(); err != nil {
-			return false, fmt.Errorf("commit tx: %w", err)
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			log.Printf("[Store] Achievement event already processed: child=%s, key=%s", childProfileID, eventKey)
+			return nil, nil
 		}
-
-		log.Printf("[Store] Achievement progress created: child=%s, achievement=%s, progress=%d/%d, unlocked=%v",
-			childProfileID, achievementID, newProgress, requirementValue, isUnlocked)
-
-		return isUnlocked, nil
-	} else if err != nil {
-		return false, fmt.Errorf("check existing progress: %w", err)
 	}
 
-	// Обновляем существующую запись
-	// Если уже разблокировано - ничего не делаем
-	if isUnlocked {
-		return false, nil
-	}
-
-	// Обновляем прогресс
-	wasJustUnlocked := newProgress >= requirementValue
+	var unlocked []UnlockedAchievement
+	for len(updates) > 0 {
+		update := updates[0]
+		updates = updates[1:]
 
-	updateQuery := `
-		UPDATE child_achievements
-		SET current_progress = $1,
-		    is_unlocked = $2,
-		    unlocked_at = CASE WHEN $2 AND unlocked_at IS NULL THEN NOW() ELSE unlocked_at END,
-		    updated_at = NOW()
-		WHERE child_profile_id = $3 AND achievement_id = $4
-	`
-	_, err = tx.ExecContext(ctx, updateQuery, newProgress, wasJustUnlocked, childProfileID, achievementID)
-	if err != nil {
-		return false, fmt.Errorf("update achievement progress: %w", err)
-	}
-
-	if err := tx.Commit(); err != nil {
-		return false, fmt.Errorf("commit tx: %w", err)
-	}
-
-	log.Printf("[Store] Achievement progress updated: child=%s, achievement=%s, progress=%d/%d, unlocked=%v",
-		childProfileID, achievementID, newProgress, requirementValue, wasJustUnlocked)
-
-	return wasJustUnlocked, nil
-}
-
-// CheckAndUpdateAchievementsByType проверяет и обновляет прогресс всех достижений заданного типа
-// Возвращает список ID разблокированных достижений
-func (s *Store) CheckAndUpdateAchievementsByType(ctx context.Context, childProfileID, requirementType string, currentValue int) ([]string, error) {
-	// Получаем все достижения данного типа
-	query := `
-		SELECT id, requirement_value
-		FROM achievements
-		WHERE requirement_type = $1
-		ORDER BY requirement_value ASC
-	`
-
-	rows, err := s.DB.QueryContext(ctx, query, requirementType)
-	if err != nil {
-		return nil, fmt.Errorf("query achievements: %w", err)
-	}
-	defer rows.Close()
-
-	var unlockedIDs []string
-
-	for rows.Next() {
-		var achievementID string
-		var requirementValue int
-
-		if err := rows.Scan(&achievementID, &requirementValue); err != nil {
-			log.Printf("[Store] Failed to scan achievement: %v", err)
-			continue
+		value, err := applyCounterUpdateTx(ctx, tx, childProfileID, update)
+		if err != nil {
+			return nil, err
 		}
 
-		// Обновляем прогресс
-		wasUnlocked, err := s.UpdateAchievementProgress(ctx, childProfileID, achievementID, currentValue)
+		ids, err := unlockAchievementsTx(ctx, tx, childProfileID, update.RequirementType, value)
 		if err != nil {
-			log.Printf("[Store] Failed to update achievement %s: %v", achievementID, err)
-			continue
+			return nil, err
 		}
 
-		if wasUnlocked {
-			unlockedIDs = append(unlockedIDs, achievementID)
-			log.Printf("[Store] 🎉 Achievement unlocked! child=%s, achievement=%s, type=%s, value=%d",
-				childProfileID, achievementID, requirementType, currentValue)
+		for _, u := range ids {
+			if unlockXP > 0 {
+				// XP за достижения ограничен дневным лимитом политики наград
+				granted, err := grantRewardTx(ctx, tx, childProfileID, RewardGrant{
+					Currency: CurrencyXP,
+					Amount:   unlockXP,
+					Source: WalletSource{
+						Type: WalletSourceAchievement,
+						ID:   u.AchievementID,
+						Key:  "achievement_unlock:" + u.AchievementID,
+					},
+				}, s.Rewards, s.XP, time.Now())
+				if err != nil {
+					return nil, fmt.Errorf("award unlock xp: %w", err)
+				}
+				u.LeveledUp = granted.LeveledUp
+			}
+
+			// Стикер пополняет коллекцию — проверяем «Коллекционера»
+			if u.RewardType == "sticker" {
+				updates = append(updates, AchievementCounterUpdate{RequirementType: RequirementStickersCollected, Delta: 1})
+			}
+
+			unlocked = append(unlocked, u)
 		}
 	}
 
-	if err := rows.Err(); err != nil {
-		return unlockedIDs, fmt.Errorf("rows iteration: %w", err)
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
 	}
 
-	return unlockedIDs, nil
-}
-
-// GetCurrentStreakDays получает текущий streak пользователя
-func (s *Store) GetCurrentStreakDays(ctx context.Context, childProfileID string) (int, error) {
-	var streakDays int
-	query := `SELECT COALESCE(streak_days, 0) FROM child_profiles WHERE id = $1`
-	err := s.DB.QueryRowContext(ctx, query, childProfileID).Scan(&streakDays)
-	if err != nil {
-		return 0, fmt.Errorf("get streak days: %w", err)
+	for _, u := range unlocked {
+		log.Printf("[Store] 🎉 Achievement unlocked! child=%s, achievement=%s, event=%s",
+			childProfileID, u.AchievementID, eventKey)
 	}
-	return streakDays, nil
-}
 
-// GetVillainsDefeatedCount получает количество побеждённых монстров
-func (s *Store) GetVillainsDefeatedCount(ctx context.Context, childProfileID string) (int, error) {
-	var count int
-	query := `
-		SELECT COUNT(DISTINCT villain_id)
-		FROM villain_battles
-		WHERE child_profile_id = $1 AND status = 'defeated'
-	`
-	err := s.DB.QueryRowContext(ctx, query, childProfileID).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("get villains defeated count: %w", err)
-	}
-	return count, nil
+	return unlocked, nil
 }
 
-// GetTasksCorrectCount получает количество правильно решённых задач
-func (s *Store) GetTasksCorrectCount(ctx context.Context, childProfileID string) (int, error) {
-	var count int
+// applyCounterUpdateTx обновляет счётчик требования и возвращает новое значение
+func applyCounterUpdateTx(ctx context.Context, tx *sql.Tx, childProfileID string, update AchievementCounterUpdate) (int, error) {
 	query := `
-		SELECT COUNT(*)
-		FROM attempts
-		WHERE child_profile_id = $1
-		  AND attempt_type = 'check'
-		  AND status = 'completed'
-		  AND is_correct = true
+		INSERT INTO child_achievement_counters (child_profile_id, requirement_type, value)
+		VALUES ($1, $2, GREATEST($3, 0))
+		ON CONFLICT (child_profile_id, requirement_type) DO UPDATE
+		SET value = GREATEST(child_achievement_counters.value + $3, 0),
+		    updated_at = NOW()
+		RETURNING value
 	`
-	err := s.DB.QueryRowContext(ctx, query, childProfileID).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("get tasks correct count: %w", err)
+	arg := update.Delta
+	if update.IsGauge {
+		query = `
+			INSERT INTO child_achievement_counters (child_profile_id, requirement_type, value)
+			VALUES ($1, $2, GREATEST($3, 0))
+			ON CONFLICT (child_profile_id, requirement_type) DO UPDATE
+			SET value = EXCLUDED.value,
+			    updated_at = NOW()
+			RETURNING value
+		`
+		arg = update.Value
 	}
-	return count, nil
-}
 
-// GetTasksNoHintsCount получает количество задач решённых без подсказок
-func (s *Store) GetTasksNoHintsCount(ctx context.Context, childProfileID string) (int, error) {
-	var count int
-	query := `
-		SELECT COUNT(*)
-		FROM attempts
-		WHERE child_profile_id = $1
-		  AND attempt_type = 'check'
-		  AND status = 'completed'
-		  AND hints_used = 0
-		  AND check_result->>'decision' = 'correct'
-	`
-	err := s.DB.QueryRowContext(ctx, query, childProfileID).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("get tasks no hints count: %w", err)
+	var value int
+	if err := tx.QueryRowContext(ctx, query, childProfileID, update.RequirementType, arg).Scan(&value); err != nil {
+		return 0, fmt.Errorf("update %s counter: %w", update.RequirementType, err)
 	}
-	return count, nil
+	return value, nil
 }
 
-// GetFriendsInvitedCount получает количество приглашённых друзей
-func (s *Store) GetFriendsInvitedCount(ctx context.Context, childProfileID string) (int, error) {
-	var count int
+// unlockAchievementsTx записывает прогресс по всем достижениям типа и возвращает
+// только что разблокированные. Уже разблокированные не трогаются.
+func unlockAchievementsTx(ctx context.Context, tx *sql.Tx, childProfileID, requirementType string, value int) ([]UnlockedAchievement, error) {
 	query := `
-		SELECT COUNT(*)
-		FROM referrals
-		WHERE referrer_child_profile_id = $1
-		  AND invited_child_profile_id IS NOT NULL
+		WITH upserted AS (
+			INSERT INTO child_achievements (child_profile_id, achievement_id, current_progress, is_unlocked, unlocked_at)
+			SELECT $1, a.id, $3, a.requirement_value <= $3,
+			       CASE WHEN a.requirement_value <= $3 THEN NOW() ELSE NULL END
+			FROM achievements a
+			WHERE a.requirement_type = $2
+			ON CONFLICT (child_profile_id, achievement_id) DO UPDATE
+			SET current_progress = EXCLUDED.current_progress,
+			    is_unlocked = EXCLUDED.is_unlocked,
+			    unlocked_at = EXCLUDED.unlocked_at,
+			    updated_at = NOW()
+			WHERE child_achievements.is_unlocked = FALSE
+			RETURNING achievement_id, is_unlocked
+		)
+		SELECT u.achievement_id, a.reward_type
+		FROM upserted u
+		JOIN achievements a ON a.id = u.achievement_id
+		WHERE u.is_unlocked = TRUE
+		ORDER BY a.requirement_value, a.id
 	`
-	err := s.DB.QueryRowContext(ctx, query, childProfileID).Scan(&count)
+	rows, err := tx.QueryContext(ctx, query, childProfileID, requirementType, value)
 	if err != nil {
-		return 0, fmt.Errorf("get friends invited count: %w", err)
+		return nil, fmt.Errorf("unlock %s achievements: %w", requirementType, err)
 	}
-	return count, nil
-}
+	defer rows.Close()
 
-// GetHintsUsedCount получает общее количество использованных подсказок
-func (s *Store) GetHintsUsedCount(ctx context.Context, childProfileID string) (int, error) {
-	var count int
-	query := `SELECT COALESCE(hints_used_total, 0) FROM child_profiles WHERE id = $1`
-	err := s.DB.QueryRowContext(ctx, query, childProfileID).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("get hints used count: %w", err)
+	var unlocked []UnlockedAchievement
+	for rows.Next() {
+		var u UnlockedAchievement
+		if err := rows.Scan(&u.AchievementID, &u.RewardType); err != nil {
+			return nil, fmt.Errorf("scan unlocked achievement: %w", err)
+		}
+		unlocked = append(unlocked, u)
 	}
-	return count, nil
-}
-
-// GetStickersCollectedCount получает количество собранных стикеров
-func (s *Store) GetStickersCollectedCount(ctx context.Context, childProfileID string) (int, error) {
-	var count int
-	query := `
-		SELECT COUNT(DISTINCT ca.achievement_id)
-		FROM child_achievements ca
-		JOIN achievements a ON ca.achievement_id = a.id
-		WHERE ca.child_profile_id = $1
-		  AND ca.is_unlocked = TRUE
-		  AND a.reward_type = 'sticker'
-	`
-	err := s.DB.QueryRowContext(ctx, query, childProfileID).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("get stickers collected count: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate unlocked achievements: %w", err)
 	}
-	return count, nil
-}
 
-// GetErrorsFoundCount получает количество найденных ошибок во всех проверках
-func (s *Store) GetErrorsFoundCount(ctx context.Context, childProfileID string) (int, error) {
-	var count int
-	query := `
-		SELECT COUNT(*)
-		FROM attempts
-		WHERE child_profile_id = $1
-		  AND attempt_type = 'check'
-		  AND status = 'completed'
-		  AND is_correct = false
-		  AND has_errors = true
-	`
-	err := s.DB.QueryRowContext(ctx, query, childProfileID).Scan(&count)
-	if err != nil {
-		return 0, fmt.Errorf("get errors found count: %w", err)
-	}
-	return count, nil
+	return unlocked, nil
 }
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+)
+
+// createCounterAchievement creates an achievement with its own requirement type
+// so seeded achievements don't interfere with assertions
+func createCounterAchievement(t *testing.T, db *sql.DB, id, requirementType string, value int, rewardType string) {
+	t.Helper()
+
+	query := `
+		INSERT INTO achievements (id, type, title, description, icon, requirement_type, requirement_value,
+		                          reward_type, reward_name, reward_amount)
+		VALUES ($1, 'test', 'Test', 'Test achievement', '🏆', $2, $3, $4, 'Test reward', 0)
+	`
+	if _, err := db.Exec(query, id, requirementType, value, rewardType); err != nil {
+		t.Fatalf("failed to create test achievement: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM achievements WHERE id = $1`, id); err != nil {
+			t.Logf("Warning: failed to cleanup test achievement: %v", err)
+		}
+	})
+}
+
+func TestApplyAchievementEvent_ExactlyOnce(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("engine_once"), 0)
+	requirementType := testID("test_counter")
+	achievementID := testID("test_engine_two")
+	createCounterAchievement(t, db, achievementID, requirementType, 2, "badge")
+
+	update := []AchievementCounterUpdate{{RequirementType: requirementType, Delta: 1}}
+
+	// Первое событие: прогресс 1/2
+	unlocked, err := s.ApplyAchievementEvent(ctx, childID, "event-1", update, 50)
+	if err != nil || len(unlocked) != 0 {
+		t.Fatalf("first event: unlocked = %+v, err = %v; want none", unlocked, err)
+	}
+
+	// Второе событие параллельно с повторами: разблокировка и XP ровно один раз
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	total := 0
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlocked, err := s.ApplyAchievementEvent(ctx, childID, "event-2", update, 50)
+			if err != nil {
+				t.Errorf("ApplyAchievementEvent() error = %v", err)
+				return
+			}
+			mu.Lock()
+			total += len(unlocked)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if total != 1 {
+		t.Errorf("unlocks = %d, want exactly 1", total)
+	}
+
+	// Новое событие после разблокировки не разблокирует повторно
+	if unlocked, err := s.ApplyAchievementEvent(ctx, childID, "event-3", update, 50); err != nil || len(unlocked) != 0 {
+		t.Errorf("event after unlock: unlocked = %+v, err = %v; want none", unlocked, err)
+	}
+
+	_, xp := getBalances(t, db, childID)
+	if xp != 50 {
+		t.Errorf("xp_total = %d, want 50", xp)
+	}
+
+	var progress int
+	var isUnlocked bool
+	query := `SELECT current_progress, is_unlocked FROM child_achievements WHERE child_profile_id = $1 AND achievement_id = $2`
+	if err := db.QueryRow(query, childID, achievementID).Scan(&progress, &isUnlocked); err != nil {
+		t.Fatalf("failed to get progress: %v", err)
+	}
+	if !isUnlocked || progress != 2 {
+		t.Errorf("progress = %d, unlocked = %v; want 2, true", progress, isUnlocked)
+	}
+}
+
+func TestApplyAchievementEvent_GaugeAndStickerCascade(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("engine_gauge"), 0)
+	requirementType := testID("test_gauge")
+	stickerID := testID("test_engine_sticker")
+	createCounterAchievement(t, db, stickerID, requirementType, 3, "sticker")
+
+	// Показатель задаётся значением, а не приращением
+	gauge := func(v int) []AchievementCounterUpdate {
+		return []AchievementCounterUpdate{{RequirementType: requirementType, Value: v, IsGauge: true}}
+	}
+	if unlocked, err := s.ApplyAchievementEvent(ctx, childID, "", gauge(2), 0); err != nil || len(unlocked) != 0 {
+		t.Fatalf("gauge 2: unlocked = %+v, err = %v; want none", unlocked, err)
+	}
+
+	var stickersBefore int
+	counterQuery := `SELECT COALESCE((SELECT value FROM child_achievement_counters WHERE child_profile_id = $1 AND requirement_type = $2), 0)`
+	if err := db.QueryRow(counterQuery, childID, RequirementStickersCollected).Scan(&stickersBefore); err != nil {
+		t.Fatalf("failed to get stickers counter: %v", err)
+	}
+
+	unlocked, err := s.ApplyAchievementEvent(ctx, childID, "", gauge(3), 0)
+	if err != nil {
+		t.Fatalf("ApplyAchievementEvent() error = %v", err)
+	}
+	found := false
+	for _, u := range unlocked {
+		if u.AchievementID == stickerID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("unlocked = %+v, want %s", unlocked, stickerID)
+	}
+
+	var stickersAfter int
+	if err := db.QueryRow(counterQuery, childID, RequirementStickersCollected).Scan(&stickersAfter); err != nil {
+		t.Fatalf("failed to get stickers counter: %v", err)
+	}
+	if stickersAfter != stickersBefore+1 {
+		t.Errorf("stickers_collected = %d, want %d", stickersAfter, stickersBefore+1)
+	}
+
+	// Сброс показателя не отбирает разблокированное
+	if _, err := s.ApplyAchievementEvent(ctx, childID, "", gauge(1), 0); err != nil {
+		t.Fatalf("ApplyAchievementEvent() error = %v", err)
+	}
+	var isUnlocked bool
+	if err := db.QueryRow(`SELECT is_unlocked FROM child_achievements WHERE child_profile_id = $1 AND achievement_id = $2`,
+		childID, stickerID).Scan(&isUnlocked); err != nil {
+		t.Fatalf("failed to get progress: %v", err)
+	}
+	if !isUnlocked {
+		t.Error("achievement must stay unlocked after gauge reset")
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"time"
 
+	"child-bot/api/internal/domain"
 	"child-bot/api/internal/llm/types"
+	"child-bot/api/internal/reward"
 
 	"github.com/google/uuid"
 )
@@ -39,6 +41,8 @@ type Attempt struct {
 	TimeSpentSeconds sql.NullInt64
 	IsCorrect        sql.NullBool
 	HasErrors        sql.NullBool
+	ParentAttemptID  uuid.NullUUID // страница домашки, если попытка — задача со страницы
+	PagePosition     sql.NullInt64 // порядковый номер задачи на странице
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 	CompletedAt      sql.NullTime
@@ -65,15 +69,15 @@ func (s *AttemptStore) CreateAttempt(ctx context.Context, childProfileID uuid.UU
 	return attempt.ID, nil
 }
 
-// UpdateTaskImage обновляет изображение задания
+// UpdateTaskImage обновляет изображение задания и его отпечаток (для узнавания повторов)
 func (s *AttemptStore) UpdateTaskImage(ctx context.Context, attemptID uuid.UUID, imageURL string) error {
 	query := `
 		UPDATE attempts
-		SET task_image_url = $1, updated_at = NOW()
-		WHERE id = $2
+		SET task_image_url = $1, image_hash = NULLIF($2, ''), updated_at = NOW()
+		WHERE id = $3
 	`
 
-	result, err := s.db.ExecContext(ctx, query, imageURL, attemptID)
+	result, err := s.db.ExecContext(ctx, query, imageURL, reward.ImageHash(imageURL), attemptID)
 	if err != nil {
 		return fmt.Errorf("failed to update task image: %w", err)
 	}
@@ -115,6 +119,30 @@ func (s *AttemptStore) UpdateAnswerImage(ctx context.Context, attemptID uuid.UUI
 	return nil
 }
 
+// ClaimCheck переводит задачу в processing перед проверкой решения одним условным UPDATE.
+// Не забирает уже решённую задачу и задачу, которая проверяется сейчас (если проверка
+// не зависла дольше staleBefore). Возвращает false, если забрать не удалось.
+func (s *AttemptStore) ClaimCheck(ctx context.Context, attemptID uuid.UUID, staleBefore time.Time) (bool, error) {
+	query := `
+		UPDATE attempts
+		SET status = 'processing', updated_at = NOW()
+		WHERE id = $1
+		  AND is_correct IS NOT TRUE
+		  AND (status <> 'processing' OR updated_at < $2)
+	`
+
+	result, err := s.db.ExecContext(ctx, query, attemptID, staleBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim check: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows == 1, nil
+}
+
 // UpdateStatus обновляет статус попытки
 func (s *AttemptStore) UpdateStatus(ctx context.Context, attemptID uuid.UUID, status string) error {
 	query := `
@@ -161,7 +189,7 @@ func (s *AttemptStore) SaveDetectResult(ctx context.Context, attemptID uuid.UUID
 	return nil
 }
 
-// SaveParseResult сохраняет результат Parse
+// SaveParseResult сохраняет результат Parse и отпечаток распознанного условия
 func (s *AttemptStore) SaveParseResult(ctx context.Context, attemptID uuid.UUID, result *types.ParseResponse) error {
 	data, err := json.Marshal(result)
 	if err != nil {
@@ -170,11 +198,11 @@ func (s *AttemptStore) SaveParseResult(ctx context.Context, attemptID uuid.UUID,
 
 	query := `
 		UPDATE attempts
-		SET parse_result = $1, updated_at = NOW()
-		WHERE id = $2
+		SET parse_result = $1, task_hash = NULLIF($2, ''), updated_at = NOW()
+		WHERE id = $3
 	`
 
-	_, err = s.db.ExecContext(ctx, query, data, attemptID)
+	_, err = s.db.ExecContext(ctx, query, data, reward.TaskHash(result.Task.TaskTextClean), attemptID)
 	if err != nil {
 		return fmt.Errorf("failed to save parse result: %w", err)
 	}
@@ -213,9 +241,11 @@ func (s *AttemptStore) SaveCheckResult(ctx context.Context, attemptID uuid.UUID,
 	isCorrect := result.Decision == types.CheckDecisionCorrect
 	hasErrors := result.Decision != types.CheckDecisionCorrect
 
+	// Результат проверки раскрывает ответ: подсказки к этой задаче больше не дают XP
 	query := `
 		UPDATE attempts
 		SET check_result = $1, is_correct = $2, has_errors = $3,
+		    answer_shown_at = COALESCE(answer_shown_at, NOW()),
 		    status = 'completed', completed_at = NOW(), updated_at = NOW()
 		WHERE id = $4
 	`
@@ -228,6 +258,74 @@ func (s *AttemptStore) SaveCheckResult(ctx context.Context, attemptID uuid.UUID,
 	return nil
 }
 
+// SaveMistakesResult сохраняет разбор ошибок и сбрасывает индекс текущей ошибки
+func (s *AttemptStore) SaveMistakesResult(ctx context.Context, attemptID uuid.UUID, result *domain.MistakesResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mistakes result: %w", err)
+	}
+
+	query := `
+		UPDATE attempts
+		SET mistakes_result = $1, current_mistake_index = 0, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	_, err = s.db.ExecContext(ctx, query, data, attemptID)
+	if err != nil {
+		return fmt.Errorf("failed to save mistakes result: %w", err)
+	}
+
+	return nil
+}
+
+// GetMistakesState получает сохранённый разбор ошибок и индекс текущей ошибки.
+// Возвращает nil, если разбор ещё не строился.
+func (s *AttemptStore) GetMistakesState(ctx context.Context, attemptID uuid.UUID) (*domain.MistakesResult, int, error) {
+	query := `
+		SELECT mistakes_result, current_mistake_index
+		FROM attempts
+		WHERE id = $1
+	`
+
+	var data []byte
+	var index int
+	err := s.db.QueryRowContext(ctx, query, attemptID).Scan(&data, &index)
+	if err == sql.ErrNoRows {
+		return nil, 0, fmt.Errorf("attempt not found: %s", attemptID)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get mistakes state: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, index, nil
+	}
+
+	var result domain.MistakesResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, 0, fmt.Errorf("failed to unmarshal mistakes result: %w", err)
+	}
+
+	return &result, index, nil
+}
+
+// SetCurrentMistakeIndex обновляет индекс текущей ошибки
+func (s *AttemptStore) SetCurrentMistakeIndex(ctx context.Context, attemptID uuid.UUID, index int) error {
+	query := `
+		UPDATE attempts
+		SET current_mistake_index = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	_, err := s.db.ExecContext(ctx, query, index, attemptID)
+	if err != nil {
+		return fmt.Errorf("failed to update current mistake index: %w", err)
+	}
+
+	return nil
+}
+
 // IncrementHintUsed увеличивает счётчик использованных подсказок и обновляет индекс текущей подсказки
 func (s *AttemptStore) IncrementHintUsed(ctx context.Context, attemptID uuid.UUID, newHintIndex int) error {
 	query := `
@@ -249,6 +347,19 @@ func (s *AttemptStore) IncrementHintUsed(ctx context.Context, attemptID uuid.UUI
 	return nil
 }
 
+// MarkAnswerShown отмечает, что ответ на задачу попытки показан (например, последней подсказкой)
+func (s *AttemptStore) MarkAnswerShown(ctx context.Context, attemptID uuid.UUID) error {
+	query := `
+		UPDATE attempts
+		SET answer_shown_at = COALESCE(answer_shown_at, NOW()), updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := s.db.ExecContext(ctx, query, attemptID); err != nil {
+		return fmt.Errorf("failed to mark answer shown: %w", err)
+	}
+	return nil
+}
+
 // GetAttempt получает попытку по ID
 func (s *AttemptStore) GetAttempt(ctx context.Context, attemptID uuid.UUID) (*Attempt, error) {
 	query := `
@@ -256,7 +367,7 @@ func (s *AttemptStore) GetAttempt(ctx context.Context, attemptID uuid.UUID) (*At
 		       task_image_url, answer_image_url,
 		       detect_result, parse_result, hints_result, check_result,
 		       current_hint_index, hints_used, time_spent_seconds,
-		       is_correct, has_errors,
+		       is_correct, has_errors, parent_attempt_id, page_position,
 		       created_at, updated_at, completed_at
 		FROM attempts
 		WHERE id = $1
@@ -279,6 +390,8 @@ func (s *AttemptStore) GetAttempt(ctx context.Context, attemptID uuid.UUID) (*At
 		&attempt.TimeSpentSeconds,
 		&attempt.IsCorrect,
 		&attempt.HasErrors,
+		&attempt.ParentAttemptID,
+		&attempt.PagePosition,
 		&attempt.CreatedAt,
 		&attempt.UpdatedAt,
 		&attempt.CompletedAt,
@@ -300,7 +413,7 @@ func (s *AttemptStore) GetUnfinishedAttempt(ctx context.Context, childProfileID
 		       task_image_url, answer_image_url,
 		       detect_result, parse_result, hints_result, check_result,
 		       current_hint_index, hints_used, time_spent_seconds,
-		       is_correct, has_errors,
+		       is_correct, has_errors, parent_attempt_id, page_position,
 		       created_at, updated_at, completed_at
 		FROM attempts
 		WHERE child_profile_id = $1 AND status IN ('created', 'processing')
@@ -325,6 +438,8 @@ func (s *AttemptStore) GetUnfinishedAttempt(ctx context.Context, childProfileID
 		&attempt.TimeSpentSeconds,
 		&attempt.IsCorrect,
 		&attempt.HasErrors,
+		&attempt.ParentAttemptID,
+		&attempt.PagePosition,
 		&attempt.CreatedAt,
 		&attempt.UpdatedAt,
 		&attempt.CompletedAt,
@@ -346,7 +461,7 @@ func (s *AttemptStore) GetRecentAttempts(ctx context.Context, childProfileID uui
 		       task_image_url, answer_image_url,
 		       detect_result, parse_result, hints_result, check_result,
 		       current_hint_index, hints_used, time_spent_seconds,
-		       is_correct, has_errors,
+		       is_correct, has_errors, parent_attempt_id, page_position,
 		       created_at, updated_at, completed_at
 		FROM attempts
 		WHERE child_profile_id = $1
@@ -379,6 +494,8 @@ func (s *AttemptStore) GetRecentAttempts(ctx context.Context, childProfileID uui
 			&attempt.TimeSpentSeconds,
 			&attempt.IsCorrect,
 			&attempt.HasErrors,
+			&attempt.ParentAttemptID,
+			&attempt.PagePosition,
 			&attempt.CreatedAt,
 			&attempt.UpdatedAt,
 			&attempt.CompletedAt,
@@ -432,3 +549,465 @@ func (s *AttemptStore) DeleteAttempt(ctx context.Context, attemptID uuid.UUID) e
 
 	return nil
 }
+
+// AttemptMessage сообщение диалога по попытке
+type AttemptMessage struct {
+	ID        int64
+	AttemptID uuid.UUID
+	Role      string // child или tutor
+	Content   string
+	Guarded   bool
+	CreatedAt time.Time
+}
+
+// AddAttemptMessage добавляет сообщение в диалог по попытке
+func (s *AttemptStore) AddAttemptMessage(ctx context.Context, attemptID uuid.UUID, role, content string, guarded bool) (*AttemptMessage, error) {
+	return insertAttemptMessage(ctx, s.db, attemptID, role, content, guarded)
+}
+
+// insertAttemptMessage добавляет сообщение в БД или в транзакции
+func insertAttemptMessage(ctx context.Context, db rowQuerier, attemptID uuid.UUID, role, content string, guarded bool) (*AttemptMessage, error) {
+	query := `
+		INSERT INTO attempt_messages (attempt_id, role, content, guarded)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	msg := AttemptMessage{
+		AttemptID: attemptID,
+		Role:      role,
+		Content:   content,
+		Guarded:   guarded,
+	}
+	err := db.QueryRowContext(ctx, query, attemptID, role, content, guarded).Scan(&msg.ID, &msg.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add attempt message: %w", err)
+	}
+
+	return &msg, nil
+}
+
+// ReserveDialogQuestion занимает слот вопроса диалога до запроса к LLM.
+// Счётчик растёт одним условным UPDATE, поэтому параллельные вопросы не превысят maxQuestions;
+// при исчерпанном лимите возвращает domain.ErrDialogLimitReached.
+// Возвращает число вопросов с учётом этого.
+func (s *AttemptStore) ReserveDialogQuestion(ctx context.Context, attemptID uuid.UUID, maxQuestions int) (int, error) {
+	query := `
+		UPDATE attempts
+		SET dialog_questions = dialog_questions + 1
+		WHERE id = $1 AND dialog_questions < $2
+		RETURNING dialog_questions
+	`
+	var questions int
+	err := s.db.QueryRowContext(ctx, query, attemptID, maxQuestions).Scan(&questions)
+	if err == sql.ErrNoRows {
+		return 0, domain.ErrDialogLimitReached
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve dialog question: %w", err)
+	}
+	return questions, nil
+}
+
+// ReleaseDialogQuestion возвращает слот вопроса, если ответ наставника получить не удалось
+func (s *AttemptStore) ReleaseDialogQuestion(ctx context.Context, attemptID uuid.UUID) error {
+	query := `
+		UPDATE attempts
+		SET dialog_questions = dialog_questions - 1
+		WHERE id = $1 AND dialog_questions > 0
+	`
+	if _, err := s.db.ExecContext(ctx, query, attemptID); err != nil {
+		return fmt.Errorf("failed to release dialog question: %w", err)
+	}
+	return nil
+}
+
+// AddDialogExchange записывает вопрос ребёнка и ответ наставника одной транзакцией.
+// Возвращает сохранённый ответ наставника.
+func (s *AttemptStore) AddDialogExchange(ctx context.Context, attemptID uuid.UUID, question, answer string, guarded bool) (*AttemptMessage, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := insertAttemptMessage(ctx, tx, attemptID, domain.DialogRoleChild, question, false); err != nil {
+		return nil, err
+	}
+	msg, err := insertAttemptMessage(ctx, tx, attemptID, domain.DialogRoleTutor, answer, guarded)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return msg, nil
+}
+
+// GetAttemptMessages получает диалог по попытке в хронологическом порядке
+func (s *AttemptStore) GetAttemptMessages(ctx context.Context, attemptID uuid.UUID) ([]AttemptMessage, error) {
+	query := `
+		SELECT id, attempt_id, role, content, guarded, created_at
+		FROM attempt_messages
+		WHERE attempt_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, attemptID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attempt messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []AttemptMessage
+	for rows.Next() {
+		var msg AttemptMessage
+		if err := rows.Scan(&msg.ID, &msg.AttemptID, &msg.Role, &msg.Content, &msg.Guarded, &msg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attempt message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return messages, nil
+}
+
+// HintFeedback оценка подсказки ребёнком
+type HintFeedback struct {
+	AttemptID      uuid.UUID
+	ChildProfileID uuid.UUID
+	HintIndex      int
+	HintLevel      string
+	TemplateID     string
+	Rating         string
+	Comment        sql.NullString
+}
+
+// SaveHintFeedback сохраняет оценку подсказки: пишет её в журнал hint_ratings
+// и обновляет последнюю оценку в hint_feedback
+func (s *AttemptStore) SaveHintFeedback(ctx context.Context, fb HintFeedback) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO hint_feedback (
+			attempt_id, child_profile_id, hint_index, hint_level, template_id, rating, comment
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (attempt_id, hint_index) DO UPDATE
+		SET rating = EXCLUDED.rating,
+		    comment = EXCLUDED.comment,
+		    updated_at = NOW()
+	`
+	if _, err := tx.ExecContext(ctx, query,
+		fb.AttemptID, fb.ChildProfileID, fb.HintIndex, fb.HintLevel, fb.TemplateID, fb.Rating, fb.Comment,
+	); err != nil {
+		return fmt.Errorf("failed to save hint feedback: %w", err)
+	}
+
+	if err := insertHintRating(ctx, tx, fb, false); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReserveHintRegeneration засчитывает перегенерацию подсказки вместе с оценкой.
+// Счётчик растёт одним условным UPDATE, поэтому параллельные запросы не превысят maxRegenerations;
+// при исчерпанном лимите возвращает domain.ErrRegenerationLimitReached.
+// Возвращает число перегенераций с учётом этой.
+func (s *AttemptStore) ReserveHintRegeneration(ctx context.Context, fb HintFeedback, maxRegenerations int) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO hint_feedback (
+			attempt_id, child_profile_id, hint_index, hint_level, template_id, rating, comment, regenerations
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, 1)
+		ON CONFLICT (attempt_id, hint_index) DO UPDATE
+		SET rating = EXCLUDED.rating,
+		    comment = EXCLUDED.comment,
+		    regenerations = hint_feedback.regenerations + 1,
+		    updated_at = NOW()
+		WHERE hint_feedback.regenerations < $8
+		RETURNING regenerations
+	`
+	var regenerations int
+	err = tx.QueryRowContext(ctx, query,
+		fb.AttemptID, fb.ChildProfileID, fb.HintIndex, fb.HintLevel, fb.TemplateID, fb.Rating, fb.Comment, maxRegenerations,
+	).Scan(&regenerations)
+	if err == sql.ErrNoRows {
+		return 0, domain.ErrRegenerationLimitReached
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve hint regeneration: %w", err)
+	}
+
+	if err := insertHintRating(ctx, tx, fb, true); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit hint regeneration: %w", err)
+	}
+	return regenerations, nil
+}
+
+// ReleaseHintRegeneration возвращает перегенерацию, если новую подсказку получить не удалось.
+// Оценка в журнале остаётся, но больше не считается запросом перегенерации.
+func (s *AttemptStore) ReleaseHintRegeneration(ctx context.Context, attemptID uuid.UUID, hintIndex int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE hint_feedback
+		SET regenerations = regenerations - 1, updated_at = NOW()
+		WHERE attempt_id = $1 AND hint_index = $2 AND regenerations > 0
+	`, attemptID, hintIndex)
+	if err != nil {
+		return fmt.Errorf("failed to release hint regeneration: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE hint_ratings
+		SET regenerate = FALSE
+		WHERE id = (
+			SELECT id FROM hint_ratings
+			WHERE attempt_id = $1 AND hint_index = $2 AND regenerate
+			ORDER BY id DESC
+			LIMIT 1
+		)
+	`, attemptID, hintIndex)
+	if err != nil {
+		return fmt.Errorf("failed to unmark released regeneration: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// insertHintRating добавляет оценку в журнал hint_ratings
+func insertHintRating(ctx context.Context, tx *sql.Tx, fb HintFeedback, regenerate bool) error {
+	query := `
+		INSERT INTO hint_ratings (
+			attempt_id, child_profile_id, hint_index, hint_level, template_id, rating, comment, regenerate
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	if _, err := tx.ExecContext(ctx, query,
+		fb.AttemptID, fb.ChildProfileID, fb.HintIndex, fb.HintLevel, fb.TemplateID, fb.Rating, fb.Comment, regenerate,
+	); err != nil {
+		return fmt.Errorf("failed to log hint rating: %w", err)
+	}
+	return nil
+}
+
+// HintHistoryStats статистика прошлых попыток ребёнка по шаблону или типу задачи
+type HintHistoryStats struct {
+	HelpAttempts   int
+	AvgHintsUsed   float64
+	L3Share        float64 // доля help попыток, где понадобилась третья подсказка
+	CheckAttempts  int
+	CorrectRate    float64
+	AvgTimeSeconds float64
+}
+
+// GetHintHistoryStats считает статистику последних попыток ребёнка, у которых
+// ped_keys.<key> первого пункта PARSE равен value. Текущая попытка не учитывается.
+func (s *AttemptStore) GetHintHistoryStats(ctx context.Context, childProfileID, excludeAttemptID uuid.UUID, key, value string, limit int) (*HintHistoryStats, error) {
+	query := `
+		WITH recent AS (
+			SELECT attempt_type, hints_used, is_correct,
+			       COALESCE(time_spent_seconds, EXTRACT(EPOCH FROM (completed_at - created_at)))::FLOAT8 AS spent
+			FROM attempts
+			WHERE child_profile_id = $1
+			  AND id <> $2
+			  AND attempt_type <> 'page'
+			  AND parse_result->'items'->0->'ped_keys'->>$3 = $4
+			ORDER BY created_at DESC
+			LIMIT $5
+		)
+		SELECT
+			COUNT(*) FILTER (WHERE attempt_type = 'help'),
+			COALESCE(AVG(hints_used) FILTER (WHERE attempt_type = 'help'), 0),
+			COALESCE(AVG(CASE WHEN hints_used >= 3 THEN 1.0 ELSE 0.0 END) FILTER (WHERE attempt_type = 'help'), 0),
+			COUNT(*) FILTER (WHERE attempt_type = 'check' AND is_correct IS NOT NULL),
+			COALESCE(AVG(CASE WHEN is_correct THEN 1.0 ELSE 0.0 END) FILTER (WHERE attempt_type = 'check' AND is_correct IS NOT NULL), 0),
+			COALESCE(AVG(spent), 0)
+		FROM recent
+	`
+
+	var stats HintHistoryStats
+	err := s.db.QueryRowContext(ctx, query, childProfileID, excludeAttemptID, key, value, limit).Scan(
+		&stats.HelpAttempts,
+		&stats.AvgHintsUsed,
+		&stats.L3Share,
+		&stats.CheckAttempts,
+		&stats.CorrectRate,
+		&stats.AvgTimeSeconds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hint history stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// SaveHintPolicy сохраняет адаптивную политику подсказок попытки
+func (s *AttemptStore) SaveHintPolicy(ctx context.Context, attemptID uuid.UUID, policy *domain.AdaptiveHintPolicy) error {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hint policy: %w", err)
+	}
+
+	query := `
+		UPDATE attempts
+		SET hint_policy = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	_, err = s.db.ExecContext(ctx, query, data, attemptID)
+	if err != nil {
+		return fmt.Errorf("failed to save hint policy: %w", err)
+	}
+
+	return nil
+}
+
+// GetHintPolicy получает адаптивную политику подсказок (nil если не рассчитывалась)
+func (s *AttemptStore) GetHintPolicy(ctx context.Context, attemptID uuid.UUID) (*domain.AdaptiveHintPolicy, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT hint_policy FROM attempts WHERE id = $1`, attemptID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hint policy: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var policy domain.AdaptiveHintPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hint policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// PageTask задача со страницы домашки (дочерняя попытка)
+type PageTask struct {
+	ID          uuid.UUID
+	Position    int
+	Status      string
+	ParseResult []byte
+	HintsUsed   int
+	IsCorrect   sql.NullBool
+	HasCheck    bool
+	UpdatedAt   time.Time
+	CompletedAt sql.NullTime
+}
+
+// CreatePageTask создаёт попытку-задачу под страницей: изображение и результаты
+// Detect/Parse уже известны, поэтому задача сразу готова к подсказкам и проверке
+func (s *AttemptStore) CreatePageTask(ctx context.Context, page *Attempt, position int, detect *types.DetectResponse, parse *types.ParseResponse) (uuid.UUID, error) {
+	detectData, err := json.Marshal(detect)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to marshal detect result: %w", err)
+	}
+	parseData, err := json.Marshal(parse)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to marshal parse result: %w", err)
+	}
+
+	// Фото у задач страницы общее, поэтому повтор узнаётся только по условию
+	query := `
+		INSERT INTO attempts (
+			child_profile_id, attempt_type, status, task_image_url,
+			detect_result, parse_result, parent_attempt_id, page_position, task_hash
+		) VALUES ($1, 'help', 'created', $2, $3, $4, $5, $6, NULLIF($7, ''))
+		RETURNING id
+	`
+
+	var id uuid.UUID
+	err = s.db.QueryRowContext(ctx, query,
+		page.ChildProfileID,
+		page.TaskImageURL,
+		detectData,
+		parseData,
+		page.ID,
+		position,
+		reward.TaskHash(parse.Task.TaskTextClean),
+	).Scan(&id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create page task: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetPageTasks получает задачи страницы по порядку
+func (s *AttemptStore) GetPageTasks(ctx context.Context, pageID uuid.UUID) ([]PageTask, error) {
+	query := `
+		SELECT id, COALESCE(page_position, 0), status, parse_result, hints_used,
+		       is_correct, check_result IS NOT NULL, updated_at, completed_at
+		FROM attempts
+		WHERE parent_attempt_id = $1
+		ORDER BY page_position ASC, created_at ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []PageTask
+	for rows.Next() {
+		var t PageTask
+		if err := rows.Scan(&t.ID, &t.Position, &t.Status, &t.ParseResult, &t.HintsUsed,
+			&t.IsCorrect, &t.HasCheck, &t.UpdatedAt, &t.CompletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan page task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// MarkPageCompleted отмечает время, когда решены все задачи страницы
+func (s *AttemptStore) MarkPageCompleted(ctx context.Context, pageID uuid.UUID) error {
+	query := `
+		UPDATE attempts
+		SET status = 'completed',
+		    completed_at = COALESCE(completed_at, NOW()),
+		    updated_at = NOW()
+		WHERE id = $1 AND attempt_type = 'page'
+	`
+
+	_, err := s.db.ExecContext(ctx, query, pageID)
+	if err != nil {
+		return fmt.Errorf("failed to mark page completed: %w", err)
+	}
+
+	return nil
+}
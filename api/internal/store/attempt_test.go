@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestHintRegeneration_LimitAndRatingsLog(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("hint_regen"), 0)
+	var attemptID uuid.UUID
+	if err := db.QueryRow(`
+		INSERT INTO attempts (child_profile_id, attempt_type, status)
+		VALUES ($1, 'help', 'completed')
+		RETURNING id
+	`, childID).Scan(&attemptID); err != nil {
+		t.Fatalf("failed to create attempt: %v", err)
+	}
+
+	fb := HintFeedback{
+		AttemptID:      attemptID,
+		ChildProfileID: uuid.MustParse(childID),
+		HintIndex:      0,
+		HintLevel:      "L1",
+		Rating:         domain.HintRatingUnclear,
+		Comment:        sql.NullString{String: "не понял", Valid: true},
+	}
+
+	if err := s.Attempts.SaveHintFeedback(ctx, fb); err != nil {
+		t.Fatalf("SaveHintFeedback() error = %v", err)
+	}
+	for want := 1; want <= 2; want++ {
+		got, err := s.Attempts.ReserveHintRegeneration(ctx, fb, 2)
+		if err != nil || got != want {
+			t.Fatalf("ReserveHintRegeneration() = %d, %v; want %d", got, err, want)
+		}
+	}
+	if _, err := s.Attempts.ReserveHintRegeneration(ctx, fb, 2); !errors.Is(err, domain.ErrRegenerationLimitReached) {
+		t.Fatalf("ReserveHintRegeneration() over limit error = %v, want ErrRegenerationLimitReached", err)
+	}
+
+	// Неудачная перегенерация возвращается
+	if err := s.Attempts.ReleaseHintRegeneration(ctx, attemptID, 0); err != nil {
+		t.Fatalf("ReleaseHintRegeneration() error = %v", err)
+	}
+	if got, err := s.Attempts.ReserveHintRegeneration(ctx, fb, 2); err != nil || got != 2 {
+		t.Fatalf("ReserveHintRegeneration() after release = %d, %v; want 2", got, err)
+	}
+
+	// Повторная оценка не затирает предыдущие; отклонённая по лимиту в журнал не попала
+	fb.Rating = domain.HintRatingHelpful
+	if err := s.Attempts.SaveHintFeedback(ctx, fb); err != nil {
+		t.Fatalf("SaveHintFeedback() error = %v", err)
+	}
+	var total, regenerate int
+	if err := db.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE regenerate)
+		FROM hint_ratings WHERE attempt_id = $1
+	`, attemptID).Scan(&total, &regenerate); err != nil {
+		t.Fatalf("failed to count hint ratings: %v", err)
+	}
+	// Возвращённая перегенерация в журнале — обычная оценка
+	if total != 5 || regenerate != 2 {
+		t.Errorf("hint ratings = %d (regenerate %d), want 5 (regenerate 2)", total, regenerate)
+	}
+
+	var rating string
+	db.QueryRow(`SELECT rating FROM hint_feedback WHERE attempt_id = $1 AND hint_index = 0`, attemptID).Scan(&rating)
+	if rating != domain.HintRatingHelpful {
+		t.Errorf("latest rating = %q, want %q", rating, domain.HintRatingHelpful)
+	}
+}
+
+func TestClaimCheck_OnceUntilDoneOrStale(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("claim_check"), 0)
+	var attemptID uuid.UUID
+	if err := db.QueryRow(`
+		INSERT INTO attempts (child_profile_id, attempt_type, status)
+		VALUES ($1, 'help', 'completed')
+		RETURNING id
+	`, childID).Scan(&attemptID); err != nil {
+		t.Fatalf("failed to create attempt: %v", err)
+	}
+
+	staleBefore := time.Now().Add(-10 * time.Minute)
+	if ok, err := s.Attempts.ClaimCheck(ctx, attemptID, staleBefore); err != nil || !ok {
+		t.Fatalf("ClaimCheck() = %v, %v; want claimed", ok, err)
+	}
+	if ok, _ := s.Attempts.ClaimCheck(ctx, attemptID, staleBefore); ok {
+		t.Error("ClaimCheck() claimed a task that is being checked")
+	}
+	// Зависшую проверку можно запустить заново
+	if ok, _ := s.Attempts.ClaimCheck(ctx, attemptID, time.Now().Add(time.Minute)); !ok {
+		t.Error("ClaimCheck() did not reclaim a stale check")
+	}
+
+	db.Exec(`UPDATE attempts SET status = 'completed', is_correct = TRUE WHERE id = $1`, attemptID)
+	if ok, _ := s.Attempts.ClaimCheck(ctx, attemptID, time.Now().Add(time.Minute)); ok {
+		t.Error("ClaimCheck() claimed a solved task")
+	}
+}
+
+func TestDialogQuestions_ReserveAndRelease(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("dialog_slots"), 0)
+	var attemptID uuid.UUID
+	if err := db.QueryRow(`
+		INSERT INTO attempts (child_profile_id, attempt_type, status)
+		VALUES ($1, 'help', 'completed')
+		RETURNING id
+	`, childID).Scan(&attemptID); err != nil {
+		t.Fatalf("failed to create attempt: %v", err)
+	}
+
+	for want := 1; want <= 2; want++ {
+		got, err := s.Attempts.ReserveDialogQuestion(ctx, attemptID, 2)
+		if err != nil || got != want {
+			t.Fatalf("ReserveDialogQuestion() = %d, %v; want %d", got, err, want)
+		}
+	}
+	if _, err := s.Attempts.ReserveDialogQuestion(ctx, attemptID, 2); !errors.Is(err, domain.ErrDialogLimitReached) {
+		t.Fatalf("ReserveDialogQuestion() over limit: error = %v, want ErrDialogLimitReached", err)
+	}
+
+	// Освобождённый слот можно занять снова
+	if err := s.Attempts.ReleaseDialogQuestion(ctx, attemptID); err != nil {
+		t.Fatalf("ReleaseDialogQuestion() error = %v", err)
+	}
+	if got, err := s.Attempts.ReserveDialogQuestion(ctx, attemptID, 2); err != nil || got != 2 {
+		t.Errorf("ReserveDialogQuestion() after release = %d, %v; want 2", got, err)
+	}
+
+	saved, err := s.Attempts.AddDialogExchange(ctx, attemptID, "Почему?", "Подумай о разрядах", false)
+	if err != nil || saved.Role != domain.DialogRoleTutor {
+		t.Fatalf("AddDialogExchange() = %+v, %v; want tutor message", saved, err)
+	}
+	messages, err := s.Attempts.GetAttemptMessages(ctx, attemptID)
+	if err != nil || len(messages) != 2 || messages[0].Role != domain.DialogRoleChild {
+		t.Errorf("GetAttemptMessages() = %+v, %v; want child question and tutor answer", messages, err)
+	}
+}
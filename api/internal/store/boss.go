@@ -0,0 +1,539 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"child-bot/api/internal/boss"
+	"child-bot/api/internal/damage"
+
+	"github.com/google/uuid"
+)
+
+// Статусы битвы с недельным боссом
+const (
+	BossBattleActive   = "active"
+	BossBattleDefeated = "defeated"
+	BossBattleExpired  = "expired" // неделя закончилась, босс не побеждён
+)
+
+// Типы наград за недельного босса
+const (
+	BossRewardCoins = "coins"
+	BossRewardXP    = "xp"
+	BossRewardItem  = "item" // предмет магазина в инвентарь
+)
+
+// BossConfig настройки недельного босса
+type BossConfig struct {
+	VillainID     string
+	DurationDays  int
+	RegenHPPerDay int
+	WeekOrder     int
+}
+
+// BossReward награда за победу над боссом
+type BossReward struct {
+	Type     string
+	ItemID   string // для item
+	ItemName string
+	Amount   int
+}
+
+// BossBattleRow битва ребёнка с недельным боссом
+type BossBattleRow struct {
+	ID             int64
+	ChildProfileID string
+	VillainID      string
+	WeekStart      string // YYYY-MM-DD, понедельник по календарю ребёнка
+	EndsOn         string // последний день битвы
+	Status         string
+	CurrentPhase   int
+	PhaseHP        int
+	TotalDamage    int
+	TasksCount     int
+	LastActiveOn   string // последний день с правильно решённой задачей (или начало битвы)
+	RegenThrough   string // пропуски до этого дня включительно уже восстановили HP
+	RegenTotal     int
+	StartedAt      time.Time
+	DefeatedAt     sql.NullTime
+}
+
+// BossDamageEventRow удар по боссу
+type BossDamageEventRow struct {
+	ID         int64
+	Phase      int
+	Damage     int
+	IsCritical bool
+	Bonuses    []byte // JSONB: [{code, label, damage}]
+	Subject    string
+	CreatedAt  time.Time
+}
+
+// BossHitInput правильно решённая задача, которая бьёт босса
+type BossHitInput struct {
+	BattleID      int64
+	AttemptID     uuid.UUID
+	Subject       string
+	TaskTypes     []string
+	Hit           damage.Breakdown
+	Today         string // YYYY-MM-DD по календарю ребёнка
+	RegenHPPerDay int
+}
+
+// BossHitResult итог удара по боссу
+type BossHitResult struct {
+	boss.Hit
+	Battle     *BossBattleRow
+	Matched    bool // задача подходит для текущей фазы
+	AlreadyHit bool // эта попытка уже била босса
+	Healed     int  // HP, восстановленное за пропуски перед ударом
+	Rewards    []BossReward
+	LeveledUp  bool
+}
+
+const bossBattleColumns = `
+	id, child_profile_id, villain_id,
+	to_char(week_start, 'YYYY-MM-DD'), to_char(ends_on, 'YYYY-MM-DD'),
+	status, current_phase, phase_hp, total_damage, tasks_count,
+	to_char(last_active_on, 'YYYY-MM-DD'), COALESCE(to_char(regen_through, 'YYYY-MM-DD'), ''),
+	regen_total, started_at, defeated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanBossBattle(row rowScanner) (*BossBattleRow, error) {
+	var b BossBattleRow
+	err := row.Scan(
+		&b.ID, &b.ChildProfileID, &b.VillainID,
+		&b.WeekStart, &b.EndsOn,
+		&b.Status, &b.CurrentPhase, &b.PhaseHP, &b.TotalDamage, &b.TasksCount,
+		&b.LastActiveOn, &b.RegenThrough,
+		&b.RegenTotal, &b.StartedAt, &b.DefeatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ListBossConfigs включённые недельные боссы в порядке смены по неделям
+func (s *Store) ListBossConfigs(ctx context.Context) ([]BossConfig, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT villain_id, duration_days, regen_hp_per_day, week_order
+		FROM boss_configs
+		WHERE is_enabled = TRUE
+		ORDER BY week_order
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query boss configs: %w", err)
+	}
+	defer rows.Close()
+
+	var configs []BossConfig
+	for rows.Next() {
+		var c BossConfig
+		if err := rows.Scan(&c.VillainID, &c.DurationDays, &c.RegenHPPerDay, &c.WeekOrder); err != nil {
+			return nil, fmt.Errorf("scan boss config: %w", err)
+		}
+		configs = append(configs, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate boss configs: %w", err)
+	}
+	return configs, nil
+}
+
+// GetBossConfig настройки босса; nil, если злодей не недельный босс
+func (s *Store) GetBossConfig(ctx context.Context, villainID string) (*BossConfig, error) {
+	var c BossConfig
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT villain_id, duration_days, regen_hp_per_day, week_order
+		FROM boss_configs
+		WHERE villain_id = $1
+	`, villainID).Scan(&c.VillainID, &c.DurationDays, &c.RegenHPPerDay, &c.WeekOrder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get boss config: %w", err)
+	}
+	return &c, nil
+}
+
+// GetBossPhases фазы босса по порядку
+func (s *Store) GetBossPhases(ctx context.Context, villainID string) ([]boss.Phase, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT phase, title, hp, COALESCE(required_subject, ''), COALESCE(required_task_type, '')
+		FROM boss_phases
+		WHERE villain_id = $1
+		ORDER BY phase
+	`, villainID)
+	if err != nil {
+		return nil, fmt.Errorf("query boss phases: %w", err)
+	}
+	defer rows.Close()
+
+	var phases []boss.Phase
+	for rows.Next() {
+		var p boss.Phase
+		if err := rows.Scan(&p.Number, &p.Title, &p.HP, &p.RequiredSubject, &p.RequiredTaskType); err != nil {
+			return nil, fmt.Errorf("scan boss phase: %w", err)
+		}
+		phases = append(phases, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate boss phases: %w", err)
+	}
+	return phases, nil
+}
+
+// GetBossRewards награды за победу над боссом
+func (s *Store) GetBossRewards(ctx context.Context, villainID string) ([]BossReward, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT br.reward_type, COALESCE(br.item_id, ''), COALESCE(si.name, ''), br.amount
+		FROM boss_rewards br
+		LEFT JOIN shop_items si ON si.id = br.item_id
+		WHERE br.villain_id = $1
+		ORDER BY br.id
+	`, villainID)
+	if err != nil {
+		return nil, fmt.Errorf("query boss rewards: %w", err)
+	}
+	defer rows.Close()
+
+	var rewards []BossReward
+	for rows.Next() {
+		var r BossReward
+		if err := rows.Scan(&r.Type, &r.ItemID, &r.ItemName, &r.Amount); err != nil {
+			return nil, fmt.Errorf("scan boss reward: %w", err)
+		}
+		rewards = append(rewards, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate boss rewards: %w", err)
+	}
+	return rewards, nil
+}
+
+// GetBossBattle битва ребёнка с боссом на неделе weekStart; nil, если её ещё нет
+func (s *Store) GetBossBattle(ctx context.Context, childProfileID, weekStart string) (*BossBattleRow, error) {
+	row := s.DB.QueryRowContext(ctx, `
+		SELECT `+bossBattleColumns+`
+		FROM boss_battles
+		WHERE child_profile_id = $1 AND week_start = $2
+	`, childProfileID, weekStart)
+	b, err := scanBossBattle(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get boss battle: %w", err)
+	}
+	return b, nil
+}
+
+// CreateBossBattle начинает битву с боссом на неделе weekStart (или возвращает уже начатую)
+func (s *Store) CreateBossBattle(ctx context.Context, childProfileID, villainID, weekStart, endsOn, today string, phaseHP int) (*BossBattleRow, error) {
+	_, err := s.DB.ExecContext(ctx, `
+		INSERT INTO boss_battles (child_profile_id, villain_id, week_start, ends_on, phase_hp, last_active_on)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (child_profile_id, week_start) DO NOTHING
+	`, childProfileID, villainID, weekStart, endsOn, phaseHP, today)
+	if err != nil {
+		return nil, fmt.Errorf("create boss battle: %w", err)
+	}
+	return s.GetBossBattle(ctx, childProfileID, weekStart)
+}
+
+// ExpireBossBattles завершает неудачные битвы ребёнка, у которых неделя закончилась до today
+func (s *Store) ExpireBossBattles(ctx context.Context, childProfileID, today string) error {
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE boss_battles
+		SET status = 'expired', updated_at = NOW()
+		WHERE child_profile_id = $1 AND status = 'active' AND ends_on < $2
+	`, childProfileID, today)
+	if err != nil {
+		return fmt.Errorf("expire boss battles: %w", err)
+	}
+	return nil
+}
+
+// RegenerateBossBattle восстанавливает HP текущей фазы за пропущенные дни.
+// Возвращает битву после восстановления и сколько HP восстановлено.
+func (s *Store) RegenerateBossBattle(ctx context.Context, battleID int64, phases []boss.Phase, perDay int, today string) (*BossBattleRow, int, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	b, err := lockBossBattleTx(ctx, tx, battleID)
+	if err != nil {
+		return nil, 0, err
+	}
+	healed, err := regenBossBattleTx(ctx, tx, b, phases, perDay, today)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return b, healed, nil
+}
+
+// RecordBossHit наносит удар по текущей фазе босса. Сначала восстанавливается HP
+// за пропущенные дни, затем урон идёт в фазу, если задача подходит по предмету и типу.
+// Неподходящая задача записывается с нулевым уроном, но день считается активным.
+// При победе в той же транзакции начисляются награды босса.
+func (s *Store) RecordBossHit(ctx context.Context, in BossHitInput, phases []boss.Phase, rewards []BossReward) (*BossHitResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	b, err := lockBossBattleTx(ctx, tx, in.BattleID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BossHitResult{Battle: b}
+	result.Progress = boss.Progress{Phase: b.CurrentPhase, PhaseHP: b.PhaseHP}
+	if b.Status != BossBattleActive {
+		return result, nil
+	}
+
+	result.Healed, err = regenBossBattleTx(ctx, tx, b, phases, in.RegenHPPerDay, in.Today)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.CurrentPhase < 1 || b.CurrentPhase > len(phases) {
+		return nil, fmt.Errorf("boss battle %d: phase %d out of range", b.ID, b.CurrentPhase)
+	}
+	result.Matched = phases[b.CurrentPhase-1].Matches(in.Subject, in.TaskTypes)
+
+	amount := 0
+	breakdown := damage.Breakdown{}
+	if result.Matched {
+		amount = in.Hit.Total
+		breakdown = in.Hit
+	}
+	hit, err := boss.Apply(phases, boss.Progress{Phase: b.CurrentPhase, PhaseHP: b.PhaseHP}, amount)
+	if err != nil {
+		return nil, err
+	}
+	result.Hit = hit
+
+	bonuses := breakdown.Bonuses
+	if bonuses == nil {
+		bonuses = []damage.Bonus{}
+	}
+	bonusesJSON, err := json.Marshal(bonuses)
+	if err != nil {
+		return nil, fmt.Errorf("marshal boss damage bonuses: %w", err)
+	}
+
+	var eventID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO boss_damage_events (battle_id, attempt_id, phase, damage, is_critical, bonuses, subject)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (battle_id, attempt_id) WHERE attempt_id IS NOT NULL DO NOTHING
+		RETURNING id
+	`, b.ID, in.AttemptID, b.CurrentPhase, hit.Damage, breakdown.IsCritical, bonusesJSON, in.Subject).Scan(&eventID)
+	if err == sql.ErrNoRows {
+		// Эта попытка уже била босса — ничего не меняем
+		result.AlreadyHit = true
+		result.Hit = boss.Hit{Progress: boss.Progress{Phase: b.CurrentPhase, PhaseHP: b.PhaseHP}}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit tx: %w", err)
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("record boss damage event: %w", err)
+	}
+
+	status := BossBattleActive
+	if hit.Defeated {
+		status = BossBattleDefeated
+	}
+	tasks := 0
+	if result.Matched {
+		tasks = 1
+	}
+	err = tx.QueryRowContext(ctx, `
+		UPDATE boss_battles
+		SET current_phase = $2,
+		    phase_hp = $3,
+		    total_damage = total_damage + $4,
+		    tasks_count = tasks_count + $5,
+		    last_active_on = GREATEST(last_active_on, $6::date),
+		    status = $7,
+		    defeated_at = CASE WHEN $7 = 'defeated' THEN NOW() ELSE defeated_at END,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+bossBattleColumns,
+		b.ID, hit.Phase, hit.PhaseHP, hit.Damage, tasks, in.Today, status,
+	).Scan(
+		&b.ID, &b.ChildProfileID, &b.VillainID,
+		&b.WeekStart, &b.EndsOn,
+		&b.Status, &b.CurrentPhase, &b.PhaseHP, &b.TotalDamage, &b.TasksCount,
+		&b.LastActiveOn, &b.RegenThrough,
+		&b.RegenTotal, &b.StartedAt, &b.DefeatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("update boss battle: %w", err)
+	}
+
+	if hit.Defeated {
+		leveledUp, err := grantBossRewardsTx(ctx, tx, s.XP, b, rewards)
+		if err != nil {
+			return nil, err
+		}
+		result.Rewards = rewards
+		result.LeveledUp = leveledUp
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return result, nil
+}
+
+// GetBossDamageEvents последние удары по боссу
+func (s *Store) GetBossDamageEvents(ctx context.Context, battleID int64, limit int) ([]BossDamageEventRow, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, phase, damage, is_critical, bonuses, subject, created_at
+		FROM boss_damage_events
+		WHERE battle_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`, battleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query boss damage events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []BossDamageEventRow
+	for rows.Next() {
+		var e BossDamageEventRow
+		if err := rows.Scan(&e.ID, &e.Phase, &e.Damage, &e.IsCritical, &e.Bonuses, &e.Subject, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan boss damage event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate boss damage events: %w", err)
+	}
+	return events, nil
+}
+
+// lockBossBattleTx читает битву с блокировкой строки
+func lockBossBattleTx(ctx context.Context, tx *sql.Tx, battleID int64) (*BossBattleRow, error) {
+	row := tx.QueryRowContext(ctx, `
+		SELECT `+bossBattleColumns+`
+		FROM boss_battles
+		WHERE id = $1
+		FOR UPDATE
+	`, battleID)
+	b, err := scanBossBattle(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("boss battle %d not found", battleID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock boss battle: %w", err)
+	}
+	return b, nil
+}
+
+// regenBossBattleTx восстанавливает HP текущей фазы за ещё не учтённые пропущенные дни
+// и обновляет b. Пропуски учитываются один раз: regen_through сдвигается на вчера.
+func regenBossBattleTx(ctx context.Context, tx *sql.Tx, b *BossBattleRow, phases []boss.Phase, perDay int, today string) (int, error) {
+	if b.Status != BossBattleActive || perDay <= 0 {
+		return 0, nil
+	}
+	if b.CurrentPhase < 1 || b.CurrentPhase > len(phases) {
+		return 0, nil
+	}
+
+	// Пропуски после конца битвы не считаются
+	day := today
+	if day > b.EndsOn {
+		end, err := time.Parse(dateLayout, b.EndsOn)
+		if err != nil {
+			return 0, fmt.Errorf("parse boss battle end: %w", err)
+		}
+		day = end.AddDate(0, 0, 1).Format(dateLayout)
+	}
+
+	missed, err := boss.MissedDays(b.LastActiveOn, b.RegenThrough, day)
+	if err != nil {
+		return 0, err
+	}
+	if missed == 0 {
+		return 0, nil
+	}
+
+	newHP, healed := boss.Regenerate(b.PhaseHP, phases[b.CurrentPhase-1].HP, missed, perDay)
+	dayTime, err := time.Parse(dateLayout, day)
+	if err != nil {
+		return 0, fmt.Errorf("parse boss day: %w", err)
+	}
+	regenThrough := dayTime.AddDate(0, 0, -1).Format(dateLayout)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE boss_battles
+		SET phase_hp = $2,
+		    regen_total = regen_total + $3,
+		    regen_through = $4,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, b.ID, newHP, healed, regenThrough)
+	if err != nil {
+		return 0, fmt.Errorf("regenerate boss battle: %w", err)
+	}
+
+	b.PhaseHP = newHP
+	b.RegenTotal += healed
+	b.RegenThrough = regenThrough
+	return healed, nil
+}
+
+// grantBossRewardsTx начисляет награды за победу над боссом.
+// Ключ журнала — id битвы, а статус битвы меняется в той же транзакции, поэтому награды выдаются один раз.
+func grantBossRewardsTx(ctx context.Context, tx *sql.Tx, xp XPConfig, b *BossBattleRow, rewards []BossReward) (bool, error) {
+	leveledUp := false
+	src := WalletSource{
+		Type:        WalletSourceVillainBattle,
+		ID:          "boss:" + strconv.FormatInt(b.ID, 10),
+		Description: "Недельный босс",
+	}
+	for _, r := range rewards {
+		switch r.Type {
+		case BossRewardCoins:
+			if _, _, err := applyWalletEntryTx(ctx, tx, b.ChildProfileID, CurrencyCoins, r.Amount, src); err != nil {
+				return false, fmt.Errorf("add boss reward coins: %w", err)
+			}
+		case BossRewardXP:
+			_, up, err := addXPTx(ctx, tx, b.ChildProfileID, r.Amount, xp, src)
+			if err != nil {
+				return false, err
+			}
+			leveledUp = leveledUp || up
+		case BossRewardItem:
+			if err := addInventoryTx(ctx, tx, b.ChildProfileID, r.ItemID, r.Amount); err != nil {
+				return false, err
+			}
+		}
+	}
+	return leveledUp, nil
+}
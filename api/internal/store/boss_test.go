@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"child-bot/api/internal/boss"
+	"child-bot/api/internal/damage"
+
+	"github.com/google/uuid"
+)
+
+const testBossID = "boss_week_chaos"
+
+// startTestBossBattle начинает битву с сидовым недельным боссом
+func startTestBossBattle(t *testing.T, s *Store, childProfileID, weekStart, today string) (*BossBattleRow, []boss.Phase, []BossReward) {
+	t.Helper()
+	ctx := context.Background()
+
+	phases, err := s.GetBossPhases(ctx, testBossID)
+	if err != nil || len(phases) < 2 {
+		t.Fatalf("GetBossPhases = %v, %v; want seeded phases", phases, err)
+	}
+	rewards, err := s.GetBossRewards(ctx, testBossID)
+	if err != nil {
+		t.Fatalf("GetBossRewards error = %v", err)
+	}
+	endsOn, _ := boss.EndsOn(weekStart, 7)
+	b, err := s.CreateBossBattle(ctx, childProfileID, testBossID, weekStart, endsOn, today, phases[0].HP)
+	if err != nil {
+		t.Fatalf("CreateBossBattle error = %v", err)
+	}
+	return b, phases, rewards
+}
+
+func mustBossHit(t *testing.T, s *Store, in BossHitInput, phases []boss.Phase, rewards []BossReward) *BossHitResult {
+	t.Helper()
+
+	res, err := s.RecordBossHit(context.Background(), in, phases, rewards)
+	if err != nil {
+		t.Fatalf("RecordBossHit error = %v", err)
+	}
+	return res
+}
+
+func TestRecordBossHit_PhaseRequirementAndReplay(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+
+	child := createTestProfile(t, db, testID("boss_phase"), 0)
+	b, phases, rewards := startTestBossBattle(t, s, child, "2026-03-02", "2026-03-02")
+
+	// Фаза 1 требует математику: русский не ранит босса
+	wrong := BossHitInput{BattleID: b.ID, AttemptID: uuid.New(), Subject: "ru", Hit: damage.Fixed(30), Today: "2026-03-02"}
+	if res := mustBossHit(t, s, wrong, phases, rewards); res.Matched || res.Damage != 0 || res.PhaseHP != phases[0].HP {
+		t.Errorf("wrong subject hit = %+v, want no damage", res.Hit)
+	}
+
+	right := BossHitInput{BattleID: b.ID, AttemptID: uuid.New(), Subject: "math", Hit: damage.Fixed(30), Today: "2026-03-02"}
+	res := mustBossHit(t, s, right, phases, rewards)
+	if !res.Matched || res.Damage != 30 || res.PhaseHP != phases[0].HP-30 {
+		t.Errorf("math hit = %+v, want 30 damage", res.Hit)
+	}
+
+	// Повтор той же попытки ничего не меняет
+	replay := mustBossHit(t, s, right, phases, rewards)
+	if !replay.AlreadyHit || replay.Battle.TotalDamage != 30 {
+		t.Errorf("replay = %+v (total %d), want already hit with total 30", replay.Hit, replay.Battle.TotalDamage)
+	}
+
+	// Добиваем фазу: перебор не переносится во вторую фазу
+	finish := BossHitInput{BattleID: b.ID, AttemptID: uuid.New(), Subject: "math", Hit: damage.Fixed(phases[0].HP), Today: "2026-03-02"}
+	res = mustBossHit(t, s, finish, phases, rewards)
+	if !res.PhaseCleared || res.Phase != 2 || res.PhaseHP != phases[1].HP {
+		t.Errorf("finishing hit = %+v, want phase 2 with full HP", res.Hit)
+	}
+}
+
+func TestRecordBossHit_DefeatGrantsRewardsOnce(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+
+	child := createTestProfile(t, db, testID("boss_defeat"), 0)
+	b, phases, rewards := startTestBossBattle(t, s, child, "2026-03-02", "2026-03-02")
+
+	for _, p := range phases {
+		subject := p.RequiredSubject
+		var taskTypes []string
+		if p.RequiredTaskType != "" {
+			taskTypes = []string{p.RequiredTaskType}
+		}
+		in := BossHitInput{BattleID: b.ID, AttemptID: uuid.New(), Subject: subject, TaskTypes: taskTypes, Hit: damage.Fixed(p.HP), Today: "2026-03-03"}
+		res := mustBossHit(t, s, in, phases, rewards)
+		if !res.PhaseCleared {
+			t.Fatalf("phase %d not cleared: %+v", p.Number, res.Hit)
+		}
+		if p.Number == len(phases) && (!res.Defeated || res.Battle.Status != BossBattleDefeated) {
+			t.Fatalf("last phase: %+v (status %s), want defeated", res.Hit, res.Battle.Status)
+		}
+	}
+
+	wantCoins := 0
+	for _, r := range rewards {
+		if r.Type == BossRewardCoins {
+			wantCoins += r.Amount
+		}
+	}
+	coins, _ := getBalances(t, db, child)
+	if coins < wantCoins {
+		t.Errorf("coins after defeat = %d, want at least %d", coins, wantCoins)
+	}
+	if got := getStreakFreezes(t, db, child); got != 1 {
+		t.Errorf("streak freezes after defeat = %d, want 1", got)
+	}
+
+	// Побеждённый босс больше не получает урон и не выдаёт награды
+	after := BossHitInput{BattleID: b.ID, AttemptID: uuid.New(), Subject: "math", Hit: damage.Fixed(10), Today: "2026-03-03"}
+	if res := mustBossHit(t, s, after, phases, rewards); res.Damage != 0 || len(res.Rewards) != 0 {
+		t.Errorf("hit after defeat = %+v, rewards %v", res.Hit, res.Rewards)
+	}
+	if again, _ := getBalances(t, db, child); again != coins {
+		t.Errorf("coins changed after defeat: %d -> %d", coins, again)
+	}
+}
+
+func TestRegenerateBossBattle_SkippedDays(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	child := createTestProfile(t, db, testID("boss_regen"), 0)
+	b, phases, rewards := startTestBossBattle(t, s, child, "2026-03-02", "2026-03-02")
+
+	in := BossHitInput{BattleID: b.ID, AttemptID: uuid.New(), Subject: "math", Hit: damage.Fixed(100), Today: "2026-03-02", RegenHPPerDay: 20}
+	mustBossHit(t, s, in, phases, rewards)
+
+	// Пропущены 3 и 4 марта: +40 HP, но не выше максимума фазы
+	got, healed, err := s.RegenerateBossBattle(ctx, b.ID, phases, 20, "2026-03-05")
+	if err != nil {
+		t.Fatalf("RegenerateBossBattle error = %v", err)
+	}
+	wantHP := phases[0].HP - 100 + 40
+	if wantHP > phases[0].HP {
+		wantHP = phases[0].HP
+	}
+	if got.PhaseHP != wantHP || healed != wantHP-(phases[0].HP-100) {
+		t.Errorf("after regen HP = %d (healed %d), want %d", got.PhaseHP, healed, wantHP)
+	}
+
+	// Повторный запрос в тот же день пропуски второй раз не считает
+	again, healed, err := s.RegenerateBossBattle(ctx, b.ID, phases, 20, "2026-03-05")
+	if err != nil {
+		t.Fatalf("RegenerateBossBattle error = %v", err)
+	}
+	if healed != 0 || again.PhaseHP != got.PhaseHP {
+		t.Errorf("second regen healed %d (HP %d), want 0", healed, again.PhaseHP)
+	}
+}
@@ -0,0 +1,586 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/family"
+)
+
+// Статусы семейного квеста
+const (
+	FamilyQuestActive    = "active"
+	FamilyQuestCompleted = "completed"
+	FamilyQuestExpired   = "expired"
+)
+
+// Участники семейного квеста
+const (
+	FamilyActorChild  = "child"
+	FamilyActorParent = "parent"
+)
+
+// FamilyNotificationQuestCompleted уведомление о выполненном квесте
+const FamilyNotificationQuestCompleted = "quest_completed"
+
+// FamilyQuestSyncResult итог синхронизации шаблонов квестов
+type FamilyQuestSyncResult struct {
+	Upserted    int
+	Deactivated int
+}
+
+// FamilyQuest семейный квест ребёнка
+type FamilyQuest struct {
+	ID              int64
+	ChildProfileID  string
+	TemplateID      string
+	QuestType       string
+	Icon            string
+	Title           string
+	Description     string
+	ChildMetric     string
+	ChildTarget     int
+	ChildProgress   int
+	ParentAction    string // пусто — подтверждения родителя не нужны
+	ParentTarget    int
+	ParentProgress  int
+	ParentLabel     string
+	Window          string
+	RewardCoins     int
+	RewardXP        int
+	RewardBadgeID   string
+	RewardBadgeName string
+	StartedBy       string
+	Status          string
+	StartedAt       time.Time
+	EndsAt          time.Time
+	CompletedAt     *time.Time
+}
+
+// Done выполнены ли обе цели квеста
+func (q FamilyQuest) Done() bool {
+	return q.ChildProgress >= q.ChildTarget && (q.ParentAction == "" || q.ParentProgress >= q.ParentTarget)
+}
+
+// FamilyQuestAdvanceResult итог засчитывания действия квестам
+type FamilyQuestAdvanceResult struct {
+	Advanced  []FamilyQuest // квесты, прогресс которых изменился
+	Completed []FamilyQuest // из них выполненные этим действием
+	LeveledUp bool
+}
+
+// FamilyNotification уведомление ребёнку или родителю
+type FamilyNotification struct {
+	ID           int64
+	Recipient    string
+	Kind         string
+	QuestID      int64
+	RedemptionID int64 // запрос награды за монеты
+	Title        string
+	Body         string
+	ReadAt       *time.Time
+	CreatedAt    time.Time
+}
+
+// SyncFamilyQuestTemplates в одной транзакции добавляет и обновляет шаблоны каталога.
+// Шаблоны, которых больше нет в каталоге, выключаются: на них ссылаются начатые квесты.
+func (s *Store) SyncFamilyQuestTemplates(ctx context.Context, version int, templates []family.Template) (*FamilyQuestSyncResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO family_quest_templates (id, quest_type, icon, title, description, child_metric, child_target,
+		                                    parent_action, parent_target, parent_label, quest_window, duration_days,
+		                                    reward_coins, reward_xp, reward_badge_id, reward_badge_name,
+		                                    catalog_version, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, true)
+		ON CONFLICT (id) DO UPDATE
+		SET quest_type = EXCLUDED.quest_type,
+		    icon = EXCLUDED.icon,
+		    title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    child_metric = EXCLUDED.child_metric,
+		    child_target = EXCLUDED.child_target,
+		    parent_action = EXCLUDED.parent_action,
+		    parent_target = EXCLUDED.parent_target,
+		    parent_label = EXCLUDED.parent_label,
+		    quest_window = EXCLUDED.quest_window,
+		    duration_days = EXCLUDED.duration_days,
+		    reward_coins = EXCLUDED.reward_coins,
+		    reward_xp = EXCLUDED.reward_xp,
+		    reward_badge_id = EXCLUDED.reward_badge_id,
+		    reward_badge_name = EXCLUDED.reward_badge_name,
+		    catalog_version = EXCLUDED.catalog_version,
+		    is_active = true,
+		    updated_at = NOW()
+	`
+	ids := make([]string, 0, len(templates))
+	for _, t := range templates {
+		var parentAction sql.NullString
+		var parentTarget int
+		var parentLabel string
+		if t.Parent != nil {
+			parentAction = sql.NullString{String: t.Parent.Action, Valid: true}
+			parentTarget, parentLabel = t.Parent.Target, t.Parent.Label
+		}
+		_, err := tx.ExecContext(ctx, query,
+			t.ID, t.Type, t.Icon, t.Title, t.Description, t.Child.Metric, t.Child.Target,
+			parentAction, parentTarget, parentLabel, t.Window, t.DurationDays,
+			t.Reward.Coins, t.Reward.XP, nullString(t.Reward.BadgeID), nullString(t.Reward.BadgeName), version)
+		if err != nil {
+			return nil, fmt.Errorf("upsert family quest template %s: %w", t.ID, err)
+		}
+		ids = append(ids, t.ID)
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE family_quest_templates
+		SET is_active = false, updated_at = NOW()
+		WHERE is_active AND NOT (id = ANY($1))
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("deactivate family quest templates: %w", err)
+	}
+	deactivated, _ := res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	result := &FamilyQuestSyncResult{Upserted: len(templates), Deactivated: int(deactivated)}
+	log.Printf("[Store] Family quest templates synced: upserted=%d, deactivated=%d", result.Upserted, result.Deactivated)
+	return result, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// IsChildParent является ли аккаунт платформы родителем ребёнка: родитель — тот,
+// кто создал профиль (child_profiles.platform_id и platform_user_id)
+func (s *Store) IsChildParent(ctx context.Context, childProfileID, platformID, parentUserID string) (bool, error) {
+	var ok bool
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM child_profiles
+			WHERE id = $1 AND platform_id = $2 AND platform_user_id = $3
+		)
+	`, childProfileID, platformID, parentUserID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check child parent: %w", err)
+	}
+	return ok, nil
+}
+
+const familyQuestColumns = `id, child_profile_id, template_id, quest_type, icon, title, description,
+	child_metric, child_target, child_progress, COALESCE(parent_action, ''), parent_target, parent_progress,
+	parent_label, quest_window, reward_coins, reward_xp, COALESCE(reward_badge_id, ''),
+	COALESCE(reward_badge_name, ''), started_by, status, started_at, ends_at, completed_at`
+
+func scanFamilyQuest(row rowScanner) (*FamilyQuest, error) {
+	var q FamilyQuest
+	var completedAt sql.NullTime
+	err := row.Scan(&q.ID, &q.ChildProfileID, &q.TemplateID, &q.QuestType, &q.Icon, &q.Title, &q.Description,
+		&q.ChildMetric, &q.ChildTarget, &q.ChildProgress, &q.ParentAction, &q.ParentTarget, &q.ParentProgress,
+		&q.ParentLabel, &q.Window, &q.RewardCoins, &q.RewardXP, &q.RewardBadgeID,
+		&q.RewardBadgeName, &q.StartedBy, &q.Status, &q.StartedAt, &q.EndsAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		q.CompletedAt = &completedAt.Time
+	}
+	return &q, nil
+}
+
+func queryFamilyQuests(ctx context.Context, db querier, query string, args ...any) ([]FamilyQuest, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query family quests: %w", err)
+	}
+	defer rows.Close()
+
+	var quests []FamilyQuest
+	for rows.Next() {
+		q, err := scanFamilyQuest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan family quest: %w", err)
+		}
+		quests = append(quests, *q)
+	}
+	return quests, rows.Err()
+}
+
+// expireFamilyQuests помечает истёкшими активные квесты ребёнка, срок которых прошёл
+func expireFamilyQuests(ctx context.Context, db execer, childProfileID string, now time.Time) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE family_quests
+		SET status = 'expired', updated_at = NOW()
+		WHERE child_profile_id = $1 AND status = 'active' AND ends_at <= $2
+	`, childProfileID, now)
+	if err != nil {
+		return fmt.Errorf("expire family quests: %w", err)
+	}
+	return nil
+}
+
+// StartFamilyQuest начинает квест по шаблону t, который идёт до endsAt.
+// Если квест этого шаблона уже идёт, возвращает domain.ErrConflict.
+func (s *Store) StartFamilyQuest(ctx context.Context, childProfileID string, t family.Template, startedBy string, now, endsAt time.Time) (*FamilyQuest, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем профиль: два одновременных старта одного шаблона не создают два квеста
+	var lockedID string
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM child_profiles WHERE id = $1 FOR UPDATE`, childProfileID).Scan(&lockedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("lock profile: %w", err)
+	}
+	if err := expireFamilyQuests(ctx, tx, childProfileID, now); err != nil {
+		return nil, err
+	}
+
+	var active bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM family_quests
+			WHERE child_profile_id = $1 AND template_id = $2 AND status = 'active'
+		)
+	`, childProfileID, t.ID).Scan(&active)
+	if err != nil {
+		return nil, fmt.Errorf("check active family quest: %w", err)
+	}
+	if active {
+		return nil, domain.ErrConflict
+	}
+
+	var parentAction sql.NullString
+	var parentTarget int
+	var parentLabel string
+	if t.Parent != nil {
+		parentAction = sql.NullString{String: t.Parent.Action, Valid: true}
+		parentTarget, parentLabel = t.Parent.Target, t.Parent.Label
+	}
+	quest, err := scanFamilyQuest(tx.QueryRowContext(ctx, `
+		INSERT INTO family_quests (child_profile_id, template_id, quest_type, icon, title, description,
+		                           child_metric, child_target, parent_action, parent_target, parent_label,
+		                           quest_window, reward_coins, reward_xp, reward_badge_id, reward_badge_name,
+		                           started_by, started_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		RETURNING `+familyQuestColumns,
+		childProfileID, t.ID, t.Type, t.Icon, t.Title, t.Description,
+		t.Child.Metric, t.Child.Target, parentAction, parentTarget, parentLabel,
+		t.Window, t.Reward.Coins, t.Reward.XP, nullString(t.Reward.BadgeID), nullString(t.Reward.BadgeName),
+		startedBy, now, endsAt))
+	if err != nil {
+		return nil, fmt.Errorf("insert family quest: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return quest, nil
+}
+
+// ListFamilyQuests активные квесты ребёнка и завершённые с момента since, новые первыми.
+// Просроченные активные квесты помечаются истёкшими.
+func (s *Store) ListFamilyQuests(ctx context.Context, childProfileID string, since, now time.Time) ([]FamilyQuest, error) {
+	if err := expireFamilyQuests(ctx, s.DB, childProfileID, now); err != nil {
+		return nil, err
+	}
+	return queryFamilyQuests(ctx, s.DB, `
+		SELECT `+familyQuestColumns+`
+		FROM family_quests
+		WHERE child_profile_id = $1 AND (status = 'active' OR started_at >= $2)
+		ORDER BY status = 'active' DESC, started_at DESC, id DESC
+	`, childProfileID, since)
+}
+
+// AdvanceFamilyQuestsForCheck засчитывает проверку attemptID активным квестам ребёнка.
+// at — время проверки, loc — часовой пояс ребёнка (квесты выходного дня считают только
+// субботу и воскресенье, active_days — разные дни). Одна проверка двигает квест один раз.
+func (s *Store) AdvanceFamilyQuestsForCheck(ctx context.Context, childProfileID, attemptID string, isCorrect bool, at time.Time, loc *time.Location) (*FamilyQuestAdvanceResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	quests, err := queryFamilyQuests(ctx, tx, `
+		SELECT `+familyQuestColumns+`
+		FROM family_quests
+		WHERE child_profile_id = $1 AND status = 'active' AND started_at <= $2 AND ends_at > $2
+		ORDER BY id
+		FOR UPDATE
+	`, childProfileID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FamilyQuestAdvanceResult{}
+	for _, q := range quests {
+		if !(family.Template{Window: q.Window}).CountsOn(at, loc) || q.ChildProgress >= q.ChildTarget {
+			continue
+		}
+		inc := family.ChildIncrement(q.ChildMetric, isCorrect)
+		if inc == 0 {
+			continue
+		}
+		key := family.ChildSourceKey(q.ChildMetric, attemptID, at, loc)
+		recorded, err := recordFamilyQuestEventTx(ctx, tx, q.ID, FamilyActorChild, key, inc, "", at)
+		if err != nil {
+			return nil, err
+		}
+		if !recorded {
+			continue
+		}
+
+		q.ChildProgress = min(q.ChildTarget, q.ChildProgress+inc)
+		if err := applyFamilyQuestProgressTx(ctx, tx, s.XP, &q, at, result); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return result, nil
+}
+
+// ConfirmFamilyQuest засчитывает подтверждение родителя квесту questID. Повтор с тем же
+// ключом ничего не меняет. Квест не ребёнка — domain.ErrNotFound, завершённый или истёкший —
+// domain.ErrConflict, квест без подтверждений родителя — domain.ErrInvalidInput.
+func (s *Store) ConfirmFamilyQuest(ctx context.Context, childProfileID string, questID int64, key, note string, now time.Time) (*FamilyQuest, *FamilyQuestAdvanceResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	q, err := scanFamilyQuest(tx.QueryRowContext(ctx, `
+		SELECT `+familyQuestColumns+`
+		FROM family_quests
+		WHERE id = $1 AND child_profile_id = $2
+		FOR UPDATE
+	`, questID, childProfileID))
+	if err == sql.ErrNoRows {
+		return nil, nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("get family quest: %w", err)
+	}
+
+	if q.ParentAction == "" {
+		return nil, nil, domain.ErrInvalidInput
+	}
+
+	// Ключ проверяется до статуса: повтор подтверждения, завершившего квест, — не конфликт
+	result := &FamilyQuestAdvanceResult{}
+	recorded, err := recordFamilyQuestEventTx(ctx, tx, q.ID, FamilyActorParent, "confirm:"+key, 1, note, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !recorded {
+		// Повтор запроса: подтверждение уже засчитано
+		return q, result, nil
+	}
+	if q.Status != FamilyQuestActive || !q.EndsAt.After(now) {
+		return nil, nil, domain.ErrConflict
+	}
+
+	if q.ParentProgress < q.ParentTarget {
+		q.ParentProgress++
+		if err := applyFamilyQuestProgressTx(ctx, tx, s.XP, q, now, result); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return q, result, nil
+}
+
+// recordFamilyQuestEventTx записывает действие квесту; false — действие уже засчитано
+func recordFamilyQuestEventTx(ctx context.Context, tx *sql.Tx, questID int64, actor, sourceKey string, inc int, note string, at time.Time) (bool, error) {
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO family_quest_events (quest_id, actor, source_key, increment, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (quest_id, source_key) DO NOTHING
+	`, questID, actor, sourceKey, inc, note, at)
+	if err != nil {
+		return false, fmt.Errorf("insert family quest event: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// applyFamilyQuestProgressTx сохраняет прогресс квеста; если обе цели выполнены — завершает
+// квест, начисляет награду и уведомляет ребёнка и родителя
+func applyFamilyQuestProgressTx(ctx context.Context, tx *sql.Tx, xp XPConfig, q *FamilyQuest, now time.Time, result *FamilyQuestAdvanceResult) error {
+	if q.Done() {
+		q.Status = FamilyQuestCompleted
+		q.CompletedAt = &now
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE family_quests
+		SET child_progress = $2, parent_progress = $3, status = $4, completed_at = $5, updated_at = NOW()
+		WHERE id = $1
+	`, q.ID, q.ChildProgress, q.ParentProgress, q.Status, q.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("update family quest %d: %w", q.ID, err)
+	}
+	result.Advanced = append(result.Advanced, *q)
+	if q.Status != FamilyQuestCompleted {
+		return nil
+	}
+
+	result.Completed = append(result.Completed, *q)
+	up, err := grantFamilyQuestRewardTx(ctx, tx, xp, *q)
+	if err != nil {
+		return err
+	}
+	result.LeveledUp = result.LeveledUp || up
+
+	return notifyFamilyQuestCompletedTx(ctx, tx, *q)
+}
+
+// grantFamilyQuestRewardTx начисляет ребёнку монеты, XP и семейный бейдж квеста
+func grantFamilyQuestRewardTx(ctx context.Context, tx *sql.Tx, xp XPConfig, q FamilyQuest) (bool, error) {
+	src := WalletSource{
+		Type:        WalletSourceFamilyQuest,
+		ID:          strconv.FormatInt(q.ID, 10),
+		Description: "Семейный квест: " + q.Title,
+	}
+	if q.RewardCoins > 0 {
+		if _, _, err := applyWalletEntryTx(ctx, tx, q.ChildProfileID, CurrencyCoins, q.RewardCoins, src); err != nil {
+			return false, fmt.Errorf("add family quest coins: %w", err)
+		}
+	}
+	leveledUp := false
+	if q.RewardXP > 0 {
+		_, up, err := addXPTx(ctx, tx, q.ChildProfileID, q.RewardXP, xp, src)
+		if err != nil {
+			return false, err
+		}
+		leveledUp = up
+	}
+	if q.RewardBadgeID != "" {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO child_rewards (child_profile_id, reward_type, reward_id, reward_name)
+			VALUES ($1, 'badge', $2, $3)
+			ON CONFLICT (child_profile_id, reward_type, reward_id) DO NOTHING
+		`, q.ChildProfileID, q.RewardBadgeID, q.RewardBadgeName)
+		if err != nil {
+			return false, fmt.Errorf("add family badge: %w", err)
+		}
+	}
+	return leveledUp, nil
+}
+
+// notifyFamilyQuestCompletedTx создаёт уведомления о выполненном квесте ребёнку и родителю
+func notifyFamilyQuestCompletedTx(ctx context.Context, tx *sql.Tx, q FamilyQuest) error {
+	reward := familyRewardText(q)
+	notes := []struct{ recipient, title, body string }{
+		{FamilyActorChild, "Семейный квест выполнен!", fmt.Sprintf("Вы вместе справились с квестом «%s». %s", q.Title, reward)},
+		{FamilyActorParent, "Семейный квест выполнен", fmt.Sprintf("Квест «%s» выполнен. Ребёнок получил: %s", q.Title, reward)},
+	}
+	for _, n := range notes {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO family_notifications (child_profile_id, recipient, kind, quest_id, title, body)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (quest_id, recipient, kind) DO NOTHING
+		`, q.ChildProfileID, n.recipient, FamilyNotificationQuestCompleted, q.ID, n.title, n.body)
+		if err != nil {
+			return fmt.Errorf("insert family notification: %w", err)
+		}
+	}
+	return nil
+}
+
+func familyRewardText(q FamilyQuest) string {
+	var parts []string
+	if q.RewardCoins > 0 {
+		parts = append(parts, fmt.Sprintf("%d монет", q.RewardCoins))
+	}
+	if q.RewardXP > 0 {
+		parts = append(parts, fmt.Sprintf("%d XP", q.RewardXP))
+	}
+	if q.RewardBadgeName != "" {
+		parts = append(parts, "бейдж «"+q.RewardBadgeName+"»")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ListFamilyNotifications последние limit уведомлений получателя и число непрочитанных
+func (s *Store) ListFamilyNotifications(ctx context.Context, childProfileID, recipient string, limit int) ([]FamilyNotification, int, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, recipient, kind, COALESCE(quest_id, 0), COALESCE(redemption_id, 0), title, body, read_at, created_at
+		FROM family_notifications
+		WHERE child_profile_id = $1 AND recipient = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`, childProfileID, recipient, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query family notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []FamilyNotification
+	for rows.Next() {
+		var n FamilyNotification
+		var readAt sql.NullTime
+		if err := rows.Scan(&n.ID, &n.Recipient, &n.Kind, &n.QuestID, &n.RedemptionID, &n.Title, &n.Body, &readAt, &n.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("scan family notification: %w", err)
+		}
+		if readAt.Valid {
+			n.ReadAt = &readAt.Time
+		}
+		notes = append(notes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate family notifications: %w", err)
+	}
+
+	var unread int
+	err = s.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM family_notifications
+		WHERE child_profile_id = $1 AND recipient = $2 AND read_at IS NULL
+	`, childProfileID, recipient).Scan(&unread)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count unread family notifications: %w", err)
+	}
+	return notes, unread, nil
+}
+
+// MarkFamilyNotificationRead отмечает уведомление прочитанным; чужое — domain.ErrNotFound
+func (s *Store) MarkFamilyNotificationRead(ctx context.Context, childProfileID, recipient string, id int64) error {
+	res, err := s.DB.ExecContext(ctx, `
+		UPDATE family_notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND child_profile_id = $2 AND recipient = $3
+	`, id, childProfileID, recipient)
+	if err != nil {
+		return fmt.Errorf("mark family notification read: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
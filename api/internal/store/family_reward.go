@@ -0,0 +1,404 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"child-bot/api/internal/domain"
+)
+
+// Статусы запроса награды за монеты
+const (
+	FamilyRedemptionPending  = "pending"
+	FamilyRedemptionApproved = "approved"
+	FamilyRedemptionDeclined = "declined"
+)
+
+// Уведомления о наградах за монеты
+const (
+	FamilyNotificationRewardRequested = "reward_requested"
+	FamilyNotificationRewardApproved  = "reward_approved"
+	FamilyNotificationRewardDeclined  = "reward_declined"
+)
+
+// FamilyReward награда в реальном мире, которую родитель назначил за монеты
+type FamilyReward struct {
+	ID             int64
+	ChildProfileID string
+	Title          string
+	Description    string
+	Icon           string
+	Price          int
+	IsActive       bool
+	CreatedBy      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// FamilyRedemption запрос награды ребёнком; название, иконка и цена — на момент запроса
+type FamilyRedemption struct {
+	ID             int64
+	ChildProfileID string
+	RewardID       int64
+	Title          string
+	Icon           string
+	Price          int
+	Status         string
+	ParentNote     string
+	RequestedAt    time.Time
+	DecidedAt      *time.Time
+}
+
+const familyRewardColumns = `id, child_profile_id, title, description, icon, price, is_active,
+	created_by, created_at, updated_at`
+
+const familyRedemptionColumns = `id, child_profile_id, reward_id, title, icon, price, status,
+	parent_note, requested_at, decided_at`
+
+func scanFamilyReward(row rowScanner) (*FamilyReward, error) {
+	var r FamilyReward
+	err := row.Scan(&r.ID, &r.ChildProfileID, &r.Title, &r.Description, &r.Icon, &r.Price, &r.IsActive,
+		&r.CreatedBy, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func scanFamilyRedemption(row rowScanner) (*FamilyRedemption, error) {
+	var r FamilyRedemption
+	var decidedAt sql.NullTime
+	err := row.Scan(&r.ID, &r.ChildProfileID, &r.RewardID, &r.Title, &r.Icon, &r.Price, &r.Status,
+		&r.ParentNote, &r.RequestedAt, &decidedAt)
+	if err != nil {
+		return nil, err
+	}
+	if decidedAt.Valid {
+		r.DecidedAt = &decidedAt.Time
+	}
+	return &r, nil
+}
+
+func queryFamilyRedemptions(ctx context.Context, db querier, query string, args ...any) ([]FamilyRedemption, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query family redemptions: %w", err)
+	}
+	defer rows.Close()
+
+	var list []FamilyRedemption
+	for rows.Next() {
+		r, err := scanFamilyRedemption(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan family redemption: %w", err)
+		}
+		list = append(list, *r)
+	}
+	return list, rows.Err()
+}
+
+// ListFamilyRewards активные награды ребёнка, от дешёвых к дорогим
+func (s *Store) ListFamilyRewards(ctx context.Context, childProfileID string) ([]FamilyReward, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+familyRewardColumns+`
+		FROM family_rewards
+		WHERE child_profile_id = $1 AND is_active = TRUE
+		ORDER BY price, id
+	`, childProfileID)
+	if err != nil {
+		return nil, fmt.Errorf("query family rewards: %w", err)
+	}
+	defer rows.Close()
+
+	var list []FamilyReward
+	for rows.Next() {
+		r, err := scanFamilyReward(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan family reward: %w", err)
+		}
+		list = append(list, *r)
+	}
+	return list, rows.Err()
+}
+
+// CreateFamilyReward добавляет награду в каталог ребёнка. Если активных наград уже
+// maxActive, возвращает domain.ErrConflict.
+func (s *Store) CreateFamilyReward(ctx context.Context, r FamilyReward, maxActive int) (*FamilyReward, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем профиль: одновременные добавления не превышают лимит
+	var lockedID string
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM child_profiles WHERE id = $1 FOR UPDATE`, r.ChildProfileID).Scan(&lockedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("lock profile: %w", err)
+	}
+
+	var active int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM family_rewards WHERE child_profile_id = $1 AND is_active = TRUE
+	`, r.ChildProfileID).Scan(&active)
+	if err != nil {
+		return nil, fmt.Errorf("count family rewards: %w", err)
+	}
+	if active >= maxActive {
+		return nil, domain.ErrConflict
+	}
+
+	created, err := scanFamilyReward(tx.QueryRowContext(ctx, `
+		INSERT INTO family_rewards (child_profile_id, title, description, icon, price, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+familyRewardColumns,
+		r.ChildProfileID, r.Title, r.Description, r.Icon, r.Price, r.CreatedBy))
+	if err != nil {
+		return nil, fmt.Errorf("insert family reward: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return created, nil
+}
+
+// UpdateFamilyReward меняет активную награду; уже поданные запросы сохраняют прежнюю цену
+func (s *Store) UpdateFamilyReward(ctx context.Context, r FamilyReward) (*FamilyReward, error) {
+	updated, err := scanFamilyReward(s.DB.QueryRowContext(ctx, `
+		UPDATE family_rewards
+		SET title = $3, description = $4, icon = $5, price = $6, updated_at = NOW()
+		WHERE id = $1 AND child_profile_id = $2 AND is_active = TRUE
+		RETURNING `+familyRewardColumns,
+		r.ID, r.ChildProfileID, r.Title, r.Description, r.Icon, r.Price))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("update family reward: %w", err)
+	}
+	return updated, nil
+}
+
+// ArchiveFamilyReward снимает награду из каталога; история запросов остаётся
+func (s *Store) ArchiveFamilyReward(ctx context.Context, childProfileID string, id int64) error {
+	res, err := s.DB.ExecContext(ctx, `
+		UPDATE family_rewards
+		SET is_active = FALSE, updated_at = NOW()
+		WHERE id = $1 AND child_profile_id = $2 AND is_active = TRUE
+	`, id, childProfileID)
+	if err != nil {
+		return fmt.Errorf("archive family reward: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// RedeemFamilyReward списывает монеты за награду и создаёт запрос, который ждёт решения
+// родителя. Повтор с тем же key возвращает прежний запрос и replayed = true. Если
+// ожидающих запросов уже maxPending — domain.ErrConflict, не хватает монет —
+// domain.ErrInsufficientFunds.
+func (s *Store) RedeemFamilyReward(ctx context.Context, childProfileID string, rewardID int64, key string, maxPending int) (*FamilyRedemption, bool, error) {
+	if key == "" {
+		return nil, false, domain.ErrInvalidInput
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем профиль: запросы одного ребёнка проходят последовательно
+	var lockedID string
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM child_profiles WHERE id = $1 FOR UPDATE`, childProfileID).Scan(&lockedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, domain.ErrNotFound
+		}
+		return nil, false, fmt.Errorf("lock profile: %w", err)
+	}
+
+	// Повтор запроса: награда уже запрошена
+	existing, err := scanFamilyRedemption(tx.QueryRowContext(ctx, `
+		SELECT `+familyRedemptionColumns+`
+		FROM family_reward_redemptions
+		WHERE child_profile_id = $1 AND idempotency_key = $2
+	`, childProfileID, key))
+	if err == nil {
+		return existing, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("check redemption replay: %w", err)
+	}
+
+	reward, err := scanFamilyReward(tx.QueryRowContext(ctx, `
+		SELECT `+familyRewardColumns+`
+		FROM family_rewards
+		WHERE id = $1 AND child_profile_id = $2 AND is_active = TRUE
+	`, rewardID, childProfileID))
+	if err == sql.ErrNoRows {
+		return nil, false, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get family reward: %w", err)
+	}
+
+	var pending int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM family_reward_redemptions WHERE child_profile_id = $1 AND status = 'pending'
+	`, childProfileID).Scan(&pending)
+	if err != nil {
+		return nil, false, fmt.Errorf("count pending redemptions: %w", err)
+	}
+	if pending >= maxPending {
+		return nil, false, domain.ErrConflict
+	}
+
+	redemption, err := scanFamilyRedemption(tx.QueryRowContext(ctx, `
+		INSERT INTO family_reward_redemptions (child_profile_id, reward_id, title, icon, price, idempotency_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+familyRedemptionColumns,
+		childProfileID, reward.ID, reward.Title, reward.Icon, reward.Price, key))
+	if err != nil {
+		return nil, false, fmt.Errorf("insert family redemption: %w", err)
+	}
+
+	_, _, err = applyWalletEntryTx(ctx, tx, childProfileID, CurrencyCoins, -reward.Price, WalletSource{
+		Type:        WalletSourceFamilyReward,
+		ID:          strconv.FormatInt(redemption.ID, 10),
+		Key:         "family_reward:" + strconv.FormatInt(redemption.ID, 10),
+		Description: reward.Title,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = notifyFamilyRedemptionTx(ctx, tx, *redemption, FamilyActorParent, FamilyNotificationRewardRequested,
+		"Запрос награды", fmt.Sprintf("Ребёнок хочет получить «%s» за %d монет. Монеты уже списаны и ждут вашего решения.", reward.Title, reward.Price))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("commit tx: %w", err)
+	}
+
+	log.Printf("[Store] Family reward redeemed: child=%s, reward=%d, redemption=%d, price=%d",
+		childProfileID, reward.ID, redemption.ID, reward.Price)
+	return redemption, false, nil
+}
+
+// DecideFamilyRedemption одобряет или отклоняет запрос награды; при отказе монеты
+// возвращаются ребёнку. Повтор того же решения возвращает запрос без изменений, другое
+// решение по уже решённому запросу — domain.ErrConflict.
+func (s *Store) DecideFamilyRedemption(ctx context.Context, childProfileID string, id int64, approve bool, note string, now time.Time) (*FamilyRedemption, error) {
+	status := FamilyRedemptionDeclined
+	if approve {
+		status = FamilyRedemptionApproved
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	redemption, err := scanFamilyRedemption(tx.QueryRowContext(ctx, `
+		SELECT `+familyRedemptionColumns+`
+		FROM family_reward_redemptions
+		WHERE id = $1 AND child_profile_id = $2
+		FOR UPDATE
+	`, id, childProfileID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get family redemption: %w", err)
+	}
+	if redemption.Status == status {
+		return redemption, nil
+	}
+	if redemption.Status != FamilyRedemptionPending {
+		return nil, domain.ErrConflict
+	}
+
+	redemption, err = scanFamilyRedemption(tx.QueryRowContext(ctx, `
+		UPDATE family_reward_redemptions
+		SET status = $2, parent_note = $3, decided_at = $4
+		WHERE id = $1
+		RETURNING `+familyRedemptionColumns,
+		id, status, note, now))
+	if err != nil {
+		return nil, fmt.Errorf("update family redemption: %w", err)
+	}
+
+	kind, title, body := FamilyNotificationRewardApproved, "Награда одобрена!",
+		fmt.Sprintf("Родитель одобрил «%s». Приятного отдыха!", redemption.Title)
+	if !approve {
+		_, _, err = applyWalletEntryTx(ctx, tx, childProfileID, CurrencyCoins, redemption.Price, WalletSource{
+			Type:        WalletSourceFamilyReward,
+			ID:          strconv.FormatInt(redemption.ID, 10),
+			Key:         "family_reward_refund:" + strconv.FormatInt(redemption.ID, 10),
+			Description: "Возврат: " + redemption.Title,
+		})
+		if err != nil {
+			return nil, err
+		}
+		kind, title, body = FamilyNotificationRewardDeclined, "Награда отклонена",
+			fmt.Sprintf("Родитель отклонил «%s». %d монет вернулись на счёт.", redemption.Title, redemption.Price)
+	}
+	if note != "" {
+		body += " Родитель пишет: " + note
+	}
+	if err := notifyFamilyRedemptionTx(ctx, tx, *redemption, FamilyActorChild, kind, title, body); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	log.Printf("[Store] Family redemption %d %s: child=%s, price=%d", redemption.ID, status, childProfileID, redemption.Price)
+	return redemption, nil
+}
+
+// ListFamilyRedemptions ожидающие запросы ребёнка и решённые с since, новые первыми
+func (s *Store) ListFamilyRedemptions(ctx context.Context, childProfileID string, since time.Time) ([]FamilyRedemption, error) {
+	return queryFamilyRedemptions(ctx, s.DB, `
+		SELECT `+familyRedemptionColumns+`
+		FROM family_reward_redemptions
+		WHERE child_profile_id = $1 AND (status = 'pending' OR requested_at >= $2)
+		ORDER BY requested_at DESC, id DESC
+	`, childProfileID, since)
+}
+
+// ListFamilyRedemptionsBetween запросы наград, поданные в [from, to) — для недельного отчёта
+func (s *Store) ListFamilyRedemptionsBetween(ctx context.Context, childProfileID string, from, to time.Time) ([]FamilyRedemption, error) {
+	return queryFamilyRedemptions(ctx, s.DB, `
+		SELECT `+familyRedemptionColumns+`
+		FROM family_reward_redemptions
+		WHERE child_profile_id = $1 AND requested_at >= $2 AND requested_at < $3
+		ORDER BY requested_at, id
+	`, childProfileID, from, to)
+}
+
+// notifyFamilyRedemptionTx создаёт уведомление о запросе награды
+func notifyFamilyRedemptionTx(ctx context.Context, tx *sql.Tx, r FamilyRedemption, recipient, kind, title, body string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO family_notifications (child_profile_id, recipient, kind, redemption_id, title, body)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (redemption_id, recipient, kind) WHERE redemption_id IS NOT NULL DO NOTHING
+	`, r.ChildProfileID, recipient, kind, r.ID, title, body)
+	if err != nil {
+		return fmt.Errorf("insert family notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/domain"
+)
+
+func TestFamilyReward_RedeemDeclineRefunds(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("family_reward"), 500)
+	reward, err := s.CreateFamilyReward(ctx, FamilyReward{
+		ChildProfileID: childID, Title: "30 минут мультиков", Icon: "📺", Price: 300, CreatedBy: "parent",
+	}, 5)
+	if err != nil {
+		t.Fatalf("CreateFamilyReward() error = %v", err)
+	}
+
+	first, replayed, err := s.RedeemFamilyReward(ctx, childID, reward.ID, "key-1", 3)
+	if err != nil || replayed || first.Status != FamilyRedemptionPending {
+		t.Fatalf("RedeemFamilyReward() = %+v, %v, %v; want pending", first, replayed, err)
+	}
+	// Повтор запроса не списывает монеты второй раз
+	if again, replayed, err := s.RedeemFamilyReward(ctx, childID, reward.ID, "key-1", 3); err != nil || !replayed || again.ID != first.ID {
+		t.Errorf("repeated RedeemFamilyReward() = %+v, %v, %v; want replayed", again, replayed, err)
+	}
+	if coins, _ := getBalances(t, db, childID); coins != 200 {
+		t.Errorf("coins after redeem = %d, want 200", coins)
+	}
+	if _, _, err := s.RedeemFamilyReward(ctx, childID, reward.ID, "key-2", 3); !errors.Is(err, domain.ErrInsufficientFunds) {
+		t.Errorf("RedeemFamilyReward() without coins error = %v, want ErrInsufficientFunds", err)
+	}
+
+	declined, err := s.DecideFamilyRedemption(ctx, childID, first.ID, false, "Сначала уроки", time.Now())
+	if err != nil || declined.Status != FamilyRedemptionDeclined {
+		t.Fatalf("DecideFamilyRedemption() = %+v, %v; want declined", declined, err)
+	}
+	if _, err := s.DecideFamilyRedemption(ctx, childID, first.ID, false, "", time.Now()); err != nil {
+		t.Errorf("repeated decline error = %v, want nil", err)
+	}
+	if _, err := s.DecideFamilyRedemption(ctx, childID, first.ID, true, "", time.Now()); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("approve after decline error = %v, want ErrConflict", err)
+	}
+	if coins, _ := getBalances(t, db, childID); coins != 500 {
+		t.Errorf("coins after decline = %d, want 500", coins)
+	}
+
+	notes, _, err := s.ListFamilyNotifications(ctx, childID, FamilyActorChild, 10)
+	if err != nil || len(notes) != 1 || notes[0].Kind != FamilyNotificationRewardDeclined || notes[0].RedemptionID != first.ID {
+		t.Errorf("child notifications = %+v, %v; want one reward_declined", notes, err)
+	}
+}
+
+func TestFamilyReward_PendingLimit(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("family_reward_limit"), 100)
+	reward, err := s.CreateFamilyReward(ctx, FamilyReward{
+		ChildProfileID: childID, Title: "Поход в парк", Icon: "🌳", Price: 10, CreatedBy: "parent",
+	}, 5)
+	if err != nil {
+		t.Fatalf("CreateFamilyReward() error = %v", err)
+	}
+
+	for _, key := range []string{"a", "b"} {
+		if _, _, err := s.RedeemFamilyReward(ctx, childID, reward.ID, key, 2); err != nil {
+			t.Fatalf("RedeemFamilyReward(%s) error = %v", key, err)
+		}
+	}
+	if _, _, err := s.RedeemFamilyReward(ctx, childID, reward.ID, "c", 2); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("third pending redemption error = %v, want ErrConflict", err)
+	}
+
+	// Снятая награда остаётся в истории, но больше не запрашивается
+	if err := s.ArchiveFamilyReward(ctx, childID, reward.ID); err != nil {
+		t.Fatalf("ArchiveFamilyReward() error = %v", err)
+	}
+	list, err := s.ListFamilyRedemptions(ctx, childID, time.Now().Add(-time.Hour))
+	if err != nil || len(list) != 2 {
+		t.Errorf("ListFamilyRedemptions() = %d, %v; want 2", len(list), err)
+	}
+}
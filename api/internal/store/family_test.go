@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/family"
+)
+
+func TestFamilyQuest_ChildAndParentComplete(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	catalog, err := family.Default()
+	if err != nil {
+		t.Fatalf("family.Default() error = %v", err)
+	}
+	if _, err := s.SyncFamilyQuestTemplates(ctx, catalog.Version, catalog.Templates); err != nil {
+		t.Fatalf("SyncFamilyQuestTemplates error = %v", err)
+	}
+	var teachMe family.Template
+	for _, tpl := range catalog.Templates {
+		if tpl.ID == "teach_me" {
+			teachMe = tpl
+		}
+	}
+
+	child := createTestProfile(t, db, testID("family"), 0)
+	now := time.Now()
+	quest, err := s.StartFamilyQuest(ctx, child, teachMe, FamilyActorParent, now, now.Add(72*time.Hour))
+	if err != nil {
+		t.Fatalf("StartFamilyQuest error = %v", err)
+	}
+	if _, err := s.StartFamilyQuest(ctx, child, teachMe, FamilyActorChild, now, now.Add(72*time.Hour)); !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("second StartFamilyQuest error = %v, want ErrConflict", err)
+	}
+
+	// Неверная проверка tasks_correct не двигает, повтор той же проверки — тоже
+	at := now.Add(time.Minute)
+	for _, check := range []struct {
+		attempt string
+		correct bool
+	}{{"a1", true}, {"a1", true}, {"a2", false}, {"a3", true}, {"a4", true}} {
+		if _, err := s.AdvanceFamilyQuestsForCheck(ctx, child, check.attempt, check.correct, at, time.UTC); err != nil {
+			t.Fatalf("AdvanceFamilyQuestsForCheck(%s) error = %v", check.attempt, err)
+		}
+	}
+
+	var res *FamilyQuestAdvanceResult
+	for _, key := range []string{"c1", "c1", "c2", "c3"} {
+		if _, res, err = s.ConfirmFamilyQuest(ctx, child, quest.ID, key, "разобрали задачу", at); err != nil {
+			t.Fatalf("ConfirmFamilyQuest(%s) error = %v", key, err)
+		}
+	}
+	if len(res.Completed) != 1 || res.Completed[0].ChildProgress != 3 || res.Completed[0].ParentProgress != 3 {
+		t.Fatalf("last confirmation = %+v, want the quest completed with 3/3 and 3/3", res)
+	}
+
+	// Повтор подтверждения после завершения не конфликт, новое — конфликт
+	if _, _, err := s.ConfirmFamilyQuest(ctx, child, quest.ID, "c3", "", at); err != nil {
+		t.Errorf("repeated ConfirmFamilyQuest error = %v, want nil", err)
+	}
+	if _, _, err := s.ConfirmFamilyQuest(ctx, child, quest.ID, "c4", "", at); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("ConfirmFamilyQuest after completion error = %v, want ErrConflict", err)
+	}
+
+	var coins int
+	if err := db.QueryRow(`SELECT coins_balance FROM child_profiles WHERE id = $1`, child).Scan(&coins); err != nil {
+		t.Fatalf("get coins: %v", err)
+	}
+	if coins != teachMe.Reward.Coins {
+		t.Errorf("coins = %d, want %d", coins, teachMe.Reward.Coins)
+	}
+
+	for _, recipient := range []string{FamilyActorChild, FamilyActorParent} {
+		notes, unread, err := s.ListFamilyNotifications(ctx, child, recipient, 10)
+		if err != nil || len(notes) != 1 || unread != 1 || notes[0].QuestID != quest.ID {
+			t.Fatalf("ListFamilyNotifications(%s) = %+v, %d, %v, want one unread", recipient, notes, unread, err)
+		}
+		if err := s.MarkFamilyNotificationRead(ctx, child, recipient, notes[0].ID); err != nil {
+			t.Fatalf("MarkFamilyNotificationRead error = %v", err)
+		}
+	}
+}
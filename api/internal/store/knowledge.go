@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/knowledge"
+)
+
+// KnowledgeSyncResult итог синхронизации дерева тем
+type KnowledgeSyncResult struct {
+	Upserted    int
+	Deactivated int
+}
+
+// KnowledgeEvidenceResult итог учёта проверки в карте знаний
+type KnowledgeEvidenceResult struct {
+	Updated []string // узлы, освоение которых изменилось
+	Skipped []string // узлы, которым эта проверка уже засчитана
+}
+
+// SyncKnowledgeNodes в одной транзакции добавляет и обновляет узлы каталога.
+// Узлы, которых больше нет в каталоге, выключаются, а не удаляются: по ним есть освоение детей.
+func (s *Store) SyncKnowledgeNodes(ctx context.Context, version int, nodes []knowledge.Node) (*KnowledgeSyncResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Разделы идут в каталоге перед своими темами, поэтому parent_id уже существует
+	query := `
+		INSERT INTO knowledge_nodes (id, subject, parent_id, kind, title, grade_min, grade_max,
+		                             template_code, task_types, sort_order, catalog_version, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, true)
+		ON CONFLICT (id) DO UPDATE
+		SET subject = EXCLUDED.subject,
+		    parent_id = EXCLUDED.parent_id,
+		    kind = EXCLUDED.kind,
+		    title = EXCLUDED.title,
+		    grade_min = EXCLUDED.grade_min,
+		    grade_max = EXCLUDED.grade_max,
+		    template_code = EXCLUDED.template_code,
+		    task_types = EXCLUDED.task_types,
+		    sort_order = EXCLUDED.sort_order,
+		    catalog_version = EXCLUDED.catalog_version,
+		    is_active = true,
+		    updated_at = NOW()
+	`
+	ids := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		taskTypes := n.TaskTypes
+		if taskTypes == nil {
+			taskTypes = []string{}
+		}
+		_, err := tx.ExecContext(ctx, query,
+			n.ID, n.Subject, sql.NullString{String: n.ParentID, Valid: n.ParentID != ""}, n.Kind, n.Title,
+			n.GradeMin, n.GradeMax, sql.NullString{String: n.TemplateCode, Valid: n.TemplateCode != ""},
+			pq.Array(taskTypes), n.SortOrder, version)
+		if err != nil {
+			return nil, fmt.Errorf("upsert knowledge node %s: %w", n.ID, err)
+		}
+		ids = append(ids, n.ID)
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE knowledge_nodes
+		SET is_active = false, updated_at = NOW()
+		WHERE is_active AND NOT (id = ANY($1))
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("deactivate knowledge nodes: %w", err)
+	}
+	deactivated, _ := res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	result := &KnowledgeSyncResult{Upserted: len(nodes), Deactivated: int(deactivated)}
+	log.Printf("[Store] Knowledge nodes synced: upserted=%d, deactivated=%d", result.Upserted, result.Deactivated)
+	return result, nil
+}
+
+// GetKnowledgeNodes активные узлы дерева предмета в порядке каталога
+func (s *Store) GetKnowledgeNodes(ctx context.Context, subject string) ([]knowledge.Node, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, subject, COALESCE(parent_id, ''), kind, title, grade_min, grade_max,
+		       COALESCE(template_code, ''), task_types, sort_order
+		FROM knowledge_nodes
+		WHERE subject = $1 AND is_active
+		ORDER BY (parent_id IS NOT NULL), sort_order, id
+	`, subject)
+	if err != nil {
+		return nil, fmt.Errorf("query knowledge nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []knowledge.Node
+	for rows.Next() {
+		var n knowledge.Node
+		if err := rows.Scan(&n.ID, &n.Subject, &n.ParentID, &n.Kind, &n.Title, &n.GradeMin, &n.GradeMax,
+			&n.TemplateCode, pq.Array(&n.TaskTypes), &n.SortOrder); err != nil {
+			return nil, fmt.Errorf("scan knowledge node: %w", err)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, rows.Err()
+}
+
+// GetKnowledgeProgress освоение узлов ребёнком по id узла
+func (s *Store) GetKnowledgeProgress(ctx context.Context, childProfileID string) (map[string]knowledge.Progress, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT node_id, attempts, correct, hints_used, mastery, last_practiced_at
+		FROM child_knowledge_mastery
+		WHERE child_profile_id = $1
+	`, childProfileID)
+	if err != nil {
+		return nil, fmt.Errorf("query knowledge progress: %w", err)
+	}
+	defer rows.Close()
+
+	progress := make(map[string]knowledge.Progress)
+	for rows.Next() {
+		var nodeID string
+		var p knowledge.Progress
+		var lastPracticedAt sql.NullTime
+		if err := rows.Scan(&nodeID, &p.Attempts, &p.Correct, &p.HintsUsed, &p.Mastery, &lastPracticedAt); err != nil {
+			return nil, fmt.Errorf("scan knowledge progress: %w", err)
+		}
+		if lastPracticedAt.Valid {
+			p.LastPracticedAt = &lastPracticedAt.Time
+		}
+		progress[nodeID] = p
+	}
+	return progress, rows.Err()
+}
+
+// RecordKnowledgeEvidence засчитывает проверку attemptID узлам nodeIDs и пересчитывает их освоение.
+// Одна проверка засчитывается узлу один раз: повтор события освоение не меняет.
+func (s *Store) RecordKnowledgeEvidence(ctx context.Context, childProfileID, attemptID string, nodeIDs []string, isCorrect bool, hintsUsed int, now time.Time) (*KnowledgeEvidenceResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	score := knowledge.EvidenceScore(isCorrect, hintsUsed)
+	correct := 0
+	if isCorrect {
+		correct = 1
+	}
+
+	result := &KnowledgeEvidenceResult{}
+	for _, nodeID := range nodeIDs {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO knowledge_evidence (child_profile_id, attempt_id, node_id, score, is_correct, hints_used, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (child_profile_id, attempt_id, node_id) DO NOTHING
+		`, childProfileID, attemptID, nodeID, score, isCorrect, hintsUsed, now)
+		if err != nil {
+			return nil, fmt.Errorf("insert knowledge evidence %s: %w", nodeID, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			result.Skipped = append(result.Skipped, nodeID)
+			continue
+		}
+
+		var attempts, mastery int
+		err = tx.QueryRowContext(ctx, `
+			SELECT attempts, mastery FROM child_knowledge_mastery
+			WHERE child_profile_id = $1 AND node_id = $2
+			FOR UPDATE
+		`, childProfileID, nodeID).Scan(&attempts, &mastery)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("lock knowledge mastery %s: %w", nodeID, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO child_knowledge_mastery (child_profile_id, node_id, attempts, correct, hints_used,
+			                                     mastery, last_correct, last_practiced_at, updated_at)
+			VALUES ($1, $2, 1, $3, $4, $5, $6, $7, NOW())
+			ON CONFLICT (child_profile_id, node_id) DO UPDATE
+			SET attempts = child_knowledge_mastery.attempts + 1,
+			    correct = child_knowledge_mastery.correct + EXCLUDED.correct,
+			    hints_used = child_knowledge_mastery.hints_used + EXCLUDED.hints_used,
+			    mastery = EXCLUDED.mastery,
+			    last_correct = EXCLUDED.last_correct,
+			    last_practiced_at = EXCLUDED.last_practiced_at,
+			    updated_at = NOW()
+		`, childProfileID, nodeID, correct, hintsUsed, knowledge.UpdateMastery(mastery, attempts, score), isCorrect, now)
+		if err != nil {
+			return nil, fmt.Errorf("update knowledge mastery %s: %w", nodeID, err)
+		}
+		result.Updated = append(result.Updated, nodeID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit knowledge evidence: %w", err)
+	}
+	return result, nil
+}
+
+// GetChildGrade класс ребёнка из профиля
+func (s *Store) GetChildGrade(ctx context.Context, childProfileID string) (int, error) {
+	var grade sql.NullInt64
+	err := s.DB.QueryRowContext(ctx,
+		`SELECT grade FROM child_profiles WHERE id = $1`, childProfileID,
+	).Scan(&grade)
+	if err == sql.ErrNoRows {
+		return 0, domain.ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get child grade: %w", err)
+	}
+	return int(grade.Int64), nil
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/knowledge"
+)
+
+func TestRecordKnowledgeEvidence_OncePerAttempt(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	catalog, err := knowledge.Default()
+	if err != nil {
+		t.Fatalf("knowledge.Default() error = %v", err)
+	}
+	if _, err := s.SyncKnowledgeNodes(ctx, catalog.Version, catalog.Nodes()); err != nil {
+		t.Fatalf("SyncKnowledgeNodes error = %v", err)
+	}
+
+	nodes, err := s.GetKnowledgeNodes(ctx, "math")
+	if err != nil {
+		t.Fatalf("GetKnowledgeNodes error = %v", err)
+	}
+	if len(nodes) != len(catalog.Nodes()) {
+		t.Fatalf("GetKnowledgeNodes = %d nodes, want %d", len(nodes), len(catalog.Nodes()))
+	}
+	topic := knowledge.Resolve(nodes, []knowledge.Item{{TemplateCode: "T45"}})
+	if len(topic) != 1 {
+		t.Fatalf("Resolve(T45) = %v, want one topic", topic)
+	}
+
+	child := createTestProfile(t, db, testID("knowledge"), 0)
+	now := time.Now()
+
+	res, err := s.RecordKnowledgeEvidence(ctx, child, "attempt-1", topic, false, 0, now)
+	if err != nil {
+		t.Fatalf("RecordKnowledgeEvidence error = %v", err)
+	}
+	if len(res.Updated) != 1 {
+		t.Errorf("first evidence updated = %v, want %v", res.Updated, topic)
+	}
+	if _, err := s.RecordKnowledgeEvidence(ctx, child, "attempt-2", topic, true, 1, now); err != nil {
+		t.Fatalf("RecordKnowledgeEvidence error = %v", err)
+	}
+
+	// Повтор той же проверки освоение не меняет
+	res, err = s.RecordKnowledgeEvidence(ctx, child, "attempt-2", topic, true, 1, now)
+	if err != nil {
+		t.Fatalf("repeated RecordKnowledgeEvidence error = %v", err)
+	}
+	if len(res.Updated) != 0 || len(res.Skipped) != 1 {
+		t.Errorf("repeated evidence = %+v, want skipped", res)
+	}
+
+	progress, err := s.GetKnowledgeProgress(ctx, child)
+	if err != nil {
+		t.Fatalf("GetKnowledgeProgress error = %v", err)
+	}
+	got := progress[topic[0]]
+	// 0, затем верно с подсказкой (80) поровну -> 40
+	if got.Attempts != 2 || got.Correct != 1 || got.HintsUsed != 1 || got.Mastery != 40 {
+		t.Errorf("progress = %+v, want 2 attempts, 1 correct, 1 hint, mastery 40", got)
+	}
+
+	grade, err := s.GetChildGrade(ctx, child)
+	if err != nil || grade != 3 {
+		t.Errorf("GetChildGrade = %d, %v, want 3", grade, err)
+	}
+}
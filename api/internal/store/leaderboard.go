@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Метрики leaderboard
+const (
+	LeaderboardMetricTasks    = "tasks"
+	LeaderboardMetricXP       = "xp"
+	LeaderboardMetricVillains = "villains"
+)
+
+// LeaderboardRow строка leaderboard друзей
+type LeaderboardRow struct {
+	ChildProfileID   string
+	DisplayName      string
+	AvatarID         string
+	Level            int
+	TasksSolved      int
+	XPGained         int
+	VillainsDefeated int
+	Rank             int // одинаковый счёт — одинаковое место
+}
+
+// GetFriendsLeaderboard ранжирует ребёнка и его друзей (рефералы в обе стороны)
+// по выбранной метрике с момента since (нулевое время — за всё время).
+// Возвращает первые limit мест и всегда строку самого ребёнка.
+func (s *Store) GetFriendsLeaderboard(ctx context.Context, childProfileID, metric string, since time.Time, limit int) ([]LeaderboardRow, error) {
+	query := `
+		WITH members AS (
+			SELECT $1::UUID AS id
+			UNION
+			SELECT referred_id FROM referrals WHERE referrer_id = $1::UUID
+			UNION
+			SELECT referrer_id FROM referrals WHERE referred_id = $1::UUID
+		),
+		scores AS (
+			SELECT cp.id, cp.display_name, COALESCE(cp.avatar_id, '') AS avatar_id, COALESCE(cp.level, 1) AS level,
+			       (SELECT COUNT(*)
+			        FROM attempts a
+			        WHERE a.child_profile_id = cp.id
+			          AND a.attempt_type = 'check' AND a.status = 'completed' AND a.is_correct = TRUE
+			          AND ($3::TIMESTAMPTZ IS NULL OR a.completed_at >= $3)) AS tasks,
+			       CASE WHEN $3::TIMESTAMPTZ IS NULL THEN COALESCE(cp.xp_total, 0)::BIGINT
+			            ELSE (SELECT COALESCE(SUM(wt.amount), 0)
+			                  FROM wallet_transactions wt
+			                  WHERE wt.child_profile_id = cp.id
+			                    AND wt.currency = 'xp' AND wt.amount > 0
+			                    AND wt.source <> 'opening_balance'
+			                    AND wt.created_at >= $3)
+			       END AS xp,
+			       (SELECT COUNT(*)
+			        FROM villain_battles vb
+			        WHERE vb.child_profile_id = cp.id AND vb.status = 'defeated'
+			          AND ($3::TIMESTAMPTZ IS NULL OR vb.defeated_at >= $3)) AS villains
+			FROM members m
+			JOIN child_profiles cp ON cp.id = m.id
+		),
+		ranked AS (
+			SELECT scores.*,
+			       CASE $2 WHEN 'xp' THEN xp WHEN 'villains' THEN villains ELSE tasks END AS score
+			FROM scores
+		),
+		positioned AS (
+			SELECT ranked.*,
+			       RANK() OVER (ORDER BY score DESC) AS rank,
+			       ROW_NUMBER() OVER (ORDER BY score DESC, level DESC, id) AS pos
+			FROM ranked
+		)
+		SELECT id, display_name, avatar_id, level, tasks, xp, villains, rank
+		FROM positioned
+		WHERE pos <= $4 OR id = $1::UUID
+		ORDER BY pos
+	`
+	sinceArg := sql.NullTime{Time: since, Valid: !since.IsZero()}
+	rows, err := s.DB.QueryContext(ctx, query, childProfileID, metric, sinceArg, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get friends leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	var result []LeaderboardRow
+	for rows.Next() {
+		var r LeaderboardRow
+		if err := rows.Scan(&r.ChildProfileID, &r.DisplayName, &r.AvatarID, &r.Level,
+			&r.TasksSolved, &r.XPGained, &r.VillainsDefeated, &r.Rank); err != nil {
+			return nil, fmt.Errorf("scan leaderboard row: %w", err)
+		}
+		result = append(result, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate leaderboard: %w", err)
+	}
+
+	return result, nil
+}
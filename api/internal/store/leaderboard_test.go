@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+// linkFriends creates a referral between two profiles
+func linkFriends(t *testing.T, db *sql.DB, referrerID, referredID string) {
+	t.Helper()
+
+	query := `INSERT INTO referrals (referrer_id, referred_id, is_active) VALUES ($1, $2, TRUE)`
+	if _, err := db.Exec(query, referrerID, referredID); err != nil {
+		t.Fatalf("failed to create referral: %v", err)
+	}
+}
+
+// addCorrectAttempts creates completed correct check attempts finished at completedAt
+func addCorrectAttempts(t *testing.T, db *sql.DB, childProfileID string, count int, completedAt time.Time) {
+	t.Helper()
+
+	query := `
+		INSERT INTO attempts (child_profile_id, attempt_type, status, is_correct, completed_at)
+		VALUES ($1, 'check', 'completed', TRUE, $2)
+	`
+	for i := 0; i < count; i++ {
+		if _, err := db.Exec(query, childProfileID, completedAt); err != nil {
+			t.Fatalf("failed to create attempt: %v", err)
+		}
+	}
+}
+
+func TestGetFriendsLeaderboard_IncludesCurrentChild(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	me := createTestProfile(t, db, testID("lb_me"), 0)
+	friendA := createTestProfile(t, db, testID("lb_a"), 0)
+	friendB := createTestProfile(t, db, testID("lb_b"), 0)
+	stranger := createTestProfile(t, db, testID("lb_stranger"), 0)
+
+	// Дружба в обе стороны: я пригласил A, B пригласил меня
+	linkFriends(t, db, me, friendA)
+	linkFriends(t, db, friendB, me)
+
+	now := time.Now()
+	addCorrectAttempts(t, db, friendA, 3, now)
+	addCorrectAttempts(t, db, friendB, 2, now)
+	addCorrectAttempts(t, db, me, 1, now)
+	addCorrectAttempts(t, db, stranger, 10, now)
+
+	rows, err := s.GetFriendsLeaderboard(ctx, me, LeaderboardMetricTasks, time.Time{}, 1)
+	if err != nil {
+		t.Fatalf("GetFriendsLeaderboard() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("rows = %+v, want top 1 plus current child", rows)
+	}
+	if rows[0].ChildProfileID != friendA || rows[0].Rank != 1 || rows[0].TasksSolved != 3 {
+		t.Errorf("first row = %+v, want friend A with rank 1 and 3 tasks", rows[0])
+	}
+	if rows[1].ChildProfileID != me || rows[1].Rank != 3 {
+		t.Errorf("last row = %+v, want current child with rank 3", rows[1])
+	}
+}
+
+func TestGetFriendsLeaderboard_Period(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	me := createTestProfile(t, db, testID("lb_period_me"), 0)
+	friend := createTestProfile(t, db, testID("lb_period_friend"), 0)
+	linkFriends(t, db, me, friend)
+
+	now := time.Now()
+	addCorrectAttempts(t, db, friend, 5, now.AddDate(0, -2, 0))
+	addCorrectAttempts(t, db, me, 1, now)
+
+	rows, err := s.GetFriendsLeaderboard(ctx, me, LeaderboardMetricTasks, now.AddDate(0, 0, -7), 10)
+	if err != nil {
+		t.Fatalf("GetFriendsLeaderboard() error = %v", err)
+	}
+	if len(rows) != 2 || rows[0].ChildProfileID != me || rows[0].TasksSolved != 1 || rows[1].TasksSolved != 0 {
+		t.Errorf("weekly rows = %+v, want current child first with old attempts excluded", rows)
+	}
+
+	rows, err = s.GetFriendsLeaderboard(ctx, me, LeaderboardMetricTasks, time.Time{}, 10)
+	if err != nil {
+		t.Fatalf("GetFriendsLeaderboard() error = %v", err)
+	}
+	if len(rows) != 2 || rows[0].ChildProfileID != friend || rows[0].TasksSolved != 5 {
+		t.Errorf("all-time rows = %+v, want friend first with 5 tasks", rows)
+	}
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/level"
+)
+
+// LevelUp переход ребёнка на уровень с выданными наградами
+type LevelUp struct {
+	Level      int
+	Rewards    []level.Reward
+	Backfilled bool // уровень пройден до появления таблицы наград
+	CreatedAt  time.Time
+	SeenAt     *time.Time
+}
+
+// LevelBackfillResult итог выдачи наград за уже пройденные уровни
+type LevelBackfillResult struct {
+	Children int
+	Levels   int
+}
+
+// grantLevelRewardsTx записывает переход на уровень и выдаёт его награды. Повторный
+// переход на тот же уровень ничего не выдаёт и возвращает false. При backfill монеты
+// не начисляются: за пройденные уровни они уже выданы прежним правилом.
+func grantLevelRewardsTx(ctx context.Context, tx *sql.Tx, childProfileID string, l level.Level, backfill bool) (bool, error) {
+	granted := make([]level.Reward, 0, len(l.Rewards))
+	for _, r := range l.Rewards {
+		if backfill && r.Type == level.RewardCoins {
+			continue
+		}
+		granted = append(granted, r)
+	}
+	data, err := json.Marshal(granted)
+	if err != nil {
+		return false, fmt.Errorf("marshal level rewards: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO child_level_ups (child_profile_id, level, rewards, backfilled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (child_profile_id, level) DO NOTHING
+	`, childProfileID, l.Level, data, backfill)
+	if err != nil {
+		return false, fmt.Errorf("record level up: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return false, nil
+	}
+
+	for _, r := range granted {
+		switch r.Type {
+		case level.RewardCoins:
+			// Ключ по номеру уровня, как и у прежней награды за уровень
+			_, _, err = applyWalletEntryTx(ctx, tx, childProfileID, CurrencyCoins, r.Amount, WalletSource{
+				Type:        WalletSourceLevelUp,
+				ID:          strconv.Itoa(l.Level),
+				Description: fmt.Sprintf("Уровень %d", l.Level),
+			})
+		case level.RewardAvatar, level.RewardMascotItem:
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO child_inventory (child_profile_id, item_id, quantity)
+				VALUES ($1, $2, 1)
+				ON CONFLICT (child_profile_id, item_id) DO NOTHING
+			`, childProfileID, r.ID)
+		case level.RewardTitle:
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO child_rewards (child_profile_id, reward_type, reward_id, reward_name)
+				VALUES ($1, 'title', $2, $3)
+				ON CONFLICT (child_profile_id, reward_type, reward_id) DO NOTHING
+			`, childProfileID, r.ID, r.Name)
+		}
+		// Скидка в магазине следует из уровня и не хранится
+		if err != nil {
+			return false, fmt.Errorf("grant level %d reward %s: %w", l.Level, r.Type, err)
+		}
+	}
+
+	log.Printf("[Store] 🎁 Level %d rewards granted: child=%s, rewards=%d, backfill=%v",
+		l.Level, childProfileID, len(granted), backfill)
+	return true, nil
+}
+
+// BackfillLevelRewards выдаёт награды таблицы за уровни, которые дети прошли раньше,
+// чем уровень стал записываться. Повторный запуск ничего не меняет.
+func (s *Store) BackfillLevelRewards(ctx context.Context, t *level.Table) (*LevelBackfillResult, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT cp.id
+		FROM child_profiles cp
+		WHERE COALESCE(cp.level, 1) > 1
+		  AND (SELECT COUNT(*) FROM child_level_ups u
+		       WHERE u.child_profile_id = cp.id AND u.level <= cp.level) < cp.level - 1
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list children for level backfill: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan child for level backfill: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate children for level backfill: %w", err)
+	}
+
+	result := &LevelBackfillResult{}
+	for _, id := range ids {
+		n, err := s.backfillChildLevels(ctx, id, t)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			result.Children++
+			result.Levels += n
+		}
+	}
+
+	log.Printf("[Store] Level rewards backfilled: children=%d, levels=%d", result.Children, result.Levels)
+	return result, nil
+}
+
+// backfillChildLevels выдаёт награды за пройденные уровни одного ребёнка
+func (s *Store) backfillChildLevels(ctx context.Context, childProfileID string, t *level.Table) (int, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current int
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(level, 1) FROM child_profiles WHERE id = $1 FOR UPDATE`, childProfileID).Scan(&current)
+	if err != nil {
+		return 0, fmt.Errorf("lock profile: %w", err)
+	}
+
+	granted := 0
+	for l := 2; l <= current; l++ {
+		ok, err := grantLevelRewardsTx(ctx, tx, childProfileID, t.Level(l), true)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			granted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return granted, nil
+}
+
+// MissingShopItems предметы из ids, которых нет в каталоге магазина
+func (s *Store) MissingShopItems(ctx context.Context, ids []string) ([]string, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT u.id FROM unnest($1::TEXT[]) AS u(id)
+		WHERE NOT EXISTS (SELECT 1 FROM shop_items i WHERE i.id = u.id)
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("check shop items: %w", err)
+	}
+	defer rows.Close()
+
+	var missing []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan shop item id: %w", err)
+		}
+		missing = append(missing, id)
+	}
+	return missing, rows.Err()
+}
+
+// ListLevelUps переходы ребёнка на уровни, новые первыми; unseenOnly — только без поздравления
+func (s *Store) ListLevelUps(ctx context.Context, childProfileID string, unseenOnly bool) ([]LevelUp, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT level, rewards, backfilled, created_at, seen_at
+		FROM child_level_ups
+		WHERE child_profile_id = $1 AND (NOT $2 OR seen_at IS NULL)
+		ORDER BY level DESC
+	`, childProfileID, unseenOnly)
+	if err != nil {
+		return nil, fmt.Errorf("list level ups: %w", err)
+	}
+	defer rows.Close()
+
+	var ups []LevelUp
+	for rows.Next() {
+		var u LevelUp
+		var rewards []byte
+		var seenAt sql.NullTime
+		if err := rows.Scan(&u.Level, &rewards, &u.Backfilled, &u.CreatedAt, &seenAt); err != nil {
+			return nil, fmt.Errorf("scan level up: %w", err)
+		}
+		if err := json.Unmarshal(rewards, &u.Rewards); err != nil {
+			return nil, fmt.Errorf("unmarshal level rewards: %w", err)
+		}
+		if seenAt.Valid {
+			u.SeenAt = &seenAt.Time
+		}
+		ups = append(ups, u)
+	}
+	return ups, rows.Err()
+}
+
+// MarkLevelUpsSeen отмечает поздравления с уровнями до upTo включительно просмотренными
+func (s *Store) MarkLevelUpsSeen(ctx context.Context, childProfileID string, upTo int) (int, error) {
+	if upTo < 2 {
+		return 0, domain.ErrInvalidInput
+	}
+	result, err := s.DB.ExecContext(ctx, `
+		UPDATE child_level_ups
+		SET seen_at = NOW()
+		WHERE child_profile_id = $1 AND level <= $2 AND seen_at IS NULL
+	`, childProfileID, upTo)
+	if err != nil {
+		return 0, fmt.Errorf("mark level ups seen: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
+
+// ShopPrice цена предмета для ребёнка уровня childLevel: на усилители действует
+// скидка из таблицы уровней
+func (c XPConfig) ShopPrice(item ShopItem, childLevel int) int {
+	if item.Category != ShopCategoryPowerUp {
+		return item.Price
+	}
+	return level.Discounted(item.Price, c.Levels.ShopDiscount(childLevel))
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAddXP_GrantsLevelTableRewards(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("level_rewards"), 0)
+	src := WalletSource{Type: WalletSourceVillainBattle, ID: testID("battle")}
+
+	// Скачок сразу через два уровня: награды выдаются за каждый
+	if _, _, err := s.AddXP(ctx, childID, s.XP.XPForLevel(2), s.XP, src); err != nil {
+		t.Fatalf("AddXP() error = %v", err)
+	}
+
+	ups, err := s.ListLevelUps(ctx, childID, true)
+	if err != nil {
+		t.Fatalf("ListLevelUps() error = %v", err)
+	}
+	if len(ups) != 2 || ups[0].Level != 3 || ups[1].Level != 2 {
+		t.Fatalf("level ups = %+v, want levels 3 and 2", ups)
+	}
+
+	coins, _ := getBalances(t, db, childID)
+	if coins != 200 {
+		t.Errorf("coins = %d, want 200", coins)
+	}
+	for _, item := range []string{"tiger", "mascot_cap"} {
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM child_inventory WHERE child_profile_id = $1 AND item_id = $2`, childID, item).Scan(&n); err != nil || n != 1 {
+			t.Errorf("inventory %s = %d (err %v), want 1", item, n, err)
+		}
+	}
+
+	if n, err := s.MarkLevelUpsSeen(ctx, childID, 3); err != nil || n != 2 {
+		t.Errorf("MarkLevelUpsSeen() = %d, %v; want 2, nil", n, err)
+	}
+}
+
+func TestBackfillLevelRewards_SkipsCoinsAndIsIdempotent(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("level_backfill"), 0)
+	if _, err := db.Exec(`UPDATE child_profiles SET xp_total = $2, level = 5 WHERE id = $1`, childID, s.XP.XPForLevel(4)); err != nil {
+		t.Fatalf("set level: %v", err)
+	}
+
+	if _, err := s.BackfillLevelRewards(ctx, s.XP.Levels); err != nil {
+		t.Fatalf("BackfillLevelRewards() error = %v", err)
+	}
+	if _, err := s.BackfillLevelRewards(ctx, s.XP.Levels); err != nil {
+		t.Fatalf("repeated BackfillLevelRewards() error = %v", err)
+	}
+
+	ups, err := s.ListLevelUps(ctx, childID, false)
+	if err != nil {
+		t.Fatalf("ListLevelUps() error = %v", err)
+	}
+	if len(ups) != 4 || !ups[0].Backfilled {
+		t.Fatalf("level ups = %+v, want 4 backfilled", ups)
+	}
+
+	// Монеты за эти уровни уже выданы прежним правилом
+	if coins, _ := getBalances(t, db, childID); coins != 0 {
+		t.Errorf("coins = %d, want 0", coins)
+	}
+	var titles int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM child_rewards WHERE child_profile_id = $1 AND reward_type = 'title'`, childID).Scan(&titles); err != nil || titles != 2 {
+		t.Errorf("titles = %d (err %v), want 2", titles, err)
+	}
+}
@@ -0,0 +1,358 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"child-bot/api/internal/mission"
+)
+
+// Статусы миссии дня
+const (
+	DailyMissionActive    = "active"
+	DailyMissionCompleted = "completed"
+)
+
+// MissionSyncResult итог синхронизации каталога миссий
+type MissionSyncResult struct {
+	Upserted    int
+	Deactivated int
+}
+
+// DailyMission миссия ребёнка на день
+type DailyMission struct {
+	ID             int64
+	ChildProfileID string
+	MissionDate    string // YYYY-MM-DD по календарю ребёнка
+	Slot           int
+	MissionID      string
+	Metric         string
+	Subject        string
+	Target         int
+	Progress       int
+	RewardCoins    int
+	RewardXP       int
+	Icon           string
+	Title          string
+	Description    string
+	Status         string
+	CompletedAt    *time.Time
+}
+
+// MissionAdvanceResult итог засчитывания действия миссиям дня
+type MissionAdvanceResult struct {
+	Advanced  []DailyMission // миссии, прогресс которых изменился
+	Completed []DailyMission // из них выполненные этим действием
+	AllDone   bool           // этим действием выполнены все миссии дня
+	LeveledUp bool
+}
+
+// SyncMissionDefinitions в одной транзакции добавляет и обновляет миссии каталога.
+// Миссии, которых больше нет в каталоге, выключаются: на них ссылаются выданные миссии.
+func (s *Store) SyncMissionDefinitions(ctx context.Context, version int, defs []mission.Definition) (*MissionSyncResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO mission_definitions (id, group_key, metric, subject, target, reward_coins, reward_xp,
+		                                 weight, rules, icon, title, description, catalog_version, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, true)
+		ON CONFLICT (id) DO UPDATE
+		SET group_key = EXCLUDED.group_key,
+		    metric = EXCLUDED.metric,
+		    subject = EXCLUDED.subject,
+		    target = EXCLUDED.target,
+		    reward_coins = EXCLUDED.reward_coins,
+		    reward_xp = EXCLUDED.reward_xp,
+		    weight = EXCLUDED.weight,
+		    rules = EXCLUDED.rules,
+		    icon = EXCLUDED.icon,
+		    title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    catalog_version = EXCLUDED.catalog_version,
+		    is_active = true,
+		    updated_at = NOW()
+	`
+	ids := make([]string, 0, len(defs))
+	for _, d := range defs {
+		rules, err := json.Marshal(d.When)
+		if err != nil {
+			return nil, fmt.Errorf("marshal mission rules %s: %w", d.ID, err)
+		}
+		_, err = tx.ExecContext(ctx, query,
+			d.ID, d.Group, d.Metric, sql.NullString{String: d.Subject, Valid: d.Subject != ""}, d.Target,
+			d.Reward.Coins, d.Reward.XP, d.Weight, rules, d.Icon, d.Title, d.Description, version)
+		if err != nil {
+			return nil, fmt.Errorf("upsert mission definition %s: %w", d.ID, err)
+		}
+		ids = append(ids, d.ID)
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE mission_definitions
+		SET is_active = false, updated_at = NOW()
+		WHERE is_active AND NOT (id = ANY($1))
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("deactivate mission definitions: %w", err)
+	}
+	deactivated, _ := res.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	result := &MissionSyncResult{Upserted: len(defs), Deactivated: int(deactivated)}
+	log.Printf("[Store] Mission definitions synced: upserted=%d, deactivated=%d", result.Upserted, result.Deactivated)
+	return result, nil
+}
+
+// GetMissionHistory сводка проверок ребёнка с момента since для выбора миссий
+func (s *Store) GetMissionHistory(ctx context.Context, childProfileID string, since time.Time) (*mission.History, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT COALESCE(NULLIF(parse_result->'task'->>'subject', ''), 'math') AS subject,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE is_correct),
+		       COUNT(*) FILTER (WHERE hints_used > 0),
+		       COUNT(*) FILTER (WHERE has_errors AND is_correct IS NOT TRUE)
+		FROM attempts
+		WHERE child_profile_id = $1 AND attempt_type = 'check' AND status = 'completed' AND created_at >= $2
+		GROUP BY 1
+	`, childProfileID, since)
+	if err != nil {
+		return nil, fmt.Errorf("query mission history: %w", err)
+	}
+	defer rows.Close()
+
+	h := &mission.History{Subjects: make(map[string]int)}
+	for rows.Next() {
+		var subject string
+		var tasks, correct, withHints, unfixed int
+		if err := rows.Scan(&subject, &tasks, &correct, &withHints, &unfixed); err != nil {
+			return nil, fmt.Errorf("scan mission history: %w", err)
+		}
+		h.Subjects[subject] = tasks
+		h.Tasks += tasks
+		h.Correct += correct
+		h.WithHints += withHints
+		h.UnfixedErrors += unfixed
+	}
+	return h, rows.Err()
+}
+
+// CreateDailyMissions выдаёт ребёнку миссии на день date в порядке defs.
+// Если миссии на этот день уже выданы, ничего не меняет.
+func (s *Store) CreateDailyMissions(ctx context.Context, childProfileID, date string, defs []mission.Definition) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем профиль: миссии дня выдаются один раз, даже если выбор запустили одновременно
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM child_profiles WHERE id = $1 FOR UPDATE`, childProfileID); err != nil {
+		return fmt.Errorf("lock child profile: %w", err)
+	}
+	var exists bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM child_daily_missions WHERE child_profile_id = $1 AND mission_date = $2)
+	`, childProfileID, date).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check daily missions: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	for i, d := range defs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO child_daily_missions (child_profile_id, mission_date, slot, mission_id, metric, subject, target,
+			                                  reward_coins, reward_xp, icon, title, description)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		`, childProfileID, date, i+1, d.ID, d.Metric, sql.NullString{String: d.Subject, Valid: d.Subject != ""},
+			d.Target, d.Reward.Coins, d.Reward.XP, d.Icon, d.Title, d.Description)
+		if err != nil {
+			return fmt.Errorf("insert daily mission %s: %w", d.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+const dailyMissionColumns = `id, child_profile_id, to_char(mission_date, 'YYYY-MM-DD'), slot, mission_id, metric,
+	COALESCE(subject, ''), target, progress, reward_coins, reward_xp, icon, title, description, status, completed_at`
+
+func scanDailyMission(row rowScanner) (*DailyMission, error) {
+	var m DailyMission
+	var completedAt sql.NullTime
+	err := row.Scan(&m.ID, &m.ChildProfileID, &m.MissionDate, &m.Slot, &m.MissionID, &m.Metric,
+		&m.Subject, &m.Target, &m.Progress, &m.RewardCoins, &m.RewardXP, &m.Icon, &m.Title, &m.Description,
+		&m.Status, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		m.CompletedAt = &completedAt.Time
+	}
+	return &m, nil
+}
+
+// GetDailyMissions миссии ребёнка на день date по порядку слотов
+func (s *Store) GetDailyMissions(ctx context.Context, childProfileID, date string) ([]DailyMission, error) {
+	return queryDailyMissions(ctx, s.DB, `
+		SELECT `+dailyMissionColumns+`
+		FROM child_daily_missions
+		WHERE child_profile_id = $1 AND mission_date = $2
+		ORDER BY slot
+	`, childProfileID, date)
+}
+
+// querier БД или транзакция для выборки строк
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func queryDailyMissions(ctx context.Context, db querier, query string, args ...any) ([]DailyMission, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query daily missions: %w", err)
+	}
+	defer rows.Close()
+
+	var missions []DailyMission
+	for rows.Next() {
+		m, err := scanDailyMission(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan daily mission: %w", err)
+		}
+		missions = append(missions, *m)
+	}
+	return missions, rows.Err()
+}
+
+// AdvanceDailyMissions засчитывает действие action миссиям ребёнка на день date.
+// Одно действие (sourceKey) двигает каждую миссию один раз. Награда за миссию начисляется
+// в той же транзакции, когда прогресс достигает цели; bonus — когда выполнены все миссии дня.
+func (s *Store) AdvanceDailyMissions(ctx context.Context, childProfileID, date, sourceKey string, action mission.Action, bonus mission.Reward, now time.Time) (*MissionAdvanceResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	missions, err := queryDailyMissions(ctx, tx, `
+		SELECT `+dailyMissionColumns+`
+		FROM child_daily_missions
+		WHERE child_profile_id = $1 AND mission_date = $2
+		ORDER BY slot
+		FOR UPDATE
+	`, childProfileID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MissionAdvanceResult{}
+	completed := 0
+	for _, m := range missions {
+		if m.Status == DailyMissionCompleted {
+			completed++
+			continue
+		}
+		inc := mission.Increment(m.Metric, m.Subject, action)
+		if inc == 0 {
+			continue
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO mission_progress_events (mission_id, source_key, increment, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (mission_id, source_key) DO NOTHING
+		`, m.ID, sourceKey, inc, now)
+		if err != nil {
+			return nil, fmt.Errorf("insert mission progress event: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+
+		m.Progress = min(m.Target, m.Progress+inc)
+		if m.Progress >= m.Target {
+			m.Status = DailyMissionCompleted
+			m.CompletedAt = &now
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE child_daily_missions
+			SET progress = $2, status = $3, completed_at = $4, updated_at = NOW()
+			WHERE id = $1
+		`, m.ID, m.Progress, m.Status, m.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("update daily mission %d: %w", m.ID, err)
+		}
+		result.Advanced = append(result.Advanced, m)
+
+		if m.Status == DailyMissionCompleted {
+			completed++
+			result.Completed = append(result.Completed, m)
+			src := WalletSource{
+				Type:        WalletSourceMission,
+				ID:          strconv.FormatInt(m.ID, 10),
+				Description: "Миссия дня: " + m.Title,
+			}
+			up, err := grantMissionRewardTx(ctx, tx, s.XP, childProfileID, mission.Reward{Coins: m.RewardCoins, XP: m.RewardXP}, src)
+			if err != nil {
+				return nil, err
+			}
+			result.LeveledUp = result.LeveledUp || up
+		}
+	}
+
+	if len(result.Completed) > 0 && completed == len(missions) {
+		result.AllDone = true
+		src := WalletSource{
+			Type:        WalletSourceMission,
+			ID:          date,
+			Key:         "mission_day:" + date,
+			Description: "Все миссии дня выполнены",
+		}
+		up, err := grantMissionRewardTx(ctx, tx, s.XP, childProfileID, bonus, src)
+		if err != nil {
+			return nil, err
+		}
+		result.LeveledUp = result.LeveledUp || up
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return result, nil
+}
+
+// grantMissionRewardTx начисляет монеты и XP награды; нулевые части пропускаются
+func grantMissionRewardTx(ctx context.Context, tx *sql.Tx, xp XPConfig, childProfileID string, r mission.Reward, src WalletSource) (bool, error) {
+	if r.Coins > 0 {
+		if _, _, err := applyWalletEntryTx(ctx, tx, childProfileID, CurrencyCoins, r.Coins, src); err != nil {
+			return false, fmt.Errorf("add mission reward coins: %w", err)
+		}
+	}
+	if r.XP > 0 {
+		_, up, err := addXPTx(ctx, tx, childProfileID, r.XP, xp, src)
+		if err != nil {
+			return false, err
+		}
+		return up, nil
+	}
+	return false, nil
+}
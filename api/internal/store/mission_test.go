@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/mission"
+)
+
+func TestDailyMissions_ProgressAndRewards(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	catalog, err := mission.Default()
+	if err != nil {
+		t.Fatalf("mission.Default() error = %v", err)
+	}
+	if _, err := s.SyncMissionDefinitions(ctx, catalog.Version, catalog.Missions); err != nil {
+		t.Fatalf("SyncMissionDefinitions error = %v", err)
+	}
+
+	byID := make(map[string]mission.Definition)
+	for _, d := range catalog.Missions {
+		byID[d.ID] = d
+	}
+	defs := []mission.Definition{byID["check_2"], byID["no_hints_1"]}
+
+	child := createTestProfile(t, db, testID("mission"), 0)
+	const date = "2026-03-03"
+	if err := s.CreateDailyMissions(ctx, child, date, defs); err != nil {
+		t.Fatalf("CreateDailyMissions error = %v", err)
+	}
+	// Повторная выдача в тот же день ничего не меняет
+	if err := s.CreateDailyMissions(ctx, child, date, []mission.Definition{byID["solve_any_3"]}); err != nil {
+		t.Fatalf("repeated CreateDailyMissions error = %v", err)
+	}
+	missions, err := s.GetDailyMissions(ctx, child, date)
+	if err != nil || len(missions) != 2 || missions[0].MissionID != "check_2" {
+		t.Fatalf("GetDailyMissions = %+v, %v, want check_2 and no_hints_1", missions, err)
+	}
+
+	bonus := mission.Reward{Coins: 15, XP: 10}
+	now := time.Now()
+	check := mission.Action{Kind: mission.ActionCheck, Subject: "math", IsCorrect: true}
+
+	res, err := s.AdvanceDailyMissions(ctx, child, date, "attempt:a1", check, bonus, now)
+	if err != nil {
+		t.Fatalf("AdvanceDailyMissions error = %v", err)
+	}
+	if len(res.Advanced) != 2 || len(res.Completed) != 1 || res.Completed[0].MissionID != "no_hints_1" || res.AllDone {
+		t.Fatalf("first action = %+v, want no_hints_1 completed and check_2 advanced", res)
+	}
+
+	// То же действие второй раз миссии не двигает
+	res, err = s.AdvanceDailyMissions(ctx, child, date, "attempt:a1", check, bonus, now)
+	if err != nil || len(res.Advanced) != 0 {
+		t.Fatalf("repeated action = %+v, %v, want nothing advanced", res, err)
+	}
+
+	res, err = s.AdvanceDailyMissions(ctx, child, date, "attempt:a2", mission.Action{Kind: mission.ActionCheck}, bonus, now)
+	if err != nil {
+		t.Fatalf("AdvanceDailyMissions error = %v", err)
+	}
+	if len(res.Completed) != 1 || !res.AllDone {
+		t.Fatalf("second action = %+v, want check_2 completed and all done", res)
+	}
+
+	coins, xp := getBalances(t, db, child)
+	wantCoins := byID["check_2"].Reward.Coins + byID["no_hints_1"].Reward.Coins + bonus.Coins
+	wantXP := byID["check_2"].Reward.XP + byID["no_hints_1"].Reward.XP + bonus.XP
+	if coins != wantCoins || xp != wantXP {
+		t.Errorf("balances = %d coins, %d xp, want %d, %d", coins, xp, wantCoins, wantXP)
+	}
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"child-bot/api/internal/domain"
+)
+
+// ParentPIN PIN родителя (хранится только хэш)
+type ParentPIN struct {
+	PlatformID     string
+	ParentUserID   string
+	Hash           []byte
+	Salt           []byte
+	FailedAttempts int
+	LockCount      int // блокировок подряд с последнего верного PIN
+	LockedUntil    *time.Time
+}
+
+// ParentSession родительская сессия, привязанная к профилю ребёнка
+type ParentSession struct {
+	TokenHash      []byte
+	ChildProfileID string
+	PlatformID     string
+	ParentUserID   string
+	ExpiresAt      time.Time
+}
+
+// GetParentPIN возвращает PIN родителя или domain.ErrNotFound, если он ещё не задан
+func (s *Store) GetParentPIN(ctx context.Context, platformID, parentUserID string) (*ParentPIN, error) {
+	p := ParentPIN{PlatformID: platformID, ParentUserID: parentUserID}
+	var lockedUntil sql.NullTime
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT pin_hash, pin_salt, failed_attempts, lock_count, locked_until
+		FROM parent_pins
+		WHERE platform_id = $1 AND parent_user_id = $2
+	`, platformID, parentUserID).Scan(&p.Hash, &p.Salt, &p.FailedAttempts, &p.LockCount, &lockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get parent pin: %w", err)
+	}
+	if lockedUntil.Valid {
+		p.LockedUntil = &lockedUntil.Time
+	}
+	return &p, nil
+}
+
+// SetParentPIN задаёт или меняет PIN родителя и сбрасывает счётчик ошибок
+func (s *Store) SetParentPIN(ctx context.Context, platformID, parentUserID string, hash, salt []byte) error {
+	_, err := s.DB.ExecContext(ctx, `
+		INSERT INTO parent_pins (platform_id, parent_user_id, pin_hash, pin_salt)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (platform_id, parent_user_id) DO UPDATE
+		SET pin_hash = EXCLUDED.pin_hash,
+		    pin_salt = EXCLUDED.pin_salt,
+		    failed_attempts = 0,
+		    locked_until = NULL,
+		    lock_count = 0,
+		    updated_at = NOW()
+	`, platformID, parentUserID, hash, salt)
+	if err != nil {
+		return fmt.Errorf("set parent pin: %w", err)
+	}
+	return nil
+}
+
+// RecordParentPINFailure засчитывает неверный PIN; после maxFailures подряд ввод блокируется на lockFor,
+// и каждая следующая блокировка до верного PIN вдвое длиннее (не больше lockFor × 2^maxLockShift)
+func (s *Store) RecordParentPINFailure(ctx context.Context, platformID, parentUserID string, maxFailures int, lockFor time.Duration, maxLockShift int, now time.Time) error {
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE parent_pins
+		SET failed_attempts = CASE WHEN failed_attempts + 1 >= $3 THEN 0 ELSE failed_attempts + 1 END,
+		    locked_until = CASE WHEN failed_attempts + 1 >= $3
+		        THEN $4::timestamptz + make_interval(secs => $5 * POWER(2, LEAST(lock_count, $6)))
+		        ELSE locked_until END,
+		    lock_count = CASE WHEN failed_attempts + 1 >= $3 THEN lock_count + 1 ELSE lock_count END,
+		    updated_at = NOW()
+		WHERE platform_id = $1 AND parent_user_id = $2
+	`, platformID, parentUserID, maxFailures, now, lockFor.Seconds(), maxLockShift)
+	if err != nil {
+		return fmt.Errorf("record parent pin failure: %w", err)
+	}
+	return nil
+}
+
+// ResetParentPINFailures сбрасывает счётчики ошибок и блокировок после верного PIN
+func (s *Store) ResetParentPINFailures(ctx context.Context, platformID, parentUserID string) error {
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE parent_pins
+		SET failed_attempts = 0, locked_until = NULL, lock_count = 0, updated_at = NOW()
+		WHERE platform_id = $1 AND parent_user_id = $2
+		  AND (failed_attempts > 0 OR locked_until IS NOT NULL OR lock_count > 0)
+	`, platformID, parentUserID)
+	if err != nil {
+		return fmt.Errorf("reset parent pin failures: %w", err)
+	}
+	return nil
+}
+
+// GetParentEmail первый подтверждённый email аккаунта родителя или domain.ErrNotFound.
+// Берётся первый адрес: подтверждённый позже другой адрес не перехватывает коды PIN.
+func (s *Store) GetParentEmail(ctx context.Context, platformID, parentUserID string) (string, error) {
+	var email string
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT email
+		FROM email_verifications
+		WHERE platform_id = $1 AND parent_user_id = $2 AND is_verified = TRUE
+		ORDER BY verified_at, created_at
+		LIMIT 1
+	`, platformID, parentUserID).Scan(&email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrNotFound
+		}
+		return "", fmt.Errorf("get parent email: %w", err)
+	}
+	return email, nil
+}
+
+// SaveParentPINCode сохраняет хэш одноразового кода для PIN; прежний код перестаёт действовать
+func (s *Store) SaveParentPINCode(ctx context.Context, platformID, parentUserID string, codeHash []byte, expiresAt time.Time) error {
+	_, err := s.DB.ExecContext(ctx, `
+		INSERT INTO parent_pin_codes (platform_id, parent_user_id, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (platform_id, parent_user_id) DO UPDATE
+		SET code_hash = EXCLUDED.code_hash,
+		    attempts = 0,
+		    expires_at = EXCLUDED.expires_at,
+		    created_at = NOW()
+	`, platformID, parentUserID, codeHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("save parent pin code: %w", err)
+	}
+	return nil
+}
+
+// UseParentPINCode сверяет одноразовый код и при совпадении тратит его.
+// Неверный код засчитывается; после maxAttempts неверных, как и после истечения, код не действует.
+func (s *Store) UseParentPINCode(ctx context.Context, platformID, parentUserID string, codeHash []byte, maxAttempts int, now time.Time) (bool, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var stored []byte
+	var attempts int
+	var expiresAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT code_hash, attempts, expires_at
+		FROM parent_pin_codes
+		WHERE platform_id = $1 AND parent_user_id = $2
+		FOR UPDATE
+	`, platformID, parentUserID).Scan(&stored, &attempts, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get parent pin code: %w", err)
+	}
+	if attempts >= maxAttempts || !expiresAt.After(now) {
+		return false, nil
+	}
+
+	matched := subtle.ConstantTimeCompare(stored, codeHash) == 1
+	query := `UPDATE parent_pin_codes SET attempts = attempts + 1 WHERE platform_id = $1 AND parent_user_id = $2`
+	if matched {
+		query = `DELETE FROM parent_pin_codes WHERE platform_id = $1 AND parent_user_id = $2`
+	}
+	if _, err := tx.ExecContext(ctx, query, platformID, parentUserID); err != nil {
+		return false, fmt.Errorf("use parent pin code: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit parent pin code: %w", err)
+	}
+	return matched, nil
+}
+
+// CreateParentSession сохраняет родительскую сессию и удаляет истёкшие
+func (s *Store) CreateParentSession(ctx context.Context, session ParentSession, now time.Time) error {
+	if _, err := s.DB.ExecContext(ctx, `DELETE FROM parent_sessions WHERE expires_at < $1`, now); err != nil {
+		return fmt.Errorf("delete expired parent sessions: %w", err)
+	}
+	_, err := s.DB.ExecContext(ctx, `
+		INSERT INTO parent_sessions (token_hash, child_profile_id, platform_id, parent_user_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, session.TokenHash, session.ChildProfileID, session.PlatformID, session.ParentUserID, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("create parent session: %w", err)
+	}
+	return nil
+}
+
+// GetParentSession возвращает действующую сессию по хэшу токена или domain.ErrNotFound
+func (s *Store) GetParentSession(ctx context.Context, tokenHash []byte, now time.Time) (*ParentSession, error) {
+	session := ParentSession{TokenHash: tokenHash}
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT child_profile_id, platform_id, parent_user_id, expires_at
+		FROM parent_sessions
+		WHERE token_hash = $1 AND expires_at > $2
+	`, tokenHash, now).Scan(&session.ChildProfileID, &session.PlatformID, &session.ParentUserID, &session.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get parent session: %w", err)
+	}
+	return &session, nil
+}
+
+// DeleteParentSession завершает родительскую сессию
+func (s *Store) DeleteParentSession(ctx context.Context, tokenHash []byte) error {
+	if _, err := s.DB.ExecContext(ctx, `DELETE FROM parent_sessions WHERE token_hash = $1`, tokenHash); err != nil {
+		return fmt.Errorf("delete parent session: %w", err)
+	}
+	return nil
+}
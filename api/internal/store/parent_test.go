@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/domain"
+)
+
+func TestParentPIN_LocksAfterFailures(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	userID := testID("parent_pin")
+	if err := s.SetParentPIN(ctx, "vk", userID, []byte("hash"), []byte("salt")); err != nil {
+		t.Fatalf("SetParentPIN() error = %v", err)
+	}
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM parent_pins WHERE platform_id = 'vk' AND parent_user_id = $1`, userID)
+	})
+
+	now := time.Now()
+	lock := func() *ParentPIN {
+		t.Helper()
+		for i := 0; i < 3; i++ {
+			if err := s.RecordParentPINFailure(ctx, "vk", userID, 3, 15*time.Minute, 6, now); err != nil {
+				t.Fatalf("RecordParentPINFailure() error = %v", err)
+			}
+		}
+		pin, err := s.GetParentPIN(ctx, "vk", userID)
+		if err != nil {
+			t.Fatalf("GetParentPIN() error = %v", err)
+		}
+		return pin
+	}
+	pin := lock()
+	if pin.LockedUntil == nil || !pin.LockedUntil.After(now) || pin.FailedAttempts != 0 || pin.LockCount != 1 {
+		t.Errorf("pin = %+v, want locked with reset counter", pin)
+	}
+
+	// Следующая блокировка подряд вдвое длиннее
+	first := *pin.LockedUntil
+	if pin := lock(); pin.LockedUntil == nil || pin.LockedUntil.Sub(first) < 14*time.Minute || pin.LockCount != 2 {
+		t.Errorf("second lock = %+v, want 30 minutes", pin)
+	}
+
+	if err := s.ResetParentPINFailures(ctx, "vk", userID); err != nil {
+		t.Fatalf("ResetParentPINFailures() error = %v", err)
+	}
+	if pin, _ := s.GetParentPIN(ctx, "vk", userID); pin.LockedUntil != nil || pin.LockCount != 0 {
+		t.Errorf("pin after reset = %+v, want unlocked", pin)
+	}
+}
+
+func TestParentPINCode_SingleUseWithAttempts(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	userID := testID("parent_code")
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM parent_pin_codes WHERE platform_id = 'vk' AND parent_user_id = $1`, userID)
+	})
+
+	now := time.Now()
+	if err := s.SaveParentPINCode(ctx, "vk", userID, []byte("right"), now.Add(time.Minute)); err != nil {
+		t.Fatalf("SaveParentPINCode() error = %v", err)
+	}
+	if ok, err := s.UseParentPINCode(ctx, "vk", userID, []byte("wrong"), 2, now); err != nil || ok {
+		t.Fatalf("UseParentPINCode(wrong) = %v, %v; want false", ok, err)
+	}
+	if ok, err := s.UseParentPINCode(ctx, "vk", userID, []byte("right"), 2, now); err != nil || !ok {
+		t.Fatalf("UseParentPINCode(right) = %v, %v; want true", ok, err)
+	}
+	// Код одноразовый
+	if ok, _ := s.UseParentPINCode(ctx, "vk", userID, []byte("right"), 2, now); ok {
+		t.Error("UseParentPINCode() accepted a used code")
+	}
+
+	// После исчерпания попыток не подходит и верный код
+	s.SaveParentPINCode(ctx, "vk", userID, []byte("right"), now.Add(time.Minute))
+	s.UseParentPINCode(ctx, "vk", userID, []byte("wrong"), 2, now)
+	s.UseParentPINCode(ctx, "vk", userID, []byte("wrong"), 2, now)
+	if ok, _ := s.UseParentPINCode(ctx, "vk", userID, []byte("right"), 2, now); ok {
+		t.Error("UseParentPINCode() accepted a code after too many attempts")
+	}
+
+	// Истёкший код не подходит
+	s.SaveParentPINCode(ctx, "vk", userID, []byte("right"), now.Add(-time.Second))
+	if ok, _ := s.UseParentPINCode(ctx, "vk", userID, []byte("right"), 2, now); ok {
+		t.Error("UseParentPINCode() accepted an expired code")
+	}
+}
+
+func TestParentSession_Expires(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	userID := testID("parent_session")
+	childID := createTestProfile(t, db, userID, 0)
+	now := time.Now()
+	session := ParentSession{
+		TokenHash:      []byte(testID("token")),
+		ChildProfileID: childID,
+		PlatformID:     "vk",
+		ParentUserID:   userID,
+		ExpiresAt:      now.Add(time.Minute),
+	}
+	if err := s.CreateParentSession(ctx, session, now); err != nil {
+		t.Fatalf("CreateParentSession() error = %v", err)
+	}
+
+	got, err := s.GetParentSession(ctx, session.TokenHash, now)
+	if err != nil || got.ChildProfileID != childID || got.ParentUserID != userID {
+		t.Fatalf("GetParentSession() = %+v, %v; want session of %s", got, err, childID)
+	}
+	if _, err := s.GetParentSession(ctx, session.TokenHash, now.Add(2*time.Minute)); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expired GetParentSession() error = %v, want ErrNotFound", err)
+	}
+
+	if err := s.DeleteParentSession(ctx, session.TokenHash); err != nil {
+		t.Fatalf("DeleteParentSession() error = %v", err)
+	}
+	if _, err := s.GetParentSession(ctx, session.TokenHash, now); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetParentSession() after delete error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PendingEvent доменное событие, не доставленное одному подписчику
+type PendingEvent struct {
+	ID             int64
+	Subscriber     string
+	EventType      string
+	ChildProfileID string
+	Payload        json.RawMessage
+	Attempts       int
+	LastError      string
+}
+
+// SavePendingEvent записывает недоставленное событие; первая повторная попытка — в nextAttemptAt
+func (s *Store) SavePendingEvent(ctx context.Context, event PendingEvent, nextAttemptAt time.Time) error {
+	_, err := s.DB.ExecContext(ctx, `
+		INSERT INTO pending_events (subscriber, event_type, child_profile_id, payload, last_error, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, event.Subscriber, event.EventType, event.ChildProfileID, []byte(event.Payload), event.LastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("save pending event: %w", err)
+	}
+	return nil
+}
+
+// ClaimPendingEvents забирает до limit событий, срок повтора которых наступил.
+// Забранные события откладываются на lease, чтобы другой экземпляр сервера не взял их
+// одновременно; если повтор не завершится, они вернутся после lease.
+func (s *Store) ClaimPendingEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]PendingEvent, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		UPDATE pending_events
+		SET next_attempt_at = $2, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM pending_events
+			WHERE next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, subscriber, event_type, child_profile_id, payload, attempts, last_error
+	`, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim pending events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []PendingEvent
+	for rows.Next() {
+		var e PendingEvent
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Subscriber, &e.EventType, &e.ChildProfileID, &payload, &e.Attempts, &e.LastError); err != nil {
+			return nil, fmt.Errorf("scan pending event: %w", err)
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// DeletePendingEvent удаляет доставленное событие
+func (s *Store) DeletePendingEvent(ctx context.Context, id int64) error {
+	if _, err := s.DB.ExecContext(ctx, `DELETE FROM pending_events WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete pending event: %w", err)
+	}
+	return nil
+}
+
+// FailPendingEvent засчитывает неудачный повтор. nextAttemptAt == nil — попытки исчерпаны,
+// событие больше не повторяется и остаётся для разбора.
+func (s *Store) FailPendingEvent(ctx context.Context, id int64, lastError string, nextAttemptAt *time.Time) error {
+	_, err := s.DB.ExecContext(ctx, `
+		UPDATE pending_events
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3, updated_at = NOW()
+		WHERE id = $1
+	`, id, lastError, nextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("fail pending event: %w", err)
+	}
+	return nil
+}
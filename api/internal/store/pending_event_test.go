@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPendingEvents_ClaimFailDelete(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("pending_event"), 0)
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM pending_events WHERE child_profile_id = $1`, childID)
+	})
+
+	// Время в будущем, чтобы не забрать чужие события из общей тестовой базы
+	now := time.Now().Add(24 * time.Hour)
+	event := PendingEvent{
+		Subscriber:     "achievements",
+		EventType:      "attempt_completed",
+		ChildProfileID: childID,
+		Payload:        json.RawMessage(`{"ChildProfileID":"` + childID + `"}`),
+		LastError:      "boom",
+	}
+	if err := s.SavePendingEvent(ctx, event, now.Add(time.Minute)); err != nil {
+		t.Fatalf("SavePendingEvent() error = %v", err)
+	}
+
+	claimOwn := func(at time.Time) []PendingEvent {
+		t.Helper()
+		claimed, err := s.ClaimPendingEvents(ctx, at, 5*time.Minute, 1000)
+		if err != nil {
+			t.Fatalf("ClaimPendingEvents() error = %v", err)
+		}
+		var own []PendingEvent
+		for _, e := range claimed {
+			if e.ChildProfileID == childID {
+				own = append(own, e)
+			}
+		}
+		return own
+	}
+
+	if got := claimOwn(now); len(got) != 0 {
+		t.Fatalf("claimed before due: %+v", got)
+	}
+	got := claimOwn(now.Add(2 * time.Minute))
+	if len(got) != 1 || got[0].Subscriber != "achievements" || got[0].Attempts != 1 {
+		t.Fatalf("claimed = %+v, want one achievements event", got)
+	}
+	// Забранное событие отложено на lease
+	if again := claimOwn(now.Add(3 * time.Minute)); len(again) != 0 {
+		t.Errorf("claimed twice within lease: %+v", again)
+	}
+
+	if err := s.FailPendingEvent(ctx, got[0].ID, "boom again", nil); err != nil {
+		t.Fatalf("FailPendingEvent() error = %v", err)
+	}
+	if dead := claimOwn(now.Add(24 * time.Hour)); len(dead) != 0 {
+		t.Errorf("claimed exhausted event: %+v", dead)
+	}
+
+	if err := s.DeletePendingEvent(ctx, got[0].ID); err != nil {
+		t.Fatalf("DeletePendingEvent() error = %v", err)
+	}
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM pending_events WHERE child_profile_id = $1`, childID).Scan(&count)
+	if count != 0 {
+		t.Errorf("pending events after delete = %d, want 0", count)
+	}
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"child-bot/api/internal/pet"
+)
+
+// PetRow питомец ребёнка; показатели посчитаны на момент StatsAt
+type PetRow struct {
+	ChildProfileID string
+	PetType        string
+	pet.Stats
+	Stage     int
+	StatsAt   time.Time
+	LastFedAt *time.Time
+	EvolvedAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PetFeedResult результат кормления
+type PetFeedResult struct {
+	Pet        *PetRow
+	Before     pet.Stats // показатели перед кормлением (с учётом прошедшего времени)
+	AlreadyFed bool      // за этот источник питомца уже кормили
+}
+
+const petColumns = `child_profile_id, pet_type, hunger, happiness, energy, stage,
+	stats_at, last_fed_at, evolved_at, created_at, updated_at`
+
+func scanPet(row rowScanner) (*PetRow, error) {
+	var p PetRow
+	var lastFedAt, evolvedAt sql.NullTime
+	err := row.Scan(&p.ChildProfileID, &p.PetType, &p.Hunger, &p.Happiness, &p.Energy, &p.Stage,
+		&p.StatsAt, &lastFedAt, &evolvedAt, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if lastFedAt.Valid {
+		p.LastFedAt = &lastFedAt.Time
+	}
+	if evolvedAt.Valid {
+		p.EvolvedAt = &evolvedAt.Time
+	}
+	return &p, nil
+}
+
+// GetPet возвращает питомца ребёнка; nil, если питомца ещё нет
+func (s *Store) GetPet(ctx context.Context, childProfileID string) (*PetRow, error) {
+	p, err := scanPet(s.DB.QueryRowContext(ctx,
+		`SELECT `+petColumns+` FROM child_pets WHERE child_profile_id = $1`, childProfileID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get pet: %w", err)
+	}
+	return p, nil
+}
+
+// EnsurePet создаёт питомца с начальными показателями, если его ещё нет, и возвращает его
+func (s *Store) EnsurePet(ctx context.Context, childProfileID string, stats pet.Stats, now time.Time) (*PetRow, error) {
+	if err := insertPet(ctx, s.DB, childProfileID, stats, now); err != nil {
+		return nil, err
+	}
+	return s.GetPet(ctx, childProfileID)
+}
+
+// FeedPet кормит питомца за источник sourceKey (attempt:<id>, villain:...).
+// Перед кормлением показатели пересчитываются на момент now по часам ребёнка.
+// Повторное кормление за тот же источник ничего не меняет.
+func (s *Store) FeedPet(ctx context.Context, childProfileID, sourceKey string, food pet.Food, now time.Time, loc *time.Location, rules pet.Rules) (*PetFeedResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertPet(ctx, tx, childProfileID, pet.NewStats(), now); err != nil {
+		return nil, err
+	}
+	current, err := scanPet(tx.QueryRowContext(ctx,
+		`SELECT `+petColumns+` FROM child_pets WHERE child_profile_id = $1 FOR UPDATE`, childProfileID))
+	if err != nil {
+		return nil, fmt.Errorf("lock pet: %w", err)
+	}
+
+	before := pet.Decay(current.Stats, current.StatsAt, now, loc, rules)
+	after := pet.Feed(before, food)
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO pet_feedings (child_profile_id, source_key, food, hunger_delta, happiness_delta, energy_delta, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (child_profile_id, source_key) DO NOTHING
+	`, childProfileID, sourceKey, food.Code,
+		after.Hunger-before.Hunger, after.Happiness-before.Happiness, after.Energy-before.Energy, now)
+	if err != nil {
+		return nil, fmt.Errorf("insert pet feeding: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return &PetFeedResult{Pet: current, Before: current.Stats, AlreadyFed: true}, nil
+	}
+
+	fed, err := scanPet(tx.QueryRowContext(ctx, `
+		UPDATE child_pets
+		SET hunger = $2, happiness = $3, energy = $4, stats_at = $5, last_fed_at = $5, updated_at = NOW()
+		WHERE child_profile_id = $1
+		RETURNING `+petColumns,
+		childProfileID, after.Hunger, after.Happiness, after.Energy, now))
+	if err != nil {
+		return nil, fmt.Errorf("update pet: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit pet feeding: %w", err)
+	}
+	return &PetFeedResult{Pet: fed, Before: before}, nil
+}
+
+// EvolvePet поднимает стадию питомца до stage. Стадия не понижается;
+// false — питомец уже на этой стадии или выше.
+func (s *Store) EvolvePet(ctx context.Context, childProfileID string, stage int, now time.Time) (bool, error) {
+	res, err := s.DB.ExecContext(ctx, `
+		UPDATE child_pets
+		SET stage = $2, evolved_at = $3, updated_at = NOW()
+		WHERE child_profile_id = $1 AND stage < $2
+	`, childProfileID, stage, now)
+	if err != nil {
+		return false, fmt.Errorf("evolve pet: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// execer общий интерфейс *sql.DB и *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertPet создаёт питомца, если его ещё нет
+func insertPet(ctx context.Context, db execer, childProfileID string, stats pet.Stats, now time.Time) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO child_pets (child_profile_id, pet_type, hunger, happiness, energy, stats_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (child_profile_id) DO NOTHING
+	`, childProfileID, pet.DefaultType, stats.Hunger, stats.Happiness, stats.Energy, now)
+	if err != nil {
+		return fmt.Errorf("create pet: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/pet"
+)
+
+func TestFeedPet_OncePerSource(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	loc, _ := time.LoadLocation("Europe/Moscow")
+	rules := pet.DefaultRules()
+	child := createTestProfile(t, db, testID("pet_feed"), 0)
+
+	// Питомец появился утром и до обеда проголодался
+	morning := time.Date(2026, 3, 2, 8, 0, 0, 0, loc)
+	if _, err := s.EnsurePet(ctx, child, pet.Stats{Hunger: 60, Happiness: 50, Energy: 80}, morning); err != nil {
+		t.Fatalf("EnsurePet error = %v", err)
+	}
+
+	noon := time.Date(2026, 3, 2, 12, 0, 0, 0, loc)
+	res, err := s.FeedPet(ctx, child, "attempt:1", pet.Meal, noon, loc, rules)
+	if err != nil {
+		t.Fatalf("FeedPet error = %v", err)
+	}
+	wantBefore := pet.Decay(pet.Stats{Hunger: 60, Happiness: 50, Energy: 80}, morning, noon, loc, rules)
+	if res.AlreadyFed || res.Before != wantBefore {
+		t.Errorf("FeedPet before = %+v (already %v), want %+v", res.Before, res.AlreadyFed, wantBefore)
+	}
+	if want := pet.Feed(wantBefore, pet.Meal); res.Pet.Stats != want {
+		t.Errorf("FeedPet stats = %+v, want %+v", res.Pet.Stats, want)
+	}
+	if res.Pet.LastFedAt == nil || !res.Pet.StatsAt.Equal(noon) {
+		t.Errorf("FeedPet last_fed_at = %v, stats_at = %v, want %v", res.Pet.LastFedAt, res.Pet.StatsAt, noon)
+	}
+
+	// Та же попытка второй раз не кормит
+	again, err := s.FeedPet(ctx, child, "attempt:1", pet.Meal, noon.Add(time.Minute), loc, rules)
+	if err != nil {
+		t.Fatalf("FeedPet replay error = %v", err)
+	}
+	if !again.AlreadyFed || again.Pet.Stats != res.Pet.Stats {
+		t.Errorf("FeedPet replay = %+v (already %v), want unchanged", again.Pet.Stats, again.AlreadyFed)
+	}
+}
+
+func TestEvolvePet_NeverGoesBack(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	child := createTestProfile(t, db, testID("pet_evolve"), 0)
+	if _, err := s.EnsurePet(ctx, child, pet.NewStats(), time.Now()); err != nil {
+		t.Fatalf("EnsurePet error = %v", err)
+	}
+
+	if evolved, err := s.EvolvePet(ctx, child, 3, time.Now()); err != nil || !evolved {
+		t.Fatalf("EvolvePet(3) = %v, %v, want evolved", evolved, err)
+	}
+	if evolved, err := s.EvolvePet(ctx, child, 2, time.Now()); err != nil || evolved {
+		t.Errorf("EvolvePet(2) after 3 = %v, %v, want no change", evolved, err)
+	}
+
+	p, err := s.GetPet(ctx, child)
+	if err != nil || p == nil {
+		t.Fatalf("GetPet = %v, %v", p, err)
+	}
+	if p.Stage != 3 || p.EvolvedAt == nil {
+		t.Errorf("pet stage = %d (evolved_at %v), want 3", p.Stage, p.EvolvedAt)
+	}
+}
@@ -0,0 +1,367 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/practice"
+)
+
+// Статусы сессии разминки
+const (
+	PracticeSessionActive   = "active"
+	PracticeSessionFinished = "finished"
+)
+
+// PracticeSession сессия разминки ребёнка
+type PracticeSession struct {
+	ID             int64
+	ChildProfileID string
+	Kind           string
+	Grade          int
+	Status         string
+	ItemsTotal     int
+	CorrectCount   int // засчитанные верные ответы, после завершения
+	XPAwarded      int
+	CoinsAwarded   int
+	StartedAt      time.Time
+	ExpiresAt      time.Time
+	FinishedAt     *time.Time
+	Items          []PracticeItem
+}
+
+// PracticeItem задание сессии. Answer и Hint клиенту отдаются только после ответа ребёнка.
+type PracticeItem struct {
+	Position    int
+	Prompt      string
+	Options     []string
+	Hint        string
+	Answer      string
+	Answered    bool
+	GivenAnswer string
+	IsCorrect   bool
+	Counted     bool
+}
+
+// PracticeAnswerResult итог ответа на задание
+type PracticeAnswerResult struct {
+	Position      int
+	IsCorrect     bool
+	Counted       bool // ответ идёт в счёт: верный и не быстрее practice.MinAnswerInterval
+	CorrectAnswer string
+	Hint          string
+	Answered      int
+	Total         int
+	Replayed      bool // тот же ответ уже был принят
+}
+
+// PracticeFinishResult итог завершения сессии
+type PracticeFinishResult struct {
+	Session    *PracticeSession
+	Answered   int
+	CapReached bool // награда урезана дневным лимитом
+	LeveledUp  bool
+	Replayed   bool // сессия уже была завершена
+}
+
+const practiceSessionColumns = `id, child_profile_id, kind, grade, status, items_total, correct_count,
+	xp_awarded, coins_awarded, started_at, expires_at, finished_at`
+
+func scanPracticeSession(row rowScanner) (*PracticeSession, error) {
+	var p PracticeSession
+	var finishedAt sql.NullTime
+	err := row.Scan(&p.ID, &p.ChildProfileID, &p.Kind, &p.Grade, &p.Status, &p.ItemsTotal, &p.CorrectCount,
+		&p.XPAwarded, &p.CoinsAwarded, &p.StartedAt, &p.ExpiresAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	if finishedAt.Valid {
+		p.FinishedAt = &finishedAt.Time
+	}
+	return &p, nil
+}
+
+// CreatePracticeSession сохраняет сессию с заданиями, принимающую ответы до expiresAt
+func (s *Store) CreatePracticeSession(ctx context.Context, childProfileID, kind string, grade int, items []practice.Item, now, expiresAt time.Time) (*PracticeSession, error) {
+	if len(items) == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	p, err := scanPracticeSession(tx.QueryRowContext(ctx, `
+		INSERT INTO practice_sessions (child_profile_id, kind, grade, items_total, started_at, expires_at)
+		SELECT id, $2, $3, $4, $5, $6 FROM child_profiles WHERE id = $1
+		RETURNING `+practiceSessionColumns,
+		childProfileID, kind, grade, len(items), now, expiresAt))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create practice session: %w", err)
+	}
+
+	for i, item := range items {
+		options := item.Options
+		if options == nil {
+			options = []string{}
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO practice_items (session_id, position, prompt, options, hint, answer)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, p.ID, i, item.Prompt, pq.Array(options), item.Hint, item.Answer)
+		if err != nil {
+			return nil, fmt.Errorf("add practice item: %w", err)
+		}
+		p.Items = append(p.Items, PracticeItem{Position: i, Prompt: item.Prompt, Options: options, Hint: item.Hint, Answer: item.Answer})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return p, nil
+}
+
+// GetPracticeSession сессия ребёнка с заданиями; чужая — domain.ErrNotFound
+func (s *Store) GetPracticeSession(ctx context.Context, childProfileID string, id int64) (*PracticeSession, error) {
+	p, err := scanPracticeSession(s.DB.QueryRowContext(ctx, `
+		SELECT `+practiceSessionColumns+` FROM practice_sessions WHERE id = $1 AND child_profile_id = $2
+	`, id, childProfileID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get practice session: %w", err)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT position, prompt, options, hint, answer, given_answer, COALESCE(is_correct, false), COALESCE(counted, false)
+		FROM practice_items
+		WHERE session_id = $1
+		ORDER BY position
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query practice items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item PracticeItem
+		var given sql.NullString
+		if err := rows.Scan(&item.Position, &item.Prompt, pq.Array(&item.Options), &item.Hint, &item.Answer,
+			&given, &item.IsCorrect, &item.Counted); err != nil {
+			return nil, fmt.Errorf("scan practice item: %w", err)
+		}
+		item.Answered, item.GivenAnswer = given.Valid, given.String
+		p.Items = append(p.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate practice items: %w", err)
+	}
+	return p, nil
+}
+
+// AnswerPracticeItem проверяет ответ на задание position. На задание отвечают один раз:
+// повтор того же ответа возвращает прежний итог, другой ответ — domain.ErrConflict.
+// Завершённая или истёкшая сессия ответов не принимает (domain.ErrConflict).
+func (s *Store) AnswerPracticeItem(ctx context.Context, childProfileID string, id int64, position int, given string, now time.Time) (*PracticeAnswerResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	var startedAt, expiresAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT status, started_at, expires_at FROM practice_sessions
+		WHERE id = $1 AND child_profile_id = $2
+		FOR UPDATE
+	`, id, childProfileID).Scan(&status, &startedAt, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get practice session: %w", err)
+	}
+
+	result := &PracticeAnswerResult{Position: position}
+	var prev sql.NullString
+	var isCorrect, counted sql.NullBool
+	err = tx.QueryRowContext(ctx, `
+		SELECT answer, hint, given_answer, is_correct, counted FROM practice_items
+		WHERE session_id = $1 AND position = $2
+	`, id, position).Scan(&result.CorrectAnswer, &result.Hint, &prev, &isCorrect, &counted)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get practice item: %w", err)
+	}
+
+	if prev.Valid {
+		if !practice.Check(prev.String, given) {
+			return nil, domain.ErrConflict
+		}
+		result.IsCorrect, result.Counted, result.Replayed = isCorrect.Bool, counted.Bool, true
+	} else {
+		if status != PracticeSessionActive || !now.Before(expiresAt) {
+			return nil, domain.ErrConflict
+		}
+
+		// Ответ быстрее минимального интервала после предыдущего (или начала) в счёт не идёт
+		var last sql.NullTime
+		if err := tx.QueryRowContext(ctx,
+			`SELECT MAX(answered_at) FROM practice_items WHERE session_id = $1`, id,
+		).Scan(&last); err != nil {
+			return nil, fmt.Errorf("get last answer time: %w", err)
+		}
+		since := startedAt
+		if last.Valid {
+			since = last.Time
+		}
+
+		result.IsCorrect = practice.Check(result.CorrectAnswer, given)
+		result.Counted = result.IsCorrect && now.Sub(since) >= practice.MinAnswerInterval
+		_, err := tx.ExecContext(ctx, `
+			UPDATE practice_items
+			SET given_answer = $3, is_correct = $4, counted = $5, answered_at = $6
+			WHERE session_id = $1 AND position = $2
+		`, id, position, given, result.IsCorrect, result.Counted, now)
+		if err != nil {
+			return nil, fmt.Errorf("save practice answer: %w", err)
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FILTER (WHERE answered_at IS NOT NULL), COUNT(*)
+		FROM practice_items WHERE session_id = $1
+	`, id).Scan(&result.Answered, &result.Total)
+	if err != nil {
+		return nil, fmt.Errorf("count practice answers: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return result, nil
+}
+
+// GetPracticeEarnings сколько XP и монет ребёнок получил за разминки в день date (YYYY-MM-DD)
+func (s *Store) GetPracticeEarnings(ctx context.Context, childProfileID, date string) (xp, coins int, err error) {
+	return practiceEarnings(ctx, s.DB, childProfileID, date)
+}
+
+func practiceEarnings(ctx context.Context, db querier, childProfileID, date string) (xp, coins int, err error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT COALESCE(SUM(xp_awarded), 0), COALESCE(SUM(coins_awarded), 0)
+		FROM practice_sessions
+		WHERE child_profile_id = $1 AND reward_date = $2::date
+	`, childProfileID, date)
+	if err != nil {
+		return 0, 0, fmt.Errorf("get practice earnings: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&xp, &coins); err != nil {
+			return 0, 0, fmt.Errorf("scan practice earnings: %w", err)
+		}
+	}
+	return xp, coins, rows.Err()
+}
+
+// FinishPracticeSession завершает сессию и начисляет награду за засчитанные ответы
+// в пределах дневного лимита дня date (YYYY-MM-DD по календарю ребёнка).
+// Повторное завершение возвращает прежний итог.
+func (s *Store) FinishPracticeSession(ctx context.Context, childProfileID string, id int64, date string, rewards practice.Rewards, now time.Time) (*PracticeFinishResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем профиль: дневной лимит считается по всем сессиям ребёнка
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM child_profiles WHERE id = $1 FOR UPDATE`, childProfileID); err != nil {
+		return nil, fmt.Errorf("lock child profile: %w", err)
+	}
+
+	p, err := scanPracticeSession(tx.QueryRowContext(ctx, `
+		SELECT `+practiceSessionColumns+` FROM practice_sessions
+		WHERE id = $1 AND child_profile_id = $2
+		FOR UPDATE
+	`, id, childProfileID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get practice session: %w", err)
+	}
+
+	result := &PracticeFinishResult{Session: p}
+	var correct int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FILTER (WHERE counted), COUNT(*) FILTER (WHERE answered_at IS NOT NULL)
+		FROM practice_items WHERE session_id = $1
+	`, id).Scan(&correct, &result.Answered)
+	if err != nil {
+		return nil, fmt.Errorf("count practice answers: %w", err)
+	}
+
+	if p.Status == PracticeSessionFinished {
+		result.Replayed = true
+		result.CapReached = p.XPAwarded < p.CorrectCount*rewards.XPPerCorrect ||
+			p.CoinsAwarded < p.CorrectCount*rewards.CoinsPerCorrect
+		return result, nil
+	}
+
+	earnedXP, earnedCoins, err := practiceEarnings(ctx, tx, childProfileID, date)
+	if err != nil {
+		return nil, err
+	}
+	xp, coins := rewards.Grant(correct, earnedXP, earnedCoins)
+	result.CapReached = xp < correct*rewards.XPPerCorrect || coins < correct*rewards.CoinsPerCorrect
+
+	src := WalletSource{
+		Type:        WalletSourcePractice,
+		ID:          strconv.FormatInt(id, 10),
+		Description: "Разминка",
+	}
+	if coins > 0 {
+		if _, _, err := applyWalletEntryTx(ctx, tx, childProfileID, CurrencyCoins, coins, src); err != nil {
+			return nil, fmt.Errorf("add practice coins: %w", err)
+		}
+	}
+	if xp > 0 {
+		_, up, err := addXPTx(ctx, tx, childProfileID, xp, s.XP, src)
+		if err != nil {
+			return nil, err
+		}
+		result.LeveledUp = up
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE practice_sessions
+		SET status = 'finished', correct_count = $2, xp_awarded = $3, coins_awarded = $4,
+		    reward_date = $5::date, finished_at = $6
+		WHERE id = $1
+	`, id, correct, xp, coins, date, now)
+	if err != nil {
+		return nil, fmt.Errorf("finish practice session: %w", err)
+	}
+	p.Status, p.CorrectCount, p.XPAwarded, p.CoinsAwarded, p.FinishedAt = PracticeSessionFinished, correct, xp, coins, &now
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/practice"
+)
+
+func TestPracticeSession_ScoringAndDailyCap(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	child := createTestProfile(t, db, testID("practice"), 0)
+	rewards := practice.Rewards{XPPerCorrect: 10, CoinsPerCorrect: 10, DailyXPCap: 15, DailyCoinsCap: 15}
+	items := []practice.Item{
+		{Prompt: "2 × 3", Answer: "6"},
+		{Prompt: "4 × 5", Answer: "20"},
+		{Prompt: "м_локо", Options: []string{"о", "а"}, Hint: "Словарное слово", Answer: "о"},
+	}
+	start := time.Now()
+	date := start.Format("2006-01-02")
+
+	session, err := s.CreatePracticeSession(ctx, child, practice.KindMultiplication, 2, items, start, start.Add(practice.SessionTTL))
+	if err != nil {
+		t.Fatalf("CreatePracticeSession error = %v", err)
+	}
+
+	// Слишком быстрый верный ответ не идёт в счёт, неверный — тоже
+	answers := []struct {
+		position    int
+		given       string
+		after       time.Duration
+		wantCorrect bool
+		wantCounted bool
+	}{
+		{0, "6", 100 * time.Millisecond, true, false},
+		{1, " 20 ", 3 * time.Second, true, true},
+		{2, "а", 6 * time.Second, false, false},
+	}
+	for _, a := range answers {
+		res, err := s.AnswerPracticeItem(ctx, child, session.ID, a.position, a.given, start.Add(a.after))
+		if err != nil {
+			t.Fatalf("AnswerPracticeItem(%d) error = %v", a.position, err)
+		}
+		if res.IsCorrect != a.wantCorrect || res.Counted != a.wantCounted {
+			t.Errorf("AnswerPracticeItem(%d) = correct %v counted %v, want %v %v", a.position, res.IsCorrect, res.Counted, a.wantCorrect, a.wantCounted)
+		}
+	}
+	if res, err := s.AnswerPracticeItem(ctx, child, session.ID, 1, "20", start.Add(7*time.Second)); err != nil || !res.Replayed {
+		t.Errorf("repeated answer = %+v, %v; want replayed", res, err)
+	}
+	if _, err := s.AnswerPracticeItem(ctx, child, session.ID, 2, "о", start.Add(8*time.Second)); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("changed answer error = %v, want ErrConflict", err)
+	}
+
+	res, err := s.FinishPracticeSession(ctx, child, session.ID, date, rewards, start.Add(10*time.Second))
+	if err != nil {
+		t.Fatalf("FinishPracticeSession error = %v", err)
+	}
+	if res.Session.CorrectCount != 1 || res.Session.XPAwarded != 10 || res.Session.CoinsAwarded != 10 || res.Answered != 3 {
+		t.Fatalf("finish = %+v, want 1 counted answer for 10 XP and 10 coins", res.Session)
+	}
+	if again, err := s.FinishPracticeSession(ctx, child, session.ID, date, rewards, start.Add(11*time.Second)); err != nil || !again.Replayed {
+		t.Errorf("repeated finish = %+v, %v; want replayed", again, err)
+	}
+
+	// Вторая сессия упирается в дневной лимит
+	second, err := s.CreatePracticeSession(ctx, child, practice.KindMultiplication, 2, items[:2], start, start.Add(practice.SessionTTL))
+	if err != nil {
+		t.Fatalf("CreatePracticeSession error = %v", err)
+	}
+	for i, given := range []string{"6", "20"} {
+		if _, err := s.AnswerPracticeItem(ctx, child, second.ID, i, given, start.Add(time.Duration(i+1)*2*time.Second)); err != nil {
+			t.Fatalf("AnswerPracticeItem error = %v", err)
+		}
+	}
+	res, err = s.FinishPracticeSession(ctx, child, second.ID, date, rewards, start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("FinishPracticeSession error = %v", err)
+	}
+	if !res.CapReached || res.Session.XPAwarded != 5 || res.Session.CoinsAwarded != 5 {
+		t.Errorf("capped finish = %+v, cap %v; want 5 XP and 5 coins", res.Session, res.CapReached)
+	}
+	if _, err := s.AnswerPracticeItem(ctx, child, second.ID, 0, "6", start.Add(2*time.Minute)); err != nil {
+		t.Errorf("replay after finish error = %v, want nil", err)
+	}
+
+	var coins int
+	if err := db.QueryRow(`SELECT coins_balance FROM child_profiles WHERE id = $1`, child).Scan(&coins); err != nil {
+		t.Fatalf("get coins: %v", err)
+	}
+	if coins != 15 {
+		t.Errorf("coins = %d, want 15", coins)
+	}
+}
@@ -0,0 +1,275 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/review"
+)
+
+// Статусы задачи в очереди повторения
+const (
+	ReviewItemActive  = "active"
+	ReviewItemRetired = "retired" // закреплена, больше не повторяется
+)
+
+// Источники задания для повторения
+const (
+	ReviewTaskAnalogue = "analogue" // аналог от LLM
+	ReviewTaskOriginal = "original" // исходная задача
+)
+
+// ReviewItemInput задача, которую ставят в очередь повторения
+type ReviewItemInput struct {
+	ChildProfileID  string
+	SourceAttemptID string
+	Reason          string // after_incorrect, after_3_hints
+	Subject         string
+	Grade           int
+	TaskText        string
+	TaskStruct      json.RawMessage // снимок Parse
+}
+
+// ReviewItem задача в очереди повторения
+type ReviewItem struct {
+	ID               int64
+	ChildProfileID   string
+	SourceAttemptID  string
+	Reason           string
+	Subject          string
+	Grade            int
+	TaskText         string
+	TaskStruct       json.RawMessage
+	ReviewTaskText   string
+	ReviewTaskSource string // analogue, original; пусто — задание ещё не готово
+	ReviewTaskFor    int    // для какого повторения (reviews_count) готово задание; -1 — ни для какого
+	Status           string
+	review.Schedule
+	DueOn          string // YYYY-MM-DD по календарю ребёнка
+	ReviewsCount   int
+	Lapses         int
+	LastReviewedAt *time.Time
+	CreatedAt      time.Time
+}
+
+// HasReviewTask готово ли задание для ближайшего повторения
+func (i *ReviewItem) HasReviewTask() bool {
+	return i.ReviewTaskText != "" && i.ReviewTaskFor == i.ReviewsCount
+}
+
+// ReviewStats сводка очереди повторения
+type ReviewStats struct {
+	Due       int    // к повторению сегодня (включая просроченные)
+	Upcoming  int    // запланировано на будущие дни
+	Retired   int    // закреплено
+	NextDueOn string // ближайший день будущего повторения; пусто, если нет
+}
+
+const reviewItemColumns = `id, child_profile_id, source_attempt_id, reason, subject, grade, task_text, task_struct,
+	COALESCE(review_task_text, ''), COALESCE(review_task_source, ''), COALESCE(review_task_for, -1),
+	status, repetitions, interval_days, ease_factor, to_char(due_on, 'YYYY-MM-DD'),
+	reviews_count, lapses, last_reviewed_at, created_at`
+
+func scanReviewItem(row rowScanner) (*ReviewItem, error) {
+	var i ReviewItem
+	var taskStruct []byte
+	var lastReviewedAt sql.NullTime
+	err := row.Scan(&i.ID, &i.ChildProfileID, &i.SourceAttemptID, &i.Reason, &i.Subject, &i.Grade, &i.TaskText, &taskStruct,
+		&i.ReviewTaskText, &i.ReviewTaskSource, &i.ReviewTaskFor,
+		&i.Status, &i.Repetitions, &i.IntervalDays, &i.EaseFactor, &i.DueOn,
+		&i.ReviewsCount, &i.Lapses, &lastReviewedAt, &i.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if len(taskStruct) > 0 {
+		i.TaskStruct = taskStruct
+	}
+	if lastReviewedAt.Valid {
+		i.LastReviewedAt = &lastReviewedAt.Time
+	}
+	return &i, nil
+}
+
+// EnqueueReviewItem ставит задачу в очередь с первым повторением в день dueOn.
+// Одна попытка попадает в очередь один раз; false — задача уже в очереди.
+func (s *Store) EnqueueReviewItem(ctx context.Context, in ReviewItemInput, dueOn string) (bool, error) {
+	initial := review.Initial()
+	var taskStruct interface{}
+	if len(in.TaskStruct) > 0 {
+		taskStruct = []byte(in.TaskStruct)
+	}
+	res, err := s.DB.ExecContext(ctx, `
+		INSERT INTO review_items (child_profile_id, source_attempt_id, reason, subject, grade, task_text, task_struct,
+		                          repetitions, interval_days, ease_factor, due_on)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (child_profile_id, source_attempt_id) DO NOTHING
+	`, in.ChildProfileID, in.SourceAttemptID, in.Reason, in.Subject, in.Grade, in.TaskText, taskStruct,
+		initial.Repetitions, initial.IntervalDays, initial.EaseFactor, dueOn)
+	if err != nil {
+		return false, fmt.Errorf("enqueue review item: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// GetDueReviewItems задачи, которые пора повторить к дню today: сначала самые просроченные
+func (s *Store) GetDueReviewItems(ctx context.Context, childProfileID, today string, limit int) ([]ReviewItem, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+reviewItemColumns+`
+		FROM review_items
+		WHERE child_profile_id = $1 AND status = 'active' AND due_on <= $2
+		ORDER BY due_on, id
+		LIMIT $3
+	`, childProfileID, today, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query due review items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []ReviewItem
+	for rows.Next() {
+		item, err := scanReviewItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan review item: %w", err)
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// GetReviewStats сводка очереди ребёнка на день today
+func (s *Store) GetReviewStats(ctx context.Context, childProfileID, today string) (*ReviewStats, error) {
+	var stats ReviewStats
+	var nextDueOn sql.NullString
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FILTER (WHERE status = 'active' AND due_on <= $2),
+		       COUNT(*) FILTER (WHERE status = 'active' AND due_on > $2),
+		       COUNT(*) FILTER (WHERE status = 'retired'),
+		       to_char(MIN(due_on) FILTER (WHERE status = 'active' AND due_on > $2), 'YYYY-MM-DD')
+		FROM review_items
+		WHERE child_profile_id = $1
+	`, childProfileID, today).Scan(&stats.Due, &stats.Upcoming, &stats.Retired, &nextDueOn)
+	if err != nil {
+		return nil, fmt.Errorf("get review stats: %w", err)
+	}
+	stats.NextDueOn = nextDueOn.String
+	return &stats, nil
+}
+
+// GetReviewItem задача очереди ребёнка
+func (s *Store) GetReviewItem(ctx context.Context, childProfileID string, id int64) (*ReviewItem, error) {
+	item, err := scanReviewItem(s.DB.QueryRowContext(ctx,
+		`SELECT `+reviewItemColumns+` FROM review_items WHERE id = $1 AND child_profile_id = $2`, id, childProfileID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get review item: %w", err)
+	}
+	return item, nil
+}
+
+// GetReviewItemsWithoutTask активные задачи, для ближайшего повторения которых задание ещё не готово:
+// сначала те, что повторять раньше
+func (s *Store) GetReviewItemsWithoutTask(ctx context.Context, limit int) ([]ReviewItem, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT `+reviewItemColumns+`
+		FROM review_items
+		WHERE status = 'active' AND review_task_for IS DISTINCT FROM reviews_count
+		ORDER BY due_on, id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query review items without task: %w", err)
+	}
+	defer rows.Close()
+
+	var items []ReviewItem
+	for rows.Next() {
+		item, err := scanReviewItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan review item: %w", err)
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// SetReviewTask сохраняет задание для повторения номер forReview, если для него задания ещё нет.
+// false — задание уже сохранено раньше, и оно не меняется: ребёнок решает то, что ему показали.
+func (s *Store) SetReviewTask(ctx context.Context, id int64, text, source string, forReview int) (bool, error) {
+	res, err := s.DB.ExecContext(ctx, `
+		UPDATE review_items
+		SET review_task_text = $2, review_task_source = $3, review_task_for = $4, updated_at = NOW()
+		WHERE id = $1 AND review_task_for IS DISTINCT FROM $4
+	`, id, text, source, forReview)
+	if err != nil {
+		return false, fmt.Errorf("set review task: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// RecordReviewResult записывает повторение с качеством quality в день today и переносит задачу
+// по SM-2. Задачу можно повторить, только когда подошёл её день: повторная отправка
+// того же повторения возвращает domain.ErrReviewNotDue.
+func (s *Store) RecordReviewResult(ctx context.Context, childProfileID string, id int64, decision string, quality int, today string, now time.Time) (*ReviewItem, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	item, err := scanReviewItem(tx.QueryRowContext(ctx,
+		`SELECT `+reviewItemColumns+` FROM review_items WHERE id = $1 AND child_profile_id = $2 FOR UPDATE`,
+		id, childProfileID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock review item: %w", err)
+	}
+	if item.Status != ReviewItemActive || item.DueOn > today {
+		return nil, domain.ErrReviewNotDue
+	}
+
+	next := review.Next(item.Schedule, quality)
+	status := ReviewItemActive
+	if review.Retired(next) {
+		status = ReviewItemRetired
+	}
+	lapses := 0
+	if quality < review.QualityHard {
+		lapses = 1
+	}
+
+	updated, err := scanReviewItem(tx.QueryRowContext(ctx, `
+		UPDATE review_items
+		SET repetitions = $3, interval_days = $4, ease_factor = $5, due_on = $6::DATE + $4,
+		    status = $7, reviews_count = reviews_count + 1, lapses = lapses + $8,
+		    last_reviewed_at = $9, updated_at = NOW()
+		WHERE id = $1 AND child_profile_id = $2
+		RETURNING `+reviewItemColumns,
+		id, childProfileID, next.Repetitions, next.IntervalDays, next.EaseFactor, today, status, lapses, now))
+	if err != nil {
+		return nil, fmt.Errorf("reschedule review item: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO review_results (review_item_id, child_profile_id, decision, quality, task_source, interval_days, due_on, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8)
+	`, id, childProfileID, decision, quality, item.ReviewTaskSource, updated.IntervalDays, updated.DueOn, now)
+	if err != nil {
+		return nil, fmt.Errorf("insert review result: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit review result: %w", err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/review"
+)
+
+func TestReviewQueue_EnqueueAndReschedule(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	child := createTestProfile(t, db, testID("review"), 0)
+	in := ReviewItemInput{
+		ChildProfileID:  child,
+		SourceAttemptID: uuid.NewString(),
+		Reason:          review.ReasonAfterIncorrect,
+		Subject:         "math",
+		Grade:           2,
+		TaskText:        "Реши: 7 + 8",
+	}
+
+	created, err := s.EnqueueReviewItem(ctx, in, "2026-03-03")
+	if err != nil || !created {
+		t.Fatalf("EnqueueReviewItem = %v, %v, want created", created, err)
+	}
+	// Та же попытка второй раз в очередь не попадает
+	if created, err := s.EnqueueReviewItem(ctx, in, "2026-03-03"); err != nil || created {
+		t.Fatalf("repeated EnqueueReviewItem = %v, %v, want not created", created, err)
+	}
+
+	if due, err := s.GetDueReviewItems(ctx, child, "2026-03-02", 10); err != nil || len(due) != 0 {
+		t.Fatalf("due before the day = %d, %v, want none", len(due), err)
+	}
+	due, err := s.GetDueReviewItems(ctx, child, "2026-03-03", 10)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("due on the day = %d, %v, want 1", len(due), err)
+	}
+	item := due[0]
+	if item.HasReviewTask() || item.ReviewTaskFor != -1 || item.EaseFactor != review.DefaultEaseFactor {
+		t.Errorf("new item = %+v, want no review task and default EF", item)
+	}
+
+	pending, err := s.GetReviewItemsWithoutTask(ctx, 1000)
+	if err != nil || !containsReviewItem(pending, item.ID) {
+		t.Fatalf("GetReviewItemsWithoutTask = %d items, %v, want the new item", len(pending), err)
+	}
+	if set, err := s.SetReviewTask(ctx, item.ID, "Реши: 6 + 9", ReviewTaskAnalogue, item.ReviewsCount); err != nil || !set {
+		t.Fatalf("SetReviewTask = %v, %v, want set", set, err)
+	}
+	// Показанное задание не подменяется
+	if set, err := s.SetReviewTask(ctx, item.ID, in.TaskText, ReviewTaskOriginal, item.ReviewsCount); err != nil || set {
+		t.Fatalf("repeated SetReviewTask = %v, %v, want kept", set, err)
+	}
+	pending, err = s.GetReviewItemsWithoutTask(ctx, 1000)
+	if err != nil || containsReviewItem(pending, item.ID) {
+		t.Fatalf("GetReviewItemsWithoutTask after set = %v, want the item skipped", err)
+	}
+
+	now := time.Now()
+	updated, err := s.RecordReviewResult(ctx, child, item.ID, "correct", review.QualityPerfect, "2026-03-03", now)
+	if err != nil {
+		t.Fatalf("RecordReviewResult error = %v", err)
+	}
+	want := review.Next(review.Initial(), review.QualityPerfect)
+	if updated.Schedule != want || updated.DueOn != "2026-03-04" || updated.ReviewsCount != 1 {
+		t.Errorf("rescheduled = %+v due %s, want %+v due 2026-03-04", updated.Schedule, updated.DueOn, want)
+	}
+	// Задание было для прошлого повторения — для следующего его нужно готовить заново
+	if updated.HasReviewTask() {
+		t.Error("review task must not carry over to the next review")
+	}
+
+	// Повторная отправка того же повторения
+	if _, err := s.RecordReviewResult(ctx, child, item.ID, "correct", review.QualityPerfect, "2026-03-03", now); !errors.Is(err, domain.ErrReviewNotDue) {
+		t.Errorf("repeated RecordReviewResult error = %v, want ErrReviewNotDue", err)
+	}
+	// Чужой ребёнок задачу не видит
+	other := createTestProfile(t, db, testID("review_other"), 0)
+	if _, err := s.GetReviewItem(ctx, other, item.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetReviewItem(other child) error = %v, want ErrNotFound", err)
+	}
+
+	stats, err := s.GetReviewStats(ctx, child, "2026-03-03")
+	if err != nil {
+		t.Fatalf("GetReviewStats error = %v", err)
+	}
+	if stats.Due != 0 || stats.Upcoming != 1 || stats.NextDueOn != "2026-03-04" {
+		t.Errorf("stats = %+v, want 1 upcoming on 2026-03-04", stats)
+	}
+}
+
+func containsReviewItem(items []ReviewItem, id int64) bool {
+	for _, item := range items {
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
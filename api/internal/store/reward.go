@@ -0,0 +1,216 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/domain"
+	"child-bot/api/internal/reward"
+)
+
+// RewardGrant начисление за учебное действие, которое проходит через политику наград
+type RewardGrant struct {
+	Currency  string // CurrencyXP или CurrencyCoins
+	Amount    int
+	Source    WalletSource
+	AttemptID string // попытка, за задачу которой награда; пусто — награда не за задачу
+}
+
+// RewardResult итог начисления по политике
+type RewardResult struct {
+	Applied   bool // в журнале появилась запись; false — повтор события или урезано до нуля
+	Replayed  bool // событие уже оценивалось раньше
+	Requested int
+	Granted   int
+	Reason    string // reward.Reason*, если начислено меньше запрошенного
+	Level     int
+	LeveledUp bool
+}
+
+// RewardPolicyEvent начисление, урезанное политикой наград
+type RewardPolicyEvent struct {
+	ID        int64
+	Currency  string
+	Source    string
+	SourceID  sql.NullString
+	Requested int
+	Granted   int
+	Reason    string
+	Repeats   int
+	CreatedAt time.Time
+}
+
+// GrantReward начисляет награду с учётом политики s.Rewards: дневного лимита источника
+// по календарю ребёнка, повтора той же задачи и показанного ответа
+func (s *Store) GrantReward(ctx context.Context, childProfileID string, g RewardGrant) (*RewardResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := grantRewardTx(ctx, tx, childProfileID, g, s.Rewards, s.XP, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return res, nil
+}
+
+// grantRewardTx оценивает начисление по политике, записывает урезанное в аудит и
+// начисляет остаток внутри переданной транзакции
+func grantRewardTx(ctx context.Context, tx *sql.Tx, childProfileID string, g RewardGrant, policy reward.Policy, xp XPConfig, now time.Time) (*RewardResult, error) {
+	res := &RewardResult{Requested: g.Amount}
+	if g.Amount <= 0 {
+		return res, nil
+	}
+
+	// Блокируем профиль: начисления одного ребёнка оцениваются последовательно
+	var tz string
+	err := tx.QueryRowContext(ctx, `SELECT timezone FROM child_profiles WHERE id = $1 FOR UPDATE`, childProfileID).Scan(&tz)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock profile: %w", err)
+	}
+
+	// Ключ фиксируем один раз: событие без идентификатора получает случайный
+	key := g.Source.idempotencyKey(g.Currency)
+	g.Source.Key = strings.TrimPrefix(key, g.Currency+":")
+
+	var seen bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM wallet_transactions WHERE child_profile_id = $1 AND idempotency_key = $2)
+		    OR EXISTS (SELECT 1 FROM reward_policy_events WHERE child_profile_id = $1 AND idempotency_key = $2)
+	`, childProfileID, key).Scan(&seen)
+	if err != nil {
+		return nil, fmt.Errorf("check reward replay: %w", err)
+	}
+	if seen {
+		res.Replayed = true
+		return res, nil
+	}
+
+	req := reward.Request{Source: g.Source.Type, Currency: g.Currency, Amount: g.Amount}
+
+	dayStart := calendar.StartOfDay(now, calendar.Location(tz))
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM wallet_transactions
+		WHERE child_profile_id = $1 AND currency = $2 AND source = $3 AND amount > 0
+		  AND created_at >= $4 AND created_at < $5
+	`, childProfileID, g.Currency, g.Source.Type, dayStart, dayStart.AddDate(0, 0, 1)).Scan(&req.EarnedToday)
+	if err != nil {
+		return nil, fmt.Errorf("sum today rewards: %w", err)
+	}
+
+	if g.AttemptID != "" {
+		if req.Repeats, req.AnswerShown, err = attemptTaskHistoryTx(ctx, tx, childProfileID, g.AttemptID); err != nil {
+			return nil, err
+		}
+	}
+
+	d := policy.Apply(req)
+	res.Granted, res.Reason = d.Amount, d.Reason
+
+	if d.Reduced(g.Amount) {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO reward_policy_events (child_profile_id, currency, source, source_id,
+			                                  idempotency_key, requested, granted, reason, repeats)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (child_profile_id, idempotency_key) DO NOTHING
+		`, childProfileID, g.Currency, g.Source.Type,
+			sql.NullString{String: g.Source.ID, Valid: g.Source.ID != ""},
+			key, g.Amount, d.Amount, d.Reason, req.Repeats)
+		if err != nil {
+			return nil, fmt.Errorf("record reward policy event: %w", err)
+		}
+		log.Printf("[Store] Reward reduced: child=%s, key=%s, %d -> %d (%s)", childProfileID, key, g.Amount, d.Amount, d.Reason)
+	}
+
+	if d.Amount == 0 {
+		return res, nil
+	}
+
+	// Повтор ключа отсечён выше под блокировкой профиля, так что запись в журнале появится
+	if g.Currency == CurrencyXP {
+		res.Level, res.LeveledUp, err = addXPTx(ctx, tx, childProfileID, d.Amount, xp, g.Source)
+		if err != nil {
+			return nil, err
+		}
+		res.Applied = true
+		return res, nil
+	}
+
+	res.Applied, _, err = applyWalletEntryTx(ctx, tx, childProfileID, g.Currency, d.Amount, g.Source)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// attemptTaskHistoryTx сколько раз ребёнок уже брался за задачу попытки (тем же видом
+// попытки) и показан ли ответ на неё — в этой или другой попытке. Задача узнаётся по
+// отпечатку фото или распознанного условия.
+func attemptTaskHistoryTx(ctx context.Context, tx *sql.Tx, childProfileID, attemptID string) (int, bool, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM attempts o
+			 WHERE o.child_profile_id = a.child_profile_id AND o.id <> a.id
+			   AND o.attempt_type = a.attempt_type AND o.created_at < a.created_at
+			   AND (o.task_hash = a.task_hash OR o.image_hash = a.image_hash)),
+			a.answer_shown_at IS NOT NULL OR EXISTS (
+				SELECT 1 FROM attempts o
+				WHERE o.child_profile_id = a.child_profile_id AND o.id <> a.id
+				  AND o.answer_shown_at IS NOT NULL
+				  AND (o.task_hash = a.task_hash OR o.image_hash = a.image_hash))
+		FROM attempts a
+		WHERE a.id = $1 AND a.child_profile_id = $2
+	`
+	var repeats int
+	var answerShown bool
+	err := tx.QueryRowContext(ctx, query, attemptID, childProfileID).Scan(&repeats, &answerShown)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("get attempt task history: %w", err)
+	}
+	return repeats, answerShown, nil
+}
+
+// ListRewardPolicyEvents урезанные начисления ребёнка начиная с since, новые первыми
+func (s *Store) ListRewardPolicyEvents(ctx context.Context, childProfileID string, since time.Time, limit int) ([]RewardPolicyEvent, error) {
+	query := `
+		SELECT id, currency, source, source_id, requested, granted, reason, repeats, created_at
+		FROM reward_policy_events
+		WHERE child_profile_id = $1 AND created_at >= $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+	rows, err := s.DB.QueryContext(ctx, query, childProfileID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list reward policy events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []RewardPolicyEvent
+	for rows.Next() {
+		var e RewardPolicyEvent
+		if err := rows.Scan(&e.ID, &e.Currency, &e.Source, &e.SourceID, &e.Requested, &e.Granted,
+			&e.Reason, &e.Repeats, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan reward policy event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/reward"
+
+	"github.com/google/uuid"
+)
+
+func TestGrantReward_HintFarming(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	s.Rewards = reward.Policy{
+		Caps:               []reward.Cap{{Source: WalletSourceHint, Currency: CurrencyXP, Daily: 30}},
+		RepeatPercents:     []int{100, 50, 0},
+		AnswerShownSources: []string{WalletSourceHint},
+	}
+	ctx := context.Background()
+
+	child := createTestProfile(t, db, testID("reward"), 0)
+	newAttempt := func(image string) string {
+		id, err := s.Attempts.CreateAttempt(ctx, uuid.MustParse(child), "help")
+		if err != nil {
+			t.Fatalf("CreateAttempt error = %v", err)
+		}
+		if err := s.Attempts.UpdateTaskImage(ctx, id, image); err != nil {
+			t.Fatalf("UpdateTaskImage error = %v", err)
+		}
+		return id.String()
+	}
+	hint := func(attemptID string, index int) *RewardResult {
+		t.Helper()
+		res, err := s.GrantReward(ctx, child, RewardGrant{
+			Currency:  CurrencyXP,
+			Amount:    10,
+			AttemptID: attemptID,
+			Source:    WalletSource{Type: WalletSourceHint, ID: attemptID, Key: fmt.Sprintf("hint:%s:%d", attemptID, index)},
+		})
+		if err != nil {
+			t.Fatalf("GrantReward error = %v", err)
+		}
+		return res
+	}
+
+	first := newAttempt("data:image/png;base64,AAAA")
+	for i := 0; i < 2; i++ {
+		if res := hint(first, i); res.Granted != 10 || !res.Applied {
+			t.Errorf("first attempt hint %d = %+v, want 10 XP", i, res)
+		}
+	}
+	if res := hint(first, 1); !res.Replayed || res.Applied {
+		t.Errorf("repeated hint = %+v, want replayed", res)
+	}
+
+	// То же фото ещё раз — половина награды, а после показа ответа — ничего
+	time.Sleep(10 * time.Millisecond)
+	second := newAttempt("data:image/png;base64,AAAA")
+	if res := hint(second, 0); res.Granted != 5 || res.Reason != reward.ReasonRepeatedTask {
+		t.Errorf("repeated task hint = %+v, want 5 XP (repeated_task)", res)
+	}
+	if err := s.Attempts.MarkAnswerShown(ctx, uuid.MustParse(first)); err != nil {
+		t.Fatalf("MarkAnswerShown error = %v", err)
+	}
+	if res := hint(second, 1); res.Granted != 0 || res.Applied || res.Reason != reward.ReasonAnswerShown {
+		t.Errorf("hint after answer shown = %+v, want 0 XP (answer_shown)", res)
+	}
+
+	// Новая задача упирается в дневной лимит: 10 + 10 + 5 уже получено
+	third := newAttempt("data:image/png;base64,BBBB")
+	if res := hint(third, 0); res.Granted != 5 || res.Reason != reward.ReasonDailyCap {
+		t.Errorf("capped hint = %+v, want 5 XP (daily_cap)", res)
+	}
+
+	events, err := s.ListRewardPolicyEvents(ctx, child, time.Now().Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("ListRewardPolicyEvents error = %v", err)
+	}
+	if len(events) != 3 || events[0].Reason != reward.ReasonDailyCap {
+		t.Errorf("events = %+v, want 3 with daily_cap first", events)
+	}
+
+	var xp int
+	if err := db.QueryRow(`SELECT xp_total FROM child_profiles WHERE id = $1`, child).Scan(&xp); err != nil {
+		t.Fatalf("get xp: %v", err)
+	}
+	if xp != 30 {
+		t.Errorf("xp = %d, want 30", xp)
+	}
+}
@@ -0,0 +1,404 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/lib/pq"
+
+	"child-bot/api/internal/seasonal"
+)
+
+// EventVillainUnlockOrder порядок злодеев событий: вне ежедневной ротации 1..7 и недельного босса
+const EventVillainUnlockOrder = 200
+
+// SeasonalSyncResult итог синхронизации каталога событий
+type SeasonalSyncResult struct {
+	Events       int
+	Villains     int
+	Achievements int
+	ShopItems    int
+	Deactivated  int
+}
+
+// SeasonalEvent событие из БД
+type SeasonalEvent struct {
+	ID          string
+	Title       string
+	Description string
+	Icon        string
+	ThemeColor  string
+	StartDate   string // YYYY-MM-DD
+	EndDate     string // YYYY-MM-DD
+}
+
+// SeasonalAchievementProgress достижение события и прогресс ребёнка
+type SeasonalAchievementProgress struct {
+	ID          string
+	Icon        string
+	Title       string
+	Description string
+	Target      int
+	Progress    int
+	IsUnlocked  bool
+	RewardType  string
+	RewardName  string
+}
+
+// SeasonalVillainProgress злодей события и победа над ним
+type SeasonalVillainProgress struct {
+	ID         string
+	Name       string
+	ImageURL   string
+	Weekdays   []int
+	IsDefeated bool
+}
+
+// SeasonalShopItem предмет события и есть ли он у ребёнка
+type SeasonalShopItem struct {
+	ID       string
+	Category string
+	Name     string
+	Icon     string
+	Price    int
+	MinLevel int
+	Owned    bool
+}
+
+// SeasonalEventProgress прогресс ребёнка в событии
+type SeasonalEventProgress struct {
+	Achievements []SeasonalAchievementProgress
+	Villains     []SeasonalVillainProgress
+	ShopItems    []SeasonalShopItem
+}
+
+// SyncSeasonalEvents в одной транзакции добавляет и обновляет события каталога с их злодеями,
+// достижениями и предметами. События, которых нет в каталоге, выключаются: по ним может быть
+// прогресс. Строка с тем же id, но не принадлежащая событию, считается ошибкой каталога.
+func (s *Store) SyncSeasonalEvents(ctx context.Context, version int, events []seasonal.Event) (*SeasonalSyncResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &SeasonalSyncResult{}
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		if err := upsertSeasonalEventTx(ctx, tx, version, e); err != nil {
+			return nil, err
+		}
+		ids = append(ids, e.ID)
+		result.Events++
+
+		for _, v := range e.Villains {
+			if err := upsertEventVillainTx(ctx, tx, e.ID, v); err != nil {
+				return nil, err
+			}
+			result.Villains++
+		}
+		for _, a := range e.Achievements {
+			if err := upsertEventAchievementTx(ctx, tx, version, e.ID, a); err != nil {
+				return nil, err
+			}
+			result.Achievements++
+		}
+		for i, it := range e.ShopItems {
+			if err := upsertEventShopItemTx(ctx, tx, e.ID, i, it); err != nil {
+				return nil, err
+			}
+			result.ShopItems++
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE seasonal_events
+		SET is_active = false, updated_at = NOW()
+		WHERE is_active AND NOT (id = ANY($1))
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("deactivate seasonal events: %w", err)
+	}
+	deactivated, _ := res.RowsAffected()
+	result.Deactivated = int(deactivated)
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	log.Printf("[Store] Seasonal events synced: events=%d, villains=%d, achievements=%d, shop_items=%d, deactivated=%d",
+		result.Events, result.Villains, result.Achievements, result.ShopItems, result.Deactivated)
+	return result, nil
+}
+
+func upsertSeasonalEventTx(ctx context.Context, tx *sql.Tx, version int, e seasonal.Event) error {
+	query := `
+		INSERT INTO seasonal_events (id, title, description, icon, theme_color, start_date, end_date, catalog_version, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, true)
+		ON CONFLICT (id) DO UPDATE
+		SET title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    icon = EXCLUDED.icon,
+		    theme_color = EXCLUDED.theme_color,
+		    start_date = EXCLUDED.start_date,
+		    end_date = EXCLUDED.end_date,
+		    catalog_version = EXCLUDED.catalog_version,
+		    is_active = true,
+		    updated_at = NOW()
+	`
+	if _, err := tx.ExecContext(ctx, query,
+		e.ID, e.Title, e.Description, e.Icon, e.ThemeColor, e.StartDate, e.EndDate, version); err != nil {
+		return fmt.Errorf("upsert seasonal event %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+// expectOwnedRow превращает «ни одной строки» после upsert с условием на event_id в ошибку
+func expectOwnedRow(res sql.Result, what, id string) error {
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return fmt.Errorf("%s %s already exists outside the event", what, id)
+	}
+	return nil
+}
+
+func upsertEventVillainTx(ctx context.Context, tx *sql.Tx, eventID string, v seasonal.Villain) error {
+	query := `
+		INSERT INTO villains (id, name, description, image_url, max_hp, level, damage_per_correct_task,
+		                      unlock_order, reward_coins, is_boss, event_id, event_weekdays)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, false, $10, $11)
+		ON CONFLICT (id) DO UPDATE
+		SET name = EXCLUDED.name,
+		    description = EXCLUDED.description,
+		    image_url = EXCLUDED.image_url,
+		    max_hp = EXCLUDED.max_hp,
+		    level = EXCLUDED.level,
+		    damage_per_correct_task = EXCLUDED.damage_per_correct_task,
+		    reward_coins = EXCLUDED.reward_coins,
+		    event_weekdays = EXCLUDED.event_weekdays
+		WHERE villains.event_id = EXCLUDED.event_id
+	`
+	res, err := tx.ExecContext(ctx, query,
+		v.ID, v.Name, v.Description, v.ImageURL, v.MaxHP, v.Level, v.DamagePerCorrectTask,
+		EventVillainUnlockOrder, v.RewardCoins, eventID, pq.Array(v.Weekdays))
+	if err != nil {
+		return fmt.Errorf("upsert event villain %s: %w", v.ID, err)
+	}
+	return expectOwnedRow(res, "villain", v.ID)
+}
+
+func upsertEventAchievementTx(ctx context.Context, tx *sql.Tx, version int, eventID string, a seasonal.Achievement) error {
+	query := `
+		INSERT INTO achievements (id, type, title, description, icon, requirement_type, requirement_value,
+		                          reward_type, reward_name, reward_amount, priority, catalog_version, event_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO UPDATE
+		SET title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    icon = EXCLUDED.icon,
+		    requirement_type = EXCLUDED.requirement_type,
+		    requirement_value = EXCLUDED.requirement_value,
+		    reward_type = EXCLUDED.reward_type,
+		    reward_name = EXCLUDED.reward_name,
+		    reward_amount = EXCLUDED.reward_amount,
+		    priority = EXCLUDED.priority,
+		    catalog_version = EXCLUDED.catalog_version,
+		    updated_at = NOW()
+		WHERE achievements.event_id = EXCLUDED.event_id
+	`
+	res, err := tx.ExecContext(ctx, query,
+		a.ID, seasonal.CategorySeasonal, a.Title, a.Description, a.Icon,
+		seasonal.CounterType(eventID, a.Requirement.Type), a.Requirement.Value,
+		a.Reward.Type, a.RewardName, sql.NullInt32{Int32: int32(a.Reward.Amount), Valid: a.Reward.Amount > 0},
+		a.Priority, version, eventID)
+	if err != nil {
+		return fmt.Errorf("upsert event achievement %s: %w", a.ID, err)
+	}
+	return expectOwnedRow(res, "achievement", a.ID)
+}
+
+func upsertEventShopItemTx(ctx context.Context, tx *sql.Tx, eventID string, order int, it seasonal.ShopItem) error {
+	query := `
+		INSERT INTO shop_items (id, category, name, description, icon, price, min_level, sort_order, event_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE
+		SET category = EXCLUDED.category,
+		    name = EXCLUDED.name,
+		    description = EXCLUDED.description,
+		    icon = EXCLUDED.icon,
+		    price = EXCLUDED.price,
+		    min_level = EXCLUDED.min_level,
+		    sort_order = EXCLUDED.sort_order,
+		    is_active = TRUE
+		WHERE shop_items.event_id = EXCLUDED.event_id
+	`
+	res, err := tx.ExecContext(ctx, query,
+		it.ID, it.Category, it.Name, it.Description, it.Icon, it.Price, it.MinLevel, order, eventID)
+	if err != nil {
+		return fmt.Errorf("upsert event shop item %s: %w", it.ID, err)
+	}
+	return expectOwnedRow(res, "shop item", it.ID)
+}
+
+// GetOpenSeasonalEvents события, идущие в день date (YYYY-MM-DD); раньше заканчивающиеся — первыми
+func (s *Store) GetOpenSeasonalEvents(ctx context.Context, date string) ([]SeasonalEvent, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT id, title, description, icon, theme_color,
+		       to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD')
+		FROM seasonal_events
+		WHERE is_active AND $1::DATE BETWEEN start_date AND end_date
+		ORDER BY end_date, id
+	`, date)
+	if err != nil {
+		return nil, fmt.Errorf("query open seasonal events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []SeasonalEvent
+	for rows.Next() {
+		var e SeasonalEvent
+		if err := rows.Scan(&e.ID, &e.Title, &e.Description, &e.Icon, &e.ThemeColor, &e.StartDate, &e.EndDate); err != nil {
+			return nil, fmt.Errorf("scan seasonal event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate seasonal events: %w", err)
+	}
+	return events, nil
+}
+
+// GetSeasonalEventProgress достижения, злодеи и предметы события с прогрессом ребёнка
+func (s *Store) GetSeasonalEventProgress(ctx context.Context, childProfileID, eventID string) (*SeasonalEventProgress, error) {
+	progress := &SeasonalEventProgress{}
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT a.id, a.icon, a.title, a.description, a.requirement_value,
+		       LEAST(COALESCE(c.value, 0), a.requirement_value), COALESCE(ca.is_unlocked, FALSE),
+		       a.reward_type, COALESCE(a.reward_name, '')
+		FROM achievements a
+		LEFT JOIN child_achievement_counters c
+		       ON c.child_profile_id = $1 AND c.requirement_type = a.requirement_type
+		LEFT JOIN child_achievements ca
+		       ON ca.child_profile_id = $1 AND ca.achievement_id = a.id
+		WHERE a.event_id = $2
+		ORDER BY a.priority, a.id
+	`, childProfileID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query event achievements: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a SeasonalAchievementProgress
+		if err := rows.Scan(&a.ID, &a.Icon, &a.Title, &a.Description, &a.Target,
+			&a.Progress, &a.IsUnlocked, &a.RewardType, &a.RewardName); err != nil {
+			return nil, fmt.Errorf("scan event achievement: %w", err)
+		}
+		if a.IsUnlocked {
+			a.Progress = a.Target
+		}
+		progress.Achievements = append(progress.Achievements, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate event achievements: %w", err)
+	}
+
+	vrows, err := s.DB.QueryContext(ctx, `
+		SELECT v.id, v.name, v.image_url, COALESCE(v.event_weekdays, '{}'),
+		       EXISTS (
+		           SELECT 1 FROM villain_battles vb
+		           WHERE vb.child_profile_id = $1 AND vb.villain_id = v.id AND vb.status = 'defeated'
+		       )
+		FROM villains v
+		WHERE v.event_id = $2
+		ORDER BY v.id
+	`, childProfileID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query event villains: %w", err)
+	}
+	defer vrows.Close()
+	for vrows.Next() {
+		var v SeasonalVillainProgress
+		var weekdays pq.Int64Array
+		if err := vrows.Scan(&v.ID, &v.Name, &v.ImageURL, &weekdays, &v.IsDefeated); err != nil {
+			return nil, fmt.Errorf("scan event villain: %w", err)
+		}
+		for _, d := range weekdays {
+			v.Weekdays = append(v.Weekdays, int(d))
+		}
+		progress.Villains = append(progress.Villains, v)
+	}
+	if err := vrows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate event villains: %w", err)
+	}
+
+	irows, err := s.DB.QueryContext(ctx, `
+		SELECT i.id, i.category, i.name, i.icon, i.price, i.min_level,
+		       COALESCE(inv.quantity, 0) > 0
+		FROM shop_items i
+		LEFT JOIN child_inventory inv
+		       ON inv.child_profile_id = $1 AND inv.item_id = i.id
+		WHERE i.event_id = $2 AND i.is_active = TRUE
+		ORDER BY i.sort_order, i.id
+	`, childProfileID, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("query event shop items: %w", err)
+	}
+	defer irows.Close()
+	for irows.Next() {
+		var it SeasonalShopItem
+		if err := irows.Scan(&it.ID, &it.Category, &it.Name, &it.Icon, &it.Price, &it.MinLevel, &it.Owned); err != nil {
+			return nil, fmt.Errorf("scan event shop item: %w", err)
+		}
+		progress.ShopItems = append(progress.ShopItems, it)
+	}
+	if err := irows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate event shop items: %w", err)
+	}
+
+	return progress, nil
+}
+
+// GetEventVillainForDay злодей события, идущего в день date, для дня недели weekday (1..7).
+// Злодей, которого ребёнок уже победил, больше не появляется. Нет такого — nil.
+func (s *VillainStore) GetEventVillainForDay(ctx context.Context, childProfileID, date string, weekday int) (*VillainRow, error) {
+	query := `
+		SELECT v.id, v.name, v.description, v.image_url, v.max_hp, v.level,
+		       v.damage_per_correct_task, v.damage_formula, v.unlock_order, v.reward_coins,
+		       v.reward_achievement_id, v.is_boss
+		FROM villains v
+		JOIN seasonal_events e ON e.id = v.event_id
+		WHERE e.is_active AND $2::DATE BETWEEN e.start_date AND e.end_date
+		  AND $3 = ANY(v.event_weekdays)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM villain_battles vb
+		      WHERE vb.child_profile_id = $1 AND vb.villain_id = v.id AND vb.status = 'defeated'
+		  )
+		ORDER BY e.end_date, v.id
+		LIMIT 1
+	`
+
+	var villain VillainRow
+	err := s.db.QueryRowContext(ctx, query, childProfileID, date, weekday).Scan(
+		&villain.ID,
+		&villain.Name,
+		&villain.Description,
+		&villain.ImageURL,
+		&villain.MaxHP,
+		&villain.Level,
+		&villain.DamagePerCorrectTask,
+		&villain.DamageFormula,
+		&villain.UnlockOrder,
+		&villain.RewardCoins,
+		&villain.RewardAchievementID,
+		&villain.IsBoss,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event villain: %w", err)
+	}
+	return &villain, nil
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/seasonal"
+)
+
+func TestSeasonalEvents_OpenEventContent(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	catalog, err := seasonal.Default()
+	if err != nil {
+		t.Fatalf("seasonal.Default() error = %v", err)
+	}
+
+	// Событие идёт вчера—завтра: сегодня оно открыто в любом часовом поясе
+	now := time.Now().UTC()
+	id := fmt.Sprintf("t%d", now.UnixNano()%1e12)
+	event := seasonal.Event{
+		ID:        id,
+		Title:     "Тестовое событие",
+		StartDate: now.AddDate(0, 0, -1).Format("2006-01-02"),
+		EndDate:   now.AddDate(0, 0, 1).Format("2006-01-02"),
+		Villains: []seasonal.Villain{{
+			ID: id + "_villain", Name: "Злодей", ImageURL: "/x.png", Level: 1, MaxHP: 10,
+			DamagePerCorrectTask: 10, Weekdays: []int{1, 2, 3, 4, 5, 6, 7},
+		}},
+		Achievements: []seasonal.Achievement{{
+			ID: id + "_tasks_2", Title: "Две задачи", Icon: "⭐",
+			Requirement: seasonal.Requirement{Type: RequirementTasksCorrect, Value: 2},
+			Reward:      seasonal.Reward{Type: "coins", Amount: 5},
+		}},
+		ShopItems: []seasonal.ShopItem{{ID: id + "_hat", Category: "mascot_item", Name: "Шляпа", Icon: "🎩", Price: 10, MinLevel: 1}},
+	}
+	events := append(append([]seasonal.Event{}, catalog.Events...), event)
+	if _, err := s.SyncSeasonalEvents(ctx, catalog.Version, events); err != nil {
+		t.Fatalf("SyncSeasonalEvents error = %v", err)
+	}
+	// Повторная синхронизация того же каталога проходит без ошибок
+	if _, err := s.SyncSeasonalEvents(ctx, catalog.Version, events); err != nil {
+		t.Fatalf("repeated SyncSeasonalEvents error = %v", err)
+	}
+
+	child := createTestProfile(t, db, testID("seasonal"), 0)
+	today := calendar.DateKey(time.Now(), time.UTC)
+
+	items, err := s.ListShopItems(ctx, child, "mascot_item")
+	if err != nil {
+		t.Fatalf("ListShopItems error = %v", err)
+	}
+	found := false
+	for _, it := range items {
+		found = found || it.ID == id+"_hat"
+	}
+	if !found {
+		t.Errorf("ListShopItems does not offer the open event item %s_hat", id)
+	}
+
+	villain, err := s.Villains.GetEventVillainForDay(ctx, child, today, calendar.ISOWeekday(time.Now(), time.UTC))
+	if err != nil || villain == nil {
+		t.Fatalf("GetEventVillainForDay = %v, %v, want an event villain", villain, err)
+	}
+
+	// Прогресс события идёт в свой счётчик
+	update := AchievementCounterUpdate{RequirementType: seasonal.CounterType(id, RequirementTasksCorrect), Delta: 2}
+	unlocked, err := s.ApplyAchievementEvent(ctx, child, "attempt:"+id, []AchievementCounterUpdate{update}, 0)
+	if err != nil || len(unlocked) != 1 || unlocked[0].AchievementID != id+"_tasks_2" {
+		t.Fatalf("ApplyAchievementEvent = %+v, %v, want %s_tasks_2 unlocked", unlocked, err, id)
+	}
+
+	progress, err := s.GetSeasonalEventProgress(ctx, child, id)
+	if err != nil {
+		t.Fatalf("GetSeasonalEventProgress error = %v", err)
+	}
+	if len(progress.Achievements) != 1 || !progress.Achievements[0].IsUnlocked || progress.Achievements[0].Progress != 2 {
+		t.Errorf("achievements = %+v, want one unlocked with progress 2", progress.Achievements)
+	}
+	if len(progress.Villains) != 1 || len(progress.ShopItems) != 1 {
+		t.Errorf("progress = %+v, want one villain and one shop item", progress)
+	}
+
+	// Id, занятый обычным предметом, событию не достаётся
+	clash := event
+	clash.Villains, clash.Achievements = nil, nil
+	clash.ShopItems = []seasonal.ShopItem{{ID: StreakFreezeItemID, Category: "mascot_item", Name: "X", Icon: "x", Price: 1, MinLevel: 1}}
+	_, err = s.SyncSeasonalEvents(ctx, catalog.Version, append(append([]seasonal.Event{}, catalog.Events...), clash))
+	if err == nil || !strings.Contains(err.Error(), "outside the event") {
+		t.Errorf("SyncSeasonalEvents with a clashing id error = %v, want outside the event", err)
+	}
+}
@@ -27,6 +27,7 @@ type HintContextData struct {
 	MaxHints        int             `json:"max_hints"`                   // максимум подсказок
 	ImageBase64     string          `json:"image_base64"`                // изображение в base64 (опционально)
 	CachedHintsJSON json.RawMessage `json:"cached_hints_json,omitempty"` // кэш ответа LLM со всеми подсказками
+	DialogJSON      json.RawMessage `json:"dialog_json,omitempty"`       // диалог ребёнка с наставником по задаче
 }
 
 // ParseContextData — структура для сериализации контекста ожидания подтверждения парсинга
@@ -0,0 +1,351 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"child-bot/api/internal/domain"
+)
+
+// Категории предметов магазина
+const (
+	ShopCategoryAvatar     = "avatar"
+	ShopCategoryMascotItem = "mascot_item"
+	ShopCategoryPowerUp    = "power_up"
+)
+
+// PowerUpDoubleHit усилитель «Двойной удар»: верная проверка, для которой его выбрал ребёнок, бьёт злодея сильнее
+const PowerUpDoubleHit = "power_double_hit"
+
+// ShopItem предмет каталога магазина
+type ShopItem struct {
+	ID          string
+	Category    string
+	Name        string
+	Description string
+	Icon        string
+	Price       int
+	MinLevel    int
+	IsDefault   bool
+	IsStackable bool
+	Effect      json.RawMessage
+	SortOrder   int
+}
+
+// ShopItemWithOwnership предмет каталога вместе с тем, что есть у ребёнка
+type ShopItemWithOwnership struct {
+	ShopItem
+	Quantity int  // сколько штук в инвентаре
+	Owned    bool // бесплатный по умолчанию, куплен или получен за достижение
+}
+
+// InventoryItem предмет в инвентаре ребёнка
+type InventoryItem struct {
+	ShopItem
+	Quantity   int
+	AcquiredAt time.Time
+}
+
+// ShopPurchase результат покупки
+type ShopPurchase struct {
+	Item         ShopItem
+	Quantity     int // сколько куплено этой покупкой
+	TotalPrice   int
+	OwnedQty     int // сколько теперь в инвентаре
+	CoinsBalance int
+	Replayed     bool // покупка с этим ключом уже была проведена ранее
+}
+
+const shopItemColumns = `
+	i.id, i.category, i.name, i.description, i.icon, i.price, i.min_level,
+	i.is_default, i.is_stackable, i.effect, i.sort_order
+`
+
+func scanShopItem(scan func(dest ...interface{}) error, item *ShopItem, extra ...interface{}) error {
+	var effect []byte
+	dest := []interface{}{
+		&item.ID, &item.Category, &item.Name, &item.Description, &item.Icon, &item.Price, &item.MinLevel,
+		&item.IsDefault, &item.IsStackable, &effect, &item.SortOrder,
+	}
+	if err := scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	if len(effect) > 0 {
+		item.Effect = json.RawMessage(effect)
+	}
+	return nil
+}
+
+// ListShopItems возвращает активные предметы каталога (опционально одной категории)
+// и отмечает, какие из них уже есть у ребёнка. Пустой childProfileID — только каталог.
+func (s *Store) ListShopItems(ctx context.Context, childProfileID, category string) ([]ShopItemWithOwnership, error) {
+	query := `
+		SELECT ` + shopItemColumns + `,
+		       COALESCE(inv.quantity, 0),
+		       i.is_default
+		           OR COALESCE(inv.quantity, 0) > 0
+		           OR EXISTS (
+		               SELECT 1 FROM child_rewards r
+		               WHERE r.child_profile_id::TEXT = $1
+		                 AND r.reward_type = 'avatar' AND i.category = 'avatar'
+		                 AND r.reward_id = i.id
+		           )
+		FROM shop_items i
+		LEFT JOIN child_inventory inv
+			ON inv.item_id = i.id AND inv.child_profile_id::TEXT = $1
+		WHERE i.is_active = TRUE AND ($2 = '' OR i.category = $2)
+		  -- Предметы события продаются только в его даты
+		  AND (i.event_id IS NULL OR seasonal_event_open(i.event_id, $1))
+		ORDER BY i.category, i.sort_order, i.id
+	`
+	rows, err := s.DB.QueryContext(ctx, query, childProfileID, category)
+	if err != nil {
+		return nil, fmt.Errorf("list shop items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []ShopItemWithOwnership
+	for rows.Next() {
+		var item ShopItemWithOwnership
+		if err := scanShopItem(rows.Scan, &item.ShopItem, &item.Quantity, &item.Owned); err != nil {
+			return nil, fmt.Errorf("scan shop item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate shop items: %w", err)
+	}
+
+	return items, nil
+}
+
+// GetInventory возвращает купленные ребёнком предметы
+func (s *Store) GetInventory(ctx context.Context, childProfileID string) ([]InventoryItem, error) {
+	query := `
+		SELECT ` + shopItemColumns + `, inv.quantity, inv.acquired_at
+		FROM child_inventory inv
+		JOIN shop_items i ON i.id = inv.item_id
+		WHERE inv.child_profile_id = $1 AND inv.quantity > 0
+		ORDER BY i.category, i.sort_order, i.id
+	`
+	rows, err := s.DB.QueryContext(ctx, query, childProfileID)
+	if err != nil {
+		return nil, fmt.Errorf("get inventory: %w", err)
+	}
+	defer rows.Close()
+
+	var items []InventoryItem
+	for rows.Next() {
+		var item InventoryItem
+		if err := scanShopItem(rows.Scan, &item.ShopItem, &item.Quantity, &item.AcquiredAt); err != nil {
+			return nil, fmt.Errorf("scan inventory item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate inventory: %w", err)
+	}
+
+	return items, nil
+}
+
+// PurchaseShopItem в одной транзакции проверяет уровень и баланс, списывает монеты
+// через журнал, кладёт предмет в инвентарь и записывает покупку в shop_purchases.
+// Повтор с тем же idempotencyKey ничего не списывает и возвращает Replayed = true;
+// тот же ключ для другого предмета — domain.ErrConflict.
+func (s *Store) PurchaseShopItem(ctx context.Context, childProfileID, itemID string, quantity int, idempotencyKey string) (*ShopPurchase, error) {
+	if quantity <= 0 || idempotencyKey == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Блокируем профиль: покупки одного ребёнка проходят последовательно
+	var level, coins int
+	profileQuery := `SELECT COALESCE(level, 1), COALESCE(coins_balance, 0) FROM child_profiles WHERE id = $1 FOR UPDATE`
+	if err := tx.QueryRowContext(ctx, profileQuery, childProfileID).Scan(&level, &coins); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get profile: %w", err)
+	}
+
+	// Повтор запроса: покупка с этим ключом уже проведена
+	purchase := &ShopPurchase{Quantity: quantity}
+	var replayedItemID string
+	replayQuery := `
+		SELECT item_id, quantity, total_price FROM shop_purchases
+		WHERE child_profile_id = $1 AND idempotency_key = $2
+	`
+	err = tx.QueryRowContext(ctx, replayQuery, childProfileID, idempotencyKey).
+		Scan(&replayedItemID, &purchase.Quantity, &purchase.TotalPrice)
+	replayed := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("check purchase replay: %w", err)
+	}
+	if replayed && replayedItemID != itemID {
+		return nil, domain.ErrConflict
+	}
+
+	// Проведённая покупка возвращается, даже если предмет уже снят с продажи
+	itemQuery := `SELECT ` + shopItemColumns + ` FROM shop_items i
+		WHERE i.id = $1 AND ($3 OR (i.is_active = TRUE
+		  AND (i.event_id IS NULL OR seasonal_event_open(i.event_id, $2))))`
+	if err := scanShopItem(tx.QueryRowContext(ctx, itemQuery, itemID, childProfileID, replayed).Scan, &purchase.Item); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("get shop item: %w", err)
+	}
+	item := purchase.Item
+
+	if !replayed {
+		if level < item.MinLevel {
+			return nil, domain.ErrLevelTooLow
+		}
+		if !item.IsStackable && quantity > 1 {
+			return nil, domain.ErrInvalidInput
+		}
+
+		// Аватар мог быть получен за достижение — тогда он уже есть, как в IsAvatarOwned
+		var ownedQty int
+		var rewarded bool
+		ownedQuery := `
+			SELECT
+				COALESCE((SELECT SUM(quantity) FROM child_inventory WHERE child_profile_id = $1 AND item_id = $2), 0),
+				$3 = 'avatar' AND EXISTS (
+					SELECT 1 FROM child_rewards
+					WHERE child_profile_id = $1 AND reward_type = 'avatar' AND reward_id = $2
+				)
+		`
+		if err := tx.QueryRowContext(ctx, ownedQuery, childProfileID, item.ID, item.Category).Scan(&ownedQty, &rewarded); err != nil {
+			return nil, fmt.Errorf("get owned quantity: %w", err)
+		}
+		if item.IsDefault || rewarded || (!item.IsStackable && ownedQty > 0) {
+			return nil, domain.ErrItemAlreadyOwned
+		}
+
+		purchase.TotalPrice = s.XP.ShopPrice(item, level) * quantity
+		if purchase.TotalPrice > 0 {
+			src := WalletSource{
+				Type:        WalletSourcePurchase,
+				ID:          item.ID,
+				Key:         "purchase:" + idempotencyKey,
+				Description: item.Name,
+			}
+			if _, _, err := applyWalletEntryTx(ctx, tx, childProfileID, CurrencyCoins, -purchase.TotalPrice, src); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := addInventoryTx(ctx, tx, childProfileID, item.ID, quantity); err != nil {
+			return nil, err
+		}
+
+		insertQuery := `
+			INSERT INTO shop_purchases (child_profile_id, idempotency_key, item_id, quantity, total_price)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		if _, err := tx.ExecContext(ctx, insertQuery, childProfileID, idempotencyKey, item.ID, quantity, purchase.TotalPrice); err != nil {
+			return nil, fmt.Errorf("record purchase: %w", err)
+		}
+	}
+	purchase.Replayed = replayed
+
+	stateQuery := `
+		SELECT COALESCE(cp.coins_balance, 0), COALESCE(inv.quantity, 0)
+		FROM child_profiles cp
+		LEFT JOIN child_inventory inv ON inv.child_profile_id = cp.id AND inv.item_id = $2
+		WHERE cp.id = $1
+	`
+	if err := tx.QueryRowContext(ctx, stateQuery, childProfileID, item.ID).Scan(&purchase.CoinsBalance, &purchase.OwnedQty); err != nil {
+		return nil, fmt.Errorf("get purchase state: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	log.Printf("[Store] Shop purchase: child=%s, item=%s, qty=%d, price=%d, balance=%d, replayed=%v",
+		childProfileID, item.ID, purchase.Quantity, purchase.TotalPrice, purchase.CoinsBalance, replayed)
+
+	return purchase, nil
+}
+
+// IsAvatarOwned проверяет, может ли ребёнок надеть аватар: бесплатный по умолчанию,
+// купленный в магазине или полученный за достижение. Без профиля (пустой ID) — только бесплатный.
+func (s *Store) IsAvatarOwned(ctx context.Context, childProfileID, avatarID string) (bool, error) {
+	var owned bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM shop_items
+			WHERE id = $2 AND category = 'avatar' AND is_default = TRUE
+		) OR EXISTS (
+			SELECT 1 FROM child_inventory inv
+			JOIN shop_items i ON i.id = inv.item_id
+			WHERE inv.child_profile_id = NULLIF($1, '')::uuid AND inv.item_id = $2
+			  AND i.category = 'avatar' AND inv.quantity > 0
+		) OR EXISTS (
+			SELECT 1 FROM child_rewards
+			WHERE child_profile_id = NULLIF($1, '')::uuid AND reward_type = 'avatar' AND reward_id = $2
+		)
+	`
+	if err := s.DB.QueryRowContext(ctx, query, childProfileID, avatarID).Scan(&owned); err != nil {
+		return false, fmt.Errorf("check avatar owned: %w", err)
+	}
+	return owned, nil
+}
+
+// ConsumePowerUp списывает из инвентаря один усилитель и возвращает его эффект.
+// Списание — один условный UPDATE, поэтому усилитель не тратится дважды; нет усилителя — nil.
+func (s *Store) ConsumePowerUp(ctx context.Context, childProfileID, itemID string) (json.RawMessage, error) {
+	return consumePowerUp(ctx, s.DB, childProfileID, itemID)
+}
+
+// rowQuerier БД или транзакция для выборки одной строки
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// consumePowerUp списывает усилитель в БД или в транзакции (см. ConsumePowerUp)
+func consumePowerUp(ctx context.Context, db rowQuerier, childProfileID, itemID string) (json.RawMessage, error) {
+	var effect []byte
+	err := db.QueryRowContext(ctx, `
+		UPDATE child_inventory inv
+		SET quantity = inv.quantity - 1, updated_at = NOW()
+		FROM shop_items i
+		WHERE inv.child_profile_id = $1 AND inv.item_id = $2 AND inv.quantity > 0
+		  AND i.id = inv.item_id AND i.category = 'power_up'
+		RETURNING COALESCE(i.effect, '{}'::jsonb)
+	`, childProfileID, itemID).Scan(&effect)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("consume power-up %s: %w", itemID, err)
+	}
+	return json.RawMessage(effect), nil
+}
+
+// addInventoryTx кладёт quantity штук предмета в инвентарь ребёнка в рамках транзакции
+func addInventoryTx(ctx context.Context, tx *sql.Tx, childProfileID, itemID string, quantity int) error {
+	query := `
+		INSERT INTO child_inventory (child_profile_id, item_id, quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (child_profile_id, item_id) DO UPDATE
+		SET quantity = child_inventory.quantity + EXCLUDED.quantity,
+		    updated_at = NOW()
+	`
+	if _, err := tx.ExecContext(ctx, query, childProfileID, itemID, quantity); err != nil {
+		return fmt.Errorf("add inventory item: %w", err)
+	}
+	return nil
+}
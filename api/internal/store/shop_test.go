@@ -0,0 +1,276 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"child-bot/api/internal/damage"
+	"child-bot/api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// createTestShopItem creates a shop item and returns its ID
+func createTestShopItem(t *testing.T, db *sql.DB, category string, price, minLevel int, stackable bool) string {
+	t.Helper()
+
+	id := testID("test_item")
+	query := `
+		INSERT INTO shop_items (id, category, name, icon, price, min_level, is_stackable)
+		VALUES ($1, $2, 'Test item', '🎁', $3, $4, $5)
+	`
+	if _, err := db.Exec(query, id, category, price, minLevel, stackable); err != nil {
+		t.Fatalf("failed to create test shop item: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM shop_items WHERE id = $1`, id); err != nil {
+			t.Logf("Warning: failed to cleanup test shop item: %v", err)
+		}
+	})
+
+	return id
+}
+
+func TestPurchaseShopItem_DebitsThroughLedger(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("shop_buy"), 200)
+	itemID := createTestShopItem(t, db, ShopCategoryAvatar, 150, 1, false)
+
+	purchase, err := s.PurchaseShopItem(ctx, childID, itemID, 1, "key-1")
+	if err != nil {
+		t.Fatalf("PurchaseShopItem() error = %v", err)
+	}
+	if purchase.CoinsBalance != 50 || purchase.OwnedQty != 1 || purchase.Replayed {
+		t.Errorf("purchase = %+v, want balance 50, owned 1, not replayed", purchase)
+	}
+
+	// Повтор с тем же ключом не списывает монеты второй раз
+	again, err := s.PurchaseShopItem(ctx, childID, itemID, 1, "key-1")
+	if err != nil {
+		t.Fatalf("repeated PurchaseShopItem() error = %v", err)
+	}
+	if !again.Replayed || again.CoinsBalance != 50 {
+		t.Errorf("repeated purchase = %+v, want replayed with balance 50", again)
+	}
+
+	// Новый ключ для уже купленного аватара
+	if _, err := s.PurchaseShopItem(ctx, childID, itemID, 1, "key-2"); !errors.Is(err, domain.ErrItemAlreadyOwned) {
+		t.Errorf("second purchase error = %v, want ErrItemAlreadyOwned", err)
+	}
+
+	txs, total, err := s.ListWalletTransactions(ctx, childID, CurrencyCoins, 10, 0)
+	if err != nil {
+		t.Fatalf("ListWalletTransactions() error = %v", err)
+	}
+	if total != 1 || txs[0].Source != WalletSourcePurchase || txs[0].Amount != -150 || txs[0].SourceID.String != itemID {
+		t.Errorf("unexpected ledger entries: total=%d, %+v", total, txs)
+	}
+
+	owned, err := s.IsAvatarOwned(ctx, childID, itemID)
+	if err != nil {
+		t.Fatalf("IsAvatarOwned() error = %v", err)
+	}
+	if !owned {
+		t.Error("expected purchased avatar to be owned")
+	}
+
+	// Без профиля доступны только бесплатные аватары
+	if owned, err := s.IsAvatarOwned(ctx, "", itemID); err != nil || owned {
+		t.Errorf("IsAvatarOwned() without profile = %v, %v; want false", owned, err)
+	}
+}
+
+func TestPurchaseShopItem_Rejections(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("shop_reject"), 20)
+	expensive := createTestShopItem(t, db, ShopCategoryMascotItem, 100, 1, false)
+	highLevel := createTestShopItem(t, db, ShopCategoryAvatar, 10, 5, false)
+
+	if _, err := s.PurchaseShopItem(ctx, childID, expensive, 1, "poor"); !errors.Is(err, domain.ErrInsufficientFunds) {
+		t.Errorf("expensive: error = %v, want ErrInsufficientFunds", err)
+	}
+	if _, err := s.PurchaseShopItem(ctx, childID, highLevel, 1, "level"); !errors.Is(err, domain.ErrLevelTooLow) {
+		t.Errorf("high level: error = %v, want ErrLevelTooLow", err)
+	}
+	if _, err := s.PurchaseShopItem(ctx, childID, "missing_item", 1, "missing"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("missing: error = %v, want ErrNotFound", err)
+	}
+
+	coins, _ := getBalances(t, db, childID)
+	if coins != 20 {
+		t.Errorf("coins_balance = %d, want 20 (nothing debited)", coins)
+	}
+
+	owned, err := s.IsAvatarOwned(ctx, childID, highLevel)
+	if err != nil {
+		t.Fatalf("IsAvatarOwned() error = %v", err)
+	}
+	if owned {
+		t.Error("avatar must not be owned after rejected purchase")
+	}
+}
+
+func TestPurchaseShopItem_RewardedAvatarOwned(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("shop_rewarded"), 100)
+	avatarID := createTestShopItem(t, db, ShopCategoryAvatar, 30, 1, false)
+	if _, err := db.Exec(`
+		INSERT INTO child_rewards (child_profile_id, reward_type, reward_id, reward_name)
+		VALUES ($1, 'avatar', $2, 'Test avatar')
+	`, childID, avatarID); err != nil {
+		t.Fatalf("failed to grant avatar reward: %v", err)
+	}
+
+	if _, err := s.PurchaseShopItem(ctx, childID, avatarID, 1, "rewarded"); !errors.Is(err, domain.ErrItemAlreadyOwned) {
+		t.Errorf("rewarded avatar: error = %v, want ErrItemAlreadyOwned", err)
+	}
+	if coins, _ := getBalances(t, db, childID); coins != 100 {
+		t.Errorf("coins_balance = %d, want 100 (nothing debited)", coins)
+	}
+}
+
+func TestPurchaseShopItem_ReplayByKey(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("shop_replay"), 100)
+	freeID := createTestShopItem(t, db, ShopCategoryPowerUp, 0, 1, true)
+	otherID := createTestShopItem(t, db, ShopCategoryPowerUp, 10, 1, true)
+
+	if _, err := s.PurchaseShopItem(ctx, childID, freeID, 2, "free-1"); err != nil {
+		t.Fatalf("PurchaseShopItem() error = %v", err)
+	}
+	replay, err := s.PurchaseShopItem(ctx, childID, freeID, 2, "free-1")
+	if err != nil {
+		t.Fatalf("PurchaseShopItem() replay error = %v", err)
+	}
+	if !replay.Replayed || replay.OwnedQty != 2 {
+		t.Errorf("free replay = %+v, want replayed with owned 2", replay)
+	}
+
+	if _, err := s.PurchaseShopItem(ctx, childID, otherID, 1, "free-1"); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("key reused for another item: error = %v, want ErrConflict", err)
+	}
+	if coins, _ := getBalances(t, db, childID); coins != 100 {
+		t.Errorf("coins_balance = %d, want 100 (nothing debited)", coins)
+	}
+}
+
+func TestPurchaseShopItem_Stackable(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("shop_stack"), 100)
+	itemID := createTestShopItem(t, db, ShopCategoryPowerUp, 10, 1, true)
+
+	if _, err := s.PurchaseShopItem(ctx, childID, itemID, 3, "stack-1"); err != nil {
+		t.Fatalf("PurchaseShopItem() error = %v", err)
+	}
+	purchase, err := s.PurchaseShopItem(ctx, childID, itemID, 2, "stack-2")
+	if err != nil {
+		t.Fatalf("PurchaseShopItem() error = %v", err)
+	}
+	if purchase.OwnedQty != 5 || purchase.CoinsBalance != 50 {
+		t.Errorf("purchase = %+v, want owned 5, balance 50", purchase)
+	}
+}
+
+func TestConsumePowerUp(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("shop_power"), 100)
+	itemID := createTestShopItem(t, db, ShopCategoryPowerUp, 10, 1, true)
+	if _, err := db.Exec(`UPDATE shop_items SET effect = '{"damage_multiplier": 2}' WHERE id = $1`, itemID); err != nil {
+		t.Fatalf("set effect: %v", err)
+	}
+	if _, err := s.PurchaseShopItem(ctx, childID, itemID, 1, "power-1"); err != nil {
+		t.Fatalf("PurchaseShopItem() error = %v", err)
+	}
+
+	effect, err := s.ConsumePowerUp(ctx, childID, itemID)
+	if err != nil || string(effect) != `{"damage_multiplier": 2}` {
+		t.Fatalf("ConsumePowerUp() = %s, %v; want effect", effect, err)
+	}
+	// Единственный усилитель уже потрачен
+	if effect, err := s.ConsumePowerUp(ctx, childID, itemID); err != nil || effect != nil {
+		t.Errorf("second ConsumePowerUp() = %s, %v; want nil", effect, err)
+	}
+}
+
+func TestApplyDamage_ChosenPowerUp(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("shop_damage"), 100)
+	itemID := createTestShopItem(t, db, ShopCategoryPowerUp, 10, 1, true)
+	if _, err := db.Exec(`UPDATE shop_items SET effect = '{"damage_multiplier": 2}' WHERE id = $1`, itemID); err != nil {
+		t.Fatalf("set effect: %v", err)
+	}
+	if _, err := s.PurchaseShopItem(ctx, childID, itemID, 1, "damage-1"); err != nil {
+		t.Fatalf("PurchaseShopItem() error = %v", err)
+	}
+
+	villainID := testID("test_villain")
+	if _, err := db.Exec(`
+		INSERT INTO villains (id, name, description, image_url, max_hp, damage_per_correct_task)
+		VALUES ($1, 'Test villain', 'Test', 'villain.png', 100, 10)
+	`, villainID); err != nil {
+		t.Fatalf("failed to create villain: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM villains WHERE id = $1`, villainID); err != nil {
+			t.Logf("Warning: failed to cleanup test villain: %v", err)
+		}
+	})
+	var battleID int64
+	if err := db.QueryRow(`
+		INSERT INTO villain_battles (child_profile_id, villain_id, status, current_hp)
+		VALUES ($1, $2, 'active', 100)
+		RETURNING id
+	`, childID, villainID).Scan(&battleID); err != nil {
+		t.Fatalf("failed to create battle: %v", err)
+	}
+	var attemptID uuid.UUID
+	if err := db.QueryRow(`
+		INSERT INTO attempts (child_profile_id, attempt_type, status)
+		VALUES ($1, 'check', 'completed')
+		RETURNING id
+	`, childID).Scan(&attemptID); err != nil {
+		t.Fatalf("failed to create attempt: %v", err)
+	}
+
+	formula := damage.Formula{Base: 10}
+	task := damage.Task{HintsUsed: 1}
+
+	// Без выбора ребёнка усилитель не тратится
+	hit, hp, err := s.Villains.ApplyDamage(ctx, childID, battleID, attemptID, "check", formula, task, "")
+	if err != nil || hit.Total != 10 || hp != 90 {
+		t.Fatalf("ApplyDamage() = %d, hp %d, %v; want 10, hp 90", hit.Total, hp, err)
+	}
+	hit, hp, err = s.Villains.ApplyDamage(ctx, childID, battleID, attemptID, "check", formula, task, itemID)
+	if err != nil || hit.Total != 20 || hp != 70 {
+		t.Fatalf("ApplyDamage(power-up) = %d, hp %d, %v; want 20, hp 70", hit.Total, hp, err)
+	}
+	// Усилитель потрачен — следующий урон обычный
+	hit, hp, err = s.Villains.ApplyDamage(ctx, childID, battleID, attemptID, "check", formula, task, itemID)
+	if err != nil || hit.Total != 10 || hp != 60 {
+		t.Errorf("ApplyDamage(spent power-up) = %d, hp %d, %v; want 10, hp 60", hit.Total, hp, err)
+	}
+}
@@ -1,11 +1,17 @@
 package store
 
-import "database/sql"
+import (
+	"database/sql"
+
+	"child-bot/api/internal/reward"
+)
 
 type Store struct {
 	DB       *sql.DB
 	Attempts *AttemptStore
 	Villains *VillainStore
+	Rewards  reward.Policy // политика наград за учебные действия (GrantReward)
+	XP       XPConfig      // таблица уровней: пороги XP, награды и скидки магазина
 }
 
 func NewStore(db *sql.DB) *Store {
@@ -13,5 +19,7 @@ func NewStore(db *sql.DB) *Store {
 		DB:       db,
 		Attempts: NewAttemptStore(db),
 		Villains: NewVillainStore(db),
+		Rewards:  reward.DefaultPolicy,
+		XP:       DefaultXPConfig,
 	}
 }
@@ -0,0 +1,471 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"child-bot/api/internal/calendar"
+	"child-bot/api/internal/domain"
+)
+
+// Статусы дня в истории серии
+const (
+	StreakDayActive  = "active"  // ребёнок занимался
+	StreakDayFrozen  = "frozen"  // пропуск закрыт заморозкой
+	StreakDayExcused = "excused" // пропуск простил родитель
+	StreakDayMissed  = "missed"  // пропуск оборвал серию (не хранится, выводится календарём)
+	StreakDayNone    = "none"    // до начала занятий, сегодня пока без активности
+)
+
+const (
+	// StreakFreezeItemID предмет магазина «Заморозка серии»
+	StreakFreezeItemID = "streak_freeze"
+	// MaxStreakGapDays пропуск длиннее не закрывается заморозками и прощёнными днями
+	MaxStreakGapDays = 31
+	// MaxExcuseDaysBack на сколько дней назад родитель может простить пропуск
+	MaxExcuseDaysBack = 7
+)
+
+const dateLayout = "2006-01-02"
+
+// StreakDay день в истории серии
+type StreakDay struct {
+	Date   string // YYYY-MM-DD по календарю ребёнка
+	Status string
+	Reason string
+}
+
+// StreakActivity результат отметки активности за день
+type StreakActivity struct {
+	PreviousStreak int
+	Streak         int
+	SameDay        bool // сегодня ребёнок уже занимался
+	FreezesUsed    int
+	ExcusedDays    int  // сколько пропущенных дней закрыли прощённые родителем
+	Broken         bool // пропуск оборвал серию
+}
+
+// StreakExcuse результат прощения дня родителем
+type StreakExcuse struct {
+	Date           string
+	Status         string // active, если ребёнок в этот день всё-таки занимался
+	FreezeRefunded bool   // день был закрыт заморозкой — она вернулась в инвентарь
+	Recovered      bool   // оборванная серия восстановлена
+	Streak         int
+}
+
+// StreakHistory сохранённые дни серии за период и текущее состояние
+type StreakHistory struct {
+	Days        []StreakDay // только сохранённые дни, по возрастанию даты
+	FirstDay    string      // первый день в истории, "" если ребёнок ещё не занимался
+	Streak      int
+	Freezes     int
+	BrokenValue int // длина оборванной серии, которую ещё можно восстановить
+}
+
+// gapCoverage чем закрыт пропуск в серии
+type gapCoverage struct {
+	Covered     bool
+	FreezesUsed int
+	ExcusedDays int
+}
+
+// RecordStreakActivity отмечает активность ребёнка в день now (по поясу loc) и пересчитывает серию.
+// Пропущенные дни закрываются прощёнными родителем днями и заморозками; заморозки расходуются,
+// только если их хватает на весь пропуск, иначе серия начинается заново и запоминается для восстановления.
+func (s *Store) RecordStreakActivity(ctx context.Context, childProfileID string, now time.Time, loc *time.Location) (*StreakActivity, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var lastActivityAt sql.NullTime
+	var streak int
+	err = tx.QueryRowContext(ctx,
+		`SELECT last_activity_at, streak_days FROM child_profiles WHERE id = $1 FOR UPDATE`,
+		childProfileID,
+	).Scan(&lastActivityAt, &streak)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock profile: %w", err)
+	}
+
+	act := &StreakActivity{PreviousStreak: streak, Streak: streak}
+	var brokenStart, brokenEnd string
+
+	if !lastActivityAt.Valid {
+		act.Streak = 1
+	} else {
+		daysDiff := calendar.DaysBetween(lastActivityAt.Time, now, loc)
+		switch {
+		case daysDiff <= 0:
+			// Тот же день (или пояс сменили на более западный)
+			act.SameDay = true
+		case daysDiff == 1:
+			act.Streak = streak + 1
+		default:
+			gapStart := calendar.StartOfDay(lastActivityAt.Time, loc).AddDate(0, 0, 1)
+			gapLen := daysDiff - 1
+			cov, err := coverStreakGapTx(ctx, tx, childProfileID, gapStart, gapLen)
+			if err != nil {
+				return nil, err
+			}
+			act.FreezesUsed = cov.FreezesUsed
+			act.ExcusedDays = cov.ExcusedDays
+			if cov.Covered {
+				act.Streak = streak + 1
+			} else {
+				act.Streak = 1
+				act.Broken = true
+				if streak > 0 && gapLen <= MaxStreakGapDays {
+					brokenStart = gapStart.Format(dateLayout)
+					brokenEnd = gapStart.AddDate(0, 0, gapLen-1).Format(dateLayout)
+				}
+			}
+		}
+	}
+
+	dayQuery := `
+		INSERT INTO child_streak_days (child_profile_id, day, status)
+		VALUES ($1, $2, 'active')
+		ON CONFLICT (child_profile_id, day) DO UPDATE
+		SET status = 'active', updated_at = NOW()
+		WHERE child_streak_days.status <> 'active'
+	`
+	if _, err := tx.ExecContext(ctx, dayQuery, childProfileID, calendar.DateKey(now, loc)); err != nil {
+		return nil, fmt.Errorf("record streak day: %w", err)
+	}
+
+	updateQuery := `
+		UPDATE child_profiles
+		SET streak_days = $2,
+		    last_activity_at = $3,
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.ExecContext(ctx, updateQuery, childProfileID, act.Streak, now); err != nil {
+		return nil, fmt.Errorf("update streak: %w", err)
+	}
+
+	if act.Broken {
+		// Запоминаем обрыв: если родитель простит пропущенные дни, серия вернётся
+		brokenValue := 0
+		if brokenStart != "" {
+			brokenValue = streak
+		}
+		brokenQuery := `
+			UPDATE child_profiles
+			SET streak_broken_value = $2,
+			    streak_broken_gap_start = NULLIF($3, '')::date,
+			    streak_broken_gap_end = NULLIF($4, '')::date
+			WHERE id = $1
+		`
+		if _, err := tx.ExecContext(ctx, brokenQuery, childProfileID, brokenValue, brokenStart, brokenEnd); err != nil {
+			return nil, fmt.Errorf("save broken streak: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	if act.FreezesUsed > 0 || act.ExcusedDays > 0 || act.Broken {
+		log.Printf("[Store] Streak gap: child=%s, streak %d -> %d, freezes_used=%d, excused=%d, broken=%v",
+			childProfileID, act.PreviousStreak, act.Streak, act.FreezesUsed, act.ExcusedDays, act.Broken)
+	}
+
+	return act, nil
+}
+
+// coverStreakGapTx проверяет, закрыт ли пропуск из gapLen дней начиная с gapStart.
+// Дни, которые не простил родитель, закрываются заморозками — только если их хватает на все.
+func coverStreakGapTx(ctx context.Context, tx *sql.Tx, childProfileID string, gapStart time.Time, gapLen int) (*gapCoverage, error) {
+	cov := &gapCoverage{}
+	if gapLen <= 0 {
+		cov.Covered = true
+		return cov, nil
+	}
+	if gapLen > MaxStreakGapDays {
+		return cov, nil
+	}
+
+	first := gapStart.Format(dateLayout)
+	last := gapStart.AddDate(0, 0, gapLen-1).Format(dateLayout)
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT to_char(day, 'YYYY-MM-DD'), status
+		FROM child_streak_days
+		WHERE child_profile_id = $1 AND day BETWEEN $2 AND $3
+	`, childProfileID, first, last)
+	if err != nil {
+		return nil, fmt.Errorf("query gap days: %w", err)
+	}
+	covered := make(map[string]bool)
+	for rows.Next() {
+		var day, status string
+		if err := rows.Scan(&day, &status); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan gap day: %w", err)
+		}
+		covered[day] = true
+		if status == StreakDayExcused {
+			cov.ExcusedDays++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate gap days: %w", err)
+	}
+
+	need := gapLen - len(covered)
+	if need == 0 {
+		cov.Covered = true
+		return cov, nil
+	}
+
+	var freezes int
+	err = tx.QueryRowContext(ctx, `
+		SELECT quantity FROM child_inventory
+		WHERE child_profile_id = $1 AND item_id = $2
+		FOR UPDATE
+	`, childProfileID, StreakFreezeItemID).Scan(&freezes)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("get streak freezes: %w", err)
+	}
+	if need > freezes {
+		// Частично закрывать пропуск бессмысленно: серия всё равно оборвётся
+		return cov, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE child_inventory
+		SET quantity = quantity - $3, updated_at = NOW()
+		WHERE child_profile_id = $1 AND item_id = $2
+	`, childProfileID, StreakFreezeItemID, need); err != nil {
+		return nil, fmt.Errorf("use streak freezes: %w", err)
+	}
+
+	frozenQuery := `
+		INSERT INTO child_streak_days (child_profile_id, day, status)
+		VALUES ($1, $2, 'frozen')
+		ON CONFLICT (child_profile_id, day) DO NOTHING
+	`
+	for i := 0; i < gapLen; i++ {
+		day := gapStart.AddDate(0, 0, i).Format(dateLayout)
+		if covered[day] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, frozenQuery, childProfileID, day); err != nil {
+			return nil, fmt.Errorf("record frozen day: %w", err)
+		}
+	}
+
+	cov.Covered = true
+	cov.FreezesUsed = need
+	return cov, nil
+}
+
+// ExcuseStreakDay отмечает день прощённым родителем (болезнь, поездка).
+// День должен быть не в будущем и не старше MaxExcuseDaysBack. Если день был закрыт заморозкой,
+// она возвращается; если день попал в пропуск, оборвавший серию, и пропуск теперь закрыт — серия восстанавливается.
+func (s *Store) ExcuseStreakDay(ctx context.Context, childProfileID, date, reason, grantedBy string, now time.Time, loc *time.Location) (*StreakExcuse, error) {
+	day, err := calendar.ParseDate(date, loc)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if ago := calendar.DaysBetween(day, now, loc); ago < 0 || ago > MaxExcuseDaysBack {
+		return nil, domain.ErrInvalidInput
+	}
+	date = day.Format(dateLayout)
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var streak, brokenValue int
+	var brokenStart, brokenEnd sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT streak_days, streak_broken_value,
+		       to_char(streak_broken_gap_start, 'YYYY-MM-DD'), to_char(streak_broken_gap_end, 'YYYY-MM-DD')
+		FROM child_profiles
+		WHERE id = $1
+		FOR UPDATE
+	`, childProfileID).Scan(&streak, &brokenValue, &brokenStart, &brokenEnd)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lock profile: %w", err)
+	}
+
+	excuse := &StreakExcuse{Date: date, Status: StreakDayExcused, Streak: streak}
+
+	var status string
+	err = tx.QueryRowContext(ctx,
+		`SELECT status FROM child_streak_days WHERE child_profile_id = $1 AND day = $2`,
+		childProfileID, date,
+	).Scan(&status)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("get streak day: %w", err)
+	}
+
+	switch status {
+	case StreakDayActive:
+		// Ребёнок в этот день занимался — прощать нечего
+		excuse.Status = StreakDayActive
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit tx: %w", err)
+		}
+		return excuse, nil
+	case StreakDayFrozen:
+		if err := addInventoryTx(ctx, tx, childProfileID, StreakFreezeItemID, 1); err != nil {
+			return nil, err
+		}
+		excuse.FreezeRefunded = true
+	}
+
+	upsertQuery := `
+		INSERT INTO child_streak_days (child_profile_id, day, status, reason, granted_by)
+		VALUES ($1, $2, 'excused', $3, NULLIF($4, ''))
+		ON CONFLICT (child_profile_id, day) DO UPDATE
+		SET status = 'excused',
+		    reason = EXCLUDED.reason,
+		    granted_by = EXCLUDED.granted_by,
+		    updated_at = NOW()
+	`
+	if _, err := tx.ExecContext(ctx, upsertQuery, childProfileID, date, reason, grantedBy); err != nil {
+		return nil, fmt.Errorf("excuse streak day: %w", err)
+	}
+
+	// Восстановление серии, оборванной пропуском, в который попал прощённый день
+	if brokenValue > 0 && brokenStart.Valid && brokenEnd.Valid &&
+		date >= brokenStart.String && date <= brokenEnd.String {
+		gapStart, err := time.Parse(dateLayout, brokenStart.String)
+		if err != nil {
+			return nil, fmt.Errorf("parse broken gap: %w", err)
+		}
+		gapEnd, err := time.Parse(dateLayout, brokenEnd.String)
+		if err != nil {
+			return nil, fmt.Errorf("parse broken gap: %w", err)
+		}
+		gapLen := calendar.DaysBetween(gapStart, gapEnd, time.UTC) + 1
+
+		cov, err := coverStreakGapTx(ctx, tx, childProfileID, gapStart, gapLen)
+		if err != nil {
+			return nil, err
+		}
+		if cov.Covered {
+			recoverQuery := `
+				UPDATE child_profiles
+				SET streak_days = streak_days + streak_broken_value,
+				    streak_broken_value = 0,
+				    streak_broken_gap_start = NULL,
+				    streak_broken_gap_end = NULL,
+				    updated_at = NOW()
+				WHERE id = $1
+				RETURNING streak_days
+			`
+			if err := tx.QueryRowContext(ctx, recoverQuery, childProfileID).Scan(&excuse.Streak); err != nil {
+				return nil, fmt.Errorf("recover streak: %w", err)
+			}
+			excuse.Recovered = true
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	log.Printf("[Store] Streak day excused: child=%s, day=%s, refunded=%v, recovered=%v, streak=%d",
+		childProfileID, date, excuse.FreezeRefunded, excuse.Recovered, excuse.Streak)
+
+	return excuse, nil
+}
+
+// GetStreakHistory возвращает сохранённые дни серии за период [from, to] (YYYY-MM-DD)
+// вместе с текущей серией и количеством заморозок
+func (s *Store) GetStreakHistory(ctx context.Context, childProfileID, from, to string) (*StreakHistory, error) {
+	h := &StreakHistory{Days: []StreakDay{}}
+
+	var firstDay sql.NullString
+	err := s.DB.QueryRowContext(ctx, `
+		SELECT cp.streak_days, cp.streak_broken_value,
+		       COALESCE((SELECT quantity FROM child_inventory
+		                 WHERE child_profile_id = cp.id AND item_id = $2), 0),
+		       (SELECT to_char(MIN(day), 'YYYY-MM-DD') FROM child_streak_days WHERE child_profile_id = cp.id)
+		FROM child_profiles cp
+		WHERE cp.id = $1
+	`, childProfileID, StreakFreezeItemID).Scan(&h.Streak, &h.BrokenValue, &h.Freezes, &firstDay)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get streak state: %w", err)
+	}
+	h.FirstDay = firstDay.String
+
+	rows, err := s.DB.QueryContext(ctx, `
+		SELECT to_char(day, 'YYYY-MM-DD'), status, reason
+		FROM child_streak_days
+		WHERE child_profile_id = $1 AND day BETWEEN $2 AND $3
+		ORDER BY day
+	`, childProfileID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query streak days: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d StreakDay
+		if err := rows.Scan(&d.Date, &d.Status, &d.Reason); err != nil {
+			return nil, fmt.Errorf("scan streak day: %w", err)
+		}
+		h.Days = append(h.Days, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate streak days: %w", err)
+	}
+
+	return h, nil
+}
+
+// StreakCalendar раскладывает сохранённые дни по календарю [from, to]: дни без записи
+// между первым днём истории и вчерашним днём — пропуски, остальные — none
+func StreakCalendar(from, to, today, firstDay string, stored []StreakDay) ([]StreakDay, error) {
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return nil, fmt.Errorf("parse from: %w", err)
+	}
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return nil, fmt.Errorf("parse to: %w", err)
+	}
+
+	byDate := make(map[string]StreakDay, len(stored))
+	for _, d := range stored {
+		byDate[d.Date] = d
+	}
+
+	var days []StreakDay
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		if d, ok := byDate[date]; ok {
+			days = append(days, d)
+			continue
+		}
+		status := StreakDayNone
+		if firstDay != "" && date > firstDay && date < today {
+			status = StreakDayMissed
+		}
+		days = append(days, StreakDay{Date: date, Status: status})
+	}
+	return days, nil
+}
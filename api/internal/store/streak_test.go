@@ -0,0 +1,264 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"child-bot/api/internal/domain"
+)
+
+// giveStreakFreezes sets how many streak freezes the child owns
+func giveStreakFreezes(t *testing.T, db *sql.DB, childProfileID string, quantity int) {
+	t.Helper()
+
+	query := `
+		INSERT INTO child_inventory (child_profile_id, item_id, quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (child_profile_id, item_id) DO UPDATE SET quantity = EXCLUDED.quantity
+	`
+	if _, err := db.Exec(query, childProfileID, StreakFreezeItemID, quantity); err != nil {
+		t.Fatalf("failed to give streak freezes: %v", err)
+	}
+}
+
+func getStreakFreezes(t *testing.T, db *sql.DB, childProfileID string) int {
+	t.Helper()
+
+	var quantity int
+	query := `SELECT COALESCE(SUM(quantity), 0) FROM child_inventory WHERE child_profile_id = $1 AND item_id = $2`
+	if err := db.QueryRow(query, childProfileID, StreakFreezeItemID).Scan(&quantity); err != nil {
+		t.Fatalf("failed to get streak freezes: %v", err)
+	}
+	return quantity
+}
+
+func getStreakDayStatus(t *testing.T, db *sql.DB, childProfileID, date string) string {
+	t.Helper()
+
+	var status string
+	query := `SELECT status FROM child_streak_days WHERE child_profile_id = $1 AND day = $2`
+	err := db.QueryRow(query, childProfileID, date).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ""
+	}
+	if err != nil {
+		t.Fatalf("failed to get streak day: %v", err)
+	}
+	return status
+}
+
+func mustRecordStreak(t *testing.T, s *Store, childProfileID string, now time.Time, loc *time.Location) *StreakActivity {
+	t.Helper()
+
+	act, err := s.RecordStreakActivity(context.Background(), childProfileID, now, loc)
+	if err != nil {
+		t.Fatalf("RecordStreakActivity(%s) error = %v", now, err)
+	}
+	return act
+}
+
+func TestRecordStreakActivity_ConsecutiveDays(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	loc, _ := time.LoadLocation("Europe/Moscow")
+
+	child := createTestProfile(t, db, testID("streak_days"), 0)
+	day1 := time.Date(2026, 3, 2, 23, 50, 0, 0, loc)
+
+	if act := mustRecordStreak(t, s, child, day1, loc); act.Streak != 1 {
+		t.Errorf("first activity streak = %d, want 1", act.Streak)
+	}
+	// Через 20 минут — уже следующий день по Москве
+	if act := mustRecordStreak(t, s, child, day1.Add(20*time.Minute), loc); act.Streak != 2 || act.SameDay {
+		t.Errorf("next day streak = %d (same_day=%v), want 2", act.Streak, act.SameDay)
+	}
+	if act := mustRecordStreak(t, s, child, day1.Add(2*time.Hour), loc); act.Streak != 2 || !act.SameDay {
+		t.Errorf("same day streak = %d (same_day=%v), want 2", act.Streak, act.SameDay)
+	}
+
+	if got := getStreakDayStatus(t, db, child, "2026-03-03"); got != StreakDayActive {
+		t.Errorf("day status = %q, want active", got)
+	}
+}
+
+func TestRecordStreakActivity_FreezesCoverGap(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	loc, _ := time.LoadLocation("Europe/Moscow")
+
+	child := createTestProfile(t, db, testID("streak_freeze"), 0)
+	giveStreakFreezes(t, db, child, 3)
+
+	day1 := time.Date(2026, 3, 2, 18, 0, 0, 0, loc)
+	mustRecordStreak(t, s, child, day1, loc)
+	mustRecordStreak(t, s, child, day1.AddDate(0, 0, 1), loc)
+
+	// Пропущены 4 и 5 марта
+	act := mustRecordStreak(t, s, child, day1.AddDate(0, 0, 4), loc)
+	if act.Streak != 3 || act.FreezesUsed != 2 || act.Broken {
+		t.Errorf("after gap: %+v, want streak=3 freezes_used=2", act)
+	}
+	if got := getStreakFreezes(t, db, child); got != 1 {
+		t.Errorf("freezes left = %d, want 1", got)
+	}
+	for _, date := range []string{"2026-03-04", "2026-03-05"} {
+		if got := getStreakDayStatus(t, db, child, date); got != StreakDayFrozen {
+			t.Errorf("%s status = %q, want frozen", date, got)
+		}
+	}
+}
+
+func TestRecordStreakActivity_NotEnoughFreezesKeepsThem(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	loc, _ := time.LoadLocation("Europe/Moscow")
+
+	child := createTestProfile(t, db, testID("streak_broken"), 0)
+	giveStreakFreezes(t, db, child, 1)
+
+	day1 := time.Date(2026, 3, 2, 18, 0, 0, 0, loc)
+	mustRecordStreak(t, s, child, day1, loc)
+	mustRecordStreak(t, s, child, day1.AddDate(0, 0, 1), loc)
+
+	act := mustRecordStreak(t, s, child, day1.AddDate(0, 0, 4), loc)
+	if act.Streak != 1 || !act.Broken || act.FreezesUsed != 0 {
+		t.Errorf("after gap: %+v, want broken streak=1 without using freezes", act)
+	}
+	if got := getStreakFreezes(t, db, child); got != 1 {
+		t.Errorf("freezes left = %d, want 1", got)
+	}
+}
+
+func TestExcuseStreakDay_RecoversBrokenStreak(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+	loc, _ := time.LoadLocation("Europe/Moscow")
+
+	child := createTestProfile(t, db, testID("streak_recover"), 0)
+	giveStreakFreezes(t, db, child, 1)
+
+	day1 := time.Date(2026, 3, 2, 18, 0, 0, 0, loc)
+	mustRecordStreak(t, s, child, day1, loc)
+	mustRecordStreak(t, s, child, day1.AddDate(0, 0, 1), loc)
+	mustRecordStreak(t, s, child, day1.AddDate(0, 0, 2), loc)
+	// Болел 5 и 6 марта, вернулся 7-го: одной заморозки мало, серия оборвалась
+	returned := day1.AddDate(0, 0, 5)
+	if act := mustRecordStreak(t, s, child, returned, loc); !act.Broken {
+		t.Fatalf("expected broken streak, got %+v", act)
+	}
+
+	// Родитель прощает один день: второй закрывает заморозка, серия 3 + 1
+	excuse, err := s.ExcuseStreakDay(ctx, child, "2026-03-05", "Болел", "parent-1", returned, loc)
+	if err != nil {
+		t.Fatalf("ExcuseStreakDay() error = %v", err)
+	}
+	if !excuse.Recovered || excuse.Streak != 4 {
+		t.Errorf("excuse = %+v, want recovered streak=4", excuse)
+	}
+	if got := getStreakFreezes(t, db, child); got != 0 {
+		t.Errorf("freezes left = %d, want 0", got)
+	}
+	if got := getStreakDayStatus(t, db, child, "2026-03-06"); got != StreakDayFrozen {
+		t.Errorf("2026-03-06 status = %q, want frozen", got)
+	}
+
+	// Прощение замороженного дня возвращает заморозку
+	excuse, err = s.ExcuseStreakDay(ctx, child, "2026-03-06", "Болел", "parent-1", returned, loc)
+	if err != nil {
+		t.Fatalf("ExcuseStreakDay() error = %v", err)
+	}
+	if !excuse.FreezeRefunded || excuse.Recovered {
+		t.Errorf("excuse = %+v, want refunded freeze without second recovery", excuse)
+	}
+	if got := getStreakFreezes(t, db, child); got != 1 {
+		t.Errorf("freezes after refund = %d, want 1", got)
+	}
+
+	// Следующий день продолжает восстановленную серию
+	if act := mustRecordStreak(t, s, child, returned.AddDate(0, 0, 1), loc); act.Streak != 5 {
+		t.Errorf("streak after recovery = %d, want 5", act.Streak)
+	}
+}
+
+func TestExcuseStreakDay_Validation(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+	loc, _ := time.LoadLocation("Europe/Moscow")
+
+	child := createTestProfile(t, db, testID("streak_excuse"), 0)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, loc)
+
+	for _, date := range []string{"2026-03-11", "2026-03-01", "10.03.2026"} {
+		if _, err := s.ExcuseStreakDay(ctx, child, date, "", "", now, loc); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("ExcuseStreakDay(%s) error = %v, want ErrInvalidInput", date, err)
+		}
+	}
+
+	mustRecordStreak(t, s, child, now, loc)
+	excuse, err := s.ExcuseStreakDay(ctx, child, "2026-03-10", "", "", now, loc)
+	if err != nil {
+		t.Fatalf("ExcuseStreakDay() error = %v", err)
+	}
+	if excuse.Status != StreakDayActive {
+		t.Errorf("excusing an active day status = %q, want active", excuse.Status)
+	}
+}
+
+func TestClaimAchievementReward_StreakFreeze(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	child := createTestProfile(t, db, testID("freeze_reward"), 0)
+	achievementID := testID("test_freeze")
+	createTestAchievement(t, db, achievementID, "streak_freeze", sql.NullString{}, 2)
+	setProgress(t, db, child, achievementID, true)
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.ClaimAchievementReward(ctx, child, achievementID); err != nil {
+			t.Fatalf("ClaimAchievementReward() error = %v", err)
+		}
+	}
+	if got := getStreakFreezes(t, db, child); got != 2 {
+		t.Errorf("freezes = %d, want 2 (claimed once)", got)
+	}
+}
+
+func TestStreakCalendar(t *testing.T) {
+	stored := []StreakDay{
+		{Date: "2026-03-02", Status: StreakDayActive},
+		{Date: "2026-03-03", Status: StreakDayFrozen},
+		{Date: "2026-03-05", Status: StreakDayExcused, Reason: "Болел"},
+	}
+
+	days, err := StreakCalendar("2026-03-01", "2026-03-07", "2026-03-07", "2026-03-02", stored)
+	if err != nil {
+		t.Fatalf("StreakCalendar() error = %v", err)
+	}
+
+	want := []string{
+		StreakDayNone,    // до первого дня
+		StreakDayActive,  // 02
+		StreakDayFrozen,  // 03
+		StreakDayMissed,  // 04
+		StreakDayExcused, // 05
+		StreakDayMissed,  // 06
+		StreakDayNone,    // сегодня, ещё не занимался
+	}
+	if len(days) != len(want) {
+		t.Fatalf("len(days) = %d, want %d", len(days), len(want))
+	}
+	for i, d := range days {
+		if d.Status != want[i] {
+			t.Errorf("%s status = %q, want %q", d.Date, d.Status, want[i])
+		}
+	}
+	if days[4].Reason != "Болел" {
+		t.Errorf("excused reason = %q", days[4].Reason)
+	}
+}
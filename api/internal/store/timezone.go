@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"child-bot/api/internal/domain"
+)
+
+// GetChildTimezone возвращает IANA-часовой пояс профиля ребёнка
+func (s *Store) GetChildTimezone(ctx context.Context, childProfileID string) (string, error) {
+	var tz string
+	err := s.DB.QueryRowContext(ctx,
+		`SELECT timezone FROM child_profiles WHERE id = $1`, childProfileID,
+	).Scan(&tz)
+	if err == sql.ErrNoRows {
+		return "", domain.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("get child timezone: %w", err)
+	}
+	return tz, nil
+}
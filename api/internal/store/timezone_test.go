@@ -0,0 +1,41 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"child-bot/api/internal/domain"
+)
+
+func TestGetChildTimezone(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	child := createTestProfile(t, db, testID("tz"), 0)
+
+	tz, err := s.GetChildTimezone(ctx, child)
+	if err != nil {
+		t.Fatalf("GetChildTimezone() error = %v", err)
+	}
+	if tz != "Europe/Moscow" {
+		t.Errorf("default timezone = %q, want Europe/Moscow", tz)
+	}
+
+	if _, err := db.Exec(`UPDATE child_profiles SET timezone = 'Asia/Vladivostok' WHERE id = $1`, child); err != nil {
+		t.Fatalf("failed to update timezone: %v", err)
+	}
+	tz, err = s.GetChildTimezone(ctx, child)
+	if err != nil {
+		t.Fatalf("GetChildTimezone() error = %v", err)
+	}
+	if tz != "Asia/Vladivostok" {
+		t.Errorf("timezone = %q, want Asia/Vladivostok", tz)
+	}
+
+	_, err = s.GetChildTimezone(ctx, "00000000-0000-0000-0000-000000000000")
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetChildTimezone(unknown) error = %v, want ErrNotFound", err)
+	}
+}
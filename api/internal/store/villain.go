@@ -3,9 +3,12 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"child-bot/api/internal/damage"
+
 	"github.com/google/uuid"
 )
 
@@ -28,6 +31,7 @@ type VillainRow struct {
 	MaxHP                int
 	Level                int
 	DamagePerCorrectTask int
+	DamageFormula        []byte // JSONB - может быть NULL (формула по умолчанию)
 	UnlockOrder          int
 	RewardCoins          int
 	RewardAchievementID  sql.NullString
@@ -52,12 +56,15 @@ type VillainBattleRow struct {
 
 // DamageEventRow запись урона
 type DamageEventRow struct {
-	ID        int64
-	BattleID  int64
-	AttemptID sql.NullString
-	Damage    int
-	TaskType  string
-	CreatedAt time.Time
+	ID         int64
+	BattleID   int64
+	AttemptID  sql.NullString
+	Damage     int
+	BaseDamage int
+	IsCritical bool
+	Bonuses    []byte // JSONB: [{code, label, damage}]
+	TaskType   string
+	CreatedAt  time.Time
 }
 
 // GetActiveVillainBattle получает активную битву со злодеем
@@ -68,7 +75,7 @@ func (s *VillainStore) GetActiveVillainBattle(ctx context.Context, childProfileI
 			vb.current_hp, vb.total_damage_dealt, vb.correct_tasks_count,
 			vb.rewards_claimed, vb.started_at, vb.defeated_at, vb.updated_at,
 			v.id, v.name, v.description, v.image_url, v.max_hp, v.level,
-			v.damage_per_correct_task, v.unlock_order, v.reward_coins,
+			v.damage_per_correct_task, v.damage_formula, v.unlock_order, v.reward_coins,
 			v.reward_achievement_id, v.is_boss, v.created_at
 		FROM villain_battles vb
 		JOIN villains v ON vb.villain_id = v.id
@@ -86,7 +93,7 @@ func (s *VillainStore) GetActiveVillainBattle(ctx context.Context, childProfileI
 		&battle.CurrentHP, &battle.TotalDamageDealt, &battle.CorrectTasksCount,
 		&battle.RewardsClaimed, &battle.StartedAt, &battle.DefeatedAt, &battle.UpdatedAt,
 		&villain.ID, &villain.Name, &villain.Description, &villain.ImageURL,
-		&villain.MaxHP, &villain.Level, &villain.DamagePerCorrectTask,
+		&villain.MaxHP, &villain.Level, &villain.DamagePerCorrectTask, &villain.DamageFormula,
 		&villain.UnlockOrder, &villain.RewardCoins, &villain.RewardAchievementID,
 		&villain.IsBoss, &villain.CreatedAt,
 	)
@@ -101,14 +108,23 @@ func (s *VillainStore) GetActiveVillainBattle(ctx context.Context, childProfileI
 	return &battle, &villain, nil
 }
 
-// RecordDamageEvent записывает событие нанесения урона
-func (s *VillainStore) RecordDamageEvent(ctx context.Context, battleID int64, attemptID uuid.UUID, damage int, taskType string) error {
+// RecordDamageEvent записывает событие нанесения урона вместе с разбивкой (база, крит, бонусы)
+func (s *VillainStore) RecordDamageEvent(ctx context.Context, battleID int64, attemptID uuid.UUID, taskType string, hit damage.Breakdown) error {
+	bonuses := hit.Bonuses
+	if bonuses == nil {
+		bonuses = []damage.Bonus{}
+	}
+	bonusesJSON, err := json.Marshal(bonuses)
+	if err != nil {
+		return fmt.Errorf("failed to marshal damage bonuses: %w", err)
+	}
+
 	query := `
-		INSERT INTO damage_events (battle_id, attempt_id, damage, task_type)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO damage_events (battle_id, attempt_id, damage, task_type, base_damage, is_critical, bonuses)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
-	_, err := s.db.ExecContext(ctx, query, battleID, attemptID, damage, taskType)
+	_, err = s.db.ExecContext(ctx, query, battleID, attemptID, hit.Total, taskType, hit.Base, hit.IsCritical, bonusesJSON)
 	if err != nil {
 		return fmt.Errorf("failed to record damage event: %w", err)
 	}
@@ -116,6 +132,69 @@ func (s *VillainStore) RecordDamageEvent(ctx context.Context, battleID int64, at
 	return nil
 }
 
+// ApplyDamage в одной транзакции тратит выбранный ребёнком усилитель (powerUpID, пусто — без него),
+// считает урон по формуле, записывает событие урона и уменьшает HP битвы.
+// Если усилителя уже нет, урон обычный. Возвращает разбивку урона и новое HP.
+func (s *VillainStore) ApplyDamage(ctx context.Context, childProfileID string, battleID int64, attemptID uuid.UUID, taskType string, formula damage.Formula, task damage.Task, powerUpID string) (damage.Breakdown, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return damage.Breakdown{}, 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if powerUpID != "" {
+		raw, err := consumePowerUp(ctx, tx, childProfileID, powerUpID)
+		if err != nil {
+			return damage.Breakdown{}, 0, err
+		}
+		if raw != nil {
+			var effect struct {
+				DamageMultiplier float64 `json:"damage_multiplier"`
+			}
+			if err := json.Unmarshal(raw, &effect); err != nil {
+				return damage.Breakdown{}, 0, fmt.Errorf("invalid power-up effect %s: %w", powerUpID, err)
+			}
+			task.Multiplier = effect.DamageMultiplier
+		}
+	}
+	hit := damage.Calculate(formula, task)
+
+	bonuses := hit.Bonuses
+	if bonuses == nil {
+		bonuses = []damage.Bonus{}
+	}
+	bonusesJSON, err := json.Marshal(bonuses)
+	if err != nil {
+		return damage.Breakdown{}, 0, fmt.Errorf("failed to marshal damage bonuses: %w", err)
+	}
+	eventQuery := `
+		INSERT INTO damage_events (battle_id, attempt_id, damage, task_type, base_damage, is_critical, bonuses)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	if _, err := tx.ExecContext(ctx, eventQuery, battleID, attemptID, hit.Total, taskType, hit.Base, hit.IsCritical, bonusesJSON); err != nil {
+		return damage.Breakdown{}, 0, fmt.Errorf("failed to record damage event: %w", err)
+	}
+
+	var newHP int
+	progressQuery := `
+		UPDATE villain_battles
+		SET current_hp = GREATEST(current_hp - $1, 0),
+		    total_damage_dealt = total_damage_dealt + $1,
+		    correct_tasks_count = correct_tasks_count + 1,
+		    updated_at = NOW()
+		WHERE id = $2
+		RETURNING current_hp
+	`
+	if err := tx.QueryRowContext(ctx, progressQuery, hit.Total, battleID).Scan(&newHP); err != nil {
+		return damage.Breakdown{}, 0, fmt.Errorf("failed to update battle progress: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return damage.Breakdown{}, 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return hit, newHP, nil
+}
+
 // UpdateBattleProgress обновляет прогресс битвы (HP, урон, счётчик задач)
 func (s *VillainStore) UpdateBattleProgress(ctx context.Context, battleID int64, newHP int, damageDealt int) error {
 	query := `
@@ -174,7 +253,7 @@ func (s *VillainStore) MarkBattleDefeated(ctx context.Context, battleID int64) e
 func (s *VillainStore) GetVillainByOrder(ctx context.Context, unlockOrder int) (*VillainRow, error) {
 	query := `
 		SELECT id, name, description, image_url, max_hp, level,
-		       damage_per_correct_task, unlock_order, reward_coins, reward_achievement_id, is_boss
+		       damage_per_correct_task, damage_formula, unlock_order, reward_coins, reward_achievement_id, is_boss
 		FROM villains
 		WHERE unlock_order = $1
 		LIMIT 1
@@ -189,6 +268,7 @@ func (s *VillainStore) GetVillainByOrder(ctx context.Context, unlockOrder int) (
 		&villain.MaxHP,
 		&villain.Level,
 		&villain.DamagePerCorrectTask,
+		&villain.DamageFormula,
 		&villain.UnlockOrder,
 		&villain.RewardCoins,
 		&villain.RewardAchievementID,
@@ -229,7 +309,7 @@ func (s *VillainStore) GetVillainBattleByVillainID(ctx context.Context, childPro
 			vb.current_hp, vb.total_damage_dealt, vb.correct_tasks_count,
 			vb.rewards_claimed, vb.started_at, vb.defeated_at, vb.updated_at,
 			v.id, v.name, v.description, v.image_url, v.max_hp, v.level,
-			v.damage_per_correct_task, v.unlock_order, v.reward_coins,
+			v.damage_per_correct_task, v.damage_formula, v.unlock_order, v.reward_coins,
 			v.reward_achievement_id, v.is_boss, v.created_at
 		FROM villain_battles vb
 		JOIN villains v ON vb.villain_id = v.id
@@ -247,7 +327,7 @@ func (s *VillainStore) GetVillainBattleByVillainID(ctx context.Context, childPro
 		&battle.CurrentHP, &battle.TotalDamageDealt, &battle.CorrectTasksCount,
 		&battle.RewardsClaimed, &battle.StartedAt, &battle.DefeatedAt, &battle.UpdatedAt,
 		&villain.ID, &villain.Name, &villain.Description, &villain.ImageURL,
-		&villain.MaxHP, &villain.Level, &villain.DamagePerCorrectTask,
+		&villain.MaxHP, &villain.Level, &villain.DamagePerCorrectTask, &villain.DamageFormula,
 		&villain.UnlockOrder, &villain.RewardCoins, &villain.RewardAchievementID,
 		&villain.IsBoss, &villain.CreatedAt,
 	)
@@ -266,7 +346,7 @@ func (s *VillainStore) GetVillainBattleByVillainID(ctx context.Context, childPro
 func (s *VillainStore) GetVillainByID(ctx context.Context, villainID string) (*VillainRow, error) {
 	query := `
 		SELECT id, name, description, image_url, max_hp, level,
-		       damage_per_correct_task, unlock_order, reward_coins, reward_achievement_id, is_boss
+		       damage_per_correct_task, damage_formula, unlock_order, reward_coins, reward_achievement_id, is_boss
 		FROM villains
 		WHERE id = $1
 		LIMIT 1
@@ -281,6 +361,7 @@ func (s *VillainStore) GetVillainByID(ctx context.Context, villainID string) (*V
 		&villain.MaxHP,
 		&villain.Level,
 		&villain.DamagePerCorrectTask,
+		&villain.DamageFormula,
 		&villain.UnlockOrder,
 		&villain.RewardCoins,
 		&villain.RewardAchievementID,
@@ -300,7 +381,7 @@ func (s *VillainStore) GetVillainByID(ctx context.Context, villainID string) (*V
 // GetDamageEvents получает последние события урона для битвы
 func (s *VillainStore) GetDamageEvents(ctx context.Context, battleID int64, limit int) ([]DamageEventRow, error) {
 	query := `
-		SELECT id, battle_id, attempt_id, damage, task_type, created_at
+		SELECT id, battle_id, attempt_id, damage, base_damage, is_critical, bonuses, task_type, created_at
 		FROM damage_events
 		WHERE battle_id = $1
 		ORDER BY created_at DESC
@@ -316,7 +397,11 @@ func (s *VillainStore) GetDamageEvents(ctx context.Context, battleID int64, limi
 	var events []DamageEventRow
 	for rows.Next() {
 		var event DamageEventRow
-		if err := rows.Scan(&event.ID, &event.BattleID, &event.AttemptID, &event.Damage, &event.TaskType, &event.CreatedAt); err != nil {
+		if err := rows.Scan(
+			&event.ID, &event.BattleID, &event.AttemptID, &event.Damage,
+			&event.BaseDamage, &event.IsCritical, &event.Bonuses,
+			&event.TaskType, &event.CreatedAt,
+		); err != nil {
 			return nil, fmt.Errorf("failed to scan damage event: %w", err)
 		}
 		events = append(events, event)
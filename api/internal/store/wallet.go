@@ -0,0 +1,254 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"child-bot/api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Валюты журнала
+const (
+	CurrencyCoins = "coins"
+	CurrencyXP    = "xp"
+)
+
+// Источники начислений и списаний
+const (
+	WalletSourceAttempt        = "attempt"
+	WalletSourceHint           = "hint"
+	WalletSourceAchievement    = "achievement"
+	WalletSourceVillainBattle  = "villain_battle"
+	WalletSourceLevelUp        = "level_up"
+	WalletSourceDailyLogin     = "daily_login"
+	WalletSourceReferral       = "referral"
+	WalletSourcePurchase       = "purchase"
+	WalletSourceOpeningBalance = "opening_balance"
+	WalletSourceAdjustment     = "adjustment"
+	WalletSourceMission        = "mission"
+	WalletSourceFamilyQuest    = "family_quest"
+	WalletSourcePractice       = "practice"
+	WalletSourceFamilyReward   = "family_reward"
+)
+
+// WalletSource описывает событие, за которое меняется баланс
+type WalletSource struct {
+	Type        string // attempt, achievement, villain_battle, ...
+	ID          string // id попытки, достижения, битвы и т.п.
+	Key         string // ключ идемпотентности без префикса валюты; по умолчанию Type:ID
+	Description string
+}
+
+// idempotencyKey ключ записи журнала: <валюта>:<ключ события>
+func (src WalletSource) idempotencyKey(currency string) string {
+	key := src.Key
+	if key == "" && src.ID != "" {
+		key = src.Type + ":" + src.ID
+	}
+	if key == "" {
+		// Событие без идентификатора — каждая операция уникальна
+		key = src.Type + ":" + uuid.NewString()
+	}
+	return currency + ":" + key
+}
+
+// WalletTransaction запись журнала монет и XP
+type WalletTransaction struct {
+	ID             int64
+	ChildProfileID string
+	Currency       string
+	Amount         int
+	BalanceAfter   int
+	Source         string
+	SourceID       sql.NullString
+	IdempotencyKey string
+	Description    sql.NullString
+	CreatedAt      time.Time
+}
+
+// WalletMismatch расхождение между журналом и материализованным балансом
+type WalletMismatch struct {
+	ChildProfileID string
+	Currency       string
+	LedgerTotal    int
+	ProfileBalance int
+}
+
+// applyWalletEntryTx добавляет запись в журнал и меняет материализованный баланс в child_profiles.
+// Повтор с тем же ключом ничего не меняет и возвращает applied = false и текущий баланс.
+func applyWalletEntryTx(ctx context.Context, tx *sql.Tx, childProfileID, currency string, amount int, src WalletSource) (bool, int, error) {
+	column := "coins_balance"
+	if currency == CurrencyXP {
+		column = "xp_total"
+	}
+
+	// Блокируем профиль: записи одного ребёнка применяются последовательно
+	var balance int
+	query := `SELECT COALESCE(` + column + `, 0) FROM child_profiles WHERE id = $1 FOR UPDATE`
+	if err := tx.QueryRowContext(ctx, query, childProfileID).Scan(&balance); err != nil {
+		return false, 0, fmt.Errorf("get %s balance: %w", currency, err)
+	}
+
+	if amount == 0 {
+		return false, balance, nil
+	}
+
+	// Списание не может увести баланс в минус
+	if balance+amount < 0 {
+		return false, balance, domain.ErrInsufficientFunds
+	}
+
+	insertQuery := `
+		INSERT INTO wallet_transactions (child_profile_id, currency, amount, balance_after,
+		                                 source, source_id, idempotency_key, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (child_profile_id, idempotency_key) DO NOTHING
+	`
+	result, err := tx.ExecContext(ctx, insertQuery,
+		childProfileID, currency, amount, balance+amount,
+		src.Type,
+		sql.NullString{String: src.ID, Valid: src.ID != ""},
+		src.idempotencyKey(currency),
+		sql.NullString{String: src.Description, Valid: src.Description != ""},
+	)
+	if err != nil {
+		return false, 0, fmt.Errorf("insert wallet transaction: %w", err)
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		log.Printf("[Store] Wallet entry already applied: child=%s, key=%s", childProfileID, src.idempotencyKey(currency))
+		return false, balance, nil
+	}
+
+	updateQuery := `
+		UPDATE child_profiles
+		SET ` + column + ` = COALESCE(` + column + `, 0) + $1,
+		    updated_at = NOW()
+		WHERE id = $2
+	`
+	if _, err := tx.ExecContext(ctx, updateQuery, amount, childProfileID); err != nil {
+		return false, 0, fmt.Errorf("update %s balance: %w", currency, err)
+	}
+
+	return true, balance + amount, nil
+}
+
+// AddCoins начисляет (amount > 0) или списывает (amount < 0) монеты через журнал.
+// Возвращает false, если событие с таким ключом уже было учтено.
+func (s *Store) AddCoins(ctx context.Context, childProfileID string, amount int, src WalletSource) (bool, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	applied, balance, err := applyWalletEntryTx(ctx, tx, childProfileID, CurrencyCoins, amount, src)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+
+	if applied {
+		log.Printf("[Store] 🪙 Coins updated: child=%s, amount=%d, balance=%d, source=%s/%s",
+			childProfileID, amount, balance, src.Type, src.ID)
+	}
+
+	return applied, nil
+}
+
+// ListWalletTransactions возвращает записи журнала ребёнка (новые первыми) и их общее число.
+// Пустая currency — обе валюты.
+func (s *Store) ListWalletTransactions(ctx context.Context, childProfileID, currency string, limit, offset int) ([]WalletTransaction, int, error) {
+	var total int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM wallet_transactions
+		WHERE child_profile_id = $1 AND ($2 = '' OR currency = $2)
+	`
+	if err := s.DB.QueryRowContext(ctx, countQuery, childProfileID, currency).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count wallet transactions: %w", err)
+	}
+
+	query := `
+		SELECT id, child_profile_id, currency, amount, balance_after,
+		       source, source_id, idempotency_key, description, created_at
+		FROM wallet_transactions
+		WHERE child_profile_id = $1 AND ($2 = '' OR currency = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := s.DB.QueryContext(ctx, query, childProfileID, currency, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list wallet transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []WalletTransaction
+	for rows.Next() {
+		var t WalletTransaction
+		if err := rows.Scan(
+			&t.ID, &t.ChildProfileID, &t.Currency, &t.Amount, &t.BalanceAfter,
+			&t.Source, &t.SourceID, &t.IdempotencyKey, &t.Description, &t.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan wallet transaction: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate wallet transactions: %w", err)
+	}
+
+	return transactions, total, nil
+}
+
+// ReconcileWallets сравнивает суммы журнала с балансами в child_profiles
+// и возвращает профили, где они расходятся
+func (s *Store) ReconcileWallets(ctx context.Context) ([]WalletMismatch, error) {
+	query := `
+		WITH ledger AS (
+			SELECT child_profile_id,
+			       SUM(amount) FILTER (WHERE currency = 'coins') AS coins,
+			       SUM(amount) FILTER (WHERE currency = 'xp') AS xp
+			FROM wallet_transactions
+			GROUP BY child_profile_id
+		),
+		balances AS (
+			SELECT cp.id,
+			       COALESCE(l.coins, 0) AS ledger_coins, COALESCE(cp.coins_balance, 0) AS profile_coins,
+			       COALESCE(l.xp, 0) AS ledger_xp, COALESCE(cp.xp_total, 0) AS profile_xp
+			FROM child_profiles cp
+			LEFT JOIN ledger l ON l.child_profile_id = cp.id
+		)
+		SELECT id, 'coins', ledger_coins, profile_coins FROM balances WHERE ledger_coins <> profile_coins
+		UNION ALL
+		SELECT id, 'xp', ledger_xp, profile_xp FROM balances WHERE ledger_xp <> profile_xp
+		ORDER BY 1, 2
+	`
+	rows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("reconcile wallets: %w", err)
+	}
+	defer rows.Close()
+
+	var mismatches []WalletMismatch
+	for rows.Next() {
+		var m WalletMismatch
+		if err := rows.Scan(&m.ChildProfileID, &m.Currency, &m.LedgerTotal, &m.ProfileBalance); err != nil {
+			return nil, fmt.Errorf("scan wallet mismatch: %w", err)
+		}
+		mismatches = append(mismatches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate wallet mismatches: %w", err)
+	}
+
+	return mismatches, nil
+}
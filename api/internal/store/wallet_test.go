@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"child-bot/api/internal/level"
+)
+
+func TestAddCoins_Idempotent(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("wallet_coins"), 0)
+	src := WalletSource{Type: WalletSourceAttempt, ID: "attempt-1", Key: "attempt_correct:attempt-1"}
+
+	for i := 0; i < 3; i++ {
+		applied, err := s.AddCoins(ctx, childID, 5, src)
+		if err != nil {
+			t.Fatalf("AddCoins() error = %v", err)
+		}
+		if applied != (i == 0) {
+			t.Errorf("call %d: applied = %v, want %v", i, applied, i == 0)
+		}
+	}
+
+	coins, _ := getBalances(t, db, childID)
+	if coins != 5 {
+		t.Errorf("coins_balance = %d, want 5", coins)
+	}
+
+	txs, total, err := s.ListWalletTransactions(ctx, childID, CurrencyCoins, 10, 0)
+	if err != nil {
+		t.Fatalf("ListWalletTransactions() error = %v", err)
+	}
+	if total != 1 || len(txs) != 1 {
+		t.Fatalf("transactions = %d (total %d), want 1", len(txs), total)
+	}
+	if txs[0].Source != WalletSourceAttempt || txs[0].BalanceAfter != 5 || txs[0].IdempotencyKey != "coins:attempt_correct:attempt-1" {
+		t.Errorf("unexpected transaction: %+v", txs[0])
+	}
+}
+
+func TestAddXP_LevelUpRecordedInLedger(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("wallet_xp"), 0)
+	src := WalletSource{Type: WalletSourceVillainBattle, ID: "42"}
+
+	// Для перехода с 1 на 2 уровень нужно XPForLevel(1) = 100 XP
+	newLevel, leveledUp, err := s.AddXP(ctx, childID, s.XP.XPForLevel(1), s.XP, src)
+	if err != nil {
+		t.Fatalf("AddXP() error = %v", err)
+	}
+	if !leveledUp || newLevel != 2 {
+		t.Errorf("AddXP() = level %d, leveledUp %v; want 2, true", newLevel, leveledUp)
+	}
+
+	// Повтор той же победы не начисляет ни XP, ни монеты за уровень
+	if _, leveledUp, err := s.AddXP(ctx, childID, s.XP.XPForLevel(1), s.XP, src); err != nil || leveledUp {
+		t.Errorf("repeated AddXP() leveledUp = %v, err = %v; want false, nil", leveledUp, err)
+	}
+
+	// Монеты за второй уровень берутся из таблицы уровней
+	levelCoins := 0
+	for _, r := range s.XP.Levels.Level(2).Rewards {
+		if r.Type == level.RewardCoins {
+			levelCoins += r.Amount
+		}
+	}
+	coins, xp := getBalances(t, db, childID)
+	if xp != s.XP.XPForLevel(1) || coins != levelCoins {
+		t.Errorf("balances = %d coins, %d xp; want %d, %d", coins, xp, levelCoins, s.XP.XPForLevel(1))
+	}
+
+	txs, _, err := s.ListWalletTransactions(ctx, childID, "", 10, 0)
+	if err != nil {
+		t.Fatalf("ListWalletTransactions() error = %v", err)
+	}
+	sources := map[string]bool{}
+	for _, tx := range txs {
+		sources[tx.Currency+":"+tx.Source] = true
+	}
+	if len(txs) != 2 || !sources["xp:villain_battle"] || !sources["coins:level_up"] {
+		t.Errorf("unexpected ledger entries: %+v", txs)
+	}
+}
+
+func TestAutoClaimCoins_RecordedInLedger(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	childID := createTestProfile(t, db, testID("wallet_auto"), 0)
+	achievementID := testID("test_wallet_auto")
+	createTestAchievement(t, db, achievementID, "coins", sql.NullString{}, 25)
+	setProgress(t, db, childID, achievementID, true)
+
+	// Повторная ручная попытка с тем же ключом не начисляет второй раз
+	if _, err := s.ClaimAchievementReward(ctx, childID, achievementID); err != nil {
+		t.Fatalf("ClaimAchievementReward() error = %v", err)
+	}
+	if _, err := s.AddCoins(ctx, childID, 25, WalletSource{Type: WalletSourceAchievement, ID: achievementID}); err != nil {
+		t.Fatalf("AddCoins() error = %v", err)
+	}
+
+	coins, _ := getBalances(t, db, childID)
+	if coins != 25 {
+		t.Errorf("coins_balance = %d, want 25", coins)
+	}
+
+	txs, total, err := s.ListWalletTransactions(ctx, childID, CurrencyCoins, 10, 0)
+	if err != nil {
+		t.Fatalf("ListWalletTransactions() error = %v", err)
+	}
+	if total != 1 || txs[0].Source != WalletSourceAchievement || txs[0].SourceID.String != achievementID {
+		t.Errorf("unexpected ledger entries: total=%d, %+v", total, txs)
+	}
+}
+
+func TestAutoClaimMilestone_RecordedInLedger(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	var milestoneID int64
+	var reward int
+	err := db.QueryRow(`SELECT id, reward_coins FROM referral_milestones ORDER BY friends_count LIMIT 1`).Scan(&milestoneID, &reward)
+	if err == sql.ErrNoRows {
+		t.Skip("no referral milestones seeded")
+	}
+	if err != nil {
+		t.Fatalf("get milestone: %v", err)
+	}
+
+	childID := createTestProfile(t, db, testID("wallet_milestone"), 0)
+	if _, err := db.Exec(`INSERT INTO child_referral_milestones (child_profile_id, milestone_id) VALUES ($1, $2)`, childID, milestoneID); err != nil {
+		t.Fatalf("insert milestone: %v", err)
+	}
+
+	if coins, _ := getBalances(t, db, childID); coins != reward {
+		t.Errorf("coins_balance = %d, want %d", coins, reward)
+	}
+	txs, total, err := s.ListWalletTransactions(ctx, childID, CurrencyCoins, 10, 0)
+	if err != nil {
+		t.Fatalf("ListWalletTransactions() error = %v", err)
+	}
+	if total != 1 || txs[0].Source != WalletSourceReferral || txs[0].Amount != reward {
+		t.Errorf("unexpected ledger entries: total=%d, %+v", total, txs)
+	}
+}
+
+func TestReconcileWallets(t *testing.T) {
+	db := setupTestDB(t)
+	s := NewStore(db)
+	ctx := context.Background()
+
+	consistent := createTestProfile(t, db, testID("wallet_ok"), 0)
+	if _, err := s.AddCoins(ctx, consistent, 10, WalletSource{Type: WalletSourceAdjustment, ID: "test"}); err != nil {
+		t.Fatalf("AddCoins() error = %v", err)
+	}
+
+	// Баланс изменён в обход журнала
+	drifted := createTestProfile(t, db, testID("wallet_drift"), 7)
+
+	mismatches, err := s.ReconcileWallets(ctx)
+	if err != nil {
+		t.Fatalf("ReconcileWallets() error = %v", err)
+	}
+
+	found := false
+	for _, m := range mismatches {
+		if m.ChildProfileID == consistent {
+			t.Errorf("consistent profile reported as mismatch: %+v", m)
+		}
+		if m.ChildProfileID == drifted && m.Currency == CurrencyCoins {
+			found = true
+			if m.LedgerTotal != 0 || m.ProfileBalance != 7 {
+				t.Errorf("mismatch = %+v, want ledger 0, profile 7", m)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected drifted profile in mismatches")
+	}
+}
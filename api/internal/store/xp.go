@@ -2,99 +2,93 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
+
+	"child-bot/api/internal/level"
 )
 
 // XPConfig конфигурация системы XP
 type XPConfig struct {
-	LevelUpCoinsReward int // Константные монеты за повышение уровня
+	Levels *level.Table // пороги уровней и награды за них
 }
 
-// DefaultXPConfig стандартная конфигурация
+// DefaultXPConfig стандартная конфигурация: встроенная таблица уровней
 var DefaultXPConfig = XPConfig{
-	LevelUpCoinsReward: 100, // 100 монет за каждый уровень
+	Levels: level.MustDefault(),
 }
 
-// AddXP добавляет XP пользователю и проверяет повышение уровня
+// AddXP добавляет XP пользователю через журнал и проверяет повышение уровня.
+// Повтор события с тем же ключом (src) XP не начисляет.
 // Возвращает: (новый уровень, был ли повышен уровень, ошибка)
-func (s *Store) AddXP(ctx context.Context, childProfileID string, xpAmount int, config XPConfig) (int, bool, error) {
+func (s *Store) AddXP(ctx context.Context, childProfileID string, xpAmount int, config XPConfig, src WalletSource) (int, bool, error) {
 	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, false, fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback()
 
+	newLevel, leveledUp, err := addXPTx(ctx, tx, childProfileID, xpAmount, config, src)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, false, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return newLevel, leveledUp, nil
+}
+
+// addXPTx начисляет XP и награды пройденных уровней внутри переданной транзакции
+func addXPTx(ctx context.Context, tx *sql.Tx, childProfileID string, xpAmount int, config XPConfig, src WalletSource) (int, bool, error) {
 	// Получаем текущие XP и уровень
 	var currentXP, currentLevel int
-	query := `SELECT COALESCE(xp_total, 0), COALESCE(level, 1) FROM child_profiles WHERE id = $1`
-	err = tx.QueryRowContext(ctx, query, childProfileID).Scan(&currentXP, &currentLevel)
+	query := `SELECT COALESCE(xp_total, 0), COALESCE(level, 1) FROM child_profiles WHERE id = $1 FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, childProfileID).Scan(&currentXP, &currentLevel)
 	if err != nil {
 		return 0, false, fmt.Errorf("get current xp and level: %w", err)
 	}
 
-	newXP := currentXP + xpAmount
-	newLevel := currentLevel
-	leveledUp := false
-
-	// Проверяем повышение уровня
-	for {
-		xpNeeded := XPForLevel(newLevel)
-		if newXP < xpNeeded {
-			break
-		}
+	applied, newXP, err := applyWalletEntryTx(ctx, tx, childProfileID, CurrencyXP, xpAmount, src)
+	if err != nil {
+		return 0, false, err
+	}
+	if !applied {
+		return currentLevel, false, nil
+	}
 
-		// Повышаем уровень
-		newLevel++
-		leveledUp = true
-		log.Printf("[Store] 🎉 Level up! child=%s, level %d -> %d (XP: %d)",
-			childProfileID, currentLevel, newLevel, newXP)
+	newLevel := config.Levels.LevelFor(newXP)
+	if newLevel <= currentLevel {
+		log.Printf("[Store] ✅ XP updated: child=%s, XP: %d -> %d, level: %d",
+			childProfileID, currentXP, newXP, currentLevel)
+		return currentLevel, false, nil
 	}
 
-	// Обновляем XP и уровень
+	// Обновляем уровень (XP уже обновлён записью журнала)
 	updateQuery := `
 		UPDATE child_profiles
-		SET xp_total = $1,
-		    level = $2,
+		SET level = $1,
 		    updated_at = NOW()
-		WHERE id = $3
+		WHERE id = $2
 	`
-	_, err = tx.ExecContext(ctx, updateQuery, newXP, newLevel, childProfileID)
+	_, err = tx.ExecContext(ctx, updateQuery, newLevel, childProfileID)
 	if err != nil {
-		return 0, false, fmt.Errorf("update xp and level: %w", err)
+		return 0, false, fmt.Errorf("update level: %w", err)
 	}
 
-	// Если был повышен уровень, начисляем бонусные монеты
-	if leveledUp {
-		coinsReward := config.LevelUpCoinsReward
-		coinsQuery := `
-			UPDATE child_profiles
-			SET coins_balance = coins_balance + $1,
-			    updated_at = NOW()
-			WHERE id = $2
-		`
-		_, err = tx.ExecContext(ctx, coinsQuery, coinsReward, childProfileID)
-		if err != nil {
-			return 0, false, fmt.Errorf("add level up coins: %w", err)
+	// Награды каждого пройденного уровня по таблице: запись перехода не даёт выдать их дважды
+	for l := currentLevel + 1; l <= newLevel; l++ {
+		if _, err := grantLevelRewardsTx(ctx, tx, childProfileID, config.Levels.Level(l), false); err != nil {
+			return 0, false, err
 		}
-
-		log.Printf("[Store] 🪙 Level up reward: child=%s, level=%d, coins=%d",
-			childProfileID, newLevel, coinsReward)
-	}
-
-	if err := tx.Commit(); err != nil {
-		return 0, false, fmt.Errorf("commit tx: %w", err)
 	}
 
-	if leveledUp {
-		log.Printf("[Store] ✅ XP updated: child=%s, XP: %d -> %d, level: %d -> %d, coins_reward=%d",
-			childProfileID, currentXP, newXP, currentLevel, newLevel, config.LevelUpCoinsReward)
-	} else {
-		log.Printf("[Store] ✅ XP updated: child=%s, XP: %d -> %d, level: %d",
-			childProfileID, currentXP, newXP, currentLevel)
-	}
+	log.Printf("[Store] 🎉 Level up! child=%s, XP: %d -> %d, level: %d -> %d",
+		childProfileID, currentXP, newXP, currentLevel, newLevel)
 
-	return newLevel, leveledUp, nil
+	return newLevel, true, nil
 }
 
 // GetXPAndLevel получает текущие XP и уровень пользователя
@@ -108,17 +102,17 @@ func (s *Store) GetXPAndLevel(ctx context.Context, childProfileID string) (int,
 	return xpTotal, level, nil
 }
 
-// XPForLevel рассчитывает сколько XP нужно для следующего уровня
-// Формула: 50 × level² + 50 × level
-func XPForLevel(level int) int {
-	return 50*level*level + 50*level
+// XPForLevel рассчитывает сколько всего XP нужно для перехода на следующий уровень
+// по таблице уровней (встроенная повторяет прежнюю формулу 50 × level² + 50 × level)
+func (c XPConfig) XPForLevel(level int) int {
+	return c.Levels.XPForLevel(level)
 }
 
 // XPProgress рассчитывает прогресс до следующего уровня
 // Возвращает: (текущий XP в уровне, XP нужно для уровня)
-func XPProgress(xpTotal int, level int) (int, int) {
-	xpNeeded := XPForLevel(level)
-	currentLevelXP := xpTotal - XPForLevel(level-1)
+func (c XPConfig) XPProgress(xpTotal int, level int) (int, int) {
+	xpNeeded := c.XPForLevel(level)
+	currentLevelXP := xpTotal - c.XPForLevel(level-1)
 	if currentLevelXP < 0 {
 		currentLevelXP = 0
 	}
@@ -0,0 +1,114 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"child-bot/api/internal/domain"
+	llmtypes "child-bot/api/internal/llm/types"
+	"child-bot/api/internal/service"
+)
+
+// questionPrefixes начала фраз, по которым текст в режиме подсказок считается вопросом, а не решением
+var questionPrefixes = []string{"почему", "зачем", "как ", "что ", "а почему", "а как", "а что", "а зачем", "не понимаю", "не понял", "объясни"}
+
+// looksLikeQuestion сообщает, что текст — вопрос по задаче, а не ответ ученика
+func looksLikeQuestion(text string) bool {
+	s := strings.ToLower(strings.TrimSpace(text))
+	if strings.HasSuffix(s, "?") {
+		return true
+	}
+	for _, p := range questionPrefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleDialogMessage отвечает на вопрос ребёнка в рамках активной сессии подсказок
+func (r *Router) handleDialogMessage(ctx context.Context, chatID int64, text string) {
+	v, ok := hintState.Load(chatID)
+	if !ok {
+		// Пробуем восстановить из БД (после редеплоя)
+		if r.restoreStateFromDB(chatID) {
+			v, ok = hintState.Load(chatID)
+		}
+		if !ok {
+			r.send(chatID, HintNotFoundText, makeErrorButtons())
+			return
+		}
+	}
+	hs, ok := v.(*hintSession)
+	if !ok {
+		r.send(chatID, HintNotFoundText, makeErrorButtons())
+		return
+	}
+
+	hs.mu.Lock()
+	parseData := hs.Parse
+	cachedHints := hs.CachedHints
+	level := hs.NextLevel
+	maxHints := hs.MaxHints
+	history := append([]domain.DialogMessage(nil), hs.Dialog...)
+	hs.mu.Unlock()
+
+	// Типы v2 совпадают с types LLM по JSON — конвертируем через сериализацию
+	in := service.DialogInput{
+		HintsShown: level - 1,
+		History:    history,
+		Question:   text,
+	}
+	var parse llmtypes.ParseResponse
+	if err := convertViaJSON(parseData, &parse); err != nil {
+		log.Printf("[dialog] failed to convert parse for chat %d: %v", chatID, err)
+		r.sendError(chatID, err)
+		return
+	}
+	in.Parse = &parse
+	if cachedHints != nil {
+		var hints llmtypes.HintResponse
+		if err := convertViaJSON(cachedHints, &hints); err == nil {
+			in.Hints = &hints
+		}
+	}
+
+	reply, err := r.Dialog.Reply(ctx, r.LlmManager.Get(chatID), strconv.FormatInt(chatID, 10), in)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrDialogLimitReached):
+			r.send(chatID, DialogLimitText, makeHintButtons(level-1, maxHints, true))
+		case errors.Is(err, domain.ErrRateLimited):
+			r.send(chatID, DialogRateLimitText, nil)
+		case errors.Is(err, domain.ErrInvalidInput):
+			r.send(chatID, HintNotFoundText, makeErrorButtons())
+		default:
+			r.sendError(chatID, err)
+		}
+		return
+	}
+
+	hs.mu.Lock()
+	hs.Dialog = append(hs.Dialog,
+		domain.DialogMessage{Role: domain.DialogRoleChild, Text: text, CreatedAt: time.Now()},
+		reply.Message,
+	)
+	hs.mu.Unlock()
+	r.saveHintContext(chatID, hs)
+
+	r.send(chatID, reply.Message.Text, makeHintButtons(level-1, maxHints, true))
+}
+
+// convertViaJSON переносит данные между структурами с одинаковой JSON-схемой
+func convertViaJSON(src, dst interface{}) error {
+	js, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, dst)
+}
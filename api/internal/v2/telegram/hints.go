@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"child-bot/api/internal/domain"
 	"child-bot/api/internal/store"
 	"child-bot/api/internal/v2/types"
 )
@@ -22,7 +23,8 @@ type hintSession struct {
 	EngineName   string
 	NextLevel    int
 	MaxHints     int
-	CachedHints  *types.HintResponse // кэш ответа LLM со всеми подсказками
+	CachedHints  *types.HintResponse    // кэш ответа LLM со всеми подсказками
+	Dialog       []domain.DialogMessage // вопросы ребёнка и ответы наставника по задаче
 }
 
 func (r *Router) sendHint(_ context.Context, chatID int64, msgID int, hs *hintSession) {
@@ -22,6 +22,7 @@ type Router struct {
 	LlmManager *service.LlmManager
 	LLMClient  *llmclient.Client
 	Store      *store.Store
+	Dialog     *service.DialogService // диалог по задаче во время подсказок (опционально)
 }
 
 func (r *Router) GetToken() string {
@@ -165,6 +166,12 @@ func (r *Router) HandleUpdate(upd tgbotapi.Update, llmName string) {
 			})
 			r.send(cid, NewTaskText, makeErrorButtons())
 			return
+		case Hints:
+			// В диалог идут только вопросы по задаче; ответ ученика обрабатывается как раньше
+			if r.Dialog != nil && looksLikeQuestion(upd.Message.Text) {
+				r.handleDialogMessage(ctx, cid, upd.Message.Text)
+				return
+			}
 		}
 	}
 
@@ -330,6 +330,9 @@ func inferNextState(upd tgbotapi.Update, cur State) (State, bool) {
 
 	// 5) Текст
 	if s := strings.TrimSpace(upd.Message.Text); s != "" {
+		if cur == Hints && looksLikeQuestion(s) {
+			return Hints, true // вопрос по задаче → диалог с наставником
+		}
 		if cur == AwaitSolution || cur == Hints {
 			return Check, true // текстовое решение → сразу check
 		}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"log"
 
+	"child-bot/api/internal/domain"
 	"child-bot/api/internal/store"
 	"child-bot/api/internal/v2/types"
 )
@@ -307,6 +308,7 @@ func (r *Router) saveHintContext(chatID int64, hs *hintSession) {
 	nextLevel := hs.NextLevel
 	maxHints := hs.MaxHints
 	cachedHints := hs.CachedHints
+	dialog := append([]domain.DialogMessage(nil), hs.Dialog...)
 	// Для больших изображений очищаем ссылку чтобы освободить память
 	var imageCopy []byte
 	if len(hs.Image) > 0 && len(hs.Image) < 100*1024 {
@@ -340,6 +342,11 @@ func (r *Router) saveHintContext(chatID int64, hs *hintSession) {
 			cachedHintsJSON, _ = json.Marshal(cachedHints)
 		}
 
+		var dialogJSON []byte
+		if len(dialog) > 0 {
+			dialogJSON, _ = json.Marshal(dialog)
+		}
+
 		data := store.HintContextData{
 			ParseJSON:       parseJSON,
 			DetectJSON:      detectJSON,
@@ -348,6 +355,7 @@ func (r *Router) saveHintContext(chatID int64, hs *hintSession) {
 			MaxHints:        maxHints,
 			ImageBase64:     imageBase64,
 			CachedHintsJSON: cachedHintsJSON,
+			DialogJSON:      dialogJSON,
 		}
 
 		hintContextJSON, err := json.Marshal(data)
@@ -425,6 +433,13 @@ func (r *Router) restoreHintSession(hintContextJSON []byte) (*hintSession, error
 		}
 	}
 
+	// Восстанавливаем диалог по задаче
+	if len(data.DialogJSON) > 0 {
+		if err := json.Unmarshal(data.DialogJSON, &hs.Dialog); err != nil {
+			log.Printf("[state_persistence] failed to unmarshal dialog: %v", err)
+		}
+	}
+
 	return hs, nil
 }
 
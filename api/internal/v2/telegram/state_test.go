@@ -366,6 +366,8 @@ func TestInferNextState_Text(t *testing.T) {
 		{"Text from AwaitSolution -> Check", "42", AwaitSolution, Check, true},
 		{"Text from Report -> Report", "error description", Report, Report, true},
 		{"Text from AwaitGrade -> AwaitingTask", "2", AwaitGrade, AwaitingTask, true},
+		{"Question from Hints -> Hints", "А почему надо делить?", Hints, Hints, true},
+		{"Answer from Hints -> Check", "42", Hints, Check, true},
 		{"Text from AwaitingTask (no change)", "hello", AwaitingTask, AwaitingTask, false},
 		{"Empty text", "   ", AwaitingTask, AwaitingTask, false},
 	}
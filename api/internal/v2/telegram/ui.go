@@ -48,6 +48,8 @@ const (
 	GradePreviewText        = "Чтобы я мог давать подсказки подходящего уровня, выбери свой класс 🧩"
 	AwaitSolutionText       = "📸 Пришли фото твоего решения — и я посмотрю, всё ли правильно 😊"
 	AwaitNewTaskText        = "📸 Скидывай своё задание — и разберёмся вместе! 🤓"
+	DialogLimitText         = "🤔 По этой задаче вопросов уже много.\nПопробуй следующую подсказку или пришли своё решение 📸"
+	DialogRateLimitText     = "⏳ Давай чуть помедленнее — подумай над ответом и спроси ещё раз через минуту 😉"
 	StepSolutionText        = "\n\n\n\n📘 Шаги решения\n\n"
 
 	YesButton          = "✅ Да, направь подсказку"
@@ -0,0 +1,4 @@
+-- Откатываем разбор ошибок
+ALTER TABLE attempts
+DROP COLUMN IF EXISTS current_mistake_index,
+DROP COLUMN IF EXISTS mistakes_result;
@@ -0,0 +1,7 @@
+-- Разбор ошибок для check попыток ("объясни мою ошибку")
+ALTER TABLE attempts
+ADD COLUMN IF NOT EXISTS mistakes_result JSONB,
+ADD COLUMN IF NOT EXISTS current_mistake_index INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN attempts.mistakes_result IS 'JSON разбор ошибок: объяснение и мини-подсказки для каждого ErrorSpan';
+COMMENT ON COLUMN attempts.current_mistake_index IS 'Индекс ошибки, которую ребёнок разбирает сейчас';
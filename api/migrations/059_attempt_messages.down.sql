@@ -0,0 +1,3 @@
+-- Откатываем диалог по попыткам
+DROP INDEX IF EXISTS idx_attempt_messages_attempt;
+DROP TABLE IF EXISTS attempt_messages;
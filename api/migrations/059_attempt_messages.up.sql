@@ -0,0 +1,23 @@
+-- Attempt Messages - диалог ребёнка с наставником по задаче
+CREATE TABLE IF NOT EXISTS attempt_messages (
+    id BIGSERIAL PRIMARY KEY,
+    attempt_id UUID NOT NULL REFERENCES attempts(id) ON DELETE CASCADE,
+
+    -- Автор и текст
+    role VARCHAR(10) NOT NULL CHECK (role IN ('child', 'tutor')),
+    content TEXT NOT NULL,
+
+    -- Ответ наставника заменён, потому что раскрывал решение
+    guarded BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- Timestamps
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Индексы
+CREATE INDEX IF NOT EXISTS idx_attempt_messages_attempt
+    ON attempt_messages (attempt_id, created_at);
+
+-- Комментарии
+COMMENT ON TABLE attempt_messages IS 'Сократический диалог по попытке: вопросы ребёнка и ответы наставника';
+COMMENT ON COLUMN attempt_messages.guarded IS 'TRUE если ответ LLM был заменён, так как раскрывал итоговый ответ';
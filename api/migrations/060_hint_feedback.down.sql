@@ -0,0 +1,4 @@
+-- Откатываем оценки подсказок
+DROP VIEW IF EXISTS hint_template_ratings;
+DROP INDEX IF EXISTS idx_hint_feedback_template;
+DROP TABLE IF EXISTS hint_feedback;
@@ -0,0 +1,46 @@
+-- Hint Feedback - оценки подсказок ребёнком и перегенерации
+CREATE TABLE IF NOT EXISTS hint_feedback (
+    id BIGSERIAL PRIMARY KEY,
+    attempt_id UUID NOT NULL REFERENCES attempts(id) ON DELETE CASCADE,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+
+    -- Какая подсказка оценена
+    hint_index INTEGER NOT NULL,
+    hint_level VARCHAR(10) NOT NULL,
+    template_id VARCHAR(100) NOT NULL DEFAULT '',
+
+    -- Оценка
+    rating VARCHAR(20) NOT NULL CHECK (rating IN ('helpful', 'unclear', 'gave_away_answer')),
+    comment TEXT,
+    regenerations INTEGER NOT NULL DEFAULT 0,
+
+    -- Timestamps
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (attempt_id, hint_index)
+);
+
+-- Индексы
+CREATE INDEX IF NOT EXISTS idx_hint_feedback_template
+    ON hint_feedback (template_id, rating);
+
+-- Сводка по шаблонам: где подсказки чаще всего непонятны или раскрывают ответ
+CREATE OR REPLACE VIEW hint_template_ratings AS
+SELECT
+    template_id,
+    hint_level,
+    COUNT(*) AS ratings_total,
+    COUNT(*) FILTER (WHERE rating = 'helpful') AS helpful,
+    COUNT(*) FILTER (WHERE rating = 'unclear') AS unclear,
+    COUNT(*) FILTER (WHERE rating = 'gave_away_answer') AS gave_away_answer,
+    SUM(regenerations) AS regenerations,
+    ROUND(COUNT(*) FILTER (WHERE rating <> 'helpful')::NUMERIC / COUNT(*), 2) AS poor_ratio
+FROM hint_feedback
+GROUP BY template_id, hint_level;
+
+-- Комментарии
+COMMENT ON TABLE hint_feedback IS 'Оценки подсказок: помогла / непонятно / раскрыла ответ';
+COMMENT ON COLUMN hint_feedback.hint_index IS 'Индекс подсказки в плоском списке hints_result';
+COMMENT ON COLUMN hint_feedback.regenerations IS 'Сколько раз подсказка была перегенерирована по этой оценке';
+COMMENT ON VIEW hint_template_ratings IS 'Качество подсказок по шаблонам (poor_ratio — доля плохих оценок)';
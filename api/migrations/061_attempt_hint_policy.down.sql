@@ -0,0 +1,5 @@
+-- Откатываем адаптивную политику подсказок
+DROP INDEX IF EXISTS idx_attempts_child_template;
+
+ALTER TABLE attempts
+DROP COLUMN IF EXISTS hint_policy;
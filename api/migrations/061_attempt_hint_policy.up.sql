@@ -0,0 +1,9 @@
+-- Адаптивная политика подсказок для help попыток
+ALTER TABLE attempts
+ADD COLUMN IF NOT EXISTS hint_policy JSONB;
+
+-- Индекс для поиска прошлых попыток ребёнка по шаблону
+CREATE INDEX IF NOT EXISTS idx_attempts_child_template
+    ON attempts (child_profile_id, ((parse_result->'items'->0->'ped_keys'->>'template_id')));
+
+COMMENT ON COLUMN attempts.hint_policy IS 'JSON политика подсказок, подобранная по истории ребёнка, с обоснованием';
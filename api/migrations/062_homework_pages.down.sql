@@ -0,0 +1,14 @@
+-- Откатываем режим страницы
+DELETE FROM attempts WHERE attempt_type = 'page';
+
+DROP INDEX IF EXISTS idx_attempts_parent;
+
+ALTER TABLE attempts
+DROP COLUMN IF EXISTS page_position,
+DROP COLUMN IF EXISTS parent_attempt_id;
+
+ALTER TABLE attempts DROP CONSTRAINT IF EXISTS attempts_attempt_type_check;
+ALTER TABLE attempts
+ADD CONSTRAINT attempts_attempt_type_check CHECK (attempt_type IN ('help', 'check'));
+
+COMMENT ON COLUMN attempts.attempt_type IS 'Тип: help (подсказки) или check (проверка решения)';
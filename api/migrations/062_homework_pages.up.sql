@@ -0,0 +1,18 @@
+-- Режим страницы: фото целой страницы разбивается на задачи-попытки
+ALTER TABLE attempts DROP CONSTRAINT IF EXISTS attempts_attempt_type_check;
+ALTER TABLE attempts
+ADD CONSTRAINT attempts_attempt_type_check CHECK (attempt_type IN ('help', 'check', 'page'));
+
+ALTER TABLE attempts
+ADD COLUMN IF NOT EXISTS parent_attempt_id UUID REFERENCES attempts(id) ON DELETE CASCADE,
+ADD COLUMN IF NOT EXISTS page_position INTEGER;
+
+-- Индексы
+CREATE INDEX IF NOT EXISTS idx_attempts_parent
+    ON attempts (parent_attempt_id, page_position)
+    WHERE parent_attempt_id IS NOT NULL;
+
+-- Комментарии
+COMMENT ON COLUMN attempts.attempt_type IS 'Тип: help (подсказки), check (проверка решения) или page (страница с несколькими задачами)';
+COMMENT ON COLUMN attempts.parent_attempt_id IS 'Попытка-страница, из которой выделена задача';
+COMMENT ON COLUMN attempts.page_position IS 'Порядковый номер задачи на странице (с 0)';
@@ -0,0 +1,65 @@
+-- Возвращаем автозабор всех наград при разблокировке (как в 038)
+CREATE OR REPLACE FUNCTION auto_claim_achievement_reward()
+RETURNS TRIGGER AS $$
+DECLARE
+    achievement_reward_type VARCHAR(50);
+    achievement_reward_amount INTEGER;
+BEGIN
+    IF NEW.is_unlocked = TRUE AND (OLD.is_unlocked IS NULL OR OLD.is_unlocked = FALSE) THEN
+        SELECT reward_type, reward_amount
+        INTO achievement_reward_type, achievement_reward_amount
+        FROM achievements
+        WHERE id = NEW.achievement_id;
+
+        IF achievement_reward_type = 'coins' AND achievement_reward_amount IS NOT NULL THEN
+            UPDATE child_profiles
+            SET coins_balance = coins_balance + achievement_reward_amount,
+                updated_at = NOW()
+            WHERE id = NEW.child_profile_id;
+        END IF;
+
+        NEW.is_claimed := TRUE;
+        NEW.claimed_at := NOW();
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION auto_claim_achievement_on_insert()
+RETURNS TRIGGER AS $$
+DECLARE
+    achievement_reward_type VARCHAR(50);
+    achievement_reward_amount INTEGER;
+BEGIN
+    IF NEW.is_unlocked = TRUE THEN
+        SELECT reward_type, reward_amount
+        INTO achievement_reward_type, achievement_reward_amount
+        FROM achievements
+        WHERE id = NEW.achievement_id;
+
+        IF achievement_reward_type = 'coins' AND achievement_reward_amount IS NOT NULL THEN
+            UPDATE child_profiles
+            SET coins_balance = coins_balance + achievement_reward_amount,
+                updated_at = NOW()
+            WHERE id = NEW.child_profile_id;
+        END IF;
+
+        NEW.is_claimed := TRUE;
+        NEW.claimed_at := NOW();
+        NEW.unlocked_at := NOW();
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Незабранные награды считаем полученными, как было до ручного получения
+UPDATE child_achievements
+SET is_claimed = TRUE, claimed_at = COALESCE(claimed_at, NOW())
+WHERE is_unlocked = TRUE AND is_claimed = FALSE;
+
+DROP INDEX IF EXISTS idx_child_rewards_profile;
+DROP TABLE IF EXISTS child_rewards;
+
+COMMENT ON COLUMN achievements.reward_type IS NULL;
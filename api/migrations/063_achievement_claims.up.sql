@@ -0,0 +1,99 @@
+-- Получение наград за достижения
+-- Монеты по-прежнему начисляются автоматически при разблокировке (038),
+-- остальные награды (XP, стикеры, аватары, значки) ребёнок забирает через POST /achievements/{id}/claim
+
+-- Полученные предметы-награды ребёнка (стикеры, аватары, значки)
+CREATE TABLE IF NOT EXISTS child_rewards (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    reward_type VARCHAR(50) NOT NULL CHECK (reward_type IN ('sticker', 'avatar', 'badge')),
+    reward_id VARCHAR(100) NOT NULL, -- reward_id достижения, а если его нет — id достижения
+    reward_name VARCHAR(200),
+    achievement_id VARCHAR(100) REFERENCES achievements(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- Повторное получение не создаёт дубликатов
+    UNIQUE (child_profile_id, reward_type, reward_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_child_rewards_profile
+    ON child_rewards (child_profile_id, reward_type);
+
+-- Автозабор при разблокировке только для монет
+CREATE OR REPLACE FUNCTION auto_claim_achievement_reward()
+RETURNS TRIGGER AS $$
+DECLARE
+    achievement_reward_type VARCHAR(50);
+    achievement_reward_amount INTEGER;
+BEGIN
+    -- Проверяем что достижение только что разблокировалось
+    IF NEW.is_unlocked = TRUE AND (OLD.is_unlocked IS NULL OR OLD.is_unlocked = FALSE) THEN
+        SELECT reward_type, reward_amount
+        INTO achievement_reward_type, achievement_reward_amount
+        FROM achievements
+        WHERE id = NEW.achievement_id;
+
+        -- Монеты начисляем сразу и помечаем награду полученной
+        IF achievement_reward_type = 'coins' THEN
+            IF achievement_reward_amount IS NOT NULL THEN
+                UPDATE child_profiles
+                SET coins_balance = coins_balance + achievement_reward_amount,
+                    updated_at = NOW()
+                WHERE id = NEW.child_profile_id;
+            END IF;
+
+            NEW.is_claimed := TRUE;
+            NEW.claimed_at := NOW();
+        END IF;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION auto_claim_achievement_on_insert()
+RETURNS TRIGGER AS $$
+DECLARE
+    achievement_reward_type VARCHAR(50);
+    achievement_reward_amount INTEGER;
+BEGIN
+    -- Если создаётся уже разблокированное достижение
+    IF NEW.is_unlocked = TRUE THEN
+        SELECT reward_type, reward_amount
+        INTO achievement_reward_type, achievement_reward_amount
+        FROM achievements
+        WHERE id = NEW.achievement_id;
+
+        -- Монеты начисляем сразу и помечаем награду полученной
+        IF achievement_reward_type = 'coins' THEN
+            IF achievement_reward_amount IS NOT NULL THEN
+                UPDATE child_profiles
+                SET coins_balance = coins_balance + achievement_reward_amount,
+                    updated_at = NOW()
+                WHERE id = NEW.child_profile_id;
+            END IF;
+
+            NEW.is_claimed := TRUE;
+            NEW.claimed_at := NOW();
+        END IF;
+
+        NEW.unlocked_at := NOW();
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Предметы, уже автоматически помеченные полученными, переносим в child_rewards
+INSERT INTO child_rewards (child_profile_id, reward_type, reward_id, reward_name, achievement_id, created_at)
+SELECT ca.child_profile_id, a.reward_type, COALESCE(a.reward_id, a.id), a.reward_name, a.id,
+       COALESCE(ca.claimed_at, NOW())
+FROM child_achievements ca
+JOIN achievements a ON a.id = ca.achievement_id
+WHERE ca.is_unlocked = TRUE
+  AND ca.is_claimed = TRUE
+  AND a.reward_type IN ('sticker', 'avatar', 'badge')
+ON CONFLICT (child_profile_id, reward_type, reward_id) DO NOTHING;
+
+COMMENT ON TABLE child_rewards IS 'Полученные ребёнком предметы-награды за достижения: стикеры, аватары, значки';
+COMMENT ON COLUMN achievements.reward_type IS 'Тип награды: coins, xp, sticker, avatar, badge';
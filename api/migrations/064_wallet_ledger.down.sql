@@ -0,0 +1,115 @@
+-- Возвращаем прямое начисление монет в триггерах (как в 038/063)
+CREATE OR REPLACE FUNCTION auto_claim_achievement_reward()
+RETURNS TRIGGER AS $$
+DECLARE
+    achievement_reward_type VARCHAR(50);
+    achievement_reward_amount INTEGER;
+BEGIN
+    IF NEW.is_unlocked = TRUE AND (OLD.is_unlocked IS NULL OR OLD.is_unlocked = FALSE) THEN
+        SELECT reward_type, reward_amount
+        INTO achievement_reward_type, achievement_reward_amount
+        FROM achievements
+        WHERE id = NEW.achievement_id;
+
+        IF achievement_reward_type = 'coins' THEN
+            IF achievement_reward_amount IS NOT NULL THEN
+                UPDATE child_profiles
+                SET coins_balance = coins_balance + achievement_reward_amount,
+                    updated_at = NOW()
+                WHERE id = NEW.child_profile_id;
+            END IF;
+
+            NEW.is_claimed := TRUE;
+            NEW.claimed_at := NOW();
+        END IF;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION auto_claim_achievement_on_insert()
+RETURNS TRIGGER AS $$
+DECLARE
+    achievement_reward_type VARCHAR(50);
+    achievement_reward_amount INTEGER;
+BEGIN
+    IF NEW.is_unlocked = TRUE THEN
+        SELECT reward_type, reward_amount
+        INTO achievement_reward_type, achievement_reward_amount
+        FROM achievements
+        WHERE id = NEW.achievement_id;
+
+        IF achievement_reward_type = 'coins' THEN
+            IF achievement_reward_amount IS NOT NULL THEN
+                UPDATE child_profiles
+                SET coins_balance = coins_balance + achievement_reward_amount,
+                    updated_at = NOW()
+                WHERE id = NEW.child_profile_id;
+            END IF;
+
+            NEW.is_claimed := TRUE;
+            NEW.claimed_at := NOW();
+        END IF;
+
+        NEW.unlocked_at := NOW();
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION auto_claim_referral_reward()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.is_active = TRUE AND (OLD.is_active IS NULL OR OLD.is_active = FALSE) THEN
+        IF NEW.reward_coins IS NOT NULL THEN
+            UPDATE child_profiles
+            SET coins_balance = coins_balance + NEW.reward_coins,
+                updated_at = NOW()
+            WHERE id = NEW.referrer_id;
+        END IF;
+
+        NEW.reward_claimed := TRUE;
+        NEW.reward_claimed_at := NOW();
+        NEW.activated_at := NOW();
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION auto_claim_milestone_reward()
+RETURNS TRIGGER AS $$
+DECLARE
+    milestone_reward_coins INTEGER;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        SELECT reward_coins
+        INTO milestone_reward_coins
+        FROM referral_milestones
+        WHERE id = NEW.milestone_id;
+
+        IF milestone_reward_coins IS NOT NULL THEN
+            UPDATE child_profiles
+            SET coins_balance = coins_balance + milestone_reward_coins,
+                updated_at = NOW()
+            WHERE id = NEW.child_profile_id;
+        END IF;
+
+        NEW.is_claimed := TRUE;
+        NEW.claimed_at := NOW();
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS wallet_add_coins(UUID, INTEGER, VARCHAR, VARCHAR, VARCHAR, TEXT);
+
+DROP TRIGGER IF EXISTS trigger_wallet_transactions_append_only ON wallet_transactions;
+DROP FUNCTION IF EXISTS wallet_transactions_append_only();
+
+DROP INDEX IF EXISTS idx_wallet_transactions_source;
+DROP INDEX IF EXISTS idx_wallet_transactions_profile;
+DROP TABLE IF EXISTS wallet_transactions;
@@ -0,0 +1,216 @@
+-- Журнал начислений и списаний монет и XP
+-- Каждое изменение баланса — отдельная неизменяемая запись с источником и ключом идемпотентности.
+-- child_profiles.coins_balance и xp_total остаются материализованным балансом и меняются
+-- только вместе с записью в журнале (в той же транзакции).
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    currency VARCHAR(10) NOT NULL CHECK (currency IN ('coins', 'xp')),
+    amount INTEGER NOT NULL CHECK (amount <> 0),
+    balance_after INTEGER NOT NULL CHECK (balance_after >= 0),
+
+    -- Источник: за что начислено или на что потрачено
+    source VARCHAR(30) NOT NULL CHECK (source IN (
+        'attempt', 'hint', 'achievement', 'villain_battle', 'level_up',
+        'daily_login', 'referral', 'purchase', 'opening_balance', 'adjustment'
+    )),
+    source_id VARCHAR(100),
+    idempotency_key VARCHAR(200) NOT NULL,
+    description TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- Одно и то же событие не может начислить дважды
+    UNIQUE (child_profile_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_profile
+    ON wallet_transactions (child_profile_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_source
+    ON wallet_transactions (source, source_id);
+
+-- Журнал только дополняется: исправления делаются новой записью 'adjustment'
+CREATE OR REPLACE FUNCTION wallet_transactions_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'wallet_transactions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_wallet_transactions_append_only
+    BEFORE UPDATE ON wallet_transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION wallet_transactions_append_only();
+
+-- Начисление монет из триггеров БД: запись в журнал + материализованный баланс.
+-- Повтор с тем же ключом ничего не меняет. Возвращает TRUE, если запись добавлена.
+CREATE OR REPLACE FUNCTION wallet_add_coins(
+    p_child_profile_id UUID,
+    p_amount INTEGER,
+    p_source VARCHAR,
+    p_source_id VARCHAR,
+    p_idempotency_key VARCHAR,
+    p_description TEXT
+) RETURNS BOOLEAN AS $$
+DECLARE
+    current_balance INTEGER;
+BEGIN
+    IF p_amount IS NULL OR p_amount = 0 THEN
+        RETURN FALSE;
+    END IF;
+
+    SELECT coins_balance INTO current_balance
+    FROM child_profiles
+    WHERE id = p_child_profile_id
+    FOR UPDATE;
+
+    IF NOT FOUND THEN
+        RETURN FALSE;
+    END IF;
+
+    INSERT INTO wallet_transactions (child_profile_id, currency, amount, balance_after,
+                                     source, source_id, idempotency_key, description)
+    VALUES (p_child_profile_id, 'coins', p_amount, COALESCE(current_balance, 0) + p_amount,
+            p_source, p_source_id, p_idempotency_key, p_description)
+    ON CONFLICT (child_profile_id, idempotency_key) DO NOTHING;
+
+    IF NOT FOUND THEN
+        RETURN FALSE;
+    END IF;
+
+    UPDATE child_profiles
+    SET coins_balance = COALESCE(coins_balance, 0) + p_amount,
+        updated_at = NOW()
+    WHERE id = p_child_profile_id;
+
+    RETURN TRUE;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Автозабор монет за достижения теперь проходит через журнал
+CREATE OR REPLACE FUNCTION auto_claim_achievement_reward()
+RETURNS TRIGGER AS $$
+DECLARE
+    achievement_reward_type VARCHAR(50);
+    achievement_reward_amount INTEGER;
+BEGIN
+    -- Проверяем что достижение только что разблокировалось
+    IF NEW.is_unlocked = TRUE AND (OLD.is_unlocked IS NULL OR OLD.is_unlocked = FALSE) THEN
+        SELECT reward_type, reward_amount
+        INTO achievement_reward_type, achievement_reward_amount
+        FROM achievements
+        WHERE id = NEW.achievement_id;
+
+        -- Монеты начисляем сразу и помечаем награду полученной
+        IF achievement_reward_type = 'coins' THEN
+            PERFORM wallet_add_coins(NEW.child_profile_id, achievement_reward_amount,
+                                     'achievement', NEW.achievement_id,
+                                     'coins:achievement:' || NEW.achievement_id, NULL);
+
+            NEW.is_claimed := TRUE;
+            NEW.claimed_at := NOW();
+        END IF;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION auto_claim_achievement_on_insert()
+RETURNS TRIGGER AS $$
+DECLARE
+    achievement_reward_type VARCHAR(50);
+    achievement_reward_amount INTEGER;
+BEGIN
+    -- Если создаётся уже разблокированное достижение
+    IF NEW.is_unlocked = TRUE THEN
+        SELECT reward_type, reward_amount
+        INTO achievement_reward_type, achievement_reward_amount
+        FROM achievements
+        WHERE id = NEW.achievement_id;
+
+        -- Монеты начисляем сразу и помечаем награду полученной
+        IF achievement_reward_type = 'coins' THEN
+            PERFORM wallet_add_coins(NEW.child_profile_id, achievement_reward_amount,
+                                     'achievement', NEW.achievement_id,
+                                     'coins:achievement:' || NEW.achievement_id, NULL);
+
+            NEW.is_claimed := TRUE;
+            NEW.claimed_at := NOW();
+        END IF;
+
+        NEW.unlocked_at := NOW();
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Награда рефереру за активацию приглашения
+CREATE OR REPLACE FUNCTION auto_claim_referral_reward()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Проверяем что реферал только что активировался
+    IF NEW.is_active = TRUE AND (OLD.is_active IS NULL OR OLD.is_active = FALSE) THEN
+        IF NEW.reward_coins IS NOT NULL THEN
+            PERFORM wallet_add_coins(NEW.referrer_id, NEW.reward_coins,
+                                     'referral', NEW.id::TEXT,
+                                     'coins:referral:' || NEW.id::TEXT, NULL);
+        END IF;
+
+        -- Автоматически помечаем награду как полученную
+        NEW.reward_claimed := TRUE;
+        NEW.reward_claimed_at := NOW();
+        NEW.activated_at := NOW();
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Награда за referral milestone (038) тоже проходит через журнал: ключ на пару ребёнок + milestone
+CREATE OR REPLACE FUNCTION auto_claim_milestone_reward()
+RETURNS TRIGGER AS $$
+DECLARE
+    milestone_reward_coins INTEGER;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        SELECT reward_coins
+        INTO milestone_reward_coins
+        FROM referral_milestones
+        WHERE id = NEW.milestone_id;
+
+        IF milestone_reward_coins IS NOT NULL THEN
+            PERFORM wallet_add_coins(NEW.child_profile_id, milestone_reward_coins,
+                                     'referral', 'milestone:' || NEW.milestone_id::TEXT,
+                                     'coins:referral_milestone:' || NEW.milestone_id::TEXT, NULL);
+        END IF;
+
+        NEW.is_claimed := TRUE;
+        NEW.claimed_at := NOW();
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Стартовые записи: текущие балансы становятся открывающим остатком,
+-- чтобы сумма журнала совпадала с child_profiles
+INSERT INTO wallet_transactions (child_profile_id, currency, amount, balance_after,
+                                 source, idempotency_key, description)
+SELECT id, 'coins', coins_balance, coins_balance, 'opening_balance', 'coins:opening_balance', 'Баланс до появления журнала'
+FROM child_profiles
+WHERE COALESCE(coins_balance, 0) > 0
+ON CONFLICT (child_profile_id, idempotency_key) DO NOTHING;
+
+INSERT INTO wallet_transactions (child_profile_id, currency, amount, balance_after,
+                                 source, idempotency_key, description)
+SELECT id, 'xp', xp_total, xp_total, 'opening_balance', 'xp:opening_balance', 'Опыт до появления журнала'
+FROM child_profiles
+WHERE COALESCE(xp_total, 0) > 0
+ON CONFLICT (child_profile_id, idempotency_key) DO NOTHING;
+
+COMMENT ON TABLE wallet_transactions IS 'Неизменяемый журнал начислений и списаний монет и XP';
+COMMENT ON COLUMN wallet_transactions.idempotency_key IS 'Ключ события, например coins:attempt:<id>; повтор с тем же ключом игнорируется';
+COMMENT ON COLUMN wallet_transactions.balance_after IS 'Баланс валюты после применения записи';
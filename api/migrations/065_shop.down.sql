@@ -0,0 +1,6 @@
+-- Откатываем магазин
+DROP INDEX IF EXISTS idx_child_inventory_profile;
+DROP TABLE IF EXISTS child_inventory;
+
+DROP INDEX IF EXISTS idx_shop_items_category;
+DROP TABLE IF EXISTS shop_items;
@@ -0,0 +1,73 @@
+-- Магазин за монеты: аватары, аксессуары маскота и усилители для битв со злодеями
+
+CREATE TABLE IF NOT EXISTS shop_items (
+    id VARCHAR(100) PRIMARY KEY, -- для аватаров совпадает с child_profiles.avatar_id
+    category VARCHAR(30) NOT NULL CHECK (category IN ('avatar', 'mascot_item', 'power_up')),
+    name VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    icon VARCHAR(200) NOT NULL, -- emoji или URL изображения
+
+    price INTEGER NOT NULL DEFAULT 0 CHECK (price >= 0), -- цена в монетах
+    min_level INTEGER NOT NULL DEFAULT 1 CHECK (min_level >= 1), -- с какого уровня доступен
+
+    is_default BOOLEAN NOT NULL DEFAULT FALSE, -- есть у всех детей без покупки
+    is_stackable BOOLEAN NOT NULL DEFAULT FALSE, -- можно купить несколько (расходуемые усилители)
+    effect JSONB, -- параметры усилителя, например {"damage_multiplier": 2}
+
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- Бесплатные по умолчанию предметы не продаются
+    CHECK (NOT is_default OR price = 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shop_items_category
+    ON shop_items (category, sort_order)
+    WHERE is_active = TRUE;
+
+-- Инвентарь ребёнка: купленные предметы
+CREATE TABLE IF NOT EXISTS child_inventory (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    item_id VARCHAR(100) NOT NULL REFERENCES shop_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity >= 0),
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (child_profile_id, item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_child_inventory_profile
+    ON child_inventory (child_profile_id);
+
+-- Каталог: бесплатные аватары из прежнего списка и премиум-аватары за монеты
+INSERT INTO shop_items (id, category, name, icon, price, min_level, is_default, sort_order) VALUES
+    ('cat',     'avatar', 'Кот',      '🐱', 0,   1, TRUE,  1),
+    ('dog',     'avatar', 'Пёс',      '🐶', 0,   1, TRUE,  2),
+    ('panda',   'avatar', 'Панда',    '🐼', 0,   1, TRUE,  3),
+    ('fox',     'avatar', 'Лиса',     '🦊', 0,   1, TRUE,  4),
+    ('bear',    'avatar', 'Медведь',  '🐻', 0,   1, TRUE,  5),
+    ('lion',    'avatar', 'Лев',      '🦁', 0,   1, TRUE,  6),
+    ('robot',   'avatar', 'Робот',    '🤖', 0,   1, TRUE,  7),
+    ('tiger',   'avatar', 'Тигр',     '🐯', 150, 2, FALSE, 8),
+    ('unicorn', 'avatar', 'Единорог', '🦄', 300, 3, FALSE, 9),
+    ('alien',   'avatar', 'Пришелец', '👽', 500, 5, FALSE, 10)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO shop_items (id, category, name, description, icon, price, min_level, sort_order) VALUES
+    ('mascot_cap',     'mascot_item', 'Кепка',          'Кепка для маскота',           '🧢', 60,  1, 1),
+    ('mascot_glasses', 'mascot_item', 'Очки',           'Очки умника для маскота',     '👓', 80,  1, 2),
+    ('mascot_scarf',   'mascot_item', 'Шарф',           'Тёплый шарф для маскота',     '🧣', 100, 2, 3),
+    ('mascot_crown',   'mascot_item', 'Корона',         'Корона чемпиона для маскота', '👑', 400, 5, 4)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO shop_items (id, category, name, description, icon, price, min_level, is_stackable, effect, sort_order) VALUES
+    ('power_double_hit', 'power_up', 'Двойной удар', 'Следующая верная задача наносит злодею двойной урон', '⚡', 40, 1, TRUE,
+     '{"damage_multiplier": 2, "uses": 1}', 1),
+    ('power_shield',     'power_up', 'Щит',          'Ошибка не сбрасывает серию ударов по злодею',          '🛡️', 30, 2, TRUE,
+     '{"protect_combo": true, "uses": 1}', 2)
+ON CONFLICT (id) DO NOTHING;
+
+COMMENT ON TABLE shop_items IS 'Каталог магазина за монеты: avatar, mascot_item, power_up';
+COMMENT ON TABLE child_inventory IS 'Купленные ребёнком предметы; quantity > 1 только для расходуемых усилителей';
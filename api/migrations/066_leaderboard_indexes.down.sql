@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_wallet_transactions_xp_gained;
+DROP INDEX IF EXISTS idx_villain_battles_defeated_at;
+DROP INDEX IF EXISTS idx_attempts_correct_completed;
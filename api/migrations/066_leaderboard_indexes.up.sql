@@ -0,0 +1,16 @@
+-- Индексы для leaderboard друзей: подсчёт за неделю/месяц без полного сканирования
+
+-- Правильно решённые задачи по дате завершения
+CREATE INDEX IF NOT EXISTS idx_attempts_correct_completed
+    ON attempts (child_profile_id, completed_at)
+    WHERE attempt_type = 'check' AND status = 'completed' AND is_correct = TRUE;
+
+-- Побеждённые злодеи по дате победы
+CREATE INDEX IF NOT EXISTS idx_villain_battles_defeated_at
+    ON villain_battles (child_profile_id, defeated_at)
+    WHERE status = 'defeated';
+
+-- Полученный XP из журнала
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_xp_gained
+    ON wallet_transactions (child_profile_id, created_at)
+    WHERE currency = 'xp' AND amount > 0;
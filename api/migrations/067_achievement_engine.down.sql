@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS achievement_processed_events;
+DROP TABLE IF EXISTS child_achievement_counters;
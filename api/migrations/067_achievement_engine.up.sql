@@ -0,0 +1,97 @@
+-- Инкрементальный движок достижений: счётчики по типам требований
+-- и журнал обработанных событий (каждое событие учитывается ровно один раз)
+
+CREATE TABLE IF NOT EXISTS child_achievement_counters (
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    requirement_type VARCHAR(50) NOT NULL, -- tasks_correct, hints_used, streak_days, ...
+    value INTEGER NOT NULL DEFAULT 0 CHECK (value >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (child_profile_id, requirement_type)
+);
+
+CREATE TABLE IF NOT EXISTS achievement_processed_events (
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    event_key VARCHAR(200) NOT NULL, -- attempt_completed:<id>, hint:<attempt>:<index>, ...
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (child_profile_id, event_key)
+);
+
+COMMENT ON TABLE child_achievement_counters IS 'Текущие значения требований достижений для каждого ребёнка';
+COMMENT ON TABLE achievement_processed_events IS 'События, уже учтённые движком достижений';
+
+-- Начальные значения счётчиков из накопленных данных
+INSERT INTO child_achievement_counters (child_profile_id, requirement_type, value)
+SELECT child_profile_id, 'tasks_correct', COUNT(*)
+FROM attempts
+WHERE attempt_type = 'check' AND status = 'completed' AND is_correct = TRUE
+GROUP BY child_profile_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO child_achievement_counters (child_profile_id, requirement_type, value)
+SELECT child_profile_id, 'tasks_no_hints', COUNT(*)
+FROM attempts
+WHERE attempt_type = 'check' AND status = 'completed' AND is_correct = TRUE AND COALESCE(hints_used, 0) = 0
+GROUP BY child_profile_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO child_achievement_counters (child_profile_id, requirement_type, value)
+SELECT child_profile_id, 'errors_found', COUNT(*)
+FROM attempts
+WHERE attempt_type = 'check' AND status = 'completed' AND is_correct = FALSE AND has_errors = TRUE
+GROUP BY child_profile_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO child_achievement_counters (child_profile_id, requirement_type, value)
+SELECT child_profile_id, 'villains_defeated', COUNT(DISTINCT villain_id)
+FROM villain_battles
+WHERE status = 'defeated'
+GROUP BY child_profile_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO child_achievement_counters (child_profile_id, requirement_type, value)
+SELECT referrer_id, 'friends_invited', COUNT(*)
+FROM referrals
+WHERE is_active = TRUE
+GROUP BY referrer_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO child_achievement_counters (child_profile_id, requirement_type, value)
+SELECT id, 'hints_used', hints_used_total
+FROM child_profiles
+WHERE COALESCE(hints_used_total, 0) > 0
+ON CONFLICT DO NOTHING;
+
+INSERT INTO child_achievement_counters (child_profile_id, requirement_type, value)
+SELECT id, 'streak_days', streak_days
+FROM child_profiles
+WHERE COALESCE(streak_days, 0) > 0
+ON CONFLICT DO NOTHING;
+
+INSERT INTO child_achievement_counters (child_profile_id, requirement_type, value)
+SELECT ca.child_profile_id, 'stickers_collected', COUNT(DISTINCT ca.achievement_id)
+FROM child_achievements ca
+JOIN achievements a ON a.id = ca.achievement_id
+WHERE ca.is_unlocked = TRUE AND a.reward_type = 'sticker'
+GROUP BY ca.child_profile_id
+ON CONFLICT DO NOTHING;
+
+-- Уже учтённые события не должны засчитаться повторно
+INSERT INTO achievement_processed_events (child_profile_id, event_key)
+SELECT child_profile_id, 'attempt_completed:' || id
+FROM attempts
+WHERE attempt_type = 'check' AND status = 'completed'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO achievement_processed_events (child_profile_id, event_key)
+SELECT DISTINCT child_profile_id, 'villain_defeated:' || villain_id
+FROM villain_battles
+WHERE status = 'defeated'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO achievement_processed_events (child_profile_id, event_key)
+SELECT referrer_id, 'friend_activated:' || referred_id
+FROM referrals
+WHERE is_active = TRUE
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_achievements_series_level;
+
+ALTER TABLE achievements
+DROP COLUMN IF EXISTS updated_at,
+DROP COLUMN IF EXISTS catalog_version,
+DROP COLUMN IF EXISTS texts,
+DROP COLUMN IF EXISTS artwork_key,
+DROP COLUMN IF EXISTS series_level,
+DROP COLUMN IF EXISTS series;
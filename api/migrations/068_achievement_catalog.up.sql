@@ -0,0 +1,18 @@
+-- Достижения из декларативного каталога (internal/achievement/catalog)
+ALTER TABLE achievements
+ADD COLUMN IF NOT EXISTS series VARCHAR(50),
+ADD COLUMN IF NOT EXISTS series_level INTEGER,
+ADD COLUMN IF NOT EXISTS artwork_key VARCHAR(100),
+ADD COLUMN IF NOT EXISTS texts JSONB,
+ADD COLUMN IF NOT EXISTS catalog_version INTEGER,
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_achievements_series_level
+    ON achievements (series, series_level)
+    WHERE series IS NOT NULL;
+
+COMMENT ON COLUMN achievements.series IS 'Серия из каталога (streak, checks, villains, ...); NULL — достижение из миграций';
+COMMENT ON COLUMN achievements.series_level IS 'Уровень внутри серии, с 1';
+COMMENT ON COLUMN achievements.artwork_key IS 'Ключ картинки стикера или значка';
+COMMENT ON COLUMN achievements.texts IS 'Локализованные тексты: {"ru": {"title", "description", "reward_name"}}';
+COMMENT ON COLUMN achievements.catalog_version IS 'Версия каталога, из которой достижение синхронизировано последним';
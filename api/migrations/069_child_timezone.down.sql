@@ -0,0 +1 @@
+ALTER TABLE child_profiles DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,5 @@
+-- Часовой пояс ребёнка (IANA): по нему считаются дни серии, злодей дня и недели отчётов
+ALTER TABLE child_profiles
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'Europe/Moscow';
+
+COMMENT ON COLUMN child_profiles.timezone IS 'IANA timezone (Europe/Moscow, Asia/Vladivostok), границы дня и недели для ребёнка';
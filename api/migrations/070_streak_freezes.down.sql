@@ -0,0 +1,9 @@
+ALTER TABLE child_profiles
+DROP COLUMN IF EXISTS streak_broken_gap_end,
+DROP COLUMN IF EXISTS streak_broken_gap_start,
+DROP COLUMN IF EXISTS streak_broken_value;
+
+DROP TABLE IF EXISTS child_streak_days;
+
+DELETE FROM child_inventory WHERE item_id = 'streak_freeze';
+DELETE FROM shop_items WHERE id = 'streak_freeze';
@@ -0,0 +1,42 @@
+-- Защита серии дней: заморозки, дни, прощённые родителем, и история серии для календаря
+
+-- Заморозка — расходуемый усилитель магазина, хранится в child_inventory
+INSERT INTO shop_items (id, category, name, description, icon, price, min_level, is_stackable, effect, sort_order) VALUES
+    ('streak_freeze', 'power_up', 'Заморозка серии', 'Сохраняет серию, если пропустить день', '🧊', 50, 1, TRUE,
+     '{"streak_freeze": 1}', 3)
+ON CONFLICT (id) DO NOTHING;
+
+-- История серии по дням (дата — по часовому поясу ребёнка).
+-- Пропущенные дни не хранятся: календарь выводит их из промежутков.
+CREATE TABLE IF NOT EXISTS child_streak_days (
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('active', 'frozen', 'excused')),
+    reason TEXT NOT NULL DEFAULT '', -- причина от родителя для excused
+    granted_by VARCHAR(100), -- кто из родителей простил день
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (child_profile_id, day)
+);
+
+-- Последний обрыв серии: если родитель простит пропущенные дни, серия восстанавливается
+ALTER TABLE child_profiles
+    ADD COLUMN IF NOT EXISTS streak_broken_value INTEGER NOT NULL DEFAULT 0 CHECK (streak_broken_value >= 0),
+    ADD COLUMN IF NOT EXISTS streak_broken_gap_start DATE,
+    ADD COLUMN IF NOT EXISTS streak_broken_gap_end DATE;
+
+COMMENT ON TABLE child_streak_days IS 'Дни серии ребёнка: active — занимался, frozen — спасён заморозкой, excused — прощён родителем';
+COMMENT ON COLUMN child_profiles.streak_broken_value IS 'Длина серии до последнего обрыва (0 — восстанавливать нечего)';
+
+-- Текущая серия считается непрерывной цепочкой активных дней до последнего захода
+INSERT INTO child_streak_days (child_profile_id, day, status)
+SELECT cp.id, d::date, 'active'
+FROM child_profiles cp
+CROSS JOIN LATERAL generate_series(
+    (cp.last_activity_at AT TIME ZONE cp.timezone)::date - (cp.streak_days - 1),
+    (cp.last_activity_at AT TIME ZONE cp.timezone)::date,
+    INTERVAL '1 day'
+) AS d
+WHERE cp.last_activity_at IS NOT NULL AND cp.streak_days > 0
+ON CONFLICT DO NOTHING;
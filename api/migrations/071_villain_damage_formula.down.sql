@@ -0,0 +1,7 @@
+ALTER TABLE damage_events
+DROP COLUMN IF EXISTS bonuses,
+DROP COLUMN IF EXISTS is_critical,
+DROP COLUMN IF EXISTS base_damage;
+
+ALTER TABLE villains
+DROP COLUMN IF EXISTS damage_formula;
@@ -0,0 +1,28 @@
+-- Урон злодею зависит от задачи: формула на злодея и разбивка урона в событиях
+
+-- Формула урона (см. internal/damage.Formula). NULL — формула по умолчанию
+-- от damage_per_correct_task: бонусы за задания, тип задачи, класс, первую попытку и крит без подсказок.
+ALTER TABLE villains
+    ADD COLUMN IF NOT EXISTS damage_formula JSONB;
+
+-- Босс недели бьётся дольше: меньше бонусов, зато крит сильнее
+UPDATE villains SET damage_formula = '{
+    "per_extra_item": 1,
+    "per_grade": 0,
+    "first_try_bonus": 2,
+    "critical_multiplier": 2,
+    "max_damage": 50
+}'::jsonb
+WHERE id = 'boss_week_chaos';
+
+-- Разбивка урона для экрана битвы («крит!» и причины бонусов)
+ALTER TABLE damage_events
+    ADD COLUMN IF NOT EXISTS base_damage INTEGER NOT NULL DEFAULT 0 CHECK (base_damage >= 0),
+    ADD COLUMN IF NOT EXISTS is_critical BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS bonuses JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+-- Старые события наносили фиксированный урон
+UPDATE damage_events SET base_damage = damage WHERE base_damage = 0;
+
+COMMENT ON COLUMN villains.damage_formula IS 'Формула урона за задачу (JSON), NULL — по умолчанию от damage_per_correct_task';
+COMMENT ON COLUMN damage_events.bonuses IS 'Разбивка урона: [{code, label, damage}], code: items, task_type, template, grade, first_try, critical, cap';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS boss_damage_events;
+DROP TABLE IF EXISTS boss_battles;
+DROP TABLE IF EXISTS boss_rewards;
+DROP TABLE IF EXISTS boss_phases;
+DROP TABLE IF EXISTS boss_configs;
+
+UPDATE villains SET unlock_order = 7 WHERE id = 'boss_week_chaos';
@@ -0,0 +1,103 @@
+-- Недельный босс: битва на несколько дней с фазами, восстановлением HP за пропуски и особыми наградами
+
+-- Настройки босса для недельного режима. Боссы сменяют друг друга по неделям (week_order).
+CREATE TABLE IF NOT EXISTS boss_configs (
+    villain_id VARCHAR(100) PRIMARY KEY REFERENCES villains(id) ON DELETE CASCADE,
+    duration_days INTEGER NOT NULL DEFAULT 7 CHECK (duration_days BETWEEN 1 AND 7), -- с понедельника
+    regen_hp_per_day INTEGER NOT NULL DEFAULT 0 CHECK (regen_hp_per_day >= 0),     -- за каждый пропущенный день
+    week_order INTEGER NOT NULL UNIQUE,
+    is_enabled BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+-- Фазы: каждая требует своего предмета и/или типа задач (NULL — любой)
+CREATE TABLE IF NOT EXISTS boss_phases (
+    villain_id VARCHAR(100) NOT NULL REFERENCES boss_configs(villain_id) ON DELETE CASCADE,
+    phase INTEGER NOT NULL CHECK (phase >= 1),
+    title VARCHAR(200) NOT NULL,
+    hp INTEGER NOT NULL CHECK (hp > 0),
+    required_subject VARCHAR(20),    -- math, ru, en, world, literature
+    required_task_type VARCHAR(50),  -- ped_keys.task_type: word_problems, geometry...
+
+    PRIMARY KEY (villain_id, phase)
+);
+
+-- Награды за победу над боссом
+CREATE TABLE IF NOT EXISTS boss_rewards (
+    id BIGSERIAL PRIMARY KEY,
+    villain_id VARCHAR(100) NOT NULL REFERENCES boss_configs(villain_id) ON DELETE CASCADE,
+    reward_type VARCHAR(20) NOT NULL CHECK (reward_type IN ('coins', 'xp', 'item')),
+    item_id VARCHAR(100) REFERENCES shop_items(id), -- для item
+    amount INTEGER NOT NULL CHECK (amount > 0),
+
+    CHECK ((reward_type = 'item') = (item_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_boss_rewards_villain ON boss_rewards (villain_id);
+
+-- Битвы с боссом: одна на ребёнка в неделю (неделя — по часовому поясу ребёнка)
+CREATE TABLE IF NOT EXISTS boss_battles (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    villain_id VARCHAR(100) NOT NULL REFERENCES villains(id) ON DELETE CASCADE,
+    week_start DATE NOT NULL,
+    ends_on DATE NOT NULL,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'defeated', 'expired')),
+    current_phase INTEGER NOT NULL DEFAULT 1 CHECK (current_phase >= 1),
+    phase_hp INTEGER NOT NULL CHECK (phase_hp >= 0),
+    total_damage INTEGER NOT NULL DEFAULT 0 CHECK (total_damage >= 0),
+    tasks_count INTEGER NOT NULL DEFAULT 0 CHECK (tasks_count >= 0),
+
+    -- Восстановление HP: последний день с уроном и день, до которого пропуски уже учтены
+    last_active_on DATE NOT NULL,
+    regen_through DATE,
+    regen_total INTEGER NOT NULL DEFAULT 0 CHECK (regen_total >= 0),
+
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    defeated_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (child_profile_id, week_start)
+);
+
+-- Удары по боссу (в том числе по неподходящей фазе — с нулевым уроном, для подсказки ребёнку)
+CREATE TABLE IF NOT EXISTS boss_damage_events (
+    id BIGSERIAL PRIMARY KEY,
+    battle_id BIGINT NOT NULL REFERENCES boss_battles(id) ON DELETE CASCADE,
+    attempt_id UUID REFERENCES attempts(id) ON DELETE SET NULL,
+    phase INTEGER NOT NULL,
+    damage INTEGER NOT NULL CHECK (damage >= 0),
+    is_critical BOOLEAN NOT NULL DEFAULT FALSE,
+    bonuses JSONB NOT NULL DEFAULT '[]'::jsonb,
+    subject VARCHAR(20) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_boss_damage_events_battle
+    ON boss_damage_events (battle_id, created_at DESC);
+
+-- Одна попытка бьёт босса один раз
+CREATE UNIQUE INDEX IF NOT EXISTS idx_boss_damage_events_attempt
+    ON boss_damage_events (battle_id, attempt_id) WHERE attempt_id IS NOT NULL;
+
+-- Хаос Недели становится недельным боссом и уходит из дневной ротации (unlock_order = день недели)
+UPDATE villains SET unlock_order = 100 WHERE id = 'boss_week_chaos';
+
+INSERT INTO boss_configs (villain_id, duration_days, regen_hp_per_day, week_order) VALUES
+    ('boss_week_chaos', 7, 20, 1)
+ON CONFLICT (villain_id) DO NOTHING;
+
+INSERT INTO boss_phases (villain_id, phase, title, hp, required_subject, required_task_type) VALUES
+    ('boss_week_chaos', 1, 'Щит из чисел', 120, 'math', NULL),
+    ('boss_week_chaos', 2, 'Путаница букв', 100, 'ru', NULL),
+    ('boss_week_chaos', 3, 'Сердце хаоса', 80, 'math', 'word_problems')
+ON CONFLICT (villain_id, phase) DO NOTHING;
+
+INSERT INTO boss_rewards (villain_id, reward_type, item_id, amount) VALUES
+    ('boss_week_chaos', 'coins', NULL, 500),
+    ('boss_week_chaos', 'xp', NULL, 150),
+    ('boss_week_chaos', 'item', 'streak_freeze', 1);
+
+COMMENT ON TABLE boss_configs IS 'Недельные боссы: длительность битвы, восстановление HP за пропущенный день, порядок по неделям';
+COMMENT ON TABLE boss_phases IS 'Фазы недельного босса: HP и требуемый предмет/тип задач';
+COMMENT ON TABLE boss_battles IS 'Битвы с недельным боссом: одна на ребёнка в неделю';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pet_feedings;
+DROP TABLE IF EXISTS child_pets;
@@ -0,0 +1,36 @@
+-- Питомец-компаньон ребёнка: показатели меняются со временем по часам ребёнка,
+-- решённые задачи и победы над злодеями кормят питомца, стадия растёт с уровнем.
+CREATE TABLE IF NOT EXISTS child_pets (
+    child_profile_id UUID PRIMARY KEY REFERENCES child_profiles(id) ON DELETE CASCADE,
+    pet_type VARCHAR(30) NOT NULL DEFAULT 'owl',
+    hunger INTEGER NOT NULL DEFAULT 30 CHECK (hunger BETWEEN 0 AND 100),
+    happiness INTEGER NOT NULL DEFAULT 70 CHECK (happiness BETWEEN 0 AND 100),
+    energy INTEGER NOT NULL DEFAULT 80 CHECK (energy BETWEEN 0 AND 100),
+    stage INTEGER NOT NULL DEFAULT 1 CHECK (stage BETWEEN 1 AND 4),
+    stats_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), -- момент, на который посчитаны показатели
+    last_fed_at TIMESTAMPTZ,
+    evolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Кормления: одна попытка или одна победа кормит питомца один раз
+CREATE TABLE IF NOT EXISTS pet_feedings (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    source_key VARCHAR(150) NOT NULL, -- attempt:<id>, villain:<villain_id>:<battle_id>
+    food VARCHAR(20) NOT NULL CHECK (food IN ('meal', 'snack', 'treat')),
+    hunger_delta INTEGER NOT NULL DEFAULT 0,
+    happiness_delta INTEGER NOT NULL DEFAULT 0,
+    energy_delta INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (child_profile_id, source_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pet_feedings_child_created ON pet_feedings(child_profile_id, created_at DESC);
+
+COMMENT ON TABLE child_pets IS 'Питомец ребёнка: голод, настроение и бодрость на момент stats_at (дальше пересчитываются по часам ребёнка)';
+COMMENT ON COLUMN child_pets.hunger IS 'Голод: 0 — сыт, 100 — очень голоден';
+COMMENT ON COLUMN child_pets.stage IS 'Стадия эволюции: 1 яйцо, 2 малыш, 3 подросток, 4 взрослый; открывается уровнем ребёнка';
+COMMENT ON TABLE pet_feedings IS 'Кормления питомца за решённые задачи (meal/snack) и победы над злодеями (treat)';
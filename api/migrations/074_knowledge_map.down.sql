@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS knowledge_evidence;
+DROP TABLE IF EXISTS child_knowledge_mastery;
+DROP TABLE IF EXISTS knowledge_nodes;
@@ -0,0 +1,57 @@
+-- Карта знаний: дерево тем по предметам и классам (каталог синхронизируется при старте сервера)
+-- и освоение каждой темы ребёнком по истории проверок и подсказок.
+CREATE TABLE IF NOT EXISTS knowledge_nodes (
+    id VARCHAR(100) PRIMARY KEY,
+    subject VARCHAR(20) NOT NULL,
+    parent_id VARCHAR(100) REFERENCES knowledge_nodes(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('section', 'topic')),
+    title VARCHAR(200) NOT NULL,
+    grade_min INTEGER NOT NULL DEFAULT 1 CHECK (grade_min BETWEEN 1 AND 4),
+    grade_max INTEGER NOT NULL DEFAULT 4 CHECK (grade_max BETWEEN 1 AND 4),
+    template_code VARCHAR(10), -- T1..T52 у тем
+    task_types TEXT[] NOT NULL DEFAULT '{}', -- ped_keys.task_type, засчитываемые разделу
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    catalog_version INTEGER NOT NULL DEFAULT 1,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (grade_min <= grade_max)
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_nodes_subject ON knowledge_nodes(subject, sort_order) WHERE is_active;
+
+-- Освоение узла ребёнком
+CREATE TABLE IF NOT EXISTS child_knowledge_mastery (
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    node_id VARCHAR(100) NOT NULL REFERENCES knowledge_nodes(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    correct INTEGER NOT NULL DEFAULT 0,
+    hints_used INTEGER NOT NULL DEFAULT 0,
+    mastery INTEGER NOT NULL DEFAULT 0 CHECK (mastery BETWEEN 0 AND 100),
+    last_correct BOOLEAN,
+    last_practiced_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (child_profile_id, node_id)
+);
+
+-- Свидетельства освоения: одна проверка засчитывается узлу один раз
+CREATE TABLE IF NOT EXISTS knowledge_evidence (
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    attempt_id VARCHAR(100) NOT NULL,
+    node_id VARCHAR(100) NOT NULL REFERENCES knowledge_nodes(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL CHECK (score BETWEEN 0 AND 100),
+    is_correct BOOLEAN NOT NULL,
+    hints_used INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (child_profile_id, attempt_id, node_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_evidence_child_created ON knowledge_evidence(child_profile_id, created_at DESC);
+
+COMMENT ON TABLE knowledge_nodes IS 'Дерево тем: разделы курса Петерсон и темы-шаблоны T1..T52; синхронизируется из каталога curriculum.json';
+COMMENT ON COLUMN knowledge_nodes.task_types IS 'Типы задач, которые без известного шаблона засчитываются разделу';
+COMMENT ON TABLE child_knowledge_mastery IS 'Освоение темы ребёнком: 0..100, обновляется после каждой проверки';
+COMMENT ON COLUMN child_knowledge_mastery.mastery IS 'Скользящая оценка: верно без подсказок — 100, подсказки снижают, ошибка — 0';
+COMMENT ON TABLE knowledge_evidence IS 'Проверки, засчитанные узлам карты знаний (защита от повторного учёта)';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS review_results;
+DROP TABLE IF EXISTS review_items;
@@ -0,0 +1,52 @@
+-- Очередь повторения ошибок («Машина времени»): задача, решённая с ошибкой или с третьей
+-- подсказкой, возвращается к ребёнку по интервалам SM-2 (см. internal/review).
+CREATE TABLE IF NOT EXISTS review_items (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    source_attempt_id UUID NOT NULL, -- попытка, из которой задача попала в очередь
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('after_incorrect', 'after_3_hints')),
+    subject VARCHAR(20) NOT NULL DEFAULT 'math',
+    grade INTEGER NOT NULL DEFAULT 0,
+    task_text TEXT NOT NULL,
+    task_struct JSONB, -- снимок Parse исходной задачи (попытку могут удалить)
+
+    -- Задание для ближайшего повторения: аналог от LLM или исходная задача
+    review_task_text TEXT,
+    review_task_source VARCHAR(20) CHECK (review_task_source IN ('analogue', 'original')),
+    review_task_for INTEGER, -- для какого по счёту повторения сгенерировано задание
+
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'retired')),
+    repetitions INTEGER NOT NULL DEFAULT 0,
+    interval_days INTEGER NOT NULL DEFAULT 1,
+    ease_factor NUMERIC(4, 2) NOT NULL DEFAULT 2.5,
+    due_on DATE NOT NULL, -- день по календарю ребёнка
+    reviews_count INTEGER NOT NULL DEFAULT 0,
+    lapses INTEGER NOT NULL DEFAULT 0,
+    last_reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (child_profile_id, source_attempt_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_items_due ON review_items(child_profile_id, due_on) WHERE status = 'active';
+
+-- Результаты повторений
+CREATE TABLE IF NOT EXISTS review_results (
+    id BIGSERIAL PRIMARY KEY,
+    review_item_id BIGINT NOT NULL REFERENCES review_items(id) ON DELETE CASCADE,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    decision VARCHAR(30) NOT NULL, -- решение Check
+    quality INTEGER NOT NULL CHECK (quality BETWEEN 0 AND 5),
+    task_source VARCHAR(20),
+    interval_days INTEGER NOT NULL, -- новый интервал
+    due_on DATE NOT NULL, -- следующее повторение
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_results_item ON review_results(review_item_id, created_at DESC);
+
+COMMENT ON TABLE review_items IS 'Очередь повторения: задачи с ошибкой или третьей подсказкой, расписание SM-2';
+COMMENT ON COLUMN review_items.ease_factor IS 'Коэффициент лёгкости SM-2 (не ниже 1.3)';
+COMMENT ON COLUMN review_items.status IS 'active — в очереди, retired — закреплена (интервал от 30 дней)';
+COMMENT ON TABLE review_results IS 'Повторения задач из очереди и их результаты';
@@ -0,0 +1,68 @@
+-- Ежедневные миссии: каталог заданий (синхронизируется при старте из internal/mission/catalog)
+-- и три миссии ребёнка на каждый день по его календарю.
+CREATE TABLE IF NOT EXISTS mission_definitions (
+    id VARCHAR(50) PRIMARY KEY,
+    group_key VARCHAR(50) NOT NULL, -- в один день — не больше одной миссии группы
+    metric VARCHAR(30) NOT NULL,
+    subject VARCHAR(20), -- NULL — любой предмет
+    target INTEGER NOT NULL CHECK (target > 0),
+    reward_coins INTEGER NOT NULL DEFAULT 0 CHECK (reward_coins >= 0),
+    reward_xp INTEGER NOT NULL DEFAULT 0 CHECK (reward_xp >= 0),
+    weight INTEGER NOT NULL DEFAULT 1 CHECK (weight > 0),
+    rules JSONB NOT NULL DEFAULT '{}', -- условия выдачи (mission.Rules)
+    icon VARCHAR(20) NOT NULL DEFAULT '',
+    title VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    catalog_version INTEGER NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Миссии ребёнка: снимок определения на день выдачи (правка каталога не меняет выданные миссии)
+CREATE TABLE IF NOT EXISTS child_daily_missions (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    mission_date DATE NOT NULL, -- день по календарю ребёнка
+    slot INTEGER NOT NULL CHECK (slot > 0),
+    mission_id VARCHAR(50) NOT NULL REFERENCES mission_definitions(id),
+    metric VARCHAR(30) NOT NULL,
+    subject VARCHAR(20),
+    target INTEGER NOT NULL CHECK (target > 0),
+    progress INTEGER NOT NULL DEFAULT 0 CHECK (progress >= 0),
+    reward_coins INTEGER NOT NULL DEFAULT 0,
+    reward_xp INTEGER NOT NULL DEFAULT 0,
+    icon VARCHAR(20) NOT NULL DEFAULT '',
+    title VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed')),
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (child_profile_id, mission_date, slot),
+    UNIQUE (child_profile_id, mission_date, mission_id)
+);
+
+-- Засчитанные миссии действия: одно действие двигает миссию один раз
+CREATE TABLE IF NOT EXISTS mission_progress_events (
+    id BIGSERIAL PRIMARY KEY,
+    mission_id BIGINT NOT NULL REFERENCES child_daily_missions(id) ON DELETE CASCADE,
+    source_key VARCHAR(100) NOT NULL, -- attempt:<id>, hint:<attempt_id>:<index>
+    increment INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (mission_id, source_key)
+);
+
+-- Награды за миссии — новый источник журнала монет и XP
+ALTER TABLE wallet_transactions DROP CONSTRAINT IF EXISTS wallet_transactions_source_check;
+ALTER TABLE wallet_transactions
+ADD CONSTRAINT wallet_transactions_source_check CHECK (source IN (
+    'attempt', 'hint', 'achievement', 'villain_battle', 'level_up',
+    'daily_login', 'referral', 'purchase', 'opening_balance', 'adjustment', 'mission'
+));
+
+COMMENT ON TABLE mission_definitions IS 'Каталог ежедневных миссий (источник — internal/mission/catalog/missions.json)';
+COMMENT ON TABLE child_daily_missions IS 'Миссии ребёнка на день; награда начисляется при выполнении';
+COMMENT ON TABLE mission_progress_events IS 'Действия, уже засчитанные миссии';
@@ -0,0 +1,14 @@
+DROP FUNCTION IF EXISTS seasonal_event_open(VARCHAR, TEXT);
+
+DROP INDEX IF EXISTS idx_villains_event;
+
+-- Предметы событий снимаются с продажи; злодеи (вне ротации) и достижения остаются
+UPDATE shop_items SET is_active = FALSE WHERE event_id IS NOT NULL;
+
+ALTER TABLE shop_items DROP COLUMN IF EXISTS event_id;
+ALTER TABLE achievements DROP COLUMN IF EXISTS event_id;
+ALTER TABLE villains
+DROP COLUMN IF EXISTS event_weekdays,
+DROP COLUMN IF EXISTS event_id;
+
+DROP TABLE IF EXISTS seasonal_events;
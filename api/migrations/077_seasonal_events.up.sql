@@ -0,0 +1,48 @@
+-- Сезонные события (синхронизируются при старте из internal/seasonal/catalog): даты события,
+-- его злодеи, достижения и предметы магазина доступны детям только в эти даты.
+CREATE TABLE IF NOT EXISTS seasonal_events (
+    id VARCHAR(24) PRIMARY KEY,
+    title VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    icon VARCHAR(20) NOT NULL DEFAULT '',
+    theme_color VARCHAR(20) NOT NULL DEFAULT '',
+    start_date DATE NOT NULL, -- включительно, по календарю ребёнка
+    end_date DATE NOT NULL, -- включительно
+    catalog_version INTEGER NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true, -- false — событие убрано из каталога
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (end_date >= start_date)
+);
+
+-- Злодеи события: в дни недели event_weekdays заменяют злодея ежедневной ротации
+ALTER TABLE villains
+ADD COLUMN IF NOT EXISTS event_id VARCHAR(24) REFERENCES seasonal_events(id),
+ADD COLUMN IF NOT EXISTS event_weekdays INTEGER[];
+
+ALTER TABLE achievements
+ADD COLUMN IF NOT EXISTS event_id VARCHAR(24) REFERENCES seasonal_events(id);
+
+ALTER TABLE shop_items
+ADD COLUMN IF NOT EXISTS event_id VARCHAR(24) REFERENCES seasonal_events(id);
+
+CREATE INDEX IF NOT EXISTS idx_villains_event ON villains(event_id) WHERE event_id IS NOT NULL;
+
+-- Идёт ли событие сегодня по календарю ребёнка (пустой ребёнок — по Europe/Moscow)
+CREATE OR REPLACE FUNCTION seasonal_event_open(p_event_id VARCHAR, p_child_profile_id TEXT)
+RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1
+        FROM seasonal_events e
+        WHERE e.id = p_event_id AND e.is_active
+          AND (NOW() AT TIME ZONE COALESCE(
+                  (SELECT cp.timezone FROM child_profiles cp WHERE cp.id::TEXT = p_child_profile_id),
+                  'Europe/Moscow'))::DATE BETWEEN e.start_date AND e.end_date
+    )
+$$ LANGUAGE SQL STABLE;
+
+COMMENT ON TABLE seasonal_events IS 'Сезонные события (источник — internal/seasonal/catalog/events.json)';
+COMMENT ON COLUMN villains.event_id IS 'Событие, в дни которого появляется злодей; NULL — обычная ротация';
+COMMENT ON COLUMN achievements.event_id IS 'Событие достижения: прогресс только в его даты, после — видно, только если получено';
+COMMENT ON COLUMN shop_items.event_id IS 'Событие предмета: продаётся только в его даты';
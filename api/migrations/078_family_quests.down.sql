@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS family_notifications;
+DROP TABLE IF EXISTS family_quest_events;
+DROP TABLE IF EXISTS family_quests;
+DROP TABLE IF EXISTS family_quest_templates;
+
+-- Журнал неизменяем: уже начисленные награды за квесты остаются, новые запрещаются (NOT VALID)
+ALTER TABLE wallet_transactions DROP CONSTRAINT IF EXISTS wallet_transactions_source_check;
+ALTER TABLE wallet_transactions
+ADD CONSTRAINT wallet_transactions_source_check CHECK (source IN (
+    'attempt', 'hint', 'achievement', 'villain_battle', 'level_up',
+    'daily_login', 'referral', 'purchase', 'opening_balance', 'adjustment', 'mission'
+)) NOT VALID;
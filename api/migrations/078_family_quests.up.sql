@@ -0,0 +1,110 @@
+-- Семейные квесты: задания для ребёнка и родителя (шаблоны синхронизируются при старте
+-- из internal/family/catalog). Родитель — аккаунт платформы, создавший профиль ребёнка
+-- (child_profiles.platform_id + platform_user_id).
+CREATE TABLE IF NOT EXISTS family_quest_templates (
+    id VARCHAR(50) PRIMARY KEY,
+    quest_type VARCHAR(30) NOT NULL,
+    icon VARCHAR(20) NOT NULL DEFAULT '',
+    title VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    child_metric VARCHAR(30) NOT NULL,
+    child_target INTEGER NOT NULL CHECK (child_target > 0),
+    parent_action VARCHAR(30), -- NULL — подтверждения родителя не нужны
+    parent_target INTEGER NOT NULL DEFAULT 0 CHECK (parent_target >= 0),
+    parent_label VARCHAR(100) NOT NULL DEFAULT '',
+    quest_window VARCHAR(20) NOT NULL CHECK (quest_window IN ('days', 'weekend')),
+    duration_days INTEGER NOT NULL DEFAULT 0,
+    reward_coins INTEGER NOT NULL DEFAULT 0 CHECK (reward_coins >= 0),
+    reward_xp INTEGER NOT NULL DEFAULT 0 CHECK (reward_xp >= 0),
+    reward_badge_id VARCHAR(100),
+    reward_badge_name VARCHAR(200),
+    catalog_version INTEGER NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Квест семьи: снимок шаблона на момент начала (правка каталога не меняет начатые квесты)
+CREATE TABLE IF NOT EXISTS family_quests (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    template_id VARCHAR(50) NOT NULL REFERENCES family_quest_templates(id),
+    quest_type VARCHAR(30) NOT NULL,
+    icon VARCHAR(20) NOT NULL DEFAULT '',
+    title VARCHAR(200) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    child_metric VARCHAR(30) NOT NULL,
+    child_target INTEGER NOT NULL CHECK (child_target > 0),
+    child_progress INTEGER NOT NULL DEFAULT 0 CHECK (child_progress >= 0),
+    parent_action VARCHAR(30),
+    parent_target INTEGER NOT NULL DEFAULT 0,
+    parent_progress INTEGER NOT NULL DEFAULT 0 CHECK (parent_progress >= 0),
+    parent_label VARCHAR(100) NOT NULL DEFAULT '',
+    quest_window VARCHAR(20) NOT NULL,
+    reward_coins INTEGER NOT NULL DEFAULT 0,
+    reward_xp INTEGER NOT NULL DEFAULT 0,
+    reward_badge_id VARCHAR(100),
+    reward_badge_name VARCHAR(200),
+    started_by VARCHAR(10) NOT NULL CHECK (started_by IN ('child', 'parent')),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'expired')),
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMPTZ NOT NULL, -- полночь по календарю ребёнка
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (ends_at > started_at)
+);
+
+-- Один активный квест каждого шаблона у ребёнка
+CREATE UNIQUE INDEX IF NOT EXISTS idx_family_quests_active
+    ON family_quests (child_profile_id, template_id)
+    WHERE status = 'active';
+
+CREATE INDEX IF NOT EXISTS idx_family_quests_child
+    ON family_quests (child_profile_id, started_at DESC);
+
+-- Засчитанные квесту действия: проверки ребёнка и подтверждения родителя (одно действие — один раз)
+CREATE TABLE IF NOT EXISTS family_quest_events (
+    id BIGSERIAL PRIMARY KEY,
+    quest_id BIGINT NOT NULL REFERENCES family_quests(id) ON DELETE CASCADE,
+    actor VARCHAR(10) NOT NULL CHECK (actor IN ('child', 'parent')),
+    source_key VARCHAR(100) NOT NULL, -- attempt:<id>, day:<YYYY-MM-DD>, confirm:<ключ>
+    increment INTEGER NOT NULL,
+    note TEXT NOT NULL DEFAULT '', -- комментарий родителя к подтверждению
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (quest_id, source_key)
+);
+
+-- Уведомления семьи: ребёнку и родителю о выполненном квесте
+CREATE TABLE IF NOT EXISTS family_notifications (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    recipient VARCHAR(10) NOT NULL CHECK (recipient IN ('child', 'parent')),
+    kind VARCHAR(30) NOT NULL, -- quest_completed
+    quest_id BIGINT REFERENCES family_quests(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (quest_id, recipient, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_family_notifications_inbox
+    ON family_notifications (child_profile_id, recipient, created_at DESC);
+
+-- Награды за семейные квесты — новый источник журнала монет и XP
+ALTER TABLE wallet_transactions DROP CONSTRAINT IF EXISTS wallet_transactions_source_check;
+ALTER TABLE wallet_transactions
+ADD CONSTRAINT wallet_transactions_source_check CHECK (source IN (
+    'attempt', 'hint', 'achievement', 'villain_battle', 'level_up',
+    'daily_login', 'referral', 'purchase', 'opening_balance', 'adjustment', 'mission',
+    'family_quest'
+));
+
+COMMENT ON TABLE family_quest_templates IS 'Шаблоны семейных квестов (источник — internal/family/catalog/quests.json)';
+COMMENT ON TABLE family_quests IS 'Семейные квесты: прогресс ребёнка по проверкам и родителя по подтверждениям';
+COMMENT ON TABLE family_quest_events IS 'Действия, уже засчитанные семейному квесту';
+COMMENT ON TABLE family_notifications IS 'Уведомления ребёнку и родителю о семейных квестах';
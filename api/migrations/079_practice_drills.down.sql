@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS practice_items;
+DROP TABLE IF EXISTS practice_sessions;
+
+-- Журнал неизменяем: уже начисленные награды за разминки остаются, новые запрещаются (NOT VALID)
+ALTER TABLE wallet_transactions DROP CONSTRAINT IF EXISTS wallet_transactions_source_check;
+ALTER TABLE wallet_transactions
+ADD CONSTRAINT wallet_transactions_source_check CHECK (source IN (
+    'attempt', 'hint', 'achievement', 'villain_battle', 'level_up',
+    'daily_login', 'referral', 'purchase', 'opening_balance', 'adjustment', 'mission',
+    'family_quest'
+)) NOT VALID;
@@ -0,0 +1,53 @@
+-- Тренировки-разминки (brain breaks): задания генерирует сервер, верные ответы хранятся
+-- только здесь и клиенту не отдаются, счёт считается по записанным ответам.
+CREATE TABLE IF NOT EXISTS practice_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL,
+    grade INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'finished')),
+    items_total INTEGER NOT NULL CHECK (items_total > 0),
+    correct_count INTEGER NOT NULL DEFAULT 0 CHECK (correct_count >= 0),
+    xp_awarded INTEGER NOT NULL DEFAULT 0 CHECK (xp_awarded >= 0),
+    coins_awarded INTEGER NOT NULL DEFAULT 0 CHECK (coins_awarded >= 0),
+    reward_date DATE, -- день награды по календарю ребёнка, для дневного лимита
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL, -- ответы после этого момента не принимаются
+    finished_at TIMESTAMPTZ,
+
+    CHECK (expires_at > started_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_practice_sessions_child
+    ON practice_sessions(child_profile_id, started_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_practice_sessions_reward_date
+    ON practice_sessions(child_profile_id, reward_date)
+    WHERE reward_date IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS practice_items (
+    session_id BIGINT NOT NULL REFERENCES practice_sessions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL CHECK (position >= 0),
+    prompt VARCHAR(200) NOT NULL,
+    options TEXT[] NOT NULL DEFAULT '{}',
+    hint VARCHAR(200) NOT NULL DEFAULT '',
+    answer VARCHAR(50) NOT NULL,
+    given_answer VARCHAR(50),
+    is_correct BOOLEAN,
+    counted BOOLEAN, -- верный ответ, данный не быстрее минимального интервала
+    answered_at TIMESTAMPTZ,
+
+    PRIMARY KEY (session_id, position)
+);
+
+-- Награды за разминки — новый источник журнала монет и XP
+ALTER TABLE wallet_transactions DROP CONSTRAINT IF EXISTS wallet_transactions_source_check;
+ALTER TABLE wallet_transactions
+ADD CONSTRAINT wallet_transactions_source_check CHECK (source IN (
+    'attempt', 'hint', 'achievement', 'villain_battle', 'level_up',
+    'daily_login', 'referral', 'purchase', 'opening_balance', 'adjustment', 'mission',
+    'family_quest', 'practice'
+));
+
+COMMENT ON TABLE practice_sessions IS 'Сессии тренировок-разминок с наградой в пределах дневного лимита';
+COMMENT ON TABLE practice_items IS 'Задания сессии с верным ответом и ответом ребёнка';
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS reward_policy_events;
+
+DROP INDEX IF EXISTS idx_attempts_child_task_hash;
+DROP INDEX IF EXISTS idx_attempts_child_image_hash;
+
+ALTER TABLE attempts
+    DROP COLUMN IF EXISTS answer_shown_at,
+    DROP COLUMN IF EXISTS task_hash,
+    DROP COLUMN IF EXISTS image_hash;
@@ -0,0 +1,35 @@
+-- Политика наград против «фарма»: отпечатки задачи в попытке, чтобы узнавать повтор
+-- той же задачи, момент показа ответа и журнал урезанных начислений для родителя.
+ALTER TABLE attempts
+    ADD COLUMN IF NOT EXISTS image_hash VARCHAR(64),      -- SHA-256 присланного фото задачи
+    ADD COLUMN IF NOT EXISTS task_hash VARCHAR(64),       -- SHA-256 распознанного условия
+    ADD COLUMN IF NOT EXISTS answer_shown_at TIMESTAMPTZ; -- проверка решения или последняя подсказка
+
+CREATE INDEX IF NOT EXISTS idx_attempts_child_image_hash
+    ON attempts(child_profile_id, image_hash)
+    WHERE image_hash IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_attempts_child_task_hash
+    ON attempts(child_profile_id, task_hash)
+    WHERE task_hash IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS reward_policy_events (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    currency VARCHAR(10) NOT NULL CHECK (currency IN ('coins', 'xp')),
+    source VARCHAR(30) NOT NULL,
+    source_id VARCHAR(100),
+    idempotency_key VARCHAR(200) NOT NULL, -- ключ начисления в журнале: повтор не пересчитывается
+    requested INTEGER NOT NULL CHECK (requested > 0),
+    granted INTEGER NOT NULL CHECK (granted >= 0 AND granted < requested),
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('daily_cap', 'repeated_task', 'answer_shown')),
+    repeats INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (child_profile_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reward_policy_events_child
+    ON reward_policy_events(child_profile_id, created_at DESC);
+
+COMMENT ON TABLE reward_policy_events IS 'Начисления, урезанные политикой наград, для аудита подозрительных закономерностей';
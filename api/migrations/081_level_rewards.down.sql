@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS child_level_ups;
+
+DELETE FROM child_rewards WHERE reward_type = 'title';
+ALTER TABLE child_rewards DROP CONSTRAINT IF EXISTS child_rewards_reward_type_check;
+ALTER TABLE child_rewards
+ADD CONSTRAINT child_rewards_reward_type_check CHECK (reward_type IN ('sticker', 'avatar', 'badge'));
@@ -0,0 +1,24 @@
+-- Таблица уровней с наградами (internal/level): переходы на уровень и выданные за них
+-- награды; звания хранятся среди полученных наград ребёнка.
+CREATE TABLE IF NOT EXISTS child_level_ups (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    level INTEGER NOT NULL CHECK (level >= 2),
+    rewards JSONB NOT NULL DEFAULT '[]', -- выданные награды уровня
+    backfilled BOOLEAN NOT NULL DEFAULT FALSE, -- уровень пройден до появления таблицы наград
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    seen_at TIMESTAMPTZ, -- ребёнок видел поздравление
+
+    -- Награды уровня выдаются один раз
+    UNIQUE (child_profile_id, level)
+);
+
+CREATE INDEX IF NOT EXISTS idx_child_level_ups_unseen
+    ON child_level_ups(child_profile_id, level)
+    WHERE seen_at IS NULL;
+
+ALTER TABLE child_rewards DROP CONSTRAINT IF EXISTS child_rewards_reward_type_check;
+ALTER TABLE child_rewards
+ADD CONSTRAINT child_rewards_reward_type_check CHECK (reward_type IN ('sticker', 'avatar', 'badge', 'title'));
+
+COMMENT ON TABLE child_level_ups IS 'Переходы ребёнка на уровень с выданными наградами из таблицы уровней';
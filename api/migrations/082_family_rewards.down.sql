@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_family_notifications_redemption;
+ALTER TABLE family_notifications DROP COLUMN IF EXISTS redemption_id;
+
+DROP TABLE IF EXISTS family_reward_redemptions;
+DROP TABLE IF EXISTS family_rewards;
+
+-- Журнал неизменяем: уже проведённые списания за награды остаются, новые запрещаются (NOT VALID)
+ALTER TABLE wallet_transactions DROP CONSTRAINT IF EXISTS wallet_transactions_source_check;
+ALTER TABLE wallet_transactions
+ADD CONSTRAINT wallet_transactions_source_check CHECK (source IN (
+    'attempt', 'hint', 'achievement', 'villain_battle', 'level_up',
+    'daily_login', 'referral', 'purchase', 'opening_balance', 'adjustment', 'mission',
+    'family_quest', 'practice'
+)) NOT VALID;
@@ -0,0 +1,64 @@
+-- Награды в реальном мире, которые родитель назначает за монеты («30 минут мультиков = 300 монет»).
+-- Ребёнок запрашивает награду — монеты списываются сразу и ждут решения родителя; при отказе
+-- они возвращаются.
+CREATE TABLE IF NOT EXISTS family_rewards (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    title VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    icon VARCHAR(20) NOT NULL DEFAULT '🎁',
+    price INTEGER NOT NULL CHECK (price > 0), -- цена в монетах
+    is_active BOOLEAN NOT NULL DEFAULT TRUE, -- снятая родителем награда остаётся в истории
+    created_by VARCHAR(255) NOT NULL, -- platform_user_id родителя
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_family_rewards_active
+    ON family_rewards (child_profile_id, price)
+    WHERE is_active = TRUE;
+
+CREATE TABLE IF NOT EXISTS family_reward_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    reward_id BIGINT NOT NULL REFERENCES family_rewards(id) ON DELETE CASCADE,
+    -- Награда на момент запроса: родитель может изменить её позже
+    title VARCHAR(100) NOT NULL,
+    icon VARCHAR(20) NOT NULL,
+    price INTEGER NOT NULL CHECK (price > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'declined')),
+    idempotency_key VARCHAR(100) NOT NULL,
+    parent_note TEXT NOT NULL DEFAULT '',
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMPTZ,
+
+    UNIQUE (child_profile_id, idempotency_key),
+    CHECK ((status = 'pending') = (decided_at IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_family_reward_redemptions_child
+    ON family_reward_redemptions (child_profile_id, requested_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_family_reward_redemptions_pending
+    ON family_reward_redemptions (child_profile_id)
+    WHERE status = 'pending';
+
+-- Уведомления о запросах награды и решениях родителя
+ALTER TABLE family_notifications
+ADD COLUMN IF NOT EXISTS redemption_id BIGINT REFERENCES family_reward_redemptions(id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_family_notifications_redemption
+    ON family_notifications (redemption_id, recipient, kind)
+    WHERE redemption_id IS NOT NULL;
+
+-- Списание и возврат монет за награды — новый источник журнала
+ALTER TABLE wallet_transactions DROP CONSTRAINT IF EXISTS wallet_transactions_source_check;
+ALTER TABLE wallet_transactions
+ADD CONSTRAINT wallet_transactions_source_check CHECK (source IN (
+    'attempt', 'hint', 'achievement', 'villain_battle', 'level_up',
+    'daily_login', 'referral', 'purchase', 'opening_balance', 'adjustment', 'mission',
+    'family_quest', 'practice', 'family_reward'
+));
+
+COMMENT ON TABLE family_rewards IS 'Награды в реальном мире, которые родитель назначил ребёнку за монеты';
+COMMENT ON TABLE family_reward_redemptions IS 'Запросы наград ребёнком: монеты списаны, ждут решения родителя';
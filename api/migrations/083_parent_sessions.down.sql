@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS parent_sessions;
+DROP TABLE IF EXISTS parent_pins;
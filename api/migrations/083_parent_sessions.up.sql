@@ -0,0 +1,26 @@
+-- Родительская сессия. Ребёнок и родитель обычно запускают приложение с одного аккаунта платформы,
+-- поэтому подписанных параметров запуска мало: родитель дополнительно вводит PIN и получает
+-- короткоживущий токен сессии, который привязан к профилю ребёнка.
+CREATE TABLE IF NOT EXISTS parent_pins (
+    platform_id VARCHAR(20) NOT NULL,
+    parent_user_id VARCHAR(255) NOT NULL, -- подписанный user ID платформы (vk_user_id)
+    pin_hash BYTEA NOT NULL, -- PBKDF2-SHA256
+    pin_salt BYTEA NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (platform_id, parent_user_id)
+);
+
+CREATE TABLE IF NOT EXISTS parent_sessions (
+    token_hash BYTEA PRIMARY KEY, -- SHA-256 токена, сам токен не хранится
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    platform_id VARCHAR(20) NOT NULL,
+    parent_user_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_parent_sessions_expires ON parent_sessions (expires_at);
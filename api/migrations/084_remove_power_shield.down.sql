@@ -0,0 +1,2 @@
+-- Возвраты остаются в журнале; щит снова продаётся
+UPDATE shop_items SET is_active = TRUE WHERE id = 'power_shield';
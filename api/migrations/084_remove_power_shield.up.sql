@@ -0,0 +1,17 @@
+-- Усилитель «Щит» (065) снимается с продажи: у злодея нет серии ударов, которую он бы защищал,
+-- и ни одна битва его не тратила. Купленные щиты возвращаются монетами через журнал —
+-- ровно столько, сколько за них списано (с учётом скидки уровня).
+UPDATE shop_items SET is_active = FALSE WHERE id = 'power_shield';
+
+SELECT wallet_add_coins(p.child_profile_id, p.spent, 'adjustment', 'power_shield',
+                        'coins:refund:power_shield', 'Возврат за снятый усилитель «Щит»')
+FROM (
+    SELECT child_profile_id, -SUM(amount)::INTEGER AS spent
+    FROM wallet_transactions
+    WHERE currency = 'coins' AND source = 'purchase' AND source_id = 'power_shield'
+    GROUP BY child_profile_id
+) p
+WHERE p.spent > 0;
+
+UPDATE child_inventory SET quantity = 0, updated_at = NOW()
+WHERE item_id = 'power_shield' AND quantity > 0;
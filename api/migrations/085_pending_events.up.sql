@@ -0,0 +1,18 @@
+-- Недоставленные доменные события. Шина событий записывает сюда событие для подписчика,
+-- обработчик которого вернул ошибку, и повторяет доставку с нарастающей паузой.
+-- Обработчики идемпотентны по ключу события, поэтому повтор безопасен.
+CREATE TABLE IF NOT EXISTS pending_events (
+    id BIGSERIAL PRIMARY KEY,
+    subscriber VARCHAR(50) NOT NULL, -- кому не доставлено: achievements, pet, knowledge, ...
+    event_type VARCHAR(50) NOT NULL,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    last_error TEXT NOT NULL,
+    next_attempt_at TIMESTAMPTZ, -- NULL — попытки исчерпаны, событие оставлено для разбора
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_events_due ON pending_events (next_attempt_at)
+    WHERE next_attempt_at IS NOT NULL;
@@ -0,0 +1,14 @@
+CREATE OR REPLACE VIEW hint_template_ratings AS
+SELECT
+    template_id,
+    hint_level,
+    COUNT(*) AS ratings_total,
+    COUNT(*) FILTER (WHERE rating = 'helpful') AS helpful,
+    COUNT(*) FILTER (WHERE rating = 'unclear') AS unclear,
+    COUNT(*) FILTER (WHERE rating = 'gave_away_answer') AS gave_away_answer,
+    SUM(regenerations) AS regenerations,
+    ROUND(COUNT(*) FILTER (WHERE rating <> 'helpful')::NUMERIC / COUNT(*), 2) AS poor_ratio
+FROM hint_feedback
+GROUP BY template_id, hint_level;
+
+DROP TABLE IF EXISTS hint_ratings;
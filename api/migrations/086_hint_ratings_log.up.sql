@@ -0,0 +1,42 @@
+-- Журнал оценок подсказок. hint_feedback хранит только последнюю оценку и счётчик
+-- перегенераций, поэтому повторная оценка затирала предыдущую; сюда пишется каждая оценка.
+CREATE TABLE IF NOT EXISTS hint_ratings (
+    id BIGSERIAL PRIMARY KEY,
+    attempt_id UUID NOT NULL REFERENCES attempts(id) ON DELETE CASCADE,
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    hint_index INTEGER NOT NULL,
+    hint_level VARCHAR(10) NOT NULL,
+    template_id VARCHAR(100) NOT NULL DEFAULT '',
+    rating VARCHAR(20) NOT NULL CHECK (rating IN ('helpful', 'unclear', 'gave_away_answer')),
+    comment TEXT,
+    regenerate BOOLEAN NOT NULL DEFAULT FALSE, -- оценка с запросом перегенерации
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_hint_ratings_attempt ON hint_ratings (attempt_id, hint_index);
+CREATE INDEX IF NOT EXISTS idx_hint_ratings_template ON hint_ratings (template_id, rating);
+
+-- Сохранившиеся оценки: последняя оценка и по строке на каждую перегенерацию
+INSERT INTO hint_ratings (attempt_id, child_profile_id, hint_index, hint_level, template_id, rating, comment, regenerate, created_at)
+SELECT attempt_id, child_profile_id, hint_index, hint_level, template_id, rating, comment, FALSE, created_at
+FROM hint_feedback;
+
+INSERT INTO hint_ratings (attempt_id, child_profile_id, hint_index, hint_level, template_id, rating, comment, regenerate, created_at)
+SELECT f.attempt_id, f.child_profile_id, f.hint_index, f.hint_level, f.template_id, f.rating, f.comment, TRUE, f.updated_at
+FROM hint_feedback f, generate_series(1, f.regenerations);
+
+-- Сводка по шаблонам считается по журналу
+CREATE OR REPLACE VIEW hint_template_ratings AS
+SELECT
+    template_id,
+    hint_level,
+    COUNT(*) AS ratings_total,
+    COUNT(*) FILTER (WHERE rating = 'helpful') AS helpful,
+    COUNT(*) FILTER (WHERE rating = 'unclear') AS unclear,
+    COUNT(*) FILTER (WHERE rating = 'gave_away_answer') AS gave_away_answer,
+    COUNT(*) FILTER (WHERE regenerate) AS regenerations,
+    ROUND(COUNT(*) FILTER (WHERE rating <> 'helpful')::NUMERIC / COUNT(*), 2) AS poor_ratio
+FROM hint_ratings
+GROUP BY template_id, hint_level;
+
+COMMENT ON TABLE hint_ratings IS 'Все оценки подсказок по порядку (hint_feedback — последняя оценка)';
@@ -0,0 +1,23 @@
+-- Покупки в магазине по ключу запроса. Повтор раньше искался по записи в журнале монет,
+-- но бесплатная покупка туда не попадает, а ключ не был связан с предметом.
+CREATE TABLE IF NOT EXISTS shop_purchases (
+    child_profile_id UUID NOT NULL REFERENCES child_profiles(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(150) NOT NULL,
+    item_id VARCHAR(100) NOT NULL REFERENCES shop_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    total_price INTEGER NOT NULL CHECK (total_price >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (child_profile_id, idempotency_key)
+);
+
+-- Прежние платные покупки из журнала (количество в журнале не хранилось)
+INSERT INTO shop_purchases (child_profile_id, idempotency_key, item_id, quantity, total_price, created_at)
+SELECT w.child_profile_id, SUBSTRING(w.idempotency_key FROM LENGTH('coins:purchase:') + 1),
+       w.source_id, 1, -w.amount, w.created_at
+FROM wallet_transactions w
+JOIN shop_items i ON i.id = w.source_id
+WHERE w.source = 'purchase'
+  AND w.idempotency_key LIKE 'coins:purchase:%'
+  AND LENGTH(w.idempotency_key) - LENGTH('coins:purchase:') BETWEEN 1 AND 150
+ON CONFLICT DO NOTHING;
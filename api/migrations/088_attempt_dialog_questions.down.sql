@@ -0,0 +1 @@
+ALTER TABLE attempts DROP COLUMN IF EXISTS dialog_questions;
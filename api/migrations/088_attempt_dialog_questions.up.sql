@@ -0,0 +1,16 @@
+-- Счётчик вопросов диалога по попытке. Слот вопроса занимается одним условным UPDATE до запроса к LLM,
+-- поэтому параллельные вопросы не превысят лимит.
+ALTER TABLE attempts ADD COLUMN IF NOT EXISTS dialog_questions INTEGER NOT NULL DEFAULT 0
+    CHECK (dialog_questions >= 0);
+
+UPDATE attempts a
+SET dialog_questions = m.questions
+FROM (
+    SELECT attempt_id, COUNT(*) AS questions
+    FROM attempt_messages
+    WHERE role = 'child'
+    GROUP BY attempt_id
+) m
+WHERE a.id = m.attempt_id;
+
+COMMENT ON COLUMN attempts.dialog_questions IS 'Сколько вопросов диалога занято (включая ожидающие ответа наставника)';
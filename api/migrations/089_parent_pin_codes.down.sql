@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_email_verifications_parent;
+ALTER TABLE parent_pins DROP COLUMN IF EXISTS lock_count;
+DROP TABLE IF EXISTS parent_pin_codes;
@@ -0,0 +1,20 @@
+-- Задать PIN впервые или сбросить забытый можно только с одноразовым кодом из письма на email родителя
+-- (первый подтверждённый адрес аккаунта в email_verifications): ребёнок с того же аккаунта платформы
+-- письмо не получит. Хранится только SHA-256 кода.
+CREATE TABLE IF NOT EXISTS parent_pin_codes (
+    platform_id VARCHAR(20) NOT NULL,
+    parent_user_id VARCHAR(255) NOT NULL,
+    code_hash BYTEA NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (platform_id, parent_user_id)
+);
+
+-- Каждая следующая блокировка PIN подряд вдвое длиннее предыдущей
+ALTER TABLE parent_pins ADD COLUMN IF NOT EXISTS lock_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_email_verifications_parent
+    ON email_verifications (platform_id, parent_user_id, verified_at)
+    WHERE is_verified = TRUE;
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"child-bot/api/internal/llm"
 	"child-bot/api/internal/llmclient"
 	"child-bot/api/internal/service"
 	"child-bot/api/internal/store"
@@ -175,6 +176,7 @@ func runCheckAnswerForPair(t *testing.T, cfg *TestConfig, llmClient *llmclient.C
 		LlmManager: service.NewLlmManager(cfg.LLMName),
 		LLMClient:  llmClient,
 		Store:      st,
+		Dialog:     service.NewDialogService(st, llm.NewClient(cfg.LLMProxyURL), cfg.LLMName),
 	}
 
 	taskImage := loadTestImage(t, pair.TaskPath)
@@ -12,6 +12,7 @@ import (
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
+	"child-bot/api/internal/llm"
 	"child-bot/api/internal/llmclient"
 	"child-bot/api/internal/service"
 	"child-bot/api/internal/store"
@@ -75,6 +76,7 @@ func runHintFlowForImage(t *testing.T, cfg *TestConfig, llmClient *llmclient.Cli
 		LlmManager: service.NewLlmManager(cfg.LLMName),
 		LLMClient:  llmClient,
 		Store:      st,
+		Dialog:     service.NewDialogService(st, llm.NewClient(cfg.LLMProxyURL), cfg.LLMName),
 	}
 
 	testImage := loadTestImage(t, imagePath)
@@ -269,6 +271,7 @@ func TestE2E_GradeSelection(t *testing.T) {
 		LlmManager: service.NewLlmManager(cfg.LLMName),
 		LLMClient:  llmClient,
 		Store:      st,
+		Dialog:     service.NewDialogService(st, llm.NewClient(cfg.LLMProxyURL), cfg.LLMName),
 	}
 
 	chatID := int64(99999002)
@@ -309,6 +312,7 @@ func TestE2E_StartCommand(t *testing.T) {
 		LlmManager: service.NewLlmManager(cfg.LLMName),
 		LLMClient:  llmClient,
 		Store:      st,
+		Dialog:     service.NewDialogService(st, llm.NewClient(cfg.LLMProxyURL), cfg.LLMName),
 	}
 
 	chatID := int64(99999003)
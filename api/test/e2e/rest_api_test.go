@@ -17,7 +17,9 @@ import (
 
 	"child-bot/api/internal/api/router"
 	"child-bot/api/internal/config"
+	"child-bot/api/internal/family"
 	"child-bot/api/internal/llm"
+	"child-bot/api/internal/mission"
 	"child-bot/api/internal/store"
 )
 
@@ -89,12 +91,24 @@ func setupE2EServer(t *testing.T, cfg *E2ETestConfig) (*httptest.Server, *sql.DB
 		llmClient = llm.NewClient(cfg.LLMProxyURL)
 	}
 
+	// Embedded content catalogs
+	missions, err := mission.Default()
+	if err != nil {
+		t.Fatalf("failed to load mission catalog: %v", err)
+	}
+	quests, err := family.Default()
+	if err != nil {
+		t.Fatalf("failed to load family quest catalog: %v", err)
+	}
+
 	// Create router
 	r := router.New(&router.Dependencies{
-		Store:      st,
-		LLMClient:  llmClient,
-		Config:     &config.Config{},
-		DefaultLLM: cfg.LLMName,
+		Store:        st,
+		LLMClient:    llmClient,
+		Config:       &config.Config{},
+		DefaultLLM:   cfg.LLMName,
+		Missions:     missions,
+		FamilyQuests: quests,
 	})
 
 	// Create test server
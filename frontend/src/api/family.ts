@@ -0,0 +1,115 @@
+// src/api/family.ts
+import { apiClient } from './client';
+import { parentSessionHeaders } from './parent';
+import type {
+  ConfirmFamilyQuestResult,
+  FamilyActor,
+  FamilyNotifications,
+  FamilyQuest,
+  FamilyQuests,
+  FamilyRedemption,
+  FamilyReward,
+  FamilyRewardInput,
+  FamilyRewards,
+  RedeemFamilyRewardResult,
+} from '@/types/family';
+
+export const familyAPI = {
+  /**
+   * Получить семейные квесты и шаблоны, которые можно начать
+   */
+  async getQuests(): Promise<FamilyQuests> {
+    return apiClient.get<FamilyQuests>('/family/quests');
+  },
+
+  /**
+   * Начать квест; с родительской сессией квест начинает родитель
+   */
+  async start(templateId: string, parentSession?: string): Promise<FamilyQuest> {
+    return apiClient.post<FamilyQuest>(`/family/quests/${templateId}/start`, undefined, parentSessionHeaders(parentSession));
+  },
+
+  /**
+   * Подтверждение родителя («объяснил задачу»)
+   */
+  async confirm(id: number, parentSession: string, idempotencyKey: string, note?: string): Promise<ConfirmFamilyQuestResult> {
+    return apiClient.post<ConfirmFamilyQuestResult>(
+      `/family/quests/${id}/confirm`,
+      { idempotency_key: idempotencyKey, note },
+      parentSessionHeaders(parentSession)
+    );
+  },
+
+  /**
+   * Уведомления ребёнку или родителю
+   */
+  async getNotifications(recipient: FamilyActor = 'child', parentSession?: string): Promise<FamilyNotifications> {
+    return apiClient.get<FamilyNotifications>('/family/notifications', {
+      params: { recipient },
+      ...parentSessionHeaders(parentSession),
+    });
+  },
+
+  /**
+   * Отметить уведомление прочитанным
+   */
+  async markRead(id: number, recipient: FamilyActor = 'child', parentSession?: string): Promise<void> {
+    return apiClient.post<void>(`/family/notifications/${id}/read`, undefined, {
+      params: { recipient },
+      ...parentSessionHeaders(parentSession),
+    });
+  },
+
+  /**
+   * Каталог наград за монеты, запросы ребёнка и баланс
+   */
+  async getRewards(): Promise<FamilyRewards> {
+    return apiClient.get<FamilyRewards>('/family/rewards');
+  },
+
+  /**
+   * Добавить награду в каталог (родитель)
+   */
+  async createReward(reward: FamilyRewardInput, parentSession: string): Promise<FamilyReward> {
+    return apiClient.post<FamilyReward>('/family/rewards', reward, parentSessionHeaders(parentSession));
+  },
+
+  /**
+   * Изменить награду (родитель)
+   */
+  async updateReward(id: number, reward: FamilyRewardInput, parentSession: string): Promise<FamilyReward> {
+    return apiClient.patch<FamilyReward>(`/family/rewards/${id}`, reward, parentSessionHeaders(parentSession));
+  },
+
+  /**
+   * Снять награду из каталога (родитель)
+   */
+  async archiveReward(id: number, parentSession: string): Promise<void> {
+    return apiClient.delete<void>(`/family/rewards/${id}`, parentSessionHeaders(parentSession));
+  },
+
+  /**
+   * Запросить награду: монеты списываются и ждут решения родителя
+   */
+  async redeem(id: number, idempotencyKey: string): Promise<RedeemFamilyRewardResult> {
+    return apiClient.post<RedeemFamilyRewardResult>(`/family/rewards/${id}/redeem`, {
+      idempotency_key: idempotencyKey,
+    });
+  },
+
+  /**
+   * Одобрить (approve) или отклонить (decline, монеты вернутся) запрос награды (родитель)
+   */
+  async decideRedemption(
+    id: number,
+    decision: 'approve' | 'decline',
+    parentSession: string,
+    note?: string
+  ): Promise<FamilyRedemption> {
+    return apiClient.post<FamilyRedemption>(
+      `/family/redemptions/${id}/${decision}`,
+      note ? { note } : undefined,
+      parentSessionHeaders(parentSession)
+    );
+  },
+};
@@ -0,0 +1,23 @@
+// src/api/knowledge.ts
+import { apiClient } from './client';
+import type { KnowledgeMap } from '@/types/knowledge';
+
+export const knowledgeAPI = {
+  /**
+   * Получить карту знаний ребёнка: темы с освоением и слабые места
+   */
+  async getMap(subject?: string, grade?: number): Promise<KnowledgeMap> {
+    return apiClient.get<KnowledgeMap>('/knowledge-map', {
+      params: { subject, grade },
+    });
+  },
+
+  /**
+   * Получить карту знаний ребёнка для родителя
+   */
+  async getChildMap(childProfileId: string, subject?: string, grade?: number): Promise<KnowledgeMap> {
+    return apiClient.get<KnowledgeMap>(`/reports/${childProfileId}/knowledge-map`, {
+      params: { subject, grade },
+    });
+  },
+};
@@ -0,0 +1,19 @@
+// src/api/level.ts
+import { apiClient } from './client';
+import type { Levels } from '@/types/level';
+
+export const levelAPI = {
+  /**
+   * Получить таблицу уровней, прогресс и непросмотренные поздравления
+   */
+  async getLevels(): Promise<Levels> {
+    return apiClient.get<Levels>('/levels');
+  },
+
+  /**
+   * Отметить поздравления с уровнями до level включительно просмотренными
+   */
+  async markSeen(level: number): Promise<{ marked: number }> {
+    return apiClient.post<{ marked: number }>(`/levels/${level}/seen`);
+  },
+};
@@ -0,0 +1,12 @@
+// src/api/mission.ts
+import { apiClient } from './client';
+import type { DailyMissions } from '@/types/mission';
+
+export const missionAPI = {
+  /**
+   * Получить миссии на сегодня
+   */
+  async getToday(): Promise<DailyMissions> {
+    return apiClient.get<DailyMissions>('/missions/today');
+  },
+};
@@ -19,6 +19,7 @@ export const onboardingAPI = {
     avatarId: string;
     displayName: string;
     referralCode?: string;
+    timezone?: string;
   }): Promise<{ childProfileId: string }> {
     return apiClient.post<{ childProfileId: string }>('/profiles/child', data);
   },
@@ -0,0 +1,40 @@
+// src/api/parent.ts
+import { apiClient } from './client';
+import type { ParentPinStatus, ParentSession } from '@/types/parent';
+
+// Подписанные параметры запуска VK: по ним сервер узнаёт аккаунт родителя
+const launchParams = () => ({ params: Object.fromEntries(new URLSearchParams(window.location.search)) });
+
+// Действия родителя подтверждаются токеном родительской сессии
+export const parentSessionHeaders = (parentSession?: string) =>
+  parentSession ? { headers: { 'X-Parent-Session': parentSession } } : {};
+
+export const parentAPI = {
+  /**
+   * Задан ли PIN родителя
+   */
+  async getPinStatus(): Promise<ParentPinStatus> {
+    return apiClient.get<ParentPinStatus>('/parent/pin', launchParams());
+  },
+
+  /**
+   * Задать или сменить PIN родителя (смена требует текущий PIN)
+   */
+  async setPin(pin: string, currentPin?: string): Promise<void> {
+    return apiClient.post<void>('/parent/pin', { pin, current_pin: currentPin }, launchParams());
+  },
+
+  /**
+   * Открыть родительскую сессию по PIN
+   */
+  async startSession(pin: string): Promise<ParentSession> {
+    return apiClient.post<ParentSession>('/parent/session', { pin }, launchParams());
+  },
+
+  /**
+   * Завершить родительскую сессию
+   */
+  async endSession(parentSession: string): Promise<void> {
+    return apiClient.delete<void>('/parent/session', parentSessionHeaders(parentSession));
+  },
+};
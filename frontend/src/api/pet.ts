@@ -0,0 +1,12 @@
+// src/api/pet.ts
+import { apiClient } from './client';
+import type { Pet } from '@/types/pet';
+
+export const petAPI = {
+  /**
+   * Получить питомца с показателями на текущий момент
+   */
+  async getPet(): Promise<Pet> {
+    return apiClient.get<Pet>('/pet');
+  },
+};
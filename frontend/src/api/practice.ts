@@ -0,0 +1,46 @@
+// src/api/practice.ts
+import { apiClient } from './client';
+import type {
+  PracticeAnswerResult,
+  PracticeDrills,
+  PracticeFinishResult,
+  PracticeKind,
+  PracticeSession,
+} from '@/types/practice';
+
+export const practiceAPI = {
+  /**
+   * Получить виды разминок и награду за сегодня
+   */
+  async getDrills(): Promise<PracticeDrills> {
+    return apiClient.get<PracticeDrills>('/practice/drills');
+  },
+
+  /**
+   * Начать разминку; без kind — для самого слабого места
+   */
+  async start(kind?: PracticeKind): Promise<PracticeSession> {
+    return apiClient.post<PracticeSession>('/practice/sessions', kind ? { kind } : {});
+  },
+
+  /**
+   * Получить сессию (например, после перезагрузки)
+   */
+  async getSession(id: number): Promise<PracticeSession> {
+    return apiClient.get<PracticeSession>(`/practice/sessions/${id}`);
+  },
+
+  /**
+   * Ответить на задание
+   */
+  async answer(id: number, position: number, answer: string): Promise<PracticeAnswerResult> {
+    return apiClient.post<PracticeAnswerResult>(`/practice/sessions/${id}/answer`, { position, answer });
+  },
+
+  /**
+   * Завершить разминку и получить награду
+   */
+  async finish(id: number): Promise<PracticeFinishResult> {
+    return apiClient.post<PracticeFinishResult>(`/practice/sessions/${id}/finish`);
+  },
+};
@@ -0,0 +1,19 @@
+// src/api/review.ts
+import { apiClient } from './client';
+import type { DueReviews, ReviewSubmitResult } from '@/types/review';
+
+export const reviewAPI = {
+  /**
+   * Получить задачи, которые пора повторить сегодня
+   */
+  async getDue(): Promise<DueReviews> {
+    return apiClient.get<DueReviews>('/reviews/due');
+  },
+
+  /**
+   * Отправить фото решения задачи на повторение
+   */
+  async submit(id: number, imageData: string): Promise<ReviewSubmitResult> {
+    return apiClient.post<ReviewSubmitResult>(`/reviews/${id}/submit`, { image_data: imageData });
+  },
+};
@@ -0,0 +1,14 @@
+// src/api/reward.ts
+import { apiClient } from './client';
+import type { RewardAudit } from '@/types/reward';
+
+export const rewardAPI = {
+  /**
+   * Получить для родителя урезанные политикой начисления и подозрительные дни
+   */
+  async getAudit(childProfileId: string, days?: number): Promise<RewardAudit> {
+    return apiClient.get<RewardAudit>(`/reports/${childProfileId}/reward-audit`, {
+      params: { days },
+    });
+  },
+};
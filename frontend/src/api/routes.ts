@@ -29,6 +29,78 @@ export const API_ROUTES = {
     damage: (id: string) => `/villains/${id}/damage`,
   },
 
+  // Weekly boss
+  boss: {
+    weekly: '/boss',
+  },
+
+  // Pet
+  pet: {
+    get: '/pet',
+  },
+
+  // Knowledge map
+  knowledge: {
+    map: '/knowledge-map',
+    childMap: (childProfileId: string) => `/reports/${childProfileId}/knowledge-map`,
+  },
+
+  // Reviews (spaced repetition)
+  reviews: {
+    due: '/reviews/due',
+    submit: (id: number) => `/reviews/${id}/submit`,
+  },
+
+  // Daily missions
+  missions: {
+    today: '/missions/today',
+  },
+
+  // Seasonal events
+  events: {
+    current: '/events/current',
+  },
+
+  // Family quests
+  family: {
+    quests: '/family/quests',
+    start: (templateId: string) => `/family/quests/${templateId}/start`,
+    confirm: (id: number) => `/family/quests/${id}/confirm`,
+    notifications: '/family/notifications',
+    read: (id: number) => `/family/notifications/${id}/read`,
+    rewards: '/family/rewards',
+    reward: (id: number) => `/family/rewards/${id}`,
+    redeem: (id: number) => `/family/rewards/${id}/redeem`,
+    approve: (id: number) => `/family/redemptions/${id}/approve`,
+    decline: (id: number) => `/family/redemptions/${id}/decline`,
+  },
+
+  // Practice drills (brain breaks)
+  practice: {
+    drills: '/practice/drills',
+    sessions: '/practice/sessions',
+    session: (id: number) => `/practice/sessions/${id}`,
+    answer: (id: number) => `/practice/sessions/${id}/answer`,
+    finish: (id: number) => `/practice/sessions/${id}/finish`,
+  },
+
+  // Reward policy audit (parent)
+  rewards: {
+    audit: (childProfileId: string) => `/reports/${childProfileId}/reward-audit`,
+  },
+
+  // Parent PIN and session
+  parent: {
+    pin: '/parent/pin',
+    session: '/parent/session',
+  },
+
+  // Level table and level-up rewards
+  levels: {
+    list: '/levels',
+    seen: (level: number) => `/levels/${level}/seen`,
+  },
+
   // Profile
   profile: {
     get: '/profile',
@@ -0,0 +1,12 @@
+// src/api/seasonal.ts
+import { apiClient } from './client';
+import type { CurrentEvents } from '@/types/seasonal';
+
+export const seasonalAPI = {
+  /**
+   * Получить сезонные события, идущие сегодня
+   */
+  async getCurrent(): Promise<CurrentEvents> {
+    return apiClient.get<CurrentEvents>('/events/current');
+  },
+};
@@ -1,6 +1,7 @@
 // src/api/villain.ts
 import { apiClient } from './client';
 import type {
+  BossFight,
   Villain,
   VillainBattle,
   VillainVictory,
@@ -28,6 +29,13 @@ export const villainAPI = {
     return apiClient.get<VillainVictory>(`/villains/${villainId}/victory`);
   },
 
+  /**
+   * Получить недельного босса и состояние битвы
+   */
+  async getWeeklyBoss(): Promise<BossFight> {
+    return apiClient.get<BossFight>('/boss');
+  },
+
   /**
    * Нанести урон злодею
    */
@@ -102,6 +102,7 @@ export function OnboardingPageNew() {
         avatarId: avatarId!,
         displayName: displayName!,
         referralCode: referralCode || undefined,
+        timezone: Intl.DateTimeFormat().resolvedOptions().timeZone || undefined,
       });
 
       console.log('[Onboarding] Child profile created:', childProfileId);
@@ -0,0 +1,124 @@
+// src/types/family.ts
+
+export type FamilyActor = 'child' | 'parent';
+
+// Цель одной стороны квеста: метрика ребёнка или действие родителя
+export interface FamilyGoal {
+  kind: 'tasks_correct' | 'tasks_checked' | 'active_days' | 'explained_task' | 'practiced_together';
+  target: number;
+  progress: number;
+  label?: string; // текст кнопки подтверждения родителя
+}
+
+// Награда за квест; семейный бейдж попадает в коллекцию
+export interface FamilyReward {
+  coins: number;
+  xp: number;
+  badge_id?: string;
+  badge_name?: string;
+}
+
+// Шаблон квеста, который можно начать
+export interface FamilyQuestTemplate {
+  id: string;
+  type: string;
+  icon: string;
+  title: string;
+  description: string;
+  child: FamilyGoal;
+  parent?: FamilyGoal;
+  window: 'days' | 'weekend';
+  duration_days?: number;
+  reward: FamilyReward;
+}
+
+// Начатый семейный квест
+export interface FamilyQuest {
+  id: number;
+  template_id: string;
+  type: string;
+  icon: string;
+  title: string;
+  description: string;
+  child: FamilyGoal;
+  parent?: FamilyGoal;
+  window: 'days' | 'weekend';
+  reward: FamilyReward;
+  started_by: FamilyActor;
+  status: 'active' | 'completed' | 'expired';
+  started_at: string;
+  ends_at: string;
+  completed_at?: string;
+}
+
+export interface FamilyQuests {
+  templates: FamilyQuestTemplate[];
+  active: FamilyQuest[];
+  history: FamilyQuest[]; // за последние 30 дней
+}
+
+export interface ConfirmFamilyQuestResult {
+  quest: FamilyQuest;
+  completed: boolean; // квест выполнен этим подтверждением
+}
+
+export interface FamilyNotification {
+  id: number;
+  kind: 'quest_completed' | 'reward_requested' | 'reward_approved' | 'reward_declined';
+  quest_id?: number;
+  redemption_id?: number; // запрос награды за монеты
+  title: string;
+  body: string;
+  read: boolean;
+  created_at: string;
+}
+
+export interface FamilyNotifications {
+  recipient: FamilyActor;
+  notifications: FamilyNotification[];
+  unread: number;
+}
+
+// Награда в реальном мире, которую родитель назначил за монеты
+export interface FamilyReward {
+  id: number;
+  title: string;
+  description?: string;
+  icon: string;
+  price: number;
+  updated_at: string;
+}
+
+// Награда, которую добавляет или меняет родитель
+export interface FamilyRewardInput {
+  title: string;
+  description?: string;
+  icon?: string; // по умолчанию 🎁
+  price: number;
+}
+
+export type FamilyRedemptionStatus = 'pending' | 'approved' | 'declined';
+
+// Запрос награды: монеты списаны и ждут решения родителя; при отказе возвращаются
+export interface FamilyRedemption {
+  id: number;
+  reward_id: number;
+  title: string;
+  icon: string;
+  price: number;
+  status: FamilyRedemptionStatus;
+  parent_note?: string;
+  requested_at: string;
+  decided_at?: string;
+}
+
+export interface FamilyRewards {
+  rewards: FamilyReward[];
+  redemptions: FamilyRedemption[]; // ожидающие и решённые за 30 дней, новые первыми
+  coins_balance: number;
+}
+
+export interface RedeemFamilyRewardResult {
+  redemption: FamilyRedemption;
+  replayed?: boolean;
+}
@@ -17,6 +17,7 @@ export interface HomeData {
     state: MascotState;
     imageUrl: string;
     message: string;
+    pet?: HomePet; // Настоящее состояние питомца
   };
   villain: Villain | null;
   unfinishedAttempt: Attempt | null;
@@ -25,6 +26,52 @@ export interface HomeData {
     unlockedCount: number;
     totalCount: number;
   };
+  missions?: HomeMissions; // Нет — миссии недоступны
+  event?: HomeEvent; // Нет — сегодня событий нет
+}
+
+// Сезонное событие, которое закончится раньше других
+export interface HomeEvent {
+  id: string;
+  title: string;
+  icon: string;
+  themeColor: string;
+  endDate: string; // YYYY-MM-DD
+  daysLeft: number;
+  achievementsUnlocked: number;
+  achievementsTotal: number;
+}
+
+// Сводка миссий дня
+export interface HomeMissions {
+  completedCount: number;
+  totalCount: number;
+  items: HomeMission[];
+}
+
+export interface HomeMission {
+  id: number;
+  icon: string;
+  title: string;
+  progress: number;
+  target: number;
+  completed: boolean;
+}
+
+export type PetMood = 'happy' | 'ok' | 'hungry' | 'sad' | 'tired' | 'sleeping';
+
+export interface HomePet {
+  type: string;
+  stage: number;
+  stageCode: 'egg' | 'baby' | 'teen' | 'adult';
+  stageName: string;
+  hunger: number; // 0 — сыт, 100 — очень голоден
+  happiness: number;
+  energy: number;
+  mood: PetMood;
+  isSleeping: boolean;
+  nextStageLevel?: number; // Нет — питомец уже взрослый
+  justEvolved: boolean; // Показать анимацию эволюции
 }
 
 export interface RecentAttempt {
@@ -0,0 +1,44 @@
+// src/types/knowledge.ts
+
+// Уровень освоения: 0 не начата, 1 изучается, 2 закрепляется, 3 освоена
+export type KnowledgeLevel = 0 | 1 | 2 | 3;
+
+// Раздел или тема карты знаний
+export interface KnowledgeNode {
+  id: string;
+  kind: 'section' | 'topic';
+  title: string;
+  template_code?: string;
+  grade_min: number;
+  grade_max: number;
+  mastery: number; // 0..100
+  level: KnowledgeLevel;
+  attempts: number;
+  correct: number;
+  hints_used: number;
+  is_weak: boolean;
+  last_practiced_at?: string;
+  topics_total?: number;
+  topics_practiced?: number;
+  topics_mastered?: number;
+  topics?: KnowledgeNode[];
+}
+
+// Слабое место с рекомендацией
+export interface KnowledgeWeakSpot extends KnowledgeNode {
+  section_id: string;
+  section_title: string;
+  recommendation: string;
+}
+
+// Карта знаний (GET /knowledge-map, GET /reports/{childProfileId}/knowledge-map)
+export interface KnowledgeMap {
+  subject: string;
+  grade: number; // 0 — все классы
+  mastery: number;
+  topics_total: number;
+  topics_practiced: number;
+  topics_mastered: number;
+  sections: KnowledgeNode[];
+  weak_spots: KnowledgeWeakSpot[];
+}
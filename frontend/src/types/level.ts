@@ -0,0 +1,43 @@
+// src/types/level.ts
+
+export type LevelRewardType = 'coins' | 'avatar' | 'mascot_item' | 'title' | 'shop_discount';
+
+// Награда за уровень
+export interface LevelReward {
+  type: LevelRewardType;
+  id?: string; // предмет магазина или звание
+  name?: string; // название звания
+  amount?: number; // монеты
+  percent?: number; // скидка на усилители
+}
+
+// Строка таблицы уровней
+export interface LevelRow {
+  level: number;
+  xp: number;
+  title: string;
+  rewards: LevelReward[];
+  reached: boolean;
+}
+
+// Переход на уровень с выданными наградами; backfilled — уровень пройден до появления таблицы
+export interface LevelUp {
+  level: number;
+  title: string;
+  rewards: LevelReward[];
+  backfilled: boolean;
+  created_at: string;
+}
+
+// Прогресс ребёнка по таблице уровней
+export interface Levels {
+  version: number;
+  level: number;
+  title: string;
+  xp_total: number;
+  xp_in_level: number;
+  xp_needed: number;
+  shop_discount_percent: number;
+  levels: LevelRow[];
+  level_ups: LevelUp[]; // непросмотренные поздравления, новые первыми
+}
@@ -0,0 +1,30 @@
+// src/types/mission.ts
+
+export interface MissionReward {
+  coins: number;
+  xp: number;
+}
+
+// Миссия дня
+export interface DailyMission {
+  id: number;
+  mission_id: string;
+  icon: string;
+  title: string;
+  description: string;
+  subject?: string; // Засчитываются только задачи этого предмета
+  target: number;
+  progress: number;
+  reward: MissionReward;
+  completed: boolean;
+  completed_at?: string;
+}
+
+// Миссии ребёнка на сегодня (GET /missions/today)
+export interface DailyMissions {
+  date: string; // YYYY-MM-DD
+  items: DailyMission[];
+  completed_count: number;
+  total_count: number;
+  all_done_bonus: MissionReward;
+}
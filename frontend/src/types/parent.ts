@@ -0,0 +1,13 @@
+// src/types/parent.ts
+
+// Задан ли PIN родителя
+export interface ParentPinStatus {
+  pin_set: boolean;
+  locked_until?: string; // ввод PIN заблокирован после серии ошибок
+}
+
+// Родительская сессия: токен передаётся в заголовке X-Parent-Session
+export interface ParentSession {
+  token: string;
+  expires_at: string;
+}
@@ -0,0 +1,21 @@
+// src/types/pet.ts
+import type { PetMood } from './home';
+
+// Питомец ребёнка (GET /pet)
+export interface Pet {
+  type: string;
+  stage: number;
+  stage_code: 'egg' | 'baby' | 'teen' | 'adult';
+  stage_name: string;
+  hunger: number; // 0 — сыт, 100 — очень голоден
+  happiness: number;
+  energy: number;
+  mood: PetMood;
+  is_sleeping: boolean;
+  message: string;
+  image_url: string;
+  level: number; // Уровень ребёнка
+  next_stage_level?: number;
+  just_evolved: boolean;
+  last_fed_at?: string;
+}
@@ -0,0 +1,76 @@
+// src/types/practice.ts
+
+export type PracticeKind = 'multiplication' | 'column_addition' | 'order_of_operations' | 'orthography';
+
+// Вид разминки; weak — тренирует слабое место ребёнка
+export interface PracticeDrill {
+  kind: PracticeKind;
+  subject: 'math' | 'russian';
+  icon: string;
+  title: string;
+  available: boolean;
+  weak: boolean;
+}
+
+// Награда за разминки и сколько уже получено сегодня
+export interface PracticeRewards {
+  xp_per_correct: number;
+  coins_per_correct: number;
+  daily_xp_cap: number;
+  daily_coins_cap: number;
+  xp_today: number;
+  coins_today: number;
+}
+
+export interface PracticeDrills {
+  grade: number;
+  recommended?: PracticeKind;
+  drills: PracticeDrill[];
+  rewards: PracticeRewards;
+}
+
+// Задание; верный ответ и правило приходят только после ответа
+export interface PracticeItem {
+  position: number;
+  prompt: string;
+  options?: string[]; // пусто — ответ вводится
+  hint?: string;
+  answered: boolean;
+  given_answer?: string;
+  is_correct?: boolean;
+  correct_answer?: string;
+}
+
+export interface PracticeSession {
+  id: number;
+  kind: PracticeKind;
+  grade: number;
+  status: 'active' | 'finished';
+  started_at: string;
+  expires_at: string;
+  items: PracticeItem[];
+  correct_count: number;
+  xp_awarded: number;
+  coins_awarded: number;
+}
+
+export interface PracticeAnswerResult {
+  position: number;
+  is_correct: boolean;
+  counted: boolean; // false — ответ слишком быстрый и в счёт не идёт
+  correct_answer: string;
+  hint?: string;
+  answered: number;
+  total: number;
+}
+
+export interface PracticeFinishResult {
+  session_id: number;
+  answered: number;
+  total: number;
+  correct_count: number;
+  xp_awarded: number;
+  coins_awarded: number;
+  cap_reached: boolean; // награда урезана дневным лимитом
+  leveled_up: boolean;
+}
@@ -0,0 +1,36 @@
+// src/types/review.ts
+
+export type ReviewReason = 'after_incorrect' | 'after_3_hints';
+
+// Задача на повторение
+export interface ReviewTask {
+  id: number;
+  reason: ReviewReason;
+  subject: string;
+  task_text: string;
+  task_source: 'analogue' | 'original';
+  due_on: string; // YYYY-MM-DD
+  reviews_count: number;
+  interval_days: number;
+  created_at: string;
+}
+
+// Задачи на повторение на сегодня (GET /reviews/due)
+export interface DueReviews {
+  items: ReviewTask[];
+  due_count: number;
+  upcoming_count: number;
+  retired_count: number;
+  next_due_on?: string;
+}
+
+// Результат повторения (POST /reviews/{id}/submit)
+export interface ReviewSubmitResult {
+  decision: string;
+  is_correct: boolean;
+  feedback?: string;
+  rescheduled: boolean; // false — ответ не удалось проверить
+  retired: boolean; // задача закреплена
+  interval_days: number;
+  next_due_on?: string;
+}
@@ -0,0 +1,53 @@
+// src/types/reward.ts
+
+export type RewardCurrency = 'xp' | 'coins';
+
+// Почему начисление урезано политикой наград
+export type RewardReason = 'daily_cap' | 'repeated_task' | 'answer_shown';
+
+// Дневной лимит источника
+export interface RewardCap {
+  source: string;
+  currency: RewardCurrency;
+  daily: number;
+}
+
+// Правила начислений
+export interface RewardPolicy {
+  caps: RewardCap[];
+  repeat_percents: number[];
+  answer_shown_sources: string[];
+  suspicious_per_day: number;
+}
+
+// Урезанные начисления одной причины за день; suspicious — похоже на «фарм»
+export interface RewardAuditDay {
+  date: string;
+  reason: RewardReason;
+  events: number;
+  withheld_xp: number;
+  withheld_coins: number;
+  suspicious: boolean;
+}
+
+// Урезанное начисление
+export interface RewardAuditEvent {
+  id: number;
+  currency: RewardCurrency;
+  source: string;
+  source_id?: string;
+  requested: number;
+  granted: number;
+  reason: RewardReason;
+  repeats: number;
+  created_at: string;
+}
+
+// Аудит наград ребёнка для родителя
+export interface RewardAudit {
+  days: number;
+  suspicious: boolean;
+  policy: RewardPolicy;
+  summary: RewardAuditDay[];
+  events: RewardAuditEvent[];
+}
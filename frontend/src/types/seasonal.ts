@@ -0,0 +1,56 @@
+// src/types/seasonal.ts
+
+// Достижение события: считаются только действия в даты события
+export interface EventAchievement {
+  id: string;
+  icon: string;
+  title: string;
+  description: string;
+  progress: number;
+  target: number;
+  unlocked: boolean;
+  reward_type: 'coins' | 'xp' | 'sticker' | 'badge';
+  reward_name?: string;
+}
+
+// Злодей события: приходит в свои дни недели, пока не побеждён
+export interface EventVillain {
+  id: string;
+  name: string;
+  image_url: string;
+  weekdays: number[]; // 1 — понедельник, 7 — воскресенье
+  defeated: boolean;
+}
+
+// Предмет магазина, который продаётся только во время события
+export interface EventShopItem {
+  id: string;
+  category: 'avatar' | 'mascot_item';
+  name: string;
+  icon: string;
+  price: number;
+  min_level: number;
+  owned: boolean;
+}
+
+export interface SeasonalEvent {
+  id: string;
+  title: string;
+  description: string;
+  icon: string;
+  theme_color: string;
+  start_date: string; // YYYY-MM-DD
+  end_date: string; // YYYY-MM-DD, включительно
+  days_left: number; // Включая сегодня
+  achievements_unlocked: number;
+  achievements_total: number;
+  achievements: EventAchievement[];
+  villains: EventVillain[];
+  shop_items: EventShopItem[];
+}
+
+// События, идущие сегодня (GET /events/current)
+export interface CurrentEvents {
+  date: string; // YYYY-MM-DD
+  events: SeasonalEvent[];
+}
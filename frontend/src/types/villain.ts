@@ -32,10 +32,79 @@ export interface BattleStats {
 export interface DamageEvent {
   id: string;
   damage: number;
+  base_damage: number; // Урон без бонусов
+  is_critical: boolean; // Решено без подсказок — показываем «крит!»
+  bonuses: DamageBonus[];
   task_type: string;
   created_at: string;
 }
 
+export interface DamageBonus {
+  code: 'items' | 'task_type' | 'template' | 'grade' | 'first_try' | 'critical' | 'cap';
+  label: string; // Готовая подпись: «С первой попытки», «Крит! Без подсказок»
+  damage: number; // Для cap — отрицательный (урон срезан потолком)
+}
+
+// Недельный босс: битва на несколько дней с фазами (GET /boss)
+export interface BossRequirement {
+  subject?: string; // math, ru, ...; нет — любой предмет
+  subject_name?: string;
+  task_type?: string; // word_problems, ...; нет — любой тип
+  task_type_name?: string;
+}
+
+export interface BossPhase {
+  number: number;
+  title: string;
+  hp: number;
+  max_hp: number;
+  status: 'completed' | 'current' | 'locked';
+  requirement: BossRequirement;
+}
+
+export interface BossReward {
+  type: 'coins' | 'xp' | 'item';
+  item_id?: string;
+  item_name?: string;
+  amount: number;
+}
+
+export interface BossDamageEvent {
+  phase: number;
+  damage: number;
+  is_critical: boolean;
+  bonuses: DamageBonus[];
+  subject?: string;
+  created_at: string;
+}
+
+export interface BossFight {
+  battle_id: number;
+  villain_id: string;
+  name: string;
+  description: string;
+  image_url: string;
+  level: number;
+  status: 'active' | 'defeated' | 'expired';
+  week_start: string; // YYYY-MM-DD
+  ends_on: string; // YYYY-MM-DD, последний день битвы
+  today: string;
+  days_left: number; // Включая сегодня
+  hp: number; // Осталось у босса по всем фазам
+  max_hp: number;
+  current_phase: number;
+  phases: BossPhase[];
+  next?: BossRequirement; // Что решать, чтобы ранить босса
+  regen_hp_per_day: number;
+  healed_now: number; // Восстановлено за пропуски при этом запросе
+  regen_total: number;
+  total_damage: number;
+  tasks_count: number;
+  rewards: BossReward[];
+  recent_damage: BossDamageEvent[];
+  defeated_at?: string;
+}
+
 export interface VillainVictory {
   villain_id: string;
   villain_name: string;